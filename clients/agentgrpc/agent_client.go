@@ -23,6 +23,8 @@ const (
 	MethodEvaluateTx          Method = "/network.forta.Agent/EvaluateTx"
 	MethodEvaluateBlock       Method = "/network.forta.Agent/EvaluateBlock"
 	MethodEvaluateAlert Method = "/network.forta.Agent/EvaluateAlert"
+	// MethodHealthCheck is the standard gRPC health check.
+	MethodHealthCheck Method = "/grpc.health.v1.Health/Check"
 )
 
 // Client allows us to communicate with an agent.
@@ -46,6 +48,7 @@ func (client *Client) Dial(cfg config.AgentConfig) error {
 		conn, err = grpc.Dial(
 			fmt.Sprintf("%s:%s", cfg.ContainerName(), cfg.GrpcPort()),
 			grpc.WithInsecure(),
+			grpc.WithNoProxy(), // agents are in the internal network
 			grpc.WithBlock(),
 			grpc.WithTimeout(10*time.Second),
 			grpc.WithDefaultCallOptions(grpc.MaxCallRecvMsgSize(defaultAgentResponseMaxByteCount)),
@@ -2,18 +2,107 @@ package alertapi
 
 import (
 	"bytes"
+	"compress/gzip"
+	"context"
+	"errors"
 	"fmt"
 	"io"
+	"math/rand"
 	"net/http"
+	"sync/atomic"
 	"time"
 
+	"github.com/forta-network/forta-core-go/clients/health"
 	"github.com/forta-network/forta-core-go/domain"
+	"github.com/forta-network/forta-node/clients/signer"
 	"github.com/goccy/go-json"
 	log "github.com/sirupsen/logrus"
 )
 
+// RetryConfig configures the retries of the alert API requests.
+type RetryConfig struct {
+	MaxAttempts    int
+	InitialBackoff time.Duration
+	MaxBackoff     time.Duration
+	// Jitter is the fraction of the backoff to randomize (0-1).
+	Jitter float64
+}
+
+// StartupCheckRetryConfig is the short retry budget of the start-up check so that transient
+// errors do not block the node start.
+var StartupCheckRetryConfig = RetryConfig{
+	MaxAttempts:    3,
+	InitialBackoff: time.Second,
+	MaxBackoff:     time.Second * 2,
+	Jitter:         0.2,
+}
+
+func (cfg RetryConfig) maxAttempts() int {
+	if cfg.MaxAttempts < 1 {
+		return 1
+	}
+	return cfg.MaxAttempts
+}
+
+func (cfg RetryConfig) nextBackoff(backoff time.Duration) time.Duration {
+	backoff *= 2
+	if cfg.MaxBackoff > 0 && backoff > cfg.MaxBackoff {
+		return cfg.MaxBackoff
+	}
+	return backoff
+}
+
+func (cfg RetryConfig) withJitter(backoff time.Duration) time.Duration {
+	if cfg.Jitter <= 0 || backoff <= 0 {
+		return backoff
+	}
+	delta := cfg.Jitter * float64(backoff)
+	return backoff + time.Duration(delta*(rand.Float64()*2-1))
+}
+
+// APIError is returned when the alert API request fails.
+type APIError struct {
+	StatusCode int
+	Body       string
+	Retryable  bool
+	Err        error
+}
+
+func (e *APIError) Error() string {
+	if e.Err != nil {
+		return e.Err.Error()
+	}
+	return fmt.Sprintf("%d error: %s", e.StatusCode, e.Body)
+}
+
+func (e *APIError) Unwrap() error {
+	return e.Err
+}
+
+// IsRetryable tells if the error is temporary and the request can be retried later.
+func IsRetryable(err error) bool {
+	var apiErr *APIError
+	if errors.As(err, &apiErr) {
+		return apiErr.Retryable
+	}
+	return false
+}
+
+func isRetryableStatus(statusCode int) bool {
+	return statusCode == http.StatusTooManyRequests || statusCode >= 500
+}
+
 type client struct {
-	apiUrl string
+	apiUrl     string
+	retry      RetryConfig
+	compress   bool
+	httpClient *http.Client
+	sleep      func(time.Duration)
+	signer     signer.Signer
+
+	retryCount     atomic.Int64
+	lastRetryCount health.NumberTracker
+	lastErr        health.ErrorTracker
 }
 
 func (c *client) post(path string, body interface{}, headers map[string]string, target interface{}) error {
@@ -21,19 +110,55 @@ func (c *client) post(path string, body interface{}, headers map[string]string,
 	if err != nil {
 		return err
 	}
-	req, err := http.NewRequest("POST", fmt.Sprintf("%s%s", c.apiUrl, path), bytes.NewBuffer(jsonVal))
+	return c.postJSON(path, jsonVal, headers, target)
+}
+
+func (c *client) postJSON(path string, jsonVal []byte, headers map[string]string, target interface{}) (err error) {
+	backoff := c.retry.InitialBackoff
+	for attempt := 1; ; attempt++ {
+		err = c.doPost(path, jsonVal, headers, target)
+		if err == nil || !IsRetryable(err) || attempt >= c.retry.maxAttempts() {
+			break
+		}
+		c.retryCount.Add(1)
+		wait := c.retry.withJitter(backoff)
+		log.WithFields(log.Fields{
+			"apiUrl":  c.apiUrl,
+			"path":    path,
+			"attempt": attempt,
+			"wait":    wait.String(),
+		}).WithError(err).Warn("alert api request failed - retrying")
+		c.sleep(wait)
+		backoff = c.retry.nextBackoff(backoff)
+	}
+	c.lastRetryCount.Set(float64(c.retryCount.Load()))
+	c.lastErr.Set(err)
+	return err
+}
+
+func (c *client) doPost(path string, jsonVal []byte, headers map[string]string, target interface{}) error {
+	reqBody := jsonVal
+	if c.compress {
+		var err error
+		reqBody, err = gzipBytes(jsonVal)
+		if err != nil {
+			return &APIError{Err: err}
+		}
+	}
+	req, err := http.NewRequest("POST", fmt.Sprintf("%s%s", c.apiUrl, path), bytes.NewBuffer(reqBody))
 	if err != nil {
-		return err
+		return &APIError{Err: err}
 	}
 	for n, v := range headers {
 		req.Header[n] = []string{v}
 	}
-	hClient := &http.Client{
-		Timeout: 30 * time.Second,
+	if c.compress {
+		req.Header.Set("Content-Encoding", "gzip")
 	}
-	resp, err := hClient.Do(req)
+	resp, err := c.httpClient.Do(req)
 	if err != nil {
-		return err
+		// timeouts and connection errors
+		return &APIError{Err: err, Retryable: true}
 	}
 	b, _ := io.ReadAll(resp.Body)
 	defer resp.Body.Close()
@@ -45,24 +170,120 @@ func (c *client) post(path string, body interface{}, headers map[string]string,
 			"response": string(b),
 			"status":   resp.StatusCode,
 		}).Error("alert api error")
-		return fmt.Errorf("%d error: %s", resp.StatusCode, string(b))
+		return &APIError{
+			StatusCode: resp.StatusCode,
+			Body:       string(b),
+			Retryable:  isRetryableStatus(resp.StatusCode),
+		}
 	}
 	return json.Unmarshal(b, target)
 }
 
+func gzipBytes(b []byte) ([]byte, error) {
+	var buf bytes.Buffer
+	zw := gzip.NewWriter(&buf)
+	if _, err := zw.Write(b); err != nil {
+		return nil, err
+	}
+	if err := zw.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
 func (c *client) PostBatch(batch *domain.AlertBatchRequest, token string) (*domain.AlertBatchResponse, error) {
 	path := fmt.Sprintf("/batch/%s", batch.Ref)
 	headers := map[string]string{
 		"content-type":  "application/json",
 		"Authorization": fmt.Sprintf("Bearer %s", token),
 	}
+	payload, err := CanonicalBatchPayload(batch)
+	if err != nil {
+		return nil, err
+	}
+	if c.signer != nil {
+		signature, err := SignBatchPayload(c.signer, payload)
+		if err != nil {
+			return nil, fmt.Errorf("failed to sign the batch request: %w", err)
+		}
+		headers[BatchSignatureHeader] = signature.Signature
+		headers[BatchSignerHeader] = signature.Signer
+	}
 	var resp domain.AlertBatchResponse
-	if err := c.post(path, batch, headers, &resp); err != nil {
+	if err := c.postJSON(path, payload, headers, &resp); err != nil {
 		return nil, err
 	}
 	return &resp, nil
 }
 
-func NewClient(apiUrl string) *client {
-	return &client{apiUrl: apiUrl}
+// SetBatchSigner sets the signer to sign the batch requests with.
+func (c *client) SetBatchSigner(s signer.Signer) {
+	c.signer = s
+}
+
+// Name returns the name of the client.
+func (c *client) Name() string {
+	return "alert-api"
+}
+
+// Health implements the health.Reporter interface.
+func (c *client) Health() health.Reports {
+	return health.Reports{
+		c.lastRetryCount.GetReport("alert-api.retries"),
+		c.lastErr.GetReport("alert-api.last-error"),
+	}
+}
+
+// CheckReachable checks if the alert API responds without a server error. The connection errors
+// and the server errors are retried with the retry config until the context is done.
+func CheckReachable(ctx context.Context, apiUrl string, retry RetryConfig) (err error) {
+	backoff := retry.InitialBackoff
+	for attempt := 1; ; attempt++ {
+		err = checkReachable(ctx, apiUrl)
+		if err == nil || !IsRetryable(err) || attempt >= retry.maxAttempts() {
+			return err
+		}
+		log.WithFields(log.Fields{
+			"apiUrl":  apiUrl,
+			"attempt": attempt,
+		}).WithError(err).Warn("alert api is not reachable - retrying")
+		select {
+		case <-ctx.Done():
+			return err
+		case <-time.After(retry.withJitter(backoff)):
+		}
+		backoff = retry.nextBackoff(backoff)
+	}
+}
+
+func checkReachable(ctx context.Context, apiUrl string) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, apiUrl, nil)
+	if err != nil {
+		return &APIError{Err: err}
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return &APIError{Err: err, Retryable: ctx.Err() == nil}
+	}
+	resp.Body.Close()
+	if resp.StatusCode >= 500 {
+		return &APIError{
+			StatusCode: resp.StatusCode,
+			Body:       "unexpected status code",
+			Retryable:  true,
+		}
+	}
+	return nil
+}
+
+func NewClient(apiUrl string, retry RetryConfig, compress bool) *client {
+	return &client{
+		apiUrl:   apiUrl,
+		retry:    retry,
+		compress: compress,
+		httpClient: &http.Client{
+			Timeout: 30 * time.Second,
+		},
+		sleep: time.Sleep,
+	}
 }
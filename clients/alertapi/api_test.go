@@ -0,0 +1,179 @@
+package alertapi
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/accounts/keystore"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/forta-network/forta-core-go/domain"
+	"github.com/forta-network/forta-node/clients/signer"
+	"github.com/stretchr/testify/require"
+)
+
+var testRetryConfig = RetryConfig{
+	MaxAttempts:    3,
+	InitialBackoff: time.Millisecond,
+	MaxBackoff:     time.Millisecond * 5,
+	Jitter:         0.5,
+}
+
+// scriptedServer responds with given status codes in order and succeeds after the script ends.
+func scriptedServer(statusCodes ...int) (*httptest.Server, *int32) {
+	var calls int32
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		call := int(atomic.AddInt32(&calls, 1))
+		if call <= len(statusCodes) {
+			w.WriteHeader(statusCodes[call-1])
+			w.Write([]byte("error"))
+			return
+		}
+		w.Write([]byte(`{"receiptId":"receipt1"}`))
+	})), &calls
+}
+
+func TestPostBatch_RetrySuccess(t *testing.T) {
+	r := require.New(t)
+
+	server, calls := scriptedServer(http.StatusServiceUnavailable, http.StatusTooManyRequests)
+	defer server.Close()
+
+	c := NewClient(server.URL, testRetryConfig, false)
+	resp, err := c.PostBatch(&domain.AlertBatchRequest{Ref: "ref1"}, "token")
+	r.NoError(err)
+	r.Equal("receipt1", resp.ReceiptID)
+	r.Equal(int32(3), atomic.LoadInt32(calls))
+	r.Equal(int64(2), c.retryCount.Load())
+}
+
+func TestPostBatch_RetryBudgetExceeded(t *testing.T) {
+	r := require.New(t)
+
+	server, calls := scriptedServer(http.StatusBadGateway, http.StatusBadGateway, http.StatusBadGateway)
+	defer server.Close()
+
+	c := NewClient(server.URL, testRetryConfig, false)
+	_, err := c.PostBatch(&domain.AlertBatchRequest{Ref: "ref1"}, "token")
+	r.Error(err)
+	r.True(IsRetryable(err))
+	r.Equal(int32(3), atomic.LoadInt32(calls))
+
+	var apiErr *APIError
+	r.ErrorAs(err, &apiErr)
+	r.Equal(http.StatusBadGateway, apiErr.StatusCode)
+}
+
+func TestPostBatch_PermanentError(t *testing.T) {
+	for _, statusCode := range []int{http.StatusBadRequest, http.StatusRequestEntityTooLarge} {
+		r := require.New(t)
+
+		server, calls := scriptedServer(statusCode)
+		c := NewClient(server.URL, testRetryConfig, false)
+		_, err := c.PostBatch(&domain.AlertBatchRequest{Ref: "ref1"}, "token")
+		server.Close()
+
+		r.Error(err)
+		r.False(IsRetryable(err))
+		r.Equal(int32(1), atomic.LoadInt32(calls))
+	}
+}
+
+func TestPostBatch_ConnectionError(t *testing.T) {
+	r := require.New(t)
+
+	server, _ := scriptedServer()
+	server.Close()
+
+	c := NewClient(server.URL, testRetryConfig, false)
+	_, err := c.PostBatch(&domain.AlertBatchRequest{Ref: "ref1"}, "token")
+	r.Error(err)
+	r.True(IsRetryable(err))
+	r.Equal(int64(2), c.retryCount.Load())
+}
+
+func TestPostBatch_Compress(t *testing.T) {
+	r := require.New(t)
+
+	var received domain.AlertBatchRequest
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		r.Equal("gzip", req.Header.Get("Content-Encoding"))
+		zr, err := gzip.NewReader(req.Body)
+		r.NoError(err)
+		r.NoError(json.NewDecoder(zr).Decode(&received))
+		w.Write([]byte(`{"receiptId":"receipt1"}`))
+	}))
+	defer server.Close()
+
+	c := NewClient(server.URL, testRetryConfig, true)
+	_, err := c.PostBatch(&domain.AlertBatchRequest{Ref: "ref1", AlertCount: 3}, "token")
+	r.NoError(err)
+	r.Equal("ref1", received.Ref)
+	r.Equal(int64(3), received.AlertCount)
+}
+
+func TestPostBatch_Signature(t *testing.T) {
+	r := require.New(t)
+
+	privateKey, err := crypto.GenerateKey()
+	r.NoError(err)
+	key := &keystore.Key{Address: crypto.PubkeyToAddress(privateKey.PublicKey), PrivateKey: privateKey}
+	batchSigner := signer.NewLocalSigner(key)
+
+	var (
+		payload   []byte
+		signature string
+		signer    string
+	)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		payload, _ = io.ReadAll(req.Body)
+		signature = req.Header.Get(BatchSignatureHeader)
+		signer = req.Header.Get(BatchSignerHeader)
+		w.Write([]byte(`{"receiptId":"receipt1"}`))
+	}))
+	defer server.Close()
+
+	c := NewClient(server.URL, testRetryConfig, false)
+	c.SetBatchSigner(batchSigner)
+	_, err = c.PostBatch(&domain.AlertBatchRequest{Ref: "ref1", AlertCount: 3}, "token")
+	r.NoError(err)
+	r.Equal(key.Address.Hex(), signer)
+	r.NoError(VerifyBatchSignature(payload, signature, signer))
+	r.NoError(VerifyBatchSignature(payload, signature, strings.ToLower(signer)))
+
+	r.ErrorIs(VerifyBatchSignature(payload, "", signer), ErrMissingBatchSignature)
+	r.ErrorIs(VerifyBatchSignature(payload, signature, "0x1234"), ErrInvalidSignerAddress)
+	tampered := bytes.Replace(payload, []byte(`"alertCount":3`), []byte(`"alertCount":4`), 1)
+	r.NotEqual(payload, tampered)
+	r.Error(VerifyBatchSignature(tampered, signature, signer))
+}
+
+func TestCheckReachable(t *testing.T) {
+	r := require.New(t)
+
+	server, calls := scriptedServer(http.StatusServiceUnavailable, http.StatusBadGateway)
+	defer server.Close()
+	r.NoError(CheckReachable(context.Background(), server.URL, testRetryConfig))
+	r.Equal(int32(3), atomic.LoadInt32(calls))
+
+	// a client error still means that the api is reachable
+	server, calls = scriptedServer(http.StatusNotFound)
+	defer server.Close()
+	r.NoError(CheckReachable(context.Background(), server.URL, testRetryConfig))
+	r.Equal(int32(1), atomic.LoadInt32(calls))
+
+	server, calls = scriptedServer(http.StatusBadGateway, http.StatusBadGateway, http.StatusBadGateway)
+	defer server.Close()
+	err := CheckReachable(context.Background(), server.URL, testRetryConfig)
+	r.Error(err)
+	r.True(IsRetryable(err))
+	r.Equal(int32(3), atomic.LoadInt32(calls))
+}
@@ -0,0 +1,49 @@
+package alertapi
+
+import (
+	"errors"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/forta-network/forta-core-go/domain"
+	"github.com/forta-network/forta-core-go/protocol"
+	"github.com/forta-network/forta-core-go/security"
+	"github.com/forta-network/forta-node/clients/signer"
+	"github.com/goccy/go-json"
+)
+
+// Batch request signature headers
+const (
+	BatchSignatureHeader = "X-Forta-Batch-Signature"
+	BatchSignerHeader    = "X-Forta-Batch-Signer"
+)
+
+// Signature errors
+var (
+	ErrMissingBatchSignature = errors.New("missing batch signature")
+	ErrInvalidSignerAddress  = errors.New("invalid batch signer address")
+)
+
+// CanonicalBatchPayload returns the JSON encoding of the batch request. This is the exact
+// request body that is signed and sent, before any compression.
+func CanonicalBatchPayload(batch *domain.AlertBatchRequest) ([]byte, error) {
+	return json.Marshal(batch)
+}
+
+// SignBatchPayload signs the canonical batch payload.
+func SignBatchPayload(s signer.Signer, payload []byte) (*protocol.Signature, error) {
+	return signer.SignBytes(s, payload)
+}
+
+// VerifyBatchSignature verifies that the payload was signed by the given address. The payload
+// should be the uncompressed request body and the signature should be the value of the
+// batch signature header.
+func VerifyBatchSignature(payload []byte, signature string, address string) error {
+	if len(signature) == 0 {
+		return ErrMissingBatchSignature
+	}
+	if !common.IsHexAddress(address) {
+		return ErrInvalidSignerAddress
+	}
+	// the signer is compared in the checksummed form
+	return security.VerifySignature(payload, common.HexToAddress(address).Hex(), signature)
+}
@@ -8,7 +8,9 @@ import (
 	"errors"
 	"fmt"
 	"io"
+	"os"
 	"path"
+	"strconv"
 	"strings"
 	"time"
 
@@ -18,7 +20,9 @@ import (
 	"github.com/docker/docker/api/types/container"
 	"github.com/docker/docker/api/types/filters"
 	"github.com/docker/docker/client"
+	"github.com/docker/docker/pkg/stdcopy"
 	"github.com/docker/go-connections/nat"
+	"github.com/forta-network/forta-core-go/utils"
 	"github.com/forta-network/forta-core-go/utils/workers"
 	"github.com/forta-network/forta-node/config"
 	log "github.com/sirupsen/logrus"
@@ -28,6 +32,9 @@ const (
 	DockerLabelForta                          = "network.forta"
 	DockerLabelFortaSupervisor                = "network.forta.supervisor"
 	DockerLabelFortaSupervisorStrategyVersion = "network.forta.supervisor.strategy-version"
+	DockerLabelFortaInstance                  = "network.forta.instance"
+	DockerLabelFortaAgentNetworkPolicy        = "network.forta.agent.network-policy"
+	DockerLabelFortaAgentID                   = "network.forta.agent.id"
 
 	DockerLabelFortaSettingsAgentLogsEnable = "network.forta.settings.agent-logs.enable"
 )
@@ -43,9 +50,16 @@ var defaultLabels = []dockerLabel{
 
 // Client errors
 var (
-	ErrContainerNotFound = errors.New("container not found")
+	ErrContainerNotFound     = errors.New("container not found")
+	ErrCorruptImage          = errors.New("corrupt local image")
+	ErrImageDigestMismatch   = fmt.Errorf("%w: digest mismatch", ErrCorruptImage)
+	ErrContainerStartTimeout = errors.New("container did not start in time")
 )
 
+// DefaultContainerStartTimeout is how long to wait for a container to start if the context has
+// no deadline.
+const DefaultContainerStartTimeout = time.Minute
+
 // DockerContainer is a resulting container reference, including the ID and configuration
 type DockerContainer struct {
 	Name      string
@@ -67,6 +81,8 @@ type DockerContainerConfig struct {
 	Files           map[string][]byte
 	MaxLogSize      string
 	MaxLogFiles     int
+	LogDriver       string
+	LogOpts         map[string]string
 	CPUQuota        int64
 	Memory          int64
 	Cmd             []string
@@ -74,6 +90,39 @@ type DockerContainerConfig struct {
 	Labels          map[string]string
 }
 
+// logConfig returns the log driver config. The rotation settings are used only with the
+// drivers which write to the local files.
+func (config DockerContainerConfig) logConfig() container.LogConfig {
+	driver := config.LogDriver
+	if driver == "" {
+		driver = "json-file"
+	}
+
+	opts := make(map[string]string)
+	if driver == "json-file" || driver == "local" {
+		maxLogSize := config.MaxLogSize
+		if maxLogSize == "" {
+			maxLogSize = "10m"
+		}
+
+		maxLogFiles := config.MaxLogFiles
+		if maxLogFiles == 0 {
+			maxLogFiles = 10
+		}
+
+		opts["max-file"] = fmt.Sprintf("%d", maxLogFiles)
+		opts["max-size"] = maxLogSize
+	}
+	for k, v := range config.LogOpts {
+		opts[k] = v
+	}
+
+	return container.LogConfig{
+		Type:   driver,
+		Config: opts,
+	}
+}
+
 // DockerContainerList contains the full container data.
 type DockerContainerList []types.Container
 
@@ -109,12 +158,29 @@ func (dcl DockerContainerList) ContainsAny(name string) (*types.Container, bool)
 	return nil, false
 }
 
+// RunningHostPorts returns the host ports which are published by the running containers.
+func (dcl DockerContainerList) RunningHostPorts() map[string]bool {
+	ports := make(map[string]bool)
+	for _, c := range dcl {
+		if c.State != "running" {
+			continue
+		}
+		for _, port := range c.Ports {
+			if port.PublicPort > 0 {
+				ports[strconv.Itoa(int(port.PublicPort))] = true
+			}
+		}
+	}
+	return ports
+}
+
 type dockerClient struct {
-	cli      *client.Client
-	workers  *workers.Group
-	username string
-	password string
-	labels   []dockerLabel
+	cli     *client.Client
+	workers *workers.Group
+	auth    RegistryAuthProvider
+	labels  []dockerLabel
+	// instance is the node instance which the containers and the networks belong to
+	instance string
 }
 
 func (cfg DockerContainerConfig) envVars() []string {
@@ -138,32 +204,123 @@ func registryAuthValue(username, password string) string {
 
 // PullImage pulls an image using the given ref.
 func (d *dockerClient) PullImage(ctx context.Context, refStr string) error {
-	return d.workers.Execute(func() ([]interface{}, error) {
-		return nil, d.pullImage(ctx, refStr)
-	}).Error
+	_, err := d.executePull(ctx, refStr)
+	return err
 }
 
-func (d *dockerClient) pullImage(ctx context.Context, refStr string) error {
+// executePull pulls the image in a worker and returns the number of the downloaded bytes.
+func (d *dockerClient) executePull(ctx context.Context, refStr string) (int64, error) {
+	output := d.workers.Execute(func() ([]interface{}, error) {
+		size, err := d.pullImage(ctx, refStr)
+		return []interface{}{size}, err
+	})
+	if output.Error != nil {
+		return 0, output.Error
+	}
+	return output.Values[0].(int64), nil
+}
+
+func (d *dockerClient) pullImage(ctx context.Context, refStr string) (int64, error) {
+	var registryAuth string
+	if authAppliesTo(d.auth, refStr) {
+		// get fresh credentials in case the token expired
+		creds, err := d.auth.Credentials(ctx)
+		if err != nil {
+			return 0, err
+		}
+		registryAuth = registryAuthValue(creds.Username, creds.Password)
+	}
 	r, err := d.cli.ImagePull(ctx, refStr, types.ImagePullOptions{
-		RegistryAuth: registryAuthValue(d.username, d.password),
+		RegistryAuth: registryAuth,
 	})
 	if err != nil {
-		return err
+		return 0, err
 	}
 	defer r.Close()
-	b, err := io.ReadAll(r)
-	if err != nil {
-		return err
-	}
-	respStr := strings.ToLower(string(b))
-	if strings.Contains(respStr, "downloaded") || strings.Contains(respStr, "up to date") {
-		return nil
-	}
-	return fmt.Errorf("unexpected image pull response: %s", string(b))
+	return readPullProgress(log.WithField("image", refStr), r)
+}
+
+// pullMessage is a message from the image pull progress stream.
+type pullMessage struct {
+	ID             string `json:"id"`
+	Status         string `json:"status"`
+	ProgressDetail struct {
+		Total int64 `json:"total"`
+	} `json:"progressDetail"`
+	ErrorDetail *struct {
+		Message string `json:"message"`
+	} `json:"errorDetail"`
+}
+
+// readPullProgress reads the pull progress stream, logs the layer status changes and returns the
+// size of the downloaded layers. Docker only downloads the layers which do not exist locally so an
+// interrupted pull is resumed.
+func readPullProgress(logger *log.Entry, r io.Reader) (int64, error) {
+	var (
+		layerStatus = make(map[string]string)
+		layerSizes  = make(map[string]int64)
+		lastStatus  string
+		existing    int
+		downloaded  int
+	)
+	dec := json.NewDecoder(r)
+	for {
+		var msg pullMessage
+		err := dec.Decode(&msg)
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return 0, fmt.Errorf("failed to read the image pull progress: %v", err)
+		}
+		if msg.ErrorDetail != nil {
+			return 0, fmt.Errorf("image pull failed: %s", msg.ErrorDetail.Message)
+		}
+		if len(msg.ID) == 0 || strings.HasPrefix(msg.Status, "Pulling from") {
+			lastStatus = msg.Status
+			continue
+		}
+		if msg.Status == "Downloading" && msg.ProgressDetail.Total > 0 {
+			layerSizes[msg.ID] = msg.ProgressDetail.Total
+		}
+		// skip the progress updates which do not change the layer status
+		if layerStatus[msg.ID] == msg.Status {
+			continue
+		}
+		layerStatus[msg.ID] = msg.Status
+		switch msg.Status {
+		case "Already exists":
+			existing++
+		case "Pull complete":
+			downloaded++
+		case "Downloading", "Extracting", "Verifying Checksum", "Download complete", "Waiting", "Pulling fs layer":
+		default:
+			continue
+		}
+		logger.WithFields(log.Fields{
+			"layer":  msg.ID,
+			"status": msg.Status,
+		}).Debug("image pull progress")
+	}
+
+	status := strings.ToLower(lastStatus)
+	if !strings.Contains(status, "downloaded") && !strings.Contains(status, "up to date") {
+		return 0, fmt.Errorf("unexpected image pull response: %s", lastStatus)
+	}
+	var size int64
+	for _, layerSize := range layerSizes {
+		size += layerSize
+	}
+	logger.WithFields(log.Fields{
+		"existingLayers":   existing,
+		"downloadedLayers": downloaded,
+		"downloadedBytes":  size,
+	}).Info("image pull complete")
+	return size, nil
 }
 
 func (d *dockerClient) Prune(ctx context.Context) error {
-	filter := d.labelFilter()
+	filter := d.pruneFilter()
 	res, err := d.cli.NetworksPrune(ctx, filter)
 	if err != nil {
 		return err
@@ -274,20 +431,27 @@ func copyFile(cli *client.Client, ctx context.Context, filePath string, content
 
 // GetContainers returns all of the containers.
 func (d *dockerClient) GetContainers(ctx context.Context) (DockerContainerList, error) {
-	return d.cli.ContainerList(ctx, types.ContainerListOptions{
+	containers, err := d.cli.ContainerList(ctx, types.ContainerListOptions{
 		All:     true,
 		Filters: d.labelFilter(),
 	})
+	if err != nil {
+		return nil, err
+	}
+	return d.instanceContainers(containers), nil
+}
+
+// GetAllContainers returns all of the containers on the host, including the ones which do not
+// belong to the node.
+func (d *dockerClient) GetAllContainers(ctx context.Context) (DockerContainerList, error) {
+	return d.cli.ContainerList(ctx, types.ContainerListOptions{All: true})
 }
 
 // GetFortaServiceContainers returns all of the non-agent forta containers.
 func (d *dockerClient) GetFortaServiceContainers(ctx context.Context) (fortaContainers DockerContainerList, err error) {
-	containers, err := d.cli.ContainerList(ctx, types.ContainerListOptions{
-		All:     true,
-		Filters: d.labelFilter(),
-	})
+	containers, err := d.GetContainers(ctx)
 	for _, container := range containers {
-		if !strings.Contains(container.Names[0][1:], "forta-agent") {
+		if !strings.HasPrefix(container.Names[0][1:], config.DockerAgentContainerNamePrefix) {
 			fortaContainers = append(fortaContainers, container)
 		}
 	}
@@ -360,7 +524,7 @@ func (d *dockerClient) nuke(ctx context.Context) error {
 
 	// step 2: stop all and wait until each exit
 	for _, container := range containers {
-		if err := d.StopContainer(ctx, container.ID); err != nil {
+		if err := d.StopContainer(ctx, container.ID, 0); err != nil {
 			return fmt.Errorf("failed to stop: %v", err)
 		}
 		if err := d.WaitContainerExit(ctx, container.ID); err != nil {
@@ -442,16 +606,6 @@ func (d *dockerClient) StartContainer(ctx context.Context, config DockerContaine
 		volumes = append(volumes, fmt.Sprintf("%s:%s", hostVol, containerMnt))
 	}
 
-	maxLogSize := config.MaxLogSize
-	if maxLogSize == "" {
-		maxLogSize = "10m"
-	}
-
-	maxLogFiles := config.MaxLogFiles
-	if maxLogFiles == 0 {
-		maxLogFiles = 10
-	}
-
 	cntCfg := &container.Config{
 		Image:  config.Image,
 		Env:    config.envVars(),
@@ -471,13 +625,7 @@ func (d *dockerClient) StartContainer(ctx context.Context, config DockerContaine
 		PortBindings:    bindings,
 		PublishAllPorts: config.PublishAllPorts,
 		Binds:           volumes,
-		LogConfig: container.LogConfig{
-			Config: map[string]string{
-				"max-file": fmt.Sprintf("%d", maxLogFiles),
-				"max-size": maxLogSize,
-			},
-			Type: "json-file",
-		},
+		LogConfig:       config.logConfig(),
 		Resources: container.Resources{
 			CPUQuota: config.CPUQuota,
 			Memory:   config.Memory,
@@ -526,8 +674,12 @@ func (d *dockerClient) StartContainer(ctx context.Context, config DockerContaine
 	return &DockerContainer{Name: config.Name, ID: cont.ID, Config: config, ImageHash: inspection.Image}, nil
 }
 
-// StopContainer kills a container by ID
-func (d *dockerClient) StopContainer(ctx context.Context, id string) error {
+// StopContainer kills a container by ID. If a timeout is given, it stops the container gracefully
+// and kills it after the timeout.
+func (d *dockerClient) StopContainer(ctx context.Context, id string, timeout time.Duration) error {
+	if timeout > 0 {
+		return d.stopContainerWithTimeout(ctx, id, timeout)
+	}
 	return d.stopContainer(ctx, id, "SIGKILL")
 }
 
@@ -536,11 +688,31 @@ func (d *dockerClient) InterruptContainer(ctx context.Context, id string) error
 	return d.stopContainer(ctx, id, "SIGINT")
 }
 
-// TerminateContainer stops a container by sending an termination signal.
-func (d *dockerClient) TerminateContainer(ctx context.Context, id string) error {
+// TerminateContainer stops a container by sending an termination signal. If a timeout is given,
+// it waits for the container to exit and kills it after the timeout.
+func (d *dockerClient) TerminateContainer(ctx context.Context, id string, timeout time.Duration) error {
+	if timeout > 0 {
+		return d.stopContainerWithTimeout(ctx, id, timeout)
+	}
 	return d.stopContainer(ctx, id, "SIGTERM")
 }
 
+// stopContainerWithTimeout sends the stop signal and kills the container if it does not exit in time.
+func (d *dockerClient) stopContainerWithTimeout(ctx context.Context, containerID string, timeout time.Duration) error {
+	log.WithFields(log.Fields{
+		"id":      containerID,
+		"timeout": timeout.String(),
+	}).Infof("stopping container")
+	err := d.cli.ContainerStop(ctx, containerID, &timeout)
+	if err == nil {
+		return nil
+	}
+	if isNoSuchContainerErr(err) || isNotRunningErr(err) {
+		return nil
+	}
+	return err
+}
+
 // TerminateContainer stops a container by sending an termination signal.
 func (d *dockerClient) stopContainer(ctx context.Context, containerID, signal string) error {
 	log.WithFields(log.Fields{
@@ -601,18 +773,32 @@ func (d *dockerClient) WaitContainerExit(ctx context.Context, id string) error {
 	}
 }
 
-// WaitContainerStart waits for container start by checking periodically.
+// WaitContainerStart waits for container start by checking periodically. It waits until the
+// context deadline or for the default timeout if the context has no deadline.
 func (d *dockerClient) WaitContainerStart(ctx context.Context, id string) error {
 	ticker := time.NewTicker(time.Second)
+	defer ticker.Stop()
 	start := time.Now()
 	logger := log.WithFields(log.Fields{
 		"id": id,
 	})
 
-	ctx, cancel := context.WithTimeout(ctx, time.Minute)
-	defer cancel()
+	if _, ok := ctx.Deadline(); !ok {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, DefaultContainerStartTimeout)
+		defer cancel()
+	}
 
-	for t := range ticker.C {
+	var state string
+	for {
+		select {
+		case <-ctx.Done():
+			if !errors.Is(ctx.Err(), context.DeadlineExceeded) {
+				return ctx.Err()
+			}
+			return fmt.Errorf("%w: waited for %s (last state: %s)", ErrContainerStartTimeout, time.Since(start).Round(time.Second), orUnknown(state))
+		case <-ticker.C:
+		}
 		logger.Info("waiting for container start")
 		c, err := d.GetContainerByID(ctx, id)
 		if err == nil && c != nil && c.State == "running" {
@@ -620,14 +806,22 @@ func (d *dockerClient) WaitContainerStart(ctx context.Context, id string) error
 			return nil
 		}
 		if err != nil {
+			if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+				continue // report the timeout
+			}
 			return err
 		}
-		// if the conditions are not met within 30 seconds, it's a failure
-		if t.After(start.Add(time.Second * 30)) {
-			return errors.New("container did not start")
+		if c != nil {
+			state = c.State
 		}
 	}
-	return nil
+}
+
+func orUnknown(s string) string {
+	if len(s) == 0 {
+		return "unknown"
+	}
+	return s
 }
 
 // WaitContainerPrune waits for container prune by checking periodically.
@@ -660,27 +854,98 @@ func (d *dockerClient) WaitContainerPrune(ctx context.Context, id string) error
 	}
 }
 
+// GetDockerRootDir returns the data root directory of the docker daemon.
+func (d *dockerClient) GetDockerRootDir(ctx context.Context) (string, error) {
+	info, err := d.cli.Info(ctx)
+	if err != nil {
+		return "", err
+	}
+	return info.DockerRootDir, nil
+}
+
 // HasLocalImage checks if we have an image locally.
 func (d *dockerClient) HasLocalImage(ctx context.Context, ref string) bool {
 	_, _, err := d.cli.ImageInspectWithRaw(ctx, ref)
 	return err == nil
 }
 
-// EnsureLocalImage ensures that we have the image locally.
+// checkLocalImage checks if the local image is complete and has the digest from the ref.
+func checkLocalImage(image types.ImageInspect, ref string) error {
+	if len(image.RootFS.Layers) == 0 {
+		return fmt.Errorf("%w: no layers", ErrCorruptImage)
+	}
+	_, expectedDigest := utils.SplitImageRef(ref)
+	if len(expectedDigest) == 0 {
+		return nil
+	}
+	for _, repoDigest := range image.RepoDigests {
+		if _, digest := utils.SplitImageRef(repoDigest); strings.EqualFold(digest, expectedDigest) {
+			return nil
+		}
+	}
+	return fmt.Errorf("%w: expected sha256:%s, found %v", ErrImageDigestMismatch, expectedDigest, image.RepoDigests)
+}
+
+func (d *dockerClient) verifyLocalImage(ctx context.Context, ref string) error {
+	image, _, err := d.cli.ImageInspectWithRaw(ctx, ref)
+	if err != nil {
+		return err
+	}
+	return checkLocalImage(image, ref)
+}
+
+// RemoveImage removes a local image. The images which are used by any container are not removed.
+func (d *dockerClient) RemoveImage(ctx context.Context, ref string) error {
+	_, err := d.cli.ImageRemove(ctx, ref, types.ImageRemoveOptions{})
+	return err
+}
+
+// GetImages returns all of the local images.
+func (d *dockerClient) GetImages(ctx context.Context) ([]types.ImageSummary, error) {
+	return d.cli.ImageList(ctx, types.ImageListOptions{})
+}
+
+// EnsureLocalImage ensures that we have a complete image locally. A local image is removed
+// before pulling again only if its digest does not match the ref.
 func (d *dockerClient) EnsureLocalImage(ctx context.Context, name, ref string) error {
-	log.WithFields(log.Fields{
+	logger := log.WithFields(log.Fields{
 		"image": ref,
 		"name":  name,
-	}).Info("ensuring local image")
+	})
+	logger.Info("ensuring local image")
 	if d.HasLocalImage(ctx, ref) {
-		log.Infof("found local image for '%s': %s", name, ref)
-		return nil
+		err := d.verifyLocalImage(ctx, ref)
+		if err == nil {
+			log.Infof("found local image for '%s': %s", name, ref)
+			return nil
+		}
+		// do not remove the image because of a transient error
+		if !errors.Is(err, ErrImageDigestMismatch) {
+			logger.WithError(err).Warn("failed to verify the local image - pulling again")
+		} else {
+			logger.WithError(err).Warn("local image has another digest - removing and pulling again")
+			if err := d.RemoveImage(ctx, ref); err != nil {
+				return fmt.Errorf("failed to remove the corrupt image: %v", err)
+			}
+		}
 	}
 
 	ticker := time.NewTicker(time.Minute)
 
+	var pulledBytes int64
+	pullStart := time.Now()
 	for {
-		err := d.PullImage(ctx, ref)
+		size, err := d.limitedPullImage(ctx, ref)
+		pulledBytes += size
+		if err == nil {
+			err = d.verifyLocalImage(ctx, ref)
+			// start clean in the next attempt
+			if errors.Is(err, ErrImageDigestMismatch) {
+				if rmErr := d.RemoveImage(ctx, ref); rmErr != nil {
+					logger.WithError(rmErr).Warn("failed to remove the corrupt image")
+				}
+			}
+		}
 		if err == nil {
 			break
 		}
@@ -697,10 +962,62 @@ func (d *dockerClient) EnsureLocalImage(ctx context.Context, name, ref string) e
 		}
 	}
 
+	observeImagePull(name, time.Since(pullStart), pulledBytes)
 	log.Infof("pulled image for '%s': %s", name, ref)
 	return nil
 }
 
+// limitedPullImage pulls the image after acquiring a slot from the pull limiter and returns the
+// number of the downloaded bytes.
+func (d *dockerClient) limitedPullImage(ctx context.Context, ref string) (int64, error) {
+	release, err := acquirePullSlot(ctx)
+	if err != nil {
+		return 0, err
+	}
+	defer release()
+	return d.executePull(ctx, ref)
+}
+
+// GetImageDigests returns the repo digests of a local image.
+func (d *dockerClient) GetImageDigests(ctx context.Context, ref string) ([]string, error) {
+	image, _, err := d.cli.ImageInspectWithRaw(ctx, ref)
+	if err != nil {
+		return nil, err
+	}
+	return image.RepoDigests, nil
+}
+
+// ContainerLogsOptions selects the container logs to stream.
+type ContainerLogsOptions struct {
+	// Since is a timestamp or a relative time like 10m.
+	Since string
+	// Tail is the number of lines from the end of the logs or "all".
+	Tail string
+	// Follow keeps streaming the new logs.
+	Follow bool
+}
+
+// StreamContainerLogs streams the container logs to the writers. If following, it streams until
+// the context is done or the container stops.
+func (d *dockerClient) StreamContainerLogs(ctx context.Context, containerID string, opts ContainerLogsOptions, stdout, stderr io.Writer) error {
+	r, err := d.cli.ContainerLogs(ctx, containerID, types.ContainerLogsOptions{
+		ShowStdout: true,
+		ShowStderr: true,
+		Follow:     opts.Follow,
+		Since:      opts.Since,
+		Tail:       opts.Tail,
+	})
+	if err != nil {
+		return err
+	}
+	defer r.Close()
+	_, err = stdcopy.StdCopy(stdout, stderr, r)
+	if ctx.Err() != nil {
+		return nil
+	}
+	return err
+}
+
 // GetContainerLogs gets the container logs.
 func (d *dockerClient) GetContainerLogs(ctx context.Context, containerID, tail string, truncate int) (string, error) {
 	r, err := d.cli.ContainerLogs(ctx, containerID, types.ContainerLogsOptions{
@@ -742,15 +1059,41 @@ func (d *dockerClient) labelFilter() filters.Args {
 	return filter
 }
 
-func initLabels(name string) []dockerLabel {
-	if len(name) == 0 {
-		return defaultLabels
+// pruneFilter matches the containers and the networks of this instance. The default instance
+// did not always label its resources so it matches everything without an instance label.
+func (d *dockerClient) pruneFilter() filters.Args {
+	filter := d.labelFilter()
+	if len(d.instance) == 0 {
+		filter.Add("label!", DockerLabelFortaInstance)
 	}
+	return filter
+}
 
-	return append(defaultLabels, dockerLabel{
-		Name:  DockerLabelFortaSupervisor,
-		Value: name,
-	})
+// instanceContainers filters out the containers of the other instances.
+func (d *dockerClient) instanceContainers(containers []types.Container) (results DockerContainerList) {
+	for _, container := range containers {
+		if container.Labels[DockerLabelFortaInstance] == d.instance {
+			results = append(results, container)
+		}
+	}
+	return
+}
+
+func initLabels(name, instance string) []dockerLabel {
+	labels := append([]dockerLabel{}, defaultLabels...)
+	if len(name) > 0 {
+		labels = append(labels, dockerLabel{
+			Name:  DockerLabelFortaSupervisor,
+			Value: name,
+		})
+	}
+	if len(instance) > 0 {
+		labels = append(labels, dockerLabel{
+			Name:  DockerLabelFortaInstance,
+			Value: instance,
+		})
+	}
+	return labels
 }
 
 func labelsToMap(labels []dockerLabel) map[string]string {
@@ -761,16 +1104,37 @@ func labelsToMap(labels []dockerLabel) map[string]string {
 	return m
 }
 
+// DockerClientOption configures the connection to the docker daemon.
+type DockerClientOption = func(*client.Client) error
+
+// WithSocketPath makes the docker client connect to the daemon from the socket path.
+func WithSocketPath(socketPath string) DockerClientOption {
+	return client.WithHost("unix://" + socketPath)
+}
+
+// CheckDockerSocket checks if the docker daemon socket exists at the path.
+func CheckDockerSocket(socketPath string) error {
+	info, err := os.Stat(socketPath)
+	if err != nil {
+		return fmt.Errorf("docker socket not found at %s - please set docker.socketPath in the config if the docker daemon uses another socket (e.g. rootless docker): %v", socketPath, err)
+	}
+	if info.Mode()&os.ModeSocket == 0 {
+		return fmt.Errorf("%s is not a socket - please set docker.socketPath in the config to the docker daemon socket", socketPath)
+	}
+	return nil
+}
+
 // NewDockerClient creates a new docker client
-func NewDockerClient(name string) (*dockerClient, error) {
-	cli, err := client.NewClientWithOpts()
+func NewDockerClient(name string, opts ...DockerClientOption) (*dockerClient, error) {
+	cli, err := client.NewClientWithOpts(opts...)
 	if err != nil {
 		return nil, err
 	}
 	return &dockerClient{
-		cli:     cli,
-		workers: workers.New(10),
-		labels:  initLabels(name),
+		cli:      cli,
+		workers:  workers.New(10),
+		labels:   initLabels(name, config.InstanceName()),
+		instance: config.InstanceName(),
 	}, nil
 }
 
@@ -779,15 +1143,21 @@ func NewAuthDockerClient(name string, username, password string) (*dockerClient,
 	if len(username) == 0 && len(password) == 0 {
 		return NewDockerClient(name)
 	}
-	cli, err := client.NewClientWithOpts()
+	return NewDockerClientWithAuth(name, NewBasicAuthProvider(username, password))
+}
+
+// NewDockerClientWithAuth creates a new docker client which gets the credentials from the provider
+// before each pull.
+func NewDockerClientWithAuth(name string, auth RegistryAuthProvider, opts ...DockerClientOption) (*dockerClient, error) {
+	cli, err := client.NewClientWithOpts(opts...)
 	if err != nil {
 		return nil, err
 	}
 	return &dockerClient{
 		cli:      cli,
 		workers:  workers.New(10),
-		username: username,
-		password: password,
-		labels:   initLabels(name),
+		auth:     auth,
+		labels:   initLabels(name, config.InstanceName()),
+		instance: config.InstanceName(),
 	}, nil
 }
@@ -0,0 +1,208 @@
+package clients
+
+import (
+	"context"
+	"encoding/json"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/client"
+	log "github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDockerContainerConfig_LogConfig(t *testing.T) {
+	r := require.New(t)
+
+	logCfg := DockerContainerConfig{}.logConfig()
+	r.Equal("json-file", logCfg.Type)
+	r.Equal(map[string]string{"max-file": "10", "max-size": "10m"}, logCfg.Config)
+
+	logCfg = DockerContainerConfig{
+		MaxLogSize:  "50m",
+		MaxLogFiles: 5,
+		LogDriver:   "gelf",
+		LogOpts:     map[string]string{"gelf-address": "udp://1.2.3.4:12201"},
+	}.logConfig()
+	r.Equal("gelf", logCfg.Type)
+	r.Equal(map[string]string{"gelf-address": "udp://1.2.3.4:12201"}, logCfg.Config)
+
+	logCfg = DockerContainerConfig{
+		MaxLogSize: "50m",
+		LogDriver:  "local",
+		LogOpts:    map[string]string{"compress": "true"},
+	}.logConfig()
+	r.Equal("local", logCfg.Type)
+	r.Equal(map[string]string{"max-file": "10", "max-size": "50m", "compress": "true"}, logCfg.Config)
+}
+
+const (
+	testImageDigest = "1111111111111111111111111111111111111111111111111111111111111111"
+	testImageRef    = "disco.forta.network/bafybeielvnt5apaxbk6chthc4dc3p6vscpx3ai4uvti7gwh253j7facsxu@sha256:" + testImageDigest
+)
+
+func TestCheckLocalImage(t *testing.T) {
+	r := require.New(t)
+
+	var image types.ImageInspect
+	image.RepoDigests = []string{testImageRef}
+	err := checkLocalImage(image, testImageRef)
+	r.ErrorIs(err, ErrCorruptImage)
+	r.NotErrorIs(err, ErrImageDigestMismatch)
+
+	image.RootFS.Layers = []string{"sha256:layer"}
+	r.NoError(checkLocalImage(image, testImageRef))
+	r.NoError(checkLocalImage(image, "some-image:latest"))
+
+	image.RepoDigests = []string{"disco.forta.network/other@sha256:2222222222222222222222222222222222222222222222222222222222222222"}
+	r.ErrorIs(checkLocalImage(image, testImageRef), ErrImageDigestMismatch)
+}
+
+func TestReadPullProgress(t *testing.T) {
+	r := require.New(t)
+
+	logger := log.NewEntry(log.StandardLogger())
+
+	// resumed pull: one layer exists
+	size, err := readPullProgress(logger, strings.NewReader(`
+{"status":"Pulling from bafybeielvnt5apaxbk6chthc4dc3p6vscpx3ai4uvti7gwh253j7facsxu","id":"latest"}
+{"status":"Already exists","id":"a1"}
+{"status":"Pulling fs layer","id":"b2"}
+{"status":"Downloading","progressDetail":{"current":1,"total":2},"id":"b2"}
+{"status":"Downloading","progressDetail":{"current":2,"total":2},"id":"b2"}
+{"status":"Pull complete","id":"b2"}
+{"status":"Digest: sha256:1111111111111111111111111111111111111111111111111111111111111111"}
+{"status":"Status: Downloaded newer image for disco.forta.network/bafybeielvnt5apaxbk6chthc4dc3p6vscpx3ai4uvti7gwh253j7facsxu"}
+`))
+	r.NoError(err)
+	r.Equal(int64(2), size)
+
+	size, err = readPullProgress(logger, strings.NewReader(`
+{"status":"Status: Image is up to date for disco.forta.network/bafybeielvnt5apaxbk6chthc4dc3p6vscpx3ai4uvti7gwh253j7facsxu"}
+`))
+	r.NoError(err)
+	r.Zero(size)
+
+	// interrupted pull
+	_, err = readPullProgress(logger, strings.NewReader(`
+{"status":"Pulling fs layer","id":"b2"}
+{"errorDetail":{"message":"unexpected EOF"},"error":"unexpected EOF"}
+`))
+	r.Error(err)
+	_, err = readPullProgress(logger, strings.NewReader(`
+{"status":"Pulling fs layer","id":"b2"}
+`))
+	r.Error(err)
+}
+
+func TestInitLabels(t *testing.T) {
+	r := require.New(t)
+
+	r.Equal(map[string]string{DockerLabelForta: "true"}, labelsToMap(initLabels("", "")))
+	r.Equal(map[string]string{
+		DockerLabelForta:           "true",
+		DockerLabelFortaSupervisor: "supervisor",
+		DockerLabelFortaInstance:   "node2",
+	}, labelsToMap(initLabels("supervisor", "node2")))
+	// the default labels are not modified
+	r.Len(defaultLabels, 1)
+}
+
+func TestDockerClient_InstanceScope(t *testing.T) {
+	r := require.New(t)
+
+	containers := []types.Container{
+		{ID: "1", Labels: map[string]string{DockerLabelForta: "true"}},
+		{ID: "2", Labels: map[string]string{DockerLabelForta: "true", DockerLabelFortaInstance: "node2"}},
+		{ID: "3", Labels: map[string]string{DockerLabelForta: "true", DockerLabelFortaInstance: "node3"}},
+	}
+
+	defaultClient := &dockerClient{labels: initLabels("", "")}
+	found := defaultClient.instanceContainers(containers)
+	r.Len(found, 1)
+	r.Equal("1", found[0].ID)
+	filter := defaultClient.pruneFilter()
+	r.True(filter.ExactMatch("label", DockerLabelForta+"=true"))
+	r.Equal([]string{DockerLabelFortaInstance}, filter.Get("label!"))
+
+	instanceClient := &dockerClient{labels: initLabels("", "node2"), instance: "node2"}
+	found = instanceClient.instanceContainers(containers)
+	r.Len(found, 1)
+	r.Equal("2", found[0].ID)
+	filter = instanceClient.pruneFilter()
+	r.True(filter.ExactMatch("label", DockerLabelFortaInstance+"=node2"))
+	r.Empty(filter.Get("label!"))
+}
+
+func TestCheckDockerSocket(t *testing.T) {
+	r := require.New(t)
+
+	dir := t.TempDir()
+	socketPath := path.Join(dir, "docker.sock")
+	r.Error(CheckDockerSocket(socketPath))
+
+	// not a socket
+	r.NoError(os.WriteFile(socketPath, []byte{}, 0644))
+	r.Error(CheckDockerSocket(socketPath))
+	r.NoError(os.Remove(socketPath))
+
+	listener, err := net.Listen("unix", socketPath)
+	r.NoError(err)
+	defer listener.Close()
+	r.NoError(CheckDockerSocket(socketPath))
+}
+
+func testDockerAPIClient(t *testing.T, containers func() []types.Container) *dockerClient {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(containers())
+	}))
+	t.Cleanup(server.Close)
+	cli, err := client.NewClientWithOpts(client.WithHost("tcp://"+server.Listener.Addr().String()), client.WithVersion("1.37"))
+	require.NoError(t, err)
+	return &dockerClient{cli: cli, labels: initLabels("", "")}
+}
+
+func TestWaitContainerStart(t *testing.T) {
+	r := require.New(t)
+
+	state := "created"
+	var mu sync.Mutex
+	dockerClient := testDockerAPIClient(t, func() []types.Container {
+		mu.Lock()
+		defer mu.Unlock()
+		return []types.Container{{ID: "1", State: state, Labels: map[string]string{DockerLabelForta: "true"}}}
+	})
+
+	// times out with the context deadline
+	ctx, cancel := context.WithTimeout(context.Background(), time.Millisecond*1500)
+	defer cancel()
+	err := dockerClient.WaitContainerStart(ctx, "1")
+	r.ErrorIs(err, ErrContainerStartTimeout)
+	r.Contains(err.Error(), "last state: created")
+
+	// cancellation is not a timeout
+	ctx, cancel = context.WithCancel(context.Background())
+	cancel()
+	r.ErrorIs(dockerClient.WaitContainerStart(ctx, "1"), context.Canceled)
+
+	mu.Lock()
+	state = "running"
+	mu.Unlock()
+	r.NoError(dockerClient.WaitContainerStart(context.Background(), "1"))
+}
+
+func TestDockerContainerList_RunningHostPorts(t *testing.T) {
+	containers := DockerContainerList{
+		{State: "running", Ports: []types.Port{{PrivatePort: 8089, PublicPort: 8089}, {PrivatePort: 8090}}},
+		{State: "exited", Ports: []types.Port{{PrivatePort: 8091, PublicPort: 8091}}},
+	}
+	require.Equal(t, map[string]bool{"8089": true}, containers.RunningHostPorts())
+}
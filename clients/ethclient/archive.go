@@ -0,0 +1,119 @@
+package ethclient
+
+import (
+	"context"
+	"math/big"
+	"sync/atomic"
+	"time"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/forta-network/forta-core-go/domain"
+	fortaeth "github.com/forta-network/forta-core-go/ethereum"
+	log "github.com/sirupsen/logrus"
+)
+
+// DefaultArchiveBlockDepth is used when the archive block depth is not set.
+const DefaultArchiveBlockDepth = 128
+
+// archiveHeadRefreshInterval is how often the latest block number is fetched from the primary API.
+var archiveHeadRefreshInterval = time.Second * 30
+
+// archiveClient sends the requests for the blocks which are deeper than the depth to the archive
+// API and the rest of the requests to the primary API. The latest block is fetched from the
+// primary API periodically and is also learned from the primary API responses.
+type archiveClient struct {
+	fortaeth.Client
+	archive fortaeth.Client
+	depth   uint64
+	latest  atomic.Uint64
+}
+
+// WithArchive routes the requests for the old blocks to the archive client. The latest block
+// number is refreshed until the context is done.
+func WithArchive(ctx context.Context, primary, archive fortaeth.Client, depth uint64) fortaeth.Client {
+	if depth == 0 {
+		depth = DefaultArchiveBlockDepth
+	}
+	c := &archiveClient{Client: primary, archive: archive, depth: depth}
+	go c.refreshLatest(ctx)
+	return c
+}
+
+// refreshLatest fetches the latest block number at start and periodically so that the blocks
+// which are requested by number (e.g. while scanning a range) can be routed.
+func (c *archiveClient) refreshLatest(ctx context.Context) {
+	ticker := time.NewTicker(archiveHeadRefreshInterval)
+	defer ticker.Stop()
+	for {
+		if _, err := c.BlockNumber(ctx); err != nil && ctx.Err() == nil {
+			log.WithError(err).Warn("failed to get the latest block number for the archive routing")
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// isOld tells if the block is deeper than the archive depth. The blocks are not old until
+// the latest block is known.
+func (c *archiveClient) isOld(number *big.Int) bool {
+	latest := c.latest.Load()
+	if number == nil || !number.IsUint64() || latest < c.depth {
+		return false
+	}
+	return number.Uint64() < latest-c.depth
+}
+
+func (c *archiveClient) clientFor(number *big.Int) fortaeth.Client {
+	if c.isOld(number) {
+		return c.archive
+	}
+	return c.Client
+}
+
+func (c *archiveClient) setLatest(number uint64) {
+	for {
+		latest := c.latest.Load()
+		if number <= latest || c.latest.CompareAndSwap(latest, number) {
+			return
+		}
+	}
+}
+
+func (c *archiveClient) BlockNumber(ctx context.Context) (*big.Int, error) {
+	number, err := c.Client.BlockNumber(ctx)
+	if err == nil && number.IsUint64() {
+		c.setLatest(number.Uint64())
+	}
+	return number, err
+}
+
+func (c *archiveClient) BlockByNumber(ctx context.Context, number *big.Int) (*domain.Block, error) {
+	block, err := c.clientFor(number).BlockByNumber(ctx, number)
+	if err == nil && block != nil {
+		if latest, err := hexutil.DecodeUint64(block.Number); err == nil {
+			c.setLatest(latest)
+		}
+	}
+	return block, err
+}
+
+func (c *archiveClient) TraceBlock(ctx context.Context, number *big.Int) ([]domain.Trace, error) {
+	return c.clientFor(number).TraceBlock(ctx, number)
+}
+
+func (c *archiveClient) GetLogs(ctx context.Context, q ethereum.FilterQuery) ([]types.Log, error) {
+	if q.BlockHash == nil && c.isOld(q.ToBlock) {
+		return c.archive.GetLogs(ctx, q)
+	}
+	return c.Client.GetLogs(ctx, q)
+}
+
+func (c *archiveClient) Close() {
+	c.Client.Close()
+	c.archive.Close()
+}
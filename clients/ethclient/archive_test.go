@@ -0,0 +1,84 @@
+package ethclient
+
+import (
+	"context"
+	"math/big"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/forta-network/forta-core-go/domain"
+	mock_ethereum "github.com/forta-network/forta-core-go/ethereum/mocks"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestArchiveClient(t *testing.T) {
+	r := require.New(t)
+	ctrl := gomock.NewController(t)
+	primary := mock_ethereum.NewMockClient(ctrl)
+	archive := mock_ethereum.NewMockClient(ctrl)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	refreshInterval := archiveHeadRefreshInterval
+	archiveHeadRefreshInterval = time.Millisecond * 10
+	defer func() { archiveHeadRefreshInterval = refreshInterval }()
+
+	// the latest block number is only fetched by the client itself
+	var head atomic.Int64
+	primary.EXPECT().BlockNumber(gomock.Any()).DoAndReturn(func(context.Context) (*big.Int, error) {
+		return big.NewInt(head.Load()), nil
+	}).AnyTimes()
+	client := WithArchive(ctx, primary, archive, 10)
+	waitForHead := func(number uint64) {
+		r.Eventually(func() bool {
+			return client.(*archiveClient).latest.Load() == number
+		}, time.Second, time.Millisecond)
+	}
+
+	// the primary serves everything until the latest block is known
+	primary.EXPECT().BlockByNumber(ctx, big.NewInt(1)).Return(&domain.Block{Number: "0x1"}, nil)
+	_, err := client.BlockByNumber(ctx, big.NewInt(1))
+	r.NoError(err)
+
+	head.Store(100)
+	waitForHead(100)
+
+	// the deep blocks of the range are served by the archive
+	archive.EXPECT().BlockByNumber(ctx, big.NewInt(89)).Return(&domain.Block{Number: "0x59"}, nil)
+	_, err = client.BlockByNumber(ctx, big.NewInt(89))
+	r.NoError(err)
+	archive.EXPECT().TraceBlock(ctx, big.NewInt(50)).Return(nil, nil)
+	_, err = client.TraceBlock(ctx, big.NewInt(50))
+	r.NoError(err)
+	archive.EXPECT().GetLogs(ctx, gomock.Any()).Return(nil, nil)
+	_, err = client.GetLogs(ctx, ethereum.FilterQuery{FromBlock: big.NewInt(50), ToBlock: big.NewInt(50)})
+	r.NoError(err)
+
+	// the recent blocks of the range are served by the primary
+	primary.EXPECT().BlockByNumber(ctx, big.NewInt(90)).Return(&domain.Block{Number: "0x5a"}, nil)
+	_, err = client.BlockByNumber(ctx, big.NewInt(90))
+	r.NoError(err)
+	primary.EXPECT().GetLogs(ctx, gomock.Any()).Return(nil, nil)
+	_, err = client.GetLogs(ctx, ethereum.FilterQuery{FromBlock: big.NewInt(50), ToBlock: big.NewInt(95)})
+	r.NoError(err)
+
+	// the refreshed latest block number moves the depth
+	head.Store(200)
+	waitForHead(200)
+	archive.EXPECT().BlockByNumber(ctx, big.NewInt(150)).Return(&domain.Block{Number: "0x96"}, nil)
+	_, err = client.BlockByNumber(ctx, big.NewInt(150))
+	r.NoError(err)
+
+	// the blocks from the primary move the depth too
+	primary.EXPECT().BlockByNumber(ctx, big.NewInt(250)).Return(&domain.Block{Number: "0xfa"}, nil)
+	_, err = client.BlockByNumber(ctx, big.NewInt(250))
+	r.NoError(err)
+	r.EqualValues(250, client.(*archiveClient).latest.Load())
+
+	primary.EXPECT().Close()
+	archive.EXPECT().Close()
+	client.Close()
+}
@@ -0,0 +1,59 @@
+package ethclient
+
+import (
+	"context"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	gethclient "github.com/ethereum/go-ethereum/ethclient"
+	"github.com/ethereum/go-ethereum/rpc"
+	"github.com/forta-network/forta-node/config"
+)
+
+// limitedCaller passes the contract calls through the limiter.
+type limitedCaller struct {
+	caller  bind.ContractCaller
+	limiter *Limiter
+}
+
+// NewContractCaller creates a new contract caller which sends the configured headers and
+// respects the rate limit and the circuit breaker from the config.
+func NewContractCaller(ctx context.Context, apiName string, cfg config.JsonRpcConfig) (bind.ContractCaller, error) {
+	rpcClient, err := rpc.DialContext(ctx, cfg.Url)
+	if err != nil {
+		return nil, err
+	}
+	for h, v := range cfg.Headers {
+		rpcClient.SetHeader(h, v)
+	}
+	caller := gethclient.NewClient(rpcClient)
+	if !Enabled(cfg) {
+		return caller, nil
+	}
+	return WithCallLimiter(caller, NewLimiter(apiName, cfg)), nil
+}
+
+// WithCallLimiter wraps the contract caller with the limiter.
+func WithCallLimiter(caller bind.ContractCaller, limiter *Limiter) bind.ContractCaller {
+	return &limitedCaller{caller: caller, limiter: limiter}
+}
+
+func (c *limitedCaller) CodeAt(ctx context.Context, contract common.Address, blockNumber *big.Int) ([]byte, error) {
+	if err := c.limiter.Wait(ctx); err != nil {
+		return nil, err
+	}
+	code, err := c.caller.CodeAt(ctx, contract, blockNumber)
+	c.limiter.Done(err)
+	return code, err
+}
+
+func (c *limitedCaller) CallContract(ctx context.Context, call ethereum.CallMsg, blockNumber *big.Int) ([]byte, error) {
+	if err := c.limiter.Wait(ctx); err != nil {
+		return nil, err
+	}
+	result, err := c.caller.CallContract(ctx, call, blockNumber)
+	c.limiter.Done(err)
+	return result, err
+}
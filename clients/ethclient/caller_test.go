@@ -0,0 +1,47 @@
+package ethclient
+
+import (
+	"context"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/stretchr/testify/require"
+)
+
+type testCaller struct {
+	calls int
+	err   error
+}
+
+func (caller *testCaller) CodeAt(ctx context.Context, contract common.Address, blockNumber *big.Int) ([]byte, error) {
+	caller.calls++
+	return []byte{1}, caller.err
+}
+
+func (caller *testCaller) CallContract(ctx context.Context, call ethereum.CallMsg, blockNumber *big.Int) ([]byte, error) {
+	caller.calls++
+	return []byte{1}, caller.err
+}
+
+func TestLimitedCaller(t *testing.T) {
+	r := require.New(t)
+
+	caller := &testCaller{}
+	limited := WithCallLimiter(caller, NewLimiter("registry", testCfg(1, time.Minute)))
+
+	_, err := limited.CodeAt(context.Background(), common.Address{}, nil)
+	r.NoError(err)
+	caller.err = errTest
+	_, err = limited.CallContract(context.Background(), ethereum.CallMsg{}, nil)
+	r.ErrorIs(err, errTest)
+
+	// the circuit is open
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	_, err = limited.CallContract(ctx, ethereum.CallMsg{}, nil)
+	r.ErrorIs(err, ErrCircuitOpen)
+	r.Equal(2, caller.calls)
+}
@@ -0,0 +1,69 @@
+package ethclient
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/ethereum/go-ethereum/common/hexutil"
+)
+
+// ErrChainIDMismatch is returned when the API serves another chain than the configured one.
+var ErrChainIDMismatch = errors.New("chain id mismatch")
+
+var chainIDRequest = []byte(`{"jsonrpc":"2.0","id":1,"method":"eth_chainId","params":[]}`)
+
+// GetChainID gets the chain ID from the API with the given headers.
+func GetChainID(ctx context.Context, rawurl string, headers map[string]string) (uint64, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, rawurl, bytes.NewReader(chainIDRequest))
+	if err != nil {
+		return 0, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	for h, v := range headers {
+		req.Header.Set(h, v)
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return 0, fmt.Errorf("unexpected status code %d", resp.StatusCode)
+	}
+	var result struct {
+		Result *hexutil.Uint64 `json:"result"`
+		Error  *struct {
+			Message string `json:"message"`
+		} `json:"error"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return 0, fmt.Errorf("failed to decode the response: %v", err)
+	}
+	if result.Error != nil {
+		return 0, errors.New(result.Error.Message)
+	}
+	if result.Result == nil {
+		return 0, errors.New("empty result")
+	}
+	return uint64(*result.Result), nil
+}
+
+// CheckChainID checks if the API serves the configured chain after waiting for the limiter.
+func CheckChainID(ctx context.Context, limiter *Limiter, rawurl string, headers map[string]string, chainID int) error {
+	if err := limiter.Wait(ctx); err != nil {
+		return err
+	}
+	apiChainID, err := GetChainID(ctx, rawurl, headers)
+	limiter.Done(err)
+	if err != nil {
+		return fmt.Errorf("failed to get the chain id: %v", err)
+	}
+	if apiChainID != uint64(chainID) {
+		return fmt.Errorf("%w: the config has chain id %d but the api serves chain id %d", ErrChainIDMismatch, chainID, apiChainID)
+	}
+	return nil
+}
@@ -0,0 +1,39 @@
+package ethclient
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func testChainServer(t *testing.T, response string) *httptest.Server {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, "secret", r.Header.Get("X-Api-Key"))
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(response))
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestCheckChainID(t *testing.T) {
+	r := require.New(t)
+	headers := map[string]string{"X-Api-Key": "secret"}
+
+	server := testChainServer(t, `{"jsonrpc":"2.0","id":1,"result":"0x89"}`)
+	r.NoError(CheckChainID(context.Background(), nil, server.URL, headers, 137))
+
+	err := CheckChainID(context.Background(), nil, server.URL, headers, 1)
+	r.ErrorIs(err, ErrChainIDMismatch)
+	r.Contains(err.Error(), "chain id 1")
+	r.Contains(err.Error(), "chain id 137")
+
+	server = testChainServer(t, `{"jsonrpc":"2.0","id":1,"error":{"code":-32601,"message":"method not found"}}`)
+	err = CheckChainID(context.Background(), nil, server.URL, headers, 1)
+	r.Error(err)
+	r.NotErrorIs(err, ErrChainIDMismatch)
+	r.Contains(err.Error(), "method not found")
+}
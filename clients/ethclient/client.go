@@ -0,0 +1,127 @@
+package ethclient
+
+import (
+	"context"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/forta-network/forta-core-go/clients/health"
+	"github.com/forta-network/forta-core-go/domain"
+	fortaeth "github.com/forta-network/forta-core-go/ethereum"
+	"github.com/forta-network/forta-node/config"
+)
+
+// limitedClient passes the requests through the limiter. It wraps every method of the client
+// explicitly so that a new request method cannot bypass the limiter.
+type limitedClient struct {
+	client  fortaeth.Client
+	limiter *Limiter
+}
+
+var _ fortaeth.Client = &limitedClient{}
+
+// NewStreamEthClient creates a new ethereum client which respects the rate limit and
+// the circuit breaker from the config.
+func NewStreamEthClient(ctx context.Context, apiName string, cfg config.JsonRpcConfig) (fortaeth.Client, error) {
+	client, err := fortaeth.NewStreamEthClient(ctx, apiName, cfg.Url)
+	if err != nil {
+		return nil, err
+	}
+	if !Enabled(cfg) {
+		return client, nil
+	}
+	return WithLimiter(client, NewLimiter(apiName, cfg)), nil
+}
+
+// WithLimiter wraps the client with the limiter.
+func WithLimiter(client fortaeth.Client, limiter *Limiter) fortaeth.Client {
+	return &limitedClient{client: client, limiter: limiter}
+}
+
+// TestAPI tests the API after waiting for the limiter.
+func TestAPI(ctx context.Context, limiter *Limiter, rawurl string) error {
+	if err := limiter.Wait(ctx); err != nil {
+		return err
+	}
+	err := fortaeth.TestAPI(ctx, rawurl)
+	limiter.Done(err)
+	return err
+}
+
+func (c *limitedClient) Close() {
+	c.client.Close()
+}
+
+func (c *limitedClient) BlockByHash(ctx context.Context, hash string) (*domain.Block, error) {
+	if err := c.limiter.Wait(ctx); err != nil {
+		return nil, err
+	}
+	block, err := c.client.BlockByHash(ctx, hash)
+	c.limiter.Done(err)
+	return block, err
+}
+
+func (c *limitedClient) BlockByNumber(ctx context.Context, number *big.Int) (*domain.Block, error) {
+	if err := c.limiter.Wait(ctx); err != nil {
+		return nil, err
+	}
+	block, err := c.client.BlockByNumber(ctx, number)
+	c.limiter.Done(err)
+	return block, err
+}
+
+func (c *limitedClient) BlockNumber(ctx context.Context) (*big.Int, error) {
+	if err := c.limiter.Wait(ctx); err != nil {
+		return nil, err
+	}
+	number, err := c.client.BlockNumber(ctx)
+	c.limiter.Done(err)
+	return number, err
+}
+
+func (c *limitedClient) TransactionReceipt(ctx context.Context, txHash string) (*domain.TransactionReceipt, error) {
+	if err := c.limiter.Wait(ctx); err != nil {
+		return nil, err
+	}
+	receipt, err := c.client.TransactionReceipt(ctx, txHash)
+	c.limiter.Done(err)
+	return receipt, err
+}
+
+func (c *limitedClient) ChainID(ctx context.Context) (*big.Int, error) {
+	if err := c.limiter.Wait(ctx); err != nil {
+		return nil, err
+	}
+	chainID, err := c.client.ChainID(ctx)
+	c.limiter.Done(err)
+	return chainID, err
+}
+
+func (c *limitedClient) TraceBlock(ctx context.Context, number *big.Int) ([]domain.Trace, error) {
+	if err := c.limiter.Wait(ctx); err != nil {
+		return nil, err
+	}
+	traces, err := c.client.TraceBlock(ctx, number)
+	c.limiter.Done(err)
+	return traces, err
+}
+
+func (c *limitedClient) GetLogs(ctx context.Context, q ethereum.FilterQuery) ([]types.Log, error) {
+	if err := c.limiter.Wait(ctx); err != nil {
+		return nil, err
+	}
+	logs, err := c.client.GetLogs(ctx, q)
+	c.limiter.Done(err)
+	return logs, err
+}
+
+// Name implements health.Reporter interface.
+func (c *limitedClient) Name() string {
+	return c.client.Name()
+}
+
+// Health implements health.Reporter interface.
+func (c *limitedClient) Health() health.Reports {
+	return append(c.client.Health(), c.limiter.Health()...)
+}
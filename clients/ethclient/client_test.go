@@ -0,0 +1,126 @@
+package ethclient
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/forta-network/forta-core-go/clients/health"
+	"github.com/forta-network/forta-core-go/ethereum"
+	mock_ethereum "github.com/forta-network/forta-core-go/ethereum/mocks"
+	"github.com/forta-network/forta-node/config"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+)
+
+var errTest = errors.New("test error")
+
+func testCfg(threshold int, openDuration time.Duration) config.JsonRpcConfig {
+	return config.JsonRpcConfig{
+		CircuitBreaker: config.CircuitBreakerConfig{
+			FailureThreshold: threshold,
+			OpenDuration:     openDuration,
+		},
+	}
+}
+
+func TestCircuitBreaker(t *testing.T) {
+	r := require.New(t)
+	ctrl := gomock.NewController(t)
+	ethClient := mock_ethereum.NewMockClient(ctrl)
+
+	limiter := NewLimiter("chain", testCfg(2, time.Millisecond*100))
+	client := WithLimiter(ethClient, limiter)
+
+	ethClient.EXPECT().BlockNumber(gomock.Any()).Return(nil, errTest).Times(2)
+	for i := 0; i < 2; i++ {
+		_, err := client.BlockNumber(context.Background())
+		r.ErrorIs(err, errTest)
+	}
+
+	// the requests do not reach the api while the circuit is open
+	ctx, cancel := context.WithTimeout(context.Background(), time.Millisecond*10)
+	defer cancel()
+	_, err := client.BlockNumber(ctx)
+	r.ErrorIs(err, ErrCircuitOpen)
+	r.Contains(err.Error(), errTest.Error())
+	r.Equal(health.StatusFailing, limiter.Health()[0].Status)
+
+	// the request waits until the api is retried and the circuit is closed after the success
+	ethClient.EXPECT().BlockNumber(gomock.Any()).Return(big.NewInt(1), nil)
+	start := time.Now()
+	number, err := client.BlockNumber(context.Background())
+	r.NoError(err)
+	r.Equal(int64(1), number.Int64())
+	r.Greater(time.Since(start), time.Millisecond*50)
+	r.Equal(health.StatusOK, limiter.Health()[0].Status)
+}
+
+func TestCircuitBreakerReopen(t *testing.T) {
+	r := require.New(t)
+	ctrl := gomock.NewController(t)
+	ethClient := mock_ethereum.NewMockClient(ctrl)
+
+	limiter := NewLimiter("chain", testCfg(1, time.Millisecond*50))
+	client := WithLimiter(ethClient, limiter)
+
+	ethClient.EXPECT().ChainID(gomock.Any()).Return(nil, errTest).Times(2)
+	_, err := client.ChainID(context.Background())
+	r.ErrorIs(err, errTest)
+
+	// the failing retry opens the circuit again
+	_, err = client.ChainID(context.Background())
+	r.ErrorIs(err, errTest)
+	ctx, cancel := context.WithTimeout(context.Background(), time.Millisecond*10)
+	defer cancel()
+	_, err = client.ChainID(ctx)
+	r.ErrorIs(err, ErrCircuitOpen)
+	r.Equal(1, limiter.trips)
+}
+
+func TestCircuitBreakerIgnoredErrors(t *testing.T) {
+	r := require.New(t)
+	ctrl := gomock.NewController(t)
+	ethClient := mock_ethereum.NewMockClient(ctrl)
+
+	limiter := NewLimiter("chain", testCfg(1, time.Hour))
+	client := WithLimiter(ethClient, limiter)
+
+	ethClient.EXPECT().BlockByNumber(gomock.Any(), gomock.Any()).Return(nil, ethereum.ErrNotFound)
+	ethClient.EXPECT().BlockByNumber(gomock.Any(), gomock.Any()).Return(nil, context.Canceled)
+	for i := 0; i < 2; i++ {
+		_, err := client.BlockByNumber(context.Background(), big.NewInt(1))
+		r.Error(err)
+	}
+	r.Equal(0, limiter.failures)
+	r.Equal(health.StatusOK, limiter.Health()[0].Status)
+}
+
+func TestRateLimit(t *testing.T) {
+	r := require.New(t)
+	ctrl := gomock.NewController(t)
+	ethClient := mock_ethereum.NewMockClient(ctrl)
+
+	client := WithLimiter(ethClient, NewLimiter("chain", config.JsonRpcConfig{
+		RateLimit: config.ClientRateLimitConfig{RequestsPerSecond: 20},
+	}))
+
+	ethClient.EXPECT().BlockNumber(gomock.Any()).Return(big.NewInt(1), nil).Times(3)
+	start := time.Now()
+	for i := 0; i < 3; i++ {
+		_, err := client.BlockNumber(context.Background())
+		r.NoError(err)
+	}
+	r.GreaterOrEqual(time.Since(start), time.Millisecond*90)
+}
+
+func TestEnabled(t *testing.T) {
+	r := require.New(t)
+
+	r.False(Enabled(config.JsonRpcConfig{}))
+	r.True(Enabled(config.JsonRpcConfig{RateLimit: config.ClientRateLimitConfig{RequestsPerSecond: 1}}))
+	r.True(Enabled(testCfg(1, 0)))
+	r.Empty(NewLimiter("chain", config.JsonRpcConfig{}).Health())
+}
@@ -0,0 +1,196 @@
+package ethclient
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/forta-network/forta-core-go/clients/health"
+	"github.com/forta-network/forta-core-go/ethereum"
+	"github.com/forta-network/forta-node/config"
+	log "github.com/sirupsen/logrus"
+	"golang.org/x/time/rate"
+)
+
+// DefaultCircuitOpenDuration is how long the circuit stays open if the duration is not configured.
+const DefaultCircuitOpenDuration = time.Second * 30
+
+// ErrCircuitOpen is returned when the circuit breaker does not let the requests reach the API.
+var ErrCircuitOpen = errors.New("circuit breaker is open")
+
+// Limiter applies the client-side rate limit and the circuit breaker to the requests of an API.
+// A nil limiter allows all requests.
+type Limiter struct {
+	name         string
+	rateLimiter  *rate.Limiter
+	threshold    int
+	openDuration time.Duration
+
+	failures  int
+	openUntil time.Time
+	probing   bool
+	lastErr   error
+	trips     int
+	changed   chan struct{} // closed and replaced when the circuit opens or closes
+	mu        sync.Mutex
+}
+
+// Enabled tells if the config has a rate limit or a circuit breaker.
+func Enabled(cfg config.JsonRpcConfig) bool {
+	return cfg.RateLimit.RequestsPerSecond > 0 || cfg.CircuitBreaker.FailureThreshold > 0
+}
+
+// NewLimiter creates a new limiter from the API config. Both of the rate limit and the circuit
+// breaker are no-op when not configured.
+func NewLimiter(name string, cfg config.JsonRpcConfig) *Limiter {
+	limiter := &Limiter{
+		name:         name,
+		threshold:    cfg.CircuitBreaker.FailureThreshold,
+		openDuration: cfg.CircuitBreaker.OpenDuration,
+		changed:      make(chan struct{}),
+	}
+	if limiter.openDuration == 0 {
+		limiter.openDuration = DefaultCircuitOpenDuration
+	}
+	if cfg.RateLimit.RequestsPerSecond > 0 {
+		burst := cfg.RateLimit.Burst
+		if burst == 0 {
+			burst = 1
+		}
+		limiter.rateLimiter = rate.NewLimiter(rate.Limit(cfg.RateLimit.RequestsPerSecond), burst)
+	}
+	return limiter
+}
+
+// Wait blocks until the request is allowed by the rate limit and the circuit breaker. While the
+// circuit is open, the callers wait until it is time to retry so that the API is not hammered.
+// ErrCircuitOpen is returned if the context ends before that.
+func (l *Limiter) Wait(ctx context.Context) error {
+	if l == nil {
+		return nil
+	}
+	if l.rateLimiter != nil {
+		if err := l.rateLimiter.Wait(ctx); err != nil {
+			return err
+		}
+	}
+	for {
+		l.mu.Lock()
+		if l.openUntil.IsZero() {
+			l.mu.Unlock()
+			return nil
+		}
+		// let one request see if the API has recovered
+		if !l.probing && !time.Now().Before(l.openUntil) {
+			l.probing = true
+			l.mu.Unlock()
+			return nil
+		}
+		retryAt, changed, err := l.openUntil, l.changed, l.openErr()
+		l.mu.Unlock()
+
+		if deadline, ok := ctx.Deadline(); ok && deadline.Before(retryAt) {
+			return err
+		}
+		// wait for the probe result if the retry time is already reached
+		var (
+			timer *time.Timer
+			retry <-chan time.Time
+		)
+		if wait := time.Until(retryAt); wait > 0 {
+			timer = time.NewTimer(wait)
+			retry = timer.C
+		}
+		select {
+		case <-ctx.Done():
+		case <-changed:
+		case <-retry:
+		}
+		if timer != nil {
+			timer.Stop()
+		}
+		if ctx.Err() != nil {
+			return err
+		}
+	}
+}
+
+func (l *Limiter) openErr() error {
+	return fmt.Errorf("%w: %s api failed %d times in a row (retrying after %s): %v",
+		ErrCircuitOpen, l.name, l.failures, l.openUntil.UTC().Format(time.RFC3339), l.lastErr)
+}
+
+// Done records the result of a request which was allowed by Wait.
+func (l *Limiter) Done(err error) {
+	if l == nil {
+		return
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	wasProbing := l.probing
+	l.probing = false
+	// the caller gave up so the result does not tell about the api
+	if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		if wasProbing {
+			l.notify() // let another request probe
+		}
+		return
+	}
+	if err == nil || errors.Is(err, ethereum.ErrNotFound) {
+		if !l.openUntil.IsZero() {
+			log.WithField("api", l.name).Info("api has recovered - closing the circuit")
+			l.openUntil = time.Time{}
+			l.notify()
+		}
+		l.failures = 0
+		l.lastErr = nil
+		return
+	}
+
+	l.failures++
+	l.lastErr = err
+	if l.threshold == 0 || l.failures < l.threshold {
+		return
+	}
+	if l.openUntil.IsZero() {
+		l.trips++
+		log.WithError(err).WithFields(log.Fields{
+			"api":      l.name,
+			"failures": l.failures,
+			"duration": l.openDuration.String(),
+		}).Error("too many failures - opening the circuit")
+	}
+	l.openUntil = time.Now().Add(l.openDuration)
+	l.notify()
+}
+
+func (l *Limiter) notify() {
+	close(l.changed)
+	l.changed = make(chan struct{})
+}
+
+// Health implements health.Reporter interface.
+func (l *Limiter) Health() health.Reports {
+	if l == nil {
+		return nil
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.threshold == 0 {
+		return nil
+	}
+	report := &health.Report{
+		Name:    fmt.Sprintf("%s.circuit-breaker", l.name),
+		Status:  health.StatusOK,
+		Details: fmt.Sprintf("closed, trips: %d", l.trips),
+	}
+	if !l.openUntil.IsZero() {
+		report.Status = health.StatusFailing
+		report.Details = fmt.Sprintf("open, trips: %d: %v", l.trips, l.openErr())
+	}
+	return health.Reports{report}
+}
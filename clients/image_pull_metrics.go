@@ -0,0 +1,65 @@
+package clients
+
+import (
+	"strings"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// the label of the agent images
+const imagePullLabelAgent = "agent"
+
+// imagePullMetrics contains the image pull metrics.
+type imagePullMetrics struct {
+	duration *prometheus.HistogramVec
+	size     *prometheus.HistogramVec
+}
+
+func newImagePullMetrics() *imagePullMetrics {
+	return &imagePullMetrics{
+		duration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: "forta",
+			Subsystem: "image_pull",
+			Name:      "duration_seconds",
+			Help:      "Time spent pulling an image, including the retries.",
+			Buckets:   []float64{1, 5, 15, 30, 60, 120, 300, 600, 1200, 3600},
+		}, []string{"image"}),
+		size: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: "forta",
+			Subsystem: "image_pull",
+			Name:      "size_bytes",
+			Help:      "Bytes downloaded while pulling an image. The layers which already exist are not counted.",
+			Buckets:   prometheus.ExponentialBuckets(1<<20, 2, 12), // 1 MiB to 2 GiB
+		}, []string{"image"}),
+	}
+}
+
+func (metrics *imagePullMetrics) mustRegister(registerer prometheus.Registerer) {
+	registerer.MustRegister(metrics.duration, metrics.size)
+}
+
+func (metrics *imagePullMetrics) observe(name string, duration time.Duration, size int64) {
+	label := imagePullLabel(name)
+	metrics.duration.WithLabelValues(label).Observe(duration.Seconds())
+	metrics.size.WithLabelValues(label).Observe(float64(size))
+}
+
+var defaultImagePullMetrics = newImagePullMetrics()
+
+func init() {
+	defaultImagePullMetrics.mustRegister(prometheus.DefaultRegisterer)
+}
+
+// imagePullLabel returns the metric label of the image. The agent images are labeled together so
+// that the number of the label values stays small.
+func imagePullLabel(name string) string {
+	if name == imagePullLabelAgent || strings.HasPrefix(name, imagePullLabelAgent+" ") {
+		return imagePullLabelAgent
+	}
+	return name
+}
+
+func observeImagePull(name string, duration time.Duration, size int64) {
+	defaultImagePullMetrics.observe(name, duration, size)
+}
@@ -0,0 +1,58 @@
+package clients
+
+import (
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	dto "github.com/prometheus/client_model/go"
+	"github.com/stretchr/testify/require"
+)
+
+func TestImagePullLabel(t *testing.T) {
+	r := require.New(t)
+
+	r.Equal("agent", imagePullLabel("agent 0x123"))
+	r.Equal("agent", imagePullLabel("agent"))
+	r.Equal("updater", imagePullLabel("updater"))
+	r.Equal("supervisor", imagePullLabel("supervisor"))
+	r.Equal("agents", imagePullLabel("agents"))
+}
+
+func TestObserveImagePull(t *testing.T) {
+	r := require.New(t)
+
+	metrics := newImagePullMetrics()
+	registry := prometheus.NewRegistry()
+	metrics.mustRegister(registry)
+
+	metrics.observe("agent 0x123", time.Second*2, 1<<20)
+	metrics.observe("agent 0x456", time.Second, 1<<10)
+	metrics.observe("supervisor", time.Minute, 1<<30)
+
+	count, err := testutil.GatherAndCount(registry, "forta_image_pull_duration_seconds")
+	r.NoError(err)
+	r.Equal(2, count)
+	count, err = testutil.GatherAndCount(registry, "forta_image_pull_size_bytes")
+	r.NoError(err)
+	r.Equal(2, count)
+
+	// the agents are observed together
+	families, err := registry.Gather()
+	r.NoError(err)
+	var agentSize *dto.Histogram
+	for _, family := range families {
+		if family.GetName() != "forta_image_pull_size_bytes" {
+			continue
+		}
+		for _, metric := range family.GetMetric() {
+			if metric.GetLabel()[0].GetValue() == imagePullLabelAgent {
+				agentSize = metric.GetHistogram()
+			}
+		}
+	}
+	r.NotNil(agentSize)
+	r.Equal(uint64(2), agentSize.GetSampleCount())
+	r.Equal(float64(1<<20+1<<10), agentSize.GetSampleSum())
+}
@@ -3,6 +3,7 @@ package clients
 import (
 	"context"
 	"io"
+	"time"
 
 	"github.com/forta-network/forta-core-go/domain"
 	"google.golang.org/grpc"
@@ -23,14 +24,15 @@ type DockerClient interface {
 	AttachNetwork(ctx context.Context, containerID string, networkID string) error
 	RemoveNetworkByName(ctx context.Context, networkName string) error
 	GetContainers(ctx context.Context) (DockerContainerList, error)
+	GetAllContainers(ctx context.Context) (DockerContainerList, error)
 	GetFortaServiceContainers(ctx context.Context) (fortaContainers DockerContainerList, err error)
 	GetContainerByName(ctx context.Context, name string) (*types.Container, error)
 	GetContainerByID(ctx context.Context, id string) (*types.Container, error)
 	InspectContainer(ctx context.Context, id string) (*types.ContainerJSON, error)
 	StartContainer(ctx context.Context, config DockerContainerConfig) (*DockerContainer, error)
-	StopContainer(ctx context.Context, id string) error
+	StopContainer(ctx context.Context, id string, timeout time.Duration) error
 	InterruptContainer(ctx context.Context, id string) error
-	TerminateContainer(ctx context.Context, id string) error
+	TerminateContainer(ctx context.Context, id string, timeout time.Duration) error
 	RemoveContainer(ctx context.Context, containerID string) error
 	WaitContainerExit(ctx context.Context, id string) error
 	WaitContainerStart(ctx context.Context, id string) error
@@ -39,7 +41,12 @@ type DockerClient interface {
 	Nuke(ctx context.Context) error
 	HasLocalImage(ctx context.Context, ref string) bool
 	EnsureLocalImage(ctx context.Context, name, ref string) error
+	GetImages(ctx context.Context) ([]types.ImageSummary, error)
+	RemoveImage(ctx context.Context, ref string) error
+	GetImageDigests(ctx context.Context, ref string) ([]string, error)
 	GetContainerLogs(ctx context.Context, containerID, tail string, truncate int) (string, error)
+	StreamContainerLogs(ctx context.Context, containerID string, opts ContainerLogsOptions, stdout, stderr io.Writer) error
+	GetDockerRootDir(ctx context.Context) (string, error)
 }
 
 // MessageClient receives and publishes messages.
@@ -0,0 +1,294 @@
+package ipfsgateway
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/forta-network/forta-core-go/clients/health"
+	"github.com/forta-network/forta-node/config"
+	ipfsapi "github.com/ipfs/go-ipfs-api"
+	log "github.com/sirupsen/logrus"
+)
+
+const defaultTimeout = time.Second * 30
+
+// Errors
+var (
+	ErrNoGateways      = errors.New("no ipfs gateways configured")
+	ErrNoVerifyGateway = errors.New("no other gateway to verify the content from")
+	ErrContentMismatch = errors.New("fetched content does not match the added content")
+	ErrPinningFailed   = errors.New("pinning service request failed")
+)
+
+// Client adds and pins content by using multiple gateways. The gateways are tried in order
+// and the gateways which failed recently are tried last. If an IPFS node API is configured,
+// the content is added only by using the API and the gateways are used for reading.
+type Client struct {
+	gateways   []*gateway
+	api        *gateway
+	retry      config.IPFSRetryConfig
+	pinning    config.IPFSPinningConfig
+	httpClient *http.Client
+	backoff    time.Duration // unit of the retry backoff
+	mu         sync.Mutex
+
+	lastGateway health.MessageTracker
+	pinningErr  health.ErrorTracker
+}
+
+type gateway struct {
+	index    int
+	url      string
+	shell    *ipfsapi.Shell
+	failures int // consecutive
+
+	lastErr health.ErrorTracker
+}
+
+// NewClient creates a new client.
+func NewClient(cfg config.PublisherIPFSConfig) (*Client, error) {
+	gatewayURLs := cfg.Gateways()
+	if len(gatewayURLs) == 0 {
+		return nil, ErrNoGateways
+	}
+	httpClient := &http.Client{Timeout: defaultTimeout}
+	client := &Client{
+		retry:      cfg.Retry,
+		pinning:    cfg.Pinning,
+		httpClient: httpClient,
+		backoff:    time.Second,
+	}
+	for i, gatewayURL := range gatewayURLs {
+		client.gateways = append(client.gateways, &gateway{
+			index: i,
+			url:   gatewayURL,
+			shell: ipfsapi.NewShellWithClient(gatewayURL, httpClient),
+		})
+	}
+	if len(cfg.APIURL) > 0 {
+		apiClient := httpClient
+		if len(cfg.Username) > 0 || len(cfg.Password) > 0 {
+			apiClient = &http.Client{
+				Timeout: defaultTimeout,
+				Transport: &basicAuthTransport{
+					username: cfg.Username,
+					password: cfg.Password,
+					next:     http.DefaultTransport,
+				},
+			}
+		}
+		client.api = &gateway{
+			url:   cfg.APIURL,
+			shell: ipfsapi.NewShellWithClient(cfg.APIURL, apiClient),
+		}
+	}
+	return client, nil
+}
+
+// basicAuthTransport sets the basic auth credentials to the IPFS API requests.
+type basicAuthTransport struct {
+	username string
+	password string
+	next     http.RoundTripper
+}
+
+func (t *basicAuthTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	req = req.Clone(req.Context())
+	req.SetBasicAuth(t.username, t.password)
+	return t.next.RoundTrip(req)
+}
+
+// writeGateways returns the API node if configured or the ordered gateways otherwise.
+func (client *Client) writeGateways() []*gateway {
+	if client.api != nil {
+		return []*gateway{client.api}
+	}
+	return client.orderedGateways()
+}
+
+// orderedGateways returns the gateways with the least consecutive failures first.
+func (client *Client) orderedGateways() []*gateway {
+	client.mu.Lock()
+	defer client.mu.Unlock()
+	return orderGateways(client.gateways)
+}
+
+// orderGateways returns a copy of the gateways sorted by the consecutive failures.
+func orderGateways(gateways []*gateway) []*gateway {
+	ordered := make([]*gateway, len(gateways))
+	copy(ordered, gateways)
+	sort.SliceStable(ordered, func(i, j int) bool {
+		return ordered[i].failures < ordered[j].failures
+	})
+	return ordered
+}
+
+func (client *Client) recordResult(gw *gateway, err error) {
+	client.mu.Lock()
+	defer client.mu.Unlock()
+	gw.lastErr.Set(err)
+	if err != nil {
+		gw.failures++
+		return
+	}
+	gw.failures = 0
+}
+
+// withRetry retries the operation with an increasing backoff until the context is done.
+func (client *Client) withRetry(ctx context.Context, operation func() error) (err error) {
+	for attempt := 1; attempt <= client.retry.MaxAttempts; attempt++ {
+		if err = operation(); err == nil {
+			return nil
+		}
+		if attempt == client.retry.MaxAttempts {
+			break
+		}
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("%w (last error: %v)", ctx.Err(), err)
+		case <-time.After(time.Duration(client.retry.BackoffSeconds) * client.backoff * time.Duration(attempt)):
+		}
+	}
+	return
+}
+
+// Add adds and pins the content by using the first gateway that succeeds, or the IPFS node API
+// if configured, and returns the CID and the URL that was used. The content is also pinned to
+// the pinning service if configured.
+func (client *Client) Add(ctx context.Context, content []byte) (cid string, gatewayURL string, err error) {
+	for _, gw := range client.writeGateways() {
+		err = client.withRetry(ctx, func() (err error) {
+			cid, err = gw.shell.Add(bytes.NewReader(content), ipfsapi.Pin(true))
+			return
+		})
+		if err != nil && ctx.Err() != nil {
+			return "", "", err
+		}
+		client.recordResult(gw, err)
+		if err == nil {
+			gatewayURL = gw.url
+			break
+		}
+		log.WithError(err).WithField("gateway", gw.url).Warn("failed to add to ipfs gateway - trying next")
+	}
+	if err != nil {
+		return "", "", fmt.Errorf("failed to add to all ipfs gateways: %v", err)
+	}
+	client.lastGateway.Set(gatewayURL)
+
+	if len(client.pinning.URL) > 0 {
+		// the pinning service is secondary so the failures are only reported
+		err := client.withRetry(ctx, func() error {
+			return client.pin(ctx, cid)
+		})
+		client.pinningErr.Set(err)
+		if err != nil {
+			log.WithError(err).WithField("cid", cid).Warn("failed to pin to the pinning service")
+		}
+	}
+	return cid, gatewayURL, nil
+}
+
+// pin pins the CID by using a Pinata-style pinning service API.
+func (client *Client) pin(ctx context.Context, cid string) error {
+	b, _ := json.Marshal(&struct {
+		HashToPin string `json:"hashToPin"`
+	}{HashToPin: cid})
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, fmt.Sprintf("%s/pinning/pinByHash", client.pinning.URL), bytes.NewReader(b))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if len(client.pinning.APIKey) > 0 {
+		req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", client.pinning.APIKey))
+	}
+	resp, err := client.httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		body, _ := io.ReadAll(resp.Body)
+		return fmt.Errorf("%w: status %d: %s", ErrPinningFailed, resp.StatusCode, string(body))
+	}
+	return nil
+}
+
+// Verify fetches the content of the CID from a gateway other than the excluded one
+// and checks that it matches the given content.
+func (client *Client) Verify(ctx context.Context, cid string, content []byte, excludedGatewayURL string) error {
+	err := ErrNoVerifyGateway
+	for _, gw := range client.orderedGateways() {
+		if gw.url == excludedGatewayURL {
+			continue
+		}
+		var fetched []byte
+		fetched, err = client.get(ctx, gw.url, cid)
+		if err != nil {
+			err = fmt.Errorf("failed to fetch from %s: %v", gw.url, err)
+			continue
+		}
+		if !bytes.Equal(fetched, content) {
+			return fmt.Errorf("%w: %s", ErrContentMismatch, gw.url)
+		}
+		return nil
+	}
+	return err
+}
+
+func (client *Client) get(ctx context.Context, gatewayURL, cid string) ([]byte, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, fmt.Sprintf("%s/ipfs/%s", gatewayURL, cid), nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := client.httpClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected status code %d", resp.StatusCode)
+	}
+	return io.ReadAll(resp.Body)
+}
+
+// Health implements the health.Reporter interface.
+func (client *Client) Health() health.Reports {
+	client.mu.Lock()
+	defer client.mu.Unlock()
+	reports := health.Reports{
+		client.lastGateway.GetReport("ipfs.last-gateway"),
+	}
+	if len(client.pinning.URL) > 0 {
+		reports = append(reports, client.pinningErr.GetReport("ipfs.pinning.last-error"))
+	}
+	if client.api != nil {
+		reports = append(reports,
+			&health.Report{
+				Name:    "ipfs.api.failures",
+				Status:  health.StatusInfo,
+				Details: fmt.Sprint(client.api.failures),
+			},
+			client.api.lastErr.GetReport("ipfs.api.last-error"),
+		)
+	}
+	for _, gw := range client.gateways {
+		reports = append(reports,
+			&health.Report{
+				Name:    fmt.Sprintf("ipfs.gateway.%d.failures", gw.index),
+				Status:  health.StatusInfo,
+				Details: fmt.Sprint(gw.failures),
+			},
+			gw.lastErr.GetReport(fmt.Sprintf("ipfs.gateway.%d.last-error", gw.index)),
+		)
+	}
+	return reports
+}
@@ -0,0 +1,226 @@
+package ipfsgateway
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/forta-network/forta-node/config"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	testCid     = "bafkreihdwdcefgh4dqkjv67uzcmw7ojee6xedzdetojuzjevtenxquvyku"
+	testContent = "batch content\n"
+)
+
+// testGateway responds to the add and get requests or fails with given status code.
+func testGateway(failStatus int) (*httptest.Server, *int32) {
+	var calls int32
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&calls, 1)
+		if failStatus != 0 {
+			w.WriteHeader(failStatus)
+			return
+		}
+		switch r.URL.Path {
+		case "/api/v0/add":
+			json.NewEncoder(w).Encode(map[string]string{"Hash": testCid, "Name": testCid})
+		case "/ipfs/" + testCid:
+			w.Write([]byte(testContent))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	})), &calls
+}
+
+func testClient(t *testing.T, cfg config.PublisherIPFSConfig) *Client {
+	cfg.Retry = config.IPFSRetryConfig{MaxAttempts: 2, BackoffSeconds: 1}
+	client, err := NewClient(cfg)
+	require.NoError(t, err)
+	client.backoff = time.Millisecond
+	return client
+}
+
+func TestAdd_Failover(t *testing.T) {
+	r := require.New(t)
+
+	failing, failingCalls := testGateway(http.StatusInternalServerError)
+	defer failing.Close()
+	working, _ := testGateway(0)
+	defer working.Close()
+
+	var cfg config.PublisherIPFSConfig
+	cfg.GatewayURL = failing.URL
+	cfg.GatewayURLs = []string{working.URL, failing.URL}
+	client := testClient(t, cfg)
+	r.Len(client.gateways, 2)
+
+	cid, gatewayURL, err := client.Add(context.Background(), []byte(testContent))
+	r.NoError(err)
+	r.Equal(testCid, cid)
+	r.Equal(working.URL, gatewayURL)
+	r.Equal(int32(2), atomic.LoadInt32(failingCalls))
+
+	// the failing gateway is tried last now
+	_, gatewayURL, err = client.Add(context.Background(), []byte(testContent))
+	r.NoError(err)
+	r.Equal(working.URL, gatewayURL)
+	r.Equal(int32(2), atomic.LoadInt32(failingCalls))
+}
+
+func TestAdd_AllFail(t *testing.T) {
+	r := require.New(t)
+
+	failing, _ := testGateway(http.StatusBadGateway)
+	defer failing.Close()
+
+	var cfg config.PublisherIPFSConfig
+	cfg.GatewayURL = failing.URL
+	client := testClient(t, cfg)
+
+	_, _, err := client.Add(context.Background(), []byte(testContent))
+	r.Error(err)
+}
+
+func TestAdd_ContextDone(t *testing.T) {
+	r := require.New(t)
+
+	failing, failingCalls := testGateway(http.StatusBadGateway)
+	defer failing.Close()
+
+	var cfg config.PublisherIPFSConfig
+	cfg.GatewayURL = failing.URL
+	client := testClient(t, cfg)
+	client.backoff = time.Hour
+
+	// does not wait for the backoff after the context is done
+	ctx, cancel := context.WithTimeout(context.Background(), time.Millisecond*100)
+	defer cancel()
+	start := time.Now()
+	_, _, err := client.Add(ctx, []byte(testContent))
+	r.ErrorIs(err, context.DeadlineExceeded)
+	r.Less(time.Since(start), time.Second*5)
+	r.Equal(int32(1), atomic.LoadInt32(failingCalls))
+}
+
+func TestAdd_NodeAPI(t *testing.T) {
+	r := require.New(t)
+
+	gw, gatewayCalls := testGateway(0)
+	defer gw.Close()
+
+	api := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		r.Equal("/api/v0/add", req.URL.Path)
+		r.Equal("true", req.URL.Query().Get("pin"))
+		username, password, ok := req.BasicAuth()
+		r.True(ok)
+		r.Equal("user1", username)
+		r.Equal("pass1", password)
+		json.NewEncoder(w).Encode(map[string]string{"Hash": testCid, "Name": testCid})
+	}))
+	defer api.Close()
+
+	var cfg config.PublisherIPFSConfig
+	cfg.GatewayURL = gw.URL
+	cfg.APIURL = api.URL
+	cfg.Username = "user1"
+	cfg.Password = "pass1"
+	client := testClient(t, cfg)
+
+	cid, apiURL, err := client.Add(context.Background(), []byte(testContent))
+	r.NoError(err)
+	r.Equal(testCid, cid)
+	r.Equal(api.URL, apiURL)
+	r.Equal(int32(0), atomic.LoadInt32(gatewayCalls))
+
+	// read from the gateway
+	r.NoError(client.Verify(context.Background(), cid, []byte(testContent), apiURL))
+	r.Equal(int32(1), atomic.LoadInt32(gatewayCalls))
+}
+
+func TestAdd_NodeAPIFails(t *testing.T) {
+	r := require.New(t)
+
+	gw, gatewayCalls := testGateway(0)
+	defer gw.Close()
+	api, _ := testGateway(http.StatusInternalServerError)
+	defer api.Close()
+
+	var cfg config.PublisherIPFSConfig
+	cfg.GatewayURL = gw.URL
+	cfg.APIURL = api.URL
+	client := testClient(t, cfg)
+
+	// no writes to the gateways
+	_, _, err := client.Add(context.Background(), []byte(testContent))
+	r.Error(err)
+	r.Equal(int32(0), atomic.LoadInt32(gatewayCalls))
+	report, ok := client.Health().GetByName("ipfs.api.failures")
+	r.True(ok)
+	r.Equal("1", report.Details)
+}
+
+func TestAdd_Pinning(t *testing.T) {
+	r := require.New(t)
+
+	working, _ := testGateway(0)
+	defer working.Close()
+
+	var pinned string
+	pinning := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		r.Equal("/pinning/pinByHash", req.URL.Path)
+		r.Equal("Bearer key1", req.Header.Get("Authorization"))
+		var body struct {
+			HashToPin string `json:"hashToPin"`
+		}
+		r.NoError(json.NewDecoder(req.Body).Decode(&body))
+		pinned = body.HashToPin
+	}))
+	defer pinning.Close()
+
+	var cfg config.PublisherIPFSConfig
+	cfg.GatewayURL = working.URL
+	cfg.Pinning = config.IPFSPinningConfig{URL: pinning.URL, APIKey: "key1"}
+	client := testClient(t, cfg)
+
+	_, _, err := client.Add(context.Background(), []byte(testContent))
+	r.NoError(err)
+	r.Equal(testCid, pinned)
+}
+
+func TestVerify(t *testing.T) {
+	r := require.New(t)
+
+	gateway1, calls1 := testGateway(0)
+	defer gateway1.Close()
+	gateway2, calls2 := testGateway(0)
+	defer gateway2.Close()
+
+	var cfg config.PublisherIPFSConfig
+	cfg.GatewayURL = gateway1.URL
+	cfg.GatewayURLs = []string{gateway2.URL}
+	client := testClient(t, cfg)
+
+	r.NoError(client.Verify(context.Background(), testCid, []byte(testContent), gateway1.URL))
+	r.Equal(int32(0), atomic.LoadInt32(calls1))
+	r.Equal(int32(1), atomic.LoadInt32(calls2))
+
+	err := client.Verify(context.Background(), testCid, []byte("other content"), gateway1.URL)
+	r.ErrorIs(err, ErrContentMismatch)
+}
+
+func TestVerify_NoOtherGateway(t *testing.T) {
+	r := require.New(t)
+
+	var cfg config.PublisherIPFSConfig
+	cfg.GatewayURL = "http://localhost:5001"
+	client := testClient(t, cfg)
+
+	err := client.Verify(context.Background(), testCid, []byte(testContent), cfg.GatewayURL)
+	r.ErrorIs(err, ErrNoVerifyGateway)
+}
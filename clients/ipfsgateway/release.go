@@ -0,0 +1,248 @@
+package ipfsgateway
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"sync"
+
+	"github.com/forta-network/forta-core-go/clients/health"
+	"github.com/forta-network/forta-core-go/release"
+	"github.com/forta-network/forta-node/config"
+	"github.com/ipfs/go-cid"
+	"github.com/ipfs/go-merkledag"
+	"github.com/ipfs/go-unixfs"
+	unixfspb "github.com/ipfs/go-unixfs/pb"
+	log "github.com/sirupsen/logrus"
+)
+
+// the max size of a fetched block
+const maxBlockSize = 1 << 20
+
+// Errors
+var (
+	ErrCIDMismatch        = errors.New("fetched content does not hash to the cid")
+	ErrUnsupportedContent = errors.New("unsupported content")
+)
+
+// ReleaseClient gets the release manifests from multiple gateways. The gateways are tried in
+// order and the gateways which failed recently are tried last. The raw blocks are fetched so
+// that the content can be verified against the CID before it is trusted. The last verified
+// manifest is cached on disk and is used if all gateways fail.
+type ReleaseClient struct {
+	gateways   []*gateway
+	httpClient *http.Client
+	cachePath  string
+	mu         sync.Mutex
+
+	lastGateway  health.MessageTracker
+	verification health.MessageTracker
+}
+
+// NewReleaseClient creates a new release client which caches the last manifest at the given path.
+func NewReleaseClient(cfg config.IPFSConfig, cachePath string) (*ReleaseClient, error) {
+	gatewayURLs := cfg.Gateways()
+	if len(gatewayURLs) == 0 {
+		return nil, ErrNoGateways
+	}
+	client := &ReleaseClient{
+		httpClient: &http.Client{Timeout: defaultTimeout},
+		cachePath:  cachePath,
+	}
+	for i, gatewayURL := range gatewayURLs {
+		client.gateways = append(client.gateways, &gateway{
+			index: i,
+			url:   gatewayURL,
+		})
+	}
+	return client, nil
+}
+
+// GetReleaseManifest implements the release.Client interface.
+func (client *ReleaseClient) GetReleaseManifest(ctx context.Context, reference string) (*release.ReleaseManifest, error) {
+	content, err := client.GetContent(ctx, reference)
+	if err != nil {
+		return client.getCached(reference, err)
+	}
+	var rm release.ReleaseManifest
+	if err := json.Unmarshal(content, &rm); err != nil {
+		return nil, fmt.Errorf("failed to decode the release manifest: %v", err)
+	}
+	client.saveCache(reference, &rm)
+	return &rm, nil
+}
+
+// GetContent gets the content of the CID from the first gateway which provides the content that
+// matches the CID.
+func (client *ReleaseClient) GetContent(ctx context.Context, reference string) ([]byte, error) {
+	c, err := cid.Parse(reference)
+	if err != nil {
+		return nil, fmt.Errorf("invalid cid: %v", err)
+	}
+
+	var content []byte
+	for _, gw := range client.orderedGateways() {
+		content, err = client.getVerified(ctx, gw.url, c)
+		client.recordResult(gw, err)
+		if err == nil {
+			client.lastGateway.Set(gw.url)
+			client.verification.Set("verified")
+			return content, nil
+		}
+		if errors.Is(err, ErrCIDMismatch) {
+			client.verification.Set(fmt.Sprintf("rejected content from %s", gw.url))
+		}
+		log.WithError(err).WithFields(log.Fields{
+			"gateway": gw.url,
+			"cid":     reference,
+		}).Warn("failed to get the content from ipfs gateway - trying next")
+	}
+	return nil, fmt.Errorf("failed to get the content from all ipfs gateways: %w", err)
+}
+
+// orderedGateways returns the gateways with the least consecutive failures first.
+func (client *ReleaseClient) orderedGateways() []*gateway {
+	client.mu.Lock()
+	defer client.mu.Unlock()
+	return orderGateways(client.gateways)
+}
+
+func (client *ReleaseClient) recordResult(gw *gateway, err error) {
+	client.mu.Lock()
+	defer client.mu.Unlock()
+	gw.lastErr.Set(err)
+	if err != nil {
+		gw.failures++
+		return
+	}
+	gw.failures = 0
+}
+
+// getVerified fetches the raw block of the CID and returns the file content in it after checking
+// that the block hashes to the CID.
+func (client *ReleaseClient) getVerified(ctx context.Context, gatewayURL string, c cid.Cid) ([]byte, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, fmt.Sprintf("%s/ipfs/%s?format=raw", gatewayURL, c), nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", "application/vnd.ipld.raw")
+	resp, err := client.httpClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected status code %d", resp.StatusCode)
+	}
+	block, err := io.ReadAll(io.LimitReader(resp.Body, maxBlockSize))
+	if err != nil {
+		return nil, err
+	}
+	return verifyBlock(c, block)
+}
+
+// verifyBlock checks the block against the CID and extracts the content. Only the raw blocks and
+// the single-block UnixFS files are supported since the manifests are small.
+func verifyBlock(c cid.Cid, block []byte) ([]byte, error) {
+	sum, err := c.Prefix().Sum(block)
+	if err != nil {
+		return nil, fmt.Errorf("failed to hash the content: %v", err)
+	}
+	if !sum.Equals(c) {
+		return nil, fmt.Errorf("%w: got %s", ErrCIDMismatch, sum)
+	}
+
+	switch c.Type() {
+	case cid.Raw:
+		return block, nil
+
+	case cid.DagProtobuf:
+		node, err := merkledag.DecodeProtobuf(block)
+		if err != nil {
+			return nil, fmt.Errorf("failed to decode the dag node: %v", err)
+		}
+		if len(node.Links()) > 0 {
+			return nil, fmt.Errorf("%w: multi-block file", ErrUnsupportedContent)
+		}
+		fsNode, err := unixfs.FSNodeFromBytes(node.Data())
+		if err != nil {
+			return nil, fmt.Errorf("failed to decode the unixfs node: %v", err)
+		}
+		if fsNode.Type() != unixfspb.Data_File && fsNode.Type() != unixfspb.Data_Raw {
+			return nil, fmt.Errorf("%w: unixfs type %s", ErrUnsupportedContent, fsNode.Type())
+		}
+		return fsNode.Data(), nil
+
+	default:
+		return nil, fmt.Errorf("%w: codec %d", ErrUnsupportedContent, c.Type())
+	}
+}
+
+// getCached returns the cached manifest if it belongs to the reference or the fetch error otherwise.
+func (client *ReleaseClient) getCached(reference string, fetchErr error) (*release.ReleaseManifest, error) {
+	if len(client.cachePath) == 0 {
+		return nil, fetchErr
+	}
+	b, err := os.ReadFile(client.cachePath)
+	if err != nil {
+		return nil, fetchErr
+	}
+	var cached release.ReleaseInfo
+	if err := json.Unmarshal(b, &cached); err != nil || cached.IPFS != reference {
+		return nil, fetchErr
+	}
+	log.WithError(fetchErr).WithField("cid", reference).Warn("using the cached release manifest")
+	client.lastGateway.Set("cache")
+	client.verification.Set("verified before caching")
+	return &cached.Manifest, nil
+}
+
+// saveCache writes the verified manifest to the cache file. The failures are only logged since
+// the cache is only needed during the gateway outages.
+func (client *ReleaseClient) saveCache(reference string, rm *release.ReleaseManifest) {
+	if len(client.cachePath) == 0 {
+		return
+	}
+	b, _ := json.Marshal(&release.ReleaseInfo{
+		IPFS:     reference,
+		Manifest: *rm,
+	})
+	tmpPath := client.cachePath + ".tmp"
+	if err := os.WriteFile(tmpPath, b, 0644); err != nil {
+		log.WithError(err).Warn("failed to write the release manifest cache")
+		return
+	}
+	if err := os.Rename(tmpPath, client.cachePath); err != nil {
+		log.WithError(err).Warn("failed to write the release manifest cache")
+	}
+}
+
+// Name returns the name of the client.
+func (client *ReleaseClient) Name() string {
+	return "ipfs"
+}
+
+// Health implements the health.Reporter interface.
+func (client *ReleaseClient) Health() health.Reports {
+	client.mu.Lock()
+	defer client.mu.Unlock()
+	reports := health.Reports{
+		client.lastGateway.GetReport("release-manifest.last-gateway"),
+		client.verification.GetReport("release-manifest.verification"),
+	}
+	for _, gw := range client.gateways {
+		reports = append(reports,
+			&health.Report{
+				Name:    fmt.Sprintf("release-manifest.gateway.%d.failures", gw.index),
+				Status:  health.StatusInfo,
+				Details: fmt.Sprint(gw.failures),
+			},
+			gw.lastErr.GetReport(fmt.Sprintf("release-manifest.gateway.%d.last-error", gw.index)),
+		)
+	}
+	return reports
+}
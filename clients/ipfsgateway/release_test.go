@@ -0,0 +1,130 @@
+package ipfsgateway
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"path"
+	"testing"
+
+	"github.com/forta-network/forta-core-go/clients/health"
+	"github.com/forta-network/forta-node/config"
+	"github.com/ipfs/go-cid"
+	"github.com/ipfs/go-merkledag"
+	"github.com/ipfs/go-unixfs"
+	"github.com/multiformats/go-multihash"
+	"github.com/stretchr/testify/require"
+)
+
+const testManifest = `{"release":{"timestamp":"2022-12-01T00:00:00Z","repository":"https://github.com/forta-network/forta-node","version":"v0.7.0","commit":"commit1","services":{"updater":"updater1","supervisor":"supervisor1"}}}`
+
+// testManifestBlock returns the manifest as a single-block UnixFS file like the IPFS nodes add it.
+func testManifestBlock() (string, []byte) {
+	node := merkledag.NodeWithData(unixfs.FilePBData([]byte(testManifest), uint64(len(testManifest))))
+	return node.Cid().String(), node.RawData()
+}
+
+// testBlockGateway serves the given block for the raw block requests of the CID.
+func testBlockGateway(ref string, block []byte, failStatus int) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if failStatus != 0 {
+			w.WriteHeader(failStatus)
+			return
+		}
+		if r.URL.Path != "/ipfs/"+ref || r.URL.Query().Get("format") != "raw" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		w.Write(block)
+	}))
+}
+
+func testReleaseClient(t *testing.T, cachePath string, gatewayURLs ...string) *ReleaseClient {
+	client, err := NewReleaseClient(config.IPFSConfig{GatewayURL: gatewayURLs[0], GatewayURLs: gatewayURLs[1:]}, cachePath)
+	require.NoError(t, err)
+	return client
+}
+
+func healthDetails(reports health.Reports, name string) string {
+	report, ok := reports.NameContains(name)
+	if !ok {
+		return ""
+	}
+	return report.Details
+}
+
+func TestReleaseClient_CorruptedContent(t *testing.T) {
+	r := require.New(t)
+
+	ref, block := testManifestBlock()
+	corruptedBlock := append([]byte{}, block...)
+	corruptedBlock[len(corruptedBlock)-10] ^= 0xff
+	corrupted := testBlockGateway(ref, corruptedBlock, 0)
+	defer corrupted.Close()
+	working := testBlockGateway(ref, block, 0)
+	defer working.Close()
+
+	client := testReleaseClient(t, "", corrupted.URL)
+	_, err := client.GetReleaseManifest(context.Background(), ref)
+	r.ErrorIs(err, ErrCIDMismatch)
+	r.Equal("rejected content from "+corrupted.URL, healthDetails(client.Health(), "release-manifest.verification"))
+
+	client = testReleaseClient(t, "", corrupted.URL, working.URL)
+	rm, err := client.GetReleaseManifest(context.Background(), ref)
+	r.NoError(err)
+	r.Equal("v0.7.0", rm.Release.Version)
+	r.Equal("supervisor1", rm.Release.Services.Supervisor)
+
+	reports := client.Health()
+	r.Equal(working.URL, healthDetails(reports, "release-manifest.last-gateway"))
+	r.Equal("verified", healthDetails(reports, "release-manifest.verification"))
+	r.Equal("1", healthDetails(reports, "release-manifest.gateway.0.failures"))
+	r.Contains(healthDetails(reports, "release-manifest.gateway.0.last-error"), ErrCIDMismatch.Error())
+	r.Equal("0", healthDetails(reports, "release-manifest.gateway.1.failures"))
+
+	// the corrupted gateway is tried last now
+	r.Equal(working.URL, client.orderedGateways()[0].url)
+}
+
+func TestReleaseClient_Cache(t *testing.T) {
+	r := require.New(t)
+
+	ref, block := testManifestBlock()
+	working := testBlockGateway(ref, block, 0)
+	failing := testBlockGateway(ref, nil, http.StatusBadGateway)
+	defer failing.Close()
+	cachePath := path.Join(t.TempDir(), config.DefaultUpdaterReleaseCacheFileName)
+
+	client := testReleaseClient(t, cachePath, working.URL)
+	_, err := client.GetReleaseManifest(context.Background(), ref)
+	r.NoError(err)
+	working.Close()
+
+	// a restarted node gets the current release from the cache during the outage
+	client = testReleaseClient(t, cachePath, working.URL, failing.URL)
+	rm, err := client.GetReleaseManifest(context.Background(), ref)
+	r.NoError(err)
+	r.Equal("v0.7.0", rm.Release.Version)
+	r.Equal("cache", healthDetails(client.Health(), "release-manifest.last-gateway"))
+
+	// only the cached release is known
+	otherNode := merkledag.NodeWithData(unixfs.FilePBData([]byte("{}"), 2))
+	_, err = client.GetReleaseManifest(context.Background(), otherNode.Cid().String())
+	r.Error(err)
+}
+
+func TestVerifyBlock(t *testing.T) {
+	r := require.New(t)
+
+	rawCid, err := cid.V1Builder{Codec: cid.Raw, MhType: multihash.SHA2_256}.Sum([]byte(testManifest))
+	r.NoError(err)
+	content, err := verifyBlock(rawCid, []byte(testManifest))
+	r.NoError(err)
+	r.Equal(testManifest, string(content))
+	_, err = verifyBlock(rawCid, []byte(testManifest+" "))
+	r.ErrorIs(err, ErrCIDMismatch)
+
+	dirNode := unixfs.EmptyDirNode()
+	_, err = verifyBlock(dirNode.Cid(), dirNode.RawData())
+	r.ErrorIs(err, ErrUnsupportedContent)
+}
@@ -0,0 +1,254 @@
+package logforward
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/forta-network/forta-node/config"
+	log "github.com/sirupsen/logrus"
+)
+
+// Label names
+const (
+	LabelComponent = "component"
+	LabelContainer = "container"
+	LabelLevel     = "level"
+)
+
+const defaultFlushInterval = time.Second
+
+// Record is a forwarded log entry.
+type Record struct {
+	Time   time.Time         `json:"time"`
+	Level  string            `json:"level"`
+	Labels map[string]string `json:"labels"`
+	Line   string            `json:"line"`
+}
+
+// Sink ships the log records to a remote endpoint.
+type Sink interface {
+	Send(records []*Record) error
+	Close() error
+}
+
+// Forwarder is a logrus hook which ships the log entries asynchronously. The entries are
+// dropped when the buffer is full so that logging never blocks.
+type Forwarder struct {
+	sink          Sink
+	labels        map[string]string
+	batchSize     int
+	flushInterval time.Duration
+	formatter     log.Formatter
+
+	recordCh chan *Record
+	dropped  uint64
+	reported uint64
+
+	stopOnce sync.Once
+	stopCh   chan struct{}
+	doneCh   chan struct{}
+}
+
+// NewSink creates a sink from the config.
+func NewSink(cfg config.LogRemoteConfig) (Sink, error) {
+	switch cfg.Type {
+	case config.LogRemoteSyslog:
+		return newSyslogSink(cfg.URL)
+	case config.LogRemoteLoki, config.LogRemoteHTTP:
+		client, err := newHTTPClient(cfg)
+		if err != nil {
+			return nil, err
+		}
+		if cfg.Type == config.LogRemoteLoki {
+			return newLokiSink(client, cfg), nil
+		}
+		return newHTTPSink(client, cfg), nil
+	default:
+		return nil, fmt.Errorf("unknown remote log type: %s", cfg.Type)
+	}
+}
+
+// NewForwarder creates a new forwarder which ships the records to the sink. The labels are
+// added to every record.
+func NewForwarder(sink Sink, cfg config.LogRemoteConfig, labels map[string]string) *Forwarder {
+	allLabels := make(map[string]string)
+	for k, v := range cfg.Labels {
+		allLabels[k] = v
+	}
+	for k, v := range labels {
+		allLabels[k] = v
+	}
+	flushInterval := cfg.FlushInterval
+	if flushInterval <= 0 {
+		flushInterval = defaultFlushInterval
+	}
+	batchSize := cfg.BatchSize
+	if batchSize <= 0 {
+		batchSize = 1
+	}
+	bufferSize := cfg.BufferSize
+	if bufferSize <= 0 {
+		bufferSize = batchSize
+	}
+	fwd := &Forwarder{
+		sink:          sink,
+		labels:        allLabels,
+		batchSize:     batchSize,
+		flushInterval: flushInterval,
+		formatter:     &log.JSONFormatter{},
+		recordCh:      make(chan *Record, bufferSize),
+		stopCh:        make(chan struct{}),
+		doneCh:        make(chan struct{}),
+	}
+	go fwd.loop()
+	return fwd
+}
+
+// Install creates a forwarder from the config and adds it to the standard logger.
+func Install(cfg config.LogRemoteConfig, labels map[string]string) (*Forwarder, error) {
+	sink, err := NewSink(cfg)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create the remote log sink: %v", err)
+	}
+	fwd := NewForwarder(sink, cfg, labels)
+	log.AddHook(fwd)
+	return fwd, nil
+}
+
+// Levels implements the logrus.Hook interface.
+func (fwd *Forwarder) Levels() []log.Level {
+	return log.AllLevels
+}
+
+// Fire implements the logrus.Hook interface.
+func (fwd *Forwarder) Fire(entry *log.Entry) error {
+	b, err := fwd.formatter.Format(entry)
+	if err != nil {
+		return err
+	}
+	labels := make(map[string]string, len(fwd.labels)+2)
+	for k, v := range fwd.labels {
+		labels[k] = v
+	}
+	labels[LabelLevel] = entry.Level.String()
+	if component, ok := entry.Data[LabelComponent].(string); ok {
+		labels[LabelComponent] = component
+	}
+	fwd.enqueue(&Record{
+		Time:   entry.Time,
+		Level:  entry.Level.String(),
+		Labels: labels,
+		Line:   strings.TrimSpace(string(b)),
+	})
+	return nil
+}
+
+func (fwd *Forwarder) enqueue(record *Record) {
+	select {
+	case fwd.recordCh <- record:
+	default:
+		atomic.AddUint64(&fwd.dropped, 1)
+	}
+}
+
+// Dropped returns the number of the dropped records.
+func (fwd *Forwarder) Dropped() uint64 {
+	return atomic.LoadUint64(&fwd.dropped)
+}
+
+// Close flushes the buffered records and closes the sink.
+func (fwd *Forwarder) Close() error {
+	fwd.stopOnce.Do(func() {
+		close(fwd.stopCh)
+	})
+	<-fwd.doneCh
+	return fwd.sink.Close()
+}
+
+func (fwd *Forwarder) loop() {
+	defer close(fwd.doneCh)
+
+	ticker := time.NewTicker(fwd.flushInterval)
+	defer ticker.Stop()
+
+	var batch []*Record
+	for {
+		select {
+		case record := <-fwd.recordCh:
+			batch = append(batch, record)
+			if len(batch) >= fwd.batchSize {
+				batch = fwd.flush(batch)
+			}
+
+		case <-ticker.C:
+			batch = fwd.flush(batch)
+
+		case <-fwd.stopCh:
+			for {
+				select {
+				case record := <-fwd.recordCh:
+					batch = append(batch, record)
+				default:
+					fwd.flush(batch)
+					return
+				}
+			}
+		}
+	}
+}
+
+// flush sends the batch and returns an empty batch to reuse.
+func (fwd *Forwarder) flush(batch []*Record) []*Record {
+	// report the drops in the same stream
+	dropped := fwd.Dropped()
+	if dropped > fwd.reported {
+		labels := make(map[string]string, len(fwd.labels)+1)
+		for k, v := range fwd.labels {
+			labels[k] = v
+		}
+		labels[LabelLevel] = log.WarnLevel.String()
+		batch = append(batch, &Record{
+			Time:   time.Now(),
+			Level:  log.WarnLevel.String(),
+			Labels: labels,
+			Line:   fmt.Sprintf(`{"level":"warning","msg":"dropped %d log entries"}`, dropped-fwd.reported),
+		})
+		fwd.reported = dropped
+	}
+	if len(batch) == 0 {
+		return batch
+	}
+	if err := fwd.sink.Send(batch); err != nil {
+		// logging here would loop back to the forwarder
+		fmt.Fprintf(os.Stderr, "failed to forward %d log entries: %v\n", len(batch), err)
+		atomic.AddUint64(&fwd.dropped, uint64(len(batch)))
+		fwd.reported += uint64(len(batch))
+	}
+	return batch[:0]
+}
+
+func newHTTPClient(cfg config.LogRemoteConfig) (*http.Client, error) {
+	tlsConfig := &tls.Config{InsecureSkipVerify: cfg.TLSInsecureSkipVerify}
+	if len(cfg.TLSCAFile) > 0 {
+		caCert, err := os.ReadFile(cfg.TLSCAFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read the ca file: %v", err)
+		}
+		certPool := x509.NewCertPool()
+		if !certPool.AppendCertsFromPEM(caCert) {
+			return nil, fmt.Errorf("no certificates found in the ca file %s", cfg.TLSCAFile)
+		}
+		tlsConfig.RootCAs = certPool
+	}
+	return &http.Client{
+		Timeout:   time.Second * 10,
+		Transport: &http.Transport{TLSClientConfig: tlsConfig, Proxy: http.ProxyFromEnvironment},
+	}, nil
+}
@@ -0,0 +1,160 @@
+package logforward
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/forta-network/forta-node/config"
+	log "github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/require"
+)
+
+type testLokiServer struct {
+	*httptest.Server
+	mu       sync.Mutex
+	requests []*lokiPushRequest
+	username string
+	password string
+}
+
+func newTestLokiServer(r *require.Assertions) *testLokiServer {
+	srv := &testLokiServer{}
+	srv.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		r.Equal(lokiPushPath, req.URL.Path)
+		var pushReq lokiPushRequest
+		r.NoError(json.NewDecoder(req.Body).Decode(&pushReq))
+		srv.mu.Lock()
+		defer srv.mu.Unlock()
+		srv.requests = append(srv.requests, &pushReq)
+		srv.username, srv.password, _ = req.BasicAuth()
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	return srv
+}
+
+func (srv *testLokiServer) pushRequests() []*lokiPushRequest {
+	srv.mu.Lock()
+	defer srv.mu.Unlock()
+	return srv.requests
+}
+
+func TestForwarder_Loki(t *testing.T) {
+	r := require.New(t)
+
+	srv := newTestLokiServer(r)
+	defer srv.Close()
+
+	cfg := config.LogRemoteConfig{
+		Type:          config.LogRemoteLoki,
+		URL:           srv.URL,
+		Labels:        map[string]string{"node": "node-1"},
+		BufferSize:    100,
+		BatchSize:     3,
+		FlushInterval: time.Hour,
+		Username:      "user",
+		Password:      "pass",
+	}
+	sink, err := NewSink(cfg)
+	r.NoError(err)
+	fwd := NewForwarder(sink, cfg, map[string]string{LabelContainer: "forta-scanner"})
+
+	logger := log.New()
+	logger.SetOutput(io.Discard)
+	logger.AddHook(fwd)
+	logger.WithField(LabelComponent, "scanner").Info("first")
+	logger.WithField(LabelComponent, "scanner").Info("second")
+	logger.WithField(LabelComponent, "scanner").Warn("third")
+	logger.WithField(LabelComponent, "scanner").Info("fourth")
+
+	// the first batch is sent when it is full
+	r.Eventually(func() bool {
+		return len(srv.pushRequests()) == 1
+	}, time.Second*5, time.Millisecond*10)
+
+	// the rest is sent when closing
+	r.NoError(fwd.Close())
+	requests := srv.pushRequests()
+	r.Len(requests, 2)
+	r.Equal("user", srv.username)
+	r.Equal("pass", srv.password)
+
+	// the first batch has the info and the warning streams
+	r.Len(requests[0].Streams, 2)
+	infoStream := requests[0].Streams[0]
+	r.Equal(map[string]string{
+		"node":         "node-1",
+		LabelContainer: "forta-scanner",
+		LabelComponent: "scanner",
+		LabelLevel:     "info",
+	}, infoStream.Stream)
+	r.Len(infoStream.Values, 2)
+	r.Contains(infoStream.Values[0][1], `"msg":"first"`)
+	r.Contains(infoStream.Values[1][1], `"msg":"second"`)
+	r.Equal("warning", requests[0].Streams[1].Stream[LabelLevel])
+
+	r.Len(requests[1].Streams, 1)
+	r.Contains(requests[1].Streams[0].Values[0][1], `"msg":"fourth"`)
+}
+
+// blockingSink blocks until released.
+type blockingSink struct {
+	releaseCh chan struct{}
+	mu        sync.Mutex
+	records   []*Record
+}
+
+func (sink *blockingSink) Send(records []*Record) error {
+	<-sink.releaseCh
+	sink.mu.Lock()
+	defer sink.mu.Unlock()
+	sink.records = append(sink.records, records...)
+	return nil
+}
+
+func (sink *blockingSink) Close() error {
+	return nil
+}
+
+func TestForwarder_DropsWhenFull(t *testing.T) {
+	r := require.New(t)
+
+	sink := &blockingSink{releaseCh: make(chan struct{})}
+	fwd := NewForwarder(sink, config.LogRemoteConfig{
+		BufferSize:    2,
+		BatchSize:     1,
+		FlushInterval: time.Hour,
+	}, nil)
+
+	logger := log.New()
+	logger.SetOutput(io.Discard)
+	logger.AddHook(fwd)
+
+	// the first one blocks the sink and the next two fill the buffer
+	logger.Info("first")
+	r.Eventually(func() bool {
+		return len(fwd.recordCh) == 0
+	}, time.Second*5, time.Millisecond*10)
+	start := time.Now()
+	for i := 0; i < 5; i++ {
+		logger.Info("other")
+	}
+	r.Less(time.Since(start), time.Second)
+	r.Equal(uint64(3), fwd.Dropped())
+
+	close(sink.releaseCh)
+	r.NoError(fwd.Close())
+
+	// the drops are reported to the sink
+	r.Len(sink.records, 4)
+	var reported bool
+	for _, record := range sink.records {
+		reported = reported || strings.Contains(record.Line, "dropped 3 log entries")
+	}
+	r.True(reported)
+}
@@ -0,0 +1,185 @@
+package logforward
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/syslog"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/forta-network/forta-node/config"
+)
+
+const lokiPushPath = "/loki/api/v1/push"
+
+type httpSink struct {
+	client   *http.Client
+	url      string
+	username string
+	password string
+}
+
+func newHTTPSink(client *http.Client, cfg config.LogRemoteConfig) *httpSink {
+	return &httpSink{client: client, url: cfg.URL, username: cfg.Username, password: cfg.Password}
+}
+
+// Send sends the records as a JSON array.
+func (sink *httpSink) Send(records []*Record) error {
+	b, err := json.Marshal(records)
+	if err != nil {
+		return err
+	}
+	return sink.post(b)
+}
+
+func (sink *httpSink) post(body []byte) error {
+	req, err := http.NewRequest(http.MethodPost, sink.url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if len(sink.username) > 0 {
+		req.SetBasicAuth(sink.username, sink.password)
+	}
+	resp, err := sink.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		b, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return fmt.Errorf("endpoint responded with '%d': %s", resp.StatusCode, string(b))
+	}
+	return nil
+}
+
+// Close implements the Sink interface.
+func (sink *httpSink) Close() error {
+	return nil
+}
+
+type lokiSink struct {
+	*httpSink
+}
+
+func newLokiSink(client *http.Client, cfg config.LogRemoteConfig) *lokiSink {
+	sink := newHTTPSink(client, cfg)
+	if !strings.HasSuffix(sink.url, lokiPushPath) {
+		sink.url = strings.TrimSuffix(sink.url, "/") + lokiPushPath
+	}
+	return &lokiSink{httpSink: sink}
+}
+
+type lokiStream struct {
+	Stream map[string]string `json:"stream"`
+	Values [][2]string       `json:"values"`
+}
+
+type lokiPushRequest struct {
+	Streams []*lokiStream `json:"streams"`
+}
+
+// Send groups the records by the label sets and pushes them as Loki streams.
+func (sink *lokiSink) Send(records []*Record) error {
+	var req lokiPushRequest
+	streams := make(map[string]*lokiStream)
+	for _, record := range records {
+		key := labelsKey(record.Labels)
+		stream, ok := streams[key]
+		if !ok {
+			stream = &lokiStream{Stream: record.Labels}
+			streams[key] = stream
+			req.Streams = append(req.Streams, stream)
+		}
+		stream.Values = append(stream.Values, [2]string{
+			strconv.FormatInt(record.Time.UnixNano(), 10), record.Line,
+		})
+	}
+	b, err := json.Marshal(&req)
+	if err != nil {
+		return err
+	}
+	return sink.post(b)
+}
+
+func labelsKey(labels map[string]string) string {
+	var keys []string
+	for k := range labels {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	var sb strings.Builder
+	for _, k := range keys {
+		sb.WriteString(k)
+		sb.WriteByte('=')
+		sb.WriteString(labels[k])
+		sb.WriteByte(',')
+	}
+	return sb.String()
+}
+
+type syslogSink struct {
+	network string
+	addr    string
+	writer  *syslog.Writer
+}
+
+// newSyslogSink creates a sink for a url like udp://host:514 or tcp://host:514.
+func newSyslogSink(rawurl string) (*syslogSink, error) {
+	u, err := url.Parse(rawurl)
+	if err != nil {
+		return nil, fmt.Errorf("invalid syslog url: %v", err)
+	}
+	if u.Scheme != "udp" && u.Scheme != "tcp" {
+		return nil, fmt.Errorf("syslog url scheme must be udp or tcp: %s", rawurl)
+	}
+	return &syslogSink{network: u.Scheme, addr: u.Host}, nil
+}
+
+// Send writes the records with the matching syslog severities.
+func (sink *syslogSink) Send(records []*Record) error {
+	if sink.writer == nil {
+		writer, err := syslog.Dial(sink.network, sink.addr, syslog.LOG_INFO|syslog.LOG_DAEMON, "forta")
+		if err != nil {
+			return err
+		}
+		sink.writer = writer
+	}
+	for _, record := range records {
+		if err := sink.write(record); err != nil {
+			// dial again for the next batch
+			sink.writer.Close()
+			sink.writer = nil
+			return err
+		}
+	}
+	return nil
+}
+
+func (sink *syslogSink) write(record *Record) error {
+	switch record.Level {
+	case "panic", "fatal":
+		return sink.writer.Crit(record.Line)
+	case "error":
+		return sink.writer.Err(record.Line)
+	case "warning":
+		return sink.writer.Warning(record.Line)
+	case "info":
+		return sink.writer.Info(record.Line)
+	default:
+		return sink.writer.Debug(record.Line)
+	}
+}
+
+// Close implements the Sink interface.
+func (sink *syslogSink) Close() error {
+	if sink.writer == nil {
+		return nil
+	}
+	return sink.writer.Close()
+}
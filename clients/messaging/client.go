@@ -2,9 +2,11 @@ package messaging
 
 import (
 	"fmt"
+	"sync"
 	"time"
 
 	"github.com/forta-network/forta-core-go/protocol"
+	"github.com/forta-network/forta-node/config"
 	"github.com/goccy/go-json"
 	"github.com/golang/protobuf/proto"
 	"github.com/nats-io/nats.go"
@@ -16,51 +18,207 @@ var (
 	BufferSize = 1000
 )
 
-// Client wraps the NATS client to publish and receive our messages.
+// Client wraps the NATS client to publish and receive our messages. It reconnects with backoff
+// when the connection is lost, subscribes again and publishes the messages buffered while
+// it was disconnected.
 type Client struct {
 	logger *log.Entry
-	nc     *nats.Conn
+	url    string
+	cfg    config.NatsConfig
+
+	mu             sync.Mutex
+	nc             *nats.Conn
+	subs           []*subscription
+	buffered       []*bufferedMsg
+	dropped        int
+	reconnects     int
+	disconnectedAt time.Time
+	closed         bool
+}
+
+type subscription struct {
+	subject string
+	handler nats.MsgHandler
+}
+
+type bufferedMsg struct {
+	subject string
+	data    []byte
 }
 
+// initialConnectAttempts is how many times the client tries to connect before giving up.
+const initialConnectAttempts = 10
+
 // NewClient creates and starts a new client.
-func NewClient(name, natsURL string) *Client {
+func NewClient(name string, cfg config.NatsConfig) *Client {
+	natsURL := cfg.ServerURL()
 	logger := log.WithField("name", fmt.Sprintf("%s/messaging", name)).WithField("nats", natsURL)
 	logger.Infof("connecting to: %s", natsURL)
+	client := &Client{
+		logger: logger,
+		url:    natsURL,
+		cfg:    cfg,
+	}
 	var (
 		nc  *nats.Conn
 		err error
 	)
-	for i := 0; i < 10; i++ {
-		nc, err = nats.Connect(natsURL)
+	for i := 0; i < initialConnectAttempts; i++ {
+		nc, err = client.connect()
 		if err == nil {
 			break
 		}
 		logger.WithError(err).Error("failed to connect to nats server")
-		time.Sleep(time.Second * 1) // don't retry too quickly - maybe it's not up yet
+		time.Sleep(client.backoff(i)) // don't retry too quickly - maybe it's not up yet
 	}
 	if err != nil {
 		logger.Panic(err)
 	}
 	logger.Info("successfully connected")
-	client := &Client{
-		logger: logger,
-		nc:     nc,
-	}
+	client.nc = nc
 	return client
 }
 
+func (client *Client) connect() (*nats.Conn, error) {
+	opts := []nats.Option{
+		nats.NoReconnect(), // reconnected by the client so that the wait can back off
+		nats.ClosedHandler(client.handleClosed),
+	}
+	if len(client.cfg.User) > 0 {
+		opts = append(opts, nats.UserInfo(client.cfg.User, client.cfg.Password))
+	}
+	if len(client.cfg.NkeySeedFile) > 0 {
+		nkeyOpt, err := nats.NkeyOptionFromSeed(client.cfg.NkeySeedFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load the nkey seed: %v", err)
+		}
+		opts = append(opts, nkeyOpt)
+	}
+	tlsCfg := client.cfg.TLS
+	if tlsCfg.Enable {
+		opts = append(opts, nats.Secure())
+	}
+	if len(tlsCfg.CAFile) > 0 {
+		opts = append(opts, nats.RootCAs(tlsCfg.CAFile))
+	}
+	if len(tlsCfg.CertFile) > 0 {
+		opts = append(opts, nats.ClientCert(tlsCfg.CertFile, tlsCfg.KeyFile))
+	}
+	return nats.Connect(client.url, opts...)
+}
+
+// backoff doubles the reconnect wait after each failed attempt.
+func (client *Client) backoff(attempt int) time.Duration {
+	wait := client.cfg.ReconnectWait
+	if wait <= 0 {
+		wait = time.Second
+	}
+	for i := 0; i < attempt && wait < client.cfg.MaxReconnectWait; i++ {
+		wait *= 2
+	}
+	if client.cfg.MaxReconnectWait > 0 && wait > client.cfg.MaxReconnectWait {
+		wait = client.cfg.MaxReconnectWait
+	}
+	return wait
+}
+
+// handleClosed starts reconnecting if the connection was lost.
+func (client *Client) handleClosed(nc *nats.Conn) {
+	client.mu.Lock()
+	defer client.mu.Unlock()
+	if client.closed || client.nc != nc {
+		return
+	}
+	client.nc = nil
+	client.disconnectedAt = time.Now()
+	client.logger.WithError(nc.LastError()).Warn("disconnected from nats server - reconnecting")
+	go client.reconnect()
+}
+
+func (client *Client) reconnect() {
+	for attempt := 0; ; attempt++ {
+		time.Sleep(client.backoff(attempt))
+		if client.isClosed() {
+			return
+		}
+		nc, err := client.connect()
+		if err != nil {
+			client.logger.WithError(err).WithField("attempt", attempt+1).Warn("failed to reconnect to nats server")
+			continue
+		}
+
+		client.mu.Lock()
+		if client.closed {
+			client.mu.Unlock()
+			nc.Close()
+			return
+		}
+		client.nc = nc
+		client.reconnects++
+		for _, sub := range client.subs {
+			if _, err := nc.Subscribe(sub.subject, sub.handler); err != nil {
+				client.logger.WithError(err).WithField("subject", sub.subject).Error("failed to subscribe again")
+			}
+		}
+		buffered := client.buffered
+		client.buffered = nil
+		for _, msg := range buffered {
+			client.publishUnsafe(msg.subject, msg.data)
+		}
+		client.mu.Unlock()
+
+		client.logger.WithField("published", len(buffered)).Info("reconnected to nats server")
+		return
+	}
+}
+
+func (client *Client) isClosed() bool {
+	client.mu.Lock()
+	defer client.mu.Unlock()
+	return client.closed
+}
+
+// Close closes the connection and stops reconnecting.
+func (client *Client) Close() {
+	client.mu.Lock()
+	defer client.mu.Unlock()
+	client.closed = true
+	if client.nc != nil {
+		client.nc.Close()
+		client.nc = nil
+	}
+}
+
 // AgentsHandler handles agents.* subjects.
 type AgentsHandler func(AgentPayload) error
 type SubscriptionHandler func(SubscriptionPayload) error
 type AgentMetricHandler func(*protocol.AgentMetricList) error
 type InspectionResultsHandler func(results *protocol.InspectionResults) error
 type ScannerHandler func(ScannerPayload) error
+type DrainHandler func(DrainPayload) error
 
 // Subscribe subscribes the consumer to this client.
 func (client *Client) Subscribe(subject string, handler interface{}) {
 	// TODO: Configure redelivery options somehow.
 	logger := client.logger.WithField("subject", subject)
-	_, err := client.nc.Subscribe(subject, func(m *nats.Msg) {
+	sub := &subscription{subject: subject, handler: msgHandler(logger, handler)}
+
+	client.mu.Lock()
+	defer client.mu.Unlock()
+	client.subs = append(client.subs, sub)
+	// subscribed after reconnecting
+	if client.nc == nil {
+		logger.Info("subscribed while disconnected")
+		return
+	}
+	if _, err := client.nc.Subscribe(subject, sub.handler); err != nil {
+		logger.Panicf("failed to subscribe: %v", err)
+	}
+	logger.Info("subscribed")
+}
+
+func msgHandler(logger *log.Entry, handler interface{}) nats.MsgHandler {
+	return func(m *nats.Msg) {
 		logger.Debugf("received: %s", string(m.Data))
 
 		var err error
@@ -104,6 +262,14 @@ func (client *Client) Subscribe(subject string, handler interface{}) {
 			}
 			err = h(payload)
 
+		case DrainHandler:
+			var payload DrainPayload
+			err = json.Unmarshal(m.Data, &payload)
+			if err != nil {
+				break
+			}
+			err = h(payload)
+
 		default:
 			logger.Panicf("no handler found")
 		}
@@ -115,29 +281,49 @@ func (client *Client) Subscribe(subject string, handler interface{}) {
 			// }
 			logger.Errorf("failed to handle msg: %v", err)
 		}
-	})
-	if err != nil {
-		logger.Panicf("failed to subscribe: %v", err)
 	}
-	logger.Info("subscribed")
 }
 
 // Publish publishes new messages.
 func (client *Client) Publish(subject string, payload interface{}) {
-	logger := client.logger.WithField("subject", subject)
 	data, _ := json.Marshal(payload)
-	if err := client.nc.Publish(subject, data); err != nil {
-		logger.Errorf("failed to publish msg: %v", err)
-	}
-	logger.Debugf("published: %s", string(data))
+	client.publish(subject, data)
 }
 
 // PublishProto publishes new messages.
 func (client *Client) PublishProto(subject string, payload proto.Message) {
-	logger := client.logger.WithField("subject", subject)
 	data, _ := proto.Marshal(payload)
-	if err := client.nc.Publish(subject, data); err != nil {
-		logger.Errorf("failed to publish msg: %v", err)
+	client.publish(subject, data)
+}
+
+func (client *Client) publish(subject string, data []byte) {
+	client.mu.Lock()
+	defer client.mu.Unlock()
+	client.publishUnsafe(subject, data)
+}
+
+// publishUnsafe publishes the message or buffers it if the client is disconnected.
+func (client *Client) publishUnsafe(subject string, data []byte) {
+	logger := client.logger.WithField("subject", subject)
+	if client.nc != nil {
+		err := client.nc.Publish(subject, data)
+		if err == nil {
+			logger.Debugf("published: %s", string(data))
+			return
+		}
+		if err != nats.ErrConnectionClosed {
+			logger.Errorf("failed to publish msg: %v", err)
+			return
+		}
+	}
+	if client.closed {
+		logger.Error("failed to publish msg: client is closed")
+		return
+	}
+	client.buffered = append(client.buffered, &bufferedMsg{subject: subject, data: data})
+	if len(client.buffered) > client.cfg.MaxBufferedMessages {
+		client.buffered = client.buffered[1:]
+		client.dropped++
+		logger.Warn("message buffer is full - dropped the oldest message")
 	}
-	logger.Debugf("published: %s", string(data))
 }
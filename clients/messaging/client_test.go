@@ -0,0 +1,131 @@
+package messaging
+
+import (
+	"net"
+	"testing"
+	"time"
+
+	"github.com/forta-network/forta-core-go/clients/health"
+	"github.com/forta-network/forta-node/config"
+	"github.com/nats-io/nats-server/v2/server"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	testNatsUser     = "forta"
+	testNatsPassword = "secret"
+)
+
+func freePort(t *testing.T) int {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer l.Close()
+	return l.Addr().(*net.TCPAddr).Port
+}
+
+func runNatsServer(t *testing.T, port int) *server.Server {
+	srv, err := server.NewServer(&server.Options{
+		Host:     "127.0.0.1",
+		Port:     port,
+		Username: testNatsUser,
+		Password: testNatsPassword,
+		NoLog:    true,
+		NoSigs:   true,
+	})
+	require.NoError(t, err)
+	go srv.Start()
+	require.True(t, srv.ReadyForConnections(time.Second*5))
+	return srv
+}
+
+func testNatsConfig(srv *server.Server) config.NatsConfig {
+	return config.NatsConfig{
+		External:            true,
+		URL:                 srv.ClientURL(),
+		User:                testNatsUser,
+		Password:            testNatsPassword,
+		ReconnectWait:       time.Millisecond * 100,
+		MaxReconnectWait:    time.Millisecond * 200,
+		MaxBufferedMessages: 2,
+	}
+}
+
+func receiveAgents(t *testing.T, ch chan AgentPayload) AgentPayload {
+	select {
+	case payload := <-ch:
+		return payload
+	case <-time.After(time.Second * 5):
+		require.FailNow(t, "message not received")
+	}
+	return nil
+}
+
+func reportByName(reports health.Reports, name string) *health.Report {
+	report, _ := reports.NameContains(name)
+	return report
+}
+
+func TestClientAuth(t *testing.T) {
+	r := require.New(t)
+
+	srv := runNatsServer(t, freePort(t))
+	defer srv.Shutdown()
+
+	// wrong credentials are rejected
+	cfg := testNatsConfig(srv)
+	cfg.Password = "wrong"
+	_, err := (&Client{url: cfg.ServerURL(), cfg: cfg}).connect()
+	r.Error(err)
+
+	client := NewClient("test", testNatsConfig(srv))
+	defer client.Close()
+
+	received := make(chan AgentPayload, 1)
+	client.Subscribe(SubjectAgentsActionRun, AgentsHandler(func(payload AgentPayload) error {
+		received <- payload
+		return nil
+	}))
+	client.Publish(SubjectAgentsActionRun, AgentPayload{{ID: "agent-1"}})
+	r.Equal("agent-1", receiveAgents(t, received)[0].ID)
+	r.Equal(health.StatusOK, reportByName(client.Health(), "nats.connection").Status)
+}
+
+func TestClientReconnect(t *testing.T) {
+	r := require.New(t)
+
+	port := freePort(t)
+	srv := runNatsServer(t, port)
+	client := NewClient("test", testNatsConfig(srv))
+	defer client.Close()
+
+	received := make(chan AgentPayload, 10)
+	client.Subscribe(SubjectAgentsActionRun, AgentsHandler(func(payload AgentPayload) error {
+		received <- payload
+		return nil
+	}))
+
+	srv.Shutdown()
+	r.Eventually(func() bool {
+		return reportByName(client.Health(), "nats.connection").Status == health.StatusFailing
+	}, time.Second*5, time.Millisecond*10)
+
+	// the oldest message is dropped when the buffer is full
+	for _, agentID := range []string{"agent-1", "agent-2", "agent-3"} {
+		client.Publish(SubjectAgentsActionRun, AgentPayload{{ID: agentID}})
+	}
+	reports := client.Health()
+	r.Equal("2", reportByName(reports, "nats.buffered").Details)
+	r.Equal("1", reportByName(reports, "nats.dropped").Details)
+	r.Equal(health.StatusFailing, reportByName(reports, "nats.dropped").Status)
+
+	// the buffered messages are received after subscribing again
+	srv = runNatsServer(t, port)
+	defer srv.Shutdown()
+	r.Equal("agent-2", receiveAgents(t, received)[0].ID)
+	r.Equal("agent-3", receiveAgents(t, received)[0].ID)
+
+	reports = client.Health()
+	r.Equal(health.StatusOK, reportByName(reports, "nats.connection").Status)
+	r.Equal("1", reportByName(reports, "nats.reconnects").Details)
+	r.Equal("0", reportByName(reports, "nats.buffered").Details)
+}
@@ -0,0 +1,56 @@
+package messaging
+
+import (
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/forta-network/forta-core-go/clients/health"
+)
+
+// Name returns the name of the client.
+func (client *Client) Name() string {
+	return "messaging"
+}
+
+// Health implements the health.Reporter interface.
+func (client *Client) Health() health.Reports {
+	client.mu.Lock()
+	defer client.mu.Unlock()
+
+	connection := &health.Report{
+		Name:    "nats.connection",
+		Status:  health.StatusOK,
+		Details: "connected",
+	}
+	switch {
+	case client.closed:
+		connection.Status = health.StatusDown
+		connection.Details = "closed"
+	case client.nc == nil:
+		connection.Status = health.StatusFailing
+		connection.Details = fmt.Sprintf("disconnected since %s", client.disconnectedAt.UTC().Format(time.RFC3339))
+	}
+	droppedStatus := health.StatusOK
+	if client.dropped > 0 {
+		droppedStatus = health.StatusFailing
+	}
+	return health.Reports{
+		connection,
+		&health.Report{
+			Name:    "nats.reconnects",
+			Status:  health.StatusInfo,
+			Details: strconv.Itoa(client.reconnects),
+		},
+		&health.Report{
+			Name:    "nats.buffered",
+			Status:  health.StatusInfo,
+			Details: strconv.Itoa(len(client.buffered)),
+		},
+		&health.Report{
+			Name:    "nats.dropped",
+			Status:  droppedStatus,
+			Details: strconv.Itoa(client.dropped),
+		},
+	}
+}
@@ -10,6 +10,9 @@ const (
 	SubjectAgentsVersionsLatest   = "agents.versions.latest"
 	SubjectAgentsActionRun        = "agents.action.run"
 	SubjectAgentsActionStop       = "agents.action.stop"
+	SubjectAgentsActionRunOnce    = "agents.action.run-once"
+	SubjectAgentsActionRestart    = "agents.action.restart"
+	SubjectAgentsActionReset      = "agents.action.reset"
 	SubjectAgentsAlertSubscribe   = "agents.alert.subscribe"
 	SubjectAgentsAlertUnsubscribe = "agents.alert.unsubscribe"
 	SubjectAgentsStatusRunning    = "agents.status.running"
@@ -19,6 +22,8 @@ const (
 	SubjectScannerBlock           = "scanner.block"
 	SubjectScannerAlert           = "scanner.alert"
 	SubjectInspectionDone         = "inspection.done"
+	SubjectNodeDrain              = "node.drain"
+	SubjectNodeDrainState         = "node.drain.state"
 )
 
 // AgentPayload is the message payload.
@@ -36,3 +41,11 @@ type SubscriptionPayload []CombinerBotSubscription
 type ScannerPayload struct {
 	LatestBlockInput uint64 `json:"latestBlockInput"`
 }
+
+// DrainPayload is the message payload for the drain state of the scanner.
+type DrainPayload struct {
+	Drained          bool `json:"drained"`
+	InFlightRequests int  `json:"inFlightRequests"`
+	PendingAlerts    int  `json:"pendingAlerts"`
+	QueuedBatches    int  `json:"queuedBatches"`
+}
@@ -6,7 +6,9 @@ package mock_clients
 
 import (
 	context "context"
+	io "io"
 	reflect "reflect"
+	time "time"
 
 	types "github.com/docker/docker/api/types"
 	domain "github.com/forta-network/forta-core-go/domain"
@@ -145,6 +147,21 @@ func (mr *MockDockerClientMockRecorder) GetContainerLogs(ctx, containerID, tail,
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetContainerLogs", reflect.TypeOf((*MockDockerClient)(nil).GetContainerLogs), ctx, containerID, tail, truncate)
 }
 
+// GetAllContainers mocks base method.
+func (m *MockDockerClient) GetAllContainers(ctx context.Context) (clients.DockerContainerList, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetAllContainers", ctx)
+	ret0, _ := ret[0].(clients.DockerContainerList)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetAllContainers indicates an expected call of GetAllContainers.
+func (mr *MockDockerClientMockRecorder) GetAllContainers(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAllContainers", reflect.TypeOf((*MockDockerClient)(nil).GetAllContainers), ctx)
+}
+
 // GetContainers mocks base method.
 func (m *MockDockerClient) GetContainers(ctx context.Context) (clients.DockerContainerList, error) {
 	m.ctrl.T.Helper()
@@ -160,6 +177,21 @@ func (mr *MockDockerClientMockRecorder) GetContainers(ctx interface{}) *gomock.C
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetContainers", reflect.TypeOf((*MockDockerClient)(nil).GetContainers), ctx)
 }
 
+// GetDockerRootDir mocks base method.
+func (m *MockDockerClient) GetDockerRootDir(ctx context.Context) (string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetDockerRootDir", ctx)
+	ret0, _ := ret[0].(string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetDockerRootDir indicates an expected call of GetDockerRootDir.
+func (mr *MockDockerClientMockRecorder) GetDockerRootDir(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetDockerRootDir", reflect.TypeOf((*MockDockerClient)(nil).GetDockerRootDir), ctx)
+}
+
 // GetFortaServiceContainers mocks base method.
 func (m *MockDockerClient) GetFortaServiceContainers(ctx context.Context) (clients.DockerContainerList, error) {
 	m.ctrl.T.Helper()
@@ -175,6 +207,36 @@ func (mr *MockDockerClientMockRecorder) GetFortaServiceContainers(ctx interface{
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetFortaServiceContainers", reflect.TypeOf((*MockDockerClient)(nil).GetFortaServiceContainers), ctx)
 }
 
+// GetImageDigests mocks base method.
+func (m *MockDockerClient) GetImageDigests(ctx context.Context, ref string) ([]string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetImageDigests", ctx, ref)
+	ret0, _ := ret[0].([]string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetImageDigests indicates an expected call of GetImageDigests.
+func (mr *MockDockerClientMockRecorder) GetImageDigests(ctx, ref interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetImageDigests", reflect.TypeOf((*MockDockerClient)(nil).GetImageDigests), ctx, ref)
+}
+
+// GetImages mocks base method.
+func (m *MockDockerClient) GetImages(ctx context.Context) ([]types.ImageSummary, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetImages", ctx)
+	ret0, _ := ret[0].([]types.ImageSummary)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetImages indicates an expected call of GetImages.
+func (mr *MockDockerClientMockRecorder) GetImages(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetImages", reflect.TypeOf((*MockDockerClient)(nil).GetImages), ctx)
+}
+
 // HasLocalImage mocks base method.
 func (m *MockDockerClient) HasLocalImage(ctx context.Context, ref string) bool {
 	m.ctrl.T.Helper()
@@ -274,6 +336,20 @@ func (mr *MockDockerClientMockRecorder) RemoveContainer(ctx, containerID interfa
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RemoveContainer", reflect.TypeOf((*MockDockerClient)(nil).RemoveContainer), ctx, containerID)
 }
 
+// RemoveImage mocks base method.
+func (m *MockDockerClient) RemoveImage(ctx context.Context, ref string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RemoveImage", ctx, ref)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RemoveImage indicates an expected call of RemoveImage.
+func (mr *MockDockerClientMockRecorder) RemoveImage(ctx, ref interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RemoveImage", reflect.TypeOf((*MockDockerClient)(nil).RemoveImage), ctx, ref)
+}
+
 // RemoveNetworkByName mocks base method.
 func (m *MockDockerClient) RemoveNetworkByName(ctx context.Context, networkName string) error {
 	m.ctrl.T.Helper()
@@ -304,31 +380,45 @@ func (mr *MockDockerClientMockRecorder) StartContainer(ctx, config interface{})
 }
 
 // StopContainer mocks base method.
-func (m *MockDockerClient) StopContainer(ctx context.Context, id string) error {
+func (m *MockDockerClient) StopContainer(ctx context.Context, id string, timeout time.Duration) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "StopContainer", ctx, id)
+	ret := m.ctrl.Call(m, "StopContainer", ctx, id, timeout)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
 // StopContainer indicates an expected call of StopContainer.
-func (mr *MockDockerClientMockRecorder) StopContainer(ctx, id interface{}) *gomock.Call {
+func (mr *MockDockerClientMockRecorder) StopContainer(ctx, id, timeout interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "StopContainer", reflect.TypeOf((*MockDockerClient)(nil).StopContainer), ctx, id, timeout)
+}
+
+// StreamContainerLogs mocks base method.
+func (m *MockDockerClient) StreamContainerLogs(ctx context.Context, containerID string, opts clients.ContainerLogsOptions, stdout, stderr io.Writer) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "StreamContainerLogs", ctx, containerID, opts, stdout, stderr)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// StreamContainerLogs indicates an expected call of StreamContainerLogs.
+func (mr *MockDockerClientMockRecorder) StreamContainerLogs(ctx, containerID, opts, stdout, stderr interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "StopContainer", reflect.TypeOf((*MockDockerClient)(nil).StopContainer), ctx, id)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "StreamContainerLogs", reflect.TypeOf((*MockDockerClient)(nil).StreamContainerLogs), ctx, containerID, opts, stdout, stderr)
 }
 
 // TerminateContainer mocks base method.
-func (m *MockDockerClient) TerminateContainer(ctx context.Context, id string) error {
+func (m *MockDockerClient) TerminateContainer(ctx context.Context, id string, timeout time.Duration) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "TerminateContainer", ctx, id)
+	ret := m.ctrl.Call(m, "TerminateContainer", ctx, id, timeout)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
 // TerminateContainer indicates an expected call of TerminateContainer.
-func (mr *MockDockerClientMockRecorder) TerminateContainer(ctx, id interface{}) *gomock.Call {
+func (mr *MockDockerClientMockRecorder) TerminateContainer(ctx, id, timeout interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "TerminateContainer", reflect.TypeOf((*MockDockerClient)(nil).TerminateContainer), ctx, id)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "TerminateContainer", reflect.TypeOf((*MockDockerClient)(nil).TerminateContainer), ctx, id, timeout)
 }
 
 // WaitContainerExit mocks base method.
@@ -0,0 +1,118 @@
+package clients
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path"
+	"sync"
+	"syscall"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+)
+
+const pullSlotPollInterval = time.Second
+
+// PullLimiter limits the number of the concurrent image pulls.
+type PullLimiter interface {
+	// Acquire waits until a pull can be started. The returned func must be called after the pull.
+	Acquire(ctx context.Context) (release func(), err error)
+}
+
+var (
+	pullLimiter   PullLimiter
+	pullLimiterMu sync.RWMutex
+)
+
+// SetPullLimiter sets the limiter which is shared by the image pulls of all docker clients.
+func SetPullLimiter(limiter PullLimiter) {
+	pullLimiterMu.Lock()
+	defer pullLimiterMu.Unlock()
+	pullLimiter = limiter
+}
+
+func acquirePullSlot(ctx context.Context) (func(), error) {
+	pullLimiterMu.RLock()
+	limiter := pullLimiter
+	pullLimiterMu.RUnlock()
+	if limiter == nil {
+		return func() {}, nil
+	}
+	return limiter.Acquire(ctx)
+}
+
+// filePullLimiter uses the lock files as the pull slots so that the limit is shared by the
+// processes and the containers which mount the same dir (e.g. the runner and the supervisor).
+type filePullLimiter struct {
+	dir   string
+	slots int
+}
+
+// NewFilePullLimiter creates a limiter which allows the given number of concurrent pulls among
+// all users of the dir.
+func NewFilePullLimiter(dir string, slots int) (PullLimiter, error) {
+	if slots <= 0 {
+		return nil, fmt.Errorf("invalid pull slot count: %d", slots)
+	}
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, fmt.Errorf("failed to create the pull locks dir: %v", err)
+	}
+	limiter := &filePullLimiter{dir: dir, slots: slots}
+	// create all slots early so that the users with less permissions can lock them later
+	for i := 0; i < slots; i++ {
+		f, err := limiter.openSlot(i)
+		if err != nil {
+			return nil, err
+		}
+		f.Close()
+	}
+	return limiter, nil
+}
+
+// openSlot opens the lock file of the slot. Read access is enough for locking.
+func (limiter *filePullLimiter) openSlot(i int) (*os.File, error) {
+	f, err := os.OpenFile(path.Join(limiter.dir, fmt.Sprintf("slot-%d", i)), os.O_CREATE|os.O_RDONLY, 0644)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open the pull slot: %v", err)
+	}
+	return f, nil
+}
+
+// Acquire implements the PullLimiter interface.
+func (limiter *filePullLimiter) Acquire(ctx context.Context) (func(), error) {
+	var waiting bool
+	for {
+		release, err := limiter.tryAcquire()
+		if err != nil || release != nil {
+			return release, err
+		}
+		if !waiting {
+			log.WithField("slots", limiter.slots).Info("waiting for the other image pulls to finish")
+			waiting = true
+		}
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-time.After(pullSlotPollInterval):
+		}
+	}
+}
+
+func (limiter *filePullLimiter) tryAcquire() (func(), error) {
+	for i := 0; i < limiter.slots; i++ {
+		f, err := limiter.openSlot(i)
+		if err != nil {
+			return nil, err
+		}
+		if err := syscall.Flock(int(f.Fd()), syscall.LOCK_EX|syscall.LOCK_NB); err != nil {
+			f.Close()
+			continue
+		}
+		return func() {
+			syscall.Flock(int(f.Fd()), syscall.LOCK_UN)
+			f.Close()
+		}, nil
+	}
+	return nil, nil
+}
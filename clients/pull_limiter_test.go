@@ -0,0 +1,54 @@
+package clients
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestFilePullLimiter(t *testing.T) {
+	r := require.New(t)
+
+	dir := t.TempDir()
+	// separate limiters act like separate processes
+	limiter1, err := NewFilePullLimiter(dir, 2)
+	r.NoError(err)
+	limiter2, err := NewFilePullLimiter(dir, 2)
+	r.NoError(err)
+
+	release1, err := limiter1.Acquire(context.Background())
+	r.NoError(err)
+	release2, err := limiter2.Acquire(context.Background())
+	r.NoError(err)
+
+	// no slots left
+	ctx, cancel := context.WithTimeout(context.Background(), time.Millisecond*100)
+	defer cancel()
+	_, err = limiter1.Acquire(ctx)
+	r.ErrorIs(err, context.DeadlineExceeded)
+
+	// waits until a slot is released
+	go func() {
+		time.Sleep(time.Millisecond * 100)
+		release2()
+	}()
+	release3, err := limiter1.Acquire(context.Background())
+	r.NoError(err)
+	release3()
+	release1()
+}
+
+func TestFilePullLimiter_InvalidSlots(t *testing.T) {
+	_, err := NewFilePullLimiter(t.TempDir(), 0)
+	require.Error(t, err)
+}
+
+func TestAcquirePullSlot_NoLimiter(t *testing.T) {
+	r := require.New(t)
+
+	release, err := acquirePullSlot(context.Background())
+	r.NoError(err)
+	release()
+}
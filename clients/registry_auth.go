@@ -0,0 +1,226 @@
+package clients
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"os/exec"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/forta-network/forta-node/config"
+)
+
+const (
+	// refresh the tokens a bit earlier than the expiry
+	registryTokenRefreshMargin = time.Minute * 5
+
+	ecrUsername      = "AWS"
+	ecrTokenLifetime = time.Hour * 12
+
+	gcrUsername           = "oauth2accesstoken"
+	defaultGCPMetadataURL = "http://metadata.google.internal/computeMetadata/v1"
+
+	defaultImageRegistry = "docker.io"
+)
+
+// RegistryCredentials are used for pulling images from a container registry.
+type RegistryCredentials struct {
+	Username string
+	Password string
+}
+
+// RegistryAuthProvider provides the container registry credentials before each pull.
+type RegistryAuthProvider interface {
+	Credentials(ctx context.Context) (*RegistryCredentials, error)
+	// Registry returns the registry which the credentials are for. The credentials are used
+	// for all registries if it is empty.
+	Registry() string
+}
+
+// NewRegistryAuthProvider creates the auth provider from the registry config. It returns nil
+// if basic auth is used without credentials. The credentials are used only for the configured
+// container registry.
+func NewRegistryAuthProvider(cfg config.RegistryConfig) (RegistryAuthProvider, error) {
+	switch cfg.AuthType {
+	case "", config.RegistryAuthBasic:
+		if len(cfg.Username) == 0 && len(cfg.Password) == 0 {
+			return nil, nil
+		}
+		return &basicAuthProvider{
+			registry:    cfg.ContainerRegistry,
+			credentials: RegistryCredentials{Username: cfg.Username, Password: cfg.Password},
+		}, nil
+
+	case config.RegistryAuthECR:
+		if len(cfg.ECR.Region) == 0 {
+			return nil, errors.New("ecr region is required")
+		}
+		provider := newTokenAuthProvider(ecrUsername, (&ecrTokenSource{
+			cfg:        cfg.ECR,
+			runCommand: runCommand,
+		}).token)
+		provider.registry = cfg.ContainerRegistry
+		return provider, nil
+
+	case config.RegistryAuthGCR:
+		provider := newTokenAuthProvider(gcrUsername, (&gcrTokenSource{
+			cfg:         cfg.GCR,
+			metadataURL: defaultGCPMetadataURL,
+			httpClient:  &http.Client{Timeout: time.Second * 10},
+		}).token)
+		provider.registry = cfg.ContainerRegistry
+		return provider, nil
+
+	default:
+		return nil, fmt.Errorf("unknown registry auth type: %s", cfg.AuthType)
+	}
+}
+
+// authAppliesTo tells if the credentials from the provider should be sent for the image.
+func authAppliesTo(auth RegistryAuthProvider, imageRef string) bool {
+	if auth == nil {
+		return false
+	}
+	registry := auth.Registry()
+	return len(registry) == 0 || strings.EqualFold(registry, imageRegistry(imageRef))
+}
+
+// imageRegistry returns the registry host of the image ref like docker resolves it: the first
+// part of the name is the registry only if it looks like a host.
+func imageRegistry(imageRef string) string {
+	i := strings.Index(imageRef, "/")
+	if i < 0 {
+		return defaultImageRegistry
+	}
+	host := imageRef[:i]
+	if !strings.ContainsAny(host, ".:") && host != "localhost" {
+		return defaultImageRegistry
+	}
+	return host
+}
+
+type basicAuthProvider struct {
+	registry    string
+	credentials RegistryCredentials
+}
+
+// NewBasicAuthProvider creates a provider which always returns the same credentials for
+// all registries.
+func NewBasicAuthProvider(username, password string) RegistryAuthProvider {
+	return &basicAuthProvider{credentials: RegistryCredentials{Username: username, Password: password}}
+}
+
+// Credentials implements the RegistryAuthProvider interface.
+func (provider *basicAuthProvider) Credentials(ctx context.Context) (*RegistryCredentials, error) {
+	return &provider.credentials, nil
+}
+
+// Registry implements the RegistryAuthProvider interface.
+func (provider *basicAuthProvider) Registry() string {
+	return provider.registry
+}
+
+// tokenSource gets a new token and returns it with the expiry time.
+type tokenSource func(ctx context.Context) (string, time.Time, error)
+
+// tokenAuthProvider uses the expiring tokens as the password and refreshes them when needed.
+type tokenAuthProvider struct {
+	registry    string
+	username    string
+	getToken    tokenSource
+	token       string
+	tokenExpiry time.Time
+	mu          sync.Mutex
+	now         func() time.Time
+}
+
+func newTokenAuthProvider(username string, getToken tokenSource) *tokenAuthProvider {
+	return &tokenAuthProvider{
+		username: username,
+		getToken: getToken,
+		now:      time.Now,
+	}
+}
+
+// Registry implements the RegistryAuthProvider interface.
+func (provider *tokenAuthProvider) Registry() string {
+	return provider.registry
+}
+
+// Credentials implements the RegistryAuthProvider interface.
+func (provider *tokenAuthProvider) Credentials(ctx context.Context) (*RegistryCredentials, error) {
+	provider.mu.Lock()
+	defer provider.mu.Unlock()
+
+	if len(provider.token) == 0 || provider.now().Add(registryTokenRefreshMargin).After(provider.tokenExpiry) {
+		token, expiry, err := provider.getToken(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get registry token: %v", err)
+		}
+		provider.token = token
+		provider.tokenExpiry = expiry
+	}
+	return &RegistryCredentials{Username: provider.username, Password: provider.token}, nil
+}
+
+type ecrTokenSource struct {
+	cfg        config.ECRAuthConfig
+	runCommand func(ctx context.Context, name string, args ...string) ([]byte, error)
+}
+
+func runCommand(ctx context.Context, name string, args ...string) ([]byte, error) {
+	return exec.CommandContext(ctx, name, args...).Output()
+}
+
+func (source *ecrTokenSource) token(ctx context.Context) (string, time.Time, error) {
+	args := []string{"ecr", "get-login-password", "--region", source.cfg.Region}
+	if len(source.cfg.Profile) > 0 {
+		args = append(args, "--profile", source.cfg.Profile)
+	}
+	out, err := source.runCommand(ctx, "aws", args...)
+	if err != nil {
+		return "", time.Time{}, fmt.Errorf("aws cli failed: %v", err)
+	}
+	return strings.TrimSpace(string(out)), time.Now().Add(ecrTokenLifetime), nil
+}
+
+type gcrTokenSource struct {
+	cfg         config.GCRAuthConfig
+	metadataURL string
+	httpClient  *http.Client
+}
+
+func (source *gcrTokenSource) token(ctx context.Context) (string, time.Time, error) {
+	serviceAccount := source.cfg.ServiceAccount
+	if len(serviceAccount) == 0 {
+		serviceAccount = "default"
+	}
+	req, err := http.NewRequestWithContext(
+		ctx, http.MethodGet,
+		fmt.Sprintf("%s/instance/service-accounts/%s/token", source.metadataURL, serviceAccount), nil,
+	)
+	if err != nil {
+		return "", time.Time{}, err
+	}
+	req.Header.Set("Metadata-Flavor", "Google")
+	resp, err := source.httpClient.Do(req)
+	if err != nil {
+		return "", time.Time{}, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return "", time.Time{}, fmt.Errorf("unexpected metadata server status code %d", resp.StatusCode)
+	}
+	var tokenResp struct {
+		AccessToken string `json:"access_token"`
+		ExpiresIn   int    `json:"expires_in"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&tokenResp); err != nil {
+		return "", time.Time{}, fmt.Errorf("failed to decode the token response: %v", err)
+	}
+	return tokenResp.AccessToken, time.Now().Add(time.Duration(tokenResp.ExpiresIn) * time.Second), nil
+}
@@ -0,0 +1,140 @@
+package clients
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/forta-network/forta-node/config"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewRegistryAuthProvider(t *testing.T) {
+	r := require.New(t)
+
+	provider, err := NewRegistryAuthProvider(config.RegistryConfig{})
+	r.NoError(err)
+	r.Nil(provider)
+
+	provider, err = NewRegistryAuthProvider(config.RegistryConfig{Username: "user1", Password: "pass1"})
+	r.NoError(err)
+	creds, err := provider.Credentials(context.Background())
+	r.NoError(err)
+	r.Equal(&RegistryCredentials{Username: "user1", Password: "pass1"}, creds)
+
+	_, err = NewRegistryAuthProvider(config.RegistryConfig{AuthType: config.RegistryAuthECR})
+	r.Error(err)
+}
+
+func TestAuthAppliesTo(t *testing.T) {
+	r := require.New(t)
+
+	provider, err := NewRegistryAuthProvider(config.RegistryConfig{
+		ContainerRegistry: "registry.example.com:5000",
+		Username:          "user1",
+		Password:          "pass1",
+	})
+	r.NoError(err)
+	r.True(authAppliesTo(provider, "registry.example.com:5000/bafybeisupervisor@sha256:"+testImageDigest))
+	r.False(authAppliesTo(provider, testImageRef))
+	r.False(authAppliesTo(provider, "nats:2.9"))
+	r.False(authAppliesTo(nil, testImageRef))
+
+	provider, err = NewRegistryAuthProvider(config.RegistryConfig{
+		ContainerRegistry: "123.dkr.ecr.us-east-1.amazonaws.com",
+		AuthType:          config.RegistryAuthECR,
+		ECR:               config.ECRAuthConfig{Region: "us-east-1"},
+	})
+	r.NoError(err)
+	r.True(authAppliesTo(provider, "123.dkr.ecr.us-east-1.amazonaws.com/forta/supervisor:latest"))
+	r.False(authAppliesTo(provider, testImageRef))
+
+	// not scoped to a registry
+	r.True(authAppliesTo(NewBasicAuthProvider("user1", "pass1"), testImageRef))
+}
+
+func TestImageRegistry(t *testing.T) {
+	r := require.New(t)
+
+	r.Equal("disco.forta.network", imageRegistry(testImageRef))
+	r.Equal("localhost:1970", imageRegistry("localhost:1970/image:latest"))
+	r.Equal("localhost", imageRegistry("localhost/image"))
+	r.Equal("docker.io", imageRegistry("nats:2.9"))
+	r.Equal("docker.io", imageRegistry("library/nats:2.9"))
+}
+
+func TestTokenAuthProvider_Refresh(t *testing.T) {
+	r := require.New(t)
+
+	now := time.Now()
+	var calls int
+	provider := newTokenAuthProvider("user1", func(ctx context.Context) (string, time.Time, error) {
+		calls++
+		if calls == 3 {
+			return "", time.Time{}, errors.New("failed")
+		}
+		return "token", now.Add(time.Hour), nil
+	})
+	provider.now = func() time.Time { return now }
+
+	creds, err := provider.Credentials(context.Background())
+	r.NoError(err)
+	r.Equal(&RegistryCredentials{Username: "user1", Password: "token"}, creds)
+	r.Equal(1, calls)
+
+	// cached
+	_, err = provider.Credentials(context.Background())
+	r.NoError(err)
+	r.Equal(1, calls)
+
+	// refreshed before the expiry
+	now = now.Add(time.Hour - registryTokenRefreshMargin + time.Second)
+	_, err = provider.Credentials(context.Background())
+	r.NoError(err)
+	r.Equal(2, calls)
+
+	now = now.Add(time.Hour)
+	_, err = provider.Credentials(context.Background())
+	r.Error(err)
+}
+
+func TestECRTokenSource(t *testing.T) {
+	r := require.New(t)
+
+	source := &ecrTokenSource{
+		cfg: config.ECRAuthConfig{Region: "us-east-1", Profile: "profile1"},
+		runCommand: func(ctx context.Context, name string, args ...string) ([]byte, error) {
+			r.Equal("aws", name)
+			r.Equal([]string{"ecr", "get-login-password", "--region", "us-east-1", "--profile", "profile1"}, args)
+			return []byte("token1\n"), nil
+		},
+	}
+	token, expiry, err := source.token(context.Background())
+	r.NoError(err)
+	r.Equal("token1", token)
+	r.True(expiry.After(time.Now().Add(time.Hour)))
+}
+
+func TestGCRTokenSource(t *testing.T) {
+	r := require.New(t)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		r.Equal("Google", req.Header.Get("Metadata-Flavor"))
+		r.Equal("/instance/service-accounts/account1/token", req.URL.Path)
+		w.Write([]byte(`{"access_token":"token1","expires_in":3600,"token_type":"Bearer"}`))
+	}))
+	defer server.Close()
+
+	source := &gcrTokenSource{
+		cfg:         config.GCRAuthConfig{ServiceAccount: "account1"},
+		metadataURL: server.URL,
+		httpClient:  http.DefaultClient,
+	}
+	token, expiry, err := source.token(context.Background())
+	r.NoError(err)
+	r.Equal("token1", token)
+	r.True(expiry.After(time.Now().Add(time.Minute * 59)))
+}
@@ -0,0 +1,68 @@
+package clients
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"strings"
+)
+
+// ErrRegistryAuth is returned when the container registry rejects the credentials.
+var ErrRegistryAuth = errors.New("container registry rejected the credentials")
+
+var registryPingClient = &http.Client{}
+
+// PingRegistry resolves the container registry host and checks the /v2/ endpoint of the registry
+// API. The registries which use token auth respond with 401 before a token is obtained, so that
+// is accepted unless the registry asks for the basic auth credentials that were already sent.
+func PingRegistry(ctx context.Context, registry string, auth RegistryAuthProvider) error {
+	host := registry
+	if h, _, err := net.SplitHostPort(registry); err == nil {
+		host = h
+	}
+	if _, err := net.DefaultResolver.LookupHost(ctx, host); err != nil {
+		return fmt.Errorf("failed to resolve %s: %v", host, err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, registryBaseURL(registry, host)+"/v2/", nil)
+	if err != nil {
+		return err
+	}
+	var sentCredentials bool
+	if auth != nil {
+		creds, err := auth.Credentials(ctx)
+		if err != nil {
+			return err
+		}
+		req.SetBasicAuth(creds.Username, creds.Password)
+		sentCredentials = true
+	}
+	resp, err := registryPingClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to reach the registry API: %v", err)
+	}
+	resp.Body.Close()
+
+	switch {
+	case resp.StatusCode == http.StatusOK:
+		return nil
+	case resp.StatusCode == http.StatusUnauthorized:
+		challenge := strings.ToLower(resp.Header.Get("WWW-Authenticate"))
+		if sentCredentials && strings.HasPrefix(challenge, "basic") {
+			return ErrRegistryAuth
+		}
+		return nil
+	default:
+		return fmt.Errorf("registry API responded with code %d - is %s a container registry?", resp.StatusCode, registry)
+	}
+}
+
+// registryBaseURL uses plain HTTP only for the local registries like docker does.
+func registryBaseURL(registry, host string) string {
+	if ip := net.ParseIP(host); host == "localhost" || (ip != nil && ip.IsLoopback()) {
+		return "http://" + registry
+	}
+	return "https://" + registry
+}
@@ -0,0 +1,55 @@
+package clients
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestPingRegistry(t *testing.T) {
+	r := require.New(t)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		r.Equal("/v2/", req.URL.Path)
+		username, password, ok := req.BasicAuth()
+		switch {
+		case !ok:
+			w.Header().Set("WWW-Authenticate", `Bearer realm="https://auth.example.com/token"`)
+			w.WriteHeader(http.StatusUnauthorized)
+		case username == "user1" && password == "pass1":
+			w.WriteHeader(http.StatusOK)
+		default:
+			w.Header().Set("WWW-Authenticate", `Basic realm="registry"`)
+			w.WriteHeader(http.StatusUnauthorized)
+		}
+	}))
+	defer server.Close()
+	serverURL, err := url.Parse(server.URL)
+	r.NoError(err)
+	registry := serverURL.Host
+
+	ctx := context.Background()
+	r.NoError(PingRegistry(ctx, registry, nil))
+	r.NoError(PingRegistry(ctx, registry, NewBasicAuthProvider("user1", "pass1")))
+	r.ErrorIs(PingRegistry(ctx, registry, NewBasicAuthProvider("user1", "bad")), ErrRegistryAuth)
+}
+
+func TestPingRegistry_NotRegistry(t *testing.T) {
+	r := require.New(t)
+
+	server := httptest.NewServer(http.NotFoundHandler())
+	defer server.Close()
+	serverURL, err := url.Parse(server.URL)
+	r.NoError(err)
+
+	r.ErrorContains(PingRegistry(context.Background(), serverURL.Host, nil), "code 404")
+}
+
+func TestPingRegistry_Unresolvable(t *testing.T) {
+	err := PingRegistry(context.Background(), "registry.invalid", nil)
+	require.ErrorContains(t, err, "failed to resolve registry.invalid")
+}
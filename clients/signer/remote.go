@@ -0,0 +1,138 @@
+package signer
+
+import (
+	"bytes"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/forta-network/forta-core-go/clients/health"
+	"github.com/forta-network/forta-node/config"
+	log "github.com/sirupsen/logrus"
+)
+
+// the max size of the signature response
+const maxResponseSize = 1024
+
+// RemoteSigner signs by using a Web3Signer compatible API. The requests which fail because of
+// network errors, timeouts or server errors are retried and the signer is reported unavailable
+// after the last attempt.
+type RemoteSigner struct {
+	cfg        config.RemoteSignerConfig
+	address    common.Address
+	httpClient *http.Client
+	sleep      func(time.Duration)
+
+	lastSign    health.TimeTracker
+	lastSignErr health.ErrorTracker
+}
+
+// NewRemoteSigner creates a new remote signer.
+func NewRemoteSigner(cfg config.RemoteSignerConfig) *RemoteSigner {
+	return &RemoteSigner{
+		cfg:        cfg,
+		address:    common.HexToAddress(cfg.Address),
+		httpClient: &http.Client{Timeout: cfg.Timeout},
+		sleep:      time.Sleep,
+	}
+}
+
+// Address returns the address of the scanner key in the remote signer.
+func (s *RemoteSigner) Address() common.Address {
+	return s.address
+}
+
+// Sign signs the data by using the remote signer.
+func (s *RemoteSigner) Sign(data []byte) (sig []byte, err error) {
+	attempts := s.cfg.MaxAttempts
+	if attempts < 1 {
+		attempts = 1
+	}
+	for attempt := 1; attempt <= attempts; attempt++ {
+		sig, err = s.sign(data)
+		if err == nil || !IsUnavailable(err) {
+			break
+		}
+		if attempt < attempts {
+			log.WithError(err).WithField("attempt", attempt).Warn("failed to sign with the remote signer - retrying")
+			s.sleep(s.cfg.RetryWait)
+		}
+	}
+	s.lastSignErr.Set(err)
+	if err != nil {
+		return nil, err
+	}
+	s.lastSign.Set()
+	return sig, nil
+}
+
+func (s *RemoteSigner) sign(data []byte) ([]byte, error) {
+	b, _ := json.Marshal(&struct {
+		Data string `json:"data"`
+	}{Data: hexutil.Encode(data)})
+	url := fmt.Sprintf("%s/api/v1/eth1/sign/%s", strings.TrimSuffix(s.cfg.URL, "/"), s.address.Hex())
+	req, err := http.NewRequest(http.MethodPost, url, bytes.NewReader(b))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if len(s.cfg.AuthToken) > 0 {
+		req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", s.cfg.AuthToken))
+	}
+	resp, err := s.httpClient.Do(req)
+	if err != nil {
+		// network errors and timeouts
+		return nil, &UnavailableError{Err: err}
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(io.LimitReader(resp.Body, maxResponseSize))
+	if err != nil {
+		return nil, &UnavailableError{Err: err}
+	}
+
+	switch {
+	case resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= 500:
+		return nil, &UnavailableError{Err: fmt.Errorf("remote signer responded with status %d", resp.StatusCode)}
+	case resp.StatusCode != http.StatusOK:
+		// not worth retrying: the auth token or the key is wrong
+		return nil, fmt.Errorf("remote signer responded with status %d: %s", resp.StatusCode, string(body))
+	}
+	return s.parseSignature(data, body)
+}
+
+// parseSignature decodes the hex signature and makes sure that it was signed by the scanner key.
+func (s *RemoteSigner) parseSignature(data, body []byte) ([]byte, error) {
+	sigHex := strings.Trim(strings.TrimSpace(string(body)), `"`)
+	sig, err := hex.DecodeString(strings.TrimPrefix(sigHex, "0x"))
+	if err != nil || len(sig) != crypto.SignatureLength {
+		return nil, errors.New("remote signer responded with an invalid signature")
+	}
+	// the recovery id is returned as 27 or 28
+	if sig[crypto.RecoveryIDOffset] >= 27 {
+		sig[crypto.RecoveryIDOffset] -= 27
+	}
+	pubKey, err := crypto.SigToPub(crypto.Keccak256(data), sig)
+	if err != nil {
+		return nil, fmt.Errorf("failed to recover the remote signer address: %v", err)
+	}
+	if signer := crypto.PubkeyToAddress(*pubKey); signer != s.address {
+		return nil, fmt.Errorf("remote signer signed with %s instead of %s", signer.Hex(), s.address.Hex())
+	}
+	return sig, nil
+}
+
+// Health implements the health.Reporter interface.
+func (s *RemoteSigner) Health() health.Reports {
+	return health.Reports{
+		s.lastSign.GetReport("signer.remote.sign.time"),
+		s.lastSignErr.GetReport("signer.remote.sign.error"),
+	}
+}
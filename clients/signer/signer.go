@@ -0,0 +1,183 @@
+package signer
+
+import (
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/ethereum/go-ethereum/accounts/keystore"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/forta-network/forta-core-go/encoding"
+	"github.com/forta-network/forta-core-go/protocol"
+	"github.com/forta-network/forta-core-go/security"
+	"github.com/forta-network/forta-node/config"
+	"github.com/forta-network/forta-node/store"
+	"github.com/golang-jwt/jwt/v4"
+	"github.com/golang/protobuf/proto"
+	"github.com/google/uuid"
+)
+
+// jwtAlg is the signing method which the scanner JWTs are verified with.
+const jwtAlg = "ETH"
+
+// ErrReadOnly is returned when a read-only signer is asked to sign.
+var ErrReadOnly = errors.New("the signer is read-only and can not sign")
+
+// Signer signs with the scanner key.
+type Signer interface {
+	// Address returns the address of the scanner key.
+	Address() common.Address
+	// Sign signs the Keccak256 hash of the data and returns the 65-byte signature in the
+	// [R || S || V] form where V is 0 or 1.
+	Sign(data []byte) ([]byte, error)
+}
+
+// UnavailableError is returned when the signer can not be reached for now and signing can be
+// retried later.
+type UnavailableError struct {
+	Err error
+}
+
+func (e *UnavailableError) Error() string {
+	return fmt.Sprintf("signer is unavailable: %v", e.Err)
+}
+
+func (e *UnavailableError) Unwrap() error {
+	return e.Err
+}
+
+// IsUnavailable tells if the signer was temporarily unavailable.
+func IsUnavailable(err error) bool {
+	var unavailableErr *UnavailableError
+	return errors.As(err, &unavailableErr)
+}
+
+// New creates the configured signer. The scanner key in the key dir is decrypted only for the
+// local signer and the read-only signer uses the address of the key.
+func New(cfg config.SignerConfig, keyDir string, passphrase func() (string, error)) (Signer, error) {
+	switch cfg.SignerType() {
+	case config.SignerTypeRemote:
+		return NewRemoteSigner(cfg.Remote), nil
+
+	case config.SignerTypeReadOnly:
+		address, err := store.NewScannerKeyStore(keyDir).Address()
+		if err != nil {
+			return nil, fmt.Errorf("failed to read the scanner key address: %v", err)
+		}
+		return NewReadOnlySigner(address), nil
+
+	default:
+		pass, err := passphrase()
+		if err != nil {
+			return nil, fmt.Errorf("failed to read the passphrase: %v", err)
+		}
+		key, err := security.LoadKeyWithPassphrase(keyDir, pass)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load the scanner key: %v", err)
+		}
+		return NewLocalSigner(key), nil
+	}
+}
+
+// SignBytes signs the bytes and returns the signature in the protocol form.
+func SignBytes(signer Signer, b []byte) (*protocol.Signature, error) {
+	sig, err := signer.Sign(b)
+	if err != nil {
+		return nil, err
+	}
+	return &protocol.Signature{
+		Signature: fmt.Sprintf("0x%s", hex.EncodeToString(sig)),
+		Algorithm: "ECDSA",
+		Signer:    signer.Address().Hex(),
+	}, nil
+}
+
+// SignBatch signs the alert batch.
+func SignBatch(signer Signer, batch *protocol.AlertBatch) (*protocol.SignedPayload, error) {
+	return signPayload(signer, protocol.SignedPayload_BATCH, batch)
+}
+
+// SignBatchSummary signs the alert batch summary.
+func SignBatchSummary(signer Signer, summary *protocol.BatchSummary) (*protocol.SignedPayload, error) {
+	return signPayload(signer, protocol.SignedPayload_BATCH_SUMMARY, summary)
+}
+
+func signPayload(signer Signer, payloadType protocol.SignedPayload_PayloadType, msg proto.Message) (*protocol.SignedPayload, error) {
+	encoded, err := encoding.EncodeGzippedProto(msg)
+	if err != nil {
+		return nil, err
+	}
+	signature, err := SignBytes(signer, []byte(encoded))
+	if err != nil {
+		return nil, err
+	}
+	return &protocol.SignedPayload{
+		Type:      payloadType,
+		Encoded:   encoded,
+		Signature: signature,
+	}, nil
+}
+
+// CreateScannerJWT creates a short-lived scanner JWT with the given claims. The token is the
+// same as the one from the security package, which needs the private key.
+func CreateScannerJWT(signer Signer, claims map[string]interface{}) (string, error) {
+	now := time.Now().UTC()
+	mapClaims := jwt.MapClaims{
+		"jti": uuid.Must(uuid.NewUUID()).String(),
+		"sub": signer.Address().Hex(),
+		"iat": now.Unix(),
+		"nbf": now.Add(-30 * time.Second).Unix(),
+		"exp": now.Add(30 * time.Second).Unix(),
+	}
+	for k, v := range claims {
+		mapClaims[k] = v
+	}
+	signingString, err := jwt.NewWithClaims(jwt.GetSigningMethod(jwtAlg), mapClaims).SigningString()
+	if err != nil {
+		return "", err
+	}
+	sig, err := signer.Sign([]byte(signingString))
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("%s.%s", signingString, base64.RawURLEncoding.EncodeToString(sig)), nil
+}
+
+// localSigner signs with the decrypted scanner key.
+type localSigner struct {
+	key *keystore.Key
+}
+
+// NewLocalSigner creates a new signer with the decrypted scanner key.
+func NewLocalSigner(key *keystore.Key) Signer {
+	return &localSigner{key: key}
+}
+
+func (s *localSigner) Address() common.Address {
+	return s.key.Address
+}
+
+func (s *localSigner) Sign(data []byte) ([]byte, error) {
+	return crypto.Sign(crypto.Keccak256(data), s.key.PrivateKey)
+}
+
+// readOnlySigner knows the scanner address and fails on signing.
+type readOnlySigner struct {
+	address common.Address
+}
+
+// NewReadOnlySigner creates a new signer which fails on signing.
+func NewReadOnlySigner(address common.Address) Signer {
+	return &readOnlySigner{address: address}
+}
+
+func (s *readOnlySigner) Address() common.Address {
+	return s.address
+}
+
+func (s *readOnlySigner) Sign(data []byte) ([]byte, error) {
+	return nil, ErrReadOnly
+}
@@ -0,0 +1,198 @@
+package signer
+
+import (
+	"crypto/ecdsa"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"path"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/accounts/keystore"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/forta-network/forta-core-go/clients/health"
+	"github.com/forta-network/forta-core-go/protocol"
+	"github.com/forta-network/forta-core-go/security"
+	"github.com/forta-network/forta-node/config"
+	"github.com/stretchr/testify/require"
+)
+
+const testAuthToken = "token1"
+
+// testRemoteSigner responds with the given status codes in order and signs after the script ends.
+func testRemoteSigner(t *testing.T, privateKey *ecdsa.PrivateKey, statusCodes ...int) (*httptest.Server, *int32) {
+	var calls int32
+	address := crypto.PubkeyToAddress(privateKey.PublicKey).Hex()
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		call := int(atomic.AddInt32(&calls, 1))
+		if call <= len(statusCodes) {
+			w.WriteHeader(statusCodes[call-1])
+			return
+		}
+		if req.Header.Get("Authorization") != "Bearer "+testAuthToken {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		if req.URL.Path != "/api/v1/eth1/sign/"+address {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		var body struct {
+			Data string `json:"data"`
+		}
+		require.NoError(t, json.NewDecoder(req.Body).Decode(&body))
+		sig, err := crypto.Sign(crypto.Keccak256(hexutil.MustDecode(body.Data)), privateKey)
+		require.NoError(t, err)
+		sig[crypto.RecoveryIDOffset] += 27
+		w.Write([]byte(hexutil.Encode(sig)))
+	})), &calls
+}
+
+func testRemoteConfig(server *httptest.Server, privateKey *ecdsa.PrivateKey) config.RemoteSignerConfig {
+	return config.RemoteSignerConfig{
+		URL:         server.URL,
+		AuthToken:   testAuthToken,
+		Address:     crypto.PubkeyToAddress(privateKey.PublicKey).Hex(),
+		Timeout:     time.Second,
+		MaxAttempts: 3,
+		RetryWait:   time.Millisecond,
+	}
+}
+
+func generateKey(t *testing.T) *ecdsa.PrivateKey {
+	privateKey, err := crypto.GenerateKey()
+	require.NoError(t, err)
+	return privateKey
+}
+
+func TestRemoteSigner(t *testing.T) {
+	r := require.New(t)
+
+	privateKey := generateKey(t)
+	server, calls := testRemoteSigner(t, privateKey, http.StatusServiceUnavailable)
+	defer server.Close()
+	signer := NewRemoteSigner(testRemoteConfig(server, privateKey))
+
+	// the server error is retried and the signatures are the same as the local ones
+	signedBatch, err := SignBatch(signer, &protocol.AlertBatch{BlockStart: 1, BlockEnd: 2})
+	r.NoError(err)
+	r.Equal(int32(2), atomic.LoadInt32(calls))
+	r.NoError(security.VerifySignedPayload(signedBatch))
+	localBatch, err := SignBatch(NewLocalSigner(&keystore.Key{
+		Address: signer.Address(), PrivateKey: privateKey,
+	}), &protocol.AlertBatch{BlockStart: 1, BlockEnd: 2})
+	r.NoError(err)
+	r.Equal(localBatch.Signature, signedBatch.Signature)
+
+	token, err := CreateScannerJWT(signer, map[string]interface{}{"batch": "ref1"})
+	r.NoError(err)
+	scannerToken, err := security.VerifyScannerJWT(token)
+	r.NoError(err)
+	r.Equal(signer.Address().Hex(), scannerToken.Scanner)
+
+	report, _ := signer.Health().NameContains("signer.remote.sign.error")
+	r.Equal(health.StatusOK, report.Status)
+}
+
+func TestRemoteSigner_Errors(t *testing.T) {
+	r := require.New(t)
+
+	privateKey := generateKey(t)
+	server, calls := testRemoteSigner(t, privateKey)
+	defer server.Close()
+
+	// wrong token is not retried and it is not a temporary error
+	cfg := testRemoteConfig(server, privateKey)
+	cfg.AuthToken = "wrong"
+	_, err := NewRemoteSigner(cfg).Sign([]byte("data"))
+	r.Error(err)
+	r.False(IsUnavailable(err))
+	r.Equal(int32(1), atomic.LoadInt32(calls))
+
+	// the signature of another key is rejected
+	otherKey := generateKey(t)
+	otherServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		sig, _ := crypto.Sign(crypto.Keccak256([]byte("data")), otherKey)
+		w.Write([]byte(hexutil.Encode(sig)))
+	}))
+	defer otherServer.Close()
+	cfg = testRemoteConfig(otherServer, privateKey)
+	_, err = NewRemoteSigner(cfg).Sign([]byte("data"))
+	r.Error(err)
+	r.False(IsUnavailable(err))
+}
+
+func TestRemoteSigner_Unavailable(t *testing.T) {
+	r := require.New(t)
+
+	privateKey := generateKey(t)
+	server, calls := testRemoteSigner(t, privateKey,
+		http.StatusBadGateway, http.StatusTooManyRequests, http.StatusInternalServerError,
+	)
+	defer server.Close()
+
+	// all attempts fail
+	signer := NewRemoteSigner(testRemoteConfig(server, privateKey))
+	_, err := signer.Sign([]byte("data"))
+	r.True(IsUnavailable(err))
+	r.Equal(int32(3), atomic.LoadInt32(calls))
+	report, _ := signer.Health().NameContains("signer.remote.sign.error")
+	r.Equal(health.StatusFailing, report.Status)
+
+	// works again after the server recovers
+	_, err = signer.Sign([]byte("data"))
+	r.NoError(err)
+
+	// the timeouts are retried as well
+	slowServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		time.Sleep(time.Millisecond * 200)
+	}))
+	defer slowServer.Close()
+	cfg := testRemoteConfig(slowServer, privateKey)
+	cfg.Timeout = time.Millisecond * 20
+	cfg.MaxAttempts = 2
+	_, err = NewRemoteSigner(cfg).Sign([]byte("data"))
+	r.True(IsUnavailable(err))
+
+	// the server is gone
+	server.Close()
+	_, err = signer.Sign([]byte("data"))
+	r.True(IsUnavailable(err))
+}
+
+func TestNew(t *testing.T) {
+	r := require.New(t)
+
+	keyDir := path.Join(t.TempDir(), ".keys")
+	ks := keystore.NewKeyStore(keyDir, keystore.LightScryptN, keystore.LightScryptP)
+	account, err := ks.NewAccount("passphrase1")
+	r.NoError(err)
+	passphrase := func() (string, error) {
+		return "passphrase1", nil
+	}
+
+	signer, err := New(config.SignerConfig{}, keyDir, passphrase)
+	r.NoError(err)
+	r.Equal(account.Address, signer.Address())
+	signature, err := SignBytes(signer, []byte("data"))
+	r.NoError(err)
+	r.NoError(security.VerifySignature([]byte("data"), account.Address.Hex(), signature.Signature))
+
+	// the read-only signer does not need the passphrase
+	signer, err = New(config.SignerConfig{Type: config.SignerTypeReadOnly}, keyDir, nil)
+	r.NoError(err)
+	r.Equal(account.Address, signer.Address())
+	_, err = SignBatch(signer, &protocol.AlertBatch{})
+	r.ErrorIs(err, ErrReadOnly)
+	_, err = CreateScannerJWT(signer, nil)
+	r.ErrorIs(err, ErrReadOnly)
+	r.False(IsUnavailable(err))
+
+	_, err = New(config.SignerConfig{}, keyDir, func() (string, error) {
+		return "wrong", nil
+	})
+	r.Error(err)
+}
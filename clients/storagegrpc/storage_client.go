@@ -34,6 +34,7 @@ func DialContext(ctx context.Context, serverURL string) (protocol.StorageClient,
 			ctx,
 			serverURL,
 			grpc.WithInsecure(),
+			grpc.WithNoProxy(), // storage is in the internal network
 			grpc.WithBlock(),
 			grpc.WithTimeout(10*time.Second),
 		)
@@ -2,6 +2,7 @@ package cmd
 
 import (
 	"bytes"
+	"context"
 	"errors"
 	"fmt"
 	"io/ioutil"
@@ -9,7 +10,6 @@ import (
 	"path"
 	"reflect"
 	"regexp"
-	"strings"
 
 	"github.com/creasty/defaults"
 	"github.com/sirupsen/logrus"
@@ -27,14 +27,21 @@ const (
 	keyFortaPassphrase  = "forta_passphrase"
 	keyFortaDevelopment = "forta_development"
 	keyFortaExposeNats  = "forta_expose_nats"
+	keyFortaConfigURL   = "forta_config_url"
+	keyFortaConfigToken = "forta_config_token"
+
+	keyFortaNewPassphrase      = "forta_new_passphrase"
+	keyFortaKeystorePassphrase = "forta_keystore_passphrase"
 )
 
 var (
 	cfg config.Config
 
 	parsedArgs struct {
-		Version uint64
-		NoCheck bool
+		Version            uint64
+		NoCheck            bool
+		DryRun             bool
+		StrictRegistration bool
 	}
 
 	cmdForta = &cobra.Command{
@@ -81,6 +88,38 @@ publishes alerts about them`,
 		Hidden: true,
 	}
 
+	cmdFortaKeys = &cobra.Command{
+		Use:   "keys",
+		Short: "scanner key management",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return cmd.Help()
+		},
+	}
+
+	cmdFortaKeysCreate = &cobra.Command{
+		Use:   "create",
+		Short: "create a new scanner key encrypted with the passphrase",
+		RunE:  handleFortaKeysCreate,
+	}
+
+	cmdFortaKeysImport = &cobra.Command{
+		Use:   "import",
+		Short: "import a scanner key from a private key or a keystore file",
+		RunE:  handleFortaKeysImport,
+	}
+
+	cmdFortaKeysExport = &cobra.Command{
+		Use:   "export",
+		Short: "export the encrypted scanner key file",
+		RunE:  handleFortaKeysExport,
+	}
+
+	cmdFortaKeysChangePassphrase = &cobra.Command{
+		Use:   "change-passphrase",
+		Short: "re-encrypt the scanner key with a new passphrase (backs up the old key file)",
+		RunE:  handleFortaKeysChangePassphrase,
+	}
+
 	cmdFortaImages = &cobra.Command{
 		Use:   "images",
 		Short: "list the Forta node container images",
@@ -90,7 +129,10 @@ publishes alerts about them`,
 	cmdFortaVersion = &cobra.Command{
 		Use:   "version",
 		Short: "show release info",
-		RunE:  handleFortaVersion,
+		Long: `Show the build of the runner, the images embedded in it, the running images and the latest
+release which the updater has seen. The info comes from the running node and only the build
+info is shown if the node is not running.`,
+		RunE: handleFortaVersion,
 	}
 
 	cmdFortaBatch = &cobra.Command{
@@ -107,12 +149,142 @@ publishes alerts about them`,
 		RunE:  handleFortaBatchDecode,
 	}
 
+	cmdFortaConfig = &cobra.Command{
+		Use:   "config",
+		Short: "config utils",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return cmd.Help()
+		},
+	}
+
+	cmdFortaConfigDump = &cobra.Command{
+		Use:   "dump",
+		Short: "print the effective config as JSON (secrets are redacted)",
+		RunE:  handleFortaConfigDump,
+	}
+
 	cmdFortaStatus = &cobra.Command{
 		Use:   "status",
 		Short: "display statuses of node services",
+		Long:  "display statuses of node services - exits with a non-zero code if the node is not running or any of the components is not healthy",
 		RunE:  handleFortaStatus,
 	}
 
+	cmdFortaDrain = &cobra.Command{
+		Use:   "drain",
+		Short: "stop scanning new blocks and publish the pending alerts without stopping the node",
+		Long:  "stop scanning new blocks and publish the pending alerts without stopping the node - the scanning is resumed after the node is restarted",
+		RunE:  handleFortaDrain,
+	}
+
+	cmdFortaAdmin = &cobra.Command{
+		Use:   "admin",
+		Short: "operational commands for the running node",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return cmd.Help()
+		},
+	}
+
+	cmdFortaAdminRestartSupervisor = &cobra.Command{
+		Use:   "restart-supervisor",
+		Short: "restart the supervisor container",
+		RunE:  handleFortaAdminRestartSupervisor,
+	}
+
+	cmdFortaAdminRestartUpdater = &cobra.Command{
+		Use:   "restart-updater",
+		Short: "restart the updater container",
+		RunE:  handleFortaAdminRestartUpdater,
+	}
+
+	cmdFortaAdminPauseUpdates = &cobra.Command{
+		Use:   "pause-updates",
+		Short: "stop applying the new releases until resumed or restarted",
+		RunE:  handleFortaAdminPauseUpdates,
+	}
+
+	cmdFortaAdminResumeUpdates = &cobra.Command{
+		Use:   "resume-updates",
+		Short: "resume applying the new releases",
+		RunE:  handleFortaAdminResumeUpdates,
+	}
+
+	cmdFortaAdminCheckUpdates = &cobra.Command{
+		Use:   "check-updates",
+		Short: "check the latest release from the updater now",
+		RunE:  handleFortaAdminCheckUpdates,
+	}
+
+	cmdFortaAdminRetryAgents = &cobra.Command{
+		Use:   "retry-agents [agent id]",
+		Short: "restart the quarantined or failed agents which kept crashing (all if no agent id is given)",
+		Args:  cobra.MaximumNArgs(1),
+		RunE:  handleFortaAdminRetryAgents,
+	}
+
+	cmdFortaAdminState = &cobra.Command{
+		Use:   "state",
+		Short: "show the current images and the restart counts",
+		RunE:  handleFortaAdminState,
+	}
+
+	cmdFortaAgents = &cobra.Command{
+		Use:   "agents",
+		Short: "manage the agents of the running node",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return cmd.Help()
+		},
+	}
+
+	cmdFortaAgentsDisable = &cobra.Command{
+		Use:   "disable <agent id>",
+		Short: "stop the agent and keep it stopped until it is enabled (survives the restarts and the updates)",
+		Args:  cobra.ExactArgs(1),
+		RunE:  handleFortaAgentsDisable,
+	}
+
+	cmdFortaAgentsEnable = &cobra.Command{
+		Use:   "enable <agent id>",
+		Short: "run the disabled agent again",
+		Args:  cobra.ExactArgs(1),
+		RunE:  handleFortaAgentsEnable,
+	}
+
+	cmdFortaAgentsRun = &cobra.Command{
+		Use:   "run <agent id>",
+		Short: "run the agent once over a past block range next to the running agents (the findings are logged, not sent)",
+		Args:  cobra.ExactArgs(1),
+		RunE:  handleFortaAgentsRun,
+	}
+
+	cmdFortaAgentsReset = &cobra.Command{
+		Use:   "reset",
+		Short: "remove all agent containers and start the assigned agents again (the node containers keep running)",
+		Args:  cobra.NoArgs,
+		RunE:  handleFortaAgentsReset,
+	}
+
+	cmdFortaLogs = &cobra.Command{
+		Use:   "logs [component|agent id]",
+		Short: "show the logs of a node container or an agent",
+		Long:  "show the logs of a node container (supervisor, updater, scanner, json-rpc, nats, inspector, jwt-provider, storage, egress-proxy, ipfs) or an agent by the agent ID prefix",
+		Args:  cobra.MaximumNArgs(1),
+		RunE:  handleFortaLogs,
+	}
+
+	cmdFortaLogsSupervisor = &cobra.Command{
+		Use:   "supervisor",
+		Short: "follow the supervisor logs",
+		RunE:  handleFortaLogsSupervisor,
+	}
+
+	cmdFortaLogsAgent = &cobra.Command{
+		Use:   "agent <agent id>",
+		Short: "follow the logs of the agent",
+		Args:  cobra.ExactArgs(1),
+		RunE:  handleFortaLogsAgent,
+	}
+
 	cmdFortaRegister = &cobra.Command{
 		Use:   "register",
 		Short: "register your scan node to enable it for scanning (requires MATIC in your scan node address)",
@@ -147,6 +319,12 @@ func init() {
 	cmdFortaAccount.AddCommand(cmdFortaAccountAddress)
 	cmdFortaAccount.AddCommand(cmdFortaAccountImport)
 
+	cmdForta.AddCommand(cmdFortaKeys)
+	cmdFortaKeys.AddCommand(cmdFortaKeysCreate)
+	cmdFortaKeys.AddCommand(cmdFortaKeysImport)
+	cmdFortaKeys.AddCommand(cmdFortaKeysExport)
+	cmdFortaKeys.AddCommand(cmdFortaKeysChangePassphrase)
+
 	cmdForta.AddCommand(cmdFortaImages)
 
 	cmdForta.AddCommand(cmdFortaVersion)
@@ -154,8 +332,31 @@ func init() {
 	cmdForta.AddCommand(cmdFortaBatch)
 	cmdFortaBatch.AddCommand(cmdFortaBatchDecode)
 
+	cmdForta.AddCommand(cmdFortaConfig)
+	cmdFortaConfig.AddCommand(cmdFortaConfigDump)
+
 	cmdForta.AddCommand(cmdFortaStatus)
 
+	cmdForta.AddCommand(cmdFortaDrain)
+	cmdForta.AddCommand(cmdFortaAdmin)
+	cmdFortaAdmin.AddCommand(cmdFortaAdminRestartSupervisor)
+	cmdFortaAdmin.AddCommand(cmdFortaAdminRestartUpdater)
+	cmdFortaAdmin.AddCommand(cmdFortaAdminPauseUpdates)
+	cmdFortaAdmin.AddCommand(cmdFortaAdminResumeUpdates)
+	cmdFortaAdmin.AddCommand(cmdFortaAdminCheckUpdates)
+	cmdFortaAdmin.AddCommand(cmdFortaAdminRetryAgents)
+	cmdFortaAdmin.AddCommand(cmdFortaAdminState)
+
+	cmdForta.AddCommand(cmdFortaAgents)
+	cmdFortaAgents.AddCommand(cmdFortaAgentsDisable)
+	cmdFortaAgents.AddCommand(cmdFortaAgentsEnable)
+	cmdFortaAgents.AddCommand(cmdFortaAgentsRun)
+	cmdFortaAgents.AddCommand(cmdFortaAgentsReset)
+
+	cmdForta.AddCommand(cmdFortaLogs)
+	cmdFortaLogs.AddCommand(cmdFortaLogsSupervisor)
+	cmdFortaLogs.AddCommand(cmdFortaLogsAgent)
+
 	cmdForta.AddCommand(cmdFortaRegister)
 	cmdForta.AddCommand(cmdFortaEnable)
 	cmdForta.AddCommand(cmdFortaDisable)
@@ -174,12 +375,30 @@ func init() {
 	cmdForta.PersistentFlags().Bool("expose-nats", false, "expose nats via public docker network")
 	viper.BindPFlag(keyFortaExposeNats, cmdForta.PersistentFlags().Lookup("expose-nats"))
 
+	cmdForta.PersistentFlags().String("config-url", "", "http(s) url to fetch the config from instead of the config file (overrides $FORTA_CONFIG_URL)")
+	viper.BindPFlag(keyFortaConfigURL, cmdForta.PersistentFlags().Lookup("config-url"))
+
 	// forta account import
 	cmdFortaAccountImport.Flags().String("file", "", "path to a file that contains a private key hex")
 	cmdFortaAccountImport.MarkFlagRequired("file")
 
+	// forta keys
+	cmdFortaKeysCreate.Flags().Bool("force", false, "replace the existing key")
+	cmdFortaKeysImport.Flags().String("private-key", "", "path to a file that contains a private key hex")
+	cmdFortaKeysImport.Flags().String("keystore", "", "path to a keystore file")
+	cmdFortaKeysImport.Flags().String("keystore-passphrase", "", "passphrase to decrypt the keystore file if different (overrides $FORTA_KEYSTORE_PASSPHRASE)")
+	viper.BindPFlag(keyFortaKeystorePassphrase, cmdFortaKeysImport.Flags().Lookup("keystore-passphrase"))
+	cmdFortaKeysImport.Flags().Bool("force", false, "replace the existing key")
+	cmdFortaKeysExport.Flags().String("to", "", "path to write the encrypted key file to")
+	cmdFortaKeysExport.MarkFlagRequired("to")
+	cmdFortaKeysExport.Flags().Bool("force", false, "overwrite the file at the path")
+	cmdFortaKeysChangePassphrase.Flags().String("new-passphrase", "", "passphrase to encrypt the key with (overrides $FORTA_NEW_PASSPHRASE)")
+	viper.BindPFlag(keyFortaNewPassphrase, cmdFortaKeysChangePassphrase.Flags().Lookup("new-passphrase"))
+
 	// forta run
 	cmdFortaRun.Flags().BoolVar(&parsedArgs.NoCheck, "no-check", false, "disable scanner registry check and just run")
+	cmdFortaRun.Flags().BoolVar(&parsedArgs.DryRun, "dry-run", false, "check if the node is ready to run without starting it")
+	cmdFortaRun.Flags().BoolVar(&parsedArgs.StrictRegistration, "strict-registration", false, "fail the start-up if the scanner is not registered or is staked below the minimum")
 
 	// forta batch decode
 	cmdFortaBatchDecode.Flags().String("cid", "", "batch IPFS CID (content ID)")
@@ -187,11 +406,32 @@ func init() {
 	cmdFortaBatchDecode.Flags().String("o", "alert-batch.json", "output file name (default: alert-batch.json)")
 	cmdFortaBatchDecode.Flags().Bool("stdout", false, "print to stdout instead of writing to a file")
 
+	// forta version
+	cmdFortaVersion.Flags().Bool("json", false, "print the version info as json")
+
 	// forta status
-	cmdFortaStatus.Flags().String("format", StatusFormatPretty, "output formatting/encoding: pretty (default), oneline, json, csv")
+	cmdFortaStatus.Flags().String("format", StatusFormatTable, "output formatting/encoding: table (default), pretty, oneline, json, csv")
+	cmdFortaStatus.Flags().Bool("json", false, "print all of the reports as json for scripting")
 	cmdFortaStatus.Flags().Bool("no-color", false, "disable colors")
 	cmdFortaStatus.Flags().String("show", StatusShowSummary, "filter statuses to show: summary (default), important, all")
 
+	// forta agents disable
+	cmdFortaAgentsDisable.Flags().String("reason", "", "why the agent is disabled (shown in the health reports)")
+
+	// forta agents run
+	cmdFortaAgentsRun.Flags().String("image", "", "agent image reference")
+	cmdFortaAgentsRun.Flags().Uint64("start-block", 0, "first block to run the agent on")
+	cmdFortaAgentsRun.Flags().Uint64("stop-block", 0, "last block to run the agent on")
+	cmdFortaAgentsRun.MarkFlagRequired("image")
+	cmdFortaAgentsRun.MarkFlagRequired("start-block")
+	cmdFortaAgentsRun.MarkFlagRequired("stop-block")
+
+	// forta logs
+	cmdFortaLogs.PersistentFlags().String("since", "", "show the logs since a timestamp (e.g. 2022-12-01T15:04:05) or a relative time (e.g. 30m)")
+	cmdFortaLogs.PersistentFlags().String("tail", "all", "number of lines to show from the end of the logs")
+	cmdFortaLogs.Flags().BoolP("follow", "f", false, "keep streaming the new logs and reconnect if the container restarts")
+	cmdFortaLogs.Flags().Bool("all", false, "show the logs of all node and agent containers with the container name at the start of each line")
+
 	// forta register
 	cmdFortaRegister.Flags().String("owner-address", "", "Ethereum wallet address of the scanner owner")
 	cmdFortaRegister.MarkFlagRequired("owner-address")
@@ -211,6 +451,10 @@ func initConfig() {
 	viper.BindEnv(keyFortaPassphrase)
 	viper.BindEnv(keyFortaDevelopment)
 	viper.BindEnv(keyFortaExposeNats)
+	viper.BindEnv(keyFortaConfigURL)
+	viper.BindEnv(keyFortaConfigToken)
+	viper.BindEnv(keyFortaNewPassphrase)
+	viper.BindEnv(keyFortaKeystorePassphrase)
 	viper.AutomaticEnv()
 
 	fortaDir := viper.GetString(keyFortaDir)
@@ -220,8 +464,20 @@ func initConfig() {
 		fortaDir = path.Join(home, ".forta")
 	}
 
-	configPath := path.Join(fortaDir, config.DefaultConfigFileName)
-	configBytes, _ := ioutil.ReadFile(configPath)
+	var configBytes []byte
+	if configURL := viper.GetString(keyFortaConfigURL); config.IsRemoteConfigPath(configURL) {
+		var err error
+		configBytes, err = config.FetchRemoteConfig(
+			context.Background(), configURL, viper.GetString(keyFortaConfigToken),
+			path.Join(fortaDir, config.DefaultRemoteConfigFileName),
+		)
+		if err != nil {
+			logrus.WithError(err).Fatal("failed to get the remote config")
+		}
+	} else {
+		configPath := path.Join(fortaDir, config.DefaultConfigFileName)
+		configBytes, _ = ioutil.ReadFile(configPath)
+	}
 	if err := yaml.Unmarshal(configBytes, &cfg); err != nil {
 		yellowBold("Your config file is invalid! Please check the values and fix any formatting issues.\n")
 		logrus.WithError(err).Fatal("failed to read config")
@@ -232,12 +488,17 @@ func initConfig() {
 	}
 
 	cfg.FortaDir = fortaDir
+	cfg.RemoteConfig = config.IsRemoteConfigPath(viper.GetString(keyFortaConfigURL))
 	cfg.KeyDirPath = path.Join(cfg.FortaDir, config.DefaultKeysDirName)
 	cfg.Development = viper.GetBool(keyFortaDevelopment)
 	cfg.Passphrase = viper.GetString(keyFortaPassphrase)
+	cfg.ApplyEnvDefaults()
+	config.SetInstanceName(cfg.InstanceName)
+	cfg.Network.Proxy.ApplyEnv()
+	cfg.Signer.ApplyEnv()
 
 	viper.ReadConfig(bytes.NewBuffer(configBytes))
-	config.InitLogLevel(cfg)
+	config.InitLogging(cfg, "cli")
 }
 
 var configEnvVarRegexp = regexp.MustCompile(`\$[A-Z0-9_]+`)
@@ -255,19 +516,12 @@ func resolveEnvVarsHookFunc(from reflect.Kind, to reflect.Kind, data interface{}
 }
 
 func validateConfig() error {
-	validate := validator.New()
-
-	// Use the YAML names while validating the struct.
-	validate.RegisterTagNameFunc(func(fld reflect.StructField) string {
-		name := strings.SplitN(fld.Tag.Get("yaml"), ",", 2)[0]
-		if name == "-" {
-			return ""
+	if err := cfg.Validate(); err != nil {
+		var validationErrs validator.ValidationErrors
+		if !errors.As(err, &validationErrs) {
+			fmt.Fprintf(os.Stderr, "Invalid config: %v\n", err)
+			return errors.New("invalid config file")
 		}
-		return name
-	})
-
-	if err := validate.Struct(&cfg); err != nil {
-		validationErrs := err.(validator.ValidationErrors)
 		fmt.Fprintln(os.Stderr, "The config file has invalid or missing fields:")
 		for _, validationErr := range validationErrs {
 			fmt.Fprintf(os.Stderr, "  - %s\n", validationErr.Namespace()[7:])
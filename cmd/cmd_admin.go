@@ -0,0 +1,133 @@
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/forta-network/forta-node/config"
+	"github.com/forta-network/forta-node/healthutils"
+	"github.com/spf13/cobra"
+)
+
+const adminRequestTimeout = time.Minute * 5 // restarts can take a while
+
+func handleFortaAdminRestartSupervisor(cmd *cobra.Command, args []string) error {
+	return callAdminAPI(cmd, http.MethodPost, "/admin/supervisor/restart")
+}
+
+func handleFortaAdminRestartUpdater(cmd *cobra.Command, args []string) error {
+	return callAdminAPI(cmd, http.MethodPost, "/admin/updater/restart")
+}
+
+func handleFortaAdminPauseUpdates(cmd *cobra.Command, args []string) error {
+	return callAdminAPI(cmd, http.MethodPost, "/admin/updates/pause")
+}
+
+func handleFortaAdminResumeUpdates(cmd *cobra.Command, args []string) error {
+	return callAdminAPI(cmd, http.MethodPost, "/admin/updates/resume")
+}
+
+func handleFortaAdminCheckUpdates(cmd *cobra.Command, args []string) error {
+	return callAdminAPI(cmd, http.MethodPost, "/admin/updates/check")
+}
+
+func handleFortaDrain(cmd *cobra.Command, args []string) error {
+	return callAdminAPI(cmd, http.MethodPost, "/admin/drain")
+}
+
+func handleFortaAdminRetryAgents(cmd *cobra.Command, args []string) error {
+	path := "/admin/agents/retry"
+	if len(args) > 0 {
+		path = fmt.Sprintf("%s?agentId=%s", path, url.QueryEscape(args[0]))
+	}
+	return callAdminAPI(cmd, http.MethodPost, path)
+}
+
+func handleFortaAgentsDisable(cmd *cobra.Command, args []string) error {
+	reason, _ := cmd.Flags().GetString("reason")
+	path := fmt.Sprintf("/admin/agents/%s/disable?reason=%s", url.PathEscape(args[0]), url.QueryEscape(reason))
+	return callAdminAPI(cmd, http.MethodPost, path)
+}
+
+func handleFortaAgentsEnable(cmd *cobra.Command, args []string) error {
+	return callAdminAPI(cmd, http.MethodPost, fmt.Sprintf("/admin/agents/%s/enable", url.PathEscape(args[0])))
+}
+
+func handleFortaAgentsRun(cmd *cobra.Command, args []string) error {
+	image, _ := cmd.Flags().GetString("image")
+	startBlock, _ := cmd.Flags().GetUint64("start-block")
+	stopBlock, _ := cmd.Flags().GetUint64("stop-block")
+	runReq := &healthutils.AgentRunRequest{ID: args[0], Image: image, StartBlock: startBlock, StopBlock: stopBlock}
+	if err := runReq.Validate(); err != nil {
+		return err
+	}
+	b, _ := json.Marshal(runReq)
+	return callAdminAPIWithBody(cmd, http.MethodPost, "/admin/agents/runs", bytes.NewReader(b))
+}
+
+func handleFortaAgentsReset(cmd *cobra.Command, args []string) error {
+	return callAdminAPI(cmd, http.MethodPost, "/admin/agents/reset")
+}
+
+func handleFortaAdminState(cmd *cobra.Command, args []string) error {
+	return callAdminAPI(cmd, http.MethodGet, "/admin/state")
+}
+
+// callAdminAPI calls the admin API of the runner on localhost and prints the response.
+func callAdminAPI(cmd *cobra.Command, method, path string) error {
+	return callAdminAPIWithBody(cmd, method, path, nil)
+}
+
+func callAdminAPIWithBody(cmd *cobra.Command, method, path string, body io.Reader) error {
+	b, statusCode, err := doAdminRequest(method, path, body)
+	if err != nil {
+		return err
+	}
+	var out bytes.Buffer
+	if err := json.Indent(&out, b, "", "  "); err != nil {
+		out.Reset()
+		out.Write(bytes.TrimSpace(b))
+	}
+	cmd.Println(out.String())
+	if statusCode != http.StatusOK {
+		return fmt.Errorf("admin api responded with code %d", statusCode)
+	}
+	return nil
+}
+
+// doAdminRequest calls the admin API of the runner on localhost and returns the response body.
+func doAdminRequest(method, path string, body io.Reader) ([]byte, int, error) {
+	if err := cfg.LoadPortMappings(); err != nil {
+		return nil, 0, err
+	}
+	if len(cfg.RunnerConfig.ControlPort) == 0 {
+		return nil, 0, errors.New("the control api is disabled - please set runner.controlPort in the config")
+	}
+	token, err := config.ReadAdminToken(cfg.FortaDir)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to read the admin token (is the node running?): %v", err)
+	}
+
+	req, err := http.NewRequest(method, fmt.Sprintf("http://127.0.0.1:%s%s", cfg.RunnerConfig.ControlPort, path), body)
+	if err != nil {
+		return nil, 0, err
+	}
+	req.Header.Set("Authorization", "Bearer "+token)
+	resp, err := (&http.Client{Timeout: adminRequestTimeout}).Do(req)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to call the node (is the node running?): %v", err)
+	}
+	defer resp.Body.Close()
+
+	b, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, 0, err
+	}
+	return b, resp.StatusCode, nil
+}
@@ -35,12 +35,22 @@ func handleFortaBatchDecode(cmd *cobra.Command, args []string) error {
 
 	cmd.PrintErrln("Downloading...")
 
-	batchResp, err := http.Get(fmt.Sprintf("%s/ipfs/%s", cfg.Publish.IPFS.GatewayURL, batchCid))
-	if err != nil {
-		return fmt.Errorf("failed to get batch: %v", err)
+	var batchResp *http.Response
+	for _, gatewayURL := range cfg.Publish.IPFS.Gateways() {
+		batchResp, err = http.Get(fmt.Sprintf("%s/ipfs/%s", gatewayURL, batchCid))
+		if err != nil {
+			err = fmt.Errorf("failed to get batch: %v", err)
+			continue
+		}
+		if batchResp.StatusCode != http.StatusOK {
+			batchResp.Body.Close()
+			err = fmt.Errorf("request to get batch failed with status %d", batchResp.StatusCode)
+			continue
+		}
+		break
 	}
-	if batchResp.StatusCode != http.StatusOK {
-		return fmt.Errorf("request to get batch failed with status %d", batchResp.StatusCode)
+	if err != nil {
+		return err
 	}
 	defer batchResp.Body.Close()
 
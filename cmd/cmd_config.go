@@ -0,0 +1,18 @@
+package cmd
+
+import (
+	"github.com/spf13/cobra"
+)
+
+func handleFortaConfigDump(cmd *cobra.Command, args []string) error {
+	effective, err := cfg.Effective()
+	if err != nil {
+		return err
+	}
+	b, err := effective.RedactedJSON()
+	if err != nil {
+		return err
+	}
+	cmd.Println(string(b))
+	return nil
+}
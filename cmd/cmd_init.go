@@ -78,6 +78,38 @@ const defaultConfig = `# Auto generated by 'forta init' - safe to modify
 # The chainId is the chainId of the network that is analyzed (1=mainnet)
 chainId: 1
 
+# Set a unique instanceName to run more than one node on the same host (each with its own forta dir
+# and ports). The containers are then named like forta-<instanceName>-supervisor.
+#instanceName: node2
+
+# Select the env defaults such as the container registry and the ENS contract (prod or dev by default)
+#profile: prod
+
+# Set the docker daemon socket if it is not /var/run/docker.sock (e.g. rootless docker)
+#docker:
+#  socketPath: /run/user/1000/docker.sock
+
+# Pick free host ports at start-up instead of failing if the configured ports are in use
+#ports:
+#  autoAssign: true
+
+# Send the outbound requests through a proxy (HTTP_PROXY, HTTPS_PROXY and NO_PROXY are used by default)
+#network:
+#  proxy:
+#    httpProxy: http://proxy.example.com:3128
+#    httpsProxy: http://proxy.example.com:3128
+#    noProxy: internal.example.com
+
+# Sign the batches with a Web3Signer compatible remote signer (the auth token can be set with FORTA_SIGNER_AUTH_TOKEN)
+#signer:
+#  type: remote
+#  remote:
+#    url: http://localhost:9000
+#    address: <scanner address>
+
+# Run the embedded images without the updater, the alert API, IPFS and the telemetry (air-gapped hosts)
+#offline: true
+
 # The scan settings are used to retrieve the transactions that are analyzed
 scan:
   jsonRpc:
@@ -97,6 +129,12 @@ trace:
 #    username: <set if needed>
 #    password: <set if needed>
 
+# Run the agents listed in local-agents.yml (in the forta dir) in addition to the registry agents
+# agent:
+#   allowLocal: true
+#   disabled: # never start these agents (see also 'forta agents disable')
+#     - <agent id>
+
 # The jsonRpcProxy settings are used make query requests (defaults to scan url)
 # jsonRpcProxy:
 #   jsonRpc:
@@ -105,15 +143,44 @@ trace:
 # The publish settings drive how alerts are sent
 # publish:
 #  ipfs:
-#    apiUrl: https://ipfs.forta.network
+#    gatewayUrl: https://ipfs.forta.network
+#    apiUrl: <set to add and pin the batches via your own IPFS node API>
 #    username: <set if needed>
 #    password: <set if needed>
 
 # The log settings drive the log output of the scan node
 # log:
 #  level: info
+#  format: text # or json
+#  levels:
+#    runner: debug
+#    json-rpc: warn
 #  maxLogSize: 50m
 #  maxLogFiles: 10
+#  logDriver: json-file # or local, gelf, syslog, journald
+#  logOpts:
+#    gelf-address: udp://<host>:12201
+#  remote:
+#    type: loki # or syslog, http
+#    url: https://<loki host>
+#    labels:
+#      node: <node name>
+#    includeContainers: true
+#  keepRemoved:
+#    lines: 1000 # save the last logs of the node containers to removed-logs before the updates remove them
+
+# The telemetry auth settings secure the node health endpoint
+# telemetry:
+#  auth:
+#    bearerToken: <set to require a token>
+#    allowLocalUnauthenticated: true
+#    tlsCertFile: <path to the certificate>
+#    tlsKeyFile: <path to the key>
+
+# The health settings configure the node health endpoint. The supervisor reaches this endpoint
+# from its container so do not bind it to 127.0.0.1.
+# health:
+#  bindAddr: :8090
 `
 
 func isDirInitialized() bool {
@@ -0,0 +1,108 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/forta-network/forta-node/store"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+func handleFortaKeysCreate(cmd *cobra.Command, args []string) error {
+	if !checkPassphrase() {
+		return errors.New("empty passphrase")
+	}
+	force, _ := cmd.Flags().GetBool("force")
+	address, err := store.NewScannerKeyStore(cfg.KeyDirPath).Create(cfg.Passphrase, force)
+	if err != nil {
+		return keysError(err)
+	}
+	printScannerAddress(address.Hex())
+	return nil
+}
+
+func handleFortaKeysImport(cmd *cobra.Command, args []string) error {
+	privateKeyPath, _ := cmd.Flags().GetString("private-key")
+	keystorePath, _ := cmd.Flags().GetString("keystore")
+	if (len(privateKeyPath) > 0) == (len(keystorePath) > 0) {
+		return errors.New("please provide either --private-key or --keystore")
+	}
+	if !checkPassphrase() {
+		return errors.New("empty passphrase")
+	}
+	force, _ := cmd.Flags().GetBool("force")
+	keyStore := store.NewScannerKeyStore(cfg.KeyDirPath)
+
+	if len(privateKeyPath) > 0 {
+		b, err := os.ReadFile(privateKeyPath)
+		if err != nil {
+			return fmt.Errorf("failed to read the private key: %v", err)
+		}
+		address, err := keyStore.ImportPrivateKey(string(b), cfg.Passphrase, force)
+		if err != nil {
+			return keysError(err)
+		}
+		printScannerAddress(address.Hex())
+		return nil
+	}
+
+	keyJSON, err := os.ReadFile(keystorePath)
+	if err != nil {
+		return fmt.Errorf("failed to read the keystore: %v", err)
+	}
+	// the keystore is encrypted with the node passphrase unless told otherwise
+	keystorePassphrase := viper.GetString(keyFortaKeystorePassphrase)
+	if len(keystorePassphrase) == 0 {
+		keystorePassphrase = cfg.Passphrase
+	}
+	address, err := keyStore.ImportKeystore(keyJSON, keystorePassphrase, cfg.Passphrase, force)
+	if err != nil {
+		return keysError(err)
+	}
+	printScannerAddress(address.Hex())
+	return nil
+}
+
+func handleFortaKeysExport(cmd *cobra.Command, args []string) error {
+	to, _ := cmd.Flags().GetString("to")
+	force, _ := cmd.Flags().GetBool("force")
+	address, err := store.NewScannerKeyStore(cfg.KeyDirPath).Export(to, cfg.Passphrase, force)
+	if err != nil {
+		return keysError(err)
+	}
+	greenBold("Exported the encrypted key of %s to %s\n", address.Hex(), to)
+	return nil
+}
+
+func handleFortaKeysChangePassphrase(cmd *cobra.Command, args []string) error {
+	newPassphrase := viper.GetString(keyFortaNewPassphrase)
+	if len(newPassphrase) == 0 {
+		redBold("Your new passphrase is not set. Please set it with FORTA_NEW_PASSPHRASE environment variable or provide it with the --new-passphrase flag.\n")
+		return errors.New("empty new passphrase")
+	}
+	keyStore := store.NewScannerKeyStore(cfg.KeyDirPath)
+	address, err := keyStore.ChangePassphrase(cfg.Passphrase, newPassphrase)
+	if err != nil {
+		return keysError(err)
+	}
+	greenBold("Changed the passphrase of %s - the previous key file is in %s\n", address.Hex(), keyStore.BackupDir())
+	yellowBold("Please use the new passphrase from now on.\n")
+	return nil
+}
+
+func checkPassphrase() bool {
+	if len(cfg.Passphrase) == 0 {
+		redBold("Your passphrase is not set. Please set it with FORTA_PASSPHRASE environment variable or provide it with the --passphrase flag.\n")
+		return false
+	}
+	return true
+}
+
+func keysError(err error) error {
+	if errors.Is(err, store.ErrKeyExists) {
+		yellowBold("You already have a scanner key at %s. Use --force to replace it (the old key is backed up).\n", cfg.KeyDirPath)
+	}
+	return err
+}
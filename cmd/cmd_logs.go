@@ -0,0 +1,310 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"sort"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/docker/docker/api/types"
+	"github.com/forta-network/forta-node/clients"
+	"github.com/forta-network/forta-node/config"
+	"github.com/spf13/cobra"
+)
+
+const logsReconnectInterval = time.Second * 2
+
+var errRunnerNotContainer = errors.New("the runner does not run in a container - see the output of 'forta run' or the service manager (e.g. journalctl -u forta) for its logs")
+
+// logComponents maps the friendly names to the node container names.
+func logComponents() map[string]string {
+	return map[string]string{
+		"supervisor":   config.DockerSupervisorContainerName,
+		"updater":      config.DockerUpdaterContainerName,
+		"scanner":      config.DockerScannerContainerName,
+		"json-rpc":     config.DockerJSONRPCProxyContainerName,
+		"nats":         config.DockerNatsContainerName,
+		"inspector":    config.DockerInspectorContainerName,
+		"jwt-provider": config.DockerJWTProviderContainerName,
+		"storage":      config.DockerStorageContainerName,
+		"egress-proxy": config.DockerEgressProxyContainerName,
+		"ipfs":         config.DockerIpfsContainerName,
+	}
+}
+
+func logComponentNames() (names []string) {
+	names = append(names, "runner")
+	for name := range logComponents() {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return
+}
+
+func handleFortaLogs(cmd *cobra.Command, args []string) error {
+	all, _ := cmd.Flags().GetBool("all")
+	follow, _ := cmd.Flags().GetBool("follow")
+	switch {
+	case all && len(args) > 0:
+		return errors.New("either give a component or an agent ID or use --all")
+	case all:
+		return streamAllLogs(cmd, follow)
+	case len(args) == 0:
+		return fmt.Errorf("please give a component (%s) or an agent ID prefix", strings.Join(logComponentNames(), ", "))
+	}
+	target := args[0]
+	return streamLogs(cmd, follow, func(containers clients.DockerContainerList) (*types.Container, error) {
+		return resolveLogContainer(containers, target)
+	})
+}
+
+func handleFortaLogsSupervisor(cmd *cobra.Command, args []string) error {
+	return streamLogs(cmd, true, func(containers clients.DockerContainerList) (*types.Container, error) {
+		return resolveLogContainer(containers, "supervisor")
+	})
+}
+
+func handleFortaLogsAgent(cmd *cobra.Command, args []string) error {
+	agentID := args[0]
+	return streamLogs(cmd, true, func(containers clients.DockerContainerList) (*types.Container, error) {
+		return findAgentContainer(containers, agentID)
+	})
+}
+
+// resolveLogContainer finds the container of the node component or the agent by the friendly name
+// or the agent ID prefix.
+func resolveLogContainer(containers clients.DockerContainerList, target string) (*types.Container, error) {
+	if target == "runner" {
+		return nil, errRunnerNotContainer
+	}
+	if name, ok := logComponents()[target]; ok {
+		for i, container := range containers {
+			if container.Names[0][1:] == name {
+				return &containers[i], nil
+			}
+		}
+		return nil, fmt.Errorf("%w for component '%s' (is the node running?)", clients.ErrContainerNotFound, target)
+	}
+	if !strings.HasPrefix(target, "0x") {
+		return nil, fmt.Errorf("unknown component '%s' - please give one of %s or an agent ID prefix",
+			target, strings.Join(logComponentNames(), ", "))
+	}
+	return findAgentContainer(containers, target)
+}
+
+// findAgentContainer finds the container of the agent by the ID prefix. The agent ID label is
+// used if the container has it and the short ID in the container name is used otherwise. The
+// running container is preferred if the agent container is being replaced.
+func findAgentContainer(containers clients.DockerContainerList, agentIDPrefix string) (*types.Container, error) {
+	agentIDPrefix = strings.ToLower(agentIDPrefix)
+	var (
+		found   *types.Container
+		matches = make(map[string]bool)
+	)
+	for i, container := range containers {
+		agentID, ok := agentContainerID(container)
+		if !ok || !matchesAgentIDPrefix(agentID, agentIDPrefix) {
+			continue
+		}
+		matches[agentID] = true
+		if found == nil || container.State == "running" {
+			found = &containers[i]
+		}
+	}
+	switch {
+	case found == nil:
+		return nil, fmt.Errorf("%w for agent '%s'", clients.ErrContainerNotFound, agentIDPrefix)
+	case len(matches) > 1:
+		var agentIDs []string
+		for agentID := range matches {
+			agentIDs = append(agentIDs, agentID)
+		}
+		sort.Strings(agentIDs)
+		return nil, fmt.Errorf("agent ID prefix '%s' matches multiple agents: %s", agentIDPrefix, strings.Join(agentIDs, ", "))
+	}
+	return found, nil
+}
+
+// agentContainerID returns the agent ID from the label or the short agent ID from the name of a
+// steady-state agent container.
+func agentContainerID(container types.Container) (string, bool) {
+	name := container.Names[0][1:]
+	if !strings.HasPrefix(name, config.DockerAgentContainerNamePrefix) || strings.Contains(name, "-run-") {
+		return "", false
+	}
+	if agentID, ok := container.Labels[clients.DockerLabelFortaAgentID]; ok {
+		return strings.ToLower(agentID), true
+	}
+	shortID := strings.TrimPrefix(name, config.DockerAgentContainerNamePrefix)
+	if i := strings.Index(shortID, "-"); i >= 0 {
+		shortID = shortID[:i]
+	}
+	return strings.ToLower(shortID), true
+}
+
+// matchesAgentIDPrefix tells if the prefix matches the agent ID. The short IDs from the container
+// names are matched with the same length of the prefix.
+func matchesAgentIDPrefix(agentID, prefix string) bool {
+	if len(prefix) > len(agentID) {
+		return strings.HasPrefix(prefix, agentID)
+	}
+	return strings.HasPrefix(agentID, prefix)
+}
+
+// logContainerName returns the short name to prefix the log lines with.
+func logContainerName(containerName string) string {
+	return strings.TrimPrefix(containerName, strings.TrimSuffix(config.DockerAgentContainerNamePrefix, "agent-"))
+}
+
+func logOptions(cmd *cobra.Command, follow bool) clients.ContainerLogsOptions {
+	since, _ := cmd.Flags().GetString("since")
+	tail, _ := cmd.Flags().GetString("tail")
+	return clients.ContainerLogsOptions{Since: since, Tail: tail, Follow: follow}
+}
+
+func streamLogs(cmd *cobra.Command, follow bool, findContainer func(clients.DockerContainerList) (*types.Container, error)) error {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	dockerClient, err := clients.NewDockerClient("")
+	if err != nil {
+		return fmt.Errorf("failed to create the docker client: %v", err)
+	}
+	find := func(ctx context.Context) (*types.Container, error) {
+		containers, err := dockerClient.GetContainers(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get the containers: %v", err)
+		}
+		return findContainer(containers)
+	}
+	// fail early if there is no such container
+	if _, err := find(ctx); err != nil {
+		return err
+	}
+	return streamContainerLogs(ctx, dockerClient, find, logOptions(cmd, follow), cmd.OutOrStdout(), cmd.ErrOrStderr())
+}
+
+// streamAllLogs streams the logs of all node and agent containers with the container name
+// at the start of each line.
+func streamAllLogs(cmd *cobra.Command, follow bool) error {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	dockerClient, err := clients.NewDockerClient("")
+	if err != nil {
+		return fmt.Errorf("failed to create the docker client: %v", err)
+	}
+	containers, err := dockerClient.GetContainers(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to get the containers: %v", err)
+	}
+	if len(containers) == 0 {
+		return fmt.Errorf("%w (is the node running?)", clients.ErrContainerNotFound)
+	}
+
+	var (
+		mu sync.Mutex
+		wg sync.WaitGroup
+	)
+	opts := logOptions(cmd, follow)
+	for _, container := range containers {
+		name := container.Names[0][1:]
+		prefix := fmt.Sprintf("%s | ", logContainerName(name))
+		stdout := &prefixWriter{w: cmd.OutOrStdout(), prefix: prefix, mu: &mu}
+		stderr := &prefixWriter{w: cmd.ErrOrStderr(), prefix: prefix, mu: &mu}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			find := func(ctx context.Context) (*types.Container, error) {
+				return dockerClient.GetContainerByName(ctx, name)
+			}
+			if err := streamContainerLogs(ctx, dockerClient, find, opts, stdout, stderr); err != nil {
+				redBold("failed to stream the logs of %s: %v\n", name, err)
+			}
+			stdout.Flush()
+			stderr.Flush()
+		}()
+	}
+	wg.Wait()
+	return nil
+}
+
+// streamContainerLogs streams the logs of the container. While following, it finds the container
+// again and reconnects after the container restarts or is replaced.
+func streamContainerLogs(
+	ctx context.Context, dockerClient clients.DockerClient, find func(context.Context) (*types.Container, error),
+	opts clients.ContainerLogsOptions, stdout, stderr io.Writer,
+) error {
+	for {
+		container, err := find(ctx)
+		switch {
+		case ctx.Err() != nil:
+			return nil
+		case err != nil && !opts.Follow:
+			return err
+		case err == nil && (!opts.Follow || container.State == "running"):
+			err = dockerClient.StreamContainerLogs(ctx, container.ID, opts, stdout, stderr)
+			if ctx.Err() != nil || !opts.Follow {
+				return err
+			}
+			name := container.Names[0][1:]
+			if err != nil {
+				yellowBold("lost the logs of %s: %v\n", name, err)
+			}
+			yellowBold("%s stopped - waiting for it to restart\n", name)
+			// continue from where it was left
+			opts.Since = time.Now().Format(time.RFC3339Nano)
+			opts.Tail = "all"
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-time.After(logsReconnectInterval):
+		}
+	}
+}
+
+// prefixWriter writes the prefix at the start of each line. The lines from multiple writers are
+// not mixed because they are written with the same lock.
+type prefixWriter struct {
+	w      io.Writer
+	prefix string
+	mu     *sync.Mutex
+	buf    bytes.Buffer
+}
+
+func (pw *prefixWriter) Write(p []byte) (int, error) {
+	pw.buf.Write(p)
+	for {
+		i := bytes.IndexByte(pw.buf.Bytes(), '\n')
+		if i < 0 {
+			return len(p), nil
+		}
+		if err := pw.writeLine(pw.buf.Next(i + 1)); err != nil {
+			return 0, err
+		}
+	}
+}
+
+// Flush writes the last incomplete line.
+func (pw *prefixWriter) Flush() {
+	if pw.buf.Len() > 0 {
+		pw.writeLine(append(pw.buf.Next(pw.buf.Len()), '\n'))
+	}
+}
+
+func (pw *prefixWriter) writeLine(line []byte) error {
+	pw.mu.Lock()
+	defer pw.mu.Unlock()
+	_, err := fmt.Fprintf(pw.w, "%s%s", pw.prefix, line)
+	return err
+}
@@ -0,0 +1,113 @@
+package cmd
+
+import (
+	"bytes"
+	"errors"
+	"sync"
+	"testing"
+
+	"github.com/docker/docker/api/types"
+	"github.com/forta-network/forta-node/clients"
+	"github.com/forta-network/forta-node/config"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	testLogsAgentID1 = "0x04f65c638f234548104790b8ab0e3e0f4add0a6d5b9da7d7ba4b9d8c6c6ba7f0"
+	testLogsAgentID2 = "0x04f65c63aaaaaaaa04790b8ab0e3e0f4add0a6d5b9da7d7ba4b9d8c6c6ba7f0"
+	testLogsAgentID3 = "0x1234567890abcdef04790b8ab0e3e0f4add0a6d5b9da7d7ba4b9d8c6c6ba7f0"
+)
+
+func testLogsContainer(name, state string, labels map[string]string) types.Container {
+	return types.Container{ID: name + "-id", Names: []string{"/" + name}, State: state, Labels: labels}
+}
+
+func TestResolveLogContainer(t *testing.T) {
+	r := require.New(t)
+
+	containers := clients.DockerContainerList{
+		testLogsContainer(config.DockerSupervisorContainerName, "running", nil),
+		testLogsContainer(config.DockerScannerContainerName, "running", nil),
+		testLogsContainer(config.DockerJSONRPCProxyContainerName, "running", nil),
+		// the old container of the agent which is being replaced
+		testLogsContainer(config.DockerAgentContainerNamePrefix+"0x04f65c-aaaa", "exited", map[string]string{
+			clients.DockerLabelFortaAgentID: testLogsAgentID1,
+		}),
+		testLogsContainer(config.DockerAgentContainerNamePrefix+"0x04f65c-bbbb", "running", map[string]string{
+			clients.DockerLabelFortaAgentID: testLogsAgentID1,
+		}),
+		// no label
+		testLogsContainer(config.DockerAgentContainerNamePrefix+"0x123456-cccc", "running", nil),
+		// one-off run
+		testLogsContainer(config.DockerAgentContainerNamePrefix+"0x123456-run-1a2b3c4d", "running", map[string]string{
+			clients.DockerLabelFortaAgentID: testLogsAgentID3,
+		}),
+	}
+
+	container, err := resolveLogContainer(containers, "scanner")
+	r.NoError(err)
+	r.Equal(config.DockerScannerContainerName+"-id", container.ID)
+
+	container, err = resolveLogContainer(containers, "json-rpc")
+	r.NoError(err)
+	r.Equal(config.DockerJSONRPCProxyContainerName+"-id", container.ID)
+
+	_, err = resolveLogContainer(containers, "nats")
+	r.True(errors.Is(err, clients.ErrContainerNotFound))
+
+	_, err = resolveLogContainer(containers, "runner")
+	r.ErrorIs(err, errRunnerNotContainer)
+
+	_, err = resolveLogContainer(containers, "foo")
+	r.ErrorContains(err, "unknown component")
+
+	// the running container is preferred and the full ID and the prefixes match the label
+	for _, target := range []string{testLogsAgentID1, "0x04f65c638f", "0x04F6"} {
+		container, err = resolveLogContainer(containers, target)
+		r.NoError(err, target)
+		r.Equal(config.DockerAgentContainerNamePrefix+"0x04f65c-bbbb-id", container.ID, target)
+	}
+
+	// the short ID from the name is used without the label and the one-off runs are ignored
+	for _, target := range []string{testLogsAgentID3, "0x1234"} {
+		container, err = resolveLogContainer(containers, target)
+		r.NoError(err, target)
+		r.Equal(config.DockerAgentContainerNamePrefix+"0x123456-cccc-id", container.ID, target)
+	}
+
+	_, err = resolveLogContainer(containers, "0xffff")
+	r.True(errors.Is(err, clients.ErrContainerNotFound))
+
+	// ambiguous prefix
+	containers = append(containers, testLogsContainer(config.DockerAgentContainerNamePrefix+"0x04f65c-dddd", "running", map[string]string{
+		clients.DockerLabelFortaAgentID: testLogsAgentID2,
+	}))
+	_, err = resolveLogContainer(containers, "0x04f65c")
+	r.ErrorContains(err, "matches multiple agents")
+	container, err = resolveLogContainer(containers, "0x04f65c63a")
+	r.NoError(err)
+	r.Equal(config.DockerAgentContainerNamePrefix+"0x04f65c-dddd-id", container.ID)
+}
+
+func TestLogContainerName(t *testing.T) {
+	r := require.New(t)
+
+	r.Equal("scanner", logContainerName(config.DockerScannerContainerName))
+	r.Equal("agent-0x04f65c-bbbb", logContainerName(config.DockerAgentContainerNamePrefix+"0x04f65c-bbbb"))
+}
+
+func TestPrefixWriter(t *testing.T) {
+	r := require.New(t)
+
+	var (
+		buf bytes.Buffer
+		mu  sync.Mutex
+	)
+	w1 := &prefixWriter{w: &buf, prefix: "scanner | ", mu: &mu}
+	w2 := &prefixWriter{w: &buf, prefix: "nats | ", mu: &mu}
+	w1.Write([]byte("line 1\nline"))
+	w2.Write([]byte("line a\n"))
+	w1.Write([]byte(" 2\nline 3"))
+	w1.Flush()
+	r.Equal("scanner | line 1\nnats | line a\nscanner | line 2\nscanner | line 3\n", buf.String())
+}
@@ -4,10 +4,15 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"os"
+	"time"
 
-	"github.com/forta-network/forta-core-go/registry"
 	"github.com/forta-network/forta-core-go/security"
+	"github.com/forta-network/forta-node/clients/logforward"
+	"github.com/forta-network/forta-node/clients/signer"
 	"github.com/forta-network/forta-node/cmd/runner"
+	"github.com/forta-network/forta-node/config"
+	runnerservice "github.com/forta-network/forta-node/services/runner"
 	"github.com/forta-network/forta-node/store"
 	"github.com/spf13/cobra"
 )
@@ -15,10 +20,31 @@ import (
 // errors
 var (
 	ErrCannotRunScanner = errors.New("cannot run scanner")
+	ErrDryRunFailed     = errors.New("dry run failed")
 )
 
 func handleFortaRun(cmd *cobra.Command, args []string) error {
-	if err := checkScannerState(); err != nil {
+	if err := config.InitLogging(cfg, "runner"); err != nil {
+		return fmt.Errorf("failed to initialize logging: %v", err)
+	}
+	if parsedArgs.StrictRegistration {
+		cfg.RunnerConfig.StrictRegistration = true
+	}
+	if parsedArgs.DryRun {
+		return handleFortaDryRun()
+	}
+	if cfg.Log.Remote.Enabled() {
+		forwarder, err := logforward.Install(cfg.Log.Remote, nil)
+		if err != nil {
+			return err
+		}
+		defer forwarder.Close()
+	}
+	if err := checkBatchSigningKey(); err != nil {
+		return err
+	}
+	scannerStatus := store.NewScannerStatusClient(context.Background(), cfg)
+	if err := checkScannerState(scannerStatus); err != nil {
 		return err
 	}
 	if cfg.LocalModeConfig.Enable {
@@ -29,11 +55,50 @@ func handleFortaRun(cmd *cobra.Command, args []string) error {
 			yellowBold("No webhook URL specified! Logging alerts in %s/logs/\n", cfg.FortaDir)
 		}
 	}
-	runner.Run(cfg)
+	runner.Run(cfg, scannerStatus)
+	return nil
+}
+
+func handleFortaDryRun() error {
+	report, err := runner.DryRun(cfg, parsedArgs.NoCheck)
+	if err != nil {
+		return err
+	}
+	for _, result := range report.Results {
+		latency := result.Latency.Round(time.Millisecond)
+		switch {
+		case result.OK:
+			greenBold("PASS ")
+			fmt.Printf("%s (%s)\n", result.Name, latency)
+		case result.Required:
+			redBold("FAIL ")
+			fmt.Fprintf(os.Stderr, "%s (%s): %s\n", result.Name, latency, result.Error)
+		default:
+			yellowBold("WARN ")
+			fmt.Fprintf(os.Stderr, "%s (%s): %s\n", result.Name, latency, result.Error)
+		}
+	}
+	if !report.OK {
+		redBold("The node is not ready to run.\n")
+		return ErrDryRunFailed
+	}
+	greenBold("The node is ready to run.\n")
 	return nil
 }
 
-func checkScannerState() error {
+// checkBatchSigningKey makes sure that the publisher will be able to sign the batches
+// with the configured signer instead of failing after the node starts.
+func checkBatchSigningKey() error {
+	err := runnerservice.CheckBatchSigningKey(cfg)
+	if signer.IsUnavailable(err) {
+		// the publisher keeps the batches until the signer is reachable
+		yellowBold("The remote signer is not reachable right now: %v\n", err)
+		return nil
+	}
+	return err
+}
+
+func checkScannerState(scannerStatus store.ScannerStatusClient) error {
 	// disable registration and staking check in local mode
 	if cfg.LocalModeConfig.Enable {
 		return nil
@@ -42,6 +107,10 @@ func checkScannerState() error {
 	if parsedArgs.NoCheck {
 		return nil
 	}
+	// the registry is not reachable in offline mode
+	if cfg.OfflineSkip("registry") {
+		return nil
+	}
 
 	scannerKey, err := security.LoadKeyWithPassphrase(cfg.KeyDirPath, cfg.Passphrase)
 	if err != nil {
@@ -49,26 +118,18 @@ func checkScannerState() error {
 	}
 	scannerAddressStr := scannerKey.Address.Hex()
 
-	registry, err := store.GetRegistryClient(context.Background(), cfg, registry.ClientConfig{
-		JsonRpcUrl: cfg.Registry.JsonRpc.Url,
-		ENSAddress: cfg.ENSConfig.ContractAddress,
-		Name:       "registry-client",
-	})
-	if err != nil {
-		return fmt.Errorf("failed to create registry client: %v", err)
-	}
-	scanner, err := registry.GetScanner(scannerAddressStr)
+	status, err := scannerStatus.GetScannerStatus(context.Background(), scannerAddressStr)
 	if err != nil {
 		return fmt.Errorf("failed to check scanner state: %v", err)
 	}
 
 	// treat reverts the same as non-registered
-	if scanner == nil {
+	if !status.Registered {
 		yellowBold("Scanner not registered - please make sure you register with 'forta register' first.\n")
 		toStderr("You can disable this behaviour with --no-check flag.\n")
 		return ErrCannotRunScanner
 	}
-	if !scanner.Enabled {
+	if !status.Enabled || status.StakeBelowMinimum {
 		yellowBold("Warning! Your scan node is either disabled or does not meet with the minimum staking requirement. It will not receive any detection bots yet.\n")
 	}
 	return nil
@@ -4,20 +4,24 @@ import (
 	"bytes"
 	"encoding/csv"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"os"
 	"sort"
 	"strings"
+	"time"
 
 	"github.com/fatih/color"
 	"github.com/forta-network/forta-core-go/clients/health"
 	"github.com/forta-network/forta-node/config"
+	"github.com/forta-network/forta-node/healthutils"
 	"github.com/spf13/cobra"
 )
 
 // status formats
 const (
+	StatusFormatTable   = "table"
 	StatusFormatPretty  = "pretty"
 	StatusFormatOneline = "oneline"
 	StatusFormatJSON    = "json"
@@ -30,6 +34,17 @@ const (
 
 var ballPrefix = "⬤ "
 
+// the component report prefix and the report names which the status table uses
+const (
+	containerReportPrefix   = "forta.container."
+	nodeReportName          = "forta.node"
+	blockTimeReportName     = "event.block.time"
+	alertsLastHourReport    = "alerts.published.last-hour"
+	statusTableErrMaxLength = 60
+)
+
+var errNodeNotRunning = errors.New("node is not running")
+
 func handleFortaStatus(cmd *cobra.Command, args []string) error {
 	format, err := cmd.Flags().GetString("format")
 	if err != nil {
@@ -50,12 +65,38 @@ func handleFortaStatus(cmd *cobra.Command, args []string) error {
 		ballPrefix = ""
 	}
 
+	asJSON, err := cmd.Flags().GetBool("json")
+	if err != nil {
+		return err
+	}
+
+	if err := cfg.LoadPortMappings(); err != nil {
+		return err
+	}
+
 	// call the runner health server on localhost
-	allReports := health.NewClient().CheckHealth("forta", config.DefaultHealthPort)
+	allReports := healthutils.CheckNodeHealth(cfg.TelemetryConfig.Auth, cfg.Health.Port())
 	sort.Slice(allReports, func(i, j int) bool {
 		return sort.StringsAreSorted([]string{allReports[i].Name, allReports[j].Name})
 	})
 
+	if asJSON {
+		color.NoColor = true
+		if err := formatReportsJSON(allReports); err != nil {
+			return err
+		}
+		return checkStatus(allReports)
+	}
+	if nodeNotRunning(allReports) {
+		redBold("The node is not running: no response from the health API at port %s.\n", cfg.Health.Port())
+		toStderr("Please start it with 'forta run' and try again.\n")
+		return errNodeNotRunning
+	}
+	if format == StatusFormatTable {
+		formatReportsTable(allReports, time.Now())
+		return checkStatus(allReports)
+	}
+
 	var reports health.Reports
 	for _, report := range allReports {
 		var shouldInclude bool
@@ -83,17 +124,21 @@ func handleFortaStatus(cmd *cobra.Command, args []string) error {
 
 	case StatusFormatJSON:
 		color.NoColor = true
-		return formatReportsJSON(reports)
+		if err := formatReportsJSON(reports); err != nil {
+			return err
+		}
 
 	case StatusFormatCSV:
 		color.NoColor = true
-		return formatReportsCSV(reports)
+		if err := formatReportsCSV(reports); err != nil {
+			return err
+		}
 
 	default:
 		return fmt.Errorf("unknown format: %v", format)
 	}
 
-	return nil
+	return checkStatus(allReports)
 }
 
 func formatReportsPretty(reports health.Reports) {
@@ -193,3 +238,167 @@ func writeColoredBall(w io.Writer, c color.Attribute) {
 	}
 	color.New(c).Fprint(w, ballPrefix)
 }
+
+// nodeNotRunning tells if the runner health API did not respond.
+func nodeNotRunning(reports health.Reports) bool {
+	report, ok := reports.GetByName("health-api")
+	return ok && len(reports) == 1 && report.Status == health.StatusDown
+}
+
+// checkStatus returns an error if the node or any of the containers is not healthy so that
+// the command exits with a non-zero code.
+func checkStatus(reports health.Reports) error {
+	if nodeNotRunning(reports) {
+		return errNodeNotRunning
+	}
+	var unhealthy []string
+	for _, component := range statusComponents(reports, time.Now()) {
+		if !statusHealthy(component.Status) {
+			unhealthy = append(unhealthy, component.Name)
+		}
+	}
+	if len(unhealthy) > 0 {
+		return fmt.Errorf("unhealthy components: %s", strings.Join(unhealthy, ", "))
+	}
+	return nil
+}
+
+func statusHealthy(status health.Status) bool {
+	switch status {
+	case health.StatusFailing, health.StatusDown, health.StatusUnknown:
+		return false
+	}
+	return true
+}
+
+// statusComponent is a row of the status table.
+type statusComponent struct {
+	Name      string
+	Status    health.Status
+	State     string
+	Image     string
+	LastError string
+	BlockLag  string
+	Alerts    string
+}
+
+// statusComponents groups the reports by the containers and adds the node as the last component.
+func statusComponents(reports health.Reports, now time.Time) (components []*statusComponent) {
+	byName := make(map[string]*statusComponent)
+	for _, report := range reports {
+		if !strings.HasPrefix(report.Name, containerReportPrefix) {
+			continue
+		}
+		name := strings.TrimPrefix(report.Name, containerReportPrefix)
+		var subName string
+		if i := strings.Index(name, "."); i >= 0 {
+			name, subName = name[:i], name[i+1:]
+		}
+		component, ok := byName[name]
+		if !ok {
+			component = &statusComponent{Name: name}
+			byName[name] = component
+			components = append(components, component)
+		}
+		switch {
+		case len(subName) == 0:
+			component.Status = report.Status
+			component.State = report.Details
+		case subName == "image":
+			component.Image = shortDigest(report.Details)
+		case strings.HasSuffix(subName, alertsLastHourReport):
+			component.Alerts = report.Details
+		case strings.HasSuffix(subName, blockTimeReportName) && name == config.DockerScannerContainerName:
+			if t, err := time.Parse(time.RFC3339, report.Details); err == nil {
+				component.BlockLag = now.Sub(t).Round(time.Second).String()
+			}
+		}
+		if len(subName) > 0 && len(component.LastError) == 0 && !statusHealthy(report.Status) && len(report.Details) > 0 {
+			component.LastError = fmt.Sprintf("%s: %s", subName, report.Details)
+		}
+	}
+	sort.Slice(components, func(i, j int) bool {
+		return components[i].Name < components[j].Name
+	})
+	if node, ok := reports.GetByName(nodeReportName); ok {
+		components = append(components, &statusComponent{
+			Name:      nodeReportName,
+			Status:    node.Status,
+			LastError: node.Details,
+		})
+	}
+	return
+}
+
+// shortDigest shortens the image digest like the docker CLI does.
+func shortDigest(digest string) string {
+	digest = strings.TrimPrefix(digest, "sha256:")
+	if len(digest) > 12 {
+		return digest[:12]
+	}
+	return digest
+}
+
+func formatReportsTable(reports health.Reports, now time.Time) {
+	header := []string{"COMPONENT", "STATE", "IMAGE", "BLOCK LAG", "ALERTS (1H)", "LAST ERROR"}
+	var rows [][]string
+	components := statusComponents(reports, now)
+	for _, component := range components {
+		lastError := component.LastError
+		if len(lastError) > statusTableErrMaxLength {
+			lastError = lastError[:statusTableErrMaxLength-3] + "..."
+		}
+		rows = append(rows, []string{
+			component.Name, orDash(component.State), orDash(component.Image),
+			orDash(component.BlockLag), orDash(component.Alerts), orDash(lastError),
+		})
+	}
+
+	widths := make([]int, len(header))
+	for _, row := range append([][]string{header}, rows...) {
+		for i, cell := range row {
+			if len(cell) > widths[i] {
+				widths[i] = len(cell)
+			}
+		}
+	}
+
+	// pad before coloring so that the escape codes do not break the alignment
+	w := new(bytes.Buffer)
+	fmt.Fprint(w, strings.Repeat(" ", len([]rune(ballPrefix))))
+	for i, cell := range header {
+		writeName(w, padCell(cell, widths[i], i == len(header)-1))
+	}
+	fmt.Fprint(w, "\n")
+	for i, row := range rows {
+		status := components[i].Status
+		writeStatusBall(w, status)
+		for j, cell := range row {
+			cell = padCell(cell, widths[j], j == len(row)-1)
+			switch j {
+			case 0:
+				writeStatus(w, cell)
+			case len(row) - 1:
+				writeDetails(w, status, cell)
+			default:
+				fmt.Fprint(w, cell)
+			}
+		}
+		fmt.Fprint(w, "\n")
+	}
+	fmt.Fprint(os.Stdout, w.String())
+}
+
+func padCell(cell string, width int, last bool) string {
+	if last {
+		return cell
+	}
+	return fmt.Sprintf("%-*s  ", width, cell)
+}
+
+func orDash(s string) string {
+	if len(s) == 0 {
+		return "-"
+	}
+	return s
+}
@@ -6,10 +6,13 @@ import (
 	"fmt"
 	"strings"
 
+	"github.com/ethereum/go-ethereum/accounts/keystore"
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/fatih/color"
 	"github.com/forta-network/forta-core-go/registry"
 	"github.com/forta-network/forta-core-go/security"
+	"github.com/forta-network/forta-node/clients/signer"
+	"github.com/forta-network/forta-node/config"
 	"github.com/forta-network/forta-node/store"
 	"github.com/spf13/cobra"
 )
@@ -23,9 +26,9 @@ func handleFortaRegister(cmd *cobra.Command, args []string) error {
 		return errors.New("invalid owner address provided")
 	}
 
-	scannerKey, err := security.LoadKeyWithPassphrase(cfg.KeyDirPath, cfg.Passphrase)
+	scannerKey, err := loadTxKey()
 	if err != nil {
-		return fmt.Errorf("failed to load scanner key: %v", err)
+		return err
 	}
 	scannerPrivateKey := scannerKey.PrivateKey
 	scannerAddressStr := scannerKey.Address.Hex()
@@ -61,9 +64,9 @@ func handleFortaRegister(cmd *cobra.Command, args []string) error {
 }
 
 func handleFortaEnable(cmd *cobra.Command, args []string) error {
-	scannerKey, err := security.LoadKeyWithPassphrase(cfg.KeyDirPath, cfg.Passphrase)
+	scannerKey, err := loadTxKey()
 	if err != nil {
-		return fmt.Errorf("failed to load scanner key: %v", err)
+		return err
 	}
 	scannerPrivateKey := scannerKey.PrivateKey
 	scannerAddressStr := scannerKey.Address.Hex()
@@ -95,9 +98,9 @@ func handleFortaEnable(cmd *cobra.Command, args []string) error {
 }
 
 func handleFortaDisable(cmd *cobra.Command, args []string) error {
-	scannerKey, err := security.LoadKeyWithPassphrase(cfg.KeyDirPath, cfg.Passphrase)
+	scannerKey, err := loadTxKey()
 	if err != nil {
-		return fmt.Errorf("failed to load scanner key: %v", err)
+		return err
 	}
 	scannerPrivateKey := scannerKey.PrivateKey
 	scannerAddressStr := scannerKey.Address.Hex()
@@ -127,3 +130,19 @@ func handleFortaDisable(cmd *cobra.Command, args []string) error {
 
 	return nil
 }
+
+// loadTxKey loads the scanner key to send the registry transactions with. The transactions
+// are always signed with the local key.
+func loadTxKey() (*keystore.Key, error) {
+	switch cfg.Signer.SignerType() {
+	case config.SignerTypeReadOnly:
+		return nil, fmt.Errorf("can not send the transaction: %w", signer.ErrReadOnly)
+	case config.SignerTypeRemote:
+		return nil, errors.New("can not send the transaction: the remote signer is not supported for the registry transactions - please send it from a node with the local scanner key")
+	}
+	scannerKey, err := security.LoadKeyWithPassphrase(cfg.KeyDirPath, cfg.Passphrase)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load scanner key: %v", err)
+	}
+	return scannerKey, nil
+}
@@ -2,17 +2,141 @@ package cmd
 
 import (
 	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
 
 	"github.com/forta-network/forta-node/config"
 	"github.com/spf13/cobra"
 )
 
 func handleFortaVersion(cmd *cobra.Command, args []string) error {
-	releaseSummary, ok := config.GetBuildReleaseSummary()
-	if !ok {
+	asJSON, _ := cmd.Flags().GetBool("json")
+
+	// the build info of this binary is shown if the node is not running
+	version, err := getNodeVersion()
+	nodeRunning := err == nil
+	if !nodeRunning {
+		version = config.GetBuildNodeVersion()
+	}
+
+	if asJSON {
+		b, _ := json.MarshalIndent(version, "", "  ")
+		cmd.Println(string(b))
 		return nil
 	}
-	b, _ := json.MarshalIndent(releaseSummary, "", "  ")
-	cmd.Println(string(b))
+	formatNodeVersion(cmd.OutOrStdout(), version, nodeRunning)
 	return nil
 }
+
+// getNodeVersion gets the version info from the runner state.
+func getNodeVersion() (*config.NodeVersion, error) {
+	b, statusCode, err := doAdminRequest(http.MethodGet, "/admin/version", nil)
+	if err != nil {
+		return nil, err
+	}
+	if statusCode != http.StatusOK {
+		return nil, fmt.Errorf("admin api responded with code %d", statusCode)
+	}
+	var version config.NodeVersion
+	if err := json.Unmarshal(b, &version); err != nil {
+		return nil, fmt.Errorf("failed to decode the version info: %v", err)
+	}
+	return &version, nil
+}
+
+// formatNodeVersion writes the version info in the human readable form.
+func formatNodeVersion(w io.Writer, version *config.NodeVersion, nodeRunning bool) {
+	line := func(label, value string) {
+		fmt.Fprintf(w, "%-24s %s\n", label+":", orDash(value))
+	}
+	section := func(label string) {
+		fmt.Fprintf(w, "%s:\n", label)
+	}
+
+	if version.Build != nil {
+		line("Runner build", formatBuild(version.Build.Version, version.Build.Commit))
+	} else {
+		line("Runner build", "development build")
+	}
+	section("Embedded images")
+	line("  supervisor", version.Embedded.Supervisor)
+	line("  updater", version.Embedded.Updater)
+
+	if !nodeRunning {
+		fmt.Fprintln(w, "The node is not running: showing the build info only.")
+		return
+	}
+
+	line("Release channel", version.ReleaseChannel)
+	section("Running images")
+	line("  supervisor", version.Running.Supervisor)
+	line("  updater", formatRunningUpdater(version))
+	line("Running release", formatRelease(version.RunningRelease))
+	switch {
+	case !version.UpdatesEnabled:
+		line("Latest release", "updates are disabled")
+	case version.LatestRelease == nil:
+		line("Latest release", "not received from the updater yet")
+	default:
+		line("Latest release", formatRelease(version.LatestRelease))
+	}
+
+	if len(version.Containers) == 0 {
+		return
+	}
+	section("Containers")
+	var names []string
+	for name := range version.Containers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		line("  "+name, version.Containers[name])
+	}
+}
+
+func formatRunningUpdater(version *config.NodeVersion) string {
+	if !version.UpdatesEnabled {
+		return "not running (updates are disabled)"
+	}
+	return version.Running.Updater
+}
+
+func formatBuild(version, commit string) string {
+	switch {
+	case len(version) > 0 && len(commit) > 0:
+		return fmt.Sprintf("%s (commit %s)", version, commit)
+	case len(commit) > 0:
+		return "commit " + commit
+	default:
+		return version
+	}
+}
+
+func formatRelease(release *config.NodeRelease) string {
+	if release == nil {
+		return ""
+	}
+	var details []string
+	if len(release.Commit) > 0 {
+		details = append(details, "commit "+release.Commit)
+	}
+	if len(release.Channel) > 0 {
+		details = append(details, "channel "+release.Channel)
+	}
+	if len(release.Timestamp) > 0 {
+		details = append(details, "released at "+release.Timestamp)
+	}
+	if release.SeenAt != nil {
+		details = append(details, "seen at "+release.SeenAt.Format(time.RFC3339))
+	}
+	s := orDash(release.Version)
+	if len(details) > 0 {
+		s = fmt.Sprintf("%s (%s)", s, strings.Join(details, ", "))
+	}
+	return s
+}
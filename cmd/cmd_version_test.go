@@ -0,0 +1,95 @@
+package cmd
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/forta-network/forta-core-go/release"
+	"github.com/forta-network/forta-node/config"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFormatNodeVersion(t *testing.T) {
+	r := require.New(t)
+
+	seenAt := time.Date(2022, 12, 2, 10, 0, 0, 0, time.UTC)
+	version := &config.NodeVersion{
+		Build:    &release.ReleaseSummary{Version: "v0.7.0", Commit: "commit1"},
+		Embedded: config.NodeImages{Supervisor: "supervisor-embedded", Updater: "updater-embedded"},
+		Running:  config.NodeImages{Supervisor: "supervisor1", Updater: "updater1"},
+		RunningRelease: &config.NodeRelease{
+			Version: "v0.7.0", Commit: "commit1", Timestamp: "2022-12-01T00:00:00Z", Channel: "stable",
+		},
+		LatestRelease: &config.NodeRelease{
+			Version: "v0.7.1", Commit: "commit2", Timestamp: "2022-12-02T00:00:00Z", Channel: "stable", SeenAt: &seenAt,
+		},
+		Containers: map[string]string{
+			config.DockerSupervisorContainerName: "sha256:1111",
+			config.DockerScannerContainerName:    "sha256:2222",
+		},
+		ReleaseChannel: "stable",
+		UpdatesEnabled: true,
+	}
+
+	var buf bytes.Buffer
+	formatNodeVersion(&buf, version, true)
+	r.Equal(`Runner build:            v0.7.0 (commit commit1)
+Embedded images:
+  supervisor:            supervisor-embedded
+  updater:               updater-embedded
+Release channel:         stable
+Running images:
+  supervisor:            supervisor1
+  updater:               updater1
+Running release:         v0.7.0 (commit commit1, channel stable, released at 2022-12-01T00:00:00Z)
+Latest release:          v0.7.1 (commit commit2, channel stable, released at 2022-12-02T00:00:00Z, seen at 2022-12-02T10:00:00Z)
+Containers:
+  forta-scanner:         sha256:2222
+  forta-supervisor:      sha256:1111
+`, buf.String())
+}
+
+func TestFormatNodeVersion_PartialData(t *testing.T) {
+	r := require.New(t)
+
+	// updates disabled
+	version := &config.NodeVersion{
+		Embedded:       config.NodeImages{Supervisor: "supervisor-embedded", Updater: "updater-embedded"},
+		Running:        config.NodeImages{Supervisor: "supervisor1"},
+		ReleaseChannel: "stable",
+	}
+	var buf bytes.Buffer
+	formatNodeVersion(&buf, version, true)
+	r.Equal(`Runner build:            development build
+Embedded images:
+  supervisor:            supervisor-embedded
+  updater:               updater-embedded
+Release channel:         stable
+Running images:
+  supervisor:            supervisor1
+  updater:               not running (updates are disabled)
+Running release:         -
+Latest release:          updates are disabled
+`, buf.String())
+
+	// the updater has not responded yet
+	version.UpdatesEnabled = true
+	version.Running.Updater = "updater1"
+	buf.Reset()
+	formatNodeVersion(&buf, version, true)
+	r.Contains(buf.String(), "Latest release:          not received from the updater yet\n")
+
+	// the node is not running
+	buf.Reset()
+	formatNodeVersion(&buf, &config.NodeVersion{
+		Build:    &release.ReleaseSummary{Commit: "commit1"},
+		Embedded: config.NodeImages{Supervisor: "supervisor-embedded"},
+	}, false)
+	r.Equal(`Runner build:            commit commit1
+Embedded images:
+  supervisor:            supervisor-embedded
+  updater:               -
+The node is not running: showing the build info only.
+`, buf.String())
+}
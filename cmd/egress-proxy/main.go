@@ -0,0 +1,27 @@
+package egress_proxy
+
+import (
+	"context"
+
+	"github.com/forta-network/forta-core-go/clients/health"
+	"github.com/forta-network/forta-node/config"
+	"github.com/forta-network/forta-node/healthutils"
+	"github.com/forta-network/forta-node/services"
+	egress_proxy "github.com/forta-network/forta-node/services/egress-proxy"
+)
+
+func initServices(ctx context.Context, cfg config.Config) ([]services.Service, error) {
+	proxy := egress_proxy.NewEgressProxy(ctx, cfg.Agent)
+
+	return []services.Service{
+		health.NewService(
+			ctx, "", healthutils.DefaultHealthServerErrHandler,
+			health.CheckerFrom(nil, proxy),
+		),
+		proxy,
+	}, nil
+}
+
+func Run() {
+	services.ContainerMain("egress-proxy", initServices)
+}
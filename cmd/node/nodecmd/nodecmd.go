@@ -1,6 +1,7 @@
 package nodecmd
 
 import (
+	egress_proxy "github.com/forta-network/forta-node/cmd/egress-proxy"
 	inspector "github.com/forta-network/forta-node/cmd/inspector"
 	json_rpc "github.com/forta-network/forta-node/cmd/json-rpc"
 	jwt_provider "github.com/forta-network/forta-node/cmd/jwt-provider"
@@ -77,6 +78,14 @@ var (
 		},
 	}
 
+	cmdEgressProxy = &cobra.Command{
+		Use: "egress-proxy",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			egress_proxy.Run()
+			return nil
+		},
+	}
+
 	cmdStorage = &cobra.Command{
 		Use: "storage",
 		RunE: func(cmd *cobra.Command, args []string) error {
@@ -95,6 +104,7 @@ func init() {
 	cmdFortaNode.AddCommand(cmdJsonRpc)
 	cmdFortaNode.AddCommand(cmdJWTProvider)
 	cmdFortaNode.AddCommand(cmdStorage)
+	cmdFortaNode.AddCommand(cmdEgressProxy)
 }
 
 func Run() error {
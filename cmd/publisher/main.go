@@ -18,6 +18,9 @@ func initServices(ctx context.Context, cfg config.Config) ([]services.Service, e
 	cfg.Publish.APIURL = utils.ConvertToDockerHostURL(cfg.Publish.APIURL)
 	cfg.Publish.IPFS.APIURL = utils.ConvertToDockerHostURL(cfg.Publish.IPFS.APIURL)
 	cfg.Publish.IPFS.GatewayURL = utils.ConvertToDockerHostURL(cfg.Publish.IPFS.GatewayURL)
+	for i, gatewayURL := range cfg.Publish.IPFS.GatewayURLs {
+		cfg.Publish.IPFS.GatewayURLs[i] = utils.ConvertToDockerHostURL(gatewayURL)
+	}
 	cfg.LocalModeConfig.WebhookURL = utils.ConvertToDockerHostURL(cfg.LocalModeConfig.WebhookURL)
 
 	p, err := publisher.NewPublisher(ctx, cfg)
@@ -6,6 +6,7 @@ import (
 	"os"
 
 	"github.com/forta-network/forta-node/clients"
+	"github.com/forta-network/forta-node/clients/ipfsgateway"
 	"github.com/forta-network/forta-node/config"
 	"github.com/forta-network/forta-node/services"
 	"github.com/forta-network/forta-node/services/runner"
@@ -13,17 +14,74 @@ import (
 	log "github.com/sirupsen/logrus"
 )
 
-func initServices(ctx context.Context, cfg config.Config) ([]services.Service, error) {
-	shouldDisableAutoUpdate := cfg.AutoUpdate.Disable
-	imgStore, err := store.NewFortaImageStore(ctx, config.DefaultContainerPort, !shouldDisableAutoUpdate)
+func initServices(ctx context.Context, cfg config.Config, scannerStatus store.ScannerStatusClient) ([]services.Service, error) {
+	if err := cfg.AssignPorts(ownHostPorts(ctx, cfg)); err != nil {
+		return nil, err
+	}
+	if err := cfg.SavePortMappings(); err != nil {
+		log.WithError(err).Warn("failed to save the port mappings")
+	}
+	if maxPulls := cfg.ResourcesConfig.MaxConcurrentPulls; maxPulls > 0 {
+		limiter, err := clients.NewFilePullLimiter(config.PullLocksDir(cfg.FortaDir), maxPulls)
+		if err != nil {
+			return nil, err
+		}
+		clients.SetPullLimiter(limiter)
+	}
+	// the updater is not started for the external supervisor
+	r, err := newRunner(ctx, cfg, !cfg.UpdatesDisabled() && !cfg.Supervisor.External)
 	if err != nil {
-		return nil, fmt.Errorf("failed to create the image store: %v", err)
+		return nil, err
 	}
-	dockerClient, err := clients.NewDockerClient("runner")
+	return []services.Service{r.WithScannerStatus(scannerStatus)}, nil
+}
+
+// ownHostPorts returns the host ports of the running containers of the node instance so that the
+// containers which are adopted from the previous run do not make the ports look busy.
+func ownHostPorts(ctx context.Context, cfg config.Config) map[string]bool {
+	dockerClient, err := clients.NewDockerClient("", clients.WithSocketPath(cfg.Docker.HostSocketPath()))
+	if err != nil {
+		log.WithError(err).Warn("failed to create the docker client to check the ports")
+		return nil
+	}
+	containers, err := dockerClient.GetContainers(ctx)
+	if err != nil {
+		log.WithError(err).Warn("failed to get the containers to check the ports")
+		return nil
+	}
+	return containers.RunningHostPorts()
+}
+
+func newRunner(ctx context.Context, cfg config.Config, trackReleases bool) (*runner.Runner, error) {
+	var imgStore store.FortaImageStore
+	if cfg.Offline {
+		imgStore = store.NewOfflineImageStore()
+	} else {
+		releaseClient, err := ipfsgateway.NewReleaseClient(cfg.Registry.IPFS, "")
+		if err != nil {
+			return nil, fmt.Errorf("failed to create the release client: %v", err)
+		}
+		imgStore, err = store.NewFortaImageStore(
+			ctx, cfg.RunnerConfig.UpdaterPort, trackReleases, cfg.AutoUpdate.ReleaseChannel(),
+			cfg.AutoUpdate.CheckInterval(), store.NewReleaseNotesSource(releaseClient),
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create the image store: %v", err)
+		}
+	}
+	registryAuth, err := clients.NewRegistryAuthProvider(cfg.Registry)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create the registry auth provider: %v", err)
+	}
+	socketPath := cfg.Docker.HostSocketPath()
+	if err := clients.CheckDockerSocket(socketPath); err != nil {
+		return nil, err
+	}
+	dockerClient, err := clients.NewDockerClientWithAuth("runner", registryAuth, clients.WithSocketPath(socketPath))
 	if err != nil {
 		return nil, fmt.Errorf("failed to create the docker client: %v", err)
 	}
-	globalDockerClient, err := clients.NewDockerClient("")
+	globalDockerClient, err := clients.NewDockerClient("", clients.WithSocketPath(socketPath))
 	if err != nil {
 		return nil, fmt.Errorf("failed to create the docker client: %v", err)
 	}
@@ -32,13 +90,25 @@ func initServices(ctx context.Context, cfg config.Config) ([]services.Service, e
 		log.Warn("running in development mode")
 	}
 
-	return []services.Service{
-		runner.NewRunner(ctx, cfg, imgStore, dockerClient, globalDockerClient),
-	}, nil
+	return runner.NewRunner(ctx, cfg, imgStore, dockerClient, globalDockerClient).WithRegistryAuth(registryAuth), nil
+}
+
+// DryRun checks if the node is ready to run without starting any containers. The scanner is not
+// checked in the registry if skipRegistryCheck is true.
+func DryRun(cfg config.Config, skipRegistryCheck bool) (*runner.DryRunReport, error) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	// the updater is not running so no need to track the releases
+	r, err := newRunner(ctx, cfg, false)
+	if err != nil {
+		return nil, err
+	}
+	return r.WithoutRegistryCheck(skipRegistryCheck).DryRun(), nil
 }
 
-// Run runs the runner.
-func Run(cfg config.Config) {
+// Run runs the runner. The scanner status client from the pre-run checks is reused if not nil.
+func Run(cfg config.Config, scannerStatus store.ScannerStatusClient) {
 	ctx, cancel := services.InitMainContext()
 	defer cancel()
 
@@ -46,10 +116,10 @@ func Run(cfg config.Config) {
 	logger.Info("starting")
 	defer logger.Info("exiting")
 
-	serviceList, err := initServices(ctx, cfg)
+	serviceList, err := initServices(ctx, cfg, scannerStatus)
 	if err != nil {
 		logger.WithError(err).Error("could not initialize services")
-		return
+		os.Exit(1)
 	}
 
 	err = services.StartServices(ctx, cancel, log.NewEntry(log.StandardLogger()), serviceList)
@@ -59,5 +129,6 @@ func Run(cfg config.Config) {
 	}
 	if err != nil {
 		logger.WithError(err).Error("error running services")
+		os.Exit(1)
 	}
 }
@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"github.com/forta-network/forta-node/store"
 	"math/big"
+	"os"
 	"strconv"
 	"strings"
 	"time"
@@ -23,6 +24,7 @@ import (
 	"github.com/forta-network/forta-core-go/security"
 	"github.com/forta-network/forta-core-go/utils"
 	"github.com/forta-network/forta-node/clients"
+	"github.com/forta-network/forta-node/clients/ethclient"
 	"github.com/forta-network/forta-node/clients/messaging"
 	"github.com/forta-network/forta-node/config"
 	"github.com/forta-network/forta-node/healthutils"
@@ -49,9 +51,18 @@ func initTxStream(ctx context.Context, ethClient, traceClient ethereum.Client, c
 		return nil, nil, fmt.Errorf("trace requires a jsonRpc URL if enabled")
 	}
 
+	// the supervisor passes the validated poll interval
+	if pollIntervalStr := os.Getenv(config.EnvBlockPollInterval); len(pollIntervalStr) > 0 {
+		pollInterval, err := time.ParseDuration(pollIntervalStr)
+		if err != nil {
+			return nil, nil, fmt.Errorf("invalid block poll interval '%s': %v", pollIntervalStr, err)
+		}
+		cfg.Scan.BlockPollInterval = pollInterval
+	}
+
 	var rateLimit *time.Ticker
-	if cfg.Scan.BlockRateLimit > 0 {
-		rateLimit = time.NewTicker(time.Duration(cfg.Scan.BlockRateLimit) * time.Millisecond)
+	if pollInterval := cfg.Scan.PollInterval(); pollInterval > 0 {
+		rateLimit = time.NewTicker(pollInterval)
 	}
 
 	var maxAgePtr *time.Duration
@@ -160,8 +171,9 @@ func initCombinationStream(ctx context.Context, msgClient *messaging.Client, cfg
 
 	combinerStream, err := scanner.NewCombinerAlertStreamService(
 		ctx, combinerFeed, msgClient, scanner.CombinerAlertStreamServiceConfig{
-			Start: cfg.LocalModeConfig.RuntimeLimits.StartCombiner,
-			End:   cfg.LocalModeConfig.RuntimeLimits.StopCombiner,
+			Start:   cfg.LocalModeConfig.RuntimeLimits.StartCombiner,
+			End:     cfg.LocalModeConfig.RuntimeLimits.StopCombiner,
+			Offline: cfg.OfflineSkip("combiner-alert-api"),
 		},
 	)
 	if err != nil {
@@ -241,9 +253,38 @@ func initAlertSender(ctx context.Context, key *keystore.Key, pubClient clients.P
 	})
 }
 
+// initTraceClient creates the trace client only if tracing is enabled so that a disabled
+// trace config never reaches the trace API.
+func initTraceClient(ctx context.Context, cfg config.Config) (ethereum.Client, error) {
+	if !cfg.Trace.Enabled {
+		return nil, nil
+	}
+	return ethclient.NewStreamEthClient(ctx, "trace", cfg.Trace.JsonRpc)
+}
+
+// initChainClient creates the chain client. If the archive API is configured, the chain client
+// sends the requests for the old blocks to the archive client which is also returned.
+func initChainClient(ctx context.Context, cfg config.Config) (ethClient, archiveClient ethereum.Client, err error) {
+	ethClient, err = ethclient.NewStreamEthClient(ctx, "chain", cfg.Scan.JsonRpc)
+	if err != nil || !cfg.Scan.ArchiveEnabled() {
+		return
+	}
+	archiveClient, err = ethclient.NewStreamEthClient(ctx, "archive", cfg.Scan.ArchiveJsonRpc)
+	if err != nil {
+		return nil, nil, err
+	}
+	return ethclient.WithArchive(ctx, ethClient, archiveClient, cfg.Scan.ArchiveBlockDepth), archiveClient, nil
+}
+
 func initServices(ctx context.Context, cfg config.Config) ([]services.Service, error) {
+	// the supervisor passes the trace gate
+	if traceEnabledStr := os.Getenv(config.EnvTraceEnabled); len(traceEnabledStr) > 0 {
+		cfg.Trace.Enabled = utils.ParseBoolEnvVar(config.EnvTraceEnabled)
+	}
+
 	// can't dial localhost - need to dial host gateway from container
 	cfg.Scan.JsonRpc.Url = utils.ConvertToDockerHostURL(cfg.Scan.JsonRpc.Url)
+	cfg.Scan.ArchiveJsonRpc.Url = utils.ConvertToDockerHostURL(cfg.Scan.ArchiveJsonRpc.Url)
 	cfg.Trace.JsonRpc.Url = utils.ConvertToDockerHostURL(cfg.Trace.JsonRpc.Url)
 	cfg.Registry.JsonRpc.Url = utils.ConvertToDockerHostURL(cfg.Registry.JsonRpc.Url)
 	cfg.Registry.IPFS.APIURL = utils.ConvertToDockerHostURL(cfg.Registry.IPFS.APIURL)
@@ -251,8 +292,11 @@ func initServices(ctx context.Context, cfg config.Config) ([]services.Service, e
 	cfg.Publish.APIURL = utils.ConvertToDockerHostURL(cfg.Publish.APIURL)
 	cfg.Publish.IPFS.APIURL = utils.ConvertToDockerHostURL(cfg.Publish.IPFS.APIURL)
 	cfg.Publish.IPFS.GatewayURL = utils.ConvertToDockerHostURL(cfg.Publish.IPFS.GatewayURL)
+	for i, gatewayURL := range cfg.Publish.IPFS.GatewayURLs {
+		cfg.Publish.IPFS.GatewayURLs[i] = utils.ConvertToDockerHostURL(gatewayURL)
+	}
 	cfg.LocalModeConfig.WebhookURL = utils.ConvertToDockerHostURL(cfg.LocalModeConfig.WebhookURL)
-	msgClient := messaging.NewClient("scanner", fmt.Sprintf("%s:%s", config.DockerNatsContainerName, config.DefaultNatsPort))
+	msgClient := messaging.NewClient("scanner", cfg.Nats)
 
 	key, err := security.LoadKey(config.DefaultContainerKeyDirPath)
 	if err != nil {
@@ -269,12 +313,12 @@ func initServices(ctx context.Context, cfg config.Config) ([]services.Service, e
 		return nil, err
 	}
 
-	ethClient, err := ethereum.NewStreamEthClient(ctx, "chain", cfg.Scan.JsonRpc.Url)
+	ethClient, archiveClient, err := initChainClient(ctx, cfg)
 	if err != nil {
 		return nil, err
 	}
 
-	traceClient, err := ethereum.NewStreamEthClient(ctx, "trace", cfg.Trace.JsonRpc.Url)
+	traceClient, err := initTraceClient(ctx, cfg)
 	if err != nil {
 		return nil, err
 	}
@@ -289,7 +333,7 @@ func initServices(ctx context.Context, cfg config.Config) ([]services.Service, e
 		return nil, err
 	}
 
-	registryClient, err := ethereum.NewStreamEthClient(ctx, "registry", cfg.Registry.JsonRpc.Url)
+	registryClient, err := ethclient.NewStreamEthClient(ctx, "registry", cfg.Registry.JsonRpc)
 	if err != nil {
 		return nil, err
 	}
@@ -300,7 +344,15 @@ func initServices(ctx context.Context, cfg config.Config) ([]services.Service, e
 		waitBots = len(cfg.LocalModeConfig.BotImages)
 	}
 
-	agentPool := agentpool.NewAgentPool(ctx, cfg.Scan, msgClient, waitBots)
+	agentPool := agentpool.NewAgentPool(ctx, cfg.Scan, cfg.Agent, msgClient, waitBots).
+		WithOneOffRuns(func(ctx context.Context, startBlock, stopBlock uint64) (feeds.BlockFeed, error) {
+			return feeds.NewBlockFeed(ctx, ethClient, traceClient, feeds.BlockFeedConfig{
+				ChainID: config.ParseBigInt(cfg.ChainID),
+				Tracing: cfg.Trace.Enabled,
+				Start:   new(big.Int).SetUint64(startBlock),
+				End:     new(big.Int).SetUint64(stopBlock),
+			})
+		})
 	txAnalyzer, err := initTxAnalyzer(ctx, cfg, as, txStream, agentPool, msgClient)
 	if err != nil {
 		return nil, err
@@ -320,11 +372,21 @@ func initServices(ctx context.Context, cfg config.Config) ([]services.Service, e
 		blockFeed.Start()
 	}
 
+	reporters := []health.Reporter{ethClient}
+	if archiveClient != nil {
+		reporters = append(reporters, archiveClient)
+	}
+	if traceClient != nil {
+		reporters = append(reporters, traceClient)
+	}
+	reporters = append(reporters,
+		combinationFeed, blockFeed, txStream, txAnalyzer, blockAnalyzer, combinationAnalyzer, agentPool, registryService,
+		publisherSvc, msgClient,
+	)
+
 	svcs := []services.Service{
 		health.NewService(ctx, "", healthutils.DefaultHealthServerErrHandler, health.CheckerFrom(
-			summarizeReports,
-			ethClient, traceClient, combinationFeed, blockFeed, txStream, txAnalyzer, blockAnalyzer, combinationAnalyzer, agentPool, registryService,
-			publisherSvc,
+			summarizeReports, reporters...,
 		)),
 		txStream,
 		txAnalyzer,
@@ -334,6 +396,7 @@ func initServices(ctx context.Context, cfg config.Config) ([]services.Service, e
 		scanner.NewScannerAPI(ctx, blockFeed),
 		scanner.NewTxLogger(ctx),
 		publisherSvc,
+		scanner.NewDrainService(ctx, msgClient, txStream, agentPool, publisherSvc),
 	}
 
 	// for performance tests, this flag avoids using registry service
@@ -0,0 +1,38 @@
+package scanner
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+
+	"github.com/forta-network/forta-node/config"
+	"github.com/stretchr/testify/require"
+)
+
+func TestInitTraceClient(t *testing.T) {
+	r := require.New(t)
+
+	var calls int64
+	traceServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		atomic.AddInt64(&calls, 1)
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"jsonrpc":"2.0","id":1,"result":[]}`))
+	}))
+	defer traceServer.Close()
+
+	var cfg config.Config
+	cfg.Trace.JsonRpc.Url = traceServer.URL
+
+	traceClient, err := initTraceClient(context.Background(), cfg)
+	r.NoError(err)
+	r.Nil(traceClient)
+
+	cfg.Trace.Enabled = true
+	traceClient, err = initTraceClient(context.Background(), cfg)
+	r.NoError(err)
+	r.NotNil(traceClient)
+
+	r.Zero(atomic.LoadInt64(&calls))
+}
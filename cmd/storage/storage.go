@@ -12,9 +12,12 @@ import (
 )
 
 func initServices(ctx context.Context, cfg config.Config) ([]services.Service, error) {
+	routerURL := cfg.StorageConfig.Provide
+	if cfg.OfflineSkip("ipfs-router") {
+		routerURL = ""
+	}
 	service, err := storage.NewStorage(
-		ctx, fmt.Sprintf("http://%s:5001", config.DockerIpfsContainerName),
-		cfg.StorageConfig.Provide,
+		ctx, fmt.Sprintf("http://%s:5001", config.DockerIpfsContainerName), routerURL,
 	)
 	if err != nil {
 		return nil, err
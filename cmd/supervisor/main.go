@@ -7,6 +7,7 @@ import (
 	"github.com/forta-network/forta-core-go/clients/health"
 	"github.com/forta-network/forta-core-go/security"
 	"github.com/forta-network/forta-core-go/utils"
+	"github.com/forta-network/forta-node/clients"
 	"github.com/forta-network/forta-node/config"
 	"github.com/forta-network/forta-node/healthutils"
 	"github.com/forta-network/forta-node/services"
@@ -17,6 +18,17 @@ func initServices(ctx context.Context, cfg config.Config) ([]services.Service, e
 	cfg.Registry.JsonRpc.Url = utils.ConvertToDockerHostURL(cfg.Registry.JsonRpc.Url)
 	cfg.Registry.IPFS.APIURL = utils.ConvertToDockerHostURL(cfg.Registry.IPFS.APIURL)
 	cfg.Registry.IPFS.GatewayURL = utils.ConvertToDockerHostURL(cfg.Registry.IPFS.GatewayURL)
+	for i, gatewayURL := range cfg.Registry.IPFS.GatewayURLs {
+		cfg.Registry.IPFS.GatewayURLs[i] = utils.ConvertToDockerHostURL(gatewayURL)
+	}
+
+	if maxPulls := cfg.ResourcesConfig.MaxConcurrentPulls; maxPulls > 0 {
+		limiter, err := clients.NewFilePullLimiter(config.PullLocksDir(config.DefaultContainerFortaDirPath), maxPulls)
+		if err != nil {
+			return nil, err
+		}
+		clients.SetPullLimiter(limiter)
+	}
 
 	passphrase, err := security.ReadPassphrase()
 	if err != nil {
@@ -35,35 +47,41 @@ func initServices(ctx context.Context, cfg config.Config) ([]services.Service, e
 		return nil, err
 	}
 	return []services.Service{
-		health.NewService(
+		healthutils.NewHealthService(
 			ctx, "", healthutils.DefaultHealthServerErrHandler,
-			health.CheckerFrom(summarizeReports, svc),
-		),
+			health.CheckerFrom(summarizeReports(cfg.SupervisorManagedContainers()), svc), svc.ReadinessChecks()...,
+		).WithDrainer(svc, supervisor.AdminToken).
+			WithAgentRetrier(svc, supervisor.AdminToken).
+			WithAgentResetter(svc, supervisor.AdminToken).
+			WithAgentDisabler(svc, supervisor.AdminToken).
+			WithAgentRunner(svc, supervisor.AdminToken),
 		svc,
 	}, nil
 }
 
-func summarizeReports(reports health.Reports) *health.Report {
-	summary := health.NewSummary()
+func summarizeReports(managedContainers int) func(reports health.Reports) *health.Report {
+	return func(reports health.Reports) *health.Report {
+		summary := health.NewSummary()
 
-	containersManager, ok := reports.NameContains("containers.managed")
-	if ok {
-		count, _ := strconv.Atoi(containersManager.Details)
-		if count < config.DockerSupervisorManagedContainers {
-			summary.Addf("missing %d containers.", config.DockerSupervisorManagedContainers-count)
-			summary.Status(health.StatusFailing)
-		} else {
-			summary.Addf("all %d service containers are running.", config.DockerSupervisorManagedContainers)
+		containersManager, ok := reports.NameContains("containers.managed")
+		if ok {
+			count, _ := strconv.Atoi(containersManager.Details)
+			if count < managedContainers {
+				summary.Addf("missing %d containers.", managedContainers-count)
+				summary.Status(health.StatusFailing)
+			} else {
+				summary.Addf("all %d service containers are running.", managedContainers)
+			}
 		}
-	}
 
-	telemetryErr, ok := reports.NameContains("telemetry-sync.error")
-	if ok && len(telemetryErr.Details) > 0 {
-		summary.Addf("telemetry sync is failing with error '%s' (non-critical).", telemetryErr.Details)
-		// do not change status - non critical
-	}
+		telemetryErr, ok := reports.NameContains("telemetry-sync.error")
+		if ok && len(telemetryErr.Details) > 0 {
+			summary.Addf("telemetry sync is failing with error '%s' (non-critical).", telemetryErr.Details)
+			// do not change status - non critical
+		}
 
-	return summary.Finish()
+		return summary.Finish()
+	}
 }
 
 func Run() {
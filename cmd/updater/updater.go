@@ -7,14 +7,15 @@ import (
 	"fmt"
 	"io/ioutil"
 	"math/big"
+	"os"
 	"path"
 	"time"
 
 	"github.com/forta-network/forta-core-go/registry"
-	"github.com/forta-network/forta-core-go/release"
 
 	"github.com/forta-network/forta-core-go/clients/health"
 	"github.com/forta-network/forta-core-go/utils"
+	"github.com/forta-network/forta-node/clients/ipfsgateway"
 	"github.com/forta-network/forta-node/config"
 	"github.com/forta-network/forta-node/healthutils"
 	"github.com/forta-network/forta-node/services"
@@ -63,8 +64,13 @@ func initServices(ctx context.Context, cfg config.Config) ([]services.Service, e
 	cfg.Registry.JsonRpc.Url = utils.ConvertToDockerHostURL(cfg.Registry.JsonRpc.Url)
 	cfg.Registry.IPFS.APIURL = utils.ConvertToDockerHostURL(cfg.Registry.IPFS.APIURL)
 	cfg.Registry.IPFS.GatewayURL = utils.ConvertToDockerHostURL(cfg.Registry.IPFS.GatewayURL)
+	for i, gatewayURL := range cfg.Registry.IPFS.GatewayURLs {
+		cfg.Registry.IPFS.GatewayURLs[i] = utils.ConvertToDockerHostURL(gatewayURL)
+	}
 
-	releaseClient, err := release.NewClient(cfg.Registry.IPFS.GatewayURL)
+	releaseClient, err := ipfsgateway.NewReleaseClient(
+		cfg.Registry.IPFS, path.Join(config.DefaultContainerFortaDirPath, config.DefaultUpdaterReleaseCacheFileName),
+	)
 	if err != nil {
 		return nil, err
 	}
@@ -79,8 +85,15 @@ func initServices(ctx context.Context, cfg config.Config) ([]services.Service, e
 
 	developmentMode := utils.ParseBoolEnvVar(config.EnvDevelopment)
 
+	// the runner passes the channel so that the channel changes are applied without a restart
+	releaseChannel := cfg.AutoUpdate.ReleaseChannel()
+	if envChannel := os.Getenv(config.EnvReleaseChannel); len(envChannel) > 0 {
+		releaseChannel = envChannel
+	}
+
 	log.WithFields(log.Fields{
 		"developmentMode": developmentMode,
+		"releaseChannel":  releaseChannel,
 	}).Info("updater modes")
 
 	address, err := loadAddressFromKeyFile()
@@ -96,13 +109,13 @@ func initServices(ctx context.Context, cfg config.Config) ([]services.Service, e
 
 	updaterService := updater.NewUpdaterService(
 		ctx, registryClient, releaseClient, config.DefaultContainerPort,
-		developmentMode, cfg.AutoUpdate.TrackPrereleases, updateDelay, 0,
+		developmentMode, releaseChannel, updateDelay, 0,
 	)
 
 	return []services.Service{
 		health.NewService(
 			ctx, "", healthutils.DefaultHealthServerErrHandler,
-			health.CheckerFrom(summarizeReports, updaterService),
+			health.CheckerFrom(summarizeReports, updaterService, releaseClient),
 		),
 		updaterService,
 	}, nil
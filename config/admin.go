@@ -0,0 +1,43 @@
+package config
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"os"
+	"path"
+	"strings"
+)
+
+// ReadAdminToken reads the runner admin API token from the Forta dir.
+func ReadAdminToken(fortaDir string) (string, error) {
+	b, err := os.ReadFile(path.Join(fortaDir, DefaultAdminTokenFileName))
+	if err != nil {
+		return "", err
+	}
+	token := strings.TrimSpace(string(b))
+	if len(token) == 0 {
+		return "", errors.New("admin token is empty")
+	}
+	return token, nil
+}
+
+// EnsureAdminToken reads the runner admin API token from the Forta dir and generates one
+// if it does not exist yet.
+func EnsureAdminToken(fortaDir string) (string, error) {
+	token, err := ReadAdminToken(fortaDir)
+	if !errors.Is(err, os.ErrNotExist) {
+		return token, err
+	}
+
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("failed to generate the admin token: %v", err)
+	}
+	token = hex.EncodeToString(b)
+	if err := os.WriteFile(path.Join(fortaDir, DefaultAdminTokenFileName), []byte(token), 0600); err != nil {
+		return "", fmt.Errorf("failed to write the admin token: %v", err)
+	}
+	return token, nil
+}
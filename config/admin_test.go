@@ -0,0 +1,32 @@
+package config
+
+import (
+	"os"
+	"path"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestEnsureAdminToken(t *testing.T) {
+	r := require.New(t)
+
+	dir := t.TempDir()
+	_, err := ReadAdminToken(dir)
+	r.ErrorIs(err, os.ErrNotExist)
+
+	token, err := EnsureAdminToken(dir)
+	r.NoError(err)
+	r.Len(token, 64)
+	info, err := os.Stat(path.Join(dir, DefaultAdminTokenFileName))
+	r.NoError(err)
+	r.Equal(os.FileMode(0600), info.Mode().Perm())
+
+	// generated only once
+	token2, err := EnsureAdminToken(dir)
+	r.NoError(err)
+	r.Equal(token, token2)
+	token2, err = ReadAdminToken(dir)
+	r.NoError(err)
+	r.Equal(token, token2)
+}
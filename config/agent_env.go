@@ -0,0 +1,77 @@
+package config
+
+import (
+	"os"
+	"regexp"
+	"sort"
+
+	log "github.com/sirupsen/logrus"
+)
+
+var envNameRegexp = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// SetAgentEnv adds the env vars from the config to the agent config.
+func (cfg *Config) SetAgentEnv(agent *AgentConfig) {
+	for _, agentEnv := range cfg.Agent.Env {
+		if agentEnv.AgentID != agent.ID {
+			continue
+		}
+		env := make(map[string]string)
+		for key, value := range agent.Env {
+			env[key] = value
+		}
+		for key, value := range agentEnv.Vars {
+			env[key] = value
+		}
+		agent.Env = env
+	}
+}
+
+// AgentEnvRefs returns the env vars which are referenced by the agent env vars in the config.
+func (cfg *Config) AgentEnvRefs() (refs []string) {
+	seen := make(map[string]bool)
+	for _, agentEnv := range cfg.Agent.Env {
+		for _, value := range agentEnv.Vars {
+			os.Expand(value, func(name string) string {
+				if !seen[name] {
+					seen[name] = true
+					refs = append(refs, name)
+				}
+				return ""
+			})
+		}
+	}
+	sort.Strings(refs)
+	return
+}
+
+// ExpandAgentEnv expands the ${VAR} references in the agent env vars from the env of the node.
+func ExpandAgentEnv(env map[string]string) map[string]string {
+	expanded := make(map[string]string)
+	for key, value := range env {
+		expanded[key] = os.Expand(value, func(name string) string {
+			v, ok := os.LookupEnv(name)
+			if !ok {
+				log.WithFields(log.Fields{
+					"key": key,
+					"ref": name,
+				}).Warn("agent env var references an unset env var")
+			}
+			return v
+		})
+	}
+	return expanded
+}
+
+// AddAgentEnvRefs adds the env vars which are referenced by the agent env vars to the container
+// env so that they can be expanded in the container. The existing keys are kept.
+func (cfg *Config) AddAgentEnvRefs(env map[string]string) map[string]string {
+	for _, name := range cfg.AgentEnvRefs() {
+		value, ok := os.LookupEnv(name)
+		if _, exists := env[name]; !ok || exists {
+			continue
+		}
+		env[name] = value
+	}
+	return env
+}
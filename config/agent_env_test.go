@@ -0,0 +1,48 @@
+package config
+
+import (
+	"testing"
+
+	"github.com/creasty/defaults"
+	"github.com/go-playground/validator/v10"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAgentEnv(t *testing.T) {
+	r := require.New(t)
+	t.Setenv("TEST_API_KEY", "key1")
+
+	var cfg Config
+	cfg.Agent.Env = []AgentEnvConfig{
+		{AgentID: "agent-1", Vars: map[string]string{"API_KEY": "${TEST_API_KEY}", "FLAG": "on", "URL": "$TEST_URL/v1"}},
+		{AgentID: "agent-2", Vars: map[string]string{"FLAG": "off"}},
+	}
+	r.Equal([]string{"TEST_API_KEY", "TEST_URL"}, cfg.AgentEnvRefs())
+
+	agent := AgentConfig{ID: "agent-1", Env: map[string]string{"OTHER": "1", "FLAG": "off"}}
+	cfg.SetAgentEnv(&agent)
+	r.Equal(map[string]string{"OTHER": "1", "FLAG": "on", "API_KEY": "${TEST_API_KEY}", "URL": "$TEST_URL/v1"}, agent.Env)
+	r.Equal(map[string]string{"OTHER": "1", "FLAG": "on", "API_KEY": "key1", "URL": "/v1"}, ExpandAgentEnv(agent.Env))
+
+	// only the set vars are added and the existing keys are kept
+	env := cfg.AddAgentEnvRefs(map[string]string{"TEST_API_KEY": "key2"})
+	r.Equal(map[string]string{"TEST_API_KEY": "key2"}, env)
+	env = cfg.AddAgentEnvRefs(map[string]string{})
+	r.Equal(map[string]string{"TEST_API_KEY": "key1"}, env)
+}
+
+func TestAgentEnv_Validate(t *testing.T) {
+	r := require.New(t)
+
+	var cfg Config
+	r.NoError(defaults.Set(&cfg))
+	cfg.ApplyEnvDefaults()
+	cfg.Agent.Env = []AgentEnvConfig{{AgentID: "agent-1", Vars: map[string]string{"API_KEY_1": "1", "_FLAG": "on"}}}
+	r.NoError(cfg.Validate())
+
+	for _, key := range []string{"1KEY", "API-KEY", "API KEY", ""} {
+		cfg.Agent.Env = []AgentEnvConfig{{AgentID: "agent-1", Vars: map[string]string{key: "1"}}}
+		var validationErrs validator.ValidationErrors
+		r.ErrorAs(cfg.Validate(), &validationErrs, key)
+	}
+}
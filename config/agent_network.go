@@ -0,0 +1,68 @@
+package config
+
+import (
+	"fmt"
+	"net"
+	"strings"
+)
+
+// Agent network policies
+const (
+	AgentNetworkPolicyOpen     = "open"
+	AgentNetworkPolicyIsolated = "isolated"
+)
+
+// Isolated tells if the agents should be attached only to the internal networks.
+func (cfg AgentRuntimeConfig) Isolated() bool {
+	return cfg.NetworkPolicy == AgentNetworkPolicyIsolated
+}
+
+// EgressProxyEnabled tells if the isolated agents can reach the allowlisted hosts through the
+// egress proxy.
+func (cfg AgentRuntimeConfig) EgressProxyEnabled() bool {
+	return cfg.Isolated() && len(cfg.EgressAllowlist) > 0
+}
+
+// EgressAllowed tells if the host and the port are in the egress allowlist. An allowlisted
+// host without a port is allowed only on the default port of the request.
+func (cfg AgentRuntimeConfig) EgressAllowed(host, port, defaultPort string) bool {
+	for _, allowed := range cfg.EgressAllowlist {
+		allowedHost, allowedPort, err := net.SplitHostPort(allowed)
+		if err != nil {
+			allowedHost, allowedPort = allowed, defaultPort
+		}
+		if strings.EqualFold(allowedHost, host) && allowedPort == port {
+			return true
+		}
+	}
+	return false
+}
+
+// NetworkPolicyString describes the active agent network policy.
+func (cfg AgentRuntimeConfig) NetworkPolicyString() string {
+	switch {
+	case cfg.EgressProxyEnabled():
+		return fmt.Sprintf("%s (egress allowlist: %s)", AgentNetworkPolicyIsolated, strings.Join(cfg.EgressAllowlist, ", "))
+	case cfg.Isolated():
+		return fmt.Sprintf("%s (no egress)", AgentNetworkPolicyIsolated)
+	default:
+		return AgentNetworkPolicyOpen
+	}
+}
+
+// EgressProxyEnv returns the proxy env vars which make the agents send the outbound requests to
+// the egress proxy. The node services on the agent network are reached directly.
+func EgressProxyEnv() map[string]string {
+	proxyURL := fmt.Sprintf("http://%s:%s", DockerEgressProxyContainerName, DefaultEgressProxyPort)
+	noProxy := strings.Join([]string{DockerJSONRPCProxyContainerName, DockerJWTProviderContainerName}, ",")
+	env := make(map[string]string)
+	for key, value := range map[string]string{
+		envHTTPProxy:  proxyURL,
+		envHTTPSProxy: proxyURL,
+		envNoProxy:    noProxy,
+	} {
+		env[key] = value
+		env[strings.ToLower(key)] = value
+	}
+	return env
+}
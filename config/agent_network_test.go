@@ -0,0 +1,53 @@
+package config
+
+import (
+	"testing"
+
+	"github.com/creasty/defaults"
+	"github.com/go-playground/validator/v10"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAgentNetworkPolicy(t *testing.T) {
+	r := require.New(t)
+
+	var cfg Config
+	r.NoError(defaults.Set(&cfg))
+	cfg.ApplyEnvDefaults()
+	r.NoError(cfg.Validate())
+	r.False(cfg.Agent.Isolated())
+	r.Equal(AgentNetworkPolicyOpen, cfg.Agent.NetworkPolicyString())
+
+	cfg.Agent.NetworkPolicy = AgentNetworkPolicyIsolated
+	r.NoError(cfg.Validate())
+	r.True(cfg.Agent.Isolated())
+	r.False(cfg.Agent.EgressProxyEnabled())
+	r.Equal("isolated (no egress)", cfg.Agent.NetworkPolicyString())
+
+	cfg.Agent.EgressAllowlist = []string{"api.example.com", "ipfs.io:8443"}
+	r.NoError(cfg.Validate())
+	r.True(cfg.Agent.EgressProxyEnabled())
+	r.True(cfg.Agent.EgressAllowed("API.example.com", "443", "443"))
+	r.True(cfg.Agent.EgressAllowed("api.example.com", "80", "80"))
+	r.False(cfg.Agent.EgressAllowed("api.example.com", "22", "443"))
+	r.False(cfg.Agent.EgressAllowed("example.com", "443", "443"))
+	r.True(cfg.Agent.EgressAllowed("ipfs.io", "8443", "443"))
+	r.False(cfg.Agent.EgressAllowed("ipfs.io", "443", "443"))
+	r.Equal("isolated (egress allowlist: api.example.com, ipfs.io:8443)", cfg.Agent.NetworkPolicyString())
+
+	var validationErrs validator.ValidationErrors
+	cfg.Agent.EgressAllowlist = []string{"http://api.example.com"}
+	r.ErrorAs(cfg.Validate(), &validationErrs)
+	cfg.Agent.EgressAllowlist = nil
+	cfg.Agent.NetworkPolicy = "closed"
+	r.ErrorAs(cfg.Validate(), &validationErrs)
+}
+
+func TestEgressProxyEnv(t *testing.T) {
+	r := require.New(t)
+
+	env := EgressProxyEnv()
+	r.Equal("http://forta-egress-proxy:3128", env["HTTPS_PROXY"])
+	r.Equal(env["HTTPS_PROXY"], env["http_proxy"])
+	r.Equal("forta-json-rpc,forta-jwt-provider", env["NO_PROXY"])
+}
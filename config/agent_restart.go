@@ -0,0 +1,28 @@
+package config
+
+// AgentRestart returns the restart behavior of the agent. The individual values from the config
+// override the agent.maxRestarts, agent.restartBackoff and agent.restartMaxBackoff values.
+func (cfg AgentRuntimeConfig) AgentRestart(agentID string) AgentRestartConfig {
+	restart := AgentRestartConfig{
+		AgentID:           agentID,
+		MaxRestarts:       cfg.MaxRestarts,
+		RestartBackoff:    cfg.RestartBackoff,
+		RestartMaxBackoff: cfg.RestartMaxBackoff,
+	}
+	for _, agentRestart := range cfg.Restarts {
+		if agentRestart.AgentID != agentID {
+			continue
+		}
+		if agentRestart.MaxRestarts > 0 {
+			restart.MaxRestarts = agentRestart.MaxRestarts
+		}
+		if agentRestart.RestartBackoff > 0 {
+			restart.RestartBackoff = agentRestart.RestartBackoff
+		}
+		if agentRestart.RestartMaxBackoff > 0 {
+			restart.RestartMaxBackoff = agentRestart.RestartMaxBackoff
+		}
+		break
+	}
+	return restart
+}
@@ -0,0 +1,33 @@
+package config
+
+import (
+	"testing"
+	"time"
+
+	"github.com/creasty/defaults"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAgentRestart(t *testing.T) {
+	r := require.New(t)
+
+	var cfg AgentRuntimeConfig
+	r.NoError(defaults.Set(&cfg))
+	cfg.MaxRestarts = 20
+	cfg.Restarts = []AgentRestartConfig{
+		{AgentID: "agent-1", MaxRestarts: 3, RestartBackoff: time.Second * 10},
+	}
+
+	r.Equal(AgentRestartConfig{
+		AgentID:           "agent-1",
+		MaxRestarts:       3,
+		RestartBackoff:    time.Second * 10,
+		RestartMaxBackoff: time.Minute * 5,
+	}, cfg.AgentRestart("agent-1"))
+	r.Equal(AgentRestartConfig{
+		AgentID:           "agent-2",
+		MaxRestarts:       20,
+		RestartBackoff:    time.Second,
+		RestartMaxBackoff: time.Minute * 5,
+	}, cfg.AgentRestart("agent-2"))
+}
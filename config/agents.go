@@ -2,6 +2,8 @@ package config
 
 import (
 	"fmt"
+	"strconv"
+	"strings"
 
 	"github.com/forta-network/forta-core-go/protocol"
 	"github.com/forta-network/forta-core-go/utils"
@@ -19,6 +21,26 @@ type AgentConfig struct {
 	StartBlock  *uint64 `yaml:"startBlock" json:"startBlock,omitempty"`
 	StopBlock   *uint64 `yaml:"stopBlock" json:"stopBlock,omitempty"`
 	AlertConfig *protocol.AlertConfig
+
+	// AssignedGrpcPort is the port the agent serves the gRPC API from. The default port
+	// is used if it is not assigned.
+	AssignedGrpcPort int `yaml:"grpcPort" json:"grpcPort,omitempty"`
+
+	// CPULimit is the max number of CPUs and MemoryLimit is the max memory in MiB for the agent
+	// container. The node-wide limits are used if they are not set.
+	CPULimit    float64 `yaml:"cpuLimit" json:"cpuLimit,omitempty"`
+	MemoryLimit int     `yaml:"memoryLimit" json:"memoryLimit,omitempty"`
+
+	// Env is added to the env of the agent container.
+	Env map[string]string `yaml:"env" json:"env,omitempty"`
+
+	// Priority orders the agents when not all of them can run at the same time. The agents with
+	// higher priorities start first and the rest start in the assignment order.
+	Priority int `yaml:"priority" json:"priority,omitempty"`
+
+	// RunID is set for the one-off runs of the agent over a block range. The one-off runs are
+	// separate from the steady-state agents.
+	RunID string `yaml:"-" json:"runId,omitempty"`
 }
 
 // ToAgentInfo transforms the agent config to the agent info.
@@ -36,14 +58,60 @@ func (ac AgentConfig) ImageHash() string {
 	return digest
 }
 
+// OneOff tells if the config is for a one-off run of the agent.
+func (ac AgentConfig) OneOff() bool {
+	return len(ac.RunID) > 0
+}
+
 func (ac AgentConfig) ContainerName() string {
 	_, digest := utils.SplitImageRef(ac.Image)
+	if ac.OneOff() {
+		return fmt.Sprintf("%s%s-run-%s", DockerAgentContainerNamePrefix, utils.ShortenString(ac.ID, 8), ac.RunID)
+	}
 	if ac.IsLocal {
-		return fmt.Sprintf("%s-agent-%s", ContainerNamePrefix, utils.ShortenString(ac.ID, 8))
+		return fmt.Sprintf("%s%s", DockerAgentContainerNamePrefix, utils.ShortenString(ac.ID, 8))
+	}
+	return fmt.Sprintf("%s%s-%s", DockerAgentContainerNamePrefix, utils.ShortenString(ac.ID, 8), utils.ShortenString(digest, 4))
+}
+
+// IsAgentContainerName tells if the name belongs to a steady-state container of the agent. The
+// registry agent container names contain the image digest so they can only be matched by the prefix.
+func IsAgentContainerName(agentID, containerName string) bool {
+	name := AgentConfig{ID: agentID, IsLocal: true}.ContainerName()
+	if containerName == name {
+		return true
 	}
-	return fmt.Sprintf("%s-agent-%s-%s", ContainerNamePrefix, utils.ShortenString(ac.ID, 8), utils.ShortenString(digest, 4))
+	return strings.HasPrefix(containerName, name+"-") && !strings.HasPrefix(containerName, name+"-run-")
+}
+
+// IsAnyAgentContainerName tells if the name follows the agent container naming. It matches the
+// steady-state, the local and the one-off run containers of all agents.
+func IsAnyAgentContainerName(containerName string) bool {
+	return strings.HasPrefix(containerName, DockerAgentContainerNamePrefix)
 }
 
+// GrpcPort returns the gRPC port of the agent.
 func (ac AgentConfig) GrpcPort() string {
+	if ac.AssignedGrpcPort > 0 {
+		return strconv.Itoa(ac.AssignedGrpcPort)
+	}
 	return AgentGrpcPort
 }
+
+// InBlockRange tells if the agent should process the block. The range is unbounded on the side
+// which has no start or stop block.
+func (ac AgentConfig) InBlockRange(blockNumber uint64) bool {
+	if ac.StartBlock != nil && blockNumber < *ac.StartBlock {
+		return false
+	}
+	if ac.StopBlock != nil && blockNumber > *ac.StopBlock {
+		return false
+	}
+	return true
+}
+
+// PastStopBlock tells if the block is after the stop block, so the agent has no blocks left to
+// process.
+func (ac AgentConfig) PastStopBlock(blockNumber uint64) bool {
+	return ac.StopBlock != nil && blockNumber > *ac.StopBlock
+}
@@ -1,14 +1,95 @@
 package config
 
 import (
-	"github.com/stretchr/testify/assert"
 	"testing"
+
+	"github.com/stretchr/testify/require"
 )
 
-func TestAgentConfig_ContainerName(t *testing.T) {
-	cfg := AgentConfig{
-		ID:    "0x04f65c638f234548104790d7c692c9273d41f82d784b174ff2fdc3e8e5bf1636",
-		Image: "bafybeibvkqkf7i3c5ouehviwjb2dzbukgqied3cg36axl7gzm23r6ielnu@sha256:de866feeb97cba4cad6343c4137cb48bc798be0136015bec16d97c8ef28852b9",
+func blockPtr(n uint64) *uint64 {
+	return &n
+}
+
+func TestAgentConfig_InBlockRange(t *testing.T) {
+	for _, testCase := range []struct {
+		name        string
+		start, stop *uint64
+		block       uint64
+		inRange     bool
+		pastStop    bool
+	}{
+		{name: "unbounded", block: 100, inRange: true},
+		{name: "before start", start: blockPtr(100), block: 99},
+		{name: "at start", start: blockPtr(100), block: 100, inRange: true},
+		{name: "after start", start: blockPtr(100), block: 101, inRange: true},
+		{name: "before stop", stop: blockPtr(100), block: 99, inRange: true},
+		{name: "at stop", stop: blockPtr(100), block: 100, inRange: true},
+		{name: "after stop", stop: blockPtr(100), block: 101, pastStop: true},
+		{name: "single block - before", start: blockPtr(100), stop: blockPtr(100), block: 99},
+		{name: "single block - at", start: blockPtr(100), stop: blockPtr(100), block: 100, inRange: true},
+		{name: "single block - after", start: blockPtr(100), stop: blockPtr(100), block: 101, pastStop: true},
+		{name: "range - inside", start: blockPtr(100), stop: blockPtr(200), block: 150, inRange: true},
+	} {
+		t.Run(testCase.name, func(t *testing.T) {
+			r := require.New(t)
+			agentCfg := AgentConfig{StartBlock: testCase.start, StopBlock: testCase.stop}
+			r.Equal(testCase.inRange, agentCfg.InBlockRange(testCase.block))
+			r.Equal(testCase.pastStop, agentCfg.PastStopBlock(testCase.block))
+		})
+	}
+}
+
+func TestAgentConfig_ContainerName_OneOff(t *testing.T) {
+	r := require.New(t)
+
+	agentCfg := AgentConfig{
+		ID:    "0x04f65c638f234548104790b8ab0e3e0f4add0a6d5b9da7d7ba4b9d8c6c6ba7f0",
+		Image: "bafybeibvkqkf7i4ggvlqjduuprloyjcsvxjz3ckgibqqtngnvyvejvpmfq@sha256:abcdef0123456789",
+	}
+	r.False(agentCfg.OneOff())
+	steadyName := agentCfg.ContainerName()
+
+	agentCfg.RunID = "1a2b3c4d"
+	r.True(agentCfg.OneOff())
+	r.Equal(DockerAgentContainerNamePrefix+"0x04f65c-run-1a2b3c4d", agentCfg.ContainerName())
+	r.NotEqual(steadyName, agentCfg.ContainerName())
+}
+
+func TestIsAgentContainerName(t *testing.T) {
+	r := require.New(t)
+
+	agentID := "0x04f65c638f234548104790b8ab0e3e0f4add0a6d5b9da7d7ba4b9d8c6c6ba7f0"
+	agentCfg := AgentConfig{
+		ID:    agentID,
+		Image: "bafybeibvkqkf7i4ggvlqjduuprloyjcsvxjz3ckgibqqtngnvyvejvpmfq@sha256:abcdef0123456789",
+	}
+	r.True(IsAgentContainerName(agentID, agentCfg.ContainerName()))
+
+	agentCfg.IsLocal = true
+	r.True(IsAgentContainerName(agentID, agentCfg.ContainerName()))
+
+	agentCfg.RunID = "1a2b3c4d"
+	r.False(IsAgentContainerName(agentID, agentCfg.ContainerName()))
+
+	r.False(IsAgentContainerName(agentID, DockerSupervisorContainerName))
+	r.False(IsAgentContainerName("0x1234567890", agentCfg.ContainerName()))
+}
+
+func TestIsAnyAgentContainerName(t *testing.T) {
+	r := require.New(t)
+
+	agentCfg := AgentConfig{
+		ID:    "0x04f65c638f234548104790b8ab0e3e0f4add0a6d5b9da7d7ba4b9d8c6c6ba7f0",
+		Image: "bafybeibvkqkf7i4ggvlqjduuprloyjcsvxjz3ckgibqqtngnvyvejvpmfq@sha256:abcdef0123456789",
+	}
+	r.True(IsAnyAgentContainerName(agentCfg.ContainerName()))
+	agentCfg.RunID = "1a2b3c4d"
+	r.True(IsAnyAgentContainerName(agentCfg.ContainerName()))
+
+	for _, name := range []string{
+		DockerSupervisorContainerName, DockerUpdaterContainerName, DockerNatsContainerName,
+		DockerScannerContainerName, DockerJSONRPCProxyContainerName, DockerEgressProxyContainerName,
+	} {
+		r.False(IsAnyAgentContainerName(name))
 	}
-	assert.Equal(t, "forta-agent-0x04f65c-de86", cfg.ContainerName())
 }
@@ -2,16 +2,36 @@ package config
 
 import (
 	"errors"
+	"net"
 	"os"
 	"path"
+	"strings"
+	"time"
 
 	"github.com/creasty/defaults"
 	"github.com/forta-network/forta-core-go/protocol/settings"
+	"github.com/forta-network/forta-core-go/utils"
 )
 
 type JsonRpcConfig struct {
-	Url     string            `yaml:"url" json:"url" validate:"omitempty,url"`
-	Headers map[string]string `yaml:"headers" json:"headers"`
+	Url            string                `yaml:"url" json:"url" validate:"omitempty,url"`
+	Headers        map[string]string     `yaml:"headers" json:"headers"`
+	RateLimit      ClientRateLimitConfig `yaml:"rateLimit" json:"rateLimit"`
+	CircuitBreaker CircuitBreakerConfig  `yaml:"circuitBreaker" json:"circuitBreaker"`
+}
+
+// ClientRateLimitConfig limits the client-side request rate. The requests are not limited
+// when the rate is zero.
+type ClientRateLimitConfig struct {
+	RequestsPerSecond float64 `yaml:"requestsPerSecond" json:"requestsPerSecond" validate:"min=0"`
+	Burst             int     `yaml:"burst" json:"burst" validate:"min=0"`
+}
+
+// CircuitBreakerConfig stops the requests for a while after consecutive failures. The breaker
+// is disabled when the threshold is zero.
+type CircuitBreakerConfig struct {
+	FailureThreshold int           `yaml:"failureThreshold" json:"failureThreshold" validate:"min=0"`
+	OpenDuration     time.Duration `yaml:"openDuration" json:"openDuration" validate:"min=0"`
 }
 
 type ScannerConfig struct {
@@ -20,6 +40,80 @@ type ScannerConfig struct {
 	BlockRateLimit     int           `yaml:"blockRateLimit" json:"blockRateLimit" default:"200"`
 	BlockMaxAgeSeconds int64         `yaml:"blockMaxAgeSeconds" json:"blockMaxAgeSeconds" default:"600"`
 	AlertAPIURL        string        `yaml:"apiUrl" json:"apiUrl" default:"https://api.forta.network/graphql" validate:"url"`
+
+	// BlockPollInterval is the wait before fetching each block (e.g. "500ms", "2s"). Every poll
+	// costs at least one RPC call (and one more with tracing) whether or not there is a new block,
+	// so a short interval lowers the lag on fast chains at the cost of more RPC usage and a long
+	// interval saves RPC calls on slow chains. Overrides blockRateLimit when set.
+	BlockPollInterval time.Duration `yaml:"blockPollInterval" json:"blockPollInterval" validate:"omitempty,min=100ms"`
+
+	// ArchiveJsonRpc is an optional archive node API which serves the requests for the blocks
+	// which are deeper than archiveBlockDepth. The jsonRpc API serves the recent blocks.
+	ArchiveJsonRpc JsonRpcConfig `yaml:"archiveJsonRpc" json:"archiveJsonRpc"`
+	// ArchiveBlockDepth is how many blocks behind the latest block the archive API is used from.
+	ArchiveBlockDepth uint64 `yaml:"archiveBlockDepth" json:"archiveBlockDepth" default:"128"`
+
+	// Queue bounds the buffering between the block ingestion and the agents.
+	Queue DispatchQueueConfig `yaml:"queue" json:"queue"`
+
+	// SkipChainIDCheck disables checking the chain ID of the scan and the trace APIs against
+	// the configured chain ID.
+	SkipChainIDCheck bool `yaml:"skipChainIdCheck" json:"skipChainIdCheck"`
+}
+
+// Overflow policies of the dispatch queues
+const (
+	OverflowPolicyBlock      = "block"
+	OverflowPolicyDropOldest = "drop-oldest"
+)
+
+// DispatchQueueConfig bounds the queues between the block ingestion and the agents.
+type DispatchQueueConfig struct {
+	// Capacity is the max number of the blocks and the txs waiting to be dispatched and the max
+	// number of the block and the tx requests waiting for each agent.
+	Capacity int `yaml:"capacity" json:"capacity" default:"2000" validate:"min=0"`
+	// OverflowPolicy decides what happens when an agent buffer is full: "block" makes the block
+	// fetcher wait so that no data is lost and "drop-oldest" drops the oldest tx requests of
+	// the agent. The block requests are never dropped.
+	OverflowPolicy string `yaml:"overflowPolicy" json:"overflowPolicy" default:"block" validate:"omitempty,oneof=block drop-oldest"`
+}
+
+// DefaultDispatchQueueCapacity is used when the dispatch queue capacity is not set.
+const DefaultDispatchQueueCapacity = 2000
+
+// Size returns the capacity of the queues.
+func (cfg DispatchQueueConfig) Size() int {
+	if cfg.Capacity > 0 {
+		return cfg.Capacity
+	}
+	return DefaultDispatchQueueCapacity
+}
+
+// DropsOldest tells if the oldest tx requests are dropped when an agent buffer is full.
+func (cfg DispatchQueueConfig) DropsOldest() bool {
+	return cfg.OverflowPolicy == OverflowPolicyDropOldest
+}
+
+// ArchiveEnabled tells if the archive API is configured.
+func (cfg ScannerConfig) ArchiveEnabled() bool {
+	return len(cfg.ArchiveJsonRpc.Url) > 0
+}
+
+// validateArchive checks that the primary API is configured if the archive API is configured.
+func (cfg ScannerConfig) validateArchive() error {
+	if cfg.ArchiveEnabled() && len(cfg.JsonRpc.Url) == 0 {
+		return errors.New("scan.jsonRpc.url is required when scan.archiveJsonRpc.url is set")
+	}
+	return nil
+}
+
+// PollInterval returns the block polling interval. It falls back to the block rate limit
+// if the interval is not configured.
+func (cfg ScannerConfig) PollInterval() time.Duration {
+	if cfg.BlockPollInterval > 0 {
+		return cfg.BlockPollInterval
+	}
+	return time.Duration(cfg.BlockRateLimit) * time.Millisecond
 }
 
 type TraceConfig struct {
@@ -38,66 +132,347 @@ type JsonRpcProxyConfig struct {
 }
 
 type LogConfig struct {
-	Level       string `yaml:"level" json:"level" default:"info" `
+	Level string `yaml:"level" json:"level" default:"info" `
+	// Levels override the level for the components (e.g. runner, json-rpc).
+	Levels map[string]string `yaml:"levels" json:"levels" validate:"dive,oneof=trace debug info warn warning error fatal panic"`
+	// Format is the log output format. The runner defaults to text and the containers default to json.
+	Format      string `yaml:"format" json:"format" validate:"omitempty,oneof=json text"`
 	MaxLogSize  string `yaml:"maxLogSize" json:"maxLogSize" default:"50m" `
 	MaxLogFiles int    `yaml:"maxLogFiles" json:"maxLogFiles" default:"10" `
+	// LogDriver is the Docker log driver of the node and agent containers. The max log size
+	// and the max log files apply to the json-file and local drivers.
+	LogDriver string            `yaml:"logDriver" json:"logDriver" default:"json-file" validate:"omitempty,oneof=json-file local gelf syslog journald"`
+	LogOpts   map[string]string `yaml:"logOpts" json:"logOpts"`
+	// Remote forwards the node logs to a remote endpoint if the type is set.
+	Remote LogRemoteConfig `yaml:"remote" json:"remote"`
+	// KeepRemoved saves the last logs of the node containers before they are removed.
+	KeepRemoved KeepRemovedLogsConfig `yaml:"keepRemoved" json:"keepRemoved"`
+}
+
+// KeepRemovedLogsConfig configures saving the logs of the removed node containers to the forta dir.
+type KeepRemovedLogsConfig struct {
+	// Lines is the number of the last log lines to save. The logs are not saved if it is zero.
+	Lines int `yaml:"lines" json:"lines" validate:"min=0"`
+	// MaxBytes keeps only the last bytes of the saved lines.
+	MaxBytes int `yaml:"maxBytes" json:"maxBytes" default:"1048576" validate:"min=1"`
+	// MaxFiles is the number of saved log files to keep. The oldest files are deleted first.
+	MaxFiles int `yaml:"maxFiles" json:"maxFiles" default:"20" validate:"min=1"`
+}
+
+// Remote log types
+const (
+	LogRemoteSyslog = "syslog"
+	LogRemoteLoki   = "loki"
+	LogRemoteHTTP   = "http"
+)
+
+// LogRemoteConfig configures forwarding the logs to a syslog, Loki or HTTP endpoint.
+type LogRemoteConfig struct {
+	Type string `yaml:"type" json:"type" validate:"omitempty,oneof=syslog loki http"`
+	// URL is like udp://host:514 for syslog and the push endpoint for the others.
+	URL    string            `yaml:"url" json:"url" validate:"required_with=Type,omitempty,url"`
+	Labels map[string]string `yaml:"labels" json:"labels"`
+	// BufferSize is the max number of entries waiting to be shipped. New entries are dropped
+	// when the buffer is full.
+	BufferSize    int           `yaml:"bufferSize" json:"bufferSize" default:"10000" validate:"min=1"`
+	BatchSize     int           `yaml:"batchSize" json:"batchSize" default:"500" validate:"min=1"`
+	FlushInterval time.Duration `yaml:"flushInterval" json:"flushInterval" default:"1s" validate:"min=100ms"`
+	// IncludeContainers forwards the logs of the node containers too.
+	IncludeContainers bool `yaml:"includeContainers" json:"includeContainers"`
+
+	Username              string `yaml:"username" json:"username"`
+	Password              string `yaml:"password" json:"password"`
+	TLSCAFile             string `yaml:"tlsCaFile" json:"tlsCaFile"`
+	TLSInsecureSkipVerify bool   `yaml:"tlsInsecureSkipVerify" json:"tlsInsecureSkipVerify"`
 }
 
+// Enabled tells if the logs should be forwarded.
+func (cfg LogRemoteConfig) Enabled() bool {
+	return len(cfg.Type) > 0
+}
+
+// Container registry auth types
+const (
+	RegistryAuthBasic = "basic"
+	RegistryAuthECR   = "ecr"
+	RegistryAuthGCR   = "gcr"
+)
+
 type RegistryConfig struct {
 	JsonRpc              JsonRpcConfig `yaml:"jsonRpc" json:"jsonRpc" default:"{\"url\": \"https://polygon-rpc.com\"}"`
 	IPFS                 IPFSConfig    `yaml:"ipfs" json:"ipfs"`
-	ContainerRegistry    string        `yaml:"containerRegistry" json:"containerRegistry" validate:"hostname|hostname_port" default:"disco.forta.network" `
+	ContainerRegistry    string        `yaml:"containerRegistry" json:"containerRegistry" validate:"hostname|hostname_port"`
+	AuthType             string        `yaml:"authType" json:"authType" default:"basic" validate:"omitempty,oneof=basic ecr gcr"`
 	Username             string        `yaml:"username" json:"username"`
 	Password             string        `yaml:"password" json:"password"`
+	ECR                  ECRAuthConfig `yaml:"ecr" json:"ecr"`
+	GCR                  GCRAuthConfig `yaml:"gcr" json:"gcr"`
 	Disable              bool          `yaml:"disable" json:"disable"` // for testing situations
 	CheckIntervalSeconds int           `yaml:"checkIntervalSeconds" json:"checkIntervalSeconds" default:"15"`
+	// StrictImageRefs rejects the invalid disco image refs instead of trying them as they are.
+	StrictImageRefs bool `yaml:"strictImageRefs" json:"strictImageRefs"`
+	// AllowedRegistries are the registry hostnames or repository prefixes which the supervisor,
+	// the updater and the agent images can come from. All registries are allowed if it is empty.
+	AllowedRegistries []string `yaml:"allowedRegistries" json:"allowedRegistries" validate:"dive,required"`
+	// RequireDigest rejects the image refs which are not pinned to a digest.
+	RequireDigest bool `yaml:"requireDigest" json:"requireDigest"`
+}
+
+// ECRAuthConfig contains the settings for getting AWS ECR tokens by using the AWS CLI.
+type ECRAuthConfig struct {
+	Region  string `yaml:"region" json:"region"`
+	Profile string `yaml:"profile" json:"profile"`
+}
+
+// GCRAuthConfig contains the settings for getting GCP access tokens from the metadata server.
+type GCRAuthConfig struct {
+	ServiceAccount string `yaml:"serviceAccount" json:"serviceAccount" default:"default"`
 }
 
+// IPFSConfig configures the IPFS access. The API URL is the HTTP API of an IPFS node: if it is set,
+// the publisher adds and pins the batches by using the API and reads only from the gateways.
+// The additional gateways are used when the main gateway fails.
 type IPFSConfig struct {
-	GatewayURL string `yaml:"gatewayUrl" json:"gatewayUrl" validate:"url" default:"https://ipfs.forta.network" `
-	APIURL     string `yaml:"apiUrl" json:"apiUrl" validate:"url" default:"https://ipfs.forta.network" `
-	Username   string `yaml:"username" json:"username"`
-	Password   string `yaml:"password" json:"password"`
+	GatewayURL  string   `yaml:"gatewayUrl" json:"gatewayUrl" validate:"url" default:"https://ipfs.forta.network" `
+	GatewayURLs []string `yaml:"gatewayUrls" json:"gatewayUrls" validate:"dive,url"`
+	APIURL      string   `yaml:"apiUrl" json:"apiUrl" validate:"omitempty,url"`
+	Username    string   `yaml:"username" json:"username"`
+	Password    string   `yaml:"password" json:"password"`
+}
+
+// Gateways returns the gateway URL followed by the additional gateway URLs, without duplicates.
+func (cfg IPFSConfig) Gateways() (gateways []string) {
+	seen := make(map[string]bool)
+	for _, gatewayURL := range append([]string{cfg.GatewayURL}, cfg.GatewayURLs...) {
+		if len(gatewayURL) == 0 || seen[gatewayURL] {
+			continue
+		}
+		seen[gatewayURL] = true
+		gateways = append(gateways, gatewayURL)
+	}
+	return
 }
 
 type BatchConfig struct {
 	SkipEmpty                    bool `yaml:"skipEmpty" json:"skipEmpty"`
-	IntervalSeconds              *int `yaml:"intervalSeconds" json:"intervalSeconds" default:"15"`
+	IntervalSeconds              *int `yaml:"intervalSeconds" json:"intervalSeconds" default:"15" validate:"omitempty,min=1"`
 	MetricsBucketIntervalSeconds *int `yaml:"metricsBucketIntervalSeconds" json:"metricsBucketIntervalSeconds" default:"60"`
-	MaxAlerts                    *int `yaml:"maxAlerts" json:"maxAlerts" default:"1000" `
+	MaxAlerts                    *int `yaml:"maxAlerts" json:"maxAlerts" default:"1000" validate:"omitempty,min=1"`
+	Compress                     bool `yaml:"compress" json:"compress"`
+	MaxBatchBytes                int  `yaml:"maxBatchBytes" json:"maxBatchBytes" validate:"min=0"`
+	DedupWindowSeconds           int  `yaml:"dedupWindowSeconds" json:"dedupWindowSeconds" validate:"min=0"`
+	DedupMaxEntries              int  `yaml:"dedupMaxEntries" json:"dedupMaxEntries" default:"10000" validate:"min=0"`
+	Adaptive                     bool `yaml:"adaptive" json:"adaptive"`
+	MaxIntervalSeconds           int  `yaml:"maxIntervalSeconds" json:"maxIntervalSeconds" default:"300" validate:"min=0"`
+}
+
+// Default batching thresholds
+const (
+	DefaultBatchIntervalSeconds = 15
+	DefaultBatchMaxAlerts       = 1000
+)
+
+// Interval returns the batch interval. It falls back to the default interval if the
+// interval is not configured.
+func (cfg BatchConfig) Interval() time.Duration {
+	if cfg.IntervalSeconds != nil {
+		return time.Duration(*cfg.IntervalSeconds) * time.Second
+	}
+	return DefaultBatchIntervalSeconds * time.Second
+}
+
+// AlertLimit returns the max alerts in a batch. It falls back to the default limit if the
+// limit is not configured.
+func (cfg BatchConfig) AlertLimit() int {
+	if cfg.MaxAlerts != nil {
+		return *cfg.MaxAlerts
+	}
+	return DefaultBatchMaxAlerts
+}
+
+type BatchQueueConfig struct {
+	Disable     bool `yaml:"disable" json:"disable"`
+	MaxSizeMB   int  `yaml:"maxSizeMb" json:"maxSizeMb" default:"500" validate:"min=0"`
+	MaxAgeHours int  `yaml:"maxAgeHours" json:"maxAgeHours" default:"24" validate:"min=0"`
+}
+
+type AlertAPIRetryConfig struct {
+	MaxAttempts           int `yaml:"maxAttempts" json:"maxAttempts" default:"5" validate:"min=1"`
+	InitialBackoffSeconds int `yaml:"initialBackoffSeconds" json:"initialBackoffSeconds" default:"1" validate:"min=0"`
+	MaxBackoffSeconds     int `yaml:"maxBackoffSeconds" json:"maxBackoffSeconds" default:"30" validate:"min=0"`
+	JitterPercent         int `yaml:"jitterPercent" json:"jitterPercent" default:"20" validate:"min=0,max=100"`
+}
+
+type WebhookConfig struct {
+	URL         string   `yaml:"url" json:"url" validate:"url"`
+	Secret      string   `yaml:"secret" json:"secret"`
+	MinSeverity string   `yaml:"minSeverity" json:"minSeverity" validate:"omitempty,oneof=INFO LOW MEDIUM HIGH CRITICAL"`
+	BotIDs      []string `yaml:"botIds" json:"botIds"`
+	SendBatch   bool     `yaml:"sendBatch" json:"sendBatch"`
+}
+
+// IPFSRetryConfig configures the retries of the IPFS add and pin operations.
+type IPFSRetryConfig struct {
+	MaxAttempts    int `yaml:"maxAttempts" json:"maxAttempts" default:"3" validate:"min=1"`
+	BackoffSeconds int `yaml:"backoffSeconds" json:"backoffSeconds" default:"2" validate:"min=0"`
+}
+
+// IPFSPinningConfig configures a pinning service which pins the batches in addition to the gateways.
+type IPFSPinningConfig struct {
+	URL    string `yaml:"url" json:"url" validate:"omitempty,url"`
+	APIKey string `yaml:"apiKey" json:"apiKey"`
+}
+
+// PublisherIPFSConfig extends the IPFS config with the batch upload settings.
+type PublisherIPFSConfig struct {
+	IPFSConfig `yaml:",inline"`
+	Upload     bool              `yaml:"upload" json:"upload"`
+	Verify     bool              `yaml:"verify" json:"verify"`
+	Retry      IPFSRetryConfig   `yaml:"retry" json:"retry"`
+	Pinning    IPFSPinningConfig `yaml:"pinning" json:"pinning"`
+}
+
+// AgentPublishConfig overrides the publishing settings for an agent.
+type AgentPublishConfig struct {
+	AgentID     string `yaml:"agentId" json:"agentId" validate:"required"`
+	MinSeverity string `yaml:"minSeverity" json:"minSeverity" validate:"omitempty,oneof=INFO LOW MEDIUM HIGH CRITICAL"`
+	Suppress    bool   `yaml:"suppress" json:"suppress"`
 }
 
 type PublisherConfig struct {
-	SkipPublish   bool        `yaml:"skipPublish" json:"skipPublish" default:"false"`
-	AlwaysPublish bool        `yaml:"alwaysPublish" json:"alwaysPublish" default:"false"`
-	APIURL        string      `yaml:"apiUrl" json:"apiUrl" default:"https://alerts.forta.network" validate:"url"`
-	IPFS          IPFSConfig  `yaml:"ipfs" json:"ipfs" validate:"required_unless=SkipPublish true"`
-	Batch         BatchConfig `yaml:"batch" json:"batch"`
+	SkipPublish   bool                 `yaml:"skipPublish" json:"skipPublish" default:"false"`
+	AlwaysPublish bool                 `yaml:"alwaysPublish" json:"alwaysPublish" default:"false"`
+	APIURL        string               `yaml:"apiUrl" json:"apiUrl" default:"https://alerts.forta.network" validate:"url"`
+	IPFS          PublisherIPFSConfig  `yaml:"ipfs" json:"ipfs" validate:"required_unless=SkipPublish true"`
+	Batch         BatchConfig          `yaml:"batch" json:"batch"`
+	Queue         BatchQueueConfig     `yaml:"queue" json:"queue"`
+	Retry         AlertAPIRetryConfig  `yaml:"retry" json:"retry"`
+	Webhooks      []WebhookConfig      `yaml:"webhooks" json:"webhooks" validate:"dive"`
+	MinSeverity   string               `yaml:"minSeverity" json:"minSeverity" validate:"omitempty,oneof=INFO LOW MEDIUM HIGH CRITICAL"`
+	Agents        []AgentPublishConfig `yaml:"agents" json:"agents" validate:"dive"`
 }
 
 type ResourcesConfig struct {
 	DisableAgentLimits bool    `yaml:"disableAgentLimits" json:"disableAgentLimits" default:"false" `
 	AgentMaxMemoryMiB  int     `yaml:"agentMaxMemoryMib" json:"agentMaxMemoryMib" validate:"omitempty,min=100"`
 	AgentMaxCPUs       float64 `yaml:"agentMaxCpus" json:"agentMaxCpus" validate:"omitempty,gt=0"`
+	// ContainerStopTimeoutSeconds is how long the containers can take to exit gracefully before
+	// they are killed. The containers are signalled without waiting if it is zero.
+	ContainerStopTimeoutSeconds int `yaml:"containerStopTimeoutSeconds" json:"containerStopTimeoutSeconds" validate:"min=0"`
+	// ContainerStartTimeoutSeconds is how long to wait for the node containers to start before
+	// failing the launch.
+	ContainerStartTimeoutSeconds int `yaml:"containerStartTimeoutSeconds" json:"containerStartTimeoutSeconds" default:"60" validate:"min=1"`
+	// MaxConcurrentPulls limits the image pulls of the runner, the supervisor and the agents
+	// together. The pulls are not limited if it is zero.
+	MaxConcurrentPulls int `yaml:"maxConcurrentPulls" json:"maxConcurrentPulls" default:"3" validate:"min=0"`
+}
+
+// ContainerStopTimeout returns the graceful stop timeout of the containers.
+func (cfg ResourcesConfig) ContainerStopTimeout() time.Duration {
+	return time.Duration(cfg.ContainerStopTimeoutSeconds) * time.Second
+}
+
+// ContainerStartTimeout returns how long to wait for the containers to start.
+func (cfg ResourcesConfig) ContainerStartTimeout() time.Duration {
+	return time.Duration(cfg.ContainerStartTimeoutSeconds) * time.Second
 }
 
 type ENSConfig struct {
 	DefaultContract bool          `yaml:"defaultContract" json:"defaultContract" default:"false" `
-	ContractAddress string        `yaml:"contractAddress" json:"contractAddress" validate:"omitempty,eth_addr" default:"0x08f42fcc52a9C2F391bF507C4E8688D0b53e1bd7"`
+	ContractAddress string        `yaml:"contractAddress" json:"contractAddress" validate:"omitempty,eth_addr"`
 	JsonRpc         JsonRpcConfig `yaml:"jsonRpc" json:"jsonRpc" default:"{\"url\": \"https://polygon-rpc.com\"}" `
 	Override        bool          `yaml:"override" json:"override" default:"false"`
 }
 
 type TelemetryConfig struct {
-	URL       string `yaml:"url" json:"url" default:"https://alerts.forta.network/telemetry" validate:"url"`
-	CustomURL string `yaml:"customUrl" validate:"omitempty,url"`
-	Disable   bool   `yaml:"disable" json:"disable"`
+	URL       string              `yaml:"url" json:"url" default:"https://alerts.forta.network/telemetry" validate:"url"`
+	CustomURL string              `yaml:"customUrl" validate:"omitempty,url"`
+	Disable   bool                `yaml:"disable" json:"disable"`
+	Auth      TelemetryAuthConfig `yaml:"auth" json:"auth"`
+}
+
+// TelemetryAuthConfig secures the node health and metrics endpoints.
+type TelemetryAuthConfig struct {
+	// BearerToken is required in the Authorization header if set.
+	BearerToken string `yaml:"bearerToken" json:"bearerToken"`
+	// Username and Password are required as the basic auth credentials if set.
+	Username string `yaml:"username" json:"username" validate:"required_with=Password"`
+	Password string `yaml:"password" json:"password" validate:"required_with=Username"`
+	// AllowLocalUnauthenticated exempts the requests from localhost.
+	AllowLocalUnauthenticated bool `yaml:"allowLocalUnauthenticated" json:"allowLocalUnauthenticated"`
+	// TLSCertFile and TLSKeyFile enable TLS. The certificate is reloaded when the files change.
+	TLSCertFile string `yaml:"tlsCertFile" json:"tlsCertFile" validate:"required_with=TLSKeyFile"`
+	TLSKeyFile  string `yaml:"tlsKeyFile" json:"tlsKeyFile" validate:"required_with=TLSCertFile"`
+}
+
+// Enabled tells if the requests need to be authenticated.
+func (cfg TelemetryAuthConfig) Enabled() bool {
+	return len(cfg.BearerToken) > 0 || len(cfg.Username) > 0
+}
+
+// TLSEnabled tells if the endpoints should be served with TLS.
+func (cfg TelemetryAuthConfig) TLSEnabled() bool {
+	return len(cfg.TLSCertFile) > 0
+}
+
+// HealthConfig configures the runner health server.
+type HealthConfig struct {
+	// BindAddr is the host:port to bind the runner health server to. The server listens
+	// on the default health port of all interfaces if not set. A loopback address makes the
+	// server unreachable for the supervisor telemetry sync.
+	BindAddr string `yaml:"bindAddr" json:"bindAddr" validate:"omitempty,hostname_port"`
+}
+
+// Port returns the port of the runner health server.
+func (cfg HealthConfig) Port() string {
+	if len(cfg.BindAddr) == 0 {
+		return DefaultHealthPort
+	}
+	_, port, _ := net.SplitHostPort(cfg.BindAddr)
+	return port
 }
 
 type AutoUpdateConfig struct {
-	Disable          bool `yaml:"disable" json:"disable"`
-	UpdateDelay      *int `yaml:"updateDelay" json:"updateDelay"`
-	TrackPrereleases bool `yaml:"trackPrereleases" json:"trackPrereleases"`
+	Disable          bool   `yaml:"disable" json:"disable"`
+	UpdateDelay      *int   `yaml:"updateDelay" json:"updateDelay"`
+	TrackPrereleases bool   `yaml:"trackPrereleases" json:"trackPrereleases"`
+	Channel          string `yaml:"channel" json:"channel" validate:"omitempty,oneof=stable beta canary"`
+	TrackDelayHours  int    `yaml:"trackDelayHours" json:"trackDelayHours" validate:"min=0"`
+	// ValidationMinutes enables waiting for the new supervisor to become healthy and scan blocks
+	// before committing to a release. The previous supervisor is restored if it does not in time.
+	ValidationMinutes int `yaml:"validationMinutes" json:"validationMinutes" validate:"min=0"`
+	// CheckIntervalMinutes is how often the runner checks the latest release from the updater.
+	// The default is a few seconds.
+	CheckIntervalMinutes int `yaml:"checkIntervalMinutes" json:"checkIntervalMinutes" validate:"min=0"`
+
+	Window *UpdateWindowConfig `yaml:"window" json:"window"`
+}
+
+// ReleaseChannel returns the release channel to track. Tracking prereleases
+// means tracking the beta channel if the channel is not specified.
+func (cfg AutoUpdateConfig) ReleaseChannel() string {
+	if len(cfg.Channel) > 0 {
+		return cfg.Channel
+	}
+	if cfg.TrackPrereleases {
+		return ReleaseChannelBeta
+	}
+	return ReleaseChannelStable
+}
+
+// ValidationTimeout returns how long to wait for the new supervisor to be valid.
+func (cfg AutoUpdateConfig) ValidationTimeout() time.Duration {
+	return time.Duration(cfg.ValidationMinutes) * time.Minute
+}
+
+// CheckInterval returns how often to check the latest release. It is zero if not configured.
+func (cfg AutoUpdateConfig) CheckInterval() time.Duration {
+	return time.Duration(cfg.CheckIntervalMinutes) * time.Minute
+}
+
+// TrackDelay returns how long to wait after seeing a new release before applying it.
+func (cfg AutoUpdateConfig) TrackDelay() time.Duration {
+	return time.Duration(cfg.TrackDelayHours) * time.Hour
 }
 
 type AgentLogsConfig struct {
@@ -165,6 +540,177 @@ type CombinerConfig struct {
 	CombinerCachePath string `yaml:"alertCachePath" json:"alert_cache_path"`
 }
 
+type RunnerConfig struct {
+	WatchConfig                    bool `yaml:"watchConfig" json:"watchConfig"`
+	LivenessCheckIntervalSeconds   int  `yaml:"livenessCheckIntervalSeconds" json:"livenessCheckIntervalSeconds" default:"10" validate:"min=1"`
+	DependencyCheckIntervalSeconds int  `yaml:"dependencyCheckIntervalSeconds" json:"dependencyCheckIntervalSeconds" default:"300" validate:"min=0"`
+	// UpdaterPort is the host port of the updater API.
+	UpdaterPort string `yaml:"updaterPort" json:"updaterPort" default:"8089" validate:"omitempty,numeric"`
+	// ControlPort is the local port of the runner control API. The API is disabled if it is empty.
+	ControlPort string `yaml:"controlPort" json:"controlPort" default:"8091" validate:"omitempty,numeric"`
+	// FortaDirUID is the expected owner of the forta dir. The owner is not checked if it is not set.
+	FortaDirUID *int `yaml:"fortaDirUid" json:"fortaDirUid,omitempty" validate:"omitempty,min=0"`
+	// MinFreeDiskBytes is the free space required on the forta dir and the docker data root
+	// before pulling images. The check is disabled if it is zero.
+	MinFreeDiskBytes int64 `yaml:"minFreeDiskBytes" json:"minFreeDiskBytes" default:"1073741824" validate:"min=0"`
+	// ImagePruneIntervalSeconds is how often the unused images from the container registry are
+	// removed. The images are not pruned if it is zero.
+	ImagePruneIntervalSeconds int `yaml:"imagePruneIntervalSeconds" json:"imagePruneIntervalSeconds" default:"21600" validate:"min=0"`
+	// ImagePruneKeepVersions is the number of the newest unused images which are kept so that
+	// they can be used for rolling back.
+	ImagePruneKeepVersions int `yaml:"imagePruneKeepVersions" json:"imagePruneKeepVersions" default:"2" validate:"min=0"`
+	// SkipKeyCheck disables decrypting the scanner key with the passphrase at start-up. A wrong
+	// passphrase is then found out only when the node signs something.
+	SkipKeyCheck bool `yaml:"skipKeyCheck" json:"skipKeyCheck"`
+	// BlockGapCheckIntervalSeconds is how often the latest scanned block is compared with the
+	// chain head. The block gap is not checked if it is zero.
+	BlockGapCheckIntervalSeconds int `yaml:"blockGapCheckIntervalSeconds" json:"blockGapCheckIntervalSeconds" default:"60" validate:"min=0"`
+	// MaxBlockGap is the number of blocks which the scanner can be behind the chain head before
+	// the block gap is reported as failing.
+	MaxBlockGap uint64 `yaml:"maxBlockGap" json:"maxBlockGap" default:"50"`
+	// RegistrationCheck checks if the scanner is registered and staked over the minimum at
+	// start-up and with the other dependency checks after that.
+	RegistrationCheck bool `yaml:"registrationCheck" json:"registrationCheck"`
+	// StrictRegistration fails the start-up if the scanner is not registered or is staked below
+	// the minimum. It enables the registration check.
+	StrictRegistration bool `yaml:"strictRegistration" json:"strictRegistration"`
+	// RegistrationCacheSeconds is how long the registration status is reused before the registry
+	// contracts are checked again.
+	RegistrationCacheSeconds int `yaml:"registrationCacheSeconds" json:"registrationCacheSeconds" default:"3600" validate:"min=0"`
+}
+
+// AgentRuntimeConfig configures how the agent containers are run.
+type AgentRuntimeConfig struct {
+	// GrpcPortStart is the first port of the agent gRPC port range.
+	GrpcPortStart int `yaml:"grpcPortStart" json:"grpcPortStart" default:"50051" validate:"min=1,max=65535"`
+	// GrpcPortRange is the number of ports to assign to the agents starting from grpcPortStart.
+	// All agents use grpcPortStart if it is not greater than one.
+	GrpcPortRange int `yaml:"grpcPortRange" json:"grpcPortRange" validate:"min=0,max=10000"`
+	// AllowLocal enables running the agents from the local-agents.yml file in the forta dir in
+	// addition to the agents from the registry. It is always enabled in development mode.
+	AllowLocal bool `yaml:"allowLocal" json:"allowLocal"`
+	// Disabled are the IDs of the agents which are never started. The agents can also be disabled
+	// with 'forta agents disable' without changing the config.
+	Disabled []string `yaml:"disabled" json:"disabled"`
+	// Defaults are the resource limits of the agents which are not limited individually. The
+	// resources.agentMaxCpus and resources.agentMaxMemoryMib values are used if they are not set.
+	Defaults AgentLimitsConfig `yaml:"defaults" json:"defaults"`
+	// Limits set the resource limits of the individual agents.
+	Limits []AgentLimitsConfig `yaml:"limits" json:"limits" validate:"dive"`
+	// Env sets the env vars of the individual agents.
+	Env []AgentEnvConfig `yaml:"env" json:"env" validate:"dive"`
+	// MaxConsecutiveCrashes is the number of crashes in a row after which an agent is
+	// quarantined and retried hourly. The crashes are forgotten after a stable run.
+	MaxConsecutiveCrashes int `yaml:"maxConsecutiveCrashes" json:"maxConsecutiveCrashes" default:"10" validate:"min=1"`
+	// MaxOOMKillsPerHour is the number of OOM kills in an hour after which an agent is
+	// quarantined until it is retried manually.
+	MaxOOMKillsPerHour int `yaml:"maxOomKillsPerHour" json:"maxOomKillsPerHour" default:"3" validate:"min=1"`
+	// MaxRestarts is the number of restarts after which a crashing agent is marked failed and is not
+	// restarted until it is retried manually. The restarts are forgotten after a stable run. Zero is
+	// unlimited.
+	MaxRestarts int `yaml:"maxRestarts" json:"maxRestarts" validate:"min=0"`
+	// RestartBackoff is the delay of the restart after a crash. It is doubled with each crash in a
+	// row up to RestartMaxBackoff.
+	RestartBackoff    time.Duration `yaml:"restartBackoff" json:"restartBackoff" default:"1s" validate:"min=0"`
+	RestartMaxBackoff time.Duration `yaml:"restartMaxBackoff" json:"restartMaxBackoff" default:"5m" validate:"min=0"`
+	// Restarts set the restart behavior of the individual agents.
+	Restarts []AgentRestartConfig `yaml:"restarts" json:"restarts" validate:"dive"`
+	// BlockTimeout and TxTimeout are the deadlines of the block and the tx requests to the agents.
+	BlockTimeout time.Duration `yaml:"blockTimeout" json:"blockTimeout" default:"30s" validate:"min=1s"`
+	TxTimeout    time.Duration `yaml:"txTimeout" json:"txTimeout" default:"10s" validate:"min=1s"`
+	// HealthCheckInterval is how often the agents are pinged over gRPC.
+	HealthCheckInterval time.Duration `yaml:"healthCheckInterval" json:"healthCheckInterval" default:"30s" validate:"min=1s"`
+	// MaxConsecutiveTimeouts is the number of timeouts in a row after which an agent is
+	// restarted. The restarts are delayed and quarantined like the crashes.
+	MaxConsecutiveTimeouts int `yaml:"maxConsecutiveTimeouts" json:"maxConsecutiveTimeouts" default:"5" validate:"min=1"`
+	// MaxConcurrent is the max number of agents which run at the same time. The rest of the
+	// assigned agents wait until the running ones are removed or disabled. Zero is unlimited.
+	MaxConcurrent int `yaml:"maxConcurrent" json:"maxConcurrent" validate:"min=0"`
+	// SlowAgentThreshold is the p95 block or tx latency in a five-minute window above which an
+	// agent is reported as slow. Zero disables the slow agent warnings.
+	SlowAgentThreshold time.Duration `yaml:"slowAgentThreshold" json:"slowAgentThreshold" default:"5s" validate:"min=0"`
+	// DrainTimeout is how long a removed or replaced agent can take to finish the requests in
+	// flight before its container is stopped. Zero stops the agents right away.
+	DrainTimeout time.Duration `yaml:"drainTimeout" json:"drainTimeout" default:"30s" validate:"min=0"`
+	// NetworkPolicy is "open" to let the agents reach any host or "isolated" to attach them only
+	// to an internal network with the JSON-RPC proxy and the agent gRPC plumbing.
+	NetworkPolicy string `yaml:"networkPolicy" json:"networkPolicy" default:"open" validate:"omitempty,oneof=open isolated"`
+	// EgressAllowlist are the hostnames which the isolated agents can reach through the egress
+	// proxy. A hostname is allowed only on port 443, or 80 for plain HTTP, unless a port is given
+	// like "api.example.com:8443". The proxy is not started if the list is empty.
+	EgressAllowlist []string `yaml:"egressAllowlist" json:"egressAllowlist" validate:"dive,hostname|hostname_port"`
+}
+
+// AgentEnvConfig sets the env vars of an agent container. The ${VAR} references in the values are
+// expanded from the env of the node.
+type AgentEnvConfig struct {
+	AgentID string            `yaml:"agentId" json:"agentId" validate:"required"`
+	Vars    map[string]string `yaml:"vars" json:"vars" validate:"dive,keys,env_name,endkeys"`
+}
+
+// AgentRestartConfig sets the restart behavior of an agent. The agent.maxRestarts,
+// agent.restartBackoff and agent.restartMaxBackoff values are used if they are not set.
+type AgentRestartConfig struct {
+	AgentID           string        `yaml:"agentId" json:"agentId" validate:"required"`
+	MaxRestarts       int           `yaml:"maxRestarts" json:"maxRestarts" validate:"min=0"`
+	RestartBackoff    time.Duration `yaml:"restartBackoff" json:"restartBackoff" validate:"min=0"`
+	RestartMaxBackoff time.Duration `yaml:"restartMaxBackoff" json:"restartMaxBackoff" validate:"min=0"`
+}
+
+// AgentLimitsConfig sets the resource limits of the agent containers.
+type AgentLimitsConfig struct {
+	// AgentID is required only for the individual agent limits.
+	AgentID string `yaml:"agentId" json:"agentId,omitempty"`
+	// CPULimit is the max number of CPUs.
+	CPULimit float64 `yaml:"cpuLimit" json:"cpuLimit" validate:"omitempty,gt=0"`
+	// MemoryLimit is the max memory in MiB.
+	MemoryLimit int `yaml:"memoryLimit" json:"memoryLimit" validate:"omitempty,min=100"`
+}
+
+// ReadinessConfig configures when the node is reported ready to scan.
+type ReadinessConfig struct {
+	// MaxBlockLagSeconds is the max time since the latest block reached the agents.
+	MaxBlockLagSeconds int `yaml:"maxBlockLagSeconds" json:"maxBlockLagSeconds" default:"300" validate:"min=1"`
+}
+
+// DockerConfig configures the access to the docker daemon on the host.
+type DockerConfig struct {
+	// SocketPath is the docker daemon socket on the host (e.g. /run/user/1000/docker.sock for
+	// rootless docker). It is detected from $DOCKER_HOST or is /var/run/docker.sock if not set.
+	SocketPath string `yaml:"socketPath" json:"socketPath"`
+	// ContainerLabels are added to all of the containers which the node starts, including the agents.
+	// The network.forta labels are reserved for the node.
+	ContainerLabels map[string]string `yaml:"containerLabels" json:"containerLabels" validate:"dive,keys,container_label,endkeys"`
+}
+
+// HostSocketPath returns the path of the docker socket on the host.
+func (cfg DockerConfig) HostSocketPath() string {
+	if len(cfg.SocketPath) > 0 {
+		return cfg.SocketPath
+	}
+	if dockerHost := os.Getenv("DOCKER_HOST"); strings.HasPrefix(dockerHost, "unix://") {
+		return strings.TrimPrefix(dockerHost, "unix://")
+	}
+	return DefaultDockerSocketPath
+}
+
+// AddLabels adds the configured container labels to the given labels. The given labels are
+// not overridden.
+func (cfg DockerConfig) AddLabels(labels map[string]string) map[string]string {
+	if len(cfg.ContainerLabels) == 0 {
+		return labels
+	}
+	if labels == nil {
+		labels = make(map[string]string)
+	}
+	for key, value := range cfg.ContainerLabels {
+		if _, ok := labels[key]; !ok {
+			labels[key] = value
+		}
+	}
+	return labels
+}
+
 type AdvancedConfig struct {
 	SafeOffset bool `yaml:"safeOffset" json:"safeOffset"`
 }
@@ -176,11 +722,28 @@ type Config struct {
 	FortaDir    string `yaml:"-" json:"_fortaDir"`
 	KeyDirPath  string `yaml:"-" json:"_keyDirPath"`
 	Passphrase  string `yaml:"-" json:"_passphrase"`
+	// PortMappings are the effective host ports after the start-up port assignment.
+	PortMappings []*PortMapping `yaml:"-" json:"_portMappings"`
+	// RemoteConfig tells if the config is fetched from the remote config URL.
+	RemoteConfig bool `yaml:"-" json:"_remoteConfig"`
 
 	// yaml config values
 
 	ChainID int `yaml:"chainId" json:"chainId" default:"1" `
 
+	// InstanceName separates the containers and the networks of this node from the other nodes
+	// on the same host (e.g. forta-<instanceName>-supervisor). Each instance needs its own forta dir.
+	InstanceName string `yaml:"instanceName" json:"instanceName" validate:"omitempty,alphanum,lowercase"`
+
+	// Offline disables the updater and all of the external default endpoints (e.g. the alert API,
+	// telemetry and IPFS) for the networks without egress. The embedded images are used and the
+	// alerts are sent only to the local alert log and the configured webhooks.
+	Offline bool `yaml:"offline" json:"offline"`
+
+	// Profile selects the env defaults (e.g. the container registry and the ENS contract) by name.
+	// It is prod or dev depending on the development mode if not set.
+	Profile string `yaml:"profile" json:"profile" validate:"omitempty,env_profile"`
+
 	Scan  ScannerConfig `yaml:"scan" json:"scan"`
 	Trace TraceConfig   `yaml:"trace" json:"trace"`
 
@@ -191,6 +754,7 @@ type Config struct {
 	ResourcesConfig  ResourcesConfig    `yaml:"resources" json:"resources"`
 	ENSConfig        ENSConfig          `yaml:"ens" json:"ens"`
 	TelemetryConfig  TelemetryConfig    `yaml:"telemetry" json:"telemetry"`
+	Health           HealthConfig       `yaml:"health" json:"health"`
 	AutoUpdate       AutoUpdateConfig   `yaml:"autoUpdate" json:"autoUpdate"`
 	AgentLogsConfig  AgentLogsConfig    `yaml:"agentLogs" json:"agentLogs"`
 	LocalModeConfig  LocalModeConfig    `yaml:"localMode" json:"localMode"`
@@ -198,6 +762,15 @@ type Config struct {
 	StorageConfig    StorageConfig      `yaml:"storage" json:"storage"`
 	CombinerConfig   CombinerConfig     `yaml:"combiner" json:"combiner"`
 	AdvancedConfig   AdvancedConfig     `yaml:"advanced" json:"advanced"`
+	RunnerConfig     RunnerConfig       `yaml:"runner" json:"runner"`
+	Agent            AgentRuntimeConfig `yaml:"agent" json:"agent"`
+	Readiness        ReadinessConfig    `yaml:"readiness" json:"readiness"`
+	Docker           DockerConfig       `yaml:"docker" json:"docker"`
+	Ports            PortsConfig        `yaml:"ports" json:"ports"`
+	Network          NetworkConfig      `yaml:"network" json:"network"`
+	Nats             NatsConfig         `yaml:"nats" json:"nats"`
+	Signer           SignerConfig       `yaml:"signer" json:"signer"`
+	Supervisor       SupervisorConfig   `yaml:"supervisor" json:"supervisor"`
 }
 
 func (cfg *Config) ConfigFilePath() string {
@@ -207,15 +780,28 @@ func (cfg *Config) ConfigFilePath() string {
 // GetConfigForContainer is how a container gets the forta configuration (file or env var)
 func GetConfigForContainer() (Config, error) {
 	var cfg Config
-	if _, err := os.Stat(DefaultContainerConfigPath); os.IsNotExist(err) {
+	configPath := DefaultContainerConfigPath
+	// the last-good remote config takes precedence only if the node is configured from a url
+	remoteConfig := utils.ParseBoolEnvVar(EnvRemoteConfig)
+	if remoteConfig {
+		configPath = DefaultContainerRemoteConfigPath
+	}
+	if _, err := os.Stat(configPath); os.IsNotExist(err) {
 		return cfg, errors.New("config file not found")
 	}
 
-	cfg, err := getConfigFromFile(DefaultContainerConfigPath)
+	cfg, err := GetConfigFromFile(configPath)
 	if err != nil {
 		return Config{}, err
 	}
+	cfg.Development = utils.ParseBoolEnvVar(EnvDevelopment)
+	cfg.RemoteConfig = remoteConfig
 	applyContextDefaults(&cfg)
+	SetInstanceName(cfg.InstanceName)
+	cfg.Network.Proxy.ApplyEnv()
+	cfg.Nats.ApplyEnv()
+	cfg.Signer.ApplyEnv()
+	cfg.Nats.ResolvePaths(cfg.FortaDir)
 
 	// initialize combiner cache dump path if cache is persistent
 	if cfg.CombinerConfig.CombinerCachePath != "" {
@@ -236,6 +822,15 @@ func GetConfigForContainer() (Config, error) {
 
 // apply defaults that apply in certain contexts
 func applyContextDefaults(cfg *Config) {
+	applyDerivedDefaults(cfg)
+	cfg.ApplyEnvDefaults()
+	cfg.FortaDir = DefaultContainerFortaDirPath
+	cfg.KeyDirPath = path.Join(cfg.FortaDir, DefaultKeysDirName)
+	cfg.CombinerConfig.CombinerCachePath = path.Join(cfg.FortaDir, DefaultCombinerCacheFileName)
+}
+
+// apply defaults that are derived from the other values
+func applyDerivedDefaults(cfg *Config) {
 	chainSettings := settings.GetChainSettings(cfg.ChainID)
 	if chainSettings.EnableTrace {
 		cfg.Trace.Enabled = true
@@ -243,12 +838,10 @@ func applyContextDefaults(cfg *Config) {
 	if cfg.ENSConfig.DefaultContract {
 		cfg.ENSConfig.ContractAddress = ""
 	}
-	cfg.FortaDir = DefaultContainerFortaDirPath
-	cfg.KeyDirPath = path.Join(cfg.FortaDir, DefaultKeysDirName)
-	cfg.CombinerConfig.CombinerCachePath = path.Join(cfg.FortaDir, DefaultCombinerCacheFileName)
 }
 
-func getConfigFromFile(filename string) (Config, error) {
+// GetConfigFromFile reads the config from given file and applies the defaults.
+func GetConfigFromFile(filename string) (Config, error) {
 	var cfg Config
 	if err := readFile(filename, &cfg); err != nil {
 		return Config{}, err
@@ -14,19 +14,64 @@ var (
 	UseDockerImages       = "local"
 
 	DockerSupervisorManagedContainers = 7
-	DockerUpdaterContainerName        = fmt.Sprintf("%s-updater", ContainerNamePrefix)
-	DockerSupervisorContainerName     = fmt.Sprintf("%s-supervisor", ContainerNamePrefix)
-	DockerNatsContainerName           = fmt.Sprintf("%s-nats", ContainerNamePrefix)
-	DockerIpfsContainerName           = fmt.Sprintf("%s-ipfs", ContainerNamePrefix)
-	DockerScannerContainerName        = fmt.Sprintf("%s-scanner", ContainerNamePrefix)
-	DockerInspectorContainerName      = fmt.Sprintf("%s-inspector", ContainerNamePrefix)
-	DockerJSONRPCProxyContainerName   = fmt.Sprintf("%s-json-rpc", ContainerNamePrefix)
-	DockerJWTProviderContainerName    = fmt.Sprintf("%s-jwt-provider", ContainerNamePrefix)
-	DockerStorageContainerName        = fmt.Sprintf("%s-storage", ContainerNamePrefix)
+	DockerUpdaterContainerName        = containerName("updater")
+	DockerSupervisorContainerName     = containerName("supervisor")
+	DockerNatsContainerName           = containerName("nats")
+	DockerIpfsContainerName           = containerName("ipfs")
+	DockerScannerContainerName        = containerName("scanner")
+	DockerInspectorContainerName      = containerName("inspector")
+	DockerJSONRPCProxyContainerName   = containerName("json-rpc")
+	DockerJWTProviderContainerName    = containerName("jwt-provider")
+	DockerStorageContainerName        = containerName("storage")
+	DockerEgressProxyContainerName    = containerName("egress-proxy")
+	DockerAgentContainerNamePrefix    = containerName("agent-")
 
 	DockerNetworkName = DockerScannerContainerName
 
-	DefaultContainerFortaDirPath = "/.forta"
-	DefaultContainerConfigPath   = path.Join(DefaultContainerFortaDirPath, DefaultConfigFileName)
-	DefaultContainerKeyDirPath   = path.Join(DefaultContainerFortaDirPath, DefaultKeysDirName)
+	DefaultContainerFortaDirPath     = "/.forta"
+	DefaultContainerConfigPath       = path.Join(DefaultContainerFortaDirPath, DefaultConfigFileName)
+	DefaultContainerRemoteConfigPath = path.Join(DefaultContainerFortaDirPath, DefaultRemoteConfigFileName)
+	DefaultContainerKeyDirPath       = path.Join(DefaultContainerFortaDirPath, DefaultKeysDirName)
 )
+
+var instanceName string
+
+// InstanceName returns the name of the node instance. It is empty for the default instance.
+func InstanceName() string {
+	return instanceName
+}
+
+// SetInstanceName changes the container and network names so that they do not collide with
+// the other node instances on the same host. It should be called before creating any containers.
+func SetInstanceName(name string) {
+	instanceName = name
+	DockerUpdaterContainerName = containerName("updater")
+	DockerSupervisorContainerName = containerName("supervisor")
+	DockerNatsContainerName = containerName("nats")
+	DockerIpfsContainerName = containerName("ipfs")
+	DockerScannerContainerName = containerName("scanner")
+	DockerInspectorContainerName = containerName("inspector")
+	DockerJSONRPCProxyContainerName = containerName("json-rpc")
+	DockerJWTProviderContainerName = containerName("jwt-provider")
+	DockerStorageContainerName = containerName("storage")
+	DockerEgressProxyContainerName = containerName("egress-proxy")
+	DockerAgentContainerNamePrefix = containerName("agent-")
+	DockerNetworkName = DockerScannerContainerName
+}
+
+// containerName returns forta-<name> for the default instance and forta-<instance>-<name> otherwise.
+func containerName(name string) string {
+	if len(instanceName) == 0 {
+		return fmt.Sprintf("%s-%s", ContainerNamePrefix, name)
+	}
+	return fmt.Sprintf("%s-%s-%s", ContainerNamePrefix, instanceName, name)
+}
+
+// SupervisorManagedContainers returns the number of the service containers which the supervisor
+// starts. The NATS container is not started if an external NATS server is used.
+func (cfg Config) SupervisorManagedContainers() int {
+	if cfg.Nats.External {
+		return DockerSupervisorManagedContainers - 1
+	}
+	return DockerSupervisorManagedContainers
+}
@@ -0,0 +1,30 @@
+package config
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestSetInstanceName(t *testing.T) {
+	r := require.New(t)
+
+	r.Empty(InstanceName())
+	r.Equal("forta-supervisor", DockerSupervisorContainerName)
+	r.Equal("forta-scanner", DockerNetworkName)
+
+	SetInstanceName("node2")
+	defer SetInstanceName("")
+
+	r.Equal("node2", InstanceName())
+	r.Equal("forta-node2-supervisor", DockerSupervisorContainerName)
+	r.Equal("forta-node2-updater", DockerUpdaterContainerName)
+	r.Equal("forta-node2-nats", DockerNatsContainerName)
+	r.Equal("forta-node2-json-rpc", DockerJSONRPCProxyContainerName)
+	r.Equal("forta-node2-scanner", DockerNetworkName)
+	r.Equal("forta-node2-agent-0x04f65c", AgentConfig{ID: "0x04f65c638f", IsLocal: true}.ContainerName())
+
+	SetInstanceName("")
+	r.Equal("forta-supervisor", DockerSupervisorContainerName)
+	r.Equal("forta-agent-0x04f65c", AgentConfig{ID: "0x04f65c638f", IsLocal: true}.ContainerName())
+}
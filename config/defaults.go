@@ -4,11 +4,20 @@ const (
 	DefaultKeysDirName         = ".keys"
 	DefaultCombinerCacheFileName  = ".combiner_cache.json"
 	DefaultConfigFileName      = "config.yml"
+	DefaultRemoteConfigFileName = "remote-config.yml"
+	DefaultAdminTokenFileName  = "admin-token"
+	DefaultPortMappingsFileName = "ports.json"
+	DefaultUpdaterReleaseCacheFileName    = "updater-release-manifest.json"
+	DefaultSupervisorReleaseCacheFileName = "supervisor-release-manifest.json"
+	DefaultPullLocksDirName    = ".pull-locks"
+	DefaultRemovedLogsDirName  = "removed-logs"
 	DefaultNatsPort            = "4222"
 	DefaultContainerPort       = "8089"
 	DefaultHealthPort          = "8090"
 	DefaultJSONRPCProxyPort    = "8545"
 	DefaultStoragePort         = "8525"
 	DefaultJWTProviderPort     = "8515"
+	DefaultEgressProxyPort     = "3128"
 	DefaultFortaNodeBinaryPath = "/forta-node" // the path for the common binary in the container image
+	DefaultDockerSocketPath    = "/var/run/docker.sock"
 )
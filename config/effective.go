@@ -0,0 +1,127 @@
+package config
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"strings"
+
+	"github.com/creasty/defaults"
+)
+
+const redactedValue = "<redacted>"
+
+// secret values are found by the JSON keys
+var secretKeys = map[string]bool{
+	"password":    true,
+	"_passphrase": true,
+	"secret":      true,
+	"apiKey":      true,
+	"bearerToken": true,
+	"authToken":   true,
+	"headers":     true,
+}
+
+// the keys of these values are kept and the values are redacted
+var secretValueKeys = map[string]bool{
+	"vars": true,
+}
+
+// Effective returns the config which the node runs with after all defaults are applied. The env
+// defaults are from the env profile and the contract address is resolved like GetEnvDefaults does.
+func (cfg Config) Effective() (Config, error) {
+	if err := defaults.Set(&cfg); err != nil {
+		return Config{}, fmt.Errorf("failed to set the defaults: %v", err)
+	}
+	applyDerivedDefaults(&cfg)
+	cfg.ApplyEnvDefaults()
+	return cfg, nil
+}
+
+// RedactedJSON encodes the config as JSON after replacing the secret values.
+func (cfg Config) RedactedJSON() ([]byte, error) {
+	b, err := json.Marshal(cfg)
+	if err != nil {
+		return nil, err
+	}
+	var values map[string]interface{}
+	if err := json.Unmarshal(b, &values); err != nil {
+		return nil, err
+	}
+	redactSecrets(values)
+
+	var buf bytes.Buffer
+	encoder := json.NewEncoder(&buf)
+	encoder.SetEscapeHTML(false)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(values); err != nil {
+		return nil, err
+	}
+	return bytes.TrimSpace(buf.Bytes()), nil
+}
+
+func redactSecrets(value interface{}) {
+	switch value := value.(type) {
+	case map[string]interface{}:
+		for key, val := range value {
+			if secretKeys[key] && !isEmptyValue(val) {
+				value[key] = redactedValue
+				continue
+			}
+			if vals, ok := val.(map[string]interface{}); ok && secretValueKeys[key] {
+				for valKey := range vals {
+					vals[valKey] = redactedValue
+				}
+				continue
+			}
+			if str, ok := val.(string); ok {
+				value[key] = redactURL(str)
+				continue
+			}
+			redactSecrets(val)
+		}
+	case []interface{}:
+		for i, val := range value {
+			if str, ok := val.(string); ok {
+				value[i] = redactURL(str)
+				continue
+			}
+			redactSecrets(val)
+		}
+	}
+}
+
+// redactURL replaces the credentials and the query of the URLs which usually contain the API keys.
+func redactURL(value string) string {
+	u, err := url.Parse(value)
+	if err != nil || len(u.Scheme) == 0 || len(u.Host) == 0 || (u.User == nil && len(u.RawQuery) == 0) {
+		return value
+	}
+	var sb strings.Builder
+	sb.WriteString(u.Scheme)
+	sb.WriteString("://")
+	if u.User != nil {
+		sb.WriteString(redactedValue)
+		sb.WriteString("@")
+	}
+	sb.WriteString(u.Host)
+	sb.WriteString(u.EscapedPath())
+	if len(u.RawQuery) > 0 {
+		sb.WriteString("?")
+		sb.WriteString(redactedValue)
+	}
+	return sb.String()
+}
+
+func isEmptyValue(value interface{}) bool {
+	switch value := value.(type) {
+	case nil:
+		return true
+	case string:
+		return len(value) == 0
+	case map[string]interface{}:
+		return len(value) == 0
+	}
+	return false
+}
@@ -1,9 +1,24 @@
 package config
 
+import "fmt"
+
 const (
-	EnvHostFortaDir = "HOST_FORTA_DIR" // for retrieving forta dir path on the host os
-	EnvDevelopment  = "FORTA_DEVELOPMENT"
-	EnvReleaseInfo  = "FORTA_RELEASE_INFO"
+	EnvHostFortaDir     = "HOST_FORTA_DIR"     // for retrieving forta dir path on the host os
+	EnvHostDockerSocket = "HOST_DOCKER_SOCKET" // for mounting the docker socket of the host os
+	EnvDevelopment      = "FORTA_DEVELOPMENT"
+	EnvReleaseInfo      = "FORTA_RELEASE_INFO"
+	EnvLogFormat        = "FORTA_LOG_FORMAT"
+	EnvRemoteConfig     = "FORTA_REMOTE_CONFIG" // for reading the last-good remote config
+
+	// Supervisor env vars
+	EnvRunnerHealthPort = "FORTA_RUNNER_HEALTH_PORT"
+
+	// Updater env vars
+	EnvReleaseChannel = "FORTA_RELEASE_CHANNEL"
+
+	// Scanner env vars
+	EnvBlockPollInterval = "FORTA_BLOCK_POLL_INTERVAL"
+	EnvTraceEnabled      = "FORTA_TRACE_ENABLED"
 
 	// Agent env vars
 	EnvJsonRpcHost     = "JSON_RPC_HOST"
@@ -14,19 +29,79 @@ const (
 	EnvFortaBotID      = "FORTA_BOT_ID"
 )
 
+// Env profiles
+const (
+	EnvProfileProduction  = "prod"
+	EnvProfileDevelopment = "dev"
+)
+
 // EnvDefaults contain default values for one env.
 type EnvDefaults struct {
-	DiscoSubdomain string
+	DiscoSubdomain     string
+	ENSContractAddress string
 }
 
-// GetEnvDefaults returns the default values for an env.
-func GetEnvDefaults(development bool) EnvDefaults {
+// ContainerRegistry returns the default container registry of the env.
+func (defaults EnvDefaults) ContainerRegistry() string {
+	return fmt.Sprintf("%s.forta.network", defaults.DiscoSubdomain)
+}
+
+var envProfiles = map[string]EnvDefaults{
+	EnvProfileProduction: {
+		DiscoSubdomain:     "disco",
+		ENSContractAddress: "0x08f42fcc52a9C2F391bF507C4E8688D0b53e1bd7",
+	},
+	EnvProfileDevelopment: {
+		DiscoSubdomain:     "disco-dev",
+		ENSContractAddress: "0x08f42fcc52a9C2F391bF507C4E8688D0b53e1bd7",
+	},
+}
+
+// RegisterEnvProfile adds a named profile with its defaults or replaces an existing one.
+// It should be called before the config is loaded (e.g. from an init func).
+func RegisterEnvProfile(name string, defaults EnvDefaults) {
+	envProfiles[name] = defaults
+}
+
+// GetEnvProfileDefaults returns the default values of a named profile.
+func GetEnvProfileDefaults(name string) (EnvDefaults, bool) {
+	defaults, ok := envProfiles[name]
+	return defaults, ok
+}
+
+// EnvProfile returns the name of the env profile of the node. The development mode selects
+// the dev profile if no profile is set in the config.
+func (cfg Config) EnvProfile() string {
+	if len(cfg.Profile) > 0 {
+		return cfg.Profile
+	}
+	return envProfileName(cfg.Development)
+}
+
+func envProfileName(development bool) string {
 	if development {
-		return EnvDefaults{
-			DiscoSubdomain: "disco-dev",
-		}
+		return EnvProfileDevelopment
+	}
+	return EnvProfileProduction
+}
+
+// ApplyEnvDefaults fills the unset values from the defaults of the env. The values from the
+// config file always override the env defaults.
+func (cfg *Config) ApplyEnvDefaults() {
+	envDefaults, ok := GetEnvProfileDefaults(cfg.EnvProfile())
+	if !ok {
+		// the profile is validated later
+		envDefaults = GetEnvDefaults(cfg.Development)
 	}
-	return EnvDefaults{
-		DiscoSubdomain: "disco",
+	if len(cfg.Registry.ContainerRegistry) == 0 {
+		cfg.Registry.ContainerRegistry = envDefaults.ContainerRegistry()
 	}
+	if len(cfg.ENSConfig.ContractAddress) == 0 && !cfg.ENSConfig.DefaultContract {
+		cfg.ENSConfig.ContractAddress = envDefaults.ENSContractAddress
+	}
+}
+
+// GetEnvDefaults returns the default values for an env.
+func GetEnvDefaults(development bool) EnvDefaults {
+	return envProfiles[envProfileName(development)]
 }
@@ -0,0 +1,72 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// Errors
+var (
+	ErrImageRegistryNotAllowed = errors.New("image registry is not allowed")
+	ErrImageDigestRequired     = errors.New("image ref is not pinned to a digest")
+)
+
+// ImageSourceError is returned when an image does not come from a trusted source.
+type ImageSourceError struct {
+	Name string
+	Ref  string
+	Err  error
+}
+
+func (e *ImageSourceError) Error() string {
+	return fmt.Sprintf("untrusted %s image '%s': %v", e.Name, e.Ref, e.Err)
+}
+
+func (e *ImageSourceError) Unwrap() error {
+	return e.Err
+}
+
+// CheckImageSource checks the image ref against the allowed registries and the digest requirement.
+// The local images are used in development mode so the checks are skipped.
+func (cfg *Config) CheckImageSource(name, imageRef string) error {
+	if cfg.Development {
+		return nil
+	}
+	if cfg.Registry.RequireDigest && !strings.Contains(imageRef, "@sha256:") {
+		return &ImageSourceError{Name: name, Ref: imageRef, Err: ErrImageDigestRequired}
+	}
+	if !cfg.Registry.IsAllowedImage(imageRef) {
+		return &ImageSourceError{Name: name, Ref: imageRef, Err: ErrImageRegistryNotAllowed}
+	}
+	return nil
+}
+
+// IsAllowedImage tells if the image repository is under one of the allowed registries.
+func (registry RegistryConfig) IsAllowedImage(imageRef string) bool {
+	if len(registry.AllowedRegistries) == 0 {
+		return true
+	}
+	repository := imageRepository(imageRef)
+	for _, allowed := range registry.AllowedRegistries {
+		allowed = strings.TrimSuffix(strings.ToLower(allowed), "/")
+		if len(allowed) > 0 && (repository == allowed || strings.HasPrefix(repository, allowed+"/")) {
+			return true
+		}
+	}
+	return false
+}
+
+// imageRepository returns the repository of the image ref with the registry host and without the
+// tag and the digest. The refs without a registry host are from Docker Hub.
+func imageRepository(imageRef string) string {
+	repository := strings.ToLower(strings.SplitN(imageRef, "@", 2)[0])
+	if i := strings.LastIndex(repository, ":"); i > strings.LastIndex(repository, "/") {
+		repository = repository[:i]
+	}
+	parts := strings.SplitN(repository, "/", 2)
+	if len(parts) == 1 || !(strings.ContainsAny(parts[0], ".:") || parts[0] == "localhost") {
+		repository = "docker.io/" + repository
+	}
+	return repository
+}
@@ -0,0 +1,58 @@
+package config
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+const testImageDigest = "@sha256:0b1f5ab4d3a3c5f1b6d0e0c3a2b4a9c7a4a1e6ef7b9f0e3b1c2d4a6e8f0a2c4e"
+
+func TestCheckImageSource(t *testing.T) {
+	cfg := &Config{}
+	cfg.Registry.AllowedRegistries = []string{"disco.forta.network", "registry.example.com:5000/forta/"}
+	cfg.Registry.RequireDigest = true
+
+	for _, imageRef := range []string{
+		"disco.forta.network/bafybeibvkqkf7i3yaaqbzc2ioyrfv7cxd4yjcsbaz7ce5y7loz5ct5zczq" + testImageDigest,
+		"registry.example.com:5000/forta/scanner:v1" + testImageDigest,
+		"Disco.Forta.Network/image" + testImageDigest,
+	} {
+		require.NoError(t, cfg.CheckImageSource("supervisor", imageRef), imageRef)
+	}
+
+	for _, imageRef := range []string{
+		"disco.forta.network.evil.com/image" + testImageDigest,
+		"registry.example.com:5000/other/scanner" + testImageDigest,
+		"registry.example.com:5000/forta-evil/scanner" + testImageDigest,
+		"forta/scanner" + testImageDigest,
+	} {
+		err := cfg.CheckImageSource("supervisor", imageRef)
+		require.ErrorIs(t, err, ErrImageRegistryNotAllowed, imageRef)
+		var sourceErr *ImageSourceError
+		require.ErrorAs(t, err, &sourceErr)
+		require.Equal(t, imageRef, sourceErr.Ref)
+	}
+
+	err := cfg.CheckImageSource("agent", "disco.forta.network/image:latest")
+	require.ErrorIs(t, err, ErrImageDigestRequired)
+
+	// all registries and tags are allowed by default
+	require.NoError(t, (&Config{}).CheckImageSource("agent", "forta/scanner:latest"))
+
+	// the checks are skipped in development mode
+	cfg.Development = true
+	require.NoError(t, cfg.CheckImageSource("agent", "forta/scanner:latest"))
+}
+
+func TestImageRepository(t *testing.T) {
+	for imageRef, expected := range map[string]string{
+		"alpine":                        "docker.io/alpine",
+		"forta/scanner:v1":              "docker.io/forta/scanner",
+		"localhost/scanner":             "localhost/scanner",
+		"localhost:1970/scanner:latest": "localhost:1970/scanner",
+		"disco.forta.network/image" + testImageDigest: "disco.forta.network/image",
+	} {
+		require.Equal(t, expected, imageRepository(imageRef))
+	}
+}
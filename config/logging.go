@@ -0,0 +1,80 @@
+package config
+
+import (
+	"os"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// Log formats
+const (
+	LogFormatText = "text"
+	LogFormatJSON = "json"
+)
+
+// LevelOf returns the log level of the component.
+func (cfg LogConfig) LevelOf(component string) string {
+	if level, ok := cfg.Levels[component]; ok {
+		return level
+	}
+	return cfg.Level
+}
+
+// InitLogging initializes the log level and the formatter for the component. The log format
+// can be overridden by the env var which is propagated to the containers.
+func InitLogging(cfg Config, component string) error {
+	level := log.InfoLevel
+	if levelStr := cfg.Log.LevelOf(component); levelStr != "" {
+		var err error
+		level, err = log.ParseLevel(levelStr)
+		if err != nil {
+			return err
+		}
+	}
+	log.SetLevel(level)
+
+	format := cfg.Log.Format
+	if envFormat := os.Getenv(EnvLogFormat); envFormat != "" {
+		format = envFormat
+	}
+	log.SetFormatter(newLogFormatter(format))
+
+	// replace the component hook and keep the others after it so that the hooks
+	// like the log forwarder see the component field
+	hooks := make(log.LevelHooks)
+	hooks.Add(&componentHook{component: component})
+	for level, levelHooks := range log.StandardLogger().Hooks {
+		for _, hook := range levelHooks {
+			if _, ok := hook.(*componentHook); !ok {
+				hooks[level] = append(hooks[level], hook)
+			}
+		}
+	}
+	log.StandardLogger().ReplaceHooks(hooks)
+	return nil
+}
+
+func newLogFormatter(format string) log.Formatter {
+	if format == LogFormatJSON {
+		return &log.JSONFormatter{}
+	}
+	return &log.TextFormatter{
+		FullTimestamp: true,
+	}
+}
+
+// componentHook adds the component field to every log entry.
+type componentHook struct {
+	component string
+}
+
+func (hook *componentHook) Levels() []log.Level {
+	return log.AllLevels
+}
+
+func (hook *componentHook) Fire(entry *log.Entry) error {
+	if _, ok := entry.Data["component"]; !ok {
+		entry.Data["component"] = hook.component
+	}
+	return nil
+}
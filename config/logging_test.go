@@ -0,0 +1,93 @@
+package config
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"testing"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/require"
+)
+
+func TestInitLogging_Formatter(t *testing.T) {
+	r := require.New(t)
+
+	t.Setenv(EnvLogFormat, "")
+	r.NoError(InitLogging(Config{}, "runner"))
+	r.IsType(&log.TextFormatter{}, log.StandardLogger().Formatter)
+
+	r.NoError(InitLogging(Config{Log: LogConfig{Format: LogFormatJSON}}, "runner"))
+	r.IsType(&log.JSONFormatter{}, log.StandardLogger().Formatter)
+
+	// env overrides the config
+	t.Setenv(EnvLogFormat, LogFormatText)
+	r.NoError(InitLogging(Config{Log: LogConfig{Format: LogFormatJSON}}, "scanner"))
+	r.IsType(&log.TextFormatter{}, log.StandardLogger().Formatter)
+}
+
+func TestInitLogging_Levels(t *testing.T) {
+	r := require.New(t)
+
+	cfg := Config{
+		Log: LogConfig{
+			Level:  "info",
+			Levels: map[string]string{"runner": "debug", "json-rpc": "warn"},
+		},
+	}
+	r.NoError(InitLogging(cfg, "runner"))
+	r.Equal(log.DebugLevel, log.GetLevel())
+	r.NoError(InitLogging(cfg, "json-rpc"))
+	r.Equal(log.WarnLevel, log.GetLevel())
+	r.NoError(InitLogging(cfg, "scanner"))
+	r.Equal(log.InfoLevel, log.GetLevel())
+
+	cfg.Log.Levels["scanner"] = "bad"
+	r.Error(InitLogging(cfg, "scanner"))
+}
+
+func TestInitLogging_ComponentField(t *testing.T) {
+	r := require.New(t)
+
+	t.Setenv(EnvLogFormat, LogFormatJSON)
+	r.NoError(InitLogging(Config{}, "supervisor"))
+	var buf bytes.Buffer
+	log.SetOutput(&buf)
+	defer log.SetOutput(os.Stderr)
+
+	log.Info("test")
+	var entry map[string]interface{}
+	r.NoError(json.Unmarshal(buf.Bytes(), &entry))
+	r.Equal("supervisor", entry["component"])
+}
+
+type testRecordHook struct {
+	components []interface{}
+}
+
+func (hook *testRecordHook) Levels() []log.Level {
+	return log.AllLevels
+}
+
+func (hook *testRecordHook) Fire(entry *log.Entry) error {
+	hook.components = append(hook.components, entry.Data["component"])
+	return nil
+}
+
+func TestInitLogging_HookOrder(t *testing.T) {
+	r := require.New(t)
+
+	r.NoError(InitLogging(Config{}, "runner"))
+	hook := &testRecordHook{}
+	log.AddHook(hook)
+	defer log.StandardLogger().ReplaceHooks(make(log.LevelHooks))
+
+	// the component hook should stay before the other hooks after a rebuild
+	r.NoError(InitLogging(Config{}, "supervisor"))
+	log.SetOutput(&bytes.Buffer{})
+	defer log.SetOutput(os.Stderr)
+
+	log.Info("test")
+	r.Equal([]interface{}{"supervisor"}, hook.components)
+	r.Len(log.StandardLogger().Hooks[log.InfoLevel], 2)
+}
@@ -0,0 +1,87 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"path"
+	"time"
+)
+
+// NATS credential env vars which override the config file values
+const (
+	EnvNatsUser     = "FORTA_NATS_USER"
+	EnvNatsPassword = "FORTA_NATS_PASSWORD"
+)
+
+// NatsConfig configures the connections to the NATS server which carries the messages between
+// the node services. The node starts its own NATS container unless an external server is used.
+type NatsConfig struct {
+	// External makes the services connect to the NATS server at the URL instead of starting
+	// the NATS container.
+	External bool   `yaml:"external" json:"external"`
+	URL      string `yaml:"url" json:"url" validate:"required_if=External true,omitempty,url"`
+
+	// User and Password are the credentials of the NATS connections. The NATS container
+	// requires them too if set.
+	User     string `yaml:"user" json:"user" validate:"required_with=Password"`
+	Password string `yaml:"password" json:"password" validate:"required_with=User"`
+	// NkeySeedFile is the path of the nkey seed file. It is relative to the forta dir
+	// unless it is absolute.
+	NkeySeedFile string `yaml:"nkeySeedFile" json:"nkeySeedFile"`
+
+	TLS NatsTLSConfig `yaml:"tls" json:"tls"`
+
+	// ReconnectWait is the first wait before reconnecting and it doubles up to MaxReconnectWait
+	// after each failed attempt.
+	ReconnectWait    time.Duration `yaml:"reconnectWait" json:"reconnectWait" default:"1s" validate:"min=100ms"`
+	MaxReconnectWait time.Duration `yaml:"maxReconnectWait" json:"maxReconnectWait" default:"30s" validate:"min=100ms"`
+	// MaxBufferedMessages is the max number of messages to publish after reconnecting.
+	// The oldest messages are dropped first.
+	MaxBufferedMessages int `yaml:"maxBufferedMessages" json:"maxBufferedMessages" default:"1000" validate:"min=0"`
+}
+
+// NatsTLSConfig configures TLS for the connections to the external NATS server. The paths are
+// relative to the forta dir unless they are absolute.
+type NatsTLSConfig struct {
+	Enable   bool   `yaml:"enable" json:"enable"`
+	CAFile   string `yaml:"caFile" json:"caFile"`
+	CertFile string `yaml:"certFile" json:"certFile" validate:"required_with=KeyFile"`
+	KeyFile  string `yaml:"keyFile" json:"keyFile" validate:"required_with=CertFile"`
+}
+
+// ServerURL returns the URL of the NATS server which the services connect to.
+func (cfg NatsConfig) ServerURL() string {
+	if cfg.External {
+		return cfg.URL
+	}
+	return fmt.Sprintf("%s:%s", DockerNatsContainerName, DefaultNatsPort)
+}
+
+// ApplyEnv overrides the credentials with the env vars if they are set.
+func (cfg *NatsConfig) ApplyEnv() {
+	if user := os.Getenv(EnvNatsUser); len(user) > 0 {
+		cfg.User = user
+	}
+	if password := os.Getenv(EnvNatsPassword); len(password) > 0 {
+		cfg.Password = password
+	}
+}
+
+// AddEnv passes the credential env vars of the current process to the container env.
+func (cfg NatsConfig) AddEnv(env map[string]string) map[string]string {
+	for _, key := range []string{EnvNatsUser, EnvNatsPassword} {
+		if value := os.Getenv(key); len(value) > 0 {
+			env[key] = value
+		}
+	}
+	return env
+}
+
+// ResolvePaths makes the file paths absolute by using the forta dir.
+func (cfg *NatsConfig) ResolvePaths(fortaDir string) {
+	for _, file := range []*string{&cfg.NkeySeedFile, &cfg.TLS.CAFile, &cfg.TLS.CertFile, &cfg.TLS.KeyFile} {
+		if len(*file) > 0 && !path.IsAbs(*file) {
+			*file = path.Join(fortaDir, *file)
+		}
+	}
+}
@@ -0,0 +1,46 @@
+package config
+
+import (
+	"testing"
+
+	"github.com/creasty/defaults"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNatsConfig(t *testing.T) {
+	r := require.New(t)
+
+	var cfg Config
+	r.NoError(defaults.Set(&cfg))
+	cfg.ApplyEnvDefaults()
+	r.NoError(cfg.Validate())
+	r.Equal(DockerNatsContainerName+":"+DefaultNatsPort, cfg.Nats.ServerURL())
+	r.Equal(DockerSupervisorManagedContainers, cfg.SupervisorManagedContainers())
+
+	// the external server needs a url
+	cfg.Nats.External = true
+	r.Error(cfg.Validate())
+	cfg.Nats.URL = "nats://nats.corp:4222"
+	r.NoError(cfg.Validate())
+	r.Equal("nats://nats.corp:4222", cfg.Nats.ServerURL())
+	r.Equal(DockerSupervisorManagedContainers-1, cfg.SupervisorManagedContainers())
+
+	// the credentials are overridden by the env vars
+	cfg.Nats.User, cfg.Nats.Password = "file-user", "file-password"
+	t.Setenv(EnvNatsUser, "env-user")
+	t.Setenv(EnvNatsPassword, "env-password")
+	cfg.Nats.ApplyEnv()
+	r.Equal("env-user", cfg.Nats.User)
+	r.Equal("env-password", cfg.Nats.Password)
+	r.Equal(map[string]string{
+		"FOO":           "bar",
+		EnvNatsUser:     "env-user",
+		EnvNatsPassword: "env-password",
+	}, cfg.Nats.AddEnv(map[string]string{"FOO": "bar"}))
+
+	cfg.Nats.NkeySeedFile = "nats.nk"
+	cfg.Nats.TLS.CAFile = "/etc/ssl/nats-ca.pem"
+	cfg.Nats.ResolvePaths(DefaultContainerFortaDirPath)
+	r.Equal(DefaultContainerFortaDirPath+"/nats.nk", cfg.Nats.NkeySeedFile)
+	r.Equal("/etc/ssl/nats-ca.pem", cfg.Nats.TLS.CAFile)
+}
@@ -0,0 +1,20 @@
+package config
+
+import (
+	log "github.com/sirupsen/logrus"
+)
+
+// UpdatesDisabled tells if the node should run only the embedded images without the updater.
+func (cfg *Config) UpdatesDisabled() bool {
+	return cfg.AutoUpdate.Disable || cfg.Offline
+}
+
+// OfflineSkip tells if the call to the external endpoint should be skipped because the node
+// is offline.
+func (cfg *Config) OfflineSkip(endpoint string) bool {
+	if !cfg.Offline {
+		return false
+	}
+	log.WithField("endpoint", endpoint).Debug("offline mode - skipping the external endpoint")
+	return true
+}
@@ -0,0 +1,24 @@
+package config
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestConfig_Offline(t *testing.T) {
+	r := require.New(t)
+
+	var cfg Config
+	r.False(cfg.UpdatesDisabled())
+	r.False(cfg.OfflineSkip("alert-api"))
+
+	cfg.AutoUpdate.Disable = true
+	r.True(cfg.UpdatesDisabled())
+	r.False(cfg.OfflineSkip("alert-api"))
+
+	cfg.AutoUpdate.Disable = false
+	cfg.Offline = true
+	r.True(cfg.UpdatesDisabled())
+	r.True(cfg.OfflineSkip("alert-api"))
+}
@@ -0,0 +1,212 @@
+package config
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"path"
+	"strconv"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// ErrPortInUse is returned when a host port is already bound by another process.
+var ErrPortInUse = errors.New("port is already in use")
+
+// host ports
+const (
+	PortNameHealth  = "health"
+	PortNameControl = "control"
+	PortNameUpdater = "updater"
+)
+
+// UpdaterCheckPath is the path of the updater endpoint which checks the latest release immediately.
+const UpdaterCheckPath = "/check"
+
+// PortsConfig configures how the host ports are allocated.
+type PortsConfig struct {
+	// AutoAssign replaces the busy host ports with free ports at start-up.
+	AutoAssign bool `yaml:"autoAssign" json:"autoAssign"`
+}
+
+// PortMapping is the effective host port of a configured port.
+type PortMapping struct {
+	Name       string `json:"name"`
+	Configured string `json:"configured"`
+	Effective  string `json:"effective"`
+}
+
+// hostPortNames returns the names of the host ports which are used with this config.
+func (cfg *Config) hostPortNames() []string {
+	names := []string{PortNameHealth}
+	if len(cfg.RunnerConfig.ControlPort) > 0 {
+		names = append(names, PortNameControl)
+	}
+	if !cfg.UpdatesDisabled() {
+		names = append(names, PortNameUpdater)
+	}
+	return names
+}
+
+// hostPort returns the bind host and the port.
+func (cfg *Config) hostPort(name string) (host, port string) {
+	switch name {
+	case PortNameHealth:
+		host, _, _ = net.SplitHostPort(cfg.Health.BindAddr)
+		return host, cfg.Health.Port()
+	case PortNameControl:
+		return "127.0.0.1", cfg.RunnerConfig.ControlPort
+	case PortNameUpdater:
+		if len(cfg.RunnerConfig.UpdaterPort) == 0 {
+			return "", DefaultContainerPort
+		}
+		return "", cfg.RunnerConfig.UpdaterPort
+	}
+	return "", ""
+}
+
+func (cfg *Config) setHostPort(name, port string) {
+	switch name {
+	case PortNameHealth:
+		host, _ := cfg.hostPort(name)
+		cfg.Health.BindAddr = net.JoinHostPort(host, port)
+	case PortNameControl:
+		cfg.RunnerConfig.ControlPort = port
+	case PortNameUpdater:
+		cfg.RunnerConfig.UpdaterPort = port
+	}
+}
+
+// CheckPorts checks if the host ports are free.
+func (cfg *Config) CheckPorts() error {
+	for _, name := range cfg.hostPortNames() {
+		host, port := cfg.hostPort(name)
+		if err := checkPortFree(host, port); err != nil {
+			return portInUseError(name, port, err)
+		}
+	}
+	return nil
+}
+
+// AssignPorts checks if the host ports are free before starting. If auto-assignment is enabled,
+// the busy ports are replaced with free ports and the mappings are recorded in the config.
+// The ports which are published by the running containers of the node (e.g. the updater which
+// is adopted after restart) are not busy for the node and the ports which were assigned to them
+// are kept.
+func (cfg *Config) AssignPorts(ownPorts map[string]bool) error {
+	prevMappings, err := cfg.readPortMappings()
+	if err != nil {
+		log.WithError(err).Warn("failed to read the previous port mappings")
+	}
+	cfg.PortMappings = nil
+	for _, name := range cfg.hostPortNames() {
+		host, port := cfg.hostPort(name)
+		mapping := &PortMapping{Name: name, Configured: port, Effective: port}
+		cfg.PortMappings = append(cfg.PortMappings, mapping)
+		if ownPorts[port] {
+			continue
+		}
+		err := checkPortFree(host, port)
+		if err == nil {
+			continue
+		}
+		if !cfg.Ports.AutoAssign {
+			return portInUseError(name, port, err)
+		}
+		freePort, ok := ownAssignedPort(prevMappings, mapping, ownPorts)
+		if !ok {
+			freePort, err = findFreePort(host)
+			if err != nil {
+				return fmt.Errorf("failed to find a free port for the %s port: %v", name, err)
+			}
+		}
+		log.WithFields(log.Fields{
+			"name":       name,
+			"configured": port,
+			"assigned":   freePort,
+		}).Warn("port is in use - assigned a free port")
+		mapping.Effective = freePort
+		cfg.setHostPort(name, freePort)
+	}
+	return nil
+}
+
+// applyPortMappings keeps the assigned ports if the ports are not changed in the new config.
+func (cfg *Config) applyPortMappings(mappings []*PortMapping) {
+	cfg.PortMappings = mappings
+	for _, mapping := range mappings {
+		if _, port := cfg.hostPort(mapping.Name); port == mapping.Configured && port != mapping.Effective {
+			cfg.setHostPort(mapping.Name, mapping.Effective)
+		}
+	}
+}
+
+// SavePortMappings writes the effective ports to the forta dir so that the CLI can find the node.
+func (cfg *Config) SavePortMappings() error {
+	b, _ := json.Marshal(cfg.PortMappings)
+	return os.WriteFile(path.Join(cfg.FortaDir, DefaultPortMappingsFileName), b, 0644)
+}
+
+// LoadPortMappings applies the effective ports from the last start-up of the node.
+func (cfg *Config) LoadPortMappings() error {
+	mappings, err := cfg.readPortMappings()
+	if err != nil {
+		return err
+	}
+	if mappings != nil {
+		cfg.applyPortMappings(mappings)
+	}
+	return nil
+}
+
+func (cfg *Config) readPortMappings() ([]*PortMapping, error) {
+	b, err := os.ReadFile(path.Join(cfg.FortaDir, DefaultPortMappingsFileName))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var mappings []*PortMapping
+	if err := json.Unmarshal(b, &mappings); err != nil {
+		return nil, fmt.Errorf("failed to decode the port mappings: %v", err)
+	}
+	return mappings, nil
+}
+
+// ownAssignedPort returns the port which was assigned in place of the same configured port
+// before if a running container of the node still uses it.
+func ownAssignedPort(prevMappings []*PortMapping, mapping *PortMapping, ownPorts map[string]bool) (string, bool) {
+	for _, prev := range prevMappings {
+		if prev.Name == mapping.Name && prev.Configured == mapping.Configured && ownPorts[prev.Effective] {
+			return prev.Effective, true
+		}
+	}
+	return "", false
+}
+
+func portInUseError(name, port string, err error) error {
+	return fmt.Errorf(
+		"%w: %s port %s (%v) - stop the process which uses it (see 'lsof -i :%s'), change the port in the config or set ports.autoAssign: true",
+		ErrPortInUse, name, port, err, port,
+	)
+}
+
+func checkPortFree(host, port string) error {
+	lis, err := net.Listen("tcp", net.JoinHostPort(host, port))
+	if err != nil {
+		return err
+	}
+	return lis.Close()
+}
+
+func findFreePort(host string) (string, error) {
+	lis, err := net.Listen("tcp", net.JoinHostPort(host, "0"))
+	if err != nil {
+		return "", err
+	}
+	defer lis.Close()
+	return strconv.Itoa(lis.Addr().(*net.TCPAddr).Port), nil
+}
@@ -0,0 +1,94 @@
+package config
+
+import (
+	"net"
+	"os"
+	"path"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func testPortsConfig(t *testing.T) (Config, string) {
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	t.Cleanup(func() { lis.Close() })
+	_, busyPort, _ := net.SplitHostPort(lis.Addr().String())
+
+	var cfg Config
+	cfg.FortaDir = t.TempDir()
+	cfg.Health.BindAddr = "127.0.0.1:0"
+	cfg.RunnerConfig.UpdaterPort = "0"
+	cfg.RunnerConfig.ControlPort = busyPort
+	cfg.ApplyEnvDefaults()
+	return cfg, busyPort
+}
+
+func TestConfig_AssignPorts_Busy(t *testing.T) {
+	r := require.New(t)
+
+	cfg, busyPort := testPortsConfig(t)
+	err := cfg.CheckPorts()
+	r.ErrorIs(err, ErrPortInUse)
+	r.Contains(err.Error(), "control port "+busyPort)
+
+	err = cfg.AssignPorts(nil)
+	r.ErrorIs(err, ErrPortInUse)
+	r.Equal(busyPort, cfg.RunnerConfig.ControlPort)
+}
+
+func TestConfig_AssignPorts_AutoAssign(t *testing.T) {
+	r := require.New(t)
+
+	cfg, busyPort := testPortsConfig(t)
+	cfg.Ports.AutoAssign = true
+	r.NoError(cfg.AssignPorts(nil))
+	r.NotEqual(busyPort, cfg.RunnerConfig.ControlPort)
+	r.NoError(cfg.CheckPorts())
+
+	r.Len(cfg.PortMappings, 3)
+	control := cfg.PortMappings[1]
+	r.Equal(PortNameControl, control.Name)
+	r.Equal(busyPort, control.Configured)
+	r.Equal(cfg.RunnerConfig.ControlPort, control.Effective)
+
+	// the assigned port is kept after reloading the same config
+	newCfg := cfg
+	newCfg.PortMappings = nil
+	newCfg.RunnerConfig.ControlPort = busyPort
+	cfg.PrepareReloaded(&newCfg)
+	r.Empty(cfg.ApplyReloadable(newCfg))
+	r.Equal(control.Effective, cfg.RunnerConfig.ControlPort)
+
+	// the cli finds the assigned port
+	r.NoError(cfg.SavePortMappings())
+	cliCfg := newCfg
+	r.NoError(cliCfg.LoadPortMappings())
+	r.Equal(control.Effective, cliCfg.RunnerConfig.ControlPort)
+}
+
+func TestConfig_AssignPorts_OwnPorts(t *testing.T) {
+	r := require.New(t)
+
+	// the adopted container of the node uses the port
+	cfg, busyPort := testPortsConfig(t)
+	r.NoError(cfg.AssignPorts(map[string]bool{busyPort: true}))
+	r.Equal(busyPort, cfg.RunnerConfig.ControlPort)
+
+	// the port which was assigned before is kept while the container of the node uses it
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	r.NoError(err)
+	defer lis.Close()
+	_, assignedPort, _ := net.SplitHostPort(lis.Addr().String())
+	r.NoError(os.WriteFile(path.Join(cfg.FortaDir, DefaultPortMappingsFileName),
+		[]byte(`[{"name":"control","configured":"`+busyPort+`","effective":"`+assignedPort+`"}]`), 0644))
+	cfg.Ports.AutoAssign = true
+	r.NoError(cfg.AssignPorts(map[string]bool{assignedPort: true}))
+	r.Equal(assignedPort, cfg.RunnerConfig.ControlPort)
+
+	// a new port is assigned if the container is gone
+	cfg.RunnerConfig.ControlPort = busyPort
+	r.NoError(cfg.AssignPorts(nil))
+	r.NotEqual(assignedPort, cfg.RunnerConfig.ControlPort)
+	r.NotEqual(busyPort, cfg.RunnerConfig.ControlPort)
+}
@@ -0,0 +1,139 @@
+package config
+
+import (
+	"net/url"
+	"os"
+	"strings"
+
+	"golang.org/x/net/http/httpproxy"
+)
+
+// DefaultDockerNetworkCIDR covers the default address pool of the docker networks.
+const DefaultDockerNetworkCIDR = "172.16.0.0/12"
+
+// standard proxy env vars
+const (
+	envHTTPProxy  = "HTTP_PROXY"
+	envHTTPSProxy = "HTTPS_PROXY"
+	envNoProxy    = "NO_PROXY"
+)
+
+// NetworkConfig configures the outbound connections of the node.
+type NetworkConfig struct {
+	Proxy ProxyConfig `yaml:"proxy" json:"proxy"`
+}
+
+// ProxyConfig configures the HTTP proxy for the outbound requests. The standard proxy env vars
+// (HTTP_PROXY, HTTPS_PROXY, NO_PROXY) are used if no proxy is configured.
+type ProxyConfig struct {
+	HTTPProxy  string `yaml:"httpProxy" json:"httpProxy" validate:"omitempty,url"`
+	HTTPSProxy string `yaml:"httpsProxy" json:"httpsProxy" validate:"omitempty,url"`
+	// NoProxy is the comma separated list of the hosts which are reached directly. The local and
+	// the internal docker destinations are always reached directly.
+	NoProxy string `yaml:"noProxy" json:"noProxy"`
+}
+
+// Enabled tells if a proxy is configured.
+func (cfg ProxyConfig) Enabled() bool {
+	return len(cfg.HTTPProxy) > 0 || len(cfg.HTTPSProxy) > 0
+}
+
+// effective returns the configured proxy or the proxy from the env vars.
+func (cfg ProxyConfig) effective() ProxyConfig {
+	if cfg.Enabled() {
+		return cfg
+	}
+	return ProxyConfig{
+		HTTPProxy:  getProxyEnv(envHTTPProxy),
+		HTTPSProxy: getProxyEnv(envHTTPSProxy),
+		NoProxy:    getProxyEnv(envNoProxy),
+	}
+}
+
+// noProxy adds the internal destinations to the no-proxy list.
+func (cfg ProxyConfig) noProxy() string {
+	var hosts []string
+	if len(cfg.NoProxy) > 0 {
+		hosts = strings.Split(cfg.NoProxy, ",")
+	}
+	for _, host := range []string{
+		"localhost", "127.0.0.1", "host.docker.internal", DefaultDockerNetworkCIDR,
+		DockerNatsContainerName, DockerIpfsContainerName, DockerJSONRPCProxyContainerName,
+		DockerJWTProviderContainerName, DockerStorageContainerName, DockerInspectorContainerName,
+		DockerScannerContainerName, DockerSupervisorContainerName, DockerUpdaterContainerName,
+	} {
+		// the env from the parent container may already include the host
+		if !containsString(hosts, host) {
+			hosts = append(hosts, host)
+		}
+	}
+	return strings.Join(hosts, ",")
+}
+
+// Env returns the proxy env vars to pass to the containers. It is empty if there is no proxy.
+func (cfg ProxyConfig) Env() map[string]string {
+	proxy := cfg.effective()
+	if !proxy.Enabled() {
+		return nil
+	}
+	env := make(map[string]string)
+	for key, value := range map[string]string{
+		envHTTPProxy:  proxy.HTTPProxy,
+		envHTTPSProxy: proxy.HTTPSProxy,
+		envNoProxy:    proxy.noProxy(),
+	} {
+		if len(value) > 0 {
+			env[key] = value
+			env[strings.ToLower(key)] = value
+		}
+	}
+	return env
+}
+
+// AddEnv adds the proxy env vars to the container env.
+func (cfg ProxyConfig) AddEnv(env map[string]string) map[string]string {
+	for key, value := range cfg.Env() {
+		env[key] = value
+	}
+	return env
+}
+
+// ApplyEnv sets the proxy env vars of the current process so that the HTTP clients which use
+// the proxy from the environment pick up the configured proxy. It should be called before making
+// any requests.
+func (cfg ProxyConfig) ApplyEnv() {
+	for key, value := range cfg.Env() {
+		os.Setenv(key, value)
+	}
+}
+
+// ProxyFunc returns the proxy URL for the requests like the HTTP transports do after ApplyEnv.
+func (cfg ProxyConfig) ProxyFunc() func(reqURL *url.URL) (*url.URL, error) {
+	proxy := cfg.effective()
+	if !proxy.Enabled() {
+		return func(reqURL *url.URL) (*url.URL, error) {
+			return nil, nil
+		}
+	}
+	return (&httpproxy.Config{
+		HTTPProxy:  proxy.HTTPProxy,
+		HTTPSProxy: proxy.HTTPSProxy,
+		NoProxy:    proxy.noProxy(),
+	}).ProxyFunc()
+}
+
+func getProxyEnv(key string) string {
+	if value := os.Getenv(key); len(value) > 0 {
+		return value
+	}
+	return os.Getenv(strings.ToLower(key))
+}
+
+func containsString(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,69 @@
+package config
+
+import (
+	"net/url"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func clearProxyEnv(t *testing.T) {
+	for _, key := range []string{"HTTP_PROXY", "HTTPS_PROXY", "NO_PROXY", "http_proxy", "https_proxy", "no_proxy"} {
+		t.Setenv(key, "")
+	}
+}
+
+func proxyURL(r *require.Assertions, cfg ProxyConfig, rawurl string) string {
+	reqURL, err := url.Parse(rawurl)
+	r.NoError(err)
+	proxy, err := cfg.ProxyFunc()(reqURL)
+	r.NoError(err)
+	if proxy == nil {
+		return ""
+	}
+	return proxy.String()
+}
+
+func TestProxyConfig_ProxyFunc(t *testing.T) {
+	r := require.New(t)
+	clearProxyEnv(t)
+
+	cfg := ProxyConfig{
+		HTTPProxy:  "http://proxy.corp:3128",
+		HTTPSProxy: "http://secure-proxy.corp:3128",
+		NoProxy:    "internal.corp",
+	}
+	r.Equal("http://proxy.corp:3128", proxyURL(r, cfg, "http://ipfs.io/ipfs/Qm1"))
+	r.Equal("http://secure-proxy.corp:3128", proxyURL(r, cfg, "https://api.forta.network/graphql"))
+
+	// internal destinations
+	r.Empty(proxyURL(r, cfg, "http://internal.corp/rpc"))
+	r.Empty(proxyURL(r, cfg, "http://host.docker.internal:8090/health"))
+	r.Empty(proxyURL(r, cfg, "http://forta-ipfs:5001/api/v0/add"))
+	r.Empty(proxyURL(r, cfg, "http://172.18.0.5:8545"))
+
+	r.Empty(proxyURL(r, ProxyConfig{}, "http://ipfs.io/ipfs/Qm1"))
+}
+
+func TestProxyConfig_Env(t *testing.T) {
+	r := require.New(t)
+	clearProxyEnv(t)
+
+	r.Nil(ProxyConfig{}.Env())
+
+	env := ProxyConfig{HTTPProxy: "http://proxy.corp:3128"}.AddEnv(map[string]string{EnvLogFormat: "json"})
+	r.Equal("json", env[EnvLogFormat])
+	r.Equal("http://proxy.corp:3128", env["HTTP_PROXY"])
+	r.Equal("http://proxy.corp:3128", env["http_proxy"])
+	r.NotContains(env, "HTTPS_PROXY")
+	r.Contains(env["NO_PROXY"], "host.docker.internal")
+	r.Contains(env["NO_PROXY"], DefaultDockerNetworkCIDR)
+
+	// the proxy from the env is passed on without repeating the no-proxy hosts
+	t.Setenv("HTTPS_PROXY", "http://env-proxy.corp:8080")
+	t.Setenv("NO_PROXY", env["NO_PROXY"])
+	env = ProxyConfig{}.Env()
+	r.Equal("http://env-proxy.corp:8080", env["HTTPS_PROXY"])
+	r.Equal(env["NO_PROXY"], env["no_proxy"])
+	r.Equal(env["NO_PROXY"], ProxyConfig{HTTPProxy: "http://proxy.corp:3128"}.Env()["NO_PROXY"])
+}
@@ -1,9 +1,42 @@
 package config
 
 import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+
 	"github.com/forta-network/forta-core-go/release"
+	"github.com/forta-network/forta-core-go/utils"
+)
+
+// Release info errors
+var (
+	ErrInvalidReleaseInfo     = errors.New("invalid release info")
+	ErrInvalidReleaseCommit   = errors.New("invalid release commit")
+	ErrInvalidReleaseImageRef = errors.New("invalid release image ref")
 )
 
+var (
+	releaseCommitRegexp = regexp.MustCompile(`^[0-9a-f]{40}$`)
+	imageDigestRegexp   = regexp.MustCompile(`^[0-9a-f]{64}$`)
+)
+
+// Release channels
+const (
+	ReleaseChannelStable = "stable"
+	ReleaseChannelBeta   = "beta"
+	ReleaseChannelCanary = "canary"
+)
+
+// releaseChannelRanks orders the channels from the most to the least stable.
+var releaseChannelRanks = map[string]int{
+	ReleaseChannelStable: 0,
+	ReleaseChannelBeta:   1,
+	ReleaseChannelCanary: 2,
+}
+
 // Release vars - injected by the compiler
 var (
 	CommitHash = ""
@@ -37,3 +70,67 @@ func GetBuildReleaseInfo() *release.ReleaseInfo {
 		},
 	}
 }
+
+// GetReleaseChannel returns the channel of the release. Canary versions (e.g. v0.1.2-canary.1)
+// belong to the canary channel and other prerelease versions (e.g. v0.1.2-beta.1) belong to
+// the beta channel.
+func GetReleaseChannel(releaseInfo *release.ReleaseInfo) string {
+	version := releaseInfo.Manifest.Release.Version
+	switch {
+	case strings.Contains(version, "-canary"):
+		return ReleaseChannelCanary
+	case strings.Contains(version, "-"):
+		return ReleaseChannelBeta
+	default:
+		return ReleaseChannelStable
+	}
+}
+
+// MatchesReleaseChannel checks if the release can be used by a node which tracks given channel.
+// Nodes use the releases from their channel and the more stable channels, e.g. beta nodes use
+// both the stable and beta releases.
+func MatchesReleaseChannel(releaseInfo *release.ReleaseInfo, channel string) bool {
+	nodeRank, ok := releaseChannelRanks[channel]
+	if !ok {
+		nodeRank = releaseChannelRanks[ReleaseChannelStable]
+	}
+	return releaseChannelRanks[GetReleaseChannel(releaseInfo)] <= nodeRank
+}
+
+// ParseReleaseInfo parses the release info string which is passed to the containers. Unlike
+// release.ReleaseInfoFromString, it fails if the string is empty or is not valid.
+func ParseReleaseInfo(s string) (*release.ReleaseInfo, error) {
+	if len(s) == 0 {
+		return nil, fmt.Errorf("%w: empty string", ErrInvalidReleaseInfo)
+	}
+	var releaseInfo release.ReleaseInfo
+	if err := json.Unmarshal([]byte(s), &releaseInfo); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidReleaseInfo, err)
+	}
+	return &releaseInfo, nil
+}
+
+// ValidateReleaseInfo checks that the release commit is a full commit hash and that the manifest
+// references the supervisor and the updater images by digest.
+func ValidateReleaseInfo(releaseInfo *release.ReleaseInfo) error {
+	if releaseInfo == nil {
+		return fmt.Errorf("%w: no release info", ErrInvalidReleaseInfo)
+	}
+	commit := releaseInfo.Manifest.Release.Commit
+	if !releaseCommitRegexp.MatchString(commit) {
+		return fmt.Errorf("%w: '%s' is not a commit hash", ErrInvalidReleaseCommit, commit)
+	}
+	services := releaseInfo.Manifest.Release.Services
+	if err := validateReleaseImageRef("supervisor", services.Supervisor); err != nil {
+		return err
+	}
+	return validateReleaseImageRef("updater", services.Updater)
+}
+
+func validateReleaseImageRef(name, ref string) error {
+	repo, digest := utils.SplitImageRef(ref)
+	if len(repo) == 0 || strings.ContainsAny(repo, " \t\n") || !imageDigestRegexp.MatchString(digest) {
+		return fmt.Errorf("%w: %s image '%s' is not referenced by digest", ErrInvalidReleaseImageRef, name, ref)
+	}
+	return nil
+}
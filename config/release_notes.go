@@ -0,0 +1,95 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/forta-network/forta-core-go/release"
+)
+
+// release notes limits
+const (
+	MaxReleaseNotesSize     = 16 * 1024
+	releaseNotesSummaryLen  = 5
+	releaseNotesTruncSuffix = "\n... (truncated)"
+)
+
+// ReleaseNotes are the notes of a release.
+type ReleaseNotes struct {
+	Version   string     `json:"version,omitempty"`
+	Commit    string     `json:"commit,omitempty"`
+	IPFS      string     `json:"ipfs,omitempty"`
+	Notes     string     `json:"notes"`
+	Truncated bool       `json:"truncated,omitempty"`
+	AppliedAt *time.Time `json:"appliedAt,omitempty"`
+}
+
+// releaseNotesManifest contains the optional notes fields of the release manifest.
+type releaseNotesManifest struct {
+	Release struct {
+		Notes     string `json:"notes"`
+		Changelog string `json:"changelog"`
+		NotesIPFS string `json:"notesIpfs"`
+	} `json:"release"`
+}
+
+// ParseReleaseNotes finds the notes in the release manifest. The notes are either included in the
+// notes or the changelog field of the release or are referred by the notes CID. The notes CID is
+// returned if the notes are not included.
+func ParseReleaseNotes(manifest []byte) (notes string, notesRef string, err error) {
+	var rm releaseNotesManifest
+	if err := json.Unmarshal(manifest, &rm); err != nil {
+		return "", "", fmt.Errorf("failed to decode the release manifest: %v", err)
+	}
+	notes = rm.Release.Notes
+	if len(notes) == 0 {
+		notes = rm.Release.Changelog
+	}
+	if len(notes) > 0 {
+		return notes, "", nil
+	}
+	return "", rm.Release.NotesIPFS, nil
+}
+
+// NewReleaseNotes makes the release notes of the release and truncates the oversized notes.
+func NewReleaseNotes(releaseInfo *release.ReleaseInfo, notes string) *ReleaseNotes {
+	releaseNotes := &ReleaseNotes{
+		Version: releaseInfo.Manifest.Release.Version,
+		Commit:  releaseInfo.Manifest.Release.Commit,
+		IPFS:    releaseInfo.IPFS,
+	}
+	releaseNotes.Notes, releaseNotes.Truncated = TruncateReleaseNotes(notes)
+	return releaseNotes
+}
+
+// TruncateReleaseNotes cuts the notes at the max size without splitting a character.
+func TruncateReleaseNotes(notes string) (string, bool) {
+	if len(notes) <= MaxReleaseNotesSize {
+		return notes, false
+	}
+	cut := MaxReleaseNotesSize - len(releaseNotesTruncSuffix)
+	for cut > 0 && !utf8.RuneStart(notes[cut]) {
+		cut--
+	}
+	return notes[:cut] + releaseNotesTruncSuffix, true
+}
+
+// Summary returns the first non-empty lines of the notes.
+func (releaseNotes *ReleaseNotes) Summary() string {
+	var lines []string
+	for _, line := range strings.Split(releaseNotes.Notes, "\n") {
+		line = strings.TrimSpace(line)
+		if len(line) == 0 {
+			continue
+		}
+		if len(lines) == releaseNotesSummaryLen {
+			lines = append(lines, "...")
+			break
+		}
+		lines = append(lines, line)
+	}
+	return strings.Join(lines, " | ")
+}
@@ -0,0 +1,60 @@
+package config
+
+import (
+	"strings"
+	"testing"
+	"unicode/utf8"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseReleaseNotes(t *testing.T) {
+	r := require.New(t)
+
+	notes, notesRef, err := ParseReleaseNotes([]byte(`{"release":{"version":"v0.7.1","notes":"- fixed things"}}`))
+	r.NoError(err)
+	r.Equal("- fixed things", notes)
+	r.Empty(notesRef)
+
+	notes, notesRef, err = ParseReleaseNotes([]byte(`{"release":{"version":"v0.7.1","changelog":"- added things"}}`))
+	r.NoError(err)
+	r.Equal("- added things", notes)
+	r.Empty(notesRef)
+
+	notes, notesRef, err = ParseReleaseNotes([]byte(`{"release":{"version":"v0.7.1","notesIpfs":"cid1"}}`))
+	r.NoError(err)
+	r.Empty(notes)
+	r.Equal("cid1", notesRef)
+
+	// no notes
+	notes, notesRef, err = ParseReleaseNotes([]byte(`{"release":{"version":"v0.7.1","services":{"supervisor":"supervisor1"}}}`))
+	r.NoError(err)
+	r.Empty(notes)
+	r.Empty(notesRef)
+
+	_, _, err = ParseReleaseNotes([]byte(`{"release":`))
+	r.Error(err)
+}
+
+func TestNewReleaseNotes(t *testing.T) {
+	r := require.New(t)
+
+	releaseInfo := testReleaseInfo("v0.7.1")
+	releaseInfo.IPFS = "cid1"
+	notes := NewReleaseNotes(releaseInfo, "- fixed things\n\n- added things\n")
+	r.Equal("v0.7.1", notes.Version)
+	r.Equal("cid1", notes.IPFS)
+	r.False(notes.Truncated)
+	r.Equal("- fixed things | - added things", notes.Summary())
+
+	// oversized notes are truncated without splitting the characters
+	oversized := strings.Repeat("ü", MaxReleaseNotesSize)
+	notes = NewReleaseNotes(releaseInfo, oversized)
+	r.True(notes.Truncated)
+	r.LessOrEqual(len(notes.Notes), MaxReleaseNotesSize)
+	r.True(utf8.ValidString(notes.Notes))
+	r.True(strings.HasSuffix(notes.Notes, releaseNotesTruncSuffix))
+
+	notes = NewReleaseNotes(releaseInfo, strings.Repeat("line\n", 10))
+	r.Equal("line | line | line | line | line | ...", notes.Summary())
+}
@@ -0,0 +1,120 @@
+package config
+
+import (
+	"testing"
+
+	"github.com/forta-network/forta-core-go/release"
+	"github.com/stretchr/testify/require"
+)
+
+func testReleaseInfo(version string) *release.ReleaseInfo {
+	return &release.ReleaseInfo{
+		Manifest: release.ReleaseManifest{
+			Release: release.Release{Version: version},
+		},
+	}
+}
+
+func TestMatchesReleaseChannel(t *testing.T) {
+	r := require.New(t)
+
+	stable := testReleaseInfo("v0.7.1")
+	beta := testReleaseInfo("v0.7.2-beta.1")
+	canary := testReleaseInfo("v0.7.3-canary.1")
+
+	r.Equal(ReleaseChannelStable, GetReleaseChannel(stable))
+	r.Equal(ReleaseChannelBeta, GetReleaseChannel(beta))
+	r.Equal(ReleaseChannelCanary, GetReleaseChannel(canary))
+
+	r.True(MatchesReleaseChannel(stable, ReleaseChannelStable))
+	r.False(MatchesReleaseChannel(beta, ReleaseChannelStable))
+	r.True(MatchesReleaseChannel(stable, ReleaseChannelBeta))
+	r.True(MatchesReleaseChannel(beta, ReleaseChannelBeta))
+	r.False(MatchesReleaseChannel(canary, ReleaseChannelBeta))
+	r.True(MatchesReleaseChannel(stable, ReleaseChannelCanary))
+	r.True(MatchesReleaseChannel(beta, ReleaseChannelCanary))
+	r.True(MatchesReleaseChannel(canary, ReleaseChannelCanary))
+
+	// unknown channels are treated as stable
+	r.True(MatchesReleaseChannel(stable, "unknown"))
+	r.False(MatchesReleaseChannel(beta, "unknown"))
+}
+
+func TestAutoUpdateConfig_ReleaseChannel(t *testing.T) {
+	r := require.New(t)
+
+	r.Equal(ReleaseChannelStable, AutoUpdateConfig{}.ReleaseChannel())
+	r.Equal(ReleaseChannelBeta, AutoUpdateConfig{TrackPrereleases: true}.ReleaseChannel())
+	r.Equal(ReleaseChannelStable, AutoUpdateConfig{TrackPrereleases: true, Channel: ReleaseChannelStable}.ReleaseChannel())
+	r.Equal(ReleaseChannelBeta, AutoUpdateConfig{Channel: ReleaseChannelBeta}.ReleaseChannel())
+	r.Equal(ReleaseChannelCanary, AutoUpdateConfig{Channel: ReleaseChannelCanary}.ReleaseChannel())
+}
+
+const (
+	testReleaseCommit     = "4e9b5b8e1e4b5d2a3c1f0e9d8c7b6a5f4e3d2c1b"
+	testReleaseSupervisor = "disco.forta.network/bafybeielvnt5apaxbk6chthc4dc3p6vscpx3ai4uvti7gwh253j7facsxu@sha256:e0e7cd6b3c8ac4b8e3b9f9d6d4f0c1f1b8c5a6a1e1b4b6c2e4f3d9a1b2c3d4e5"
+	testReleaseUpdater    = "disco.forta.network/bafybeielvnt5apaxbk6chthc4dc3p6vscpx3ai4uvti7gwh253j7facsxu@sha256:f1f8de7c4d9bd5c9f4c0a0e7e5a1d2a2c9d6b7b2f2c5c7d3f5a4e0b2c3d4e5f6"
+)
+
+func testValidReleaseInfo() *release.ReleaseInfo {
+	return &release.ReleaseInfo{
+		IPFS: "bafybeielvnt5apaxbk6chthc4dc3p6vscpx3ai4uvti7gwh253j7facsxu",
+		Manifest: release.ReleaseManifest{
+			Release: release.Release{
+				Timestamp:  "2022-12-01T00:00:00Z",
+				Repository: "https://github.com/forta-network/forta-node",
+				Version:    "v0.7.1",
+				Commit:     testReleaseCommit,
+				Services: release.ReleaseServices{
+					Supervisor: testReleaseSupervisor,
+					Updater:    testReleaseUpdater,
+				},
+			},
+		},
+	}
+}
+
+func TestParseReleaseInfo(t *testing.T) {
+	r := require.New(t)
+
+	// round trip through the string which is passed to the containers
+	releaseInfo := testValidReleaseInfo()
+	parsed, err := ParseReleaseInfo(releaseInfo.String())
+	r.NoError(err)
+	r.Equal(releaseInfo, parsed)
+	r.Equal(releaseInfo.String(), parsed.String())
+
+	for _, s := range []string{"", "{", "null-ish", `{"manifest": "foo"}`} {
+		_, err = ParseReleaseInfo(s)
+		r.ErrorIs(err, ErrInvalidReleaseInfo, s)
+	}
+}
+
+func TestValidateReleaseInfo(t *testing.T) {
+	r := require.New(t)
+
+	r.NoError(ValidateReleaseInfo(testValidReleaseInfo()))
+	r.ErrorIs(ValidateReleaseInfo(nil), ErrInvalidReleaseInfo)
+
+	for _, commit := range []string{"", "4e9b5b8", testReleaseCommit + "00", "4E9B5B8E1E4B5D2A3C1F0E9D8C7B6A5F4E3D2C1B", "zz9b5b8e1e4b5d2a3c1f0e9d8c7b6a5f4e3d2c1b"} {
+		releaseInfo := testValidReleaseInfo()
+		releaseInfo.Manifest.Release.Commit = commit
+		r.ErrorIs(ValidateReleaseInfo(releaseInfo), ErrInvalidReleaseCommit, commit)
+	}
+
+	for _, ref := range []string{
+		"",
+		"forta-network/forta-node:latest",
+		"@sha256:e0e7cd6b3c8ac4b8e3b9f9d6d4f0c1f1b8c5a6a1e1b4b6c2e4f3d9a1b2c3d4e5",
+		"disco.forta.network/bafybei@sha256:e0e7cd",
+		"disco.forta.network/bafybei@sha256:zze7cd6b3c8ac4b8e3b9f9d6d4f0c1f1b8c5a6a1e1b4b6c2e4f3d9a1b2c3d4e5",
+	} {
+		releaseInfo := testValidReleaseInfo()
+		releaseInfo.Manifest.Release.Services.Supervisor = ref
+		r.ErrorIs(ValidateReleaseInfo(releaseInfo), ErrInvalidReleaseImageRef, ref)
+
+		releaseInfo = testValidReleaseInfo()
+		releaseInfo.Manifest.Release.Services.Updater = ref
+		r.ErrorIs(ValidateReleaseInfo(releaseInfo), ErrInvalidReleaseImageRef, ref)
+	}
+}
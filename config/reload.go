@@ -0,0 +1,46 @@
+package config
+
+import (
+	"reflect"
+	"strings"
+)
+
+// PrepareReloaded fills the new config from the file with the runtime values and the env defaults
+// of the running config so that it can be validated and compared with the running config.
+func (cfg *Config) PrepareReloaded(newCfg *Config) {
+	newCfg.Development = cfg.Development
+	newCfg.FortaDir = cfg.FortaDir
+	newCfg.RemoteConfig = cfg.RemoteConfig
+	newCfg.KeyDirPath = cfg.KeyDirPath
+	newCfg.Passphrase = cfg.Passphrase
+	newCfg.ApplyEnvDefaults()
+	// the proxy env and the instance name only change the process state so only the signer
+	// env changes the config
+	newCfg.Signer.ApplyEnv()
+	newCfg.applyPortMappings(cfg.PortMappings)
+}
+
+// ApplyReloadable copies the settings which are safe to apply without a restart from the new config
+// and returns the names of the changed settings which still require a restart. The new config should
+// be prepared with PrepareReloaded first.
+func (cfg *Config) ApplyReloadable(newCfg Config) (requiresRestart []string) {
+	cfg.Log.Level = newCfg.Log.Level
+	cfg.Log.Levels = newCfg.Log.Levels
+	cfg.Log.Format = newCfg.Log.Format
+	cfg.RunnerConfig.LivenessCheckIntervalSeconds = newCfg.RunnerConfig.LivenessCheckIntervalSeconds
+	cfg.AutoUpdate.Channel = newCfg.AutoUpdate.Channel
+	cfg.AutoUpdate.TrackPrereleases = newCfg.AutoUpdate.TrackPrereleases
+
+	oldVal := reflect.ValueOf(*cfg)
+	newVal := reflect.ValueOf(newCfg)
+	for i := 0; i < oldVal.NumField(); i++ {
+		name := strings.SplitN(oldVal.Type().Field(i).Tag.Get("yaml"), ",", 2)[0]
+		if name == "-" {
+			continue
+		}
+		if !reflect.DeepEqual(oldVal.Field(i).Interface(), newVal.Field(i).Interface()) {
+			requiresRestart = append(requiresRestart, name)
+		}
+	}
+	return
+}
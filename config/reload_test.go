@@ -0,0 +1,54 @@
+package config
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestConfig_ApplyReloadable(t *testing.T) {
+	r := require.New(t)
+
+	cfg := Config{FortaDir: "/root/.forta"}
+	cfg.Log.Level = "info"
+	cfg.RunnerConfig.LivenessCheckIntervalSeconds = 10
+	cfg.Scan.JsonRpc.Url = "http://rpc1"
+	cfg.ApplyEnvDefaults()
+
+	newCfg := cfg
+	newCfg.FortaDir = ""
+	newCfg.Log.Level = "debug"
+	newCfg.RunnerConfig.LivenessCheckIntervalSeconds = 30
+	newCfg.AutoUpdate.Channel = ReleaseChannelCanary
+	cfg.PrepareReloaded(&newCfg)
+	r.Empty(cfg.ApplyReloadable(newCfg))
+	r.Equal("debug", cfg.Log.Level)
+	r.Equal(30, cfg.RunnerConfig.LivenessCheckIntervalSeconds)
+	r.Equal(ReleaseChannelCanary, cfg.AutoUpdate.ReleaseChannel())
+	r.Equal("/root/.forta", cfg.FortaDir)
+
+	newCfg.Log.Level = "warn"
+	newCfg.Scan.JsonRpc.Url = "http://rpc2"
+	newCfg.Log.MaxLogFiles = 20
+	cfg.PrepareReloaded(&newCfg)
+	r.Equal([]string{"scan", "log"}, cfg.ApplyReloadable(newCfg))
+	r.Equal("warn", cfg.Log.Level)
+	r.Equal("http://rpc1", cfg.Scan.JsonRpc.Url)
+}
+
+func TestConfig_ApplyReloadableEnvOverrides(t *testing.T) {
+	r := require.New(t)
+
+	t.Setenv(EnvSignerAuthToken, "token1")
+	var cfg Config
+	cfg.Signer.Type = SignerTypeRemote
+	cfg.ApplyEnvDefaults()
+	cfg.Signer.ApplyEnv()
+	r.Equal("token1", cfg.Signer.Remote.AuthToken)
+
+	// the same config from the file does not have the env values
+	var newCfg Config
+	newCfg.Signer.Type = SignerTypeRemote
+	cfg.PrepareReloaded(&newCfg)
+	r.Empty(cfg.ApplyReloadable(newCfg))
+}
@@ -0,0 +1,129 @@
+package config
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/creasty/defaults"
+	log "github.com/sirupsen/logrus"
+	"gopkg.in/yaml.v3"
+)
+
+const defaultRemoteConfigTimeout = time.Second * 30
+
+// IsRemoteConfigPath tells if the config path is an http(s) URL.
+func IsRemoteConfigPath(configPath string) bool {
+	return strings.HasPrefix(configPath, "http://") || strings.HasPrefix(configPath, "https://")
+}
+
+// AddRemoteConfigEnv makes the containers read the last-good remote config if the node is
+// configured from the remote config URL.
+func (cfg Config) AddRemoteConfigEnv(env map[string]string) map[string]string {
+	if cfg.RemoteConfig {
+		env[EnvRemoteConfig] = "true"
+	}
+	return env
+}
+
+// FetchRemoteConfig fetches the YAML config from given URL and caches it to the cache path
+// if it is valid. If fetching fails, the last-good config from the cache is returned.
+func FetchRemoteConfig(ctx context.Context, configURL, token, cachePath string) ([]byte, error) {
+	logger := log.WithField("url", configURL)
+
+	b, err := fetchRemoteConfig(ctx, configURL, token)
+	if err == nil {
+		err = validateRemoteConfig(b)
+	}
+	if err == nil {
+		if err := writeRemoteConfigCache(cachePath, b); err != nil {
+			logger.WithError(err).Warn("failed to cache the remote config")
+		}
+		return b, nil
+	}
+
+	logger.WithError(err).Warn("failed to get the remote config - trying the last-good config")
+	cached, cacheErr := os.ReadFile(cachePath)
+	if cacheErr != nil {
+		return nil, fmt.Errorf("failed to get the remote config: %v (no cached config: %v)", err, cacheErr)
+	}
+	return cached, nil
+}
+
+// GetConfigFromURL fetches, decodes and validates the config from given URL.
+func GetConfigFromURL(ctx context.Context, configURL, token, cachePath string) (Config, error) {
+	b, err := FetchRemoteConfig(ctx, configURL, token, cachePath)
+	if err != nil {
+		return Config{}, err
+	}
+	return decodeConfig(b)
+}
+
+func fetchRemoteConfig(ctx context.Context, configURL, token string) ([]byte, error) {
+	ctx, cancel := context.WithTimeout(ctx, defaultRemoteConfigTimeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, configURL, nil)
+	if err != nil {
+		return nil, err
+	}
+	if len(token) > 0 {
+		req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", token))
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	b, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected status code %d", resp.StatusCode)
+	}
+	return b, nil
+}
+
+func validateRemoteConfig(b []byte) error {
+	_, err := decodeConfig(b)
+	return err
+}
+
+func decodeConfig(b []byte) (Config, error) {
+	var cfg Config
+	if err := yaml.NewDecoder(bytes.NewReader(b)).Decode(&cfg); err != nil {
+		return Config{}, fmt.Errorf("failed to decode config: %v", err)
+	}
+	if err := defaults.Set(&cfg); err != nil {
+		return Config{}, err
+	}
+	cfg.ApplyEnvDefaults()
+	if err := cfg.Validate(); err != nil {
+		return Config{}, fmt.Errorf("invalid config: %v", err)
+	}
+	return cfg, nil
+}
+
+// writes to a temp file first so that a crash does not leave a partial config behind
+func writeRemoteConfigCache(cachePath string, b []byte) error {
+	if err := os.MkdirAll(path.Dir(cachePath), 0755); err != nil {
+		return err
+	}
+	// the remote config can contain credentials so only the owner can read it and the mode of
+	// a leftover tmp file is not reused
+	tmpPath := cachePath + ".tmp"
+	if err := os.Remove(tmpPath); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	if err := os.WriteFile(tmpPath, b, 0600); err != nil {
+		return err
+	}
+	return os.Rename(tmpPath, cachePath)
+}
@@ -0,0 +1,95 @@
+package config
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	testRemoteConfig = `chainId: 137
+scan:
+  jsonRpc:
+    url: https://polygon-rpc.com
+publish:
+  ipfs:
+    gatewayUrl: https://ipfs.forta.network
+`
+	testRemoteToken = "token1"
+)
+
+func TestIsRemoteConfigPath(t *testing.T) {
+	r := require.New(t)
+
+	r.True(IsRemoteConfigPath("https://config.example.com/config.yml"))
+	r.True(IsRemoteConfigPath("http://config.example.com/config.yml"))
+	r.False(IsRemoteConfigPath("/root/.forta/config.yml"))
+}
+
+func TestGetConfigFromURL(t *testing.T) {
+	r := require.New(t)
+
+	fail := false
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		if req.Header.Get("Authorization") != "Bearer "+testRemoteToken {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		if fail {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.Write([]byte(testRemoteConfig))
+	}))
+	defer server.Close()
+
+	cachePath := path.Join(t.TempDir(), DefaultRemoteConfigFileName)
+
+	cfg, err := GetConfigFromURL(context.Background(), server.URL, testRemoteToken, cachePath)
+	r.NoError(err)
+	r.Equal(137, cfg.ChainID)
+	r.Equal("https://polygon-rpc.com", cfg.Scan.JsonRpc.Url)
+	r.Equal(200, cfg.Scan.BlockRateLimit)
+
+	cached, err := os.ReadFile(cachePath)
+	r.NoError(err)
+	r.Equal(testRemoteConfig, string(cached))
+	info, err := os.Stat(cachePath)
+	r.NoError(err)
+	r.Equal(os.FileMode(0600), info.Mode().Perm())
+
+	// falls back to the last-good config
+	fail = true
+	cfg, err = GetConfigFromURL(context.Background(), server.URL, testRemoteToken, cachePath)
+	r.NoError(err)
+	r.Equal(137, cfg.ChainID)
+
+	// fails without a cached config
+	_, err = GetConfigFromURL(context.Background(), server.URL, testRemoteToken, cachePath+".other")
+	r.Error(err)
+
+	// fails with bad token
+	_, err = FetchRemoteConfig(context.Background(), server.URL, "bad-token", cachePath+".other")
+	r.Error(err)
+}
+
+func TestFetchRemoteConfig_InvalidNotCached(t *testing.T) {
+	r := require.New(t)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		w.Write([]byte("scan:\n  jsonRpc:\n    url: not-a-url\n"))
+	}))
+	defer server.Close()
+
+	cachePath := path.Join(t.TempDir(), DefaultRemoteConfigFileName)
+	_, err := FetchRemoteConfig(context.Background(), server.URL, "", cachePath)
+	r.Error(err)
+
+	_, err = os.Stat(cachePath)
+	r.True(os.IsNotExist(err))
+}
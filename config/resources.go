@@ -1,5 +1,7 @@
 package config
 
+import "path"
+
 // AgentResourceLimits contain the agent resource limits data.
 type AgentResourceLimits struct {
 	CPUQuota int64 // in microseconds
@@ -22,12 +24,57 @@ func GetAgentResourceLimits(resourcesCfg ResourcesConfig) *AgentResourceLimits {
 
 	limits.Memory = getDefaultMemoryPerAgent()
 	if resourcesCfg.AgentMaxMemoryMiB > 0 {
-		limits.Memory = int64(resourcesCfg.AgentMaxMemoryMiB * 104858)
+		limits.Memory = MiBToBytes(resourcesCfg.AgentMaxMemoryMiB)
 	}
 
 	return &limits
 }
 
+// AgentResourceLimits returns the resource limits of the agent container. The limits of the
+// agent take precedence over the agent defaults and the node-wide limits.
+func (cfg *Config) AgentResourceLimits(agent AgentConfig) *AgentResourceLimits {
+	limits := GetAgentResourceLimits(cfg.ResourcesConfig)
+	if cfg.ResourcesConfig.DisableAgentLimits {
+		return limits
+	}
+
+	cpus, memoryMiB := cfg.Agent.Defaults.CPULimit, cfg.Agent.Defaults.MemoryLimit
+	if agent.CPULimit > 0 {
+		cpus = agent.CPULimit
+	}
+	if agent.MemoryLimit > 0 {
+		memoryMiB = agent.MemoryLimit
+	}
+	if cpus > 0 {
+		limits.CPUQuota = CPUsToMicroseconds(cpus)
+	}
+	if memoryMiB > 0 {
+		limits.Memory = MiBToBytes(memoryMiB)
+	}
+	return limits
+}
+
+// SetAgentLimits sets the individual limits from the config to the agent config.
+func (cfg *Config) SetAgentLimits(agent *AgentConfig) {
+	for _, agentLimits := range cfg.Agent.Limits {
+		if agentLimits.AgentID != agent.ID {
+			continue
+		}
+		if agentLimits.CPULimit > 0 {
+			agent.CPULimit = agentLimits.CPULimit
+		}
+		if agentLimits.MemoryLimit > 0 {
+			agent.MemoryLimit = agentLimits.MemoryLimit
+		}
+		return
+	}
+}
+
+// MiBToBytes converts given MiB amount to bytes.
+func MiBToBytes(mib int) int64 {
+	return int64(mib) * 1024 * 1024
+}
+
 // CPUsToMicroseconds converts given CPU amount to microseconds.
 func CPUsToMicroseconds(cpus float64) int64 {
 	return int64(cpus * float64(100000))
@@ -42,3 +89,8 @@ func getDefaultCPUQuotaPerAgent() int64 {
 func getDefaultMemoryPerAgent() int64 {
 	return 1048580000 // 1000 MiB in bytes
 }
+
+// PullLocksDir returns the dir of the lock files which limit the concurrent image pulls.
+func PullLocksDir(fortaDir string) string {
+	return path.Join(fortaDir, DefaultPullLocksDirName)
+}
@@ -0,0 +1,43 @@
+package config
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestAgentResourceLimits(t *testing.T) {
+	r := require.New(t)
+
+	var cfg Config
+	agent := AgentConfig{ID: "agent-1"}
+	limits := cfg.AgentResourceLimits(agent)
+	r.Equal(getDefaultCPUQuotaPerAgent(), limits.CPUQuota)
+	r.Equal(getDefaultMemoryPerAgent(), limits.Memory)
+
+	cfg.ResourcesConfig.AgentMaxMemoryMiB = 300
+	r.Equal(MiBToBytes(300), cfg.AgentResourceLimits(agent).Memory)
+
+	// the agent defaults take precedence over the node-wide limits
+	cfg.Agent.Defaults = AgentLimitsConfig{CPULimit: 0.5, MemoryLimit: 400}
+	limits = cfg.AgentResourceLimits(agent)
+	r.Equal(int64(50000), limits.CPUQuota)
+	r.Equal(MiBToBytes(400), limits.Memory)
+
+	// the individual limits take precedence over the defaults
+	cfg.Agent.Limits = []AgentLimitsConfig{
+		{AgentID: "agent-2", CPULimit: 2},
+		{AgentID: "agent-1", CPULimit: 1},
+	}
+	cfg.SetAgentLimits(&agent)
+	r.Equal(1.0, agent.CPULimit)
+	r.Zero(agent.MemoryLimit)
+	limits = cfg.AgentResourceLimits(agent)
+	r.Equal(int64(100000), limits.CPUQuota)
+	r.Equal(MiBToBytes(400), limits.Memory)
+
+	cfg.ResourcesConfig.DisableAgentLimits = true
+	limits = cfg.AgentResourceLimits(agent)
+	r.Zero(limits.CPUQuota)
+	r.Zero(limits.Memory)
+}
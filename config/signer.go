@@ -0,0 +1,70 @@
+package config
+
+import (
+	"errors"
+	"os"
+	"time"
+)
+
+// Signer types
+const (
+	SignerTypeLocal    = "local"
+	SignerTypeRemote   = "remote"
+	SignerTypeReadOnly = "read-only"
+)
+
+// EnvSignerAuthToken overrides the remote signer auth token in the config file.
+const EnvSignerAuthToken = "FORTA_SIGNER_AUTH_TOKEN"
+
+// SignerConfig configures how the alert batches are signed. The scanner key in the key dir is
+// used by default and it is still needed for signing the alerts and the registry transactions.
+type SignerConfig struct {
+	Type   string             `yaml:"type" json:"type" validate:"omitempty,oneof=local remote read-only"`
+	Remote RemoteSignerConfig `yaml:"remote" json:"remote"`
+}
+
+// RemoteSignerConfig configures the web3signer compatible signer which holds the scanner key.
+type RemoteSignerConfig struct {
+	URL       string `yaml:"url" json:"url" validate:"omitempty,url"`
+	AuthToken string `yaml:"authToken" json:"authToken"`
+	// Address is the address of the scanner key in the remote signer.
+	Address string `yaml:"address" json:"address" validate:"omitempty,eth_addr"`
+
+	Timeout time.Duration `yaml:"timeout" json:"timeout" default:"5s" validate:"min=0"`
+	// MaxAttempts is the number of the signing attempts before the signer is considered unreachable.
+	MaxAttempts int           `yaml:"maxAttempts" json:"maxAttempts" default:"3" validate:"min=0"`
+	RetryWait   time.Duration `yaml:"retryWait" json:"retryWait" default:"1s" validate:"min=0"`
+}
+
+// SignerType returns the configured signer type or the local signer type if not set.
+func (cfg SignerConfig) SignerType() string {
+	if len(cfg.Type) == 0 {
+		return SignerTypeLocal
+	}
+	return cfg.Type
+}
+
+// ApplyEnv overrides the auth token with the env var if it is set.
+func (cfg *SignerConfig) ApplyEnv() {
+	if token := os.Getenv(EnvSignerAuthToken); len(token) > 0 {
+		cfg.Remote.AuthToken = token
+	}
+}
+
+// AddEnv passes the auth token env var of the current process to the container env.
+func (cfg SignerConfig) AddEnv(env map[string]string) map[string]string {
+	if token := os.Getenv(EnvSignerAuthToken); len(token) > 0 {
+		env[EnvSignerAuthToken] = token
+	}
+	return env
+}
+
+func (cfg SignerConfig) validate() error {
+	if cfg.SignerType() != SignerTypeRemote {
+		return nil
+	}
+	if len(cfg.Remote.URL) == 0 || len(cfg.Remote.Address) == 0 {
+		return errors.New("signer.remote.url and signer.remote.address are required when signer.type is remote")
+	}
+	return nil
+}
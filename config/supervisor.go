@@ -0,0 +1,11 @@
+package config
+
+// SupervisorConfig configures how the runner manages the supervisor.
+type SupervisorConfig struct {
+	// External makes the runner only aggregate the health of the supervisor which is managed
+	// outside of the runner (e.g. as a systemd unit or a k8s deployment). The runner does not
+	// start, update or restart the supervisor and the updater then.
+	External bool `yaml:"external" json:"external"`
+	// HealthURL is the health endpoint of the external supervisor (e.g. http://10.0.0.5:8090/health).
+	HealthURL string `yaml:"healthUrl" json:"healthUrl" validate:"required_if=External true,omitempty,url"`
+}
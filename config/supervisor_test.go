@@ -0,0 +1,23 @@
+package config
+
+import (
+	"testing"
+
+	"github.com/creasty/defaults"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSupervisorConfig(t *testing.T) {
+	r := require.New(t)
+
+	var cfg Config
+	r.NoError(defaults.Set(&cfg))
+	cfg.ApplyEnvDefaults()
+	r.NoError(cfg.Validate())
+
+	// the external supervisor needs the health url
+	cfg.Supervisor.External = true
+	r.Error(cfg.Validate())
+	cfg.Supervisor.HealthURL = "http://10.0.0.5:8090/health"
+	r.NoError(cfg.Validate())
+}
@@ -0,0 +1,74 @@
+package config
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+const updateWindowTimeLayout = "15:04"
+
+var weekdays = map[string]time.Weekday{
+	"sun": time.Sunday,
+	"mon": time.Monday,
+	"tue": time.Tuesday,
+	"wed": time.Wednesday,
+	"thu": time.Thursday,
+	"fri": time.Friday,
+	"sat": time.Saturday,
+}
+
+// UpdateWindowConfig is the daily or weekly time range in which the auto-updates are allowed.
+// The window can span midnight (e.g. 22:00-02:00) and in that case the days refer to the
+// day the window starts.
+type UpdateWindowConfig struct {
+	Days     []string `yaml:"days" json:"days" validate:"dive,oneof=sun mon tue wed thu fri sat"`
+	Start    string   `yaml:"start" json:"start" validate:"required,datetime=15:04"`
+	End      string   `yaml:"end" json:"end" validate:"required,datetime=15:04"`
+	Timezone string   `yaml:"timezone" json:"timezone" default:"UTC"`
+}
+
+// Contains checks if given time is inside the window.
+func (window *UpdateWindowConfig) Contains(t time.Time) (bool, error) {
+	loc, err := time.LoadLocation(window.Timezone)
+	if err != nil {
+		return false, fmt.Errorf("invalid update window timezone: %v", err)
+	}
+	start, err := time.Parse(updateWindowTimeLayout, window.Start)
+	if err != nil {
+		return false, fmt.Errorf("invalid update window start: %v", err)
+	}
+	end, err := time.Parse(updateWindowTimeLayout, window.End)
+	if err != nil {
+		return false, fmt.Errorf("invalid update window end: %v", err)
+	}
+
+	t = t.In(loc)
+	minuteOfDay := t.Hour()*60 + t.Minute()
+	startMinute := start.Hour()*60 + start.Minute()
+	endMinute := end.Hour()*60 + end.Minute()
+
+	if startMinute <= endMinute {
+		return minuteOfDay >= startMinute && minuteOfDay < endMinute && window.allowsDay(t.Weekday()), nil
+	}
+	// spans midnight
+	if minuteOfDay >= startMinute {
+		return window.allowsDay(t.Weekday()), nil
+	}
+	if minuteOfDay < endMinute {
+		return window.allowsDay((t.Weekday() + 6) % 7), nil
+	}
+	return false, nil
+}
+
+func (window *UpdateWindowConfig) allowsDay(day time.Weekday) bool {
+	if len(window.Days) == 0 {
+		return true
+	}
+	for _, allowedDay := range window.Days {
+		if weekdays[strings.ToLower(allowedDay)] == day {
+			return true
+		}
+	}
+	return false
+}
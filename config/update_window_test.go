@@ -0,0 +1,59 @@
+package config
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestUpdateWindowConfig_Contains(t *testing.T) {
+	r := require.New(t)
+
+	// 2022-12-05 is a Monday
+	at := func(day int, hour, minute int) time.Time {
+		return time.Date(2022, 12, day, hour, minute, 0, 0, time.UTC)
+	}
+
+	daily := &UpdateWindowConfig{Start: "02:00", End: "04:00", Timezone: "UTC"}
+	for _, testCase := range []struct {
+		t        time.Time
+		expected bool
+	}{
+		{at(5, 1, 59), false},
+		{at(5, 2, 0), true},
+		{at(5, 3, 59), true},
+		{at(5, 4, 0), false},
+	} {
+		inWindow, err := daily.Contains(testCase.t)
+		r.NoError(err)
+		r.Equal(testCase.expected, inWindow, testCase.t.String())
+	}
+
+	// spans midnight and starts only on saturdays
+	weekly := &UpdateWindowConfig{Days: []string{"sat"}, Start: "22:00", End: "02:00", Timezone: "UTC"}
+	for _, testCase := range []struct {
+		t        time.Time
+		expected bool
+	}{
+		{at(10, 21, 59), false},
+		{at(10, 23, 0), true},
+		{at(11, 1, 0), true},
+		{at(11, 2, 0), false},
+		{at(11, 23, 0), false},
+		{at(5, 1, 0), false},
+	} {
+		inWindow, err := weekly.Contains(testCase.t)
+		r.NoError(err)
+		r.Equal(testCase.expected, inWindow, testCase.t.String())
+	}
+
+	// timezone
+	tz := &UpdateWindowConfig{Start: "02:00", End: "04:00", Timezone: "Europe/Istanbul"}
+	inWindow, err := tz.Contains(at(5, 0, 30))
+	r.NoError(err)
+	r.True(inWindow)
+
+	_, err = (&UpdateWindowConfig{Start: "02:00", End: "04:00", Timezone: "Bad/Zone"}).Contains(at(5, 0, 30))
+	r.Error(err)
+}
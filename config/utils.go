@@ -4,7 +4,6 @@ import (
 	"math/big"
 	"os"
 
-	log "github.com/sirupsen/logrus"
 	"gopkg.in/yaml.v3"
 )
 
@@ -16,22 +15,6 @@ func ParseBigInt(num int) *big.Int {
 	return val
 }
 
-func InitLogLevel(cfg Config) error {
-	if cfg.Log.Level != "" {
-		lvl, err := log.ParseLevel(cfg.Log.Level)
-		if err != nil {
-			return err
-		}
-		log.SetLevel(lvl)
-	} else {
-		log.SetLevel(log.InfoLevel)
-	}
-	log.SetFormatter(&log.TextFormatter{
-		FullTimestamp: true,
-	})
-	return nil
-}
-
 func readFile(filename string, cfg *Config) error {
 	f, err := os.Open(filename)
 	if f != nil {
@@ -0,0 +1,92 @@
+package config
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/forta-network/forta-core-go/utils"
+	"github.com/go-playground/validator/v10"
+)
+
+// Validate validates the config values. The returned error is a validator.ValidationErrors
+// if some of the fields are invalid or missing. Otherwise, it is an ImageRefError if the
+// strict image refs are enabled and a built-in image ref is invalid, or an error about
+// the missing primary scan API.
+func (cfg *Config) Validate() error {
+	validate := validator.New()
+
+	// Use the YAML names while validating the struct.
+	validate.RegisterTagNameFunc(func(fld reflect.StructField) string {
+		name := strings.SplitN(fld.Tag.Get("yaml"), ",", 2)[0]
+		if name == "-" {
+			return ""
+		}
+		return name
+	})
+	validate.RegisterValidation("env_name", func(fl validator.FieldLevel) bool {
+		return envNameRegexp.MatchString(fl.Field().String())
+	})
+	validate.RegisterValidation("container_label", func(fl validator.FieldLevel) bool {
+		return isCustomContainerLabel(fl.Field().String())
+	})
+	validate.RegisterValidation("env_profile", func(fl validator.FieldLevel) bool {
+		_, ok := GetEnvProfileDefaults(fl.Field().String())
+		return ok
+	})
+
+	if err := validate.Struct(cfg); err != nil {
+		return err
+	}
+	if err := cfg.validateImageRefs(); err != nil {
+		return err
+	}
+	if err := cfg.Scan.validateArchive(); err != nil {
+		return err
+	}
+	return cfg.Signer.validate()
+}
+
+// ImageRefError is returned when an image ref is not a valid disco ref.
+type ImageRefError struct {
+	Name string
+	Ref  string
+	Err  error
+}
+
+func (e *ImageRefError) Error() string {
+	return fmt.Sprintf("invalid %s image ref '%s': %v", e.Name, e.Ref, e.Err)
+}
+
+func (e *ImageRefError) Unwrap() error {
+	return e.Err
+}
+
+// validateImageRefs pre-checks the image refs which are built into the binary so that
+// the typos are detected before trying to pull them.
+func (cfg *Config) validateImageRefs() error {
+	if !cfg.Registry.StrictImageRefs || cfg.Development || UseDockerImages != "remote" {
+		return nil
+	}
+	for _, image := range []struct {
+		name string
+		ref  string
+	}{
+		{name: "supervisor", ref: DockerSupervisorImage},
+		{name: "updater", ref: DockerUpdaterImage},
+	} {
+		if _, err := utils.ValidateDiscoImageRef(cfg.Registry.ContainerRegistry, image.ref); err != nil {
+			return &ImageRefError{Name: image.name, Ref: image.ref, Err: err}
+		}
+	}
+	return nil
+}
+
+// reservedLabelPrefix is the prefix of the node container labels.
+const reservedLabelPrefix = "network.forta"
+
+// isCustomContainerLabel tells if the label can be added to the containers. The network.forta
+// labels identify the node containers and they can not be set from the config.
+func isCustomContainerLabel(key string) bool {
+	return len(key) > 0 && key != reservedLabelPrefix && !strings.HasPrefix(key, reservedLabelPrefix+".")
+}
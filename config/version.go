@@ -0,0 +1,70 @@
+package config
+
+import (
+	"time"
+
+	"github.com/forta-network/forta-core-go/release"
+)
+
+// NodeVersion is the version info of the node as reported by the runner.
+type NodeVersion struct {
+	// Build is the release which the runner binary was built from.
+	Build *release.ReleaseSummary `json:"build,omitempty"`
+	// Embedded are the image refs which the runner binary was built with.
+	Embedded NodeImages `json:"embedded"`
+	// Running are the image refs of the running supervisor and updater.
+	Running NodeImages `json:"running"`
+	// RunningRelease is the release of the running images.
+	RunningRelease *NodeRelease `json:"runningRelease,omitempty"`
+	// Containers are the image digests of the running node containers by the container name.
+	Containers map[string]string `json:"containers,omitempty"`
+	// LatestRelease is the latest release which the updater provided. It is empty if the
+	// updates are disabled or the updater has not responded yet.
+	LatestRelease  *NodeRelease `json:"latestRelease,omitempty"`
+	ReleaseChannel string       `json:"releaseChannel"`
+	UpdatesEnabled bool         `json:"updatesEnabled"`
+}
+
+// NodeImages are the supervisor and the updater image refs.
+type NodeImages struct {
+	Supervisor string `json:"supervisor,omitempty"`
+	Updater    string `json:"updater,omitempty"`
+}
+
+// NodeRelease is the short summary of a release.
+type NodeRelease struct {
+	Version   string     `json:"version,omitempty"`
+	Commit    string     `json:"commit,omitempty"`
+	Timestamp string     `json:"timestamp,omitempty"`
+	Channel   string     `json:"channel,omitempty"`
+	IPFS      string     `json:"ipfs,omitempty"`
+	SeenAt    *time.Time `json:"seenAt,omitempty"`
+}
+
+// NewNodeRelease summarizes the release info.
+func NewNodeRelease(releaseInfo *release.ReleaseInfo) *NodeRelease {
+	if releaseInfo == nil {
+		return nil
+	}
+	return &NodeRelease{
+		Version:   releaseInfo.Manifest.Release.Version,
+		Commit:    releaseInfo.Manifest.Release.Commit,
+		Timestamp: releaseInfo.Manifest.Release.Timestamp,
+		Channel:   GetReleaseChannel(releaseInfo),
+		IPFS:      releaseInfo.IPFS,
+	}
+}
+
+// GetBuildNodeVersion returns the version info which is known without the runner.
+func GetBuildNodeVersion() *NodeVersion {
+	version := &NodeVersion{
+		Embedded: NodeImages{
+			Supervisor: DockerSupervisorImage,
+			Updater:    DockerUpdaterImage,
+		},
+	}
+	if buildRelease, ok := GetBuildReleaseSummary(); ok {
+		version.Build = buildRelease
+	}
+	return version
+}
@@ -0,0 +1,109 @@
+package healthutils
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// agent disable endpoints of the supervisor
+const (
+	AgentDisablePath = "/agents/disable"
+	AgentEnablePath  = "/agents/enable"
+)
+
+// AgentDisableResult contains the agents which are disabled or enabled.
+type AgentDisableResult struct {
+	Agents []string `json:"agents"`
+}
+
+// AgentDisabler keeps the agents from running until they are enabled again.
+type AgentDisabler interface {
+	DisableAgent(agentID, reason string) error
+	// EnableAgent enables the agent and tells if it was disabled.
+	EnableAgent(agentID string) (bool, error)
+}
+
+// AgentDisableHandler disables the agent on POST to the disable path and enables it on POST to
+// the enable path. The agentId query param is required and the reason query param is optional.
+// The requests need the token as the bearer token.
+func AgentDisableHandler(disabler AgentDisabler, token func() (string, error)) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		if !checkToken(w, req, token) {
+			return
+		}
+		if req.Method != http.MethodPost {
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		agentID := req.URL.Query().Get("agentId")
+		if len(agentID) == 0 {
+			http.Error(w, "agentId is required", http.StatusBadRequest)
+			return
+		}
+
+		result := &AgentDisableResult{}
+		var err error
+		switch req.URL.Path {
+		case AgentDisablePath:
+			err = disabler.DisableAgent(agentID, req.URL.Query().Get("reason"))
+			result.Agents = []string{agentID}
+		case AgentEnablePath:
+			var enabled bool
+			enabled, err = disabler.EnableAgent(agentID)
+			if enabled {
+				result.Agents = []string{agentID}
+			}
+		default:
+			http.NotFound(w, req)
+			return
+		}
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		if err := json.NewEncoder(w).Encode(result); err != nil {
+			log.WithError(err).Warn("failed to encode agent disable response")
+		}
+	})
+}
+
+// DisableAgent disables the agent through the health server of the supervisor at the given
+// local port.
+func DisableAgent(port, token, agentID, reason string) (*AgentDisableResult, error) {
+	query := url.Values{"agentId": {agentID}, "reason": {reason}}
+	return doAgentDisableRequest(port, token, AgentDisablePath, query)
+}
+
+// EnableAgent enables the agent through the health server of the supervisor at the given local
+// port.
+func EnableAgent(port, token, agentID string) (*AgentDisableResult, error) {
+	return doAgentDisableRequest(port, token, AgentEnablePath, url.Values{"agentId": {agentID}})
+}
+
+func doAgentDisableRequest(port, token, path string, query url.Values) (*AgentDisableResult, error) {
+	reqURL := fmt.Sprintf("http://localhost:%s%s?%s", port, path, query.Encode())
+	req, err := http.NewRequest(http.MethodPost, reqURL, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", token))
+	resp, err := adminHTTPClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("request failed: %v", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("agent disable request failed with code %d", resp.StatusCode)
+	}
+
+	var result AgentDisableResult
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return nil, fmt.Errorf("bad response: %v", err)
+	}
+	return &result, nil
+}
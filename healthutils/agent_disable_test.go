@@ -0,0 +1,57 @@
+package healthutils
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+type testAgentDisabler struct {
+	disabled map[string]string
+}
+
+func (disabler *testAgentDisabler) DisableAgent(agentID, reason string) error {
+	disabler.disabled[agentID] = reason
+	return nil
+}
+
+func (disabler *testAgentDisabler) EnableAgent(agentID string) (bool, error) {
+	_, ok := disabler.disabled[agentID]
+	delete(disabler.disabled, agentID)
+	return ok, nil
+}
+
+func TestDisableAgent(t *testing.T) {
+	r := require.New(t)
+
+	disabler := &testAgentDisabler{disabled: make(map[string]string)}
+	mux := http.NewServeMux()
+	handler := AgentDisableHandler(disabler, testDrainToken)
+	mux.Handle(AgentDisablePath, handler)
+	mux.Handle(AgentEnablePath, handler)
+	server := httptest.NewServer(mux)
+	defer server.Close()
+	serverURL, err := url.Parse(server.URL)
+	r.NoError(err)
+	port := serverURL.Port()
+
+	_, err = DisableAgent(port, "bad-token", "0x123", "")
+	r.Error(err)
+	_, err = DisableAgent(port, "token1", "", "")
+	r.Error(err)
+
+	result, err := DisableAgent(port, "token1", "0x123", "too many alerts")
+	r.NoError(err)
+	r.Equal([]string{"0x123"}, result.Agents)
+	r.Equal("too many alerts", disabler.disabled["0x123"])
+
+	result, err = EnableAgent(port, "token1", "0x123")
+	r.NoError(err)
+	r.Equal([]string{"0x123"}, result.Agents)
+	result, err = EnableAgent(port, "token1", "0x123")
+	r.NoError(err)
+	r.Empty(result.Agents)
+}
@@ -0,0 +1,69 @@
+package healthutils
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// AgentResetPath is the path of the endpoint of the supervisor which removes all agent containers.
+const AgentResetPath = "/agents/reset"
+
+// AgentResetResult contains the containers of the agents which are removed.
+type AgentResetResult struct {
+	Containers []string `json:"containers"`
+}
+
+// AgentResetter removes the agent containers so that the agents are assigned again.
+type AgentResetter interface {
+	// ResetAgents removes the agent containers and returns their names.
+	ResetAgents() ([]string, error)
+}
+
+// AgentResetHandler resets the agents on POST. The requests need the token as the bearer token.
+func AgentResetHandler(resetter AgentResetter, token func() (string, error)) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		if !checkToken(w, req, token) {
+			return
+		}
+		if req.Method != http.MethodPost {
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		containers, err := resetter.ResetAgents()
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		if err := json.NewEncoder(w).Encode(&AgentResetResult{Containers: containers}); err != nil {
+			log.WithError(err).Warn("failed to encode agent reset response")
+		}
+	})
+}
+
+// ResetAgents resets the agents through the health server of the supervisor at the given local
+// port.
+func ResetAgents(port, token string) (*AgentResetResult, error) {
+	req, err := http.NewRequest(http.MethodPost, fmt.Sprintf("http://localhost:%s%s", port, AgentResetPath), nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", token))
+	resp, err := adminHTTPClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("request failed: %v", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("agent reset request failed with code %d", resp.StatusCode)
+	}
+
+	var result AgentResetResult
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return nil, fmt.Errorf("bad response: %v", err)
+	}
+	return &result, nil
+}
@@ -0,0 +1,43 @@
+package healthutils
+
+import (
+	"errors"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+type testAgentResetter struct {
+	err error
+}
+
+func (resetter *testAgentResetter) ResetAgents() ([]string, error) {
+	if resetter.err != nil {
+		return nil, resetter.err
+	}
+	return []string{"forta-agent-0x123456-abcd"}, nil
+}
+
+func TestResetAgents(t *testing.T) {
+	r := require.New(t)
+
+	resetter := &testAgentResetter{}
+	server := httptest.NewServer(AgentResetHandler(resetter, testDrainToken))
+	defer server.Close()
+	serverURL, err := url.Parse(server.URL)
+	r.NoError(err)
+	port := serverURL.Port()
+
+	_, err = ResetAgents(port, "bad-token")
+	r.Error(err)
+
+	result, err := ResetAgents(port, "token1")
+	r.NoError(err)
+	r.Equal([]string{"forta-agent-0x123456-abcd"}, result.Containers)
+
+	resetter.err = errors.New("failed")
+	_, err = ResetAgents(port, "token1")
+	r.Error(err)
+}
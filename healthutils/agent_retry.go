@@ -0,0 +1,73 @@
+package healthutils
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// AgentRetryPath is the path of the endpoint of the supervisor which retries the quarantined agents.
+const AgentRetryPath = "/agents/retry"
+
+// AgentRetryResult contains the agents which are retried.
+type AgentRetryResult struct {
+	Agents []string `json:"agents"`
+}
+
+// AgentRetrier restarts the quarantined agents.
+type AgentRetrier interface {
+	// RetryQuarantinedAgents retries the agent or all quarantined agents if the agent ID is empty.
+	RetryQuarantinedAgents(agentID string) []string
+}
+
+// AgentRetryHandler retries the quarantined agents on POST. The optional agentId query param
+// selects the agent. The requests need the token as the bearer token.
+func AgentRetryHandler(retrier AgentRetrier, token func() (string, error)) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		if !checkToken(w, req, token) {
+			return
+		}
+		if req.Method != http.MethodPost {
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		result := &AgentRetryResult{
+			Agents: retrier.RetryQuarantinedAgents(req.URL.Query().Get("agentId")),
+		}
+		w.Header().Set("Content-Type", "application/json")
+		if err := json.NewEncoder(w).Encode(result); err != nil {
+			log.WithError(err).Warn("failed to encode agent retry response")
+		}
+	})
+}
+
+// RetryQuarantinedAgents retries the quarantined agents through the health server of the
+// supervisor at the given local port.
+func RetryQuarantinedAgents(port, token, agentID string) (*AgentRetryResult, error) {
+	reqURL := fmt.Sprintf("http://localhost:%s%s", port, AgentRetryPath)
+	if len(agentID) > 0 {
+		reqURL = fmt.Sprintf("%s?agentId=%s", reqURL, url.QueryEscape(agentID))
+	}
+	req, err := http.NewRequest(http.MethodPost, reqURL, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", token))
+	resp, err := adminHTTPClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("request failed: %v", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("agent retry request failed with code %d", resp.StatusCode)
+	}
+
+	var result AgentRetryResult
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return nil, fmt.Errorf("bad response: %v", err)
+	}
+	return &result, nil
+}
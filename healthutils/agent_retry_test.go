@@ -0,0 +1,37 @@
+package healthutils
+
+import (
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+type testAgentRetrier struct {
+	agentID string
+}
+
+func (retrier *testAgentRetrier) RetryQuarantinedAgents(agentID string) []string {
+	retrier.agentID = agentID
+	return []string{"0x123"}
+}
+
+func TestRetryQuarantinedAgents(t *testing.T) {
+	r := require.New(t)
+
+	retrier := &testAgentRetrier{}
+	server := httptest.NewServer(AgentRetryHandler(retrier, testDrainToken))
+	defer server.Close()
+	serverURL, err := url.Parse(server.URL)
+	r.NoError(err)
+	port := serverURL.Port()
+
+	_, err = RetryQuarantinedAgents(port, "bad-token", "")
+	r.Error(err)
+
+	result, err := RetryQuarantinedAgents(port, "token1", "0x123")
+	r.NoError(err)
+	r.Equal([]string{"0x123"}, result.Agents)
+	r.Equal("0x123", retrier.agentID)
+}
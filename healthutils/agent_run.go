@@ -0,0 +1,112 @@
+package healthutils
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// AgentRunPath is the path of the one-off agent run endpoint of the supervisor.
+const AgentRunPath = "/agents/runs"
+
+// AgentRunRequest is an ad-hoc agent config to run once over the block range.
+type AgentRunRequest struct {
+	ID         string `json:"id"`
+	Image      string `json:"image"`
+	StartBlock uint64 `json:"startBlock"`
+	StopBlock  uint64 `json:"stopBlock"`
+}
+
+// Validate checks if the request has the agent and a valid block range.
+func (req *AgentRunRequest) Validate() error {
+	switch {
+	case len(req.ID) == 0:
+		return errors.New("id is required")
+	case len(req.Image) == 0:
+		return errors.New("image is required")
+	case req.StartBlock == 0 || req.StopBlock == 0:
+		return errors.New("startBlock and stopBlock are required")
+	case req.StartBlock > req.StopBlock:
+		return fmt.Errorf("startBlock %d is after stopBlock %d", req.StartBlock, req.StopBlock)
+	}
+	return nil
+}
+
+// AgentRunResult identifies the started one-off run.
+type AgentRunResult struct {
+	RunID         string `json:"runId"`
+	ContainerName string `json:"containerName"`
+}
+
+// AgentRunner launches the one-off agent runs.
+type AgentRunner interface {
+	RunAgentOnce(req *AgentRunRequest) (*AgentRunResult, error)
+}
+
+// AgentRunHandler launches a one-off agent run on POST with the agent run request as the body.
+// The requests need the token as the bearer token.
+func AgentRunHandler(runner AgentRunner, token func() (string, error)) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		if !checkToken(w, req, token) {
+			return
+		}
+		if req.Method != http.MethodPost {
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		var runReq AgentRunRequest
+		if err := json.NewDecoder(req.Body).Decode(&runReq); err != nil {
+			http.Error(w, fmt.Sprintf("bad request: %v", err), http.StatusBadRequest)
+			return
+		}
+		if err := runReq.Validate(); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		result, err := runner.RunAgentOnce(&runReq)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		if err := json.NewEncoder(w).Encode(result); err != nil {
+			log.WithError(err).Warn("failed to encode agent run response")
+		}
+	})
+}
+
+// RunAgentOnce launches a one-off agent run through the health server of the supervisor at the
+// given local port.
+func RunAgentOnce(port, token string, runReq *AgentRunRequest) (*AgentRunResult, error) {
+	b, err := json.Marshal(runReq)
+	if err != nil {
+		return nil, err
+	}
+	req, err := http.NewRequest(http.MethodPost, fmt.Sprintf("http://localhost:%s%s", port, AgentRunPath), bytes.NewReader(b))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", token))
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := adminHTTPClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("request failed: %v", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return nil, fmt.Errorf("agent run request failed with code %d: %s", resp.StatusCode, strings.TrimSpace(string(msg)))
+	}
+
+	var result AgentRunResult
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return nil, fmt.Errorf("bad response: %v", err)
+	}
+	return &result, nil
+}
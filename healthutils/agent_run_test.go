@@ -0,0 +1,47 @@
+package healthutils
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+type testAgentRunner struct {
+	runs []*AgentRunRequest
+}
+
+func (runner *testAgentRunner) RunAgentOnce(req *AgentRunRequest) (*AgentRunResult, error) {
+	runner.runs = append(runner.runs, req)
+	return &AgentRunResult{RunID: "run1", ContainerName: "forta-agent-0x123-run-run1"}, nil
+}
+
+func TestRunAgentOnce(t *testing.T) {
+	r := require.New(t)
+
+	runner := &testAgentRunner{}
+	mux := http.NewServeMux()
+	mux.Handle(AgentRunPath, AgentRunHandler(runner, testDrainToken))
+	server := httptest.NewServer(mux)
+	defer server.Close()
+	serverURL, err := url.Parse(server.URL)
+	r.NoError(err)
+	port := serverURL.Port()
+
+	runReq := &AgentRunRequest{ID: "0x123", Image: "image1", StartBlock: 100, StopBlock: 200}
+	_, err = RunAgentOnce(port, "bad-token", runReq)
+	r.Error(err)
+	_, err = RunAgentOnce(port, "token1", &AgentRunRequest{ID: "0x123", Image: "image1", StartBlock: 200, StopBlock: 100})
+	r.ErrorContains(err, "is after stopBlock")
+	_, err = RunAgentOnce(port, "token1", &AgentRunRequest{ID: "0x123", StartBlock: 100, StopBlock: 200})
+	r.ErrorContains(err, "image is required")
+	r.Empty(runner.runs)
+
+	result, err := RunAgentOnce(port, "token1", runReq)
+	r.NoError(err)
+	r.Equal("run1", result.RunID)
+	r.Len(runner.runs, 1)
+	r.Equal(*runReq, *runner.runs[0])
+}
@@ -0,0 +1,105 @@
+package healthutils
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/forta-network/forta-node/config"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAuthHandler(t *testing.T) {
+	okHandler := http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	})
+
+	testCases := []struct {
+		name         string
+		cfg          config.TelemetryAuthConfig
+		remoteAddr   string
+		setAuth      func(req *http.Request)
+		expectedCode int
+	}{
+		{
+			name:         "no auth configured",
+			remoteAddr:   "10.0.0.1:1234",
+			expectedCode: http.StatusOK,
+		},
+		{
+			name:         "missing bearer token",
+			cfg:          config.TelemetryAuthConfig{BearerToken: "secret"},
+			remoteAddr:   "10.0.0.1:1234",
+			expectedCode: http.StatusUnauthorized,
+		},
+		{
+			name:       "wrong bearer token",
+			cfg:        config.TelemetryAuthConfig{BearerToken: "secret"},
+			remoteAddr: "10.0.0.1:1234",
+			setAuth: func(req *http.Request) {
+				req.Header.Set("Authorization", "Bearer wrong")
+			},
+			expectedCode: http.StatusUnauthorized,
+		},
+		{
+			name:       "valid bearer token",
+			cfg:        config.TelemetryAuthConfig{BearerToken: "secret"},
+			remoteAddr: "10.0.0.1:1234",
+			setAuth: func(req *http.Request) {
+				req.Header.Set("Authorization", "Bearer secret")
+			},
+			expectedCode: http.StatusOK,
+		},
+		{
+			name:       "wrong basic auth password",
+			cfg:        config.TelemetryAuthConfig{Username: "user", Password: "pass"},
+			remoteAddr: "10.0.0.1:1234",
+			setAuth: func(req *http.Request) {
+				req.SetBasicAuth("user", "wrong")
+			},
+			expectedCode: http.StatusUnauthorized,
+		},
+		{
+			name:       "valid basic auth",
+			cfg:        config.TelemetryAuthConfig{Username: "user", Password: "pass"},
+			remoteAddr: "10.0.0.1:1234",
+			setAuth: func(req *http.Request) {
+				req.SetBasicAuth("user", "pass")
+			},
+			expectedCode: http.StatusOK,
+		},
+		{
+			name:         "local request without exemption",
+			cfg:          config.TelemetryAuthConfig{BearerToken: "secret"},
+			remoteAddr:   "127.0.0.1:1234",
+			expectedCode: http.StatusUnauthorized,
+		},
+		{
+			name:         "exempted local request",
+			cfg:          config.TelemetryAuthConfig{BearerToken: "secret", AllowLocalUnauthenticated: true},
+			remoteAddr:   "127.0.0.1:1234",
+			expectedCode: http.StatusOK,
+		},
+		{
+			name:         "remote request with local exemption",
+			cfg:          config.TelemetryAuthConfig{BearerToken: "secret", AllowLocalUnauthenticated: true},
+			remoteAddr:   "10.0.0.1:1234",
+			expectedCode: http.StatusUnauthorized,
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			r := require.New(t)
+
+			req := httptest.NewRequest(http.MethodGet, "/health", nil)
+			req.RemoteAddr = testCase.remoteAddr
+			if testCase.setAuth != nil {
+				testCase.setAuth(req)
+			}
+			w := httptest.NewRecorder()
+			AuthHandler(testCase.cfg, okHandler).ServeHTTP(w, req)
+			r.Equal(testCase.expectedCode, w.Code)
+		})
+	}
+}
@@ -0,0 +1,103 @@
+package healthutils
+
+import (
+	"crypto/subtle"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// DrainPath is the path of the drain endpoint of the supervisor.
+const DrainPath = "/drain"
+
+// DrainState is the progress of draining the node.
+type DrainState struct {
+	Draining bool   `json:"draining"`
+	Drained  bool   `json:"drained"`
+	Details  string `json:"details,omitempty"`
+}
+
+// Drainer stops taking new work and flushes the pending work.
+type Drainer interface {
+	// Drain starts draining if not started yet and returns the current state.
+	Drain() *DrainState
+	DrainState() *DrainState
+}
+
+// DrainHandler starts draining on POST and responds with the drain state. The requests need
+// the token as the bearer token.
+func DrainHandler(drainer Drainer, token func() (string, error)) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		if !checkToken(w, req, token) {
+			return
+		}
+
+		var state *DrainState
+		switch req.Method {
+		case http.MethodGet:
+			state = drainer.DrainState()
+		case http.MethodPost:
+			state = drainer.Drain()
+		default:
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		if err := json.NewEncoder(w).Encode(state); err != nil {
+			log.WithError(err).Warn("failed to encode drain response")
+		}
+	})
+}
+
+// checkToken responds with an error if the request does not have the token as the bearer token.
+func checkToken(w http.ResponseWriter, req *http.Request, token func() (string, error)) bool {
+	expected, err := token()
+	if err != nil {
+		log.WithError(err).Warn("failed to read the token")
+	}
+	actual := strings.TrimPrefix(req.Header.Get("Authorization"), "Bearer ")
+	if len(expected) == 0 || subtle.ConstantTimeCompare([]byte(actual), []byte(expected)) != 1 {
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return false
+	}
+	return true
+}
+
+var adminHTTPClient = &http.Client{Timeout: time.Second * 5}
+
+// RequestDrain starts draining the container which has the health server at the given local port.
+func RequestDrain(port, token string) (*DrainState, error) {
+	return doDrainRequest(http.MethodPost, port, token)
+}
+
+// GetDrainState gets the drain state of the container which has the health server at the given
+// local port.
+func GetDrainState(port, token string) (*DrainState, error) {
+	return doDrainRequest(http.MethodGet, port, token)
+}
+
+func doDrainRequest(method, port, token string) (*DrainState, error) {
+	req, err := http.NewRequest(method, fmt.Sprintf("http://localhost:%s%s", port, DrainPath), nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", token))
+	resp, err := adminHTTPClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("request failed: %v", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("drain request failed with code %d", resp.StatusCode)
+	}
+
+	var state DrainState
+	if err := json.NewDecoder(resp.Body).Decode(&state); err != nil {
+		return nil, fmt.Errorf("bad response: %v", err)
+	}
+	return &state, nil
+}
@@ -0,0 +1,66 @@
+package healthutils
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+type testDrainer struct {
+	state DrainState
+}
+
+func (drainer *testDrainer) Drain() *DrainState {
+	drainer.state.Draining = true
+	return drainer.DrainState()
+}
+
+func (drainer *testDrainer) DrainState() *DrainState {
+	state := drainer.state
+	return &state
+}
+
+func testDrainToken() (string, error) {
+	return "token1", nil
+}
+
+func TestDrain(t *testing.T) {
+	r := require.New(t)
+
+	drainer := &testDrainer{}
+	server := httptest.NewServer(DrainHandler(drainer, testDrainToken))
+	defer server.Close()
+	serverURL, err := url.Parse(server.URL)
+	r.NoError(err)
+	port := serverURL.Port()
+
+	_, err = RequestDrain(port, "bad-token")
+	r.Error(err)
+	r.False(drainer.state.Draining)
+
+	state, err := GetDrainState(port, "token1")
+	r.NoError(err)
+	r.False(state.Draining)
+
+	state, err = RequestDrain(port, "token1")
+	r.NoError(err)
+	r.True(state.Draining)
+	r.False(state.Drained)
+
+	drainer.state.Drained = true
+	state, err = GetDrainState(port, "token1")
+	r.NoError(err)
+	r.True(state.Drained)
+}
+
+func TestDrainHandler_NoToken(t *testing.T) {
+	r := require.New(t)
+
+	handler := DrainHandler(&testDrainer{}, func() (string, error) { return "", nil })
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, httptest.NewRequest(http.MethodPost, DrainPath, nil))
+	r.Equal(http.StatusUnauthorized, w.Code)
+}
@@ -0,0 +1,88 @@
+package healthutils
+
+import (
+	"crypto/tls"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/forta-network/forta-core-go/clients/health"
+	"github.com/forta-network/forta-node/config"
+)
+
+var (
+	healthHTTPClient = &http.Client{Timeout: time.Second * 5}
+
+	// the node certificate is for the operator's host names and the node health endpoint is
+	// reached through localhost or the docker host
+	nodeHealthHTTPClient = &http.Client{
+		Timeout: time.Second * 5,
+		Transport: &http.Transport{
+			TLSClientConfig: &tls.Config{InsecureSkipVerify: true},
+		},
+	}
+)
+
+// CheckHealthURL gets the health reports from the health endpoint at the URL. The failures are
+// reported with a single health-api report like the local health checks.
+func CheckHealthURL(rawurl string) health.Reports {
+	req, err := http.NewRequest(http.MethodGet, rawurl, nil)
+	if err != nil {
+		return healthAPIReport(health.StatusFailing, fmt.Sprintf("bad url: %v", err))
+	}
+	return checkHealth(healthHTTPClient, req)
+}
+
+// CheckNodeHealth gets the health reports from the node health endpoint on localhost with
+// the configured scheme and credentials.
+func CheckNodeHealth(cfg config.TelemetryAuthConfig, port string) health.Reports {
+	req, err := http.NewRequest(http.MethodGet, NodeHealthURL(cfg, "localhost", port), nil)
+	if err != nil {
+		return healthAPIReport(health.StatusFailing, fmt.Sprintf("bad url: %v", err))
+	}
+	SetAuth(cfg, req)
+	return checkHealth(nodeHealthHTTPClient, req)
+}
+
+// NodeHealthURL returns the URL of the node health endpoint with the configured scheme.
+func NodeHealthURL(cfg config.TelemetryAuthConfig, host, port string) string {
+	scheme := "http"
+	if cfg.TLSEnabled() {
+		scheme = "https"
+	}
+	return fmt.Sprintf("%s://%s:%s/health", scheme, host, port)
+}
+
+func checkHealth(client *http.Client, req *http.Request) health.Reports {
+	resp, err := client.Do(req)
+	if err != nil {
+		return healthAPIReport(health.StatusDown, fmt.Sprintf("request failed: %v", err))
+	}
+	defer resp.Body.Close()
+
+	b, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return healthAPIReport(health.StatusFailing, fmt.Sprintf("failed to read: %v", err))
+	}
+	if resp.StatusCode != http.StatusOK {
+		return healthAPIReport(health.StatusFailing, fmt.Sprintf("responded with status %d", resp.StatusCode))
+	}
+	var reports health.Reports
+	if err := json.Unmarshal(b, &reports); err != nil {
+		return healthAPIReport(health.StatusFailing, fmt.Sprintf("bad response: %v", err))
+	}
+	reports.ObfuscateDetails()
+	return reports
+}
+
+func healthAPIReport(status health.Status, details string) health.Reports {
+	return health.Reports{
+		{
+			Name:    "health-api",
+			Status:  status,
+			Details: details,
+		},
+	}
+}
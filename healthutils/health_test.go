@@ -0,0 +1,68 @@
+package healthutils
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/forta-network/forta-core-go/clients/health"
+	"github.com/forta-network/forta-node/config"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCheckHealthURL(t *testing.T) {
+	r := require.New(t)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		if req.URL.Path != "/health" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		w.Write([]byte(`[{"name":"service.supervisor","status":"ok"}]`))
+	}))
+
+	reports := CheckHealthURL(server.URL + "/health")
+	r.Len(reports, 1)
+	r.Equal("service.supervisor", reports[0].Name)
+	r.Equal(health.StatusOK, reports[0].Status)
+
+	reports = CheckHealthURL(server.URL + "/foo")
+	r.Len(reports, 1)
+	r.Equal("health-api", reports[0].Name)
+	r.Equal(health.StatusFailing, reports[0].Status)
+
+	server.Close()
+	reports = CheckHealthURL(server.URL + "/health")
+	r.Len(reports, 1)
+	r.Equal(health.StatusDown, reports[0].Status)
+}
+
+func TestCheckNodeHealth(t *testing.T) {
+	r := require.New(t)
+
+	authCfg := config.TelemetryAuthConfig{
+		BearerToken: "secret",
+		TLSCertFile: "cert.pem",
+		TLSKeyFile:  "key.pem",
+	}
+	server := httptest.NewTLSServer(AuthHandler(authCfg, http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		w.Write([]byte(`[{"name":"forta.node","status":"ok"}]`))
+	})))
+	defer server.Close()
+	serverURL, err := url.Parse(server.URL)
+	r.NoError(err)
+
+	// the node health endpoint is called with https and the token
+	reports := CheckNodeHealth(authCfg, serverURL.Port())
+	r.Len(reports, 1)
+	r.Equal("forta.node", reports[0].Name)
+	r.Equal(health.StatusOK, reports[0].Status)
+
+	wrongCfg := authCfg
+	wrongCfg.BearerToken = "wrong"
+	reports = CheckNodeHealth(wrongCfg, serverURL.Port())
+	r.Len(reports, 1)
+	r.Equal("health-api", reports[0].Name)
+	r.Equal(health.StatusFailing, reports[0].Status)
+}
@@ -0,0 +1,92 @@
+package healthutils
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// ReadinessPath is the path of the readiness check. The health check path is used as
+// the liveness check.
+const ReadinessPath = "/health/ready"
+
+// ReadinessCheck checks a condition which is required before the node is ready to scan.
+type ReadinessCheck struct {
+	Name  string
+	Check func() error
+}
+
+// ReadinessResponse is the response of the readiness check.
+type ReadinessResponse struct {
+	Ready   bool              `json:"ready"`
+	Reasons map[string]string `json:"reasons,omitempty"`
+}
+
+// CheckReadiness runs all checks and collects the reasons of the failing ones.
+func CheckReadiness(checks ...ReadinessCheck) *ReadinessResponse {
+	resp := &ReadinessResponse{Ready: true}
+	for _, check := range checks {
+		if err := check.Check(); err != nil {
+			if resp.Reasons == nil {
+				resp.Reasons = make(map[string]string)
+			}
+			resp.Ready = false
+			resp.Reasons[check.Name] = err.Error()
+		}
+	}
+	return resp
+}
+
+// ReadinessHandler responds with 200 if all checks pass and with 503 otherwise.
+func ReadinessHandler(checks ...ReadinessCheck) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		resp := CheckReadiness(checks...)
+		w.Header().Set("Content-Type", "application/json")
+		if !resp.Ready {
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}
+		if err := json.NewEncoder(w).Encode(resp); err != nil {
+			log.WithError(err).Warn("failed to encode readiness response")
+		}
+	})
+}
+
+var readinessHTTPClient = &http.Client{Timeout: time.Second}
+
+// GetReadiness gets the readiness of the container from the health server at the given
+// local port.
+func GetReadiness(port string) (*ReadinessResponse, error) {
+	resp, err := readinessHTTPClient.Get(fmt.Sprintf("http://localhost:%s%s", port, ReadinessPath))
+	if err != nil {
+		return nil, fmt.Errorf("request failed: %v", err)
+	}
+	defer resp.Body.Close()
+
+	var readiness ReadinessResponse
+	if err := json.NewDecoder(resp.Body).Decode(&readiness); err != nil {
+		return nil, fmt.Errorf("bad response (status %d): %v", resp.StatusCode, err)
+	}
+	return &readiness, nil
+}
+
+// String implements the fmt.Stringer interface.
+func (resp *ReadinessResponse) String() string {
+	if resp.Ready {
+		return "ready"
+	}
+	var names []string
+	for name := range resp.Reasons {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	var reasons []string
+	for _, name := range names {
+		reasons = append(reasons, fmt.Sprintf("%s: %s", name, resp.Reasons[name]))
+	}
+	return strings.Join(reasons, ", ")
+}
@@ -0,0 +1,174 @@
+package healthutils
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/forta-network/forta-core-go/clients/health"
+	"github.com/forta-network/forta-node/config"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	log "github.com/sirupsen/logrus"
+)
+
+// MetricsPath is the path of the Prometheus metrics endpoint.
+const MetricsPath = "/metrics"
+
+// StartServer starts the health server with the readiness check and the metrics in addition to
+// the health check handlers. The handlers require auth and the server uses TLS if configured.
+// The address can be a port or a host:port and the server listens on the default port of
+// all interfaces if it is empty.
+func StartServer(ctx context.Context, addr string, serverErrHandler health.ServerErrorHandler, authCfg config.TelemetryAuthConfig, healthChecker health.HealthChecker, readinessChecks ...ReadinessCheck) error {
+	return startServer(ctx, addr, serverErrHandler, authCfg, newHealthMux(healthChecker, readinessChecks...))
+}
+
+func newHealthMux(healthChecker health.HealthChecker, readinessChecks ...ReadinessCheck) *http.ServeMux {
+	mux := http.NewServeMux()
+	health.Handle(mux, healthChecker)
+	mux.Handle(ReadinessPath, ReadinessHandler(readinessChecks...))
+	mux.Handle(MetricsPath, promhttp.Handler())
+	return mux
+}
+
+func startServer(ctx context.Context, addr string, serverErrHandler health.ServerErrorHandler, authCfg config.TelemetryAuthConfig, mux *http.ServeMux) error {
+	server := &http.Server{
+		Addr:    serverAddr(addr),
+		Handler: AuthHandler(authCfg, mux),
+	}
+
+	listenAndServe := server.ListenAndServe
+	if authCfg.TLSEnabled() {
+		reloader, err := newCertReloader(authCfg.TLSCertFile, authCfg.TLSKeyFile)
+		if err != nil {
+			return fmt.Errorf("invalid health server tls config: %v", err)
+		}
+		server.TLSConfig = &tls.Config{GetCertificate: reloader.GetCertificate}
+		listenAndServe = func() error {
+			return server.ListenAndServeTLS("", "")
+		}
+		go reloader.watch(ctx)
+	}
+
+	go func() {
+		if err := listenAndServe(); err != nil {
+			if serverErrHandler != nil {
+				serverErrHandler(err)
+			} else {
+				log.WithError(err).Error("health server failed")
+			}
+		}
+	}()
+	go func() {
+		<-ctx.Done()
+		server.Close()
+	}()
+	return nil
+}
+
+func serverAddr(addr string) string {
+	if len(addr) == 0 {
+		return fmt.Sprintf(":%s", health.DefaultServerPort)
+	}
+	if !strings.Contains(addr, ":") {
+		return fmt.Sprintf(":%s", addr)
+	}
+	return addr
+}
+
+// HealthService is a health server service with the readiness check.
+type HealthService struct {
+	ctx              context.Context
+	port             string
+	serverErrHandler health.ServerErrorHandler
+	healthChecker    health.HealthChecker
+	readinessChecks  []ReadinessCheck
+	drainer          Drainer
+	agentRetrier     AgentRetrier
+	agentResetter    AgentResetter
+	agentDisabler    AgentDisabler
+	agentRunner      AgentRunner
+	adminToken       func() (string, error)
+}
+
+// NewHealthService creates a new health service.
+func NewHealthService(ctx context.Context, port string, serverErrHandler health.ServerErrorHandler, healthChecker health.HealthChecker, readinessChecks ...ReadinessCheck) *HealthService {
+	return &HealthService{
+		ctx:              ctx,
+		port:             port,
+		serverErrHandler: serverErrHandler,
+		healthChecker:    healthChecker,
+		readinessChecks:  readinessChecks,
+	}
+}
+
+// WithDrainer adds the drain endpoint which accepts the requests with the given token.
+func (service *HealthService) WithDrainer(drainer Drainer, token func() (string, error)) *HealthService {
+	service.drainer = drainer
+	service.adminToken = token
+	return service
+}
+
+// WithAgentRetrier adds the agent retry endpoint which accepts the requests with the given token.
+func (service *HealthService) WithAgentRetrier(retrier AgentRetrier, token func() (string, error)) *HealthService {
+	service.agentRetrier = retrier
+	service.adminToken = token
+	return service
+}
+
+// WithAgentResetter adds the agent reset endpoint which accepts the requests with the given token.
+func (service *HealthService) WithAgentResetter(resetter AgentResetter, token func() (string, error)) *HealthService {
+	service.agentResetter = resetter
+	service.adminToken = token
+	return service
+}
+
+// WithAgentDisabler adds the agent disable and enable endpoints which accept the requests with the
+// given token.
+func (service *HealthService) WithAgentDisabler(disabler AgentDisabler, token func() (string, error)) *HealthService {
+	service.agentDisabler = disabler
+	service.adminToken = token
+	return service
+}
+
+// WithAgentRunner adds the one-off agent run endpoint which accepts the requests with the given
+// token.
+func (service *HealthService) WithAgentRunner(runner AgentRunner, token func() (string, error)) *HealthService {
+	service.agentRunner = runner
+	service.adminToken = token
+	return service
+}
+
+// Start starts the service.
+func (service *HealthService) Start() error {
+	mux := newHealthMux(service.healthChecker, service.readinessChecks...)
+	if service.drainer != nil {
+		mux.Handle(DrainPath, DrainHandler(service.drainer, service.adminToken))
+	}
+	if service.agentRetrier != nil {
+		mux.Handle(AgentRetryPath, AgentRetryHandler(service.agentRetrier, service.adminToken))
+	}
+	if service.agentResetter != nil {
+		mux.Handle(AgentResetPath, AgentResetHandler(service.agentResetter, service.adminToken))
+	}
+	if service.agentDisabler != nil {
+		handler := AgentDisableHandler(service.agentDisabler, service.adminToken)
+		mux.Handle(AgentDisablePath, handler)
+		mux.Handle(AgentEnablePath, handler)
+	}
+	if service.agentRunner != nil {
+		mux.Handle(AgentRunPath, AgentRunHandler(service.agentRunner, service.adminToken))
+	}
+	return startServer(service.ctx, service.port, service.serverErrHandler, config.TelemetryAuthConfig{}, mux)
+}
+
+// Stop stops the service.
+func (service *HealthService) Stop() error {
+	return nil
+}
+
+// Name returns the name of the service.
+func (service *HealthService) Name() string {
+	return "health"
+}
@@ -0,0 +1,16 @@
+package healthutils
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestServerAddr(t *testing.T) {
+	r := require.New(t)
+
+	r.Equal(":8090", serverAddr(""))
+	r.Equal(":9090", serverAddr("9090"))
+	r.Equal(":9090", serverAddr(":9090"))
+	r.Equal("127.0.0.1:9090", serverAddr("127.0.0.1:9090"))
+}
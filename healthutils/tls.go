@@ -0,0 +1,111 @@
+package healthutils
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"path"
+	"sync"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+	log "github.com/sirupsen/logrus"
+)
+
+const certReloadDelay = time.Second
+
+// certReloader serves the latest certificate loaded from the files.
+type certReloader struct {
+	certFile string
+	keyFile  string
+	cert     *tls.Certificate
+	mu       sync.RWMutex
+}
+
+// newCertReloader loads the certificate so that the misconfigured files are detected early.
+func newCertReloader(certFile, keyFile string) (*certReloader, error) {
+	reloader := &certReloader{certFile: certFile, keyFile: keyFile}
+	if err := reloader.load(); err != nil {
+		return nil, err
+	}
+	return reloader, nil
+}
+
+func (reloader *certReloader) load() error {
+	cert, err := tls.LoadX509KeyPair(reloader.certFile, reloader.keyFile)
+	if err != nil {
+		return fmt.Errorf("failed to load the tls certificate (cert: %s, key: %s): %v", reloader.certFile, reloader.keyFile, err)
+	}
+	reloader.mu.Lock()
+	reloader.cert = &cert
+	reloader.mu.Unlock()
+	return nil
+}
+
+// GetCertificate implements the tls.Config.GetCertificate callback.
+func (reloader *certReloader) GetCertificate(*tls.ClientHelloInfo) (*tls.Certificate, error) {
+	reloader.mu.RLock()
+	defer reloader.mu.RUnlock()
+	return reloader.cert, nil
+}
+
+func (reloader *certReloader) reload() {
+	logger := log.WithFields(log.Fields{"cert": reloader.certFile, "key": reloader.keyFile})
+	if err := reloader.load(); err != nil {
+		logger.WithError(err).Warn("failed to reload the certificate - keeping the previous one")
+		return
+	}
+	logger.Info("reloaded the certificate")
+}
+
+// watch reloads the certificate when the files change. SIGHUP is not used for reloading
+// because it shuts the node down.
+func (reloader *certReloader) watch(ctx context.Context) {
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		log.WithError(err).Error("failed to create the certificate watcher")
+		return
+	}
+	defer watcher.Close()
+
+	// watch the dirs because the files are usually replaced
+	for _, dir := range []string{path.Dir(reloader.certFile), path.Dir(reloader.keyFile)} {
+		if err := watcher.Add(dir); err != nil {
+			log.WithError(err).WithField("dir", dir).Error("failed to watch the certificate dir")
+			return
+		}
+	}
+
+	// wait for the writes to settle down before reloading
+	reloadTimer := time.NewTimer(0)
+	<-reloadTimer.C
+
+	for {
+		select {
+		case event, ok := <-watcher.Events:
+			if !ok {
+				return
+			}
+			name := path.Clean(event.Name)
+			if name != path.Clean(reloader.certFile) && name != path.Clean(reloader.keyFile) {
+				continue
+			}
+			if event.Op&(fsnotify.Write|fsnotify.Create|fsnotify.Rename) == 0 {
+				continue
+			}
+			reloadTimer.Reset(certReloadDelay)
+
+		case err, ok := <-watcher.Errors:
+			if !ok {
+				return
+			}
+			log.WithError(err).Warn("certificate watcher error")
+
+		case <-reloadTimer.C:
+			reloader.reload()
+
+		case <-ctx.Done():
+			return
+		}
+	}
+}
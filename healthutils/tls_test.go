@@ -0,0 +1,73 @@
+package healthutils
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"os"
+	"path"
+	"testing"
+	"time"
+
+	"github.com/forta-network/forta-node/config"
+	"github.com/stretchr/testify/require"
+)
+
+func writeTestCert(r *require.Assertions, certFile, keyFile string, serial int64) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	r.NoError(err)
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(serial),
+		Subject:      pkix.Name{CommonName: "localhost"},
+		NotBefore:    time.Now(),
+		NotAfter:     time.Now().Add(time.Hour),
+	}
+	certDER, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	r.NoError(err)
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	r.NoError(err)
+	r.NoError(os.WriteFile(certFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: certDER}), 0600))
+	r.NoError(os.WriteFile(keyFile, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER}), 0600))
+}
+
+func TestCertReloader(t *testing.T) {
+	r := require.New(t)
+
+	dir := t.TempDir()
+	certFile, keyFile := path.Join(dir, "cert.pem"), path.Join(dir, "key.pem")
+	writeTestCert(r, certFile, keyFile, 1)
+
+	reloader, err := newCertReloader(certFile, keyFile)
+	r.NoError(err)
+	cert1, err := reloader.GetCertificate(nil)
+	r.NoError(err)
+
+	writeTestCert(r, certFile, keyFile, 2)
+	reloader.reload()
+	cert2, err := reloader.GetCertificate(nil)
+	r.NoError(err)
+	r.NotEqual(cert1.Certificate[0], cert2.Certificate[0])
+
+	// keeps the previous certificate if the new one is broken
+	r.NoError(os.WriteFile(certFile, []byte("bad cert"), 0600))
+	reloader.reload()
+	cert3, err := reloader.GetCertificate(nil)
+	r.NoError(err)
+	r.Equal(cert2, cert3)
+}
+
+func TestStartServer_BadCertPaths(t *testing.T) {
+	r := require.New(t)
+
+	err := StartServer(context.Background(), "0", nil, config.TelemetryAuthConfig{
+		TLSCertFile: "/non/existing/cert.pem",
+		TLSKeyFile:  "/non/existing/key.pem",
+	}, nil)
+	r.Error(err)
+	r.Contains(err.Error(), "/non/existing/cert.pem")
+}
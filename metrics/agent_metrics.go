@@ -12,32 +12,38 @@ import (
 )
 
 const (
-	MetricFinding          = "finding"
-	MetricTxRequest        = "tx.request"
-	MetricTxLatency        = "tx.latency"
-	MetricTxError          = "tx.error"
-	MetricTxSuccess        = "tx.success"
-	MetricTxDrop           = "tx.drop"
-	MetricTxBlockAge       = "tx.block.age"
-	MetricTxEventAge       = "tx.event.age"
-	MetricBlockBlockAge    = "block.block.age"
-	MetricBlockEventAge    = "block.event.age"
-	MetricBlockRequest     = "block.request"
-	MetricBlockLatency     = "block.latency"
-	MetricBlockError       = "block.error"
-	MetricBlockSuccess     = "block.success"
-	MetricBlockDrop        = "block.drop"
-	MetricStop             = "agent.stop"
-	MetricJSONRPCLatency   = "jsonrpc.latency"
-	MetricJSONRPCRequest   = "jsonrpc.request"
-	MetricJSONRPCSuccess   = "jsonrpc.success"
-	MetricJSONRPCThrottled = "jsonrpc.throttled"
-	MetricFindingsDropped  = "findings.dropped"
-	MetricCombinerRequest  = "combiner.request"
-	MetricCombinerLatency  = "combiner.latency"
-	MetricCombinerError    = "combiner.error"
-	MetricCombinerSuccess  = "combiner.success"
-	MetricCombinerDrop     = "combiner.drop"
+	MetricFinding            = "finding"
+	MetricTxRequest          = "tx.request"
+	MetricTxLatency          = "tx.latency"
+	MetricTxError            = "tx.error"
+	MetricTxSuccess          = "tx.success"
+	MetricTxDrop             = "tx.drop"
+	MetricTxBlockAge         = "tx.block.age"
+	MetricTxEventAge         = "tx.event.age"
+	MetricBlockBlockAge      = "block.block.age"
+	MetricBlockEventAge      = "block.event.age"
+	MetricBlockRequest       = "block.request"
+	MetricBlockLatency       = "block.latency"
+	MetricBlockError         = "block.error"
+	MetricBlockSuccess       = "block.success"
+	MetricBlockDrop          = "block.drop"
+	MetricBlockLatencyAvg    = "block.latency.avg"
+	MetricBlockLatencyP95    = "block.latency.p95"
+	MetricTxLatencyAvg       = "tx.latency.avg"
+	MetricTxLatencyP95       = "tx.latency.p95"
+	MetricTimeout            = "agent.timeout"
+	MetricStop               = "agent.stop"
+	MetricJSONRPCLatency     = "jsonrpc.latency"
+	MetricJSONRPCRequest     = "jsonrpc.request"
+	MetricJSONRPCSuccess     = "jsonrpc.success"
+	MetricJSONRPCThrottled   = "jsonrpc.throttled"
+	MetricFindingsDropped    = "findings.dropped"
+	MetricFindingsSuppressed = "findings.suppressed"
+	MetricCombinerRequest    = "combiner.request"
+	MetricCombinerLatency    = "combiner.latency"
+	MetricCombinerError      = "combiner.error"
+	MetricCombinerSuccess    = "combiner.success"
+	MetricCombinerDrop       = "combiner.drop"
 )
 
 func SendAgentMetrics(client clients.MessageClient, ms []*protocol.AgentMetric) {
@@ -0,0 +1,252 @@
+package egress_proxy
+
+import (
+	"bufio"
+	"context"
+	"crypto/tls"
+	"encoding/base64"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"strconv"
+	"sync/atomic"
+	"time"
+
+	"github.com/forta-network/forta-core-go/clients/health"
+	"github.com/forta-network/forta-core-go/utils"
+	"github.com/forta-network/forta-node/config"
+	log "github.com/sirupsen/logrus"
+)
+
+const dialTimeout = time.Second * 30
+
+// EgressProxy is a forward proxy which lets the isolated agents reach only the allowlisted hosts.
+// The requests and the tunnels go through the upstream proxy from the env if there is one.
+type EgressProxy struct {
+	ctx       context.Context
+	cfg       config.AgentRuntimeConfig
+	server    *http.Server
+	transport http.RoundTripper
+	proxy     func(*http.Request) (*url.URL, error)
+
+	allowed atomic.Uint64
+	denied  atomic.Uint64
+}
+
+// NewEgressProxy creates a new egress proxy.
+func NewEgressProxy(ctx context.Context, cfg config.AgentRuntimeConfig) *EgressProxy {
+	return &EgressProxy{
+		ctx: ctx,
+		cfg: cfg,
+		transport: &http.Transport{
+			Proxy:       http.ProxyFromEnvironment,
+			DialContext: (&net.Dialer{Timeout: dialTimeout}).DialContext,
+		},
+		proxy: http.ProxyFromEnvironment,
+	}
+}
+
+// Start starts the proxy server.
+func (p *EgressProxy) Start() error {
+	p.server = &http.Server{
+		Addr:    fmt.Sprintf(":%s", config.DefaultEgressProxyPort),
+		Handler: p,
+	}
+	log.WithField("allowlist", p.cfg.EgressAllowlist).Info("starting the agent egress proxy")
+	utils.GoListenAndServe(p.server)
+	return nil
+}
+
+// Stop stops the proxy server.
+func (p *EgressProxy) Stop() error {
+	if p.server != nil {
+		return p.server.Close()
+	}
+	return nil
+}
+
+// Name returns the name of the service.
+func (p *EgressProxy) Name() string {
+	return "egress-proxy"
+}
+
+// Health implements the health.Reporter interface.
+func (p *EgressProxy) Health() health.Reports {
+	return health.Reports{
+		&health.Report{
+			Name:    "requests.allowed",
+			Status:  health.StatusInfo,
+			Details: strconv.FormatUint(p.allowed.Load(), 10),
+		},
+		&health.Report{
+			Name:    "requests.denied",
+			Status:  health.StatusInfo,
+			Details: strconv.FormatUint(p.denied.Load(), 10),
+		},
+	}
+}
+
+// ServeHTTP tunnels the CONNECT requests and forwards the plain HTTP requests to the allowlisted hosts.
+func (p *EgressProxy) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	host, port := req.URL.Hostname(), req.URL.Port()
+	defaultPort := "80"
+	if req.URL.Scheme == "https" {
+		defaultPort = "443"
+	}
+	if req.Method == http.MethodConnect {
+		host, port, _ = net.SplitHostPort(req.Host)
+		defaultPort = "443"
+	}
+	if len(port) == 0 {
+		port = defaultPort
+	}
+	logger := log.WithFields(log.Fields{
+		"host":       host,
+		"port":       port,
+		"method":     req.Method,
+		"remoteAddr": req.RemoteAddr,
+	})
+	if !p.cfg.EgressAllowed(host, port, defaultPort) {
+		p.denied.Add(1)
+		logger.Warn("denied agent egress to a host which is not in the allowlist")
+		http.Error(w, fmt.Sprintf("egress to '%s' is not allowed", net.JoinHostPort(host, port)), http.StatusForbidden)
+		return
+	}
+	p.allowed.Add(1)
+
+	if req.Method == http.MethodConnect {
+		p.tunnel(logger, w, req)
+		return
+	}
+	p.forward(logger, w, req)
+}
+
+func (p *EgressProxy) tunnel(logger *log.Entry, w http.ResponseWriter, req *http.Request) {
+	hijacker, ok := w.(http.Hijacker)
+	if !ok {
+		http.Error(w, "tunneling is not supported", http.StatusInternalServerError)
+		return
+	}
+	destConn, err := p.dialTunnel(req.Host)
+	if err != nil {
+		logger.WithError(err).Warn("failed to dial the destination")
+		http.Error(w, err.Error(), http.StatusBadGateway)
+		return
+	}
+	w.WriteHeader(http.StatusOK)
+	clientConn, _, err := hijacker.Hijack()
+	if err != nil {
+		destConn.Close()
+		logger.WithError(err).Warn("failed to hijack the connection")
+		return
+	}
+	go pipe(destConn, clientConn)
+	go pipe(clientConn, destConn)
+}
+
+// dialTunnel connects to the destination directly or through the upstream proxy.
+func (p *EgressProxy) dialTunnel(hostPort string) (net.Conn, error) {
+	ctx, cancel := context.WithTimeout(p.ctx, dialTimeout)
+	defer cancel()
+
+	dialer := &net.Dialer{Timeout: dialTimeout}
+	proxyURL, err := p.proxy(&http.Request{URL: &url.URL{Scheme: "https", Host: hostPort}})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get the upstream proxy: %v", err)
+	}
+	if proxyURL == nil {
+		return dialer.DialContext(ctx, "tcp", hostPort)
+	}
+
+	proxyAddr := proxyURL.Host
+	if len(proxyURL.Port()) == 0 {
+		port := "80"
+		if proxyURL.Scheme == "https" {
+			port = "443"
+		}
+		proxyAddr = net.JoinHostPort(proxyURL.Hostname(), port)
+	}
+	conn, err := dialer.DialContext(ctx, "tcp", proxyAddr)
+	if err != nil {
+		return nil, fmt.Errorf("failed to dial the upstream proxy: %v", err)
+	}
+	if proxyURL.Scheme == "https" {
+		tlsConn := tls.Client(conn, &tls.Config{ServerName: proxyURL.Hostname()})
+		if err := tlsConn.HandshakeContext(ctx); err != nil {
+			conn.Close()
+			return nil, fmt.Errorf("failed to connect to the upstream proxy: %v", err)
+		}
+		conn = tlsConn
+	}
+
+	connectReq := &http.Request{
+		Method: http.MethodConnect,
+		URL:    &url.URL{Opaque: hostPort},
+		Host:   hostPort,
+		Header: make(http.Header),
+	}
+	if user := proxyURL.User; user != nil {
+		password, _ := user.Password()
+		auth := base64.StdEncoding.EncodeToString([]byte(user.Username() + ":" + password))
+		connectReq.Header.Set("Proxy-Authorization", "Basic "+auth)
+	}
+	if deadline, ok := ctx.Deadline(); ok {
+		conn.SetDeadline(deadline)
+		defer conn.SetDeadline(time.Time{})
+	}
+	if err := connectReq.Write(conn); err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("failed to send the request to the upstream proxy: %v", err)
+	}
+	br := bufio.NewReader(conn)
+	resp, err := http.ReadResponse(br, connectReq)
+	if err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("failed to read the response of the upstream proxy: %v", err)
+	}
+	// the body of a failed response is dropped with the connection
+	if resp.StatusCode != http.StatusOK {
+		conn.Close()
+		return nil, fmt.Errorf("upstream proxy responded with status %d", resp.StatusCode)
+	}
+	return &bufferedConn{Conn: conn, r: br}, nil
+}
+
+// bufferedConn reads the bytes which were buffered while reading the proxy response first.
+type bufferedConn struct {
+	net.Conn
+	r *bufio.Reader
+}
+
+func (conn *bufferedConn) Read(b []byte) (int, error) {
+	return conn.r.Read(b)
+}
+
+func pipe(dst io.WriteCloser, src io.ReadCloser) {
+	defer dst.Close()
+	defer src.Close()
+	io.Copy(dst, src)
+}
+
+func (p *EgressProxy) forward(logger *log.Entry, w http.ResponseWriter, req *http.Request) {
+	outReq := req.Clone(req.Context())
+	outReq.RequestURI = ""
+	outReq.Header.Del("Proxy-Connection")
+	outReq.Header.Del("Proxy-Authorization")
+	resp, err := p.transport.RoundTrip(outReq)
+	if err != nil {
+		logger.WithError(err).Warn("failed to forward the request")
+		http.Error(w, err.Error(), http.StatusBadGateway)
+		return
+	}
+	defer resp.Body.Close()
+	for key, values := range resp.Header {
+		for _, value := range values {
+			w.Header().Add(key, value)
+		}
+	}
+	w.WriteHeader(resp.StatusCode)
+	io.Copy(w, resp.Body)
+}
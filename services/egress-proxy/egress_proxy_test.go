@@ -0,0 +1,116 @@
+package egress_proxy
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/forta-network/forta-node/config"
+	"github.com/stretchr/testify/require"
+)
+
+func testProxy(t *testing.T, allowlist []string) (*EgressProxy, *url.URL) {
+	proxy := NewEgressProxy(context.Background(), config.AgentRuntimeConfig{
+		NetworkPolicy:   config.AgentNetworkPolicyIsolated,
+		EgressAllowlist: allowlist,
+	})
+	proxy.proxy = func(*http.Request) (*url.URL, error) { return nil, nil }
+	proxyServer := httptest.NewServer(proxy)
+	t.Cleanup(proxyServer.Close)
+	proxyURL, err := url.Parse(proxyServer.URL)
+	require.NoError(t, err)
+	return proxy, proxyURL
+}
+
+func testProxyClient(t *testing.T, allowlist []string, transport *http.Transport) *http.Client {
+	_, proxyURL := testProxy(t, allowlist)
+	transport.Proxy = http.ProxyURL(proxyURL)
+	return &http.Client{Transport: transport}
+}
+
+func TestEgressProxy_Forward(t *testing.T) {
+	r := require.New(t)
+
+	dest := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		w.Write([]byte("hello"))
+	}))
+	defer dest.Close()
+	destURL, _ := url.Parse(dest.URL)
+
+	client := testProxyClient(t, []string{destURL.Host}, &http.Transport{})
+	resp, err := client.Get(dest.URL)
+	r.NoError(err)
+	defer resp.Body.Close()
+	r.Equal(http.StatusOK, resp.StatusCode)
+	body, err := io.ReadAll(resp.Body)
+	r.NoError(err)
+	r.Equal("hello", string(body))
+
+	// only the default port is allowed without a port in the allowlist
+	for _, allowlist := range [][]string{{"api.example.com"}, {"127.0.0.1"}} {
+		client = testProxyClient(t, allowlist, &http.Transport{})
+		resp, err = client.Get(dest.URL)
+		r.NoError(err)
+		resp.Body.Close()
+		r.Equal(http.StatusForbidden, resp.StatusCode)
+	}
+}
+
+func TestEgressProxy_Tunnel(t *testing.T) {
+	r := require.New(t)
+
+	dest := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		w.Write([]byte("hello"))
+	}))
+	defer dest.Close()
+	destURL, _ := url.Parse(dest.URL)
+
+	client := testProxyClient(t, []string{destURL.Host}, dest.Client().Transport.(*http.Transport).Clone())
+	resp, err := client.Get(dest.URL)
+	r.NoError(err)
+	defer resp.Body.Close()
+	body, err := io.ReadAll(resp.Body)
+	r.NoError(err)
+	r.Equal("hello", string(body))
+
+	for _, allowlist := range [][]string{nil, {"127.0.0.1"}} {
+		client = testProxyClient(t, allowlist, dest.Client().Transport.(*http.Transport).Clone())
+		_, err = client.Get(dest.URL)
+		r.Error(err)
+	}
+}
+
+func TestEgressProxy_TunnelUpstream(t *testing.T) {
+	r := require.New(t)
+
+	dest := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		w.Write([]byte("hello"))
+	}))
+	defer dest.Close()
+	destURL, _ := url.Parse(dest.URL)
+
+	upstream, upstreamURL := testProxy(t, []string{destURL.Host})
+	proxy, proxyURL := testProxy(t, []string{destURL.Host})
+	proxy.proxy = http.ProxyURL(upstreamURL)
+
+	transport := dest.Client().Transport.(*http.Transport).Clone()
+	transport.Proxy = http.ProxyURL(proxyURL)
+	resp, err := (&http.Client{Transport: transport}).Get(dest.URL)
+	r.NoError(err)
+	defer resp.Body.Close()
+	body, err := io.ReadAll(resp.Body)
+	r.NoError(err)
+	r.Equal("hello", string(body))
+	r.Equal(uint64(1), upstream.allowed.Load())
+
+	// the tunnel fails if the upstream proxy denies it
+	upstream.cfg.EgressAllowlist = nil
+	transport = dest.Client().Transport.(*http.Transport).Clone()
+	transport.Proxy = http.ProxyURL(proxyURL)
+	_, err = (&http.Client{Transport: transport}).Get(dest.URL)
+	r.Error(err)
+	r.Equal(uint64(1), upstream.denied.Load())
+}
@@ -22,8 +22,7 @@ import (
 )
 
 const (
-	blockEventWaitTimeout            = time.Minute * 20
-	defaultPublishInspectionInterval = time.Second * 5
+	blockEventWaitTimeout = time.Minute * 20
 )
 
 // Inspector runs continuous inspections.
@@ -219,6 +218,9 @@ func (ins *Inspector) Health() health.Reports {
 		reports = append(reports, &reportCopy)
 	}
 	ins.trackerMu.RUnlock()
+	if reporter, ok := ins.msgClient.(health.Reporter); ok {
+		reports = append(reports, reporter.Health()...)
+	}
 
 	return reports
 }
@@ -245,7 +247,7 @@ func (ins *Inspector) getClosestBlockToInspect() uint64 {
 }
 
 func NewInspector(ctx context.Context, cfg InspectorConfig) (*Inspector, error) {
-	msgClient := messaging.NewClient("inspector", fmt.Sprintf("%s:%s", config.DockerNatsContainerName, config.DefaultNatsPort))
+	msgClient := messaging.NewClient("inspector", cfg.Config.Nats)
 
 	chainSettings := settings.GetChainSettings(cfg.Config.ChainID)
 	inspectionInterval := chainSettings.InspectionInterval
@@ -253,10 +255,7 @@ func NewInspector(ctx context.Context, cfg InspectorConfig) (*Inspector, error)
 		inspectionInterval = *cfg.Config.InspectionConfig.BlockInterval
 	}
 
-	publishInterval := defaultPublishInspectionInterval
-	if cfg.Config.Publish.Batch.IntervalSeconds != nil {
-		publishInterval = (time.Duration(*cfg.Config.Publish.Batch.IntervalSeconds) * time.Second) / 3
-	}
+	publishInterval := cfg.Config.Publish.Batch.Interval() / 3
 	inspect.DownloadTestSavingMode = cfg.Config.InspectionConfig.NetworkSavingMode
 
 	return &Inspector{
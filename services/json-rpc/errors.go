@@ -23,7 +23,15 @@ type jsonRpcError struct {
 }
 
 func writeTooManyReqsErr(w http.ResponseWriter, req *http.Request) {
-	w.WriteHeader(http.StatusTooManyRequests)
+	writeErrorResponse(w, req, http.StatusTooManyRequests, "agent exceeds scan node request limit")
+}
+
+func writeUnavailableErr(w http.ResponseWriter, req *http.Request, err error) {
+	writeErrorResponse(w, req, http.StatusServiceUnavailable, err.Error())
+}
+
+func writeErrorResponse(w http.ResponseWriter, req *http.Request, status int, message string) {
+	w.WriteHeader(status)
 
 	var reqPayload requestPayload
 	if err := json.NewDecoder(req.Body).Decode(&reqPayload); err != nil {
@@ -36,7 +44,7 @@ func writeTooManyReqsErr(w http.ResponseWriter, req *http.Request) {
 		ID:      reqPayload.ID,
 		Error: jsonRpcError{
 			Code:    -32000,
-			Message: "agent exceeds scan node request limit",
+			Message: message,
 		},
 	}); err != nil {
 		log.WithError(err).Error("failed to write jsonrpc error response body")
@@ -20,6 +20,7 @@ import (
 	"github.com/forta-network/forta-core-go/protocol/settings"
 	"github.com/forta-network/forta-core-go/utils"
 	"github.com/forta-network/forta-node/clients"
+	"github.com/forta-network/forta-node/clients/ethclient"
 	"github.com/forta-network/forta-node/clients/messaging"
 	"github.com/forta-network/forta-node/config"
 	"github.com/forta-network/forta-node/metrics"
@@ -37,11 +38,21 @@ type JsonRpcProxy struct {
 	agentConfigMu sync.RWMutex
 
 	rateLimiter *RateLimiter
+	// limiter applies the client-side rate limit and the circuit breaker of the upstream
+	limiter *ethclient.Limiter
+
+	chainID          int
+	skipChainIDCheck bool
 
 	lastErr health.ErrorTracker
 }
 
+const chainIDCheckTimeout = time.Second * 30
+
 func (p *JsonRpcProxy) Start() error {
+	if err := p.checkChainID(); err != nil {
+		return err
+	}
 	p.registerMessageHandlers()
 
 	rpcUrl, err := url.Parse(p.cfg.Url)
@@ -67,12 +78,25 @@ func (p *JsonRpcProxy) Start() error {
 
 	p.server = &http.Server{
 		Addr:    ":8545",
-		Handler: p.metricHandler(c.Handler(rp)),
+		Handler: p.metricHandler(c.Handler(p.limitHandler(rp))),
 	}
 	utils.GoListenAndServe(p.server)
 	return nil
 }
 
+// checkChainID refuses to serve the agents from an upstream which serves another chain.
+func (p *JsonRpcProxy) checkChainID() error {
+	if p.skipChainIDCheck {
+		return nil
+	}
+	ctx, cancel := context.WithTimeout(p.ctx, chainIDCheckTimeout)
+	defer cancel()
+	if err := ethclient.CheckChainID(ctx, nil, p.cfg.Url, p.cfg.Headers, p.chainID); err != nil {
+		return fmt.Errorf("failed to check the upstream chain: %w", err)
+	}
+	return nil
+}
+
 func (p *JsonRpcProxy) metricHandler(h http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
 		t := time.Now()
@@ -96,6 +120,38 @@ func (p *JsonRpcProxy) metricHandler(h http.Handler) http.Handler {
 	})
 }
 
+// limitHandler passes the agent requests through the upstream limiter. The upstream is
+// considered failing when the proxy responds with a server error.
+func (p *JsonRpcProxy) limitHandler(h http.Handler) http.Handler {
+	if p.limiter == nil {
+		return h
+	}
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		if err := p.limiter.Wait(req.Context()); err != nil {
+			writeUnavailableErr(w, req, err)
+			return
+		}
+		sw := &statusWriter{ResponseWriter: w, status: http.StatusOK}
+		h.ServeHTTP(sw, req)
+		var err error
+		if sw.status >= http.StatusInternalServerError {
+			err = fmt.Errorf("upstream responded with status %d", sw.status)
+		}
+		p.limiter.Done(err)
+	})
+}
+
+// statusWriter captures the response status.
+type statusWriter struct {
+	http.ResponseWriter
+	status int
+}
+
+func (sw *statusWriter) WriteHeader(status int) {
+	sw.status = status
+	sw.ResponseWriter.WriteHeader(status)
+}
+
 func (p *JsonRpcProxy) findAgentFromRemoteAddr(hostPort string) (*config.AgentConfig, bool) {
 	containers, err := p.dockerClient.GetContainers(p.ctx)
 	if err != nil {
@@ -158,9 +214,14 @@ func (p *JsonRpcProxy) Name() string {
 
 // Health implements health.Reporter interface.
 func (p *JsonRpcProxy) Health() health.Reports {
-	return health.Reports{
+	reports := health.Reports{
 		p.lastErr.GetReport("api"),
 	}
+	reports = append(reports, p.limiter.Health()...)
+	if reporter, ok := p.msgClient.(health.Reporter); ok {
+		reports = append(reports, reporter.Health()...)
+	}
+	return reports
 }
 
 func (p *JsonRpcProxy) apiHealthChecker() {
@@ -189,13 +250,18 @@ func NewJsonRpcProxy(ctx context.Context, cfg config.Config) (*JsonRpcProxy, err
 	if err != nil {
 		return nil, fmt.Errorf("failed to create the global docker client: %v", err)
 	}
-	msgClient := messaging.NewClient("json-rpc-proxy", fmt.Sprintf("%s:%s", config.DockerNatsContainerName, config.DefaultNatsPort))
+	msgClient := messaging.NewClient("json-rpc-proxy", cfg.Nats)
 
 	rateLimiting := cfg.JsonRpcProxy.RateLimitConfig
 	if rateLimiting == nil {
 		rateLimiting = (*config.RateLimitConfig)(settings.GetChainSettings(cfg.ChainID).JsonRpcRateLimiting)
 	}
 
+	var limiter *ethclient.Limiter
+	if ethclient.Enabled(jCfg) {
+		limiter = ethclient.NewLimiter("json-rpc-proxy", jCfg)
+	}
+
 	return &JsonRpcProxy{
 		ctx:          ctx,
 		cfg:          jCfg,
@@ -205,5 +271,8 @@ func NewJsonRpcProxy(ctx context.Context, cfg config.Config) (*JsonRpcProxy, err
 			rateLimiting.Rate,
 			rateLimiting.Burst,
 		),
+		limiter:          limiter,
+		chainID:          cfg.ChainID,
+		skipChainIDCheck: cfg.Scan.SkipChainIDCheck,
 	}, nil
 }
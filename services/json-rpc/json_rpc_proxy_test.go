@@ -0,0 +1,71 @@
+package json_rpc
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/forta-network/forta-node/clients/ethclient"
+	"github.com/forta-network/forta-node/config"
+	"github.com/stretchr/testify/require"
+)
+
+func TestJsonRpcProxy_ChainIDMismatch(t *testing.T) {
+	r := require.New(t)
+
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"jsonrpc":"2.0","id":1,"result":"0x89"}`))
+	}))
+	defer upstream.Close()
+
+	proxy := &JsonRpcProxy{
+		ctx:     context.Background(),
+		cfg:     config.JsonRpcConfig{Url: upstream.URL},
+		chainID: 1,
+	}
+	// refuses to start before serving anything
+	r.ErrorIs(proxy.Start(), ethclient.ErrChainIDMismatch)
+	r.Nil(proxy.server)
+
+	proxy.chainID = 137
+	r.NoError(proxy.checkChainID())
+
+	proxy.chainID = 1
+	proxy.skipChainIDCheck = true
+	r.NoError(proxy.checkChainID())
+}
+
+func TestJsonRpcProxy_Limiter(t *testing.T) {
+	r := require.New(t)
+
+	var upstreamReqs int
+	upstream := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		upstreamReqs++
+		w.WriteHeader(http.StatusBadGateway)
+	})
+	proxy := &JsonRpcProxy{
+		limiter: ethclient.NewLimiter("json-rpc-proxy", config.JsonRpcConfig{
+			CircuitBreaker: config.CircuitBreakerConfig{FailureThreshold: 1, OpenDuration: time.Minute},
+		}),
+	}
+	handler := proxy.limitHandler(upstream)
+
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/", strings.NewReader(`{"id":1}`)))
+	r.Equal(http.StatusBadGateway, rec.Code)
+
+	// the circuit is open so the upstream does not receive the next request
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(`{"id":2}`)).WithContext(ctx)
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+	r.Equal(http.StatusServiceUnavailable, rec.Code)
+	r.Contains(rec.Body.String(), ethclient.ErrCircuitOpen.Error())
+	r.Equal(1, upstreamReqs)
+	r.Len(proxy.limiter.Health(), 1)
+}
@@ -0,0 +1,138 @@
+package publisher
+
+import (
+	"time"
+
+	"github.com/forta-network/forta-core-go/protocol"
+)
+
+// defaultCriticalFlushDelay gives the alerts that arrive together with a critical alert
+// some time to make it into the same batch.
+const defaultCriticalFlushDelay = time.Second
+
+// adaptiveSchedule decides when to flush the batch in the adaptive mode. The batch is flushed
+// early when the alert limit is reached or shortly after a critical alert. The empty batches
+// are stretched up to the max interval if they are going to be skipped anyway.
+type adaptiveSchedule struct {
+	interval           time.Duration
+	maxInterval        time.Duration
+	criticalFlushDelay time.Duration
+	batchLimit         int
+	skipEmpty          bool
+
+	start      time.Time
+	deadline   time.Time
+	alertCount int
+}
+
+func newAdaptiveSchedule(interval, maxInterval time.Duration, batchLimit int, skipEmpty bool) *adaptiveSchedule {
+	if maxInterval < interval {
+		maxInterval = interval
+	}
+	return &adaptiveSchedule{
+		interval:           interval,
+		maxInterval:        maxInterval,
+		criticalFlushDelay: defaultCriticalFlushDelay,
+		batchLimit:         batchLimit,
+		skipEmpty:          skipEmpty,
+	}
+}
+
+// Start starts scheduling a new batch.
+func (schedule *adaptiveSchedule) Start(now time.Time) {
+	schedule.start = now
+	schedule.deadline = now.Add(schedule.interval)
+	schedule.alertCount = 0
+}
+
+// Deadline returns the time the batch should be checked for flushing.
+func (schedule *adaptiveSchedule) Deadline() time.Time {
+	return schedule.deadline
+}
+
+// AddAlert counts the alert and tells if the batch should be flushed immediately.
+func (schedule *adaptiveSchedule) AddAlert(now time.Time, severity protocol.Finding_Severity) (flush bool) {
+	schedule.alertCount++
+	if schedule.alertCount >= schedule.batchLimit {
+		return true
+	}
+	if severity == protocol.Finding_CRITICAL {
+		if criticalDeadline := now.Add(schedule.criticalFlushDelay); criticalDeadline.Before(schedule.deadline) {
+			schedule.deadline = criticalDeadline
+		}
+	}
+	return false
+}
+
+// Expired is called at the deadline and tells if the batch should be flushed. If not,
+// the deadline is extended.
+func (schedule *adaptiveSchedule) Expired(now time.Time) (flush bool) {
+	if now.Before(schedule.deadline) {
+		return false
+	}
+	if schedule.alertCount > 0 || !schedule.skipEmpty {
+		return true
+	}
+	maxDeadline := schedule.start.Add(schedule.maxInterval)
+	if !now.Before(maxDeadline) {
+		return true
+	}
+	schedule.deadline = now.Add(schedule.interval)
+	if schedule.deadline.After(maxDeadline) {
+		schedule.deadline = maxDeadline
+	}
+	return false
+}
+
+func (pub *Publisher) prepareAdaptiveBatch() {
+	batch := (*BatchData)(&protocol.AlertBatch{ChainId: uint64(pub.cfg.ChainID)})
+
+	schedule := pub.adaptiveSchedule
+	schedule.Start(time.Now())
+	timer := time.NewTimer(time.Until(schedule.Deadline()))
+	defer timer.Stop()
+
+	resetTimer := func() {
+		if !timer.Stop() {
+			select {
+			case <-timer.C:
+			default:
+			}
+		}
+		timer.Reset(time.Until(schedule.Deadline()))
+	}
+
+	for {
+		select {
+		case notif := <-pub.notifCh:
+			alert := pub.addNotification(batch, notif)
+			if pub.shouldFlush() {
+				pub.sendPreparedBatch(batch, time.Now())
+				return
+			}
+			if alert == nil {
+				continue
+			}
+			deadline := schedule.Deadline()
+			if schedule.AddAlert(time.Now(), alert.Alert.Finding.Severity) {
+				pub.sendPreparedBatch(batch, time.Now())
+				return
+			}
+			if !schedule.Deadline().Equal(deadline) {
+				resetTimer()
+			}
+
+		case <-pub.flushCh:
+			pub.addReceivedNotifications(batch)
+			pub.sendPreparedBatch(batch, time.Now())
+			return
+
+		case <-timer.C:
+			if schedule.Expired(time.Now()) {
+				pub.sendPreparedBatch(batch, time.Now())
+				return
+			}
+			timer.Reset(time.Until(schedule.Deadline()))
+		}
+	}
+}
@@ -0,0 +1,129 @@
+package publisher
+
+import (
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/forta-network/forta-core-go/protocol"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAdaptiveSchedule_FlushOnLimit(t *testing.T) {
+	r := require.New(t)
+
+	now := time.Now()
+	schedule := newAdaptiveSchedule(time.Second*15, time.Minute*5, 2, false)
+	schedule.Start(now)
+	r.Equal(now.Add(time.Second*15), schedule.Deadline())
+
+	r.False(schedule.AddAlert(now, protocol.Finding_LOW))
+	r.True(schedule.AddAlert(now, protocol.Finding_LOW))
+}
+
+func TestAdaptiveSchedule_FlushOnCritical(t *testing.T) {
+	r := require.New(t)
+
+	now := time.Now()
+	schedule := newAdaptiveSchedule(time.Second*15, time.Minute*5, 100, false)
+	schedule.Start(now)
+
+	now = now.Add(time.Second * 3)
+	r.False(schedule.AddAlert(now, protocol.Finding_HIGH))
+	r.Equal(schedule.start.Add(time.Second*15), schedule.Deadline())
+
+	r.False(schedule.AddAlert(now, protocol.Finding_CRITICAL))
+	r.Equal(now.Add(defaultCriticalFlushDelay), schedule.Deadline())
+
+	// debounced: another critical alert does not delay the flush
+	r.False(schedule.AddAlert(now.Add(time.Millisecond*500), protocol.Finding_CRITICAL))
+	r.Equal(now.Add(defaultCriticalFlushDelay), schedule.Deadline())
+
+	r.False(schedule.Expired(now.Add(time.Millisecond * 900)))
+	r.True(schedule.Expired(now.Add(defaultCriticalFlushDelay)))
+}
+
+func TestAdaptiveSchedule_StretchEmpty(t *testing.T) {
+	r := require.New(t)
+
+	start := time.Now()
+	schedule := newAdaptiveSchedule(time.Second*15, time.Second*40, 100, true)
+	schedule.Start(start)
+
+	now := start.Add(time.Second * 15)
+	r.False(schedule.Expired(now))
+	r.Equal(start.Add(time.Second*30), schedule.Deadline())
+
+	now = start.Add(time.Second * 30)
+	r.False(schedule.Expired(now))
+	r.Equal(start.Add(time.Second*40), schedule.Deadline())
+
+	r.True(schedule.Expired(start.Add(time.Second * 40)))
+}
+
+func TestAdaptiveSchedule_NoStretchWithAlerts(t *testing.T) {
+	r := require.New(t)
+
+	start := time.Now()
+	schedule := newAdaptiveSchedule(time.Second*15, time.Second*40, 100, true)
+	schedule.Start(start)
+	schedule.AddAlert(start, protocol.Finding_INFO)
+	r.True(schedule.Expired(start.Add(time.Second * 15)))
+}
+
+func TestAdaptiveSchedule_NoStretchWithoutSkipEmpty(t *testing.T) {
+	r := require.New(t)
+
+	start := time.Now()
+	schedule := newAdaptiveSchedule(time.Second*15, time.Second*40, 100, false)
+	schedule.Start(start)
+	r.True(schedule.Expired(start.Add(time.Second * 15)))
+}
+
+func testBlockNotif(blockNumber uint64, severity protocol.Finding_Severity) *protocol.NotifyRequest {
+	return &protocol.NotifyRequest{
+		SignedAlert: &protocol.SignedAlert{
+			Alert: &protocol.Alert{Finding: &protocol.Finding{Severity: severity}},
+		},
+		EvalBlockRequest: &protocol.EvaluateBlockRequest{
+			Event: &protocol.BlockEvent{
+				BlockNumber: hexutil.EncodeUint64(blockNumber),
+				Block:       &protocol.BlockEvent_EthBlock{},
+			},
+		},
+		EvalBlockResponse: &protocol.EvaluateBlockResponse{},
+		AgentInfo:         &protocol.AgentInfo{Id: "0x1", Manifest: "manifest"},
+	}
+}
+
+func TestPrepareAdaptiveBatch_Monotonic(t *testing.T) {
+	r := require.New(t)
+
+	schedule := newAdaptiveSchedule(time.Hour, time.Hour, 2, false)
+	schedule.criticalFlushDelay = time.Millisecond * 10
+	pub := &Publisher{
+		adaptiveSchedule: schedule,
+		notifCh:          make(chan *protocol.NotifyRequest, 10),
+		batchCh:          make(chan *protocol.AlertBatch, 10),
+	}
+
+	// flushed on the limit
+	pub.notifCh <- testBlockNotif(1, protocol.Finding_LOW)
+	pub.notifCh <- testBlockNotif(2, protocol.Finding_LOW)
+	pub.prepareLatestBatch()
+	batch1 := <-pub.batchCh
+	ready1 := pub.lastBatchReady
+
+	// flushed shortly after the critical alert
+	pub.notifCh <- testBlockNotif(3, protocol.Finding_CRITICAL)
+	pub.prepareLatestBatch()
+	batch2 := <-pub.batchCh
+	ready2 := pub.lastBatchReady
+
+	r.Equal(uint64(1), batch1.BlockStart)
+	r.Equal(uint64(2), batch1.BlockEnd)
+	r.Equal(uint64(3), batch2.BlockStart)
+	r.Equal(protocol.Finding_CRITICAL, batch2.MaxSeverity)
+	r.True(batch2.BlockStart > batch1.BlockEnd)
+	r.True(ready2.After(ready1))
+}
@@ -0,0 +1,126 @@
+package publisher
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/forta-network/forta-core-go/clients/health"
+	"github.com/forta-network/forta-core-go/protocol"
+	lru "github.com/hashicorp/golang-lru"
+)
+
+const (
+	defaultDedupMaxEntries = 10000
+
+	// RepeatCountTag is added to the tags of the retained alerts. The metadata is not
+	// used because it is covered by the alert signature.
+	RepeatCountTag = "repeatCount"
+)
+
+type dedupEntry struct {
+	firstSeen   time.Time
+	batchNum    int
+	retained    *protocol.SignedAlert
+	repeatCount int
+}
+
+// alertDeduplicator drops the identical findings from the same agent within a time window.
+type alertDeduplicator struct {
+	window   time.Duration
+	entries  *lru.Cache
+	batchNum int
+
+	dropCounts map[string]int
+	mu         sync.Mutex
+
+	now func() time.Time
+}
+
+func newAlertDeduplicator(window time.Duration, maxEntries int) (*alertDeduplicator, error) {
+	if maxEntries <= 0 {
+		maxEntries = defaultDedupMaxEntries
+	}
+	entries, err := lru.New(maxEntries)
+	if err != nil {
+		return nil, err
+	}
+	return &alertDeduplicator{
+		window:     window,
+		entries:    entries,
+		dropCounts: make(map[string]int),
+		now:        time.Now,
+	}, nil
+}
+
+func findingKey(agentID string, finding *protocol.Finding) (string, error) {
+	b, err := json.Marshal(finding)
+	if err != nil {
+		return "", err
+	}
+	hash := sha256.Sum256(b)
+	return fmt.Sprintf("%s|%s", agentID, hex.EncodeToString(hash[:])), nil
+}
+
+// IsDuplicate checks if the same finding was seen from the agent within the window
+// and increments the repeat count of the retained alert if so.
+func (dedup *alertDeduplicator) IsDuplicate(agentID string, alert *protocol.SignedAlert) bool {
+	if alert == nil || alert.Alert == nil || alert.Alert.Finding == nil {
+		return false
+	}
+	key, err := findingKey(agentID, alert.Alert.Finding)
+	if err != nil {
+		return false
+	}
+
+	dedup.mu.Lock()
+	defer dedup.mu.Unlock()
+
+	now := dedup.now()
+	if value, ok := dedup.entries.Get(key); ok {
+		entry := value.(*dedupEntry)
+		if now.Sub(entry.firstSeen) < dedup.window {
+			entry.repeatCount++
+			// the alerts from the previous batches can be in use while publishing
+			if entry.batchNum == dedup.batchNum {
+				if entry.retained.Alert.Tags == nil {
+					entry.retained.Alert.Tags = make(map[string]string)
+				}
+				entry.retained.Alert.Tags[RepeatCountTag] = strconv.Itoa(entry.repeatCount)
+			}
+			dedup.dropCounts[agentID]++
+			return true
+		}
+	}
+	dedup.entries.Add(key, &dedupEntry{firstSeen: now, batchNum: dedup.batchNum, retained: alert})
+	return false
+}
+
+// NextBatch should be called after a batch is ready so that the alerts from that batch
+// are not modified anymore.
+func (dedup *alertDeduplicator) NextBatch() {
+	dedup.mu.Lock()
+	defer dedup.mu.Unlock()
+	dedup.batchNum++
+}
+
+// Health returns the drop counts per agent.
+func (dedup *alertDeduplicator) Health() (reports health.Reports) {
+	if dedup == nil {
+		return
+	}
+	dedup.mu.Lock()
+	defer dedup.mu.Unlock()
+	for agentID, count := range dedup.dropCounts {
+		reports = append(reports, &health.Report{
+			Name:    fmt.Sprintf("dedup.dropped.%s", agentID),
+			Status:  health.StatusInfo,
+			Details: strconv.Itoa(count),
+		})
+	}
+	return
+}
@@ -0,0 +1,68 @@
+package publisher
+
+import (
+	"testing"
+	"time"
+
+	"github.com/forta-network/forta-core-go/protocol"
+	"github.com/stretchr/testify/require"
+)
+
+func testDedupAlert(name string) *protocol.SignedAlert {
+	return &protocol.SignedAlert{
+		Alert: &protocol.Alert{
+			Finding: &protocol.Finding{Name: name, Severity: protocol.Finding_HIGH},
+		},
+	}
+}
+
+func TestAlertDeduplicator(t *testing.T) {
+	r := require.New(t)
+
+	dedup, err := newAlertDeduplicator(time.Minute, 10)
+	r.NoError(err)
+	now := time.Now()
+	dedup.now = func() time.Time { return now }
+
+	retained := testDedupAlert("finding1")
+	r.False(dedup.IsDuplicate("bot1", retained))
+	r.True(dedup.IsDuplicate("bot1", testDedupAlert("finding1")))
+	r.True(dedup.IsDuplicate("bot1", testDedupAlert("finding1")))
+	r.Equal("2", retained.Alert.Tags[RepeatCountTag])
+
+	// different finding or agent
+	r.False(dedup.IsDuplicate("bot1", testDedupAlert("finding2")))
+	r.False(dedup.IsDuplicate("bot2", testDedupAlert("finding1")))
+
+	// the alerts from previous batches are not modified
+	dedup.NextBatch()
+	r.True(dedup.IsDuplicate("bot1", testDedupAlert("finding1")))
+	r.Equal("2", retained.Alert.Tags[RepeatCountTag])
+
+	// window expiry
+	now = now.Add(time.Minute)
+	newRetained := testDedupAlert("finding1")
+	r.False(dedup.IsDuplicate("bot1", newRetained))
+	r.True(dedup.IsDuplicate("bot1", testDedupAlert("finding1")))
+	r.Equal("1", newRetained.Alert.Tags[RepeatCountTag])
+
+	reports := dedup.Health()
+	r.Len(reports, 1)
+	r.Equal("dedup.dropped.bot1", reports[0].Name)
+	r.Equal("4", reports[0].Details)
+}
+
+func TestAlertDeduplicator_Bounded(t *testing.T) {
+	r := require.New(t)
+
+	dedup, err := newAlertDeduplicator(time.Minute, 2)
+	r.NoError(err)
+
+	r.False(dedup.IsDuplicate("bot1", testDedupAlert("finding1")))
+	r.False(dedup.IsDuplicate("bot1", testDedupAlert("finding2")))
+	r.False(dedup.IsDuplicate("bot1", testDedupAlert("finding3")))
+	r.Equal(2, dedup.entries.Len())
+
+	// evicted
+	r.False(dedup.IsDuplicate("bot1", testDedupAlert("finding1")))
+}
@@ -0,0 +1,62 @@
+package publisher
+
+import (
+	log "github.com/sirupsen/logrus"
+)
+
+// Flush sends the alerts collected so far without waiting for the batch interval. The alerts
+// which are received afterwards are also sent as soon as there is nothing else to collect, so
+// that the publisher can be drained before stopping the node.
+func (pub *Publisher) Flush() {
+	if !pub.draining.Swap(true) {
+		log.Info("flushing the alerts")
+	}
+	select {
+	case pub.flushCh <- struct{}{}:
+	default:
+	}
+	if pub.batchQueue != nil {
+		go func() {
+			if err := pub.doDrainBatchQueue(); err != nil {
+				log.WithError(err).Warn("failed to drain the batch queue while flushing")
+			}
+		}()
+	}
+}
+
+// Pending returns the number of the alerts which are not in a batch yet and the number of the
+// batches which are being published or waiting in the queue to be resent.
+func (pub *Publisher) Pending() (alerts int, batches int) {
+	alerts = int(pub.unsentAlerts.Load()) + len(pub.notifCh)
+	batches = int(pub.publishing.Load())
+	if pub.batchQueue != nil {
+		batches += pub.batchQueue.Len()
+	}
+	return
+}
+
+// shouldFlush tells if the batch should be sent right away while draining.
+func (pub *Publisher) shouldFlush() bool {
+	return pub.draining.Load() && len(pub.notifCh) == 0
+}
+
+// addReceivedNotifications adds the notifications which are already received to the batch
+// before flushing it.
+func (pub *Publisher) addReceivedNotifications(batch *BatchData) {
+	for {
+		select {
+		case notif := <-pub.notifCh:
+			pub.addNotification(batch, notif)
+		default:
+			return
+		}
+	}
+}
+
+// clearFlush drops the pending flush request since the batch is sent anyway.
+func (pub *Publisher) clearFlush() {
+	select {
+	case <-pub.flushCh:
+	default:
+	}
+}
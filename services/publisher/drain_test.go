@@ -0,0 +1,62 @@
+package publisher
+
+import (
+	"testing"
+	"time"
+
+	"github.com/forta-network/forta-core-go/protocol"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFlush(t *testing.T) {
+	r := require.New(t)
+
+	pub := &Publisher{
+		batchInterval: time.Hour,
+		batchLimit:    100,
+		batchTicker:   time.NewTicker(time.Hour),
+		notifCh:       make(chan *protocol.NotifyRequest, 10),
+		batchCh:       make(chan *protocol.AlertBatch, 10),
+		flushCh:       make(chan struct{}, 1),
+	}
+	defer pub.batchTicker.Stop()
+
+	pub.notifCh <- testBlockNotif(1, protocol.Finding_LOW)
+	alerts, batches := pub.Pending()
+	r.Equal(1, alerts)
+	r.Zero(batches)
+
+	// the batch is sent before the batch interval
+	pub.Flush()
+	pub.prepareLatestBatch()
+	alerts, batches = pub.Pending()
+	r.Zero(alerts)
+	r.Equal(1, batches)
+	batch := <-pub.batchCh
+	r.Equal(uint64(1), batch.BlockStart)
+
+	// the next alerts are sent as soon as there is nothing else to collect
+	pub.notifCh <- testBlockNotif(2, protocol.Finding_LOW)
+	pub.notifCh <- testBlockNotif(3, protocol.Finding_LOW)
+	pub.prepareLatestBatch()
+	batch = <-pub.batchCh
+	r.Equal(uint64(2), batch.BlockStart)
+	r.Equal(uint64(3), batch.BlockEnd)
+}
+
+func TestFlush_Adaptive(t *testing.T) {
+	r := require.New(t)
+
+	pub := &Publisher{
+		adaptiveSchedule: newAdaptiveSchedule(time.Hour, time.Hour, 100, false),
+		notifCh:          make(chan *protocol.NotifyRequest, 10),
+		batchCh:          make(chan *protocol.AlertBatch, 10),
+		flushCh:          make(chan struct{}, 1),
+	}
+
+	pub.notifCh <- testBlockNotif(1, protocol.Finding_LOW)
+	pub.Flush()
+	pub.prepareLatestBatch()
+	batch := <-pub.batchCh
+	r.Equal(uint64(1), batch.BlockStart)
+}
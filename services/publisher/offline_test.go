@@ -0,0 +1,56 @@
+package publisher
+
+import (
+	"path"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/accounts/keystore"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/forta-network/forta-core-go/clients/webhook/client/operations"
+	"github.com/forta-network/forta-core-go/protocol"
+	mock_clients "github.com/forta-network/forta-node/clients/mocks"
+	"github.com/forta-network/forta-node/clients/signer"
+	"github.com/forta-network/forta-node/store"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+)
+
+type testLocalAlertClient struct {
+	sent []*operations.SendAlertsParams
+}
+
+func (client *testLocalAlertClient) SendAlerts(params *operations.SendAlertsParams, opts ...operations.ClientOption) (*operations.SendAlertsOK, error) {
+	client.sent = append(client.sent, params)
+	return &operations.SendAlertsOK{}, nil
+}
+
+func TestPublishNextBatch_Offline(t *testing.T) {
+	r := require.New(t)
+
+	privateKey, err := crypto.GenerateKey()
+	r.NoError(err)
+	key := &keystore.Key{Address: crypto.PubkeyToAddress(privateKey.PublicKey), PrivateKey: privateKey}
+
+	// no calls to the alert api
+	alertClient := mock_clients.NewMockAlertAPIClient(gomock.NewController(t))
+	localAlertClient := &testLocalAlertClient{}
+	pub := &Publisher{
+		cfg:               PublisherConfig{Signer: signer.NewLocalSigner(key)},
+		metricsAggregator: NewMetricsAggregator(time.Minute),
+		alertClient:       alertClient,
+		localAlertClient:  localAlertClient,
+		batchRefStore:     store.NewFileStringStore(path.Join(t.TempDir(), ".last-batch")),
+	}
+	pub.cfg.Config.Offline = true
+
+	batch := &protocol.AlertBatch{BlockStart: 1, BlockEnd: 1}
+	notif := testBlockNotif(1, protocol.Finding_HIGH)
+	notif.SignedAlert.Alert.Agent = &protocol.AgentInfo{Id: "0x1"}
+	(*BatchData)(batch).AppendAlert(notif)
+	published, err := pub.publishNextBatch(batch)
+	r.NoError(err)
+	r.True(published)
+	r.Len(localAlertClient.sent, 1)
+	r.Len(localAlertClient.sent[0].Payload.Alerts, 1)
+}
@@ -0,0 +1,84 @@
+package publisher
+
+import (
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/forta-network/forta-core-go/clients/health"
+)
+
+// the window of the published alert count and the size of the buckets in it
+const (
+	publishedAlertsWindow     = time.Hour
+	publishedAlertsBucketSize = time.Minute
+)
+
+type publishedAlertsBucket struct {
+	start time.Time
+	count int
+}
+
+// publishedAlerts counts the alerts published in the last hour by using a bucket per minute.
+type publishedAlerts struct {
+	buckets []*publishedAlertsBucket
+	mu      sync.Mutex
+}
+
+func newPublishedAlerts() *publishedAlerts {
+	return &publishedAlerts{}
+}
+
+// Add counts the alerts published at the given time.
+func (pa *publishedAlerts) Add(now time.Time, count int) {
+	if pa == nil || count <= 0 {
+		return
+	}
+	pa.mu.Lock()
+	defer pa.mu.Unlock()
+	pa.expire(now)
+	start := now.Truncate(publishedAlertsBucketSize)
+	if n := len(pa.buckets); n > 0 && pa.buckets[n-1].start.Equal(start) {
+		pa.buckets[n-1].count += count
+		return
+	}
+	pa.buckets = append(pa.buckets, &publishedAlertsBucket{start: start, count: count})
+}
+
+// Count returns the number of the alerts published in the last hour.
+func (pa *publishedAlerts) Count(now time.Time) (count int) {
+	if pa == nil {
+		return 0
+	}
+	pa.mu.Lock()
+	defer pa.mu.Unlock()
+	pa.expire(now)
+	for _, bucket := range pa.buckets {
+		count += bucket.count
+	}
+	return
+}
+
+// expire removes the buckets which are out of the window.
+func (pa *publishedAlerts) expire(now time.Time) {
+	cutoff := now.Add(-publishedAlertsWindow)
+	var i int
+	for i < len(pa.buckets) && !pa.buckets[i].start.After(cutoff) {
+		i++
+	}
+	pa.buckets = pa.buckets[i:]
+}
+
+// Health implements the health.Reporter interface.
+func (pa *publishedAlerts) Health() health.Reports {
+	if pa == nil {
+		return nil
+	}
+	return health.Reports{
+		&health.Report{
+			Name:    "alerts.published.last-hour",
+			Status:  health.StatusInfo,
+			Details: strconv.Itoa(pa.Count(time.Now())),
+		},
+	}
+}
@@ -0,0 +1,31 @@
+package publisher
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestPublishedAlerts(t *testing.T) {
+	r := require.New(t)
+
+	pa := newPublishedAlerts()
+	start := time.Date(2022, 12, 1, 10, 0, 0, 0, time.UTC)
+	pa.Add(start, 2)
+	pa.Add(start.Add(time.Second*10), 3)
+	pa.Add(start.Add(time.Minute*30), 0)
+	pa.Add(start.Add(time.Minute*30), 4)
+	r.Len(pa.buckets, 2)
+	r.Equal(9, pa.Count(start.Add(time.Minute*59)))
+
+	// the first bucket leaves the window after an hour
+	r.Equal(4, pa.Count(start.Add(time.Hour)))
+	r.Equal(0, pa.Count(start.Add(time.Hour*2)))
+	r.Len(pa.buckets, 0)
+
+	// nil-safe for the publishers which do not count
+	var nilCounter *publishedAlerts
+	nilCounter.Add(start, 1)
+	r.Equal(0, nilCounter.Count(start))
+}
@@ -4,15 +4,18 @@ import (
 	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"math/big"
+	"net/http"
 	"os"
 	"path"
+	"strconv"
 	"sync"
+	"sync/atomic"
 	"time"
 
-	"github.com/ethereum/go-ethereum/accounts/keystore"
 	"github.com/ethereum/go-ethereum/common/hexutil"
 	"github.com/ethereum/go-ethereum/core/types"
 	"github.com/forta-network/forta-core-go/clients/health"
@@ -27,10 +30,14 @@ import (
 	"github.com/forta-network/forta-core-go/utils"
 	"github.com/forta-network/forta-node/clients"
 	"github.com/forta-network/forta-node/clients/alertapi"
+	"github.com/forta-network/forta-node/clients/ipfsgateway"
 	"github.com/forta-network/forta-node/clients/messaging"
+	"github.com/forta-network/forta-node/clients/signer"
 	"github.com/forta-network/forta-node/clients/storagegrpc"
 	"github.com/forta-network/forta-node/config"
+	"github.com/forta-network/forta-node/metrics"
 	"github.com/forta-network/forta-node/services/publisher/webhooklog"
+	"github.com/forta-network/forta-node/services/publisher/webhooks"
 	"github.com/forta-network/forta-node/services/storage"
 	"github.com/forta-network/forta-node/store"
 	ipfsapi "github.com/ipfs/go-ipfs-api"
@@ -39,12 +46,16 @@ import (
 )
 
 const (
-	defaultInterval        = time.Second * 15
 	defaultBatchLimit      = 500
 	defaultBatchBufferSize = 100
 
 	fastReportInterval = time.Minute
 	slowReportInterval = time.Minute * 15
+
+	defaultBatchQueueDrainInterval = time.Second * 30
+	batchQueueDirName              = ".batch-queue"
+
+	unsignedBatchRetryInterval = time.Second * 15
 )
 
 // Publisher receives, collects and publishes alerts.
@@ -62,6 +73,13 @@ type Publisher struct {
 
 	batchRefStore    store.StringStore
 	lastReceiptStore store.StringStore
+	batchQueue       store.BatchQueue
+	unsigned         *unsignedBatches
+	webhooks         *webhooks.Sinks
+	dedup            *alertDeduplicator
+	severityFilter   *severityFilter
+	uploader         *batchUploader
+	sendMu           sync.Mutex // serializes direct sends and queue drains
 
 	server *grpc.Server
 
@@ -74,15 +92,23 @@ type Publisher struct {
 	notifCh       chan *protocol.NotifyRequest
 	batchCh       chan *protocol.AlertBatch
 
+	// these help flushing the alerts while draining
+	flushCh      chan struct{}
+	draining     atomic.Bool
+	unsentAlerts atomic.Int64
+	publishing   atomic.Int64
+
 	lastBatchPublish        health.TimeTracker
 	lastBatchPublishAttempt health.TimeTracker
 	lastBatchSkip           health.TimeTracker
 	lastBatchSkipReason     health.MessageTracker
 	lastBatchPublishErr     health.ErrorTracker
 	lastMetricsFlush        health.TimeTracker
+	publishedAlerts         *publishedAlerts
 
 	// these help following single ticker and keep send intervals on track
 	batchTicker          *time.Ticker
+	adaptiveSchedule     *adaptiveSchedule
 	lastBatchReady       time.Time
 	lastBatchReadyMu     sync.RWMutex
 	lastBatchSendAttempt time.Time
@@ -120,7 +146,7 @@ type IPFS interface {
 
 type PublisherConfig struct {
 	ChainID         int
-	Key             *keystore.Key
+	Signer          signer.Signer
 	PublisherConfig config.PublisherConfig
 	ReleaseSummary  *release.ReleaseSummary
 	Config          config.Config
@@ -132,6 +158,12 @@ func (pub *Publisher) Notify(ctx context.Context, req *protocol.NotifyRequest) (
 }
 
 func (pub *Publisher) publishNextBatch(batch *protocol.AlertBatch) (published bool, err error) {
+	pub.prepareBatch(batch)
+	return pub.publishPreparedBatch(batch, true)
+}
+
+// prepareBatch adds the metrics and the latest node info to the batch.
+func (pub *Publisher) prepareBatch(batch *protocol.AlertBatch) {
 	// flush only if we are publishing so we can make the best use of aggregated metrics
 	if _, skip := pub.shouldSkipPublishing(batch); !skip {
 		var flushed bool
@@ -157,10 +189,6 @@ func (pub *Publisher) publishNextBatch(batch *protocol.AlertBatch) (published bo
 			Version: pub.cfg.ReleaseSummary.Version,
 		}
 	}
-	lastBatchRef, err := pub.batchRefStore.Get()
-	if err == nil {
-		batch.Parent = lastBatchRef
-	}
 
 	// use the latest block input from scanner, fall back to latest block number from the batch
 	pub.latestBlockInputMu.RLock()
@@ -169,10 +197,22 @@ func (pub *Publisher) publishNextBatch(batch *protocol.AlertBatch) (published bo
 	if batch.LatestBlockInput == 0 {
 		batch.LatestBlockInput = batch.BlockEnd
 	}
+}
+
+// publishPreparedBatch signs and sends the batch. The parent should be set only in the first
+// attempt: the batch is retried after the signer becomes available and a retried batch
+// should have the same ref.
+func (pub *Publisher) publishPreparedBatch(batch *protocol.AlertBatch, setParent bool) (published bool, err error) {
+	if setParent {
+		lastBatchRef, err := pub.batchRefStore.Get()
+		if err == nil {
+			batch.Parent = lastBatchRef
+		}
+	}
 
-	signedBatch, err := security.SignBatch(pub.cfg.Key, batch)
+	signedBatch, err := signer.SignBatch(pub.cfg.Signer, batch)
 	if err != nil {
-		return false, fmt.Errorf("failed to build envelope: %v", err)
+		return false, fmt.Errorf("failed to build envelope: %w", err)
 	}
 
 	var buf bytes.Buffer
@@ -181,6 +221,9 @@ func (pub *Publisher) publishNextBatch(batch *protocol.AlertBatch) (published bo
 	}
 	log.Tracef("alert payload: %s", string(buf.Bytes()))
 
+	// never blocks the publishing
+	pub.webhooks.Send(batch)
+
 	if pub.skipPublish {
 		const reason = "skipping batch, because skipPublish is enabled"
 		log.WithFields(
@@ -197,6 +240,9 @@ func (pub *Publisher) publishNextBatch(batch *protocol.AlertBatch) (published bo
 	}
 
 	if reason, skip := pub.shouldSkipPublishing(batch); skip {
+		if batch.AlertCount > 0 {
+			pub.countSuppressed(batch)
+		}
 		log.WithField("reason", reason).Info("skipping batch")
 		pub.lastBatchSkip.Set()
 		pub.lastBatchSkipReason.Set(reason)
@@ -207,37 +253,8 @@ func (pub *Publisher) publishNextBatch(batch *protocol.AlertBatch) (published bo
 	pub.lastBatchSendAttempt = pub.lastBatchReady
 	pub.lastBatchReadyMu.RUnlock()
 
-	if pub.cfg.Config.LocalModeConfig.Enable {
-		scannerJwt, err := security.CreateScannerJWT(
-			pub.cfg.Key, map[string]interface{}{
-				"localMode": "true",
-			},
-		)
-		alertBatch := transform.ToWebhookAlertBatch(batch)
-		if !pub.cfg.Config.LocalModeConfig.IncludeMetrics {
-			log.Debug("excluding metrics due to local mode config")
-			alertBatch.Metrics = nil
-		}
-		_, err = pub.localAlertClient.SendAlerts(
-			&operations.SendAlertsParams{
-				Context:       context.Background(),
-				Payload:       alertBatch,
-				Authorization: utils.StringPtr(fmt.Sprintf("Bearer %s", scannerJwt)),
-			},
-		)
-		if err != nil {
-			log.WithError(err).Error("failed to send local mode alerts")
-			return false, err
-		}
-		if alertBatch != nil {
-			log.WithFields(
-				log.Fields{
-					"alertCount":   len(alertBatch.Alerts),
-					"metricsCount": len(alertBatch.Metrics),
-				},
-			).Info("successfully sent local mode alerts")
-		}
-		return true, nil
+	if pub.cfg.Config.LocalModeConfig.Enable || pub.cfg.Config.OfflineSkip("alert-api") {
+		return pub.sendLocalAlerts(batch)
 	}
 
 	cid, err := pub.ipfs.CalculateFileHash(buf.Bytes())
@@ -247,6 +264,7 @@ func (pub *Publisher) publishNextBatch(batch *protocol.AlertBatch) (published bo
 	if err := pub.batchRefStore.Put(cid); err != nil {
 		return false, fmt.Errorf("failed to write last batch ref: %v", err)
 	}
+	pub.uploader.Upload(cid, buf.Bytes())
 
 	logger := log.WithFields(
 		log.Fields{
@@ -259,15 +277,104 @@ func (pub *Publisher) publishNextBatch(batch *protocol.AlertBatch) (published bo
 		},
 	)
 
+	parts := []*protocol.AlertBatch{batch}
+	maxBatchBytes := pub.cfg.PublisherConfig.Batch.MaxBatchBytes
+	if maxBatchBytes > 0 && buf.Len() > maxBatchBytes {
+		parts = splitBatchBySize(batch, maxBatchBytes)
+		logger.WithField("parts", len(parts)).Info("batch is too large - splitting")
+	}
+
+	pub.sendMu.Lock()
+	defer pub.sendMu.Unlock()
+
+	if len(parts) == 1 {
+		return pub.sendBatch(logger, batch, signedBatch, cid)
+	}
+	return pub.sendParts(logger, parts, cid)
+}
+
+// sendParts sends the parts of a split batch in order. The rest of the parts are still sent after
+// a part fails so that they are queued behind the failed part instead of being lost. The whole
+// batch is retried later if the signer is unavailable.
+func (pub *Publisher) sendParts(logger *log.Entry, parts []*protocol.AlertBatch, ref string) (sent bool, err error) {
+	sent = true
+	for i, part := range parts {
+		partSent, partErr := pub.sendBatch(logger, part, nil, partRef(ref, i))
+		if signer.IsUnavailable(partErr) {
+			return false, partErr
+		}
+		if partErr != nil && err == nil {
+			err = partErr
+		}
+		sent = sent && partSent
+	}
+	return sent, err
+}
+
+// sendLocalAlerts sends the batch to the local alert webhook or the local alert log.
+func (pub *Publisher) sendLocalAlerts(batch *protocol.AlertBatch) (published bool, err error) {
+	scannerJwt, err := signer.CreateScannerJWT(
+		pub.cfg.Signer, map[string]interface{}{
+			"localMode": "true",
+		},
+	)
+	if err != nil {
+		return false, fmt.Errorf("failed to create the local alerts jwt: %w", err)
+	}
+	alertBatch := transform.ToWebhookAlertBatch(batch)
+	if !pub.cfg.Config.LocalModeConfig.IncludeMetrics {
+		log.Debug("excluding metrics due to local mode config")
+		alertBatch.Metrics = nil
+	}
+	_, err = pub.localAlertClient.SendAlerts(
+		&operations.SendAlertsParams{
+			Context:       context.Background(),
+			Payload:       alertBatch,
+			Authorization: utils.StringPtr(fmt.Sprintf("Bearer %s", scannerJwt)),
+		},
+	)
+	if err != nil {
+		log.WithError(err).Error("failed to send local alerts")
+		return false, err
+	}
+	if alertBatch != nil {
+		log.WithFields(
+			log.Fields{
+				"alertCount":   len(alertBatch.Alerts),
+				"metricsCount": len(alertBatch.Metrics),
+			},
+		).Info("successfully sent local alerts")
+		pub.publishedAlerts.Add(time.Now(), len(alertBatch.Alerts))
+	}
+	return true, nil
+}
+
+// sendBatch sends the batch with given ref. If the alert API rejects the batch because
+// it is too large, the batch is split in half and the parts are sent instead.
+func (pub *Publisher) sendBatch(
+	logger *log.Entry, batch *protocol.AlertBatch, signedBatch *protocol.SignedPayload, ref string,
+) (sent bool, err error) {
+	logger = logger.WithFields(log.Fields{
+		"ref":        ref,
+		"alertCount": batch.AlertCount,
+	})
+
+	if signedBatch == nil {
+		signedBatch, err = signer.SignBatch(pub.cfg.Signer, batch)
+		if err != nil {
+			return false, fmt.Errorf("failed to build envelope: %w", err)
+		}
+	}
+
 	var lastReceipt string
 	lr, err := pub.lastReceiptStore.Get()
 	if err == nil {
 		lastReceipt = lr
 	}
 
-	signedBatchSummary, err := security.SignBatchSummary(
-		pub.cfg.Key, &protocol.BatchSummary{
-			Batch:            cid,
+	signedBatchSummary, err := signer.SignBatchSummary(
+		pub.cfg.Signer, &protocol.BatchSummary{
+			Batch:            ref,
 			ChainId:          batch.ChainId,
 			BlockStart:       batch.BlockStart,
 			BlockEnd:         batch.BlockEnd,
@@ -283,9 +390,9 @@ func (pub *Publisher) publishNextBatch(batch *protocol.AlertBatch) (published bo
 		return false, err
 	}
 
-	scannerJwt, err := security.CreateScannerJWT(
-		pub.cfg.Key, map[string]interface{}{
-			"batch": cid,
+	scannerJwt, err := signer.CreateScannerJWT(
+		pub.cfg.Signer, map[string]interface{}{
+			"batch": ref,
 		},
 	)
 
@@ -294,66 +401,175 @@ func (pub *Publisher) publishNextBatch(batch *protocol.AlertBatch) (published bo
 		return false, err
 	}
 
-	scannerAddr := pub.cfg.Key.Address.Hex()
-	resp, err := pub.alertClient.PostBatch(&domain.AlertBatchRequest{
-		Scanner:            scannerAddr,
+	request := &domain.AlertBatchRequest{
+		Scanner:            pub.cfg.Signer.Address().Hex(),
 		ChainID:            int64(batch.ChainId),
 		BlockStart:         int64(batch.BlockStart),
 		BlockEnd:           int64(batch.BlockEnd),
 		AlertCount:         int64(batch.AlertCount),
 		MaxSeverity:        int64(batch.MaxSeverity),
-		Ref:                cid,
+		Ref:                ref,
 		SignedBatch:        signedBatch,
 		SignedBatchSummary: signedBatchSummary,
-	}, scannerJwt)
+	}
 
+	// keep the order: the queued batches need to be sent first
+	if pub.batchQueue != nil && pub.batchQueue.Len() > 0 {
+		logger.Info("batch queue is not empty - queueing batch")
+		return false, pub.queueBatch(request)
+	}
+
+	resp, err := pub.alertClient.PostBatch(request, scannerJwt)
+	if isTooLarge(err) && batchItemCount(batch) > 1 {
+		logger.Warn("alert api rejected the batch as too large - splitting in half")
+		return pub.sendParts(logger, splitBatch(batch, 2), ref)
+	}
 	if err != nil {
 		logger.WithError(err).Error("alert while sending batch")
+		var queued bool
+		if pub.batchQueue != nil && (alertapi.IsRetryable(err) || signer.IsUnavailable(err)) {
+			if qErr := pub.queueBatch(request); qErr != nil {
+				logger.WithError(qErr).Error("failed to queue batch")
+			} else {
+				logger.Info("queued batch to send later")
+				queued = true
+			}
+		}
+		// retry the whole batch later if it could not be queued
+		if !queued && signer.IsUnavailable(err) {
+			return false, err
+		}
 		return false, fmt.Errorf("failed to send the alert tx: %v", err)
 	}
 
-	if resp.SignedReceipt != nil {
-		// store off receipt id
-		if err := pub.lastReceiptStore.Put(resp.ReceiptID); err != nil {
-			logger.WithError(err).Error("failed to marshal receipt")
-			return true, err
+	logger = pub.storeReceipt(logger, resp)
+	logger.Info("alert batch")
+	pub.publishedAlerts.Add(time.Now(), int(batch.AlertCount))
+
+	return true, nil
+}
+
+func isTooLarge(err error) bool {
+	var apiErr *alertapi.APIError
+	return errors.As(err, &apiErr) && apiErr.StatusCode == http.StatusRequestEntityTooLarge
+}
+
+// storeReceipt stores the receipt from the alert batch response and returns the logger with the receipt fields.
+func (pub *Publisher) storeReceipt(logger *log.Entry, resp *domain.AlertBatchResponse) *log.Entry {
+	if resp.SignedReceipt == nil {
+		return logger
+	}
+
+	// store off receipt id
+	if err := pub.lastReceiptStore.Put(resp.ReceiptID); err != nil {
+		logger.WithError(err).Error("failed to marshal receipt")
+		return logger
+	}
+	logger = logger.WithFields(
+		log.Fields{
+			"receiptId": resp.ReceiptID,
+		},
+	)
+
+	// if for some reason receipt can't marshal, log and move on
+	b, err := json.Marshal(resp.SignedReceipt)
+	if err != nil {
+		logger.WithError(err).Error("failed to marshal receipt (not saving receipt)")
+		return logger
+	}
+	logger = logger.WithFields(log.Fields{
+		"receipt": string(b),
+	})
+
+	ctx, cancel := context.WithTimeout(pub.ctx, time.Second*10)
+	defer cancel()
+	putResp, err := pub.storage.Put(ctx, &protocol.PutRequest{
+		User:  pub.cfg.Signer.Address().Hex(),
+		Kind:  storage.KindBatchReceipt,
+		Bytes: b,
+	})
+	if err != nil {
+		logger.WithError(err).Warn("failed to store batch receipt")
+		return logger
+	}
+	return logger.WithFields(log.Fields{
+		"storedReceiptRef":  putResp.ContentId,
+		"storedReceiptPath": putResp.ContentPath,
+	})
+}
+
+func (pub *Publisher) queueBatch(request *domain.AlertBatchRequest) error {
+	return pub.batchQueue.Push(&store.QueuedBatch{Request: request})
+}
+
+func (pub *Publisher) drainBatchQueue() {
+	ticker := time.NewTicker(defaultBatchQueueDrainInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-pub.ctx.Done():
+			return
+		case <-ticker.C:
+			if err := pub.doDrainBatchQueue(); err != nil {
+				log.WithError(err).Warn("failed to drain the batch queue - will retry")
+			}
+		}
+	}
+}
+
+// doDrainBatchQueue sends the queued batches in order until the queue is empty or sending fails.
+func (pub *Publisher) doDrainBatchQueue() error {
+	pub.sendMu.Lock()
+	defer pub.sendMu.Unlock()
+
+	for {
+		queued, err := pub.batchQueue.Peek()
+		if errors.Is(err, store.ErrBatchQueueEmpty) {
+			return nil
 		}
-		logger = logger.WithFields(
+		if err != nil {
+			return err
+		}
+		request := queued.Request
+		logger := log.WithFields(
 			log.Fields{
-				"receiptId": resp.ReceiptID,
+				"blockStart": request.BlockStart,
+				"blockEnd":   request.BlockEnd,
+				"alertCount": request.AlertCount,
+				"ref":        request.Ref,
+				"queuedAt":   queued.QueuedAt.Format(time.RFC3339),
 			},
 		)
 
-		// if for some reason receipt can't marshal, log and move on
-		b, err := json.Marshal(resp.SignedReceipt)
+		scannerJwt, err := signer.CreateScannerJWT(
+			pub.cfg.Signer, map[string]interface{}{
+				"batch": request.Ref,
+			},
+		)
 		if err != nil {
-			logger.WithError(err).Error("failed to marshal receipt (not saving receipt)")
-			return true, nil
-		}
-		logger = logger.WithFields(log.Fields{
-			"receipt": string(b),
-		})
-
-		ctx, cancel := context.WithTimeout(pub.ctx, time.Second*10)
-		defer cancel()
-		putResp, err := pub.storage.Put(ctx, &protocol.PutRequest{
-			User:  scannerAddr,
-			Kind:  storage.KindBatchReceipt,
-			Bytes: b,
-		})
+			return fmt.Errorf("failed to sign cid: %v", err)
+		}
+		resp, err := pub.alertClient.PostBatch(request, scannerJwt)
+		if err != nil && (alertapi.IsRetryable(err) || signer.IsUnavailable(err)) {
+			return fmt.Errorf("failed to send queued batch: %v", err)
+		}
 		if err != nil {
-			logger.WithError(err).Warn("failed to store batch receipt")
-		} else {
-			logger = logger.WithFields(log.Fields{
-				"storedReceiptRef":  putResp.ContentId,
-				"storedReceiptPath": putResp.ContentPath,
-			})
+			logger.WithError(err).Error("alert api rejected queued batch - dropping")
+			if err := pub.batchQueue.Pop(); err != nil {
+				return fmt.Errorf("failed to remove rejected batch from queue: %v", err)
+			}
+			continue
 		}
-	}
-
-	logger.Info("alert batch")
+		if err := pub.batchQueue.Pop(); err != nil {
+			return fmt.Errorf("failed to remove sent batch from queue: %v", err)
+		}
+		pub.lastBatchPublish.Set()
+		pub.lastBatchPublishErr.Set(nil)
+		pub.publishedAlerts.Add(time.Now(), int(request.AlertCount))
 
-	return true, nil
+		logger = pub.storeReceipt(logger, resp)
+		logger.Info("sent queued alert batch")
+	}
 }
 
 func (pub *Publisher) shouldSkipPublishing(batch *protocol.AlertBatch) (string, bool) {
@@ -361,11 +577,14 @@ func (pub *Publisher) shouldSkipPublishing(batch *protocol.AlertBatch) (string,
 		return "", false
 	}
 
-	if batch.AlertCount > 0 {
+	if batch.AlertCount > 0 && !pub.severityFilter.ShouldSuppress(batch) {
 		return "", false
 	}
 	// after this line, alert count is considered as zero
-	const becauseThereAreNoAlerts = "because there are no alerts"
+	becauseThereAreNoAlerts := "because there are no alerts"
+	if batch.AlertCount > 0 {
+		becauseThereAreNoAlerts = "because there are no alerts at or above the minimum severity"
+	}
 
 	localModeConfig := &pub.cfg.Config.LocalModeConfig
 	lastBatchSendAttempt := pub.lastBatchSendAttempt
@@ -453,19 +672,77 @@ func (pub *Publisher) handleInspectionResults(payload *protocol.InspectionResult
 }
 
 func (pub *Publisher) publishBatches() {
-	for batch := range pub.batchCh {
-		pub.lastBatchPublishAttempt.Set()
-		published, err := pub.publishNextBatch(batch)
-		if published {
-			pub.lastBatchPublish.Set()
+	ticker := time.NewTicker(unsignedBatchRetryInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case batch := <-pub.batchCh:
+			pub.publishBatch(batch)
+		case <-ticker.C:
+			pub.retryUnsignedBatches()
 		}
+	}
+}
+
+// publishBatch publishes the batch or keeps it to retry later if the signer is unavailable.
+func (pub *Publisher) publishBatch(batch *protocol.AlertBatch) {
+	// keep the order: the batches wait behind the ones which could not be signed
+	if pub.unsigned.Len() > 0 {
+		pub.prepareBatch(batch)
+		pub.queueUnsignedBatch(&unsignedBatch{batch: batch})
+		return
+	}
+	pub.lastBatchPublishAttempt.Set()
+	published, err := pub.publishNextBatch(batch)
+	if signer.IsUnavailable(err) {
 		pub.lastBatchPublishErr.Set(err)
-		if err != nil {
-			log.Errorf("failed to publish alert batch: %v", err)
+		log.WithError(err).Warn("signer is unavailable - will retry publishing the batch")
+		pub.queueUnsignedBatch(&unsignedBatch{batch: batch, attempted: true})
+		return
+	}
+	pub.finishBatch(published, err)
+}
+
+// retryUnsignedBatches publishes the batches which are waiting for the signer in order. The
+// parts of a split batch which were sent before the signer became unavailable are sent again
+// with the same refs.
+func (pub *Publisher) retryUnsignedBatches() {
+	for {
+		next := pub.unsigned.Peek()
+		if next == nil {
+			return
+		}
+		pub.lastBatchPublishAttempt.Set()
+		published, err := pub.publishPreparedBatch(next.batch, !next.attempted)
+		next.attempted = true
+		if signer.IsUnavailable(err) {
+			pub.lastBatchPublishErr.Set(err)
+			log.WithError(err).WithField("batches", pub.unsigned.Len()).Warn("signer is still unavailable - will retry publishing the batches")
+			return
 		}
+		pub.unsigned.Pop()
+		pub.finishBatch(published, err)
+	}
+}
+
+func (pub *Publisher) queueUnsignedBatch(batch *unsignedBatch) {
+	if pub.unsigned.Push(batch) {
+		log.Warn("too many batches are waiting for the signer - dropped the oldest batch")
+		pub.publishing.Add(-1)
 	}
 }
 
+func (pub *Publisher) finishBatch(published bool, err error) {
+	if published {
+		pub.lastBatchPublish.Set()
+	}
+	pub.lastBatchPublishErr.Set(err)
+	if err != nil {
+		log.Errorf("failed to publish alert batch: %v", err)
+	}
+	pub.publishing.Add(-1)
+}
+
 func (pub *Publisher) prepareBatches() {
 	for {
 		pub.prepareLatestBatch()
@@ -703,78 +980,121 @@ func (tr *TransactionResults) GetAgentAlerts(agent *protocol.AgentInfo) *protoco
 	return aa
 }
 
+// countSuppressed counts the suppressed batch and adds its findings to the agent metrics.
+func (pub *Publisher) countSuppressed(batch *protocol.AlertBatch) {
+	var agentMetrics []*protocol.AgentMetric
+	for agentID, count := range pub.severityFilter.CountSuppressed(batch) {
+		agentMetrics = append(agentMetrics, metrics.CreateAgentMetric(agentID, metrics.MetricFindingsSuppressed, float64(count)))
+	}
+	pub.metricsAggregator.AddAgentMetrics(&protocol.AgentMetricList{Metrics: agentMetrics})
+}
+
 func (pub *Publisher) prepareLatestBatch() {
+	if pub.adaptiveSchedule != nil {
+		pub.prepareAdaptiveBatch()
+		return
+	}
+
 	batch := (*BatchData)(&protocol.AlertBatch{ChainId: uint64(pub.cfg.ChainID)})
 
 	var (
 		timedOut  bool
+		flushed   bool
 		batchTime time.Time
 		i         int
 	)
 	for i < pub.batchLimit {
 		select {
 		case notif := <-pub.notifCh:
-			alert := notif.SignedAlert
-			hasAlert := alert != nil
-			if hasAlert {
-				log.WithField("alertId", alert.Alert.Id).Debug("publisher received alert")
-			}
-
 			// Notifications with empty alerts shouldn't be taken into account while limiting the batch.
 			// Otherwise, we create too many batches very quickly.
-			if hasAlert {
+			if alert := pub.addNotification(batch, notif); alert != nil {
 				i++
 			}
+			flushed = pub.shouldFlush()
 
-			var blockNum string
-			if notif.EvalBlockRequest != nil {
-				blockNum = notif.EvalBlockRequest.Event.BlockNumber
-			} else if notif.EvalTxRequest != nil {
-				blockNum = notif.EvalTxRequest.Event.Block.BlockNumber
-			} else if notif.EvalAlertRequest != nil {
-				blockNum = hexutil.EncodeUint64(notif.EvalAlertRequest.Event.Alert.Source.Block.Number)
-			}
-
-			notifBlockNum, err := hexutil.DecodeUint64(blockNum)
-			if err != nil {
-				log.Errorf("failed to parse alert notif block number: %v", err)
-				continue
-			}
-			if batch.BlockStart == 0 || (batch.BlockStart > 0 && notifBlockNum < batch.BlockStart) {
-				batch.BlockStart = notifBlockNum
-			}
-			if batch.BlockEnd == 0 || (batch.BlockEnd > 0 && notifBlockNum > batch.BlockEnd) {
-				batch.BlockEnd = notifBlockNum
-			}
-
-			if hasAlert && alert.Alert.Finding.Severity > batch.MaxSeverity {
-				batch.MaxSeverity = alert.Alert.Finding.Severity
-			}
-
-			batch.AppendAlert(notif)
+		case <-pub.flushCh:
+			pub.addReceivedNotifications(batch)
+			flushed = true
 
 		case batchTime, timedOut = <-pub.batchTicker.C:
 		}
 
-		if timedOut {
+		if timedOut || flushed {
 			break
 		}
 	}
 
 	if !timedOut {
 		batchTime = time.Now()
-		pub.batchTicker.Reset(defaultInterval)
+		pub.batchTicker.Reset(pub.batchInterval)
+	}
+	pub.sendPreparedBatch(batch, batchTime)
+}
+
+// addNotification adds the notification to the batch and returns the alert if it was not filtered out.
+func (pub *Publisher) addNotification(batch *BatchData, notif *protocol.NotifyRequest) *protocol.SignedAlert {
+	if pub.dedup != nil && notif.AgentInfo != nil && pub.dedup.IsDuplicate(notif.AgentInfo.Id, notif.SignedAlert) {
+		// keep the notification without the alert so the agent is still known to have processed the input
+		notif.SignedAlert = nil
 	}
+	pub.unsentAlerts.Add(1)
+	alert := notif.SignedAlert
+	hasAlert := alert != nil
+	if hasAlert {
+		log.WithField("alertId", alert.Alert.Id).Debug("publisher received alert")
+	}
+
+	var blockNum string
+	if notif.EvalBlockRequest != nil {
+		blockNum = notif.EvalBlockRequest.Event.BlockNumber
+	} else if notif.EvalTxRequest != nil {
+		blockNum = notif.EvalTxRequest.Event.Block.BlockNumber
+	} else if notif.EvalAlertRequest != nil {
+		blockNum = hexutil.EncodeUint64(notif.EvalAlertRequest.Event.Alert.Source.Block.Number)
+	}
+
+	notifBlockNum, err := hexutil.DecodeUint64(blockNum)
+	if err != nil {
+		log.Errorf("failed to parse alert notif block number: %v", err)
+		return alert
+	}
+	if batch.BlockStart == 0 || (batch.BlockStart > 0 && notifBlockNum < batch.BlockStart) {
+		batch.BlockStart = notifBlockNum
+	}
+	if batch.BlockEnd == 0 || (batch.BlockEnd > 0 && notifBlockNum > batch.BlockEnd) {
+		batch.BlockEnd = notifBlockNum
+	}
+
+	if hasAlert && alert.Alert.Finding.Severity > batch.MaxSeverity {
+		batch.MaxSeverity = alert.Alert.Finding.Severity
+	}
+
+	batch.AppendAlert(notif)
+	return alert
+}
+
+// sendPreparedBatch hands the batch over to the publishing goroutine.
+func (pub *Publisher) sendPreparedBatch(batch *BatchData, batchTime time.Time) {
 	pub.lastBatchReadyMu.Lock()
 	pub.lastBatchReady = batchTime
 	pub.lastBatchReadyMu.Unlock()
 
+	if pub.dedup != nil {
+		pub.dedup.NextBatch()
+	}
+	pub.clearFlush()
+	pub.publishing.Add(1)
+	pub.unsentAlerts.Store(0)
 	pub.batchCh <- (*protocol.AlertBatch)(batch)
 }
 
 func (pub *Publisher) Start() error {
 	go pub.prepareBatches()
 	go pub.publishBatches()
+	if pub.batchQueue != nil {
+		go pub.drainBatchQueue()
+	}
 	pub.registerMessageHandlers()
 	return nil
 }
@@ -792,7 +1112,7 @@ func (pub *Publisher) Name() string {
 
 // Health implements the health.Reporter interface.
 func (pub *Publisher) Health() health.Reports {
-	return health.Reports{
+	reports := health.Reports{
 		pub.lastBatchPublish.GetReport("event.batch-publish.time"),
 		pub.lastBatchPublishAttempt.GetReport("event.batch-publish-attempt.time"),
 		pub.lastBatchPublishErr.GetReport("event.batch-publish.error"),
@@ -804,14 +1124,59 @@ func (pub *Publisher) Health() health.Reports {
 		pub.lastBatchSkipReason.GetReport("event.batch-skip.reason"),
 		pub.lastMetricsFlush.GetReport("event.metrics-flush.time"),
 	}
+	if pub.batchQueue != nil {
+		reports = append(reports, pub.batchQueueReports()...)
+	}
+	if reporter, ok := pub.alertClient.(health.Reporter); ok {
+		reports = append(reports, reporter.Health()...)
+	}
+	if reporter, ok := pub.cfg.Signer.(health.Reporter); ok {
+		reports = append(reports, reporter.Health()...)
+	}
+	reports = append(reports, pub.unsigned.Health()...)
+	reports = append(reports, pub.publishedAlerts.Health()...)
+	reports = append(reports, pub.webhooks.Health()...)
+	reports = append(reports, pub.dedup.Health()...)
+	reports = append(reports, pub.severityFilter.Health()...)
+	reports = append(reports, pub.uploader.Health()...)
+	return reports
+}
+
+func (pub *Publisher) batchQueueReports() health.Reports {
+	droppedStatus := health.StatusOK
+	dropped := pub.batchQueue.Dropped()
+	if dropped > 0 {
+		droppedStatus = health.StatusFailing
+	}
+	var oldestAge string
+	if pub.batchQueue.Len() > 0 {
+		oldestAge = pub.batchQueue.OldestAge().Round(time.Second).String()
+	}
+	return health.Reports{
+		&health.Report{
+			Name:    "batch-queue.depth",
+			Status:  health.StatusInfo,
+			Details: strconv.Itoa(pub.batchQueue.Len()),
+		},
+		&health.Report{
+			Name:    "batch-queue.oldest-age",
+			Status:  health.StatusInfo,
+			Details: oldestAge,
+		},
+		&health.Report{
+			Name:    "batch-queue.dropped",
+			Status:  droppedStatus,
+			Details: strconv.Itoa(dropped),
+		},
+	}
 }
 
 func NewPublisher(ctx context.Context, cfg config.Config) (*Publisher, error) {
-	mc := messaging.NewClient("metrics", fmt.Sprintf("%s:%s", config.DockerNatsContainerName, config.DefaultNatsPort))
+	mc := messaging.NewClient("metrics", cfg.Nats)
 
-	key, err := security.LoadKey(config.DefaultContainerKeyDirPath)
+	batchSigner, err := signer.New(cfg.Signer, config.DefaultContainerKeyDirPath, security.ReadPassphrase)
 	if err != nil {
-		return nil, err
+		return nil, fmt.Errorf("failed to create the signer for the batches: %v", err)
 	}
 
 	releaseInfoStr := os.Getenv(config.EnvReleaseInfo)
@@ -821,7 +1186,14 @@ func NewPublisher(ctx context.Context, cfg config.Config) (*Publisher, error) {
 		releaseSummary = release.MakeSummaryFromReleaseInfo(releaseInfo)
 	}
 
-	apiClient := alertapi.NewClient(cfg.Publish.APIURL)
+	retryCfg := cfg.Publish.Retry
+	apiClient := alertapi.NewClient(cfg.Publish.APIURL, alertapi.RetryConfig{
+		MaxAttempts:    retryCfg.MaxAttempts,
+		InitialBackoff: time.Duration(retryCfg.InitialBackoffSeconds) * time.Second,
+		MaxBackoff:     time.Duration(retryCfg.MaxBackoffSeconds) * time.Second,
+		Jitter:         float64(retryCfg.JitterPercent) / 100,
+	}, cfg.Publish.Batch.Compress)
+	apiClient.SetBatchSigner(batchSigner)
 
 	storageClient, err := storagegrpc.DialContext(ctx, fmt.Sprintf("%s:%s", config.DockerStorageContainerName, config.DefaultStoragePort))
 	if err != nil {
@@ -830,7 +1202,7 @@ func NewPublisher(ctx context.Context, cfg config.Config) (*Publisher, error) {
 
 	return initPublisher(ctx, mc, apiClient, storageClient, PublisherConfig{
 		ChainID:         cfg.ChainID,
-		Key:             key,
+		Signer:          batchSigner,
 		PublisherConfig: cfg.Publish,
 		ReleaseSummary:  releaseSummary,
 		Config:          cfg,
@@ -843,31 +1215,65 @@ func initPublisher(ctx context.Context, mc *messaging.Client, alertClient client
 		return nil, err
 	}
 
-	batchInterval := defaultInterval
-	if cfg.PublisherConfig.Batch.IntervalSeconds != nil {
-		batchInterval = (time.Duration)(*cfg.PublisherConfig.Batch.IntervalSeconds) * time.Second
-	}
+	batchInterval := cfg.PublisherConfig.Batch.Interval()
+	batchLimit := cfg.PublisherConfig.Batch.AlertLimit()
 
-	batchLimit := defaultBatchLimit
-	if cfg.PublisherConfig.Batch.MaxAlerts != nil {
-		batchLimit = *cfg.PublisherConfig.Batch.MaxAlerts
+	var adaptiveSchedule *adaptiveSchedule
+	if batchCfg := cfg.PublisherConfig.Batch; batchCfg.Adaptive {
+		adaptiveSchedule = newAdaptiveSchedule(
+			batchInterval, time.Duration(batchCfg.MaxIntervalSeconds)*time.Second, batchLimit, batchCfg.SkipEmpty,
+		)
 	}
 
+	// the alerts are sent only to the local destinations when offline
+	localAlerts := cfg.Config.LocalModeConfig.Enable || cfg.Config.Offline
 	var localAlertClient LocalAlertClient
 	localAlertDest := cfg.Config.LocalModeConfig.WebhookURL
-	if cfg.Config.LocalModeConfig.Enable && len(localAlertDest) > 0 {
+	if localAlerts && len(localAlertDest) > 0 {
 		localAlertClient, err = webhook.NewAlertWebhookClient(localAlertDest)
 		if err != nil {
 			return nil, fmt.Errorf("failed to create local alert webhook client: %s", localAlertDest)
 		}
 	}
-	if cfg.Config.LocalModeConfig.Enable && len(localAlertDest) == 0 {
+	if localAlerts && len(localAlertDest) == 0 {
 		localAlertClient, err = webhooklog.NewLogger(cfg.Config.LocalModeConfig.LogFileName)
 		if err != nil {
 			return nil, fmt.Errorf("failed to create local alert logger: %s", localAlertDest)
 		}
 	}
 
+	var batchQueue store.BatchQueue
+	queueCfg := cfg.PublisherConfig.Queue
+	if !queueCfg.Disable && !cfg.Config.OfflineSkip("alert-api") {
+		batchQueue, err = store.NewBatchQueue(
+			path.Join(cfg.Config.FortaDir, batchQueueDirName),
+			int64(queueCfg.MaxSizeMB)*1024*1024,
+			time.Duration(queueCfg.MaxAgeHours)*time.Hour,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create the batch queue: %v", err)
+		}
+	}
+
+	var dedup *alertDeduplicator
+	if dedupWindow := cfg.PublisherConfig.Batch.DedupWindowSeconds; dedupWindow > 0 {
+		dedup, err = newAlertDeduplicator(
+			time.Duration(dedupWindow)*time.Second, cfg.PublisherConfig.Batch.DedupMaxEntries,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create the alert deduplicator: %v", err)
+		}
+	}
+
+	var uploader *batchUploader
+	if ipfsCfg := cfg.PublisherConfig.IPFS; (ipfsCfg.Upload || len(ipfsCfg.APIURL) > 0) && !cfg.Config.OfflineSkip("ipfs-upload") {
+		gatewayClient, err := ipfsgateway.NewClient(ipfsCfg)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create the ipfs gateway client: %v", err)
+		}
+		uploader = newBatchUploader(ctx, gatewayClient, ipfsCfg.Verify)
+	}
+
 	return &Publisher{
 		ctx:               ctx,
 		cfg:               cfg,
@@ -879,6 +1285,13 @@ func initPublisher(ctx context.Context, mc *messaging.Client, alertClient client
 		localAlertClient:  localAlertClient,
 		batchRefStore:     store.NewFileStringStore(path.Join(cfg.Config.FortaDir, ".last-batch")),
 		lastReceiptStore:  store.NewFileStringStore(path.Join(cfg.Config.FortaDir, ".last-receipt")),
+		batchQueue:        batchQueue,
+		unsigned:          newUnsignedBatches(defaultBatchBufferSize),
+		publishedAlerts:   newPublishedAlerts(),
+		webhooks:          webhooks.NewSinks(ctx, cfg.PublisherConfig.Webhooks),
+		dedup:             dedup,
+		severityFilter:    newSeverityFilter(cfg.PublisherConfig),
+		uploader:          uploader,
 
 		skipEmpty:     cfg.PublisherConfig.Batch.SkipEmpty,
 		skipPublish:   cfg.PublisherConfig.SkipPublish,
@@ -886,7 +1299,9 @@ func initPublisher(ctx context.Context, mc *messaging.Client, alertClient client
 		batchLimit:    batchLimit,
 		notifCh:       make(chan *protocol.NotifyRequest, defaultBatchLimit),
 		batchCh:       make(chan *protocol.AlertBatch, defaultBatchBufferSize),
+		flushCh:       make(chan struct{}, 1),
 
-		batchTicker: time.NewTicker(defaultInterval),
+		batchTicker:      time.NewTicker(batchInterval),
+		adaptiveSchedule: adaptiveSchedule,
 	}, nil
 }
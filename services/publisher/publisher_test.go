@@ -173,6 +173,37 @@ func TestShouldSkipPublishing(t *testing.T) {
 			batch:             &protocol.AlertBatch{},
 			expectedSkipValue: false,
 		},
+		{
+			name: "alerts below the minimum severity, running bots, too early",
+			publisher: &Publisher{
+				lastBatchSendAttempt: veryRecently,
+				botConfigs:           []config.AgentConfig{{}},
+				severityFilter:       newSeverityFilter(config.PublisherConfig{MinSeverity: "HIGH"}),
+			},
+			batch:               testSeverityBatch(protocol.Finding_LOW),
+			expectedSkipValue:   true,
+			expectedMsgContains: "no alerts at or above the minimum severity",
+		},
+		{
+			name: "alerts below the minimum severity, running bots, not early",
+			publisher: &Publisher{
+				lastBatchSendAttempt: time.Now().Add(-fastReportInterval),
+				botConfigs:           []config.AgentConfig{{}},
+				severityFilter:       newSeverityFilter(config.PublisherConfig{MinSeverity: "HIGH"}),
+			},
+			batch:             testSeverityBatch(protocol.Finding_LOW),
+			expectedSkipValue: false,
+		},
+		{
+			name: "alert at the minimum severity",
+			publisher: &Publisher{
+				lastBatchSendAttempt: veryRecently,
+				botConfigs:           []config.AgentConfig{{}},
+				severityFilter:       newSeverityFilter(config.PublisherConfig{MinSeverity: "HIGH"}),
+			},
+			batch:             testSeverityBatch(protocol.Finding_LOW, protocol.Finding_HIGH),
+			expectedSkipValue: false,
+		},
 	}
 
 	for _, testCase := range testCases {
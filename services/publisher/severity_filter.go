@@ -0,0 +1,147 @@
+package publisher
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/forta-network/forta-core-go/clients/health"
+	"github.com/forta-network/forta-core-go/protocol"
+	"github.com/forta-network/forta-node/config"
+	log "github.com/sirupsen/logrus"
+)
+
+// severityFilter suppresses the batches which have no findings at or above the minimum severity so
+// that they are published only when the empty batches would be.
+type severityFilter struct {
+	minSeverity      protocol.Finding_Severity
+	agentMinSeverity map[string]protocol.Finding_Severity
+
+	suppressedBatches int
+	suppressedCounts  map[string]int
+	mu                sync.Mutex
+}
+
+func parseSeverity(severity string) protocol.Finding_Severity {
+	return protocol.Finding_Severity(protocol.Finding_Severity_value[strings.ToUpper(severity)])
+}
+
+// newSeverityFilter creates a new filter if a minimum severity or an agent override is configured.
+func newSeverityFilter(cfg config.PublisherConfig) *severityFilter {
+	if len(cfg.MinSeverity) == 0 && len(cfg.Agents) == 0 {
+		return nil
+	}
+	filter := &severityFilter{
+		minSeverity:      parseSeverity(cfg.MinSeverity),
+		agentMinSeverity: make(map[string]protocol.Finding_Severity),
+		suppressedCounts: make(map[string]int),
+	}
+	for _, agentCfg := range cfg.Agents {
+		agentID := strings.ToLower(agentCfg.AgentID)
+		switch {
+		case agentCfg.Suppress:
+			filter.agentMinSeverity[agentID] = math.MaxInt32
+		case len(agentCfg.MinSeverity) > 0:
+			filter.agentMinSeverity[agentID] = parseSeverity(agentCfg.MinSeverity)
+		}
+	}
+	return filter
+}
+
+// meetsMinSeverity checks if the alert is at or above the minimum severity for the agent.
+func (filter *severityFilter) meetsMinSeverity(alert *protocol.SignedAlert) bool {
+	if alert == nil || alert.Alert == nil || alert.Alert.Finding == nil {
+		return false
+	}
+	minSeverity, ok := filter.agentMinSeverity[strings.ToLower(alert.Alert.Agent.GetId())]
+	if !ok {
+		minSeverity = filter.minSeverity
+	}
+	return alert.Alert.Finding.Severity >= minSeverity
+}
+
+// ShouldSuppress checks if the batch has findings but none of them is at or above the minimum
+// severity for its agent.
+func (filter *severityFilter) ShouldSuppress(batch *protocol.AlertBatch) bool {
+	if filter == nil || batch.AlertCount == 0 {
+		return false
+	}
+	suppress := true
+	forEachBatchAlert(batch, func(alert *protocol.SignedAlert) {
+		if filter.meetsMinSeverity(alert) {
+			suppress = false
+		}
+	})
+	return suppress
+}
+
+// CountSuppressed counts the suppressed batch and returns the number of the findings per agent
+// in the batch.
+func (filter *severityFilter) CountSuppressed(batch *protocol.AlertBatch) map[string]int {
+	findings := make(map[string]int)
+	forEachBatchAlert(batch, func(alert *protocol.SignedAlert) {
+		if alert != nil && alert.Alert != nil {
+			findings[alert.Alert.Agent.GetId()]++
+		}
+	})
+
+	filter.mu.Lock()
+	filter.suppressedBatches++
+	for agentID, count := range findings {
+		filter.suppressedCounts[agentID] += count
+	}
+	filter.mu.Unlock()
+
+	log.WithFields(log.Fields{
+		"blockStart":  batch.BlockStart,
+		"blockEnd":    batch.BlockEnd,
+		"alertCount":  batch.AlertCount,
+		"maxSeverity": batch.MaxSeverity.String(),
+	}).Debug("suppressed batch without findings at or above the minimum severity")
+	return findings
+}
+
+// forEachBatchAlert calls the func with all of the alerts in the batch.
+func forEachBatchAlert(batch *protocol.AlertBatch, fn func(alert *protocol.SignedAlert)) {
+	forEach := func(agentAlerts []*protocol.AgentAlerts) {
+		for _, aa := range agentAlerts {
+			for _, alert := range aa.Alerts {
+				fn(alert)
+			}
+		}
+	}
+	for _, blockRes := range batch.Results {
+		forEach(blockRes.Results)
+		for _, txRes := range blockRes.Transactions {
+			forEach(txRes.Results)
+		}
+	}
+	for _, combinationRes := range batch.CombinationAlerts {
+		forEach(combinationRes.Results)
+	}
+	forEach(batch.PrivateAlerts)
+}
+
+// Health returns the number of the suppressed batches and the suppressed finding counts per agent.
+func (filter *severityFilter) Health() (reports health.Reports) {
+	if filter == nil {
+		return
+	}
+	filter.mu.Lock()
+	defer filter.mu.Unlock()
+	reports = append(reports, &health.Report{
+		Name:    "suppressed.batches",
+		Status:  health.StatusInfo,
+		Details: strconv.Itoa(filter.suppressedBatches),
+	})
+	for agentID, count := range filter.suppressedCounts {
+		reports = append(reports, &health.Report{
+			Name:    fmt.Sprintf("suppressed.%s", agentID),
+			Status:  health.StatusInfo,
+			Details: strconv.Itoa(count),
+		})
+	}
+	return
+}
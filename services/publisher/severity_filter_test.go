@@ -0,0 +1,82 @@
+package publisher
+
+import (
+	"testing"
+
+	"github.com/forta-network/forta-core-go/protocol"
+	"github.com/forta-network/forta-node/config"
+	"github.com/stretchr/testify/require"
+)
+
+func testSeverityAlert(agentID string, severity protocol.Finding_Severity) *protocol.SignedAlert {
+	return &protocol.SignedAlert{
+		Alert: &protocol.Alert{
+			Agent:   &protocol.AgentInfo{Id: agentID},
+			Finding: &protocol.Finding{Severity: severity},
+		},
+	}
+}
+
+func testAgentSeverityBatch(agentID string, severities ...protocol.Finding_Severity) *protocol.AlertBatch {
+	agentAlerts := &protocol.AgentAlerts{}
+	for _, severity := range severities {
+		agentAlerts.Alerts = append(agentAlerts.Alerts, testSeverityAlert(agentID, severity))
+	}
+	return &protocol.AlertBatch{
+		AlertCount: uint32(len(severities)),
+		Results: []*protocol.BlockResults{
+			{Transactions: []*protocol.TransactionResults{{Results: []*protocol.AgentAlerts{agentAlerts}}}},
+		},
+	}
+}
+
+func testSeverityBatch(severities ...protocol.Finding_Severity) *protocol.AlertBatch {
+	return testAgentSeverityBatch("0xbot", severities...)
+}
+
+func TestSeverityFilter_NotConfigured(t *testing.T) {
+	filter := newSeverityFilter(config.PublisherConfig{})
+	require.Nil(t, filter)
+	require.False(t, filter.ShouldSuppress(testSeverityBatch(protocol.Finding_INFO)))
+}
+
+func TestSeverityFilter(t *testing.T) {
+	r := require.New(t)
+
+	filter := newSeverityFilter(config.PublisherConfig{
+		MinSeverity: "HIGH",
+		Agents: []config.AgentPublishConfig{
+			{AgentID: "0xNoisy", Suppress: true},
+			{AgentID: "0xinfo", MinSeverity: "INFO"},
+		},
+	})
+	r.NotNil(filter)
+
+	// the batch is suppressed only if none of the findings is at or above the minimum severity
+	r.True(filter.ShouldSuppress(testSeverityBatch(protocol.Finding_MEDIUM, protocol.Finding_LOW)))
+	r.False(filter.ShouldSuppress(testSeverityBatch(protocol.Finding_MEDIUM, protocol.Finding_HIGH)))
+	r.False(filter.ShouldSuppress(testSeverityBatch(protocol.Finding_CRITICAL)))
+	r.False(filter.ShouldSuppress(&protocol.AlertBatch{}))
+
+	// overrides
+	r.True(filter.ShouldSuppress(testAgentSeverityBatch("0xnoisy", protocol.Finding_CRITICAL)))
+	r.False(filter.ShouldSuppress(testAgentSeverityBatch("0xinfo", protocol.Finding_INFO)))
+
+	// the findings in a private or a combination result count too
+	batch := testSeverityBatch(protocol.Finding_LOW)
+	batch.PrivateAlerts = []*protocol.AgentAlerts{{Alerts: []*protocol.SignedAlert{testSeverityAlert("0xbot", protocol.Finding_HIGH)}}}
+	r.False(filter.ShouldSuppress(batch))
+
+	r.Equal(map[string]int{"0xbot": 2}, filter.CountSuppressed(testSeverityBatch(protocol.Finding_MEDIUM, protocol.Finding_LOW)))
+	filter.CountSuppressed(testAgentSeverityBatch("0xnoisy", protocol.Finding_CRITICAL))
+
+	reports := filter.Health()
+	r.Len(reports, 3)
+	counts := make(map[string]string)
+	for _, report := range reports {
+		counts[report.Name] = report.Details
+	}
+	r.Equal("2", counts["suppressed.batches"])
+	r.Equal("2", counts["suppressed.0xbot"])
+	r.Equal("1", counts["suppressed.0xnoisy"])
+}
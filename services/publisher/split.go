@@ -0,0 +1,114 @@
+package publisher
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/forta-network/forta-core-go/protocol"
+)
+
+// partRef makes a ref for a part of a batch which is correlated with the original batch ref.
+func partRef(ref string, index int) string {
+	return fmt.Sprintf("%s-%d", ref, index+1)
+}
+
+// batchItemCount returns the number of the items which can be distributed to batch parts.
+func batchItemCount(batch *protocol.AlertBatch) int {
+	return len(batch.Results) + len(batch.PrivateAlerts) + len(batch.CombinationAlerts)
+}
+
+func batchSize(batch *protocol.AlertBatch) int {
+	b, _ := json.Marshal(batch)
+	return len(b)
+}
+
+// splitBatchBySize splits the batch until each part is smaller than the max size
+// or can not be split any further.
+func splitBatchBySize(batch *protocol.AlertBatch, maxBytes int) []*protocol.AlertBatch {
+	size := batchSize(batch)
+	if size <= maxBytes || batchItemCount(batch) <= 1 {
+		return []*protocol.AlertBatch{batch}
+	}
+	n := (size + maxBytes - 1) / maxBytes
+	var parts []*protocol.AlertBatch
+	for _, part := range splitBatch(batch, n) {
+		parts = append(parts, splitBatchBySize(part, maxBytes)...)
+	}
+	return parts
+}
+
+// splitBatch splits the batch into n parts by distributing the results in order. The agents, metrics and
+// the inspection results are included only in the first part.
+func splitBatch(batch *protocol.AlertBatch, n int) []*protocol.AlertBatch {
+	if itemCount := batchItemCount(batch); n > itemCount {
+		n = itemCount
+	}
+	if n <= 1 {
+		return []*protocol.AlertBatch{batch}
+	}
+
+	results := chunk(batch.Results, n)
+	privateAlerts := chunk(batch.PrivateAlerts, n)
+	combinationAlerts := chunk(batch.CombinationAlerts, n)
+
+	var parts []*protocol.AlertBatch
+	for i := 0; i < n; i++ {
+		part := &protocol.AlertBatch{
+			ChainId:           batch.ChainId,
+			BlockStart:        batch.BlockStart,
+			BlockEnd:          batch.BlockEnd,
+			Results:           results[i],
+			ScannerVersion:    batch.ScannerVersion,
+			Parent:            batch.Parent,
+			PrivateAlerts:     privateAlerts[i],
+			LatestBlockInput:  batch.LatestBlockInput,
+			CombinationAlerts: combinationAlerts[i],
+			Provider:          batch.Provider,
+		}
+		if i == 0 {
+			part.Agents = batch.Agents
+			part.Metrics = batch.Metrics
+			part.InspectionResults = batch.InspectionResults
+		}
+		part.AlertCount, part.MaxSeverity = countAlerts(part)
+		parts = append(parts, part)
+	}
+	return parts
+}
+
+// chunk splits the items into n contiguous chunks of similar size.
+func chunk[T any](items []T, n int) [][]T {
+	chunks := make([][]T, n)
+	for i := 0; i < n; i++ {
+		start := i * len(items) / n
+		end := (i + 1) * len(items) / n
+		if start < end {
+			chunks[i] = items[start:end]
+		}
+	}
+	return chunks
+}
+
+func countAlerts(batch *protocol.AlertBatch) (count uint32, maxSeverity protocol.Finding_Severity) {
+	add := func(agentAlertsList []*protocol.AgentAlerts) {
+		for _, agentAlerts := range agentAlertsList {
+			for _, alert := range agentAlerts.Alerts {
+				count++
+				if alert.Alert != nil && alert.Alert.Finding != nil && alert.Alert.Finding.Severity > maxSeverity {
+					maxSeverity = alert.Alert.Finding.Severity
+				}
+			}
+		}
+	}
+	for _, blockRes := range batch.Results {
+		add(blockRes.Results)
+		for _, txRes := range blockRes.Transactions {
+			add(txRes.Results)
+		}
+	}
+	add(batch.PrivateAlerts)
+	for _, combinationRes := range batch.CombinationAlerts {
+		add(combinationRes.Results)
+	}
+	return
+}
@@ -0,0 +1,142 @@
+package publisher
+
+import (
+	"net/http"
+	"path"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/accounts/keystore"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/forta-network/forta-core-go/domain"
+	"github.com/forta-network/forta-core-go/protocol"
+	"github.com/forta-network/forta-node/clients/alertapi"
+	mock_clients "github.com/forta-network/forta-node/clients/mocks"
+	"github.com/forta-network/forta-node/clients/signer"
+	"github.com/forta-network/forta-node/store"
+	"github.com/golang/mock/gomock"
+	log "github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/require"
+)
+
+func testSplitBatch(blockCount int) *protocol.AlertBatch {
+	batch := &protocol.AlertBatch{
+		ChainId:    1,
+		BlockStart: 1,
+		BlockEnd:   uint64(blockCount),
+		Metrics:    []*protocol.AgentMetrics{{AgentId: "bot1"}},
+	}
+	for i := 1; i <= blockCount; i++ {
+		batch.Results = append(batch.Results, &protocol.BlockResults{
+			Block: &protocol.Block{BlockNumber: uint64(i)},
+			Results: []*protocol.AgentAlerts{
+				{
+					AgentManifest: "bot1",
+					Alerts: []*protocol.SignedAlert{
+						{Alert: &protocol.Alert{Finding: &protocol.Finding{Severity: protocol.Finding_Severity(i % 5)}}},
+					},
+				},
+			},
+		})
+		batch.AlertCount++
+	}
+	return batch
+}
+
+func TestSplitBatch(t *testing.T) {
+	r := require.New(t)
+
+	parts := splitBatch(testSplitBatch(5), 2)
+	r.Len(parts, 2)
+
+	// preserves the order
+	r.Len(parts[0].Results, 2)
+	r.Len(parts[1].Results, 3)
+	var blockNums []uint64
+	for _, part := range parts {
+		for _, blockRes := range part.Results {
+			blockNums = append(blockNums, blockRes.Block.BlockNumber)
+		}
+	}
+	r.Equal([]uint64{1, 2, 3, 4, 5}, blockNums)
+
+	r.Equal(uint32(2), parts[0].AlertCount)
+	r.Equal(protocol.Finding_Severity(2), parts[0].MaxSeverity)
+	r.Equal(uint32(3), parts[1].AlertCount)
+	r.Equal(protocol.Finding_Severity(4), parts[1].MaxSeverity)
+
+	// metrics only in the first part
+	r.Len(parts[0].Metrics, 1)
+	r.Empty(parts[1].Metrics)
+
+	// can not split more than the items
+	r.Len(splitBatch(testSplitBatch(2), 3), 2)
+	r.Len(splitBatch(testSplitBatch(1), 2), 1)
+}
+
+func TestSplitBatchBySize(t *testing.T) {
+	r := require.New(t)
+
+	batch := testSplitBatch(10)
+	size := batchSize(batch)
+
+	r.Len(splitBatchBySize(batch, size), 1)
+
+	maxBytes := size / 3
+	parts := splitBatchBySize(batch, maxBytes)
+	r.Greater(len(parts), 2)
+	var alertCount uint32
+	for _, part := range parts {
+		r.LessOrEqual(batchSize(part), maxBytes)
+		alertCount += part.AlertCount
+	}
+	r.Equal(batch.AlertCount, alertCount)
+
+	r.Equal("ref-1", partRef("ref", 0))
+	r.Equal("ref-2-1", partRef(partRef("ref", 1), 0))
+}
+
+func TestSendParts_QueueAfterFailure(t *testing.T) {
+	r := require.New(t)
+
+	privateKey, err := crypto.GenerateKey()
+	r.NoError(err)
+	key := &keystore.Key{Address: crypto.PubkeyToAddress(privateKey.PublicKey), PrivateKey: privateKey}
+	dir := t.TempDir()
+	batchQueue, err := store.NewBatchQueue(path.Join(dir, batchQueueDirName), 0, 0)
+	r.NoError(err)
+
+	alertClient := mock_clients.NewMockAlertAPIClient(gomock.NewController(t))
+	pub := &Publisher{
+		cfg:              PublisherConfig{Signer: signer.NewLocalSigner(key)},
+		alertClient:      alertClient,
+		lastReceiptStore: store.NewFileStringStore(path.Join(dir, ".last-receipt")),
+		batchQueue:       batchQueue,
+		publishedAlerts:  newPublishedAlerts(),
+	}
+
+	// the second part fails and the third part is queued behind it instead of being lost
+	alertClient.EXPECT().PostBatch(gomock.Any(), gomock.Any()).
+		DoAndReturn(func(request *domain.AlertBatchRequest, token string) (*domain.AlertBatchResponse, error) {
+			r.Equal("ref1-1", request.Ref)
+			return &domain.AlertBatchResponse{}, nil
+		})
+	alertClient.EXPECT().PostBatch(gomock.Any(), gomock.Any()).
+		DoAndReturn(func(request *domain.AlertBatchRequest, token string) (*domain.AlertBatchResponse, error) {
+			r.Equal("ref1-2", request.Ref)
+			return nil, &alertapi.APIError{StatusCode: http.StatusServiceUnavailable, Retryable: true}
+		})
+
+	parts := splitBatch(testSplitBatch(3), 3)
+	r.Len(parts, 3)
+	sent, err := pub.sendParts(log.NewEntry(log.StandardLogger()), parts, "ref1")
+	r.Error(err)
+	r.False(sent)
+	r.Equal(2, batchQueue.Len())
+	queued, err := batchQueue.Peek()
+	r.NoError(err)
+	r.Equal("ref1-2", queued.Request.Ref)
+	r.NoError(batchQueue.Pop())
+	queued, err = batchQueue.Peek()
+	r.NoError(err)
+	r.Equal("ref1-3", queued.Request.Ref)
+}
@@ -0,0 +1,96 @@
+package publisher
+
+import (
+	"strconv"
+	"sync"
+
+	"github.com/forta-network/forta-core-go/clients/health"
+	"github.com/forta-network/forta-core-go/protocol"
+)
+
+// unsignedBatch is a batch which is waiting for the signer.
+type unsignedBatch struct {
+	batch *protocol.AlertBatch
+	// the parent is set in the first attempt and kept so that the retried batch has the same ref
+	attempted bool
+}
+
+// unsignedBatches keeps the batches in order while the signer is unavailable. The oldest batch
+// is dropped when the queue is full.
+type unsignedBatches struct {
+	batches []*unsignedBatch
+	max     int
+	dropped int
+	mu      sync.Mutex
+}
+
+func newUnsignedBatches(max int) *unsignedBatches {
+	return &unsignedBatches{max: max}
+}
+
+// Push adds the batch to the end and tells if the oldest batch was dropped.
+func (ub *unsignedBatches) Push(batch *unsignedBatch) (dropped bool) {
+	ub.mu.Lock()
+	defer ub.mu.Unlock()
+	if len(ub.batches) >= ub.max {
+		ub.batches = ub.batches[1:]
+		ub.dropped++
+		dropped = true
+	}
+	ub.batches = append(ub.batches, batch)
+	return
+}
+
+// Peek returns the oldest batch or nil if there are no batches.
+func (ub *unsignedBatches) Peek() *unsignedBatch {
+	ub.mu.Lock()
+	defer ub.mu.Unlock()
+	if len(ub.batches) == 0 {
+		return nil
+	}
+	return ub.batches[0]
+}
+
+// Pop removes the oldest batch.
+func (ub *unsignedBatches) Pop() {
+	ub.mu.Lock()
+	defer ub.mu.Unlock()
+	if len(ub.batches) > 0 {
+		ub.batches = ub.batches[1:]
+	}
+}
+
+// Len returns the number of the waiting batches.
+func (ub *unsignedBatches) Len() int {
+	if ub == nil {
+		return 0
+	}
+	ub.mu.Lock()
+	defer ub.mu.Unlock()
+	return len(ub.batches)
+}
+
+// Health implements the health.Reporter interface.
+func (ub *unsignedBatches) Health() (reports health.Reports) {
+	if ub == nil {
+		return
+	}
+	ub.mu.Lock()
+	defer ub.mu.Unlock()
+	droppedStatus := health.StatusOK
+	if ub.dropped > 0 {
+		droppedStatus = health.StatusFailing
+	}
+	return health.Reports{
+		&health.Report{
+			Name:    "unsigned-batches.depth",
+			Status:  health.StatusInfo,
+			Details: strconv.Itoa(len(ub.batches)),
+		},
+		&health.Report{
+			Name:    "unsigned-batches.dropped",
+			Status:  droppedStatus,
+			Details: strconv.Itoa(ub.dropped),
+		},
+	}
+}
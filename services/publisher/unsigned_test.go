@@ -0,0 +1,84 @@
+package publisher
+
+import (
+	"errors"
+	"path"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/accounts/keystore"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/forta-network/forta-core-go/clients/health"
+	"github.com/forta-network/forta-core-go/protocol"
+	"github.com/forta-network/forta-node/clients/signer"
+	"github.com/forta-network/forta-node/store"
+	"github.com/stretchr/testify/require"
+)
+
+// testSigner is unavailable until it is enabled.
+type testSigner struct {
+	signer.Signer
+	available bool
+}
+
+func (s *testSigner) Sign(data []byte) ([]byte, error) {
+	if !s.available {
+		return nil, &signer.UnavailableError{Err: errors.New("connection refused")}
+	}
+	return s.Signer.Sign(data)
+}
+
+func testBatch(blockNumber uint64) *protocol.AlertBatch {
+	batch := &protocol.AlertBatch{BlockStart: blockNumber, BlockEnd: blockNumber}
+	notif := testBlockNotif(blockNumber, protocol.Finding_HIGH)
+	notif.SignedAlert.Alert.Agent = &protocol.AgentInfo{Id: "0x1"}
+	(*BatchData)(batch).AppendAlert(notif)
+	return batch
+}
+
+func TestPublishBatch_SignerUnavailable(t *testing.T) {
+	r := require.New(t)
+
+	privateKey, err := crypto.GenerateKey()
+	r.NoError(err)
+	key := &keystore.Key{Address: crypto.PubkeyToAddress(privateKey.PublicKey), PrivateKey: privateKey}
+	batchSigner := &testSigner{Signer: signer.NewLocalSigner(key)}
+
+	localAlertClient := &testLocalAlertClient{}
+	pub := &Publisher{
+		cfg:               PublisherConfig{Signer: batchSigner},
+		metricsAggregator: NewMetricsAggregator(time.Minute),
+		localAlertClient:  localAlertClient,
+		batchRefStore:     store.NewFileStringStore(path.Join(t.TempDir(), ".last-batch")),
+		unsigned:          newUnsignedBatches(2),
+	}
+	pub.cfg.Config.Offline = true
+
+	// the batches wait for the signer in order and the oldest one is dropped when full
+	for blockNumber := uint64(1); blockNumber <= 3; blockNumber++ {
+		pub.publishing.Add(1)
+		pub.publishBatch(testBatch(blockNumber))
+	}
+	r.Len(localAlertClient.sent, 0)
+	r.Equal(2, pub.unsigned.Len())
+	_, pending := pub.Pending()
+	r.Equal(2, pending)
+	r.Equal(health.StatusFailing, pub.lastBatchPublishErr.GetReport("error").Status)
+	reports := pub.unsigned.Health()
+	dropped, _ := reports.NameContains("unsigned-batches.dropped")
+	r.Equal("1", dropped.Details)
+	r.Equal(health.StatusFailing, dropped.Status)
+
+	pub.retryUnsignedBatches()
+	r.Equal(2, pub.unsigned.Len())
+
+	batchSigner.available = true
+	pub.retryUnsignedBatches()
+	r.Equal(0, pub.unsigned.Len())
+	_, pending = pub.Pending()
+	r.Equal(0, pending)
+	r.Equal(health.StatusOK, pub.lastBatchPublishErr.GetReport("error").Status)
+	r.Len(localAlertClient.sent, 2)
+	r.Equal(uint64(2), localAlertClient.sent[0].Payload.Alerts[0].Source.Block.Number)
+	r.Equal(uint64(3), localAlertClient.sent[1].Payload.Alerts[0].Source.Block.Number)
+}
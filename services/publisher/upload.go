@@ -0,0 +1,98 @@
+package publisher
+
+import (
+	"context"
+
+	"github.com/forta-network/forta-core-go/clients/health"
+	"github.com/forta-network/forta-node/clients/ipfsgateway"
+	log "github.com/sirupsen/logrus"
+)
+
+const defaultUploadBufferSize = 10
+
+type batchUpload struct {
+	cid     string
+	content []byte
+}
+
+// batchUploader uploads the batches to IPFS in the background so that the gateway
+// failures do not block publishing.
+type batchUploader struct {
+	client   *ipfsgateway.Client
+	verify   bool
+	uploadCh chan *batchUpload
+
+	lastErr   health.ErrorTracker
+	verifyErr health.ErrorTracker
+}
+
+func newBatchUploader(ctx context.Context, client *ipfsgateway.Client, verify bool) *batchUploader {
+	uploader := &batchUploader{
+		client:   client,
+		verify:   verify,
+		uploadCh: make(chan *batchUpload, defaultUploadBufferSize),
+	}
+	go uploader.loop(ctx)
+	return uploader
+}
+
+// Upload queues the batch content for uploading. It never blocks.
+func (uploader *batchUploader) Upload(cid string, content []byte) {
+	if uploader == nil {
+		return
+	}
+	select {
+	case uploader.uploadCh <- &batchUpload{cid: cid, content: content}:
+	default:
+		log.WithField("ref", cid).Warn("ipfs upload buffer is full - skipping batch upload")
+	}
+}
+
+func (uploader *batchUploader) loop(ctx context.Context) {
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case upload := <-uploader.uploadCh:
+			uploader.upload(ctx, upload)
+		}
+	}
+}
+
+func (uploader *batchUploader) upload(ctx context.Context, upload *batchUpload) {
+	logger := log.WithField("ref", upload.cid)
+	cid, gatewayURL, err := uploader.client.Add(ctx, upload.content)
+	uploader.lastErr.Set(err)
+	if err != nil {
+		logger.WithError(err).Error("failed to upload batch to ipfs")
+		return
+	}
+	logger = logger.WithField("gateway", gatewayURL)
+	if cid != upload.cid {
+		logger.WithField("addedCid", cid).Warn("ipfs gateway returned an unexpected cid")
+	}
+	logger.Info("uploaded batch to ipfs")
+
+	if !uploader.verify {
+		return
+	}
+	err = uploader.client.Verify(ctx, cid, upload.content, gatewayURL)
+	uploader.verifyErr.Set(err)
+	if err != nil {
+		logger.WithError(err).Error("failed to verify the uploaded batch")
+		return
+	}
+	logger.Info("verified the uploaded batch")
+}
+
+// Health implements the health.Reporter interface.
+func (uploader *batchUploader) Health() (reports health.Reports) {
+	if uploader == nil {
+		return
+	}
+	reports = append(reports, uploader.lastErr.GetReport("ipfs.upload.last-error"))
+	if uploader.verify {
+		reports = append(reports, uploader.verifyErr.GetReport("ipfs.verify.last-error"))
+	}
+	return append(reports, uploader.client.Health()...)
+}
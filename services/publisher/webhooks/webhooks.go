@@ -0,0 +1,255 @@
+package webhooks
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/forta-network/forta-core-go/clients/health"
+	"github.com/forta-network/forta-core-go/protocol"
+	"github.com/forta-network/forta-node/config"
+	"github.com/goccy/go-json"
+	log "github.com/sirupsen/logrus"
+)
+
+// SignatureHeader is the header which contains the HMAC signature of the request body.
+const SignatureHeader = "X-Forta-Signature"
+
+const (
+	defaultBufferSize   = 100
+	defaultMaxAttempts  = 3
+	defaultRetryBackoff = time.Second * 2
+	defaultTimeout      = time.Second * 10
+)
+
+// Alert is a webhook payload for a single alert.
+type Alert struct {
+	BotID string          `json:"botId"`
+	Alert *protocol.Alert `json:"alert"`
+}
+
+// Batch is a webhook payload for all matching alerts from a batch.
+type Batch struct {
+	BlockStart uint64   `json:"blockStart"`
+	BlockEnd   uint64   `json:"blockEnd"`
+	Alerts     []*Alert `json:"alerts"`
+}
+
+// Sinks sends alerts to all configured webhooks.
+type Sinks struct {
+	sinks []*sink
+}
+
+type sink struct {
+	index       int
+	cfg         config.WebhookConfig
+	minSeverity protocol.Finding_Severity
+	botIDs      map[string]bool
+	httpClient  *http.Client
+	reqCh       chan []byte
+
+	failures     atomic.Int64
+	failureCount health.NumberTracker
+	lastErr      health.ErrorTracker
+}
+
+// NewSinks creates the webhook sinks and starts sending in the background.
+func NewSinks(ctx context.Context, webhooks []config.WebhookConfig) *Sinks {
+	var sinks Sinks
+	for i, webhookCfg := range webhooks {
+		s := newSink(i, webhookCfg)
+		go s.loop(ctx)
+		sinks.sinks = append(sinks.sinks, s)
+	}
+	return &sinks
+}
+
+func newSink(index int, cfg config.WebhookConfig) *sink {
+	s := &sink{
+		index:       index,
+		cfg:         cfg,
+		minSeverity: protocol.Finding_Severity(protocol.Finding_Severity_value[strings.ToUpper(cfg.MinSeverity)]),
+		botIDs:      make(map[string]bool),
+		httpClient:  &http.Client{Timeout: defaultTimeout},
+		reqCh:       make(chan []byte, defaultBufferSize),
+	}
+	for _, botID := range cfg.BotIDs {
+		s.botIDs[strings.ToLower(botID)] = true
+	}
+	return s
+}
+
+// Send sends the matching alerts from the batch to the webhooks. It never blocks:
+// if a webhook is too slow to keep up, the alerts are dropped for that webhook.
+func (sinks *Sinks) Send(batch *protocol.AlertBatch) {
+	if sinks == nil || len(sinks.sinks) == 0 {
+		return
+	}
+	alerts := collectAlerts(batch)
+	if len(alerts) == 0 {
+		return
+	}
+	for _, s := range sinks.sinks {
+		s.send(batch, alerts)
+	}
+}
+
+// Health implements the health.Reporter interface.
+func (sinks *Sinks) Health() (reports health.Reports) {
+	if sinks == nil {
+		return
+	}
+	for _, s := range sinks.sinks {
+		reports = append(reports,
+			s.failureCount.GetReport(fmt.Sprintf("webhook.%d.failures", s.index)),
+			s.lastErr.GetReport(fmt.Sprintf("webhook.%d.last-error", s.index)),
+		)
+	}
+	return
+}
+
+func (s *sink) send(batch *protocol.AlertBatch, alerts []*Alert) {
+	var matching []*Alert
+	for _, alert := range alerts {
+		if s.matches(alert) {
+			matching = append(matching, alert)
+		}
+	}
+	if len(matching) == 0 {
+		return
+	}
+
+	var payloads []interface{}
+	if s.cfg.SendBatch {
+		payloads = append(payloads, &Batch{
+			BlockStart: batch.BlockStart,
+			BlockEnd:   batch.BlockEnd,
+			Alerts:     matching,
+		})
+	} else {
+		for _, alert := range matching {
+			payloads = append(payloads, alert)
+		}
+	}
+
+	for _, payload := range payloads {
+		b, err := json.Marshal(payload)
+		if err != nil {
+			log.WithError(err).Error("failed to encode webhook payload")
+			continue
+		}
+		select {
+		case s.reqCh <- b:
+		default:
+			log.WithField("webhook", s.index).Warn("webhook is too slow - dropping alerts")
+			s.recordFailure(fmt.Errorf("buffer is full"))
+		}
+	}
+}
+
+func (s *sink) matches(alert *Alert) bool {
+	if len(s.botIDs) > 0 && !s.botIDs[strings.ToLower(alert.BotID)] {
+		return false
+	}
+	if alert.Alert.Finding == nil {
+		return s.minSeverity == protocol.Finding_UNKNOWN
+	}
+	return alert.Alert.Finding.Severity >= s.minSeverity
+}
+
+func (s *sink) loop(ctx context.Context) {
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case body := <-s.reqCh:
+			s.post(ctx, body)
+		}
+	}
+}
+
+func (s *sink) post(ctx context.Context, body []byte) {
+	var err error
+	for attempt := 1; attempt <= defaultMaxAttempts; attempt++ {
+		if err = s.doPost(ctx, body); err == nil {
+			s.lastErr.Set(nil)
+			return
+		}
+		if attempt < defaultMaxAttempts {
+			select {
+			case <-ctx.Done():
+				return
+			case <-time.After(defaultRetryBackoff * time.Duration(attempt)):
+			}
+		}
+	}
+	log.WithError(err).WithField("webhook", s.index).Warn("failed to send alerts to webhook")
+	s.recordFailure(err)
+}
+
+func (s *sink) doPost(ctx context.Context, body []byte) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.cfg.URL, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if len(s.cfg.Secret) > 0 {
+		req.Header.Set(SignatureHeader, ComputeSignature([]byte(s.cfg.Secret), body))
+	}
+	resp, err := s.httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("unexpected status code %d", resp.StatusCode)
+	}
+	return nil
+}
+
+func (s *sink) recordFailure(err error) {
+	s.failureCount.Set(float64(s.failures.Add(1)))
+	s.lastErr.Set(err)
+}
+
+// ComputeSignature computes the HMAC-SHA256 signature of the body.
+func ComputeSignature(secret, body []byte) string {
+	mac := hmac.New(sha256.New, secret)
+	mac.Write(body)
+	return fmt.Sprintf("sha256=%s", hex.EncodeToString(mac.Sum(nil)))
+}
+
+func collectAlerts(batch *protocol.AlertBatch) (alerts []*Alert) {
+	add := func(agentAlertsList []*protocol.AgentAlerts) {
+		for _, agentAlerts := range agentAlertsList {
+			for _, signedAlert := range agentAlerts.Alerts {
+				if signedAlert == nil || signedAlert.Alert == nil {
+					continue
+				}
+				var botID string
+				if signedAlert.Alert.Agent != nil {
+					botID = signedAlert.Alert.Agent.Id
+				}
+				alerts = append(alerts, &Alert{BotID: botID, Alert: signedAlert.Alert})
+			}
+		}
+	}
+	for _, blockRes := range batch.Results {
+		add(blockRes.Results)
+		for _, txRes := range blockRes.Transactions {
+			add(txRes.Results)
+		}
+	}
+	for _, combinationRes := range batch.CombinationAlerts {
+		add(combinationRes.Results)
+	}
+	add(batch.PrivateAlerts)
+	return
+}
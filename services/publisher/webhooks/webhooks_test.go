@@ -0,0 +1,102 @@
+package webhooks
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/forta-network/forta-core-go/protocol"
+	"github.com/forta-network/forta-node/config"
+	"github.com/stretchr/testify/require"
+)
+
+func testAlert(botID string, severity protocol.Finding_Severity) *Alert {
+	return &Alert{
+		BotID: botID,
+		Alert: &protocol.Alert{Finding: &protocol.Finding{Severity: severity}},
+	}
+}
+
+func TestComputeSignature(t *testing.T) {
+	r := require.New(t)
+
+	// printf '{"alert":1}' | openssl dgst -sha256 -hmac 'secret'
+	r.Equal(
+		"sha256=b4c461f1522f34f430ab0206c55e466f4eb73fd1b2abfed81b600f32a350464c",
+		ComputeSignature([]byte("secret"), []byte(`{"alert":1}`)),
+	)
+	r.NotEqual(
+		ComputeSignature([]byte("secret"), []byte(`{"alert":1}`)),
+		ComputeSignature([]byte("other"), []byte(`{"alert":1}`)),
+	)
+}
+
+func TestSink_Matches(t *testing.T) {
+	r := require.New(t)
+
+	s := newSink(0, config.WebhookConfig{
+		MinSeverity: "high",
+		BotIDs:      []string{"0xBOT1"},
+	})
+	r.True(s.matches(testAlert("0xbot1", protocol.Finding_HIGH)))
+	r.True(s.matches(testAlert("0xbot1", protocol.Finding_CRITICAL)))
+	r.False(s.matches(testAlert("0xbot1", protocol.Finding_MEDIUM)))
+	r.False(s.matches(testAlert("0xbot2", protocol.Finding_CRITICAL)))
+
+	s = newSink(0, config.WebhookConfig{})
+	r.True(s.matches(testAlert("0xbot2", protocol.Finding_INFO)))
+	r.True(s.matches(&Alert{BotID: "0xbot2", Alert: &protocol.Alert{}}))
+}
+
+func TestSinks_Send(t *testing.T) {
+	r := require.New(t)
+
+	type received struct {
+		body      []byte
+		signature string
+	}
+	receivedCh := make(chan received, 10)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		b, _ := io.ReadAll(req.Body)
+		receivedCh <- received{body: b, signature: req.Header.Get(SignatureHeader)}
+	}))
+	defer server.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	sinks := NewSinks(ctx, []config.WebhookConfig{
+		{URL: server.URL, Secret: "secret", MinSeverity: "HIGH"},
+	})
+
+	batch := &protocol.AlertBatch{
+		Results: []*protocol.BlockResults{
+			{
+				Results: []*protocol.AgentAlerts{
+					{
+						Alerts: []*protocol.SignedAlert{
+							{Alert: &protocol.Alert{Id: "alert1", Finding: &protocol.Finding{Severity: protocol.Finding_LOW}}},
+							{Alert: &protocol.Alert{Id: "alert2", Finding: &protocol.Finding{Severity: protocol.Finding_HIGH}}},
+						},
+					},
+				},
+			},
+		},
+	}
+	sinks.Send(batch)
+
+	select {
+	case rec := <-receivedCh:
+		r.Contains(string(rec.body), "alert2")
+		r.Equal(ComputeSignature([]byte("secret"), rec.body), rec.signature)
+	case <-time.After(time.Second * 5):
+		r.FailNow("webhook did not receive the alert")
+	}
+	select {
+	case <-receivedCh:
+		r.FailNow("webhook received unexpected alert")
+	case <-time.After(time.Millisecond * 100):
+	}
+}
@@ -0,0 +1,74 @@
+package registry
+
+import (
+	"strconv"
+
+	"github.com/forta-network/forta-node/config"
+	log "github.com/sirupsen/logrus"
+)
+
+const maxPort = 65535
+
+// grpcPortAllocator assigns the agent gRPC ports from the configured range. An agent keeps
+// its port as long as it is in the latest agent list.
+type grpcPortAllocator struct {
+	start int
+	count int
+	ports map[string]int // agent ID -> port
+}
+
+func newGrpcPortAllocator(cfg config.AgentRuntimeConfig) *grpcPortAllocator {
+	start := cfg.GrpcPortStart
+	if start <= 0 {
+		start, _ = strconv.Atoi(config.AgentGrpcPort)
+	}
+	count := cfg.GrpcPortRange
+	if start+count-1 > maxPort {
+		count = maxPort - start + 1
+	}
+	return &grpcPortAllocator{
+		start: start,
+		count: count,
+		ports: make(map[string]int),
+	}
+}
+
+// Assign assigns the ports to the agents and releases the ports of the agents which are
+// not in the list anymore.
+func (alloc *grpcPortAllocator) Assign(agents []*config.AgentConfig) {
+	if alloc.count <= 1 {
+		for _, agent := range agents {
+			agent.AssignedGrpcPort = alloc.start
+		}
+		return
+	}
+
+	ports := make(map[string]int)
+	used := make(map[int]bool)
+	for _, agent := range agents {
+		if port, ok := alloc.ports[agent.ID]; ok {
+			ports[agent.ID] = port
+			used[port] = true
+		}
+	}
+	next := alloc.start
+	for _, agent := range agents {
+		port, ok := ports[agent.ID]
+		if !ok {
+			for next < alloc.start+alloc.count && used[next] {
+				next++
+			}
+			if next < alloc.start+alloc.count {
+				port = next
+				used[port] = true
+			} else {
+				// the agents have their own networks so sharing the port is not a conflict
+				log.WithField("botId", agent.ID).Warn("agent grpc port range is exhausted - using the first port")
+				port = alloc.start
+			}
+			ports[agent.ID] = port
+		}
+		agent.AssignedGrpcPort = port
+	}
+	alloc.ports = ports
+}
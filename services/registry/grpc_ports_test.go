@@ -0,0 +1,54 @@
+package registry
+
+import (
+	"testing"
+
+	"github.com/forta-network/forta-node/config"
+	"github.com/stretchr/testify/require"
+)
+
+func testAgents(ids ...string) (agents []*config.AgentConfig) {
+	for _, id := range ids {
+		agents = append(agents, &config.AgentConfig{ID: id})
+	}
+	return
+}
+
+func assignedPorts(agents []*config.AgentConfig) map[string]int {
+	ports := make(map[string]int)
+	for _, agent := range agents {
+		ports[agent.ID] = agent.AssignedGrpcPort
+	}
+	return ports
+}
+
+func TestGrpcPortAllocator_Default(t *testing.T) {
+	r := require.New(t)
+
+	alloc := newGrpcPortAllocator(config.AgentRuntimeConfig{})
+	agents := testAgents("1", "2")
+	alloc.Assign(agents)
+	r.Equal(map[string]int{"1": 50051, "2": 50051}, assignedPorts(agents))
+	r.Equal(config.AgentGrpcPort, agents[0].GrpcPort())
+}
+
+func TestGrpcPortAllocator_Range(t *testing.T) {
+	r := require.New(t)
+
+	alloc := newGrpcPortAllocator(config.AgentRuntimeConfig{GrpcPortStart: 60000, GrpcPortRange: 3})
+
+	agents := testAgents("1", "2")
+	alloc.Assign(agents)
+	r.Equal(map[string]int{"1": 60000, "2": 60001}, assignedPorts(agents))
+
+	// agent 2 keeps its port and agent 3 reuses the port of the removed agent 1
+	agents = testAgents("2", "3", "4")
+	alloc.Assign(agents)
+	r.Equal(map[string]int{"2": 60001, "3": 60000, "4": 60002}, assignedPorts(agents))
+	r.Equal("60001", agents[0].GrpcPort())
+
+	// the range is exhausted
+	agents = testAgents("2", "3", "4", "5")
+	alloc.Assign(agents)
+	r.Equal(map[string]int{"2": 60001, "3": 60000, "4": 60002, "5": 60000}, assignedPorts(agents))
+}
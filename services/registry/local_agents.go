@@ -0,0 +1,111 @@
+package registry
+
+import (
+	"os"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/forta-network/forta-core-go/clients/health"
+	"github.com/forta-network/forta-node/config"
+	"github.com/forta-network/forta-node/store"
+	"github.com/fsnotify/fsnotify"
+	log "github.com/sirupsen/logrus"
+)
+
+const localAgentsReloadDelay = time.Second
+
+// LocalAgentsStore merges the local agents with the registry agents.
+type LocalAgentsStore interface {
+	store.RegistryStore
+	LocalAgents() []*config.AgentConfig
+}
+
+// localAgentsAllowed tells if the agents from the local agents file can run.
+func (rs *RegistryService) localAgentsAllowed() bool {
+	return rs.cfg.Development || rs.cfg.Agent.AllowLocal
+}
+
+// initLocalAgents adds the local agents to the registry store if they are allowed.
+func (rs *RegistryService) initLocalAgents() {
+	if !rs.localAgentsAllowed() {
+		if _, err := os.Stat(path.Join(rs.cfg.FortaDir, store.LocalAgentsFileName)); err == nil {
+			log.Warn("ignoring the local agents file - please set agent.allowLocal: true in the config to run the local agents")
+		}
+		return
+	}
+	rs.localAgents = store.NewLocalAgentsStore(rs.registryStore, rs.cfg.FortaDir)
+	rs.registryStore = rs.localAgents
+}
+
+// watchLocalAgents publishes the agents again when the local agents file changes.
+func (rs *RegistryService) watchLocalAgents() {
+	filePath := path.Join(rs.cfg.FortaDir, store.LocalAgentsFileName)
+	logger := log.WithField("file", filePath)
+
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		logger.WithError(err).Error("failed to create the local agents watcher")
+		return
+	}
+	defer watcher.Close()
+
+	// watch the dir because the file may not exist yet and editors usually replace the file
+	if err := watcher.Add(rs.cfg.FortaDir); err != nil {
+		logger.WithError(err).Error("failed to watch the forta dir")
+		return
+	}
+
+	// wait for the writes to settle down before reloading
+	reloadTimer := time.NewTimer(0)
+	<-reloadTimer.C
+
+	for {
+		select {
+		case event, ok := <-watcher.Events:
+			if !ok {
+				return
+			}
+			if path.Clean(event.Name) != filePath || event.Op&(fsnotify.Write|fsnotify.Create|fsnotify.Rename|fsnotify.Remove) == 0 {
+				continue
+			}
+			reloadTimer.Reset(localAgentsReloadDelay)
+
+		case err, ok := <-watcher.Errors:
+			if !ok {
+				return
+			}
+			logger.WithError(err).Warn("local agents watcher error")
+
+		case <-reloadTimer.C:
+			logger.Info("local agents file changed")
+			err := rs.publishLatestAgents()
+			rs.lastErr.Set(err)
+			if err != nil {
+				log.WithError(err).Error("failed to publish the latest agents")
+			}
+
+		case <-rs.done:
+			return
+		}
+	}
+}
+
+func (rs *RegistryService) localAgentsReport() *health.Report {
+	details := "disabled"
+	if rs.localAgents != nil {
+		var agentIDs []string
+		for _, agent := range rs.localAgents.LocalAgents() {
+			agentIDs = append(agentIDs, agent.ID)
+		}
+		details = "none"
+		if len(agentIDs) > 0 {
+			details = strings.Join(agentIDs, ", ")
+		}
+	}
+	return &health.Report{
+		Name:    "agents.local",
+		Status:  health.StatusInfo,
+		Details: details,
+	}
+}
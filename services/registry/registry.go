@@ -28,11 +28,13 @@ type RegistryService struct {
 
 	rpcClient     *rpc.Client
 	registryStore store.RegistryStore
+	localAgents   LocalAgentsStore
 
 	agentsConfigs []*config.AgentConfig
 	done          chan struct{}
 	version       string
 	sem           *semaphore.Weighted
+	grpcPorts     *grpcPortAllocator
 
 	lastChecked        health.TimeTracker
 	lastChangeDetected health.TimeTracker
@@ -66,15 +68,19 @@ func (rs *RegistryService) Init() error {
 		regStr store.RegistryStore
 		err    error
 	)
-	if rs.cfg.LocalModeConfig.Enable {
+	switch {
+	case rs.cfg.LocalModeConfig.Enable:
 		regStr, err = store.NewPrivateRegistryStore(context.Background(), rs.cfg)
-	} else {
+	case rs.cfg.OfflineSkip("registry"):
+		regStr = store.NewOfflineRegistryStore()
+	default:
 		regStr, err = store.NewRegistryStore(context.Background(), rs.cfg, rs.ethClient)
 	}
 	if err != nil {
 		return err
 	}
 	rs.registryStore = regStr
+	rs.initLocalAgents()
 	return nil
 }
 
@@ -88,6 +94,10 @@ func (rs *RegistryService) Start() error {
 }
 
 func (rs *RegistryService) start() error {
+	if rs.localAgents != nil {
+		go rs.watchLocalAgents()
+	}
+	rs.msgClient.Subscribe(messaging.SubjectAgentsActionReset, messaging.AgentsHandler(rs.handleAgentsReset))
 	go func() {
 		ticker := time.NewTicker(time.Duration(rs.cfg.Registry.CheckIntervalSeconds) * time.Second)
 		for {
@@ -114,6 +124,10 @@ func (rs *RegistryService) publishLatestAgents() error {
 		}
 		if changed {
 			rs.lastChangeDetected.Set()
+			if rs.grpcPorts == nil {
+				rs.grpcPorts = newGrpcPortAllocator(rs.cfg.Agent)
+			}
+			rs.grpcPorts.Assign(agts)
 			log.WithField("count", len(agts)).Infof("publishing list of agents")
 			rs.agentsConfigs = agts
 			rs.msgClient.Publish(messaging.SubjectAgentsVersionsLatest, agts)
@@ -124,6 +138,22 @@ func (rs *RegistryService) publishLatestAgents() error {
 	return nil
 }
 
+// handleAgentsReset publishes the last known agents again after the agent containers are reset
+// so that all assigned agents are started again.
+func (rs *RegistryService) handleAgentsReset(payload messaging.AgentPayload) error {
+	if err := rs.sem.Acquire(context.Background(), 1); err != nil {
+		return err
+	}
+	defer rs.sem.Release(1)
+	if rs.agentsConfigs == nil {
+		log.Info("registry: no agents to publish after reset")
+		return nil
+	}
+	log.WithField("count", len(rs.agentsConfigs)).Info("publishing list of agents after reset")
+	rs.msgClient.Publish(messaging.SubjectAgentsVersionsLatest, rs.agentsConfigs)
+	return nil
+}
+
 // Stop stops the registry service.
 func (rs *RegistryService) Stop() error {
 	return nil
@@ -148,5 +178,6 @@ func (rs *RegistryService) Health() health.Reports {
 			Status:  health.StatusInfo,
 			Details: rs.lastChangeDetected.String(),
 		},
+		rs.localAgentsReport(),
 	}
 }
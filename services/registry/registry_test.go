@@ -109,3 +109,44 @@ func (s *Suite) TestDoNotPublishChanges() {
 	s.registryStore.EXPECT().GetAgentsIfChanged(s.service.scannerAddress.Hex()).Return(nil, false, nil)
 	s.NoError(s.service.publishLatestAgents())
 }
+
+func (s *Suite) TestPublishAgentsAfterReset() {
+	// nothing to publish before the first agent list
+	s.NoError(s.service.handleAgentsReset(nil))
+
+	configs := (agentConfigs)([]*config.AgentConfig{
+		{
+			ID:    testAgentIDStr,
+			Image: fmt.Sprintf("%s/%s", testContainerRegistry, testImageRef),
+		},
+	})
+	s.registryStore.EXPECT().GetAgentsIfChanged(s.service.scannerAddress.Hex()).Return(configs, true, nil)
+	s.msgClient.EXPECT().Publish(messaging.SubjectAgentsVersionsLatest, configs)
+	s.NoError(s.service.publishLatestAgents())
+
+	// the same agents are published again after the reset
+	s.msgClient.EXPECT().Publish(messaging.SubjectAgentsVersionsLatest, configs)
+	s.NoError(s.service.handleAgentsReset(nil))
+}
+
+func (s *Suite) TestInitOffline() {
+	s.service.cfg.Offline = true
+	s.NoError(s.service.Init())
+
+	// only the empty agent list is published once without the registry
+	s.msgClient.EXPECT().Publish(messaging.SubjectAgentsVersionsLatest, agentConfigs{})
+	s.NoError(s.service.publishLatestAgents())
+	s.NoError(s.service.publishLatestAgents())
+}
+
+func (s *Suite) TestInitOfflineLocalMode() {
+	s.service.cfg.Offline = true
+	s.service.cfg.LocalModeConfig.Enable = true
+	s.service.cfg.LocalModeConfig.BotImages = []string{"bot-image"}
+	s.service.cfg.LocalModeConfig.BotIDs = []string{testAgentIDStr}
+	s.NoError(s.service.Init())
+
+	// the bot IDs are skipped without the registry
+	s.msgClient.EXPECT().Publish(messaging.SubjectAgentsVersionsLatest, agentConfigs{{ID: "1", Image: "bot-image"}})
+	s.NoError(s.service.publishLatestAgents())
+}
@@ -0,0 +1,290 @@
+package runner
+
+import (
+	"context"
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/forta-network/forta-core-go/clients/health"
+	"github.com/forta-network/forta-node/config"
+	"github.com/forta-network/forta-node/healthutils"
+	"github.com/forta-network/forta-node/store"
+	"github.com/gorilla/mux"
+	log "github.com/sirupsen/logrus"
+)
+
+// admin actions
+const (
+	adminActionRestartSupervisor = "restart-supervisor"
+	adminActionRestartUpdater    = "restart-updater"
+	adminActionPauseUpdates      = "pause-updates"
+	adminActionResumeUpdates     = "resume-updates"
+	adminActionCheckUpdates      = "check-updates"
+	adminActionDrain             = "drain"
+	adminActionRetryAgents       = "retry-agents"
+	adminActionDisableAgent      = "disable-agent"
+	adminActionEnableAgent       = "enable-agent"
+	adminActionRunAgent          = "run-agent"
+	adminActionResetAgents       = "reset-agents"
+)
+
+var errContainerNotRunning = errors.New("container is not managed by the runner")
+
+// adminState is the current state of the runner as returned by the admin API.
+type adminState struct {
+	UpdaterImage    string         `json:"updaterImage"`
+	SupervisorImage string         `json:"supervisorImage"`
+	ReleaseVersion  string         `json:"releaseVersion,omitempty"`
+	ReleaseCommit   string         `json:"releaseCommit,omitempty"`
+	ReleaseChannel  string         `json:"releaseChannel"`
+	UpdatesPaused   bool           `json:"updatesPaused"`
+	Restarts        map[string]int `json:"restarts"`
+}
+
+// adminResult is the response of the admin actions.
+type adminResult struct {
+	Action string `json:"action"`
+	OK     bool   `json:"ok"`
+	Error  string `json:"error,omitempty"`
+	// Agents are the agents which the action was applied to.
+	Agents []string `json:"agents,omitempty"`
+	// Run is the launched one-off agent run.
+	Run *healthutils.AgentRunResult `json:"run,omitempty"`
+}
+
+func (runner *Runner) adminRouter(router *mux.Router) {
+	admin := router.PathPrefix("/admin").Subrouter()
+	admin.Use(runner.requireAdminToken)
+	admin.HandleFunc("/state", runner.handleAdminState).Methods(http.MethodGet)
+	admin.HandleFunc("/version", runner.handleAdminVersion).Methods(http.MethodGet)
+	admin.HandleFunc("/releases", runner.handleAdminReleases).Methods(http.MethodGet)
+	admin.HandleFunc("/supervisor/restart", runner.handleAdminAction(adminActionRestartSupervisor)).Methods(http.MethodPost)
+	admin.HandleFunc("/updater/restart", runner.handleAdminAction(adminActionRestartUpdater)).Methods(http.MethodPost)
+	admin.HandleFunc("/updates/pause", runner.handleAdminAction(adminActionPauseUpdates)).Methods(http.MethodPost)
+	admin.HandleFunc("/updates/resume", runner.handleAdminAction(adminActionResumeUpdates)).Methods(http.MethodPost)
+	admin.HandleFunc("/updates/check", runner.handleAdminCheckUpdates).Methods(http.MethodPost)
+	admin.HandleFunc("/drain", runner.handleAdminAction(adminActionDrain)).Methods(http.MethodPost)
+	admin.HandleFunc("/agents/retry", runner.handleAdminRetryAgents).Methods(http.MethodPost)
+	admin.HandleFunc("/agents/{agentId}/disable", runner.handleAdminDisableAgent).Methods(http.MethodPost)
+	admin.HandleFunc("/agents/{agentId}/enable", runner.handleAdminEnableAgent).Methods(http.MethodPost)
+	admin.HandleFunc("/agents/runs", runner.handleAdminRunAgent).Methods(http.MethodPost)
+	admin.HandleFunc("/agents/reset", runner.handleAdminResetAgents).Methods(http.MethodPost)
+}
+
+// requireAdminToken rejects the requests without the admin token. The admin API is unavailable
+// if the token could not be loaded.
+func (runner *Runner) requireAdminToken(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		token := strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer ")
+		if len(runner.adminToken) == 0 || subtle.ConstantTimeCompare([]byte(token), []byte(runner.adminToken)) != 1 {
+			http.Error(w, "unauthorized", http.StatusUnauthorized)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+func (runner *Runner) handleAdminState(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(runner.adminState())
+}
+
+func (runner *Runner) handleAdminAction(action string) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		result := &adminResult{Action: action, OK: true}
+		if err := runner.doAdminAction(action); err != nil {
+			log.WithError(err).WithField("action", action).Error("admin action failed")
+			result.OK = false
+			result.Error = err.Error()
+			w.WriteHeader(http.StatusInternalServerError)
+		}
+		_ = json.NewEncoder(w).Encode(result)
+	}
+}
+
+// handleAdminRetryAgents retries the quarantined agents. The optional agentId query param selects
+// the agent.
+func (runner *Runner) handleAdminRetryAgents(w http.ResponseWriter, r *http.Request) {
+	runner.handleAdminAgentsAction(w, adminActionRetryAgents, func() ([]string, error) {
+		return runner.RetryQuarantinedAgents(r.URL.Query().Get("agentId"))
+	})
+}
+
+// handleAdminDisableAgent stops the agent and keeps it stopped until it is enabled. The optional
+// reason query param is shown in the health reports.
+func (runner *Runner) handleAdminDisableAgent(w http.ResponseWriter, r *http.Request) {
+	runner.handleAdminAgentsAction(w, adminActionDisableAgent, func() ([]string, error) {
+		return runner.DisableAgent(mux.Vars(r)["agentId"], r.URL.Query().Get("reason"))
+	})
+}
+
+// handleAdminResetAgents removes all agent containers and lets the assigned agents start again.
+func (runner *Runner) handleAdminResetAgents(w http.ResponseWriter, r *http.Request) {
+	runner.handleAdminAgentsAction(w, adminActionResetAgents, runner.ResetAgents)
+}
+
+// handleAdminEnableAgent runs the disabled agent again.
+func (runner *Runner) handleAdminEnableAgent(w http.ResponseWriter, r *http.Request) {
+	runner.handleAdminAgentsAction(w, adminActionEnableAgent, func() ([]string, error) {
+		return runner.EnableAgent(mux.Vars(r)["agentId"])
+	})
+}
+
+// handleAdminRunAgent launches a one-off run of the agent in the body over the block range.
+func (runner *Runner) handleAdminRunAgent(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	log.WithField("action", adminActionRunAgent).Info("received admin action")
+	result := &adminResult{Action: adminActionRunAgent, OK: true}
+	var runReq healthutils.AgentRunRequest
+	err := json.NewDecoder(r.Body).Decode(&runReq)
+	if err == nil {
+		err = runReq.Validate()
+	}
+	status := http.StatusBadRequest
+	if err == nil {
+		result.Run, err = runner.RunAgentOnce(&runReq)
+		status = http.StatusInternalServerError
+	}
+	if err != nil {
+		log.WithError(err).WithField("action", adminActionRunAgent).Error("admin action failed")
+		runner.adminAction.Set(fmt.Sprintf("%s failed: %v", adminActionRunAgent, err))
+		result.OK = false
+		result.Error = err.Error()
+		w.WriteHeader(status)
+	} else {
+		runner.adminAction.Set(adminActionRunAgent)
+	}
+	_ = json.NewEncoder(w).Encode(result)
+}
+
+// handleAdminAgentsAction runs the agents action and responds with the agents which the action
+// was applied to.
+func (runner *Runner) handleAdminAgentsAction(w http.ResponseWriter, action string, do func() ([]string, error)) {
+	w.Header().Set("Content-Type", "application/json")
+	log.WithField("action", action).Info("received admin action")
+	result := &adminResult{Action: action, OK: true}
+	agents, err := do()
+	if err != nil {
+		log.WithError(err).WithField("action", action).Error("admin action failed")
+		runner.adminAction.Set(fmt.Sprintf("%s failed: %v", action, err))
+		result.OK = false
+		result.Error = err.Error()
+		w.WriteHeader(http.StatusInternalServerError)
+	} else {
+		runner.adminAction.Set(action)
+		result.Agents = agents
+	}
+	_ = json.NewEncoder(w).Encode(result)
+}
+
+func (runner *Runner) doAdminAction(action string) error {
+	log.WithField("action", action).Info("received admin action")
+	var err error
+	switch action {
+	case adminActionRestartSupervisor:
+		err = runner.restartContainer(config.DockerSupervisorContainerName)
+	case adminActionRestartUpdater:
+		err = runner.restartContainer(config.DockerUpdaterContainerName)
+	case adminActionPauseUpdates:
+		runner.pauseUpdates()
+	case adminActionResumeUpdates:
+		runner.resumeUpdates()
+	case adminActionDrain:
+		ctx, cancel := context.WithTimeout(runner.ctx, defaultDrainTimeout)
+		defer cancel()
+		err = runner.Drain(ctx)
+	default:
+		err = fmt.Errorf("unknown action: %s", action)
+	}
+	if err != nil {
+		runner.adminAction.Set(fmt.Sprintf("%s failed: %v", action, err))
+		return err
+	}
+	runner.adminAction.Set(action)
+	return nil
+}
+
+// restartContainer replaces the container with a new one from the current images.
+func (runner *Runner) restartContainer(name string) (err error) {
+	runner.containerMu.Lock()
+	defer runner.containerMu.Unlock()
+
+	refs := store.ImageRefs{
+		Supervisor:  runner.currentSupervisorImg,
+		Updater:     runner.currentUpdaterImg,
+		ReleaseInfo: runner.currentRelease,
+	}
+	logger := log.WithField("name", name)
+	switch {
+	case name == config.DockerSupervisorContainerName && runner.supervisorContainer != nil:
+		err = runner.replaceSupervisor(logger, refs)
+	case name == config.DockerUpdaterContainerName && runner.updaterContainer != nil:
+		err = runner.replaceUpdater(logger, refs)
+	default:
+		return fmt.Errorf("%w: %s", errContainerNotRunning, name)
+	}
+	if err != nil {
+		return err
+	}
+	runner.countRestart(name)
+	return nil
+}
+
+// countRestart increments the restart count of the container. The container lock should be
+// held by the caller.
+func (runner *Runner) countRestart(name string) {
+	if runner.restarts == nil {
+		runner.restarts = make(map[string]int)
+	}
+	runner.restarts[name]++
+}
+
+func (runner *Runner) adminState() *adminState {
+	state := &adminState{
+		ReleaseChannel: runner.releaseChannel(),
+		UpdatesPaused:  runner.updatesPaused.Load(),
+		Restarts:       make(map[string]int),
+	}
+
+	runner.containerMu.RLock()
+	defer runner.containerMu.RUnlock()
+	state.UpdaterImage = runner.currentUpdaterImg
+	state.SupervisorImage = runner.currentSupervisorImg
+	if runner.currentRelease != nil {
+		state.ReleaseVersion = runner.currentRelease.Manifest.Release.Version
+		state.ReleaseCommit = runner.currentRelease.Manifest.Release.Commit
+	}
+	for name, count := range runner.restarts {
+		state.Restarts[name] = count
+	}
+	return state
+}
+
+func (runner *Runner) adminReports() (reports health.Reports) {
+	if action := runner.adminAction.GetReport("forta.admin.last-action"); len(action.Details) > 0 {
+		reports = append(reports, action)
+	}
+
+	runner.containerMu.RLock()
+	defer runner.containerMu.RUnlock()
+	var names []string
+	for name := range runner.restarts {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		reports = append(reports, &health.Report{
+			Name:    fmt.Sprintf("forta.restarts.%s", name),
+			Status:  health.StatusInfo,
+			Details: strconv.Itoa(runner.restarts[name]),
+		})
+	}
+	return
+}
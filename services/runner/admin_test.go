@@ -0,0 +1,96 @@
+package runner
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/forta-network/forta-node/clients"
+	"github.com/forta-network/forta-node/config"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAdmin_Unauthorized(t *testing.T) {
+	r := require.New(t)
+
+	runner := &Runner{adminToken: "token1"}
+	server := httptest.NewServer(runner.controlRouter())
+	defer server.Close()
+
+	for _, token := range []string{"", "token2"} {
+		req, err := http.NewRequest(http.MethodPost, server.URL+"/admin/updates/pause", nil)
+		r.NoError(err)
+		req.Header.Set("Authorization", "Bearer "+token)
+		resp, err := http.DefaultClient.Do(req)
+		r.NoError(err)
+		resp.Body.Close()
+		r.Equal(http.StatusUnauthorized, resp.StatusCode)
+	}
+	r.False(runner.updatesPaused.Load())
+
+	// no token loaded: admin api is not available
+	runner.adminToken = ""
+	req, err := http.NewRequest(http.MethodGet, server.URL+"/admin/state", nil)
+	r.NoError(err)
+	req.Header.Set("Authorization", "Bearer ")
+	resp, err := http.DefaultClient.Do(req)
+	r.NoError(err)
+	resp.Body.Close()
+	r.Equal(http.StatusUnauthorized, resp.StatusCode)
+}
+
+func TestAdmin_Actions(t *testing.T) {
+	r := require.New(t)
+
+	runner, dockerClient, _ := testStateRunner(t)
+	runner.adminToken = "token1"
+	runner.currentSupervisorImg = "supervisor1"
+	runner.supervisorContainer = &clients.DockerContainer{Name: config.DockerSupervisorContainerName, ID: "supervisor-id"}
+	server := httptest.NewServer(runner.controlRouter())
+	defer server.Close()
+
+	call := func(method, path string, v interface{}) int {
+		req, err := http.NewRequest(method, server.URL+path, nil)
+		r.NoError(err)
+		req.Header.Set("Authorization", "Bearer token1")
+		resp, err := http.DefaultClient.Do(req)
+		r.NoError(err)
+		defer resp.Body.Close()
+		r.Equal("application/json", resp.Header.Get("Content-Type"))
+		r.NoError(json.NewDecoder(resp.Body).Decode(v))
+		return resp.StatusCode
+	}
+
+	var result adminResult
+	r.Equal(http.StatusOK, call(http.MethodPost, "/admin/updates/pause", &result))
+	r.True(result.OK)
+	r.Equal("true", runner.updatesPausedReport().Details)
+
+	dockerClient.EXPECT().TerminateContainer(gomock.Any(), "supervisor-id", gomock.Any()).Return(nil)
+	dockerClient.EXPECT().WaitContainerExit(gomock.Any(), "supervisor-id").Return(nil)
+	dockerClient.EXPECT().Prune(gomock.Any()).Return(nil)
+	dockerClient.EXPECT().WaitContainerPrune(gomock.Any(), "supervisor-id").Return(nil)
+	expectStartContainer(r, dockerClient, "supervisor", "supervisor1", "supervisor-id2")
+	r.Equal(http.StatusOK, call(http.MethodPost, "/admin/supervisor/restart", &result))
+	r.True(result.OK)
+	r.Equal("supervisor-id2", runner.supervisorContainer.ID)
+
+	// no updater to restart
+	r.Equal(http.StatusInternalServerError, call(http.MethodPost, "/admin/updater/restart", &result))
+	r.False(result.OK)
+	r.Contains(result.Error, errContainerNotRunning.Error())
+
+	var state adminState
+	r.Equal(http.StatusOK, call(http.MethodGet, "/admin/state", &state))
+	r.Equal("supervisor1", state.SupervisorImage)
+	r.True(state.UpdatesPaused)
+	r.Equal(1, state.Restarts[config.DockerSupervisorContainerName])
+
+	reports := runner.adminReports()
+	r.Len(reports, 2)
+	r.Contains(reports[0].Details, "restart-updater failed")
+	r.Equal("forta.restarts."+config.DockerSupervisorContainerName, reports[1].Name)
+	r.Equal("1", reports[1].Details)
+}
@@ -0,0 +1,55 @@
+package runner
+
+import (
+	"fmt"
+
+	"github.com/forta-network/forta-node/healthutils"
+	log "github.com/sirupsen/logrus"
+)
+
+// DisableAgent asks the supervisor to stop the agent and to keep it stopped until it is enabled.
+func (runner *Runner) DisableAgent(agentID, reason string) ([]string, error) {
+	token, port, err := runner.supervisorAdminEndpoint()
+	if err != nil {
+		return nil, err
+	}
+	result, err := runner.disableAgent(port, token, agentID, reason)
+	if err != nil {
+		return nil, fmt.Errorf("failed to disable the agent: %v", err)
+	}
+	log.WithField("agentId", agentID).WithField("reason", reason).Info("disabled the agent")
+	return result.Agents, nil
+}
+
+// EnableAgent asks the supervisor to run the disabled agent again.
+func (runner *Runner) EnableAgent(agentID string) ([]string, error) {
+	token, port, err := runner.supervisorAdminEndpoint()
+	if err != nil {
+		return nil, err
+	}
+	result, err := runner.enableAgent(port, token, agentID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to enable the agent: %v", err)
+	}
+	log.WithField("agents", result.Agents).Info("enabled the agents")
+	return result.Agents, nil
+}
+
+// RunAgentOnce asks the supervisor to run the agent once over the block range.
+func (runner *Runner) RunAgentOnce(req *healthutils.AgentRunRequest) (*healthutils.AgentRunResult, error) {
+	token, port, err := runner.supervisorAdminEndpoint()
+	if err != nil {
+		return nil, err
+	}
+	result, err := runner.runAgentOnce(port, token, req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to run the agent: %v", err)
+	}
+	log.WithFields(log.Fields{
+		"agentId":    req.ID,
+		"runId":      result.RunID,
+		"startBlock": req.StartBlock,
+		"stopBlock":  req.StopBlock,
+	}).Info("launched one-off agent run")
+	return result, nil
+}
@@ -0,0 +1,106 @@
+package runner
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/forta-network/forta-node/healthutils"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAdmin_DisableEnableAgent(t *testing.T) {
+	for _, testCase := range []struct {
+		path   string
+		action string
+	}{
+		{path: "/admin/agents/0x123/disable?reason=too+many+alerts", action: adminActionDisableAgent},
+		{path: "/admin/agents/0x123/enable", action: adminActionEnableAgent},
+	} {
+		runner, r := testDrainRunner(t)
+		runner.adminToken = "token1"
+		runner.disableAgent = func(port, token, agentID, reason string) (*healthutils.AgentDisableResult, error) {
+			r.Equal("1001", port)
+			r.Equal("0x123", agentID)
+			r.Equal("too many alerts", reason)
+			return &healthutils.AgentDisableResult{Agents: []string{agentID}}, nil
+		}
+		runner.enableAgent = func(port, token, agentID string) (*healthutils.AgentDisableResult, error) {
+			r.Equal("1001", port)
+			r.Equal("0x123", agentID)
+			return &healthutils.AgentDisableResult{Agents: []string{agentID}}, nil
+		}
+		server := httptest.NewServer(runner.controlRouter())
+
+		req, err := http.NewRequest(http.MethodPost, server.URL+testCase.path, nil)
+		r.NoError(err)
+		req.Header.Set("Authorization", "Bearer token1")
+		resp, err := http.DefaultClient.Do(req)
+		r.NoError(err)
+		r.Equal(http.StatusOK, resp.StatusCode)
+
+		var result adminResult
+		r.NoError(json.NewDecoder(resp.Body).Decode(&result))
+		resp.Body.Close()
+		server.Close()
+		r.True(result.OK)
+		r.Equal(testCase.action, result.Action)
+		r.Equal([]string{"0x123"}, result.Agents)
+		r.Equal(testCase.action, runner.adminAction.GetReport("forta.admin.last-action").Details)
+	}
+}
+
+func TestAdmin_RunAgent(t *testing.T) {
+	for _, testCase := range []struct {
+		name   string
+		body   string
+		status int
+	}{
+		{name: "valid", body: `{"id":"0x123","image":"image1","startBlock":100,"stopBlock":200}`, status: http.StatusOK},
+		{name: "bad range", body: `{"id":"0x123","image":"image1","startBlock":200,"stopBlock":100}`, status: http.StatusBadRequest},
+		{name: "bad body", body: `{`, status: http.StatusBadRequest},
+	} {
+		t.Run(testCase.name, func(t *testing.T) {
+			r := require.New(t)
+			// the supervisor is reached only with the valid requests
+			runner, _, _ := testStateRunner(t)
+			if testCase.status == http.StatusOK {
+				runner, _ = testDrainRunner(t)
+			}
+			runner.adminToken = "token1"
+			var runs int
+			runner.runAgentOnce = func(port, token string, req *healthutils.AgentRunRequest) (*healthutils.AgentRunResult, error) {
+				r.Equal("1001", port)
+				r.Equal("0x123", req.ID)
+				r.Equal(uint64(100), req.StartBlock)
+				r.Equal(uint64(200), req.StopBlock)
+				runs++
+				return &healthutils.AgentRunResult{RunID: "run1"}, nil
+			}
+			server := httptest.NewServer(runner.controlRouter())
+			defer server.Close()
+
+			req, err := http.NewRequest(http.MethodPost, server.URL+"/admin/agents/runs", strings.NewReader(testCase.body))
+			r.NoError(err)
+			req.Header.Set("Authorization", "Bearer token1")
+			resp, err := http.DefaultClient.Do(req)
+			r.NoError(err)
+			defer resp.Body.Close()
+			r.Equal(testCase.status, resp.StatusCode)
+
+			var result adminResult
+			r.NoError(json.NewDecoder(resp.Body).Decode(&result))
+			r.Equal(adminActionRunAgent, result.Action)
+			if testCase.status != http.StatusOK {
+				r.False(result.OK)
+				r.Zero(runs)
+				return
+			}
+			r.True(result.OK)
+			r.Equal(1, runs)
+			r.Equal("run1", result.Run.RunID)
+		})
+	}
+}
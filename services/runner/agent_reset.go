@@ -0,0 +1,22 @@
+package runner
+
+import (
+	"fmt"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// ResetAgents asks the supervisor to remove all agent containers while the service containers
+// keep running, and returns the removed containers. The assigned agents are started again.
+func (runner *Runner) ResetAgents() ([]string, error) {
+	token, port, err := runner.supervisorAdminEndpoint()
+	if err != nil {
+		return nil, err
+	}
+	result, err := runner.resetAgents(port, token)
+	if err != nil {
+		return nil, fmt.Errorf("failed to reset the agents: %v", err)
+	}
+	log.WithField("containers", result.Containers).Info("reset the agents")
+	return result.Containers, nil
+}
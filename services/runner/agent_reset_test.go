@@ -0,0 +1,37 @@
+package runner
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/forta-network/forta-node/healthutils"
+)
+
+func TestAdmin_ResetAgents(t *testing.T) {
+	runner, r := testDrainRunner(t)
+	runner.adminToken = "token1"
+	runner.resetAgents = func(port, token string) (*healthutils.AgentResetResult, error) {
+		r.Equal("1001", port)
+		r.NotEmpty(token)
+		return &healthutils.AgentResetResult{Containers: []string{"forta-agent-0x123"}}, nil
+	}
+	server := httptest.NewServer(runner.controlRouter())
+	defer server.Close()
+
+	req, err := http.NewRequest(http.MethodPost, server.URL+"/admin/agents/reset", nil)
+	r.NoError(err)
+	req.Header.Set("Authorization", "Bearer token1")
+	resp, err := http.DefaultClient.Do(req)
+	r.NoError(err)
+	defer resp.Body.Close()
+	r.Equal(http.StatusOK, resp.StatusCode)
+
+	var result adminResult
+	r.NoError(json.NewDecoder(resp.Body).Decode(&result))
+	r.True(result.OK)
+	r.Equal([]string{"forta-agent-0x123"}, result.Agents)
+	r.Equal(adminActionResetAgents, runner.adminAction.GetReport("forta.admin.last-action").Details)
+
+}
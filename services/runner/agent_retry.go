@@ -0,0 +1,36 @@
+package runner
+
+import (
+	"fmt"
+
+	"github.com/forta-network/forta-node/config"
+	log "github.com/sirupsen/logrus"
+)
+
+// RetryQuarantinedAgents asks the supervisor to restart the quarantined agent, or all of the
+// quarantined agents if the agent ID is empty, and returns the retried agents.
+func (runner *Runner) RetryQuarantinedAgents(agentID string) ([]string, error) {
+	token, port, err := runner.supervisorAdminEndpoint()
+	if err != nil {
+		return nil, err
+	}
+	result, err := runner.retryAgents(port, token, agentID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to retry the agents: %v", err)
+	}
+	log.WithField("agents", result.Agents).Info("retrying the quarantined agents")
+	return result.Agents, nil
+}
+
+// supervisorAdminEndpoint returns the admin token and the health port of the supervisor.
+func (runner *Runner) supervisorAdminEndpoint() (token, port string, err error) {
+	token, err = config.EnsureAdminToken(runner.cfg.FortaDir)
+	if err != nil {
+		return "", "", fmt.Errorf("failed to get the admin token: %v", err)
+	}
+	port, err = runner.supervisorHealthPort()
+	if err != nil {
+		return "", "", fmt.Errorf("failed to reach the supervisor: %v", err)
+	}
+	return token, port, nil
+}
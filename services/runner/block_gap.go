@@ -0,0 +1,149 @@
+package runner
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	gethclient "github.com/ethereum/go-ethereum/ethclient"
+	"github.com/forta-network/forta-core-go/clients/health"
+	"github.com/forta-network/forta-node/config"
+	log "github.com/sirupsen/logrus"
+)
+
+const blockGapCheckTimeout = time.Second * 30
+
+// blockGap is the result of the latest comparison of the scanned block with the chain head.
+type blockGap struct {
+	Head    uint64
+	Scanned uint64
+	Err     error
+}
+
+// Gap returns the number of blocks which the scanner is behind the chain head.
+func (gap *blockGap) Gap() uint64 {
+	if gap.Scanned >= gap.Head {
+		return 0
+	}
+	return gap.Head - gap.Scanned
+}
+
+// keepBlockGapChecked compares the latest scanned block with the chain head periodically.
+func (runner *Runner) keepBlockGapChecked() {
+	if runner.ethClient == nil {
+		ethClient, err := gethclient.Dial(runner.fixTestRpcUrl(runner.cfg.Scan.JsonRpc.Url))
+		if err != nil {
+			log.WithError(err).Error("failed to create the client for checking the block gap")
+			return
+		}
+		runner.ethClient = ethClient
+	}
+
+	ticker := time.NewTicker(time.Duration(runner.cfg.RunnerConfig.BlockGapCheckIntervalSeconds) * time.Second)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-runner.ctx.Done():
+			return
+		case <-ticker.C:
+			runner.checkBlockGap()
+		}
+	}
+}
+
+// checkBlockGap gets the latest scanned block from the scanner and the chain head from the scan
+// API and stores the gap. The highest scanned block is kept if the scanner goes back.
+func (runner *Runner) checkBlockGap() {
+	ctx, cancel := context.WithTimeout(runner.ctx, blockGapCheckTimeout)
+	defer cancel()
+
+	runner.blockGapMu.RLock()
+	prev := runner.blockGap
+	runner.blockGapMu.RUnlock()
+
+	gap := &blockGap{}
+	gap.Scanned, gap.Err = runner.scannedBlock(ctx)
+	if gap.Err == nil {
+		gap.Head, gap.Err = runner.chainHead(ctx)
+	}
+	if gap.Err == nil && prev != nil && prev.Scanned > gap.Scanned {
+		gap.Scanned = prev.Scanned
+	}
+
+	runner.blockGapMu.Lock()
+	runner.blockGap = gap
+	runner.blockGapMu.Unlock()
+
+	logger := log.WithFields(log.Fields{
+		"head":    gap.Head,
+		"scanned": gap.Scanned,
+	})
+	switch {
+	case gap.Err != nil:
+		logger.WithError(gap.Err).Warn("failed to check the block gap")
+	case gap.Gap() > runner.cfg.RunnerConfig.MaxBlockGap:
+		logger.WithField("gap", gap.Gap()).Warn("scanner is behind the chain head")
+	}
+}
+
+// scannedBlock gets the latest scanned block from the scanner health report.
+func (runner *Runner) scannedBlock(ctx context.Context) (uint64, error) {
+	container, err := runner.globalClient.GetContainerByName(ctx, config.DockerScannerContainerName)
+	if err != nil {
+		return 0, fmt.Errorf("failed to get the scanner container: %v", err)
+	}
+	healthPort := containerHealthPort(*container)
+	if len(healthPort) == 0 {
+		return 0, errors.New("scanner health port is not published")
+	}
+	reports := runner.checkChildHealth(config.DockerScannerContainerName, healthPort)
+	report, ok := reports.NameContains("block-feed.last-block")
+	if !ok || len(report.Details) == 0 {
+		return 0, errors.New("no blocks scanned yet")
+	}
+	scanned, err := strconv.ParseUint(report.Details, 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid scanned block number '%s'", report.Details)
+	}
+	return scanned, nil
+}
+
+// chainHead gets the latest block number from the scan API.
+func (runner *Runner) chainHead(ctx context.Context) (uint64, error) {
+	if err := runner.scanAPILimiter.Wait(ctx); err != nil {
+		return 0, err
+	}
+	head, err := runner.ethClient.BlockNumber(ctx)
+	runner.scanAPILimiter.Done(err)
+	if err != nil {
+		return 0, fmt.Errorf("failed to get the chain head: %v", err)
+	}
+	return head, nil
+}
+
+func (runner *Runner) blockGapReports() health.Reports {
+	runner.blockGapMu.RLock()
+	defer runner.blockGapMu.RUnlock()
+	gap := runner.blockGap
+	if gap == nil {
+		return nil
+	}
+	report := &health.Report{
+		Name:   "forta.block-gap",
+		Status: health.StatusOK,
+	}
+	switch {
+	case gap.Err != nil:
+		report.Status = health.StatusUnknown
+		report.Details = gap.Err.Error()
+	default:
+		report.Details = fmt.Sprintf("%d blocks (scanned %d, chain head %d)", gap.Gap(), gap.Scanned, gap.Head)
+		if gap.Gap() > runner.cfg.RunnerConfig.MaxBlockGap {
+			report.Status = health.StatusFailing
+			report.Details = fmt.Sprintf("%s - more than %d blocks behind", report.Details, runner.cfg.RunnerConfig.MaxBlockGap)
+		}
+	}
+	return health.Reports{report}
+}
@@ -0,0 +1,77 @@
+package runner
+
+import (
+	"context"
+	"testing"
+
+	"github.com/forta-network/forta-core-go/clients/health"
+	mock_clients "github.com/forta-network/forta-node/clients/mocks"
+	"github.com/forta-network/forta-node/config"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+)
+
+type testEthClient struct {
+	head uint64
+}
+
+func (c *testEthClient) BlockNumber(ctx context.Context) (uint64, error) {
+	return c.head, nil
+}
+
+func TestCheckBlockGap(t *testing.T) {
+	r := require.New(t)
+
+	globalClient := mock_clients.NewMockDockerClient(gomock.NewController(t))
+	healthClient := &testHealthClient{reports: map[string]health.Reports{}}
+	ethClient := &testEthClient{head: 100}
+	runner := &Runner{
+		ctx:          context.Background(),
+		cfg:          config.Config{RunnerConfig: config.RunnerConfig{MaxBlockGap: 10}},
+		globalClient: globalClient,
+		healthClient: healthClient,
+		ethClient:    ethClient,
+	}
+	scanner := testContainer(config.DockerScannerContainerName, "running", 1001)
+	globalClient.EXPECT().GetContainerByName(gomock.Any(), config.DockerScannerContainerName).Return(&scanner, nil).AnyTimes()
+	setScanned := func(details string) {
+		healthClient.reports["1001"] = health.Reports{
+			{Name: "block-feed.last-block", Status: health.StatusInfo, Details: details},
+		}
+	}
+	blockGapReport := func() *health.Report {
+		reports := runner.blockGapReports()
+		r.Len(reports, 1)
+		return reports[0]
+	}
+
+	// nothing is reported before the first check
+	r.Len(runner.blockGapReports(), 0)
+
+	setScanned("")
+	runner.checkBlockGap()
+	r.Equal(health.StatusUnknown, blockGapReport().Status)
+	r.Equal("no blocks scanned yet", blockGapReport().Details)
+
+	setScanned("95")
+	runner.checkBlockGap()
+	r.Equal(health.StatusOK, blockGapReport().Status)
+	r.Equal("5 blocks (scanned 95, chain head 100)", blockGapReport().Details)
+
+	// the gap is failing after the threshold
+	ethClient.head = 120
+	runner.checkBlockGap()
+	r.Equal(health.StatusFailing, blockGapReport().Status)
+	r.Equal("25 blocks (scanned 95, chain head 120) - more than 10 blocks behind", blockGapReport().Details)
+
+	// the highest scanned block is kept
+	setScanned("90")
+	runner.checkBlockGap()
+	r.Equal(uint64(95), runner.blockGap.Scanned)
+
+	// the scanner can be ahead of the chain head of another provider
+	setScanned("130")
+	runner.checkBlockGap()
+	r.Equal(health.StatusOK, blockGapReport().Status)
+	r.Equal(uint64(0), runner.blockGap.Gap())
+}
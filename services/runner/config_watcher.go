@@ -0,0 +1,127 @@
+package runner
+
+import (
+	"path"
+	"time"
+
+	"github.com/forta-network/forta-node/config"
+	"github.com/fsnotify/fsnotify"
+	log "github.com/sirupsen/logrus"
+)
+
+const (
+	configReloadDelay            = time.Second
+	defaultLivenessCheckInterval = time.Second * 10
+)
+
+// watchConfig watches the config file and applies the settings which are safe to hot-reload.
+func (runner *Runner) watchConfig() {
+	configPath := runner.cfg.ConfigFilePath()
+	logger := log.WithField("file", configPath)
+
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		logger.WithError(err).Error("failed to create the config watcher")
+		return
+	}
+	defer watcher.Close()
+
+	// watch the dir because editors usually replace the file
+	if err := watcher.Add(path.Dir(configPath)); err != nil {
+		logger.WithError(err).Error("failed to watch the config dir")
+		return
+	}
+	logger.Info("watching the config file")
+
+	// wait for the writes to settle down before reloading
+	reloadTimer := time.NewTimer(0)
+	<-reloadTimer.C
+
+	for {
+		select {
+		case event, ok := <-watcher.Events:
+			if !ok {
+				return
+			}
+			if path.Clean(event.Name) != configPath || event.Op&(fsnotify.Write|fsnotify.Create|fsnotify.Rename) == 0 {
+				continue
+			}
+			reloadTimer.Reset(configReloadDelay)
+
+		case err, ok := <-watcher.Errors:
+			if !ok {
+				return
+			}
+			logger.WithError(err).Warn("config watcher error")
+
+		case <-reloadTimer.C:
+			runner.reloadConfig(configPath)
+
+		case <-runner.ctx.Done():
+			return
+		}
+	}
+}
+
+func (runner *Runner) reloadConfig(configPath string) {
+	logger := log.WithField("file", configPath)
+
+	newCfg, err := config.GetConfigFromFile(configPath)
+	if err != nil {
+		logger.WithError(err).Warn("failed to read the changed config - not applying")
+		return
+	}
+	runner.cfgMu.RLock()
+	runner.cfg.PrepareReloaded(&newCfg)
+	runner.cfgMu.RUnlock()
+	if err := newCfg.Validate(); err != nil {
+		logger.WithError(err).Warn("changed config is invalid - not applying")
+		return
+	}
+
+	runner.cfgMu.Lock()
+	prevChannel := runner.cfg.AutoUpdate.ReleaseChannel()
+	requiresRestart := runner.cfg.ApplyReloadable(newCfg)
+	cfg := runner.cfg
+	runner.cfgMu.Unlock()
+
+	if err := config.InitLogging(cfg, "runner"); err != nil {
+		logger.WithError(err).Warn("failed to apply the log level")
+	}
+	// the runner does not manage the containers of the external supervisor
+	if !cfg.Supervisor.External {
+		runner.livenessTicker.Reset(runner.livenessCheckInterval())
+	}
+	logger.WithFields(log.Fields{
+		"logLevel":              cfg.Log.Level,
+		"livenessCheckInterval": runner.livenessCheckInterval().String(),
+		"releaseChannel":        cfg.AutoUpdate.ReleaseChannel(),
+	}).Info("reloaded config")
+
+	if channel := cfg.AutoUpdate.ReleaseChannel(); channel != prevChannel && runner.tracksUpdates() {
+		if err := runner.switchReleaseChannel(channel); err != nil {
+			logger.WithError(err).Error("failed to switch the release channel")
+		}
+	}
+
+	if len(requiresRestart) > 0 {
+		logger.WithField("changed", requiresRestart).Warn("config changes require restart")
+	}
+}
+
+// configSnapshot returns a copy of the config so that the hot-reloaded values are not read
+// while they are being reloaded.
+func (runner *Runner) configSnapshot() config.Config {
+	runner.cfgMu.RLock()
+	defer runner.cfgMu.RUnlock()
+	return runner.cfg
+}
+
+func (runner *Runner) livenessCheckInterval() time.Duration {
+	runner.cfgMu.RLock()
+	defer runner.cfgMu.RUnlock()
+	if runner.cfg.RunnerConfig.LivenessCheckIntervalSeconds <= 0 {
+		return defaultLivenessCheckInterval
+	}
+	return time.Duration(runner.cfg.RunnerConfig.LivenessCheckIntervalSeconds) * time.Second
+}
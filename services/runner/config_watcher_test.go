@@ -0,0 +1,94 @@
+package runner
+
+import (
+	"context"
+	"os"
+	"path"
+	"testing"
+	"time"
+
+	"github.com/creasty/defaults"
+	"github.com/forta-network/forta-node/config"
+	"github.com/forta-network/forta-node/store"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReloadConfig_EnvDefaults(t *testing.T) {
+	r := require.New(t)
+
+	var cfg config.Config
+	r.NoError(defaults.Set(&cfg))
+	cfg.FortaDir = t.TempDir()
+	cfg.ApplyEnvDefaults()
+	runner := &Runner{
+		ctx:            context.Background(),
+		cfg:            cfg,
+		livenessTicker: time.NewTicker(time.Hour),
+	}
+	defer runner.livenessTicker.Stop()
+
+	// the container registry is not in the file and comes from the env defaults
+	configPath := path.Join(cfg.FortaDir, config.DefaultConfigFileName)
+	r.NoError(os.WriteFile(configPath, []byte("runner:\n  livenessCheckIntervalSeconds: 30\n"), 0644))
+	runner.reloadConfig(configPath)
+	r.Equal(30, runner.cfg.RunnerConfig.LivenessCheckIntervalSeconds)
+	r.Equal(cfg.Registry.ContainerRegistry, runner.cfg.Registry.ContainerRegistry)
+}
+
+func TestReloadConfig_ExternalSupervisor(t *testing.T) {
+	r := require.New(t)
+
+	var cfg config.Config
+	r.NoError(defaults.Set(&cfg))
+	cfg.FortaDir = t.TempDir()
+	cfg.Supervisor.External = true
+	cfg.Supervisor.HealthURL = "http://localhost:8090/health"
+	cfg.ApplyEnvDefaults()
+	runner := &Runner{
+		ctx:          context.Background(),
+		cfg:          cfg,
+		imgStore:     &testImageStore{}, // panics if the release channel is switched
+		updateChecks: make(chan *updateCheck),
+	}
+
+	// no liveness ticker and no updater to restart with the external supervisor
+	configPath := path.Join(cfg.FortaDir, config.DefaultConfigFileName)
+	r.NoError(os.WriteFile(configPath, []byte(
+		"supervisor:\n  external: true\n  healthUrl: http://localhost:8090/health\n"+
+			"runner:\n  livenessCheckIntervalSeconds: 30\nautoUpdate:\n  channel: beta\n",
+	), 0644))
+	runner.reloadConfig(configPath)
+	r.Equal(30, runner.cfg.RunnerConfig.LivenessCheckIntervalSeconds)
+	r.Equal("beta", runner.cfg.AutoUpdate.Channel)
+}
+
+func TestReloadConfig_ContainerConfig(t *testing.T) {
+	r := require.New(t)
+
+	var cfg config.Config
+	r.NoError(defaults.Set(&cfg))
+	cfg.FortaDir = t.TempDir()
+	cfg.ApplyEnvDefaults()
+	runner := &Runner{
+		ctx:            context.Background(),
+		cfg:            cfg,
+		livenessTicker: time.NewTicker(time.Hour),
+	}
+	defer runner.livenessTicker.Stop()
+
+	configPath := path.Join(cfg.FortaDir, config.DefaultConfigFileName)
+	r.NoError(os.WriteFile(configPath, []byte("log:\n  format: json\n"), 0644))
+
+	// the container configs read the reloaded values safely while reloading
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		runner.reloadConfig(configPath)
+	}()
+	runner.updaterContainerConfig("updater1", store.ImageRefs{})
+	runner.supervisorContainerConfig("supervisor1", store.ImageRefs{})
+	<-done
+
+	r.Equal("json", runner.updaterContainerConfig("updater1", store.ImageRefs{}).Env[config.EnvLogFormat])
+	r.Equal("json", runner.supervisorContainerConfig("supervisor1", store.ImageRefs{}).Env[config.EnvLogFormat])
+}
@@ -0,0 +1,93 @@
+package runner
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"strconv"
+
+	"github.com/forta-network/forta-core-go/clients/health"
+	"github.com/forta-network/forta-node/config"
+	"github.com/forta-network/forta-node/healthutils"
+	"github.com/gorilla/mux"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	log "github.com/sirupsen/logrus"
+)
+
+// updatesState is the response of the update control endpoints.
+type updatesState struct {
+	Paused bool `json:"paused"`
+}
+
+func (runner *Runner) controlRouter() http.Handler {
+	router := mux.NewRouter()
+	// the telemetry is protected the same way as on the health server
+	authCfg := runner.cfg.TelemetryConfig.Auth
+	router.Handle("/health", healthutils.AuthHandler(authCfg, http.HandlerFunc(runner.handleNodeHealth))).Methods(http.MethodGet)
+	router.HandleFunc("/updates", runner.handleUpdatesState).Methods(http.MethodGet)
+	router.Handle(healthutils.MetricsPath, healthutils.AuthHandler(authCfg, promhttp.Handler())).Methods(http.MethodGet)
+	runner.adminRouter(router)
+	return router
+}
+
+// startControlServer starts the control API. It listens only on the loopback interface
+// so that the node can be controlled only from the same host. The admin endpoints also require
+// the token from the Forta dir.
+func (runner *Runner) startControlServer() error {
+	port := runner.cfg.RunnerConfig.ControlPort
+	if len(port) == 0 {
+		return nil
+	}
+	lis, err := net.Listen("tcp", fmt.Sprintf("127.0.0.1:%s", port))
+	if err != nil {
+		return fmt.Errorf("failed to listen for the control api: %v", err)
+	}
+	runner.adminToken, err = config.EnsureAdminToken(runner.cfg.FortaDir)
+	if err != nil {
+		log.WithError(err).Warn("failed to load the admin token - admin api is disabled")
+	}
+	runner.controlServer = &http.Server{Handler: runner.controlRouter()}
+	go func() {
+		if err := runner.controlServer.Serve(lis); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			log.WithError(err).Error("control api server failed")
+		}
+	}()
+	return nil
+}
+
+// handleNodeHealth responds with the health of the runner and the child containers.
+func (runner *Runner) handleNodeHealth(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(runner.checkNodeHealth())
+}
+
+func (runner *Runner) handleUpdatesState(w http.ResponseWriter, r *http.Request) {
+	runner.writeUpdatesState(w)
+}
+
+func (runner *Runner) pauseUpdates() {
+	if !runner.updatesPaused.Swap(true) {
+		log.Info("paused the updates")
+	}
+}
+
+func (runner *Runner) resumeUpdates() {
+	if runner.updatesPaused.Swap(false) {
+		log.Info("resumed the updates")
+	}
+}
+
+func (runner *Runner) writeUpdatesState(w http.ResponseWriter) {
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(&updatesState{Paused: runner.updatesPaused.Load()})
+}
+
+func (runner *Runner) updatesPausedReport() *health.Report {
+	return &health.Report{
+		Name:    "forta.update.paused",
+		Status:  health.StatusInfo,
+		Details: strconv.FormatBool(runner.updatesPaused.Load()),
+	}
+}
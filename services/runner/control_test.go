@@ -0,0 +1,75 @@
+package runner
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	mock_clients "github.com/forta-network/forta-node/clients/mocks"
+	"github.com/forta-network/forta-node/healthutils"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestControl_UpdatesState(t *testing.T) {
+	r := require.New(t)
+
+	runner := &Runner{}
+	server := httptest.NewServer(runner.controlRouter())
+	defer server.Close()
+
+	getPaused := func() bool {
+		resp, err := http.Get(server.URL + "/updates")
+		r.NoError(err)
+		defer resp.Body.Close()
+		r.Equal(http.StatusOK, resp.StatusCode)
+		var state updatesState
+		r.NoError(json.NewDecoder(resp.Body).Decode(&state))
+		return state.Paused
+	}
+
+	r.False(getPaused())
+	runner.pauseUpdates()
+	r.True(getPaused())
+	r.Equal("true", runner.updatesPausedReport().Details)
+	runner.resumeUpdates()
+	r.False(getPaused())
+
+	// the updates are controlled only through the admin api
+	for _, path := range []string{"/updates/pause", "/updates/resume"} {
+		resp, err := http.Post(server.URL+path, "", nil)
+		r.NoError(err)
+		resp.Body.Close()
+		r.Equal(http.StatusNotFound, resp.StatusCode)
+	}
+	r.False(runner.updatesPaused.Load())
+}
+
+func TestControl_TelemetryAuth(t *testing.T) {
+	r := require.New(t)
+
+	globalClient := mock_clients.NewMockDockerClient(gomock.NewController(t))
+	globalClient.EXPECT().GetFortaServiceContainers(gomock.Any()).Return(nil, errors.New("docker is down"))
+	runner := &Runner{ctx: context.Background(), globalClient: globalClient}
+	runner.cfg.TelemetryConfig.Auth.BearerToken = "token1"
+	server := httptest.NewServer(runner.controlRouter())
+	defer server.Close()
+
+	for _, path := range []string{"/health", healthutils.MetricsPath} {
+		resp, err := http.Get(server.URL + path)
+		r.NoError(err)
+		resp.Body.Close()
+		r.Equal(http.StatusUnauthorized, resp.StatusCode, path)
+
+		req, err := http.NewRequest(http.MethodGet, server.URL+path, nil)
+		r.NoError(err)
+		req.Header.Set("Authorization", "Bearer token1")
+		resp, err = http.DefaultClient.Do(req)
+		r.NoError(err)
+		resp.Body.Close()
+		r.Equal(http.StatusOK, resp.StatusCode, path)
+	}
+}
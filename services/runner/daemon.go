@@ -0,0 +1,110 @@
+package runner
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/docker/docker/api/types"
+	"github.com/forta-network/forta-core-go/clients/health"
+	"github.com/forta-network/forta-node/clients"
+	log "github.com/sirupsen/logrus"
+)
+
+// daemonDownPeriod is a period in which the docker daemon was not reachable.
+type daemonDownPeriod struct {
+	Since time.Time
+	Until time.Time
+}
+
+func (period *daemonDownPeriod) String() string {
+	return fmt.Sprintf("%s - %s (%s)", period.Since.UTC().Format(time.RFC3339), period.Until.UTC().Format(time.RFC3339),
+		period.Until.Sub(period.Since).Round(time.Second))
+}
+
+// daemonStatus tracks the connectivity to the docker daemon.
+type daemonStatus struct {
+	downSince  time.Time
+	downErr    error
+	downCount  int
+	lastPeriod *daemonDownPeriod
+}
+
+// checkDaemon lists our containers to see if the docker daemon is reachable. When the daemon
+// comes back after being down, the stored container references are bound again to the containers
+// found by name, in case the daemon restart changed them. The container lock should be held by
+// the caller.
+func (runner *Runner) checkDaemon() (reachable bool) {
+	containers, err := runner.dockerClient.GetContainers(runner.ctx)
+	if err != nil {
+		if runner.daemon.downSince.IsZero() {
+			log.WithError(err).Error("docker daemon is not reachable - waiting for it to come back")
+			runner.daemon.downSince = time.Now()
+			runner.daemon.downCount++
+		}
+		runner.daemon.downErr = err
+		return false
+	}
+	if runner.daemon.downSince.IsZero() {
+		return true
+	}
+
+	period := &daemonDownPeriod{Since: runner.daemon.downSince, Until: time.Now()}
+	log.WithField("period", period.String()).Warn("docker daemon is reachable again - rebinding the containers")
+	runner.daemon.lastPeriod = period
+	runner.daemon.downSince = time.Time{}
+	runner.daemon.downErr = nil
+
+	runner.rebindContainer(containers, runner.supervisorContainer)
+	if !runner.cfg.UpdatesDisabled() {
+		runner.rebindContainer(containers, runner.updaterContainer)
+	}
+	return true
+}
+
+// rebindContainer updates the ID of the stored container if a container with the same name is
+// found. Otherwise, the stored reference is kept so that the container is recreated from the
+// stored config by the liveness check.
+func (runner *Runner) rebindContainer(containers []types.Container, container *clients.DockerContainer) {
+	if container == nil {
+		return
+	}
+	logger := log.WithField("name", container.Name).WithField("id", container.ID)
+	for _, found := range containers {
+		if len(found.Names) == 0 || found.Names[0][1:] != container.Name {
+			continue
+		}
+		if found.ID != container.ID {
+			logger.WithField("newId", found.ID).Info("rebinding to the container found by name")
+			container.ID = found.ID
+		}
+		return
+	}
+	logger.Warn("container did not survive the docker daemon restart - will recreate")
+}
+
+// daemonReports reports the docker daemon connectivity and the last down period.
+func (runner *Runner) daemonReports() (reports health.Reports) {
+	runner.containerMu.RLock()
+	defer runner.containerMu.RUnlock()
+
+	report := &health.Report{
+		Name:    "docker.daemon",
+		Status:  health.StatusOK,
+		Details: "reachable",
+	}
+	if !runner.daemon.downSince.IsZero() {
+		report.Status = health.StatusDown
+		report.Details = fmt.Sprintf("not reachable since %s: %v",
+			runner.daemon.downSince.UTC().Format(time.RFC3339), runner.daemon.downErr)
+	}
+	reports = append(reports, report)
+
+	if runner.daemon.lastPeriod != nil {
+		reports = append(reports, &health.Report{
+			Name:    "docker.daemon.last-down",
+			Status:  health.StatusInfo,
+			Details: fmt.Sprintf("%s, down count: %d", runner.daemon.lastPeriod, runner.daemon.downCount),
+		})
+	}
+	return
+}
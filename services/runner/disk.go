@@ -0,0 +1,93 @@
+package runner
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"syscall"
+
+	"github.com/forta-network/forta-core-go/clients/health"
+	log "github.com/sirupsen/logrus"
+)
+
+// ErrLowDiskSpace is returned when there is not enough free disk space to pull the images.
+var ErrLowDiskSpace = errors.New("low disk space")
+
+// diskUsage is the last known free space of a checked directory.
+type diskUsage struct {
+	Name string
+	Path string
+	Free uint64
+}
+
+// freeDiskBytes returns the space available to unprivileged users on the file system of the path.
+func freeDiskBytes(path string) (uint64, error) {
+	var stat syscall.Statfs_t
+	if err := syscall.Statfs(path, &stat); err != nil {
+		return 0, err
+	}
+	return stat.Bavail * uint64(stat.Bsize), nil
+}
+
+// diskPaths returns the directories which should have enough free space. The docker data root
+// is skipped if it is unknown or not reachable from the runner (e.g. remote docker daemon).
+func (runner *Runner) diskPaths(ctx context.Context) []*diskUsage {
+	paths := []*diskUsage{{Name: "forta-dir", Path: runner.cfg.FortaDir}}
+	rootDir, err := runner.dockerClient.GetDockerRootDir(ctx)
+	if err != nil {
+		log.WithError(err).Warn("failed to get the docker data root - skipping its disk check")
+		return paths
+	}
+	return append(paths, &diskUsage{Name: "docker-root", Path: rootDir})
+}
+
+// checkDiskSpace makes sure that the forta dir and the docker data root have at least the
+// configured amount of free space.
+func (runner *Runner) checkDiskSpace(ctx context.Context) error {
+	minFree := runner.cfg.RunnerConfig.MinFreeDiskBytes
+	if minFree <= 0 {
+		return nil
+	}
+	var usages []*diskUsage
+	for _, usage := range runner.diskPaths(ctx) {
+		free, err := runner.diskFree(usage.Path)
+		if err != nil {
+			log.WithError(err).WithField("path", usage.Path).Warn("failed to check the free disk space - skipping")
+			continue
+		}
+		usage.Free = free
+		usages = append(usages, usage)
+	}
+
+	runner.dependencyMu.Lock()
+	runner.diskUsages = usages
+	runner.dependencyMu.Unlock()
+
+	for _, usage := range usages {
+		if usage.Free < uint64(minFree) {
+			return fmt.Errorf("%w: %s (%s) has %d bytes free, need at least %d",
+				ErrLowDiskSpace, usage.Name, usage.Path, usage.Free, minFree)
+		}
+	}
+	return nil
+}
+
+func (runner *Runner) diskReports() (reports health.Reports) {
+	minFree := runner.cfg.RunnerConfig.MinFreeDiskBytes
+
+	runner.dependencyMu.RLock()
+	defer runner.dependencyMu.RUnlock()
+	for _, usage := range runner.diskUsages {
+		status := health.StatusOK
+		if usage.Free < uint64(minFree) {
+			status = health.StatusFailing
+		}
+		reports = append(reports, &health.Report{
+			Name:    fmt.Sprintf("forta.disk.%s.free-bytes", usage.Name),
+			Status:  status,
+			Details: strconv.FormatUint(usage.Free, 10),
+		})
+	}
+	return
+}
@@ -0,0 +1,93 @@
+package runner
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/forta-network/forta-core-go/clients/health"
+	mock_clients "github.com/forta-network/forta-node/clients/mocks"
+	"github.com/golang/mock/gomock"
+	log "github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/require"
+)
+
+func testDiskRunner(t *testing.T, free map[string]uint64) (*Runner, *mock_clients.MockDockerClient, *require.Assertions) {
+	r := require.New(t)
+	runner, dockerClient, _ := testStateRunner(t)
+	runner.cfg.RunnerConfig.MinFreeDiskBytes = 1000
+	runner.diskFree = func(path string) (uint64, error) {
+		if path == runner.cfg.FortaDir {
+			return free["forta-dir"], nil
+		}
+		r.Equal("/var/lib/docker", path)
+		return free["docker-root"], nil
+	}
+	dockerClient.EXPECT().GetDockerRootDir(gomock.Any()).Return("/var/lib/docker", nil).AnyTimes()
+	return runner, dockerClient, r
+}
+
+func TestFreeDiskBytes(t *testing.T) {
+	r := require.New(t)
+
+	free, err := freeDiskBytes(t.TempDir())
+	r.NoError(err)
+	r.NotZero(free)
+}
+
+func TestEnsureImage_LowDiskSpace(t *testing.T) {
+	runner, dockerClient, r := testDiskRunner(t, map[string]uint64{"forta-dir": 5000, "docker-root": 500})
+	logger := log.WithField("test", t.Name())
+
+	// no pull when the docker data root is low on space
+	dockerClient.EXPECT().HasLocalImage(gomock.Any(), "image1").Return(false)
+	_, err := runner.ensureImage(logger, "supervisor", "image1", "")
+	r.ErrorIs(err, ErrLowDiskSpace)
+
+	// the local images do not need more space
+	dockerClient.EXPECT().HasLocalImage(gomock.Any(), "image2").Return(true)
+	dockerClient.EXPECT().EnsureLocalImage(gomock.Any(), "supervisor", "image2").Return(nil)
+	ref, err := runner.ensureImage(logger, "supervisor", "image2", "")
+	r.NoError(err)
+	r.Equal("image2", ref)
+}
+
+func TestCheckDiskSpace(t *testing.T) {
+	runner, _, r := testDiskRunner(t, map[string]uint64{"forta-dir": 5000, "docker-root": 1000})
+
+	r.NoError(runner.checkDiskSpace(runner.ctx))
+	reports := runner.diskReports()
+	r.Len(reports, 2)
+	r.Equal("forta.disk.forta-dir.free-bytes", reports[0].Name)
+	r.Equal("5000", reports[0].Details)
+	r.Equal(health.StatusOK, reports[0].Status)
+	r.Equal("forta.disk.docker-root.free-bytes", reports[1].Name)
+	r.Equal(health.StatusOK, reports[1].Status)
+
+	runner.diskFree = func(path string) (uint64, error) { return 999, nil }
+	r.ErrorIs(runner.checkDiskSpace(runner.ctx), ErrLowDiskSpace)
+	for _, report := range runner.diskReports() {
+		r.Equal(health.StatusFailing, report.Status)
+	}
+}
+
+func TestCheckDiskSpace_Disabled(t *testing.T) {
+	runner, _, r := testDiskRunner(t, nil)
+	runner.cfg.RunnerConfig.MinFreeDiskBytes = 0
+
+	r.NoError(runner.checkDiskSpace(runner.ctx))
+	r.Empty(runner.diskReports())
+	for _, check := range runner.dependencyChecks() {
+		r.NotEqual("disk", check.Name)
+	}
+}
+
+func TestCheckDiskSpace_StatFails(t *testing.T) {
+	runner, _, r := testDiskRunner(t, nil)
+	runner.diskFree = func(path string) (uint64, error) {
+		return 0, errors.New("no such file or directory")
+	}
+
+	// the paths which could not be checked are skipped
+	r.NoError(runner.checkDiskSpace(runner.ctx))
+	r.Empty(runner.diskReports())
+}
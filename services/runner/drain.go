@@ -0,0 +1,73 @@
+package runner
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/forta-network/forta-node/config"
+	"github.com/forta-network/forta-node/healthutils"
+	log "github.com/sirupsen/logrus"
+)
+
+const (
+	drainPollInterval   = time.Second * 2
+	defaultDrainTimeout = time.Minute * 4
+)
+
+// ErrDrainTimeout is returned when the node is not drained in time.
+var ErrDrainTimeout = errors.New("timed out while draining")
+
+// Drain asks the supervisor to stop scanning the new blocks and to flush the alerts, and waits
+// until there is nothing left to publish. Unlike Stop, the containers are kept running so that
+// the node can be checked before it is stopped. The updates are paused while draining.
+func (runner *Runner) Drain(ctx context.Context) error {
+	runner.pauseUpdates()
+
+	token, err := config.EnsureAdminToken(runner.cfg.FortaDir)
+	if err != nil {
+		return fmt.Errorf("failed to get the admin token: %v", err)
+	}
+	port, err := runner.supervisorHealthPort()
+	if err != nil {
+		return fmt.Errorf("failed to reach the supervisor: %v", err)
+	}
+	state, err := runner.requestDrain(port, token)
+	if err != nil {
+		return fmt.Errorf("failed to request drain: %v", err)
+	}
+	log.Info("draining the node")
+
+	ticker := time.NewTicker(drainPollInterval)
+	defer ticker.Stop()
+	for {
+		runner.setDrainStatus(state)
+		if state.Drained {
+			log.Info("drained the node")
+			return nil
+		}
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("%w: %s", ErrDrainTimeout, state.Details)
+		case <-ticker.C:
+		}
+		// the supervisor can miss the first request while it is restarting
+		if !state.Draining {
+			state, err = runner.requestDrain(port, token)
+		} else {
+			state, err = runner.getDrainState(port, token)
+		}
+		if err != nil {
+			return fmt.Errorf("failed to get the drain state: %v", err)
+		}
+	}
+}
+
+func (runner *Runner) setDrainStatus(state *healthutils.DrainState) {
+	if state.Drained {
+		runner.drainStatus.Set("drained")
+		return
+	}
+	runner.drainStatus.Set(fmt.Sprintf("draining: %s", state.Details))
+}
@@ -0,0 +1,60 @@
+package runner
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/docker/docker/api/types"
+	"github.com/forta-network/forta-node/clients"
+	"github.com/forta-network/forta-node/healthutils"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+)
+
+func testDrainRunner(t *testing.T) (*Runner, *require.Assertions) {
+	r := require.New(t)
+	runner, dockerClient, _ := testStateRunner(t)
+	runner.supervisorContainer = &clients.DockerContainer{ID: "supervisor-id"}
+	dockerClient.EXPECT().GetContainerByID(gomock.Any(), "supervisor-id").Return(&types.Container{
+		State: "running",
+		Ports: []types.Port{{PrivatePort: 8090, PublicPort: 1001}},
+	}, nil)
+	return runner, r
+}
+
+func TestDrain(t *testing.T) {
+	runner, r := testDrainRunner(t)
+
+	var checks int
+	runner.requestDrain = func(port, token string) (*healthutils.DrainState, error) {
+		r.Equal("1001", port)
+		r.NotEmpty(token)
+		return &healthutils.DrainState{Draining: true, Details: "1 pending alerts, 0 queued batches"}, nil
+	}
+	runner.getDrainState = func(port, token string) (*healthutils.DrainState, error) {
+		checks++
+		return &healthutils.DrainState{Draining: true, Drained: true}, nil
+	}
+
+	r.NoError(runner.Drain(context.Background()))
+	r.Equal(1, checks)
+	r.True(runner.updatesPaused.Load())
+	r.Equal("drained", runner.drainStatus.GetReport("forta.drain").Details)
+}
+
+func TestDrain_Timeout(t *testing.T) {
+	runner, r := testDrainRunner(t)
+
+	runner.requestDrain = func(port, token string) (*healthutils.DrainState, error) {
+		return &healthutils.DrainState{Draining: true, Details: "0 pending alerts, 2 queued batches"}, nil
+	}
+	runner.getDrainState = runner.requestDrain
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Millisecond*100)
+	defer cancel()
+	err := runner.Drain(ctx)
+	r.ErrorIs(err, ErrDrainTimeout)
+	r.Contains(err.Error(), "2 queued batches")
+	r.Equal("draining: 0 pending alerts, 2 queued batches", runner.drainStatus.GetReport("forta.drain").Details)
+}
@@ -0,0 +1,111 @@
+package runner
+
+import (
+	"context"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// DryRunResult is the result of a single dry-run check.
+type DryRunResult struct {
+	Name     string        `json:"name"`
+	Required bool          `json:"required"`
+	OK       bool          `json:"ok"`
+	Error    string        `json:"error,omitempty"`
+	Latency  time.Duration `json:"latency"`
+}
+
+// DryRunReport contains the results of all dry-run checks.
+type DryRunReport struct {
+	OK      bool            `json:"ok"`
+	Results []*DryRunResult `json:"results"`
+}
+
+// DryRun checks if the node is ready to run without starting or removing any containers.
+// The report is not OK if any of the required checks fail.
+func (runner *Runner) DryRun() *DryRunReport {
+	checks := runner.dependencyChecks()
+	checks = append(checks,
+		&dependencyCheck{
+			Name: "ports",
+			// busy ports are replaced at start-up if auto-assignment is enabled
+			Required: !runner.cfg.Ports.AutoAssign,
+			Check: func(ctx context.Context) error {
+				return runner.cfg.CheckPorts()
+			},
+		},
+		&dependencyCheck{
+			Name:     "keystore",
+			Required: true,
+			Check: func(ctx context.Context) error {
+				return runner.checkScannerKey()
+			},
+		},
+		&dependencyCheck{
+			Name:     "supervisor-image",
+			Required: true,
+			Check: func(ctx context.Context) error {
+				return runner.checkEmbeddedImage("supervisor")
+			},
+		},
+	)
+	if batchSigningCheckEnabled(runner.cfg) {
+		checks = append(checks, &dependencyCheck{
+			Name:     "signing-key",
+			Required: true,
+			Check:    runner.checkBatchSigningKey,
+		})
+	}
+	if runner.scannerRegistryCheckEnabled() {
+		checks = append(checks, &dependencyCheck{
+			Name:     "registry",
+			Required: true,
+			Check:    runner.checkScannerRegistered,
+		})
+	}
+	if !runner.cfg.UpdatesDisabled() {
+		checks = append(checks, &dependencyCheck{
+			Name:     "updater-image",
+			Required: true,
+			Check: func(ctx context.Context) error {
+				return runner.checkEmbeddedImage("updater")
+			},
+		})
+	}
+
+	report := &DryRunReport{OK: true}
+	for _, check := range checks {
+		ctx, cancel := context.WithTimeout(runner.ctx, dependencyCheckTimeout)
+		start := time.Now()
+		err := check.Check(ctx)
+		cancel()
+		result := &DryRunResult{
+			Name:     check.Name,
+			Required: check.Required,
+			OK:       err == nil,
+			Latency:  time.Since(start),
+		}
+		if err != nil {
+			result.Error = err.Error()
+			if check.Required {
+				report.OK = false
+			}
+		}
+		report.Results = append(report.Results, result)
+	}
+	return report
+}
+
+// checkEmbeddedImage makes sure that the embedded image can be pulled and verified.
+func (runner *Runner) checkEmbeddedImage(name string) error {
+	refs := runner.imgStore.EmbeddedImageRefs()
+	imageRef := refs.Supervisor
+	if name == "updater" {
+		imageRef = refs.Updater
+	}
+	_, err := runner.ensureImage(
+		log.WithField("dryRun", true), name, imageRef, manifestImageRef(refs.ReleaseInfo, name),
+	)
+	return err
+}
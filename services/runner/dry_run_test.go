@@ -0,0 +1,152 @@
+package runner
+
+import (
+	"net"
+	"path"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/accounts/keystore"
+	"github.com/forta-network/forta-node/config"
+	"github.com/forta-network/forta-node/store"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+)
+
+func testDryRunRunner(t *testing.T) *Runner {
+	runner, dockerClient, globalClient := testStateRunner(t)
+	runner.imgStore = &testImageStore{embedded: store.ImageRefs{Supervisor: "supervisor1", Updater: "updater1"}}
+
+	rpcServer := testRPCServer()
+	t.Cleanup(rpcServer.Close)
+	runner.cfg.ChainID = 1
+	runner.cfg.Scan.JsonRpc.Url = rpcServer.URL
+	runner.cfg.Publish.SkipPublish = true
+	runner.cfg.Registry.IPFS.GatewayURL = rpcServer.URL
+	runner.cfg.KeyDirPath = path.Join(runner.cfg.FortaDir, config.DefaultKeysDirName)
+	runner.cfg.Passphrase = "passphrase1"
+	runner.cfg.Health.BindAddr = "127.0.0.1:0"
+	runner.cfg.RunnerConfig.UpdaterPort = "0"
+	_, err := keystore.StoreKey(runner.cfg.KeyDirPath, runner.cfg.Passphrase, keystore.LightScryptN, keystore.LightScryptP)
+	require.NoError(t, err)
+
+	dockerClient.EXPECT().GetContainers(gomock.Any()).Return(nil, nil)
+	dockerClient.EXPECT().EnsureLocalImage(gomock.Any(), "supervisor", "supervisor1").Return(nil)
+	dockerClient.EXPECT().EnsureLocalImage(gomock.Any(), "updater", "updater1").Return(nil)
+
+	// no containers may be started or removed
+	dockerClient.EXPECT().StartContainer(gomock.Any(), gomock.Any()).Times(0)
+	globalClient.EXPECT().StartContainer(gomock.Any(), gomock.Any()).Times(0)
+	globalClient.EXPECT().Nuke(gomock.Any()).Times(0)
+	dockerClient.EXPECT().Nuke(gomock.Any()).Times(0)
+	return runner
+}
+
+func dryRunResults(report *DryRunReport) map[string]*DryRunResult {
+	results := make(map[string]*DryRunResult)
+	for _, result := range report.Results {
+		results[result.Name] = result
+	}
+	return results
+}
+
+func TestDryRun(t *testing.T) {
+	r := require.New(t)
+
+	runner := testDryRunRunner(t)
+	report := runner.DryRun()
+	r.True(report.OK)
+	results := dryRunResults(report)
+	for _, name := range []string{"docker", "scan-api", "ipfs", "forta-dir", "ports", "keystore", "supervisor-image", "updater-image"} {
+		r.Contains(results, name)
+		r.True(results[name].OK, name)
+	}
+}
+
+func TestDryRun_WrongPassphrase(t *testing.T) {
+	r := require.New(t)
+
+	runner := testDryRunRunner(t)
+	runner.cfg.Passphrase = "wrong"
+	report := runner.DryRun()
+	r.False(report.OK)
+	results := dryRunResults(report)
+	r.False(results["keystore"].OK)
+	r.NotEmpty(results["keystore"].Error)
+	r.True(results["docker"].OK)
+}
+
+func TestDryRun_PortInUse(t *testing.T) {
+	r := require.New(t)
+
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	r.NoError(err)
+	defer lis.Close()
+	_, port, _ := net.SplitHostPort(lis.Addr().String())
+
+	runner := testDryRunRunner(t)
+	runner.cfg.Health.BindAddr = lis.Addr().String()
+	report := runner.DryRun()
+	r.False(report.OK)
+	results := dryRunResults(report)
+	r.False(results["ports"].OK)
+	r.Contains(results["ports"].Error, port)
+
+	// not required if the port can be replaced
+	runner = testDryRunRunner(t)
+	runner.cfg.Health.BindAddr = lis.Addr().String()
+	runner.cfg.Ports.AutoAssign = true
+	report = runner.DryRun()
+	r.True(report.OK)
+	r.False(dryRunResults(report)["ports"].OK)
+}
+
+func TestDryRun_SigningKey(t *testing.T) {
+	r := require.New(t)
+
+	runner := testDryRunRunner(t)
+	runner.cfg.Publish.SkipPublish = false
+	runner.cfg.Publish.APIURL = runner.cfg.Scan.JsonRpc.Url
+	report := runner.DryRun()
+	r.True(report.OK)
+	r.True(dryRunResults(report)["signing-key"].OK)
+
+	runner = testDryRunRunner(t)
+	runner.cfg.Publish.SkipPublish = false
+	runner.cfg.Publish.APIURL = runner.cfg.Scan.JsonRpc.Url
+	runner.cfg.Passphrase = "wrong"
+	report = runner.DryRun()
+	r.False(report.OK)
+	results := dryRunResults(report)
+	r.False(results["signing-key"].OK)
+	r.NotEmpty(results["signing-key"].Error)
+
+	// not checked if the batches are not published
+	runner = testDryRunRunner(t)
+	r.NotContains(dryRunResults(runner.DryRun()), "signing-key")
+}
+
+func TestDryRun_Registry(t *testing.T) {
+	r := require.New(t)
+
+	runner := testDryRunRunner(t)
+	runner.scannerStatus = &testScannerStatusClient{status: &store.ScannerStatus{Registered: false}}
+	report := runner.DryRun()
+	r.False(report.OK)
+	results := dryRunResults(report)
+	r.False(results["registry"].OK)
+	r.Contains(results["registry"].Error, ErrScannerNotRegistered.Error())
+
+	// a low stake does not prevent the start
+	runner = testDryRunRunner(t)
+	runner.scannerStatus = &testScannerStatusClient{status: &store.ScannerStatus{Registered: true, StakeBelowMinimum: true}}
+	report = runner.DryRun()
+	r.True(report.OK)
+	r.True(dryRunResults(report)["registry"].OK)
+
+	// disabled with --no-check
+	runner = testDryRunRunner(t)
+	runner.scannerStatus = &testScannerStatusClient{status: &store.ScannerStatus{Registered: false}}
+	report = runner.WithoutRegistryCheck(true).DryRun()
+	r.True(report.OK)
+	r.NotContains(dryRunResults(report), "registry")
+}
@@ -0,0 +1,39 @@
+package runner
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"syscall"
+)
+
+// checkFortaDir makes sure that the forta dir exists and the runner and the containers can write to it.
+// The owner of the dir is checked too if an owner uid is expected.
+func checkFortaDir(dir string, expectedUID *int) error {
+	info, err := os.Stat(dir)
+	if errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("forta dir %s does not exist - please run 'forta init' first", dir)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to check the forta dir %s: %v", dir, err)
+	}
+	if !info.IsDir() {
+		return fmt.Errorf("forta dir %s is not a directory", dir)
+	}
+	if stat, ok := info.Sys().(*syscall.Stat_t); ok && expectedUID != nil && int(stat.Uid) != *expectedUID {
+		return fmt.Errorf(
+			"forta dir %s is owned by uid %d but expected uid %d - please fix it with 'sudo chown -R %d %s'",
+			dir, stat.Uid, *expectedUID, *expectedUID, dir,
+		)
+	}
+	f, err := os.CreateTemp(dir, ".write-check-*")
+	if err != nil {
+		uid := os.Getuid()
+		return fmt.Errorf(
+			"forta dir %s is not writable by uid %d (%v) - please fix it with 'sudo chown -R %d %s'",
+			dir, uid, err, uid, dir,
+		)
+	}
+	f.Close()
+	return os.Remove(f.Name())
+}
@@ -0,0 +1,48 @@
+package runner
+
+import (
+	"os"
+	"path"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestCheckFortaDir(t *testing.T) {
+	r := require.New(t)
+
+	dir := t.TempDir()
+	r.NoError(checkFortaDir(dir, nil))
+	uid := os.Getuid()
+	r.NoError(checkFortaDir(dir, &uid))
+	entries, err := os.ReadDir(dir)
+	r.NoError(err)
+	r.Empty(entries)
+
+	otherUID := uid + 1
+	err = checkFortaDir(dir, &otherUID)
+	r.Error(err)
+	r.Contains(err.Error(), "chown")
+
+	err = checkFortaDir(path.Join(dir, "missing"), nil)
+	r.Error(err)
+	r.Contains(err.Error(), "does not exist")
+
+	filePath := path.Join(dir, "file")
+	r.NoError(os.WriteFile(filePath, []byte{}, 0644))
+	r.Error(checkFortaDir(filePath, nil))
+}
+
+func TestCheckFortaDir_NotWritable(t *testing.T) {
+	if os.Getuid() == 0 {
+		t.Skip("root can write to any dir")
+	}
+	r := require.New(t)
+
+	dir := t.TempDir()
+	r.NoError(os.Chmod(dir, 0555))
+	defer os.Chmod(dir, 0755)
+	err := checkFortaDir(dir, nil)
+	r.Error(err)
+	r.Contains(err.Error(), "not writable")
+}
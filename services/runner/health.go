@@ -2,74 +2,298 @@ package runner
 
 import (
 	"fmt"
+	"sort"
 	"strconv"
+	"strings"
+	"sync"
+	"time"
 
+	"github.com/docker/docker/api/types"
 	"github.com/forta-network/forta-core-go/clients/health"
 	"github.com/forta-network/forta-node/config"
 )
 
+const childHealthTimeout = time.Millisecond * 500
+
+// containerHealth contains the health of a child container.
+type containerHealth struct {
+	Name    string         `json:"name"`
+	Status  health.Status  `json:"status"`
+	State   string         `json:"state"`
+	Image   string         `json:"image,omitempty"`
+	Reports health.Reports `json:"reports"`
+}
+
+// nodeHealth is the health of the node as a tree of containers.
+type nodeHealth struct {
+	Status     health.Status               `json:"status"`
+	Details    string                      `json:"details"`
+	Reports    health.Reports              `json:"reports"`
+	Containers map[string]*containerHealth `json:"containers"`
+	// PendingUpdate is the detected release which is not applied yet.
+	PendingUpdate *pendingUpdate `json:"pendingUpdate,omitempty"`
+}
+
 func (runner *Runner) checkHealth() (allReports health.Reports) {
+	node := runner.checkNodeHealth()
+	allReports = append(allReports, node.Reports...)
+	for _, name := range node.containerNames() {
+		container := node.Containers[name]
+		reportName := fmt.Sprintf("forta.container.%s", name)
+		allReports = append(allReports, &health.Report{
+			Name:    reportName,
+			Status:  container.Status,
+			Details: container.State,
+		})
+		if len(container.Image) > 0 {
+			allReports = append(allReports, &health.Report{
+				Name:    fmt.Sprintf("%s.image", reportName),
+				Status:  health.StatusInfo,
+				Details: container.Image,
+			})
+		}
+		for _, report := range container.Reports {
+			allReports = append(allReports, &health.Report{
+				Name:    fmt.Sprintf("%s.%s", reportName, report.Name),
+				Status:  report.Status,
+				Details: report.Details,
+			})
+		}
+	}
+	if node.Containers != nil {
+		allReports = append(allReports, &health.Report{
+			Name:    "forta.node",
+			Status:  node.Status,
+			Details: node.Details,
+		})
+	}
+	return
+}
+
+// checkNodeHealth checks the runner and all child containers concurrently. The node is
+// degraded if any of the containers is not healthy.
+func (runner *Runner) checkNodeHealth() *nodeHealth {
 	containers, err := runner.globalClient.GetFortaServiceContainers(runner.ctx)
 	if err != nil {
-		return health.Reports{
-			{
-				Name:    "docker",
-				Status:  health.StatusDown,
-				Details: err.Error(),
-			},
+		return &nodeHealth{
+			Status:  health.StatusDown,
+			Details: err.Error(),
+			Reports: append(health.Reports{
+				{
+					Name:    "docker",
+					Status:  health.StatusDown,
+					Details: err.Error(),
+				},
+			}, append(runner.daemonReports(), runner.dependencyReports()...)...),
 		}
 	}
 
-	allReports = append(allReports, &health.Report{
+	node := &nodeHealth{
+		Status:     health.StatusOK,
+		Containers: make(map[string]*containerHealth),
+	}
+	node.Reports = append(node.Reports, &health.Report{
 		Name:    "forta.version",
 		Status:  health.StatusInfo,
 		Details: config.GetBuildReleaseInfo().Manifest.Release.Version,
 	})
+	node.Reports = append(node.Reports, runner.updatesPausedReport())
+	if runner.cfg.Offline {
+		node.Reports = append(node.Reports, &health.Report{
+			Name:    "forta.offline",
+			Status:  health.StatusInfo,
+			Details: "offline mode: the updater and the external endpoints are disabled",
+		})
+	}
+	node.Reports = append(node.Reports, runner.releaseChannelReports()...)
+	if imgStore, ok := runner.imgStore.(health.Reporter); ok {
+		node.Reports = append(node.Reports, imgStore.Health()...)
+	}
+	if deferred := runner.deferredUpdate.GetReport("forta.update.deferred"); len(deferred.Details) > 0 {
+		node.Reports = append(node.Reports, deferred)
+	}
+	if pending := runner.pendingUpdate.GetReport("forta.update.pending"); len(pending.Details) > 0 {
+		node.Reports = append(node.Reports, pending)
+	}
+	if drain := runner.drainStatus.GetReport("forta.drain"); len(drain.Details) > 0 {
+		node.Reports = append(node.Reports, drain)
+	}
+	node.PendingUpdate = runner.heldUpdate.get()
+	node.Reports = append(node.Reports, runner.heldUpdate.reports()...)
+	node.Reports = append(node.Reports, runner.validationReports()...)
+	node.Reports = append(node.Reports, runner.adminReports()...)
+	node.Reports = append(node.Reports, runner.daemonReports()...)
+	node.Reports = append(node.Reports, runner.dependencyReports()...)
+	node.Reports = append(node.Reports, runner.registrationReports()...)
+	node.Reports = append(node.Reports, runner.blockGapReports()...)
+	node.Reports = append(node.Reports, runner.diskReports()...)
+	node.Reports = append(node.Reports, runner.imagePruneReports()...)
+	node.Reports = append(node.Reports, runner.imageSourceReports()...)
+	node.Reports = append(node.Reports, portReports(runner.cfg.PortMappings)...)
 
+	var wg sync.WaitGroup
 	for _, container := range containers {
-		name := fmt.Sprintf("forta.container.%s", container.Names[0][1:])
+		name := container.Names[0][1:]
+		child := &containerHealth{
+			Name:   name,
+			Status: health.StatusOK,
+			State:  container.State,
+			Image:  imageDigest(container),
+		}
+		node.Containers[name] = child
 
 		if container.State != "running" {
-			allReports = append(allReports, &health.Report{
-				Name:    name,
-				Status:  health.StatusDown,
-				Details: container.State,
-			})
+			child.Status = health.StatusDown
 			continue
 		}
 
-		allReports = append(allReports, &health.Report{
-			Name:    name,
-			Status:  health.StatusOK,
-			Details: container.State,
-		})
-
 		// no further checks if nats
-		if container.Names[0][1:] == config.DockerNatsContainerName {
+		if name == config.DockerNatsContainerName {
 			continue
 		}
 
-		var gotReports bool
-		for _, port := range container.Ports {
-			if strconv.Itoa(int(port.PrivatePort)) == config.DefaultHealthPort {
-				reports := runner.healthClient.CheckHealth(name, strconv.Itoa(int(port.PublicPort)))
-				for _, report := range reports {
-					report.Name = fmt.Sprintf("%s.%s", name, report.Name)
-				}
-				reports.ObfuscateDetails()
-				allReports = append(allReports, reports...)
-				gotReports = true
-				break
+		healthPort := containerHealthPort(container)
+		if len(healthPort) == 0 {
+			child.Reports = health.Reports{
+				{
+					Name:    "health-api",
+					Status:  health.StatusInfo,
+					Details: "no source found",
+				},
 			}
-		}
-		if gotReports {
 			continue
 		}
-		allReports = append(allReports, &health.Report{
-			Name:    name,
+
+		wg.Add(1)
+		go func(name, healthPort string) {
+			defer wg.Done()
+			child.Reports = runner.checkChildHealth(name, healthPort)
+		}(name, healthPort)
+	}
+	// the map is not written by the other goroutines
+	if runner.cfg.Supervisor.External {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			supervisor := runner.checkExternalSupervisorHealth()
+			node.Containers[supervisor.Name] = supervisor
+		}()
+	}
+	wg.Wait()
+
+	if updater, ok := node.Containers[config.DockerUpdaterContainerName]; ok {
+		node.Reports = append(node.Reports, updateCheckReports(updater.Reports, runner.cfg.AutoUpdate.CheckInterval(), time.Now())...)
+	}
+
+	var degraded []string
+	for _, name := range node.containerNames() {
+		child := node.Containers[name]
+		if child.Status == health.StatusOK && !reportsHealthy(child.Reports) {
+			child.Status = health.StatusFailing
+		}
+		if child.Status != health.StatusOK {
+			degraded = append(degraded, name)
+		}
+	}
+	if len(degraded) > 0 {
+		node.Status = health.StatusFailing
+		node.Details = fmt.Sprintf("degraded: %s", strings.Join(degraded, ", "))
+	}
+	return node
+}
+
+// checkExternalSupervisorHealth gets the reports of the supervisor which is managed outside of
+// the runner.
+func (runner *Runner) checkExternalSupervisorHealth() *containerHealth {
+	supervisor := &containerHealth{
+		Name:    config.DockerSupervisorContainerName,
+		Status:  health.StatusOK,
+		State:   "external",
+		Reports: runner.healthURLClient(runner.cfg.Supervisor.HealthURL),
+	}
+	if report, ok := supervisor.Reports.GetByName("health-api"); ok && len(supervisor.Reports) == 1 && report.Status == health.StatusDown {
+		supervisor.Status = health.StatusDown
+		supervisor.State = "unreachable"
+	}
+	return supervisor
+}
+
+// checkChildHealth gets the reports from the child container in a short time. If the child
+// does not respond in time or is unreachable, its health is reported as unknown.
+func (runner *Runner) checkChildHealth(name, port string) health.Reports {
+	reportsCh := make(chan health.Reports, 1)
+	go func() {
+		reportsCh <- runner.healthClient.CheckHealth(name, port)
+	}()
+
+	select {
+	case reports := <-reportsCh:
+		if report, ok := reports.GetByName("health-api"); ok && len(reports) == 1 && report.Status == health.StatusDown {
+			report.Status = health.StatusUnknown
+		}
+		reports.ObfuscateDetails()
+		return reports
+
+	case <-time.After(childHealthTimeout):
+		return health.Reports{
+			{
+				Name:    "health-api",
+				Status:  health.StatusUnknown,
+				Details: fmt.Sprintf("no response in %s", childHealthTimeout),
+			},
+		}
+	}
+}
+
+// portReports lists the effective host ports and the configured ports they replace.
+func portReports(mappings []*config.PortMapping) (reports health.Reports) {
+	for _, mapping := range mappings {
+		details := mapping.Effective
+		if mapping.Effective != mapping.Configured {
+			details = fmt.Sprintf("%s (auto-assigned, configured %s)", mapping.Effective, mapping.Configured)
+		}
+		reports = append(reports, &health.Report{
+			Name:    fmt.Sprintf("forta.port.%s", mapping.Name),
 			Status:  health.StatusInfo,
-			Details: "no source found",
+			Details: details,
 		})
 	}
 	return
 }
+
+// containerHealthPort returns the host port of the container health API if it is published.
+func containerHealthPort(container types.Container) string {
+	for _, port := range container.Ports {
+		if strconv.Itoa(int(port.PrivatePort)) == config.DefaultHealthPort {
+			return strconv.Itoa(int(port.PublicPort))
+		}
+	}
+	return ""
+}
+
+// imageDigest returns the digest of the container image from the image reference or the image ID.
+func imageDigest(container types.Container) string {
+	if i := strings.Index(container.Image, "@sha256:"); i >= 0 {
+		return container.Image[i+1:]
+	}
+	return container.ImageID
+}
+
+// reportsHealthy tells if none of the reports is failing, down or unknown.
+func reportsHealthy(reports health.Reports) bool {
+	for _, report := range reports {
+		switch report.Status {
+		case health.StatusFailing, health.StatusDown, health.StatusUnknown:
+			return false
+		}
+	}
+	return true
+}
+
+func (node *nodeHealth) containerNames() (names []string) {
+	for name := range node.Containers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return
+}
@@ -0,0 +1,150 @@
+package runner
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/docker/docker/api/types"
+	"github.com/forta-network/forta-core-go/clients/health"
+	mock_clients "github.com/forta-network/forta-node/clients/mocks"
+	"github.com/forta-network/forta-node/config"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+)
+
+// testHealthClient responds with the reports by port and hangs for the unknown ports.
+type testHealthClient struct {
+	reports map[string]health.Reports
+}
+
+func (hc *testHealthClient) CheckHealth(name, port string) health.Reports {
+	reports, ok := hc.reports[port]
+	if !ok {
+		time.Sleep(time.Second * 5)
+	}
+	return reports
+}
+
+func (hc *testHealthClient) SendReports(src, dest, authToken string) error {
+	return nil
+}
+
+func testContainer(name, state string, healthPort uint16) types.Container {
+	container := types.Container{Names: []string{"/" + name}, State: state}
+	if healthPort > 0 {
+		container.Ports = []types.Port{{PrivatePort: 8090, PublicPort: healthPort}}
+	}
+	return container
+}
+
+func TestCheckNodeHealth(t *testing.T) {
+	r := require.New(t)
+
+	globalClient := mock_clients.NewMockDockerClient(gomock.NewController(t))
+	runner := &Runner{
+		ctx:          context.Background(),
+		globalClient: globalClient,
+		healthClient: &testHealthClient{
+			reports: map[string]health.Reports{
+				"1001": {{Name: "service.scanner", Status: health.StatusOK}},
+				"1002": {{Name: "service.proxy", Status: health.StatusFailing, Details: "some error"}},
+				"1003": {{Name: "health-api", Status: health.StatusDown, Details: "request failed"}},
+			},
+		},
+		dependencyResults: make(map[string]*dependencyCheckResult),
+	}
+
+	globalClient.EXPECT().GetFortaServiceContainers(gomock.Any()).Return([]types.Container{
+		testContainer(config.DockerScannerContainerName, "running", 1001),
+		testContainer(config.DockerJSONRPCProxyContainerName, "running", 1002),
+		testContainer(config.DockerInspectorContainerName, "running", 1003),
+		testContainer(config.DockerJWTProviderContainerName, "running", 1004),
+		testContainer(config.DockerStorageContainerName, "exited", 0),
+		testContainer(config.DockerNatsContainerName, "running", 0),
+	}, nil)
+
+	start := time.Now()
+	node := runner.checkNodeHealth()
+	r.Less(time.Since(start), time.Second)
+
+	r.Equal(health.StatusFailing, node.Status)
+	r.Len(node.Containers, 6)
+	r.Equal(health.StatusOK, node.Containers[config.DockerScannerContainerName].Status)
+	r.Equal(health.StatusOK, node.Containers[config.DockerNatsContainerName].Status)
+	r.Equal(health.StatusFailing, node.Containers[config.DockerJSONRPCProxyContainerName].Status)
+	r.Equal(health.StatusDown, node.Containers[config.DockerStorageContainerName].Status)
+
+	// unreachable and slow children are unknown
+	r.Equal(health.StatusUnknown, node.Containers[config.DockerInspectorContainerName].Reports[0].Status)
+	r.Equal(health.StatusUnknown, node.Containers[config.DockerJWTProviderContainerName].Reports[0].Status)
+	r.Equal(health.StatusFailing, node.Containers[config.DockerJWTProviderContainerName].Status)
+
+	r.Equal("degraded: forta-inspector, forta-json-rpc, forta-jwt-provider, forta-storage", node.Details)
+}
+
+func TestPortReports(t *testing.T) {
+	r := require.New(t)
+
+	reports := portReports([]*config.PortMapping{
+		{Name: config.PortNameHealth, Configured: "8090", Effective: "8090"},
+		{Name: config.PortNameUpdater, Configured: "8089", Effective: "41234"},
+	})
+	r.Len(reports, 2)
+	r.Equal("forta.port.health", reports[0].Name)
+	r.Equal("8090", reports[0].Details)
+	r.Equal("forta.port.updater", reports[1].Name)
+	r.Equal("41234 (auto-assigned, configured 8089)", reports[1].Details)
+}
+
+func TestImageDigest(t *testing.T) {
+	r := require.New(t)
+
+	r.Equal("sha256:"+testDigest1, imageDigest(types.Container{Image: testImageRef1, ImageID: "sha256:" + testDigest2}))
+	r.Equal("sha256:"+testDigest2, imageDigest(types.Container{Image: "nats:2.3.2", ImageID: "sha256:" + testDigest2}))
+	r.Empty(imageDigest(types.Container{}))
+}
+
+func TestCheckNodeHealth_ExternalSupervisor(t *testing.T) {
+	r := require.New(t)
+
+	const healthURL = "http://10.0.0.5:8090/health"
+	globalClient := mock_clients.NewMockDockerClient(gomock.NewController(t))
+	supervisorReports := health.Reports{{Name: "service.supervisor", Status: health.StatusOK}}
+	runner := &Runner{
+		ctx: context.Background(),
+		cfg: config.Config{
+			Supervisor: config.SupervisorConfig{External: true, HealthURL: healthURL},
+		},
+		globalClient: globalClient,
+		healthClient: &testHealthClient{
+			reports: map[string]health.Reports{
+				"1001": {{Name: "service.scanner", Status: health.StatusOK}},
+			},
+		},
+		healthURLClient: func(rawurl string) health.Reports {
+			r.Equal(healthURL, rawurl)
+			return supervisorReports
+		},
+		dependencyResults: make(map[string]*dependencyCheckResult),
+	}
+	globalClient.EXPECT().GetFortaServiceContainers(gomock.Any()).Return([]types.Container{
+		testContainer(config.DockerScannerContainerName, "running", 1001),
+	}, nil).Times(2)
+
+	node := runner.checkNodeHealth()
+	r.Equal(health.StatusOK, node.Status)
+	r.Len(node.Containers, 2)
+	supervisor := node.Containers[config.DockerSupervisorContainerName]
+	r.Equal("external", supervisor.State)
+	r.Equal(supervisorReports, supervisor.Reports)
+
+	// the supervisor is down if the health endpoint does not respond
+	supervisorReports = health.Reports{{Name: "health-api", Status: health.StatusDown, Details: "request failed"}}
+	node = runner.checkNodeHealth()
+	r.Equal(health.StatusFailing, node.Status)
+	supervisor = node.Containers[config.DockerSupervisorContainerName]
+	r.Equal(health.StatusDown, supervisor.Status)
+	r.Equal("unreachable", supervisor.State)
+	r.Equal("degraded: forta-supervisor", node.Details)
+}
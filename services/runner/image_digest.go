@@ -0,0 +1,65 @@
+package runner
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/forta-network/forta-core-go/release"
+	"github.com/forta-network/forta-core-go/utils"
+	log "github.com/sirupsen/logrus"
+)
+
+// Errors
+var (
+	// ErrImageDigestMismatch is returned when the local image does not have the digest from the release manifest.
+	ErrImageDigestMismatch = errors.New("image digest mismatch")
+	// ErrInvalidImageRef is returned when the strict image refs are enabled and the image ref is not a valid disco ref.
+	ErrInvalidImageRef = errors.New("invalid image ref")
+)
+
+// manifestImageRef returns the image ref that the release manifest claims for given service.
+func manifestImageRef(releaseInfo *release.ReleaseInfo, name string) string {
+	if releaseInfo == nil {
+		return ""
+	}
+	switch name {
+	case "updater":
+		return releaseInfo.Manifest.Release.Services.Updater
+	case "supervisor":
+		return releaseInfo.Manifest.Release.Services.Supervisor
+	default:
+		return ""
+	}
+}
+
+// verifyImageDigest checks if the local image has the digest from the release manifest. The digest
+// from the image ref is used if the manifest does not specify one.
+func (runner *Runner) verifyImageDigest(logger *log.Entry, imageRef, manifestRef string) error {
+	_, expectedDigest := utils.SplitImageRef(manifestRef)
+	if len(expectedDigest) == 0 {
+		_, expectedDigest = utils.SplitImageRef(imageRef)
+	}
+	if len(expectedDigest) == 0 {
+		logger.Warn("no expected digest - skipping image digest verification")
+		return nil
+	}
+
+	repoDigests, err := runner.dockerClient.GetImageDigests(runner.ctx, imageRef)
+	if err != nil {
+		return fmt.Errorf("failed to get local image digests: %v", err)
+	}
+	for _, repoDigest := range repoDigests {
+		if _, digest := utils.SplitImageRef(repoDigest); strings.EqualFold(digest, expectedDigest) {
+			return nil
+		}
+	}
+	return fmt.Errorf("%w: expected sha256:%s, found %v", ErrImageDigestMismatch, expectedDigest, repoDigests)
+}
+
+func (runner *Runner) repullImage(name, imageRef string) error {
+	if err := runner.dockerClient.RemoveImage(runner.ctx, imageRef); err != nil {
+		return fmt.Errorf("failed to remove the local image: %v", err)
+	}
+	return runner.dockerClient.EnsureLocalImage(runner.ctx, name, imageRef)
+}
@@ -0,0 +1,115 @@
+package runner
+
+import (
+	"context"
+	"testing"
+
+	"github.com/forta-network/forta-core-go/release"
+	mock_clients "github.com/forta-network/forta-node/clients/mocks"
+	"github.com/forta-network/forta-node/config"
+	"github.com/golang/mock/gomock"
+	log "github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	testDigest1   = "1111111111111111111111111111111111111111111111111111111111111111"
+	testDigest2   = "2222222222222222222222222222222222222222222222222222222222222222"
+	testImageRef1 = "disco.forta.network/bafybeielvnt5apaxbk6chthc4dc3p6vscpx3ai4uvti7gwh253j7facsxu@sha256:" + testDigest1
+	testImageRef2 = "disco.forta.network/bafybeielvnt5apaxbk6chthc4dc3p6vscpx3ai4uvti7gwh253j7facsxu@sha256:" + testDigest2
+)
+
+func testImageRunner(t *testing.T, development bool) (*Runner, *mock_clients.MockDockerClient) {
+	dockerClient := mock_clients.NewMockDockerClient(gomock.NewController(t))
+	return &Runner{
+		ctx: context.Background(),
+		cfg: config.Config{
+			Development: development,
+			Registry:    config.RegistryConfig{ContainerRegistry: "disco.forta.network"},
+		},
+		dockerClient: dockerClient,
+	}, dockerClient
+}
+
+func testReleaseInfo(updaterRef string) *release.ReleaseInfo {
+	var releaseInfo release.ReleaseInfo
+	releaseInfo.Manifest.Release.Services.Updater = updaterRef
+	return &releaseInfo
+}
+
+func TestEnsureImage_DigestMatch(t *testing.T) {
+	r := require.New(t)
+
+	runner, dockerClient := testImageRunner(t, false)
+	dockerClient.EXPECT().EnsureLocalImage(gomock.Any(), "updater", testImageRef1).Return(nil)
+	dockerClient.EXPECT().GetImageDigests(gomock.Any(), testImageRef1).Return([]string{testImageRef1}, nil)
+
+	ref, err := runner.ensureImage(log.NewEntry(log.StandardLogger()), "updater", testImageRef1,
+		manifestImageRef(testReleaseInfo(testImageRef1), "updater"))
+	r.NoError(err)
+	r.Equal(testImageRef1, ref)
+}
+
+func TestEnsureImage_DigestMismatch(t *testing.T) {
+	r := require.New(t)
+
+	runner, dockerClient := testImageRunner(t, false)
+	dockerClient.EXPECT().EnsureLocalImage(gomock.Any(), "updater", testImageRef1).Return(nil).Times(2)
+	dockerClient.EXPECT().GetImageDigests(gomock.Any(), testImageRef1).Return([]string{testImageRef1}, nil).Times(2)
+	dockerClient.EXPECT().RemoveImage(gomock.Any(), testImageRef1).Return(nil)
+
+	_, err := runner.ensureImage(log.NewEntry(log.StandardLogger()), "updater", testImageRef1,
+		manifestImageRef(testReleaseInfo(testImageRef2), "updater"))
+	r.ErrorIs(err, ErrImageDigestMismatch)
+}
+
+func TestEnsureImage_DigestMismatchRepull(t *testing.T) {
+	r := require.New(t)
+
+	// the local image is left over from an interrupted pull
+	runner, dockerClient := testImageRunner(t, false)
+	gomock.InOrder(
+		dockerClient.EXPECT().EnsureLocalImage(gomock.Any(), "updater", testImageRef1).Return(nil),
+		dockerClient.EXPECT().GetImageDigests(gomock.Any(), testImageRef1).Return([]string{testImageRef2}, nil),
+		dockerClient.EXPECT().RemoveImage(gomock.Any(), testImageRef1).Return(nil),
+		dockerClient.EXPECT().EnsureLocalImage(gomock.Any(), "updater", testImageRef1).Return(nil),
+		dockerClient.EXPECT().GetImageDigests(gomock.Any(), testImageRef1).Return([]string{testImageRef1}, nil),
+	)
+
+	ref, err := runner.ensureImage(log.NewEntry(log.StandardLogger()), "updater", testImageRef1,
+		manifestImageRef(testReleaseInfo(testImageRef1), "updater"))
+	r.NoError(err)
+	r.Equal(testImageRef1, ref)
+}
+
+func TestEnsureImage_DigestMismatchDevelopment(t *testing.T) {
+	r := require.New(t)
+
+	runner, dockerClient := testImageRunner(t, true)
+	dockerClient.EXPECT().EnsureLocalImage(gomock.Any(), "updater", testImageRef1).Return(nil).Times(2)
+	dockerClient.EXPECT().GetImageDigests(gomock.Any(), testImageRef1).Return([]string{testImageRef2}, nil).Times(2)
+	dockerClient.EXPECT().RemoveImage(gomock.Any(), testImageRef1).Return(nil)
+
+	ref, err := runner.ensureImage(log.NewEntry(log.StandardLogger()), "updater", testImageRef1, "")
+	r.NoError(err)
+	r.Equal(testImageRef1, ref)
+}
+
+func TestEnsureImage_StrictImageRefs(t *testing.T) {
+	r := require.New(t)
+
+	const typoRef = "disco.forta.network/bafybeielvnt5apaxbk6chthc4dc3p6vscpx3ai4uvti7gwh253j7facsxu@sha256:123"
+
+	// not strict: tried as it is
+	runner, dockerClient := testImageRunner(t, false)
+	dockerClient.EXPECT().EnsureLocalImage(gomock.Any(), "updater", typoRef).Return(nil)
+	ref, err := runner.ensureImage(log.NewEntry(log.StandardLogger()), "updater", typoRef, "")
+	r.NoError(err)
+	r.Equal(typoRef, ref)
+
+	// strict: never pulled
+	runner, _ = testImageRunner(t, false)
+	runner.cfg.Registry.StrictImageRefs = true
+	_, err = runner.ensureImage(log.NewEntry(log.StandardLogger()), "updater", typoRef, "")
+	r.ErrorIs(err, ErrInvalidImageRef)
+}
@@ -0,0 +1,178 @@
+package runner
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/docker/docker/api/types"
+	"github.com/forta-network/forta-core-go/clients/health"
+	"github.com/forta-network/forta-core-go/utils"
+	log "github.com/sirupsen/logrus"
+)
+
+// keepImagesPruned removes the unused images periodically so that the old releases and
+// the old agent versions do not fill the disk.
+func (runner *Runner) keepImagesPruned() {
+	interval := time.Duration(runner.cfg.RunnerConfig.ImagePruneIntervalSeconds) * time.Second
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			if err := runner.pruneImages(); err != nil {
+				log.WithError(err).Warn("failed to prune the images")
+				runner.imagePrune.Set(fmt.Sprintf("failed: %v", err))
+			}
+
+		case <-runner.ctx.Done():
+			return
+		}
+	}
+}
+
+// pruneImages removes the untagged node images from the container registry which are not used by
+// any of the containers on the host. The images of the current and the rollback releases and the
+// newest unused images are kept. The agent images are never pruned.
+func (runner *Runner) pruneImages() error {
+	// the previous images are unused but not designated for rollback yet during the update
+	if !runner.updateMu.TryLock() {
+		log.Info("update in progress - skipping the image prune")
+		return nil
+	}
+	defer runner.updateMu.Unlock()
+	runner.containerMu.RLock()
+	defer runner.containerMu.RUnlock()
+
+	images, err := runner.dockerClient.GetImages(runner.ctx)
+	if err != nil {
+		return fmt.Errorf("failed to list the images: %v", err)
+	}
+	containers, err := runner.globalClient.GetAllContainers(runner.ctx)
+	if err != nil {
+		return fmt.Errorf("failed to list the containers: %v", err)
+	}
+	inUse := make(map[string]bool)
+	for _, container := range containers {
+		inUse[container.ImageID] = true
+	}
+	nodeImages := make(map[string]bool)
+	if state := runner.loadState(); state != nil {
+		for _, digest := range state.NodeImages {
+			nodeImages[digest] = true
+		}
+	}
+
+	prunable := prunableImages(
+		images, runner.cfg.Registry.ContainerRegistry, nodeImages, inUse,
+		runner.protectedImageDigests(), runner.cfg.RunnerConfig.ImagePruneKeepVersions,
+	)
+	var (
+		removed   int
+		freedSize int64
+	)
+	removedDigests := make(map[string]bool)
+	defer runner.forgetNodeImages(removedDigests)
+	for _, image := range prunable {
+		logger := log.WithFields(log.Fields{
+			"image":       image.ID,
+			"repoDigests": strings.Join(image.RepoDigests, ","),
+		})
+		if err := runner.dockerClient.RemoveImage(runner.ctx, image.ID); err != nil {
+			logger.WithError(err).Warn("failed to remove the image")
+			continue
+		}
+		logger.Info("pruned image")
+		for _, repoDigest := range image.RepoDigests {
+			if _, digest := utils.SplitImageRef(repoDigest); len(digest) > 0 {
+				removedDigests[digest] = true
+			}
+		}
+		removed++
+		freedSize += image.Size
+	}
+	runner.imagePrune.Set(fmt.Sprintf("removed %d images (%d MiB)", removed, freedSize/1024/1024))
+	return nil
+}
+
+// protectedImageDigests returns the image digests of the running, the rollback and the embedded
+// releases. The container lock should be held by the caller.
+func (runner *Runner) protectedImageDigests() map[string]bool {
+	refs := []string{runner.currentSupervisorImg, runner.currentUpdaterImg}
+	runner.validationMu.RLock()
+	if runner.rollbackRefs != nil {
+		refs = append(refs, runner.rollbackRefs.Supervisor, runner.rollbackRefs.Updater)
+	}
+	runner.validationMu.RUnlock()
+	if runner.imgStore != nil {
+		embeddedRefs := runner.imgStore.EmbeddedImageRefs()
+		refs = append(refs, embeddedRefs.Supervisor, embeddedRefs.Updater)
+	}
+	return imageDigests(refs)
+}
+
+func imageDigests(refs []string) map[string]bool {
+	digests := make(map[string]bool)
+	for _, ref := range refs {
+		if _, digest := utils.SplitImageRef(ref); len(digest) > 0 {
+			digests[digest] = true
+		}
+	}
+	return digests
+}
+
+// prunableImages selects the untagged registry images of the node which are not in use or
+// protected, except the newest ones to keep.
+func prunableImages(images []types.ImageSummary, registry string, nodeImages, inUse, protected map[string]bool, keep int) (prunable []types.ImageSummary) {
+	var unused []types.ImageSummary
+	for _, image := range images {
+		if !isUntaggedRegistryImage(image, registry) || !hasDigest(image, nodeImages) || inUse[image.ID] || hasDigest(image, protected) {
+			continue
+		}
+		unused = append(unused, image)
+	}
+	sort.SliceStable(unused, func(i, j int) bool {
+		return unused[i].Created > unused[j].Created
+	})
+	if keep >= len(unused) {
+		return nil
+	}
+	return unused[keep:]
+}
+
+// isUntaggedRegistryImage tells if the image was pulled from the registry only by the digests.
+// The tagged images and the images from the other registries are never pruned.
+func isUntaggedRegistryImage(image types.ImageSummary, registry string) bool {
+	for _, tag := range image.RepoTags {
+		if tag != "<none>:<none>" {
+			return false
+		}
+	}
+	if len(registry) == 0 || len(image.RepoDigests) == 0 {
+		return false
+	}
+	for _, repoDigest := range image.RepoDigests {
+		if !strings.HasPrefix(repoDigest, registry+"/") {
+			return false
+		}
+	}
+	return true
+}
+
+func hasDigest(image types.ImageSummary, digests map[string]bool) bool {
+	for _, repoDigest := range image.RepoDigests {
+		parts := strings.Split(repoDigest, "@sha256:")
+		if len(parts) == 2 && digests[parts[1]] {
+			return true
+		}
+	}
+	return false
+}
+
+func (runner *Runner) imagePruneReports() (reports health.Reports) {
+	if prune := runner.imagePrune.GetReport("forta.images.prune"); len(prune.Details) > 0 {
+		reports = append(reports, prune)
+	}
+	return
+}
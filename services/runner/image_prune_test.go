@@ -0,0 +1,113 @@
+package runner
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/docker/docker/api/types"
+	mock_clients "github.com/forta-network/forta-node/clients/mocks"
+	"github.com/forta-network/forta-node/config"
+	"github.com/forta-network/forta-node/store"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+)
+
+const testPruneRegistry = "disco.forta.network"
+
+func testPruneDigest(c string) string {
+	return strings.Repeat(c, 64)
+}
+
+func testPruneImage(id, digest string, created int64) types.ImageSummary {
+	return types.ImageSummary{
+		ID:          id,
+		Created:     created,
+		RepoDigests: []string{testPruneRegistry + "/bafybei" + id + "@sha256:" + digest},
+	}
+}
+
+func TestPrunableImages(t *testing.T) {
+	r := require.New(t)
+
+	images := []types.ImageSummary{
+		testPruneImage("old1", testPruneDigest("1"), 1),
+		testPruneImage("old2", testPruneDigest("2"), 2),
+		testPruneImage("old3", testPruneDigest("3"), 3),
+		testPruneImage("running", testPruneDigest("4"), 4),
+		testPruneImage("rollback", testPruneDigest("5"), 0),
+		{ID: "tagged", RepoTags: []string{"nats:2.3.2"}, RepoDigests: []string{"nats@sha256:" + testPruneDigest("6")}},
+		{ID: "other-registry", RepoDigests: []string{"docker.io/library/foo@sha256:" + testPruneDigest("7")}},
+		testPruneImage("agent", testPruneDigest("8"), 8),
+	}
+	nodeImages := make(map[string]bool)
+	for _, c := range "1234567" {
+		nodeImages[testPruneDigest(string(c))] = true
+	}
+	inUse := map[string]bool{"running": true}
+	protected := map[string]bool{testPruneDigest("5"): true}
+
+	// the newest unused image is kept
+	var ids []string
+	for _, image := range prunableImages(images, testPruneRegistry, nodeImages, inUse, protected, 1) {
+		ids = append(ids, image.ID)
+	}
+	r.Equal([]string{"old2", "old1"}, ids)
+
+	r.Len(prunableImages(images, testPruneRegistry, nodeImages, inUse, protected, 0), 3)
+	r.Empty(prunableImages(images, testPruneRegistry, nodeImages, inUse, protected, 3))
+	r.Empty(prunableImages(images, "", nodeImages, inUse, protected, 0))
+	r.Empty(prunableImages(images, testPruneRegistry, nil, inUse, protected, 0))
+}
+
+func TestPruneImages(t *testing.T) {
+	r := require.New(t)
+	ctrl := gomock.NewController(t)
+	dockerClient := mock_clients.NewMockDockerClient(ctrl)
+	globalClient := mock_clients.NewMockDockerClient(ctrl)
+
+	runner := &Runner{
+		ctx:          context.Background(),
+		dockerClient: dockerClient,
+		globalClient: globalClient,
+		stateStore:   store.NewRunnerStateStore(t.TempDir()),
+	}
+	runner.cfg.Registry.ContainerRegistry = testPruneRegistry
+	runner.cfg.RunnerConfig.ImagePruneKeepVersions = 1
+
+	// the runner remembers the node images which it started
+	for _, c := range "dcfa" {
+		runner.currentSupervisorImg = testPruneRegistry + "/bafybeisupervisor@sha256:" + testPruneDigest(string(c))
+		runner.currentUpdaterImg = runner.currentSupervisorImg
+		runner.saveState()
+	}
+
+	dockerClient.EXPECT().GetImages(gomock.Any()).Return([]types.ImageSummary{
+		testPruneImage("supervisor", testPruneDigest("a"), 10),
+		testPruneImage("agent", testPruneDigest("b"), 9),
+		testPruneImage("previous", testPruneDigest("c"), 8),
+		testPruneImage("old", testPruneDigest("d"), 7),
+		testPruneImage("other-node", testPruneDigest("f"), 6),
+		testPruneImage("old-agent", testPruneDigest("e"), 5),
+	}, nil)
+	globalClient.EXPECT().GetAllContainers(gomock.Any()).Return([]types.Container{
+		{Names: []string{"/" + config.DockerAgentContainerNamePrefix + "0x123456"}, ImageID: "agent"},
+		// the container of another node on the same host
+		{Names: []string{"/other-node-supervisor"}, ImageID: "other-node"},
+	}, nil)
+	dockerClient.EXPECT().RemoveImage(gomock.Any(), "old").Return(nil)
+
+	// the images in use, the agent images and the newest unused image are kept
+	r.NoError(runner.pruneImages())
+	r.Equal("removed 1 images (0 MiB)", runner.imagePruneReports()[0].Details)
+
+	// the pruned image is forgotten
+	state, err := runner.stateStore.Get()
+	r.NoError(err)
+	r.Equal([]string{testPruneDigest("a"), testPruneDigest("c"), testPruneDigest("f")}, state.NodeImages)
+
+	// the images are not pruned during the update
+	runner.updateMu.Lock()
+	r.NoError(runner.pruneImages())
+	runner.updateMu.Unlock()
+}
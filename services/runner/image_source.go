@@ -0,0 +1,51 @@
+package runner
+
+import (
+	"fmt"
+
+	"github.com/forta-network/forta-core-go/clients/health"
+	"github.com/forta-network/forta-node/store"
+	log "github.com/sirupsen/logrus"
+)
+
+// checkImageSource checks the image against the trusted registries and counts the rejections.
+func (runner *Runner) checkImageSource(name, imageRef string) error {
+	err := runner.cfg.CheckImageSource(name, imageRef)
+	if err != nil {
+		runner.untrustedImages.Add(1)
+		runner.untrustedImage.Set(err.Error())
+	}
+	return err
+}
+
+// trustsImageSources checks the images of the release before the running containers are replaced
+// so that a release with an untrusted image is skipped.
+func (runner *Runner) trustsImageSources(refs *store.ImageRefs) bool {
+	for _, image := range []struct {
+		name string
+		ref  string
+	}{
+		{name: "updater", ref: refs.Updater},
+		{name: "supervisor", ref: refs.Supervisor},
+	} {
+		if err := runner.checkImageSource(image.name, runner.containerImageRef(image.ref)); err != nil {
+			log.WithError(err).WithField("release", describeRelease(refs)).Error("skipping the release with an untrusted image")
+			return false
+		}
+	}
+	return true
+}
+
+func (runner *Runner) imageSourceReports() (reports health.Reports) {
+	count := runner.untrustedImages.Load()
+	if count == 0 {
+		return
+	}
+	return health.Reports{
+		{
+			Name:    "forta.images.untrusted",
+			Status:  health.StatusFailing,
+			Details: fmt.Sprintf("rejected %d times, last: %s", count, runner.untrustedImage.GetReport("").Details),
+		},
+	}
+}
@@ -0,0 +1,56 @@
+package runner
+
+import (
+	"testing"
+
+	"github.com/forta-network/forta-node/config"
+	"github.com/forta-network/forta-node/store"
+	"github.com/golang/mock/gomock"
+	log "github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/require"
+)
+
+const testUntrustedRef = "registry.example.com/forta/updater@sha256:" + testDigest1
+
+func TestEnsureImage_UntrustedRegistry(t *testing.T) {
+	r := require.New(t)
+
+	runner, dockerClient := testImageRunner(t, false)
+	runner.cfg.Registry.AllowedRegistries = []string{"disco.forta.network"}
+	runner.cfg.Registry.RequireDigest = true
+
+	// allowed
+	dockerClient.EXPECT().EnsureLocalImage(gomock.Any(), "updater", testImageRef1).Return(nil)
+	dockerClient.EXPECT().GetImageDigests(gomock.Any(), testImageRef1).Return([]string{testImageRef1}, nil)
+	_, err := runner.ensureImage(log.NewEntry(log.StandardLogger()), "updater", testImageRef1, "")
+	r.NoError(err)
+	r.Empty(runner.imageSourceReports())
+
+	// rejected: never pulled
+	_, err = runner.ensureImage(log.NewEntry(log.StandardLogger()), "updater", testUntrustedRef, "")
+	r.ErrorIs(err, config.ErrImageRegistryNotAllowed)
+	reports := runner.imageSourceReports()
+	r.Len(reports, 1)
+	r.Contains(reports[0].Details, "rejected 1 times")
+	r.Contains(reports[0].Details, testUntrustedRef)
+
+	// the development mode uses the local images
+	runner, dockerClient = testImageRunner(t, true)
+	runner.cfg.Registry.AllowedRegistries = []string{"disco.forta.network"}
+	runner.cfg.Registry.RequireDigest = true
+	dockerClient.EXPECT().EnsureLocalImage(gomock.Any(), "updater", "forta-updater").Return(nil)
+	ref, err := runner.ensureImage(log.NewEntry(log.StandardLogger()), "updater", "forta-updater", "")
+	r.NoError(err)
+	r.Equal("forta-updater", ref)
+}
+
+func TestTrustsImageSources(t *testing.T) {
+	r := require.New(t)
+
+	runner, _ := testImageRunner(t, false)
+	runner.cfg.Registry.AllowedRegistries = []string{"disco.forta.network"}
+
+	r.True(runner.trustsImageSources(&store.ImageRefs{Updater: testImageRef1, Supervisor: testImageRef2}))
+	r.False(runner.trustsImageSources(&store.ImageRefs{Updater: testImageRef1, Supervisor: testUntrustedRef}))
+	r.Equal(int64(1), runner.untrustedImages.Load())
+}
@@ -0,0 +1,104 @@
+package runner
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/docker/docker/api/types"
+	"github.com/forta-network/forta-core-go/clients/health"
+	"github.com/forta-network/forta-node/clients"
+	mock_clients "github.com/forta-network/forta-node/clients/mocks"
+	"github.com/forta-network/forta-node/config"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+)
+
+func testKeepAliveRunner(t *testing.T) (*Runner, *mock_clients.MockDockerClient) {
+	dockerClient := mock_clients.NewMockDockerClient(gomock.NewController(t))
+	return &Runner{
+		ctx:          context.Background(),
+		dockerClient: dockerClient,
+		supervisorContainer: &clients.DockerContainer{
+			Name:   config.DockerSupervisorContainerName,
+			ID:     "supervisor1",
+			Config: clients.DockerContainerConfig{Name: config.DockerSupervisorContainerName},
+		},
+		updaterContainer: &clients.DockerContainer{
+			Name:   config.DockerUpdaterContainerName,
+			ID:     "updater1",
+			Config: clients.DockerContainerConfig{Name: config.DockerUpdaterContainerName},
+		},
+	}, dockerClient
+}
+
+func TestKeepContainersAlive_RecreateRemoved(t *testing.T) {
+	r := require.New(t)
+
+	runner, dockerClient := testKeepAliveRunner(t)
+	dockerClient.EXPECT().GetContainers(gomock.Any()).Return(nil, nil)
+	dockerClient.EXPECT().GetContainerByID(gomock.Any(), "supervisor1").
+		Return(nil, fmt.Errorf("%w with id 'supervisor1'", clients.ErrContainerNotFound))
+	dockerClient.EXPECT().StartContainer(gomock.Any(), runner.supervisorContainer.Config).
+		Return(&clients.DockerContainer{Name: config.DockerSupervisorContainerName, ID: "supervisor2"}, nil)
+	dockerClient.EXPECT().GetContainerByID(gomock.Any(), "updater1").
+		Return(&types.Container{ID: "updater1", State: "running"}, nil)
+
+	r.NoError(runner.doKeepContainersAlive())
+	r.Equal("supervisor2", runner.supervisorContainer.ID)
+	r.Equal("updater1", runner.updaterContainer.ID)
+}
+
+func TestKeepContainersAlive_TransientError(t *testing.T) {
+	r := require.New(t)
+
+	runner, dockerClient := testKeepAliveRunner(t)
+	dockerClient.EXPECT().GetContainers(gomock.Any()).Return(nil, nil)
+	dockerClient.EXPECT().GetContainerByID(gomock.Any(), "supervisor1").Return(nil, errors.New("docker is busy"))
+	dockerClient.EXPECT().GetContainerByID(gomock.Any(), "updater1").
+		Return(nil, fmt.Errorf("%w with id 'updater1'", clients.ErrContainerNotFound))
+	dockerClient.EXPECT().StartContainer(gomock.Any(), runner.updaterContainer.Config).
+		Return(&clients.DockerContainer{Name: config.DockerUpdaterContainerName, ID: "updater2"}, nil)
+
+	// the supervisor is not recreated but the updater is
+	r.NoError(runner.doKeepContainersAlive())
+	r.Equal("supervisor1", runner.supervisorContainer.ID)
+	r.Equal("updater2", runner.updaterContainer.ID)
+}
+
+func TestKeepContainersAlive_DaemonRestart(t *testing.T) {
+	r := require.New(t)
+
+	runner, dockerClient := testKeepAliveRunner(t)
+
+	// nothing is checked while the daemon is down
+	dockerClient.EXPECT().GetContainers(gomock.Any()).Return(nil, errors.New("cannot connect to the docker daemon")).Times(2)
+	r.NoError(runner.doKeepContainersAlive())
+	r.NoError(runner.doKeepContainersAlive())
+	reports := runner.daemonReports()
+	r.Len(reports, 1)
+	r.Equal(health.StatusDown, reports[0].Status)
+	r.Contains(reports[0].Details, "cannot connect")
+
+	// the supervisor is found with a new ID and the missing updater is recreated
+	dockerClient.EXPECT().GetContainers(gomock.Any()).Return(clients.DockerContainerList{
+		{ID: "supervisor2", Names: []string{"/" + config.DockerSupervisorContainerName}},
+	}, nil)
+	dockerClient.EXPECT().GetContainerByID(gomock.Any(), "supervisor2").
+		Return(&types.Container{ID: "supervisor2", State: "running"}, nil)
+	dockerClient.EXPECT().GetContainerByID(gomock.Any(), "updater1").
+		Return(nil, fmt.Errorf("%w with id 'updater1'", clients.ErrContainerNotFound))
+	dockerClient.EXPECT().StartContainer(gomock.Any(), runner.updaterContainer.Config).
+		Return(&clients.DockerContainer{Name: config.DockerUpdaterContainerName, ID: "updater2"}, nil)
+
+	r.NoError(runner.doKeepContainersAlive())
+	r.Equal("supervisor2", runner.supervisorContainer.ID)
+	r.Equal("updater2", runner.updaterContainer.ID)
+
+	reports = runner.daemonReports()
+	r.Len(reports, 2)
+	r.Equal(health.StatusOK, reports[0].Status)
+	r.Equal("docker.daemon.last-down", reports[1].Name)
+	r.Contains(reports[1].Details, "down count: 1")
+}
@@ -0,0 +1,99 @@
+package runner
+
+import (
+	"sync"
+	"time"
+
+	"github.com/forta-network/forta-core-go/clients/health"
+	"github.com/forta-network/forta-node/store"
+)
+
+// the reasons to hold a detected update
+const (
+	holdReasonPaused       = "updates are paused"
+	holdReasonTrackDelay   = "waiting for the track delay"
+	holdReasonUpdateWindow = "outside the update window"
+	holdReasonValidating   = "validating the previous update"
+)
+
+// pendingUpdate is a detected release which is held instead of being applied.
+type pendingUpdate struct {
+	Version    string    `json:"version"`
+	Commit     string    `json:"commit"`
+	Supervisor string    `json:"supervisor"`
+	Updater    string    `json:"updater"`
+	Reason     string    `json:"reason"`
+	Since      time.Time `json:"since"`
+}
+
+// heldUpdate keeps the pending update for the health checks.
+type heldUpdate struct {
+	update *pendingUpdate
+	mu     sync.RWMutex
+}
+
+// hold sets the release as held for the reason. The time is kept until the release
+// or the reason changes.
+func (held *heldUpdate) hold(refs *store.ImageRefs, reason string) {
+	held.mu.Lock()
+	defer held.mu.Unlock()
+	update := &pendingUpdate{
+		Supervisor: refs.Supervisor,
+		Updater:    refs.Updater,
+		Reason:     reason,
+		Since:      time.Now().UTC(),
+	}
+	if refs.ReleaseInfo != nil {
+		update.Version = refs.ReleaseInfo.Manifest.Release.Version
+		update.Commit = refs.ReleaseInfo.Manifest.Release.Commit
+	}
+	if prev := held.update; prev != nil && prev.Supervisor == update.Supervisor &&
+		prev.Updater == update.Updater && prev.Reason == update.Reason {
+		return
+	}
+	held.update = update
+}
+
+// release clears the pending update after it is applied.
+func (held *heldUpdate) release() {
+	held.mu.Lock()
+	defer held.mu.Unlock()
+	held.update = nil
+}
+
+// get returns a copy of the pending update or nil if no update is held.
+func (held *heldUpdate) get() *pendingUpdate {
+	held.mu.RLock()
+	defer held.mu.RUnlock()
+	if held.update == nil {
+		return nil
+	}
+	update := *held.update
+	return &update
+}
+
+func (held *heldUpdate) reports() health.Reports {
+	update := held.get()
+	if update == nil {
+		return nil
+	}
+	var reports health.Reports
+	for _, field := range []struct {
+		name  string
+		value string
+	}{
+		{"reason", update.Reason},
+		{"since", update.Since.Format(time.RFC3339)},
+		{"version", update.Version},
+		{"commit", update.Commit},
+		{"supervisor", update.Supervisor},
+		{"updater", update.Updater},
+	} {
+		reports = append(reports, &health.Report{
+			Name:    "forta.pendingUpdate." + field.name,
+			Status:  health.StatusInfo,
+			Details: field.value,
+		})
+	}
+	return reports
+}
@@ -0,0 +1,49 @@
+package runner
+
+import (
+	"testing"
+
+	"github.com/forta-network/forta-core-go/clients/health"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHeldUpdate(t *testing.T) {
+	r := require.New(t)
+
+	var held heldUpdate
+	r.Nil(held.get())
+	r.Empty(held.reports())
+
+	refs := testTrackedRefs("release1")
+	refs.ReleaseInfo.Manifest.Release.Version = "v0.1.0"
+	refs.ReleaseInfo.Manifest.Release.Commit = "abcdef"
+	held.hold(refs, holdReasonPaused)
+	update := held.get()
+	r.Equal(&pendingUpdate{
+		Version:    "v0.1.0",
+		Commit:     "abcdef",
+		Supervisor: "supervisor-release1",
+		Updater:    "updater-release1",
+		Reason:     holdReasonPaused,
+		Since:      update.Since,
+	}, update)
+
+	// the time is kept while the same release is held for the same reason
+	held.hold(refs, holdReasonPaused)
+	r.Equal(update.Since, held.get().Since)
+	held.hold(refs, holdReasonUpdateWindow)
+	r.Equal(holdReasonUpdateWindow, held.get().Reason)
+
+	reports := held.reports()
+	r.Len(reports, 6)
+	reason, ok := reports.NameContains("forta.pendingUpdate.reason")
+	r.True(ok)
+	r.Equal(health.StatusInfo, reason.Status)
+	r.Equal(holdReasonUpdateWindow, reason.Details)
+	commit, ok := reports.NameContains("forta.pendingUpdate.commit")
+	r.True(ok)
+	r.Equal("abcdef", commit.Details)
+
+	held.release()
+	r.Nil(held.get())
+}
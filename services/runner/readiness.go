@@ -0,0 +1,92 @@
+package runner
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+
+	"github.com/forta-network/forta-node/config"
+	"github.com/forta-network/forta-node/healthutils"
+)
+
+// containerStateError is returned when the container is not running.
+type containerStateError struct {
+	State string
+}
+
+func (err *containerStateError) Error() string {
+	return fmt.Sprintf("container is %s", err.State)
+}
+
+func (runner *Runner) readinessChecks() []healthutils.ReadinessCheck {
+	return []healthutils.ReadinessCheck{
+		{Name: "start-up", Check: runner.checkStartUpReady},
+		{Name: "supervisor", Check: runner.checkSupervisorReady},
+	}
+}
+
+func (runner *Runner) checkStartUpReady() error {
+	if !runner.startUpChecked.Load() {
+		return errors.New("start-up checks have not passed")
+	}
+	return nil
+}
+
+// checkSupervisorReady checks if the supervisor is running and reports ready. The supervisor
+// is ready when it manages all of the containers and the blocks are not lagging. The external
+// supervisor is ready when its health endpoint responds.
+func (runner *Runner) checkSupervisorReady() error {
+	if runner.cfg.Supervisor.External {
+		return runner.checkExternalSupervisorReady()
+	}
+	healthPort, err := runner.supervisorHealthPort()
+	if err != nil {
+		return err
+	}
+	return runner.checkReady(healthPort)
+}
+
+// checkExternalSupervisorReady checks if the health endpoint of the external supervisor responds.
+func (runner *Runner) checkExternalSupervisorReady() error {
+	reports := runner.healthURLClient(runner.cfg.Supervisor.HealthURL)
+	if report, ok := reports.GetByName("health-api"); ok && len(reports) == 1 {
+		return fmt.Errorf("external supervisor health check failed: %s", report.Details)
+	}
+	return nil
+}
+
+// supervisorHealthPort returns the health port of the running supervisor.
+func (runner *Runner) supervisorHealthPort() (string, error) {
+	runner.containerMu.RLock()
+	supervisorContainer := runner.supervisorContainer
+	runner.containerMu.RUnlock()
+	if supervisorContainer == nil {
+		return "", errors.New("not started")
+	}
+
+	container, err := runner.dockerClient.GetContainerByID(runner.ctx, supervisorContainer.ID)
+	if err != nil {
+		return "", fmt.Errorf("failed to get the container: %v", err)
+	}
+	if container.State != "running" {
+		return "", &containerStateError{State: container.State}
+	}
+
+	for _, port := range container.Ports {
+		if strconv.Itoa(int(port.PrivatePort)) == config.DefaultHealthPort {
+			return strconv.Itoa(int(port.PublicPort)), nil
+		}
+	}
+	return "", errors.New("health port not found")
+}
+
+func (runner *Runner) checkReady(healthPort string) error {
+	readiness, err := runner.readinessClient(healthPort)
+	if err != nil {
+		return err
+	}
+	if !readiness.Ready {
+		return fmt.Errorf("not ready: %s", readiness)
+	}
+	return nil
+}
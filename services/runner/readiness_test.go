@@ -0,0 +1,95 @@
+package runner
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/docker/docker/api/types"
+	"github.com/forta-network/forta-core-go/clients/health"
+	"github.com/forta-network/forta-node/clients"
+	mock_clients "github.com/forta-network/forta-node/clients/mocks"
+	"github.com/forta-network/forta-node/config"
+	"github.com/forta-network/forta-node/healthutils"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReadiness(t *testing.T) {
+	r := require.New(t)
+
+	dockerClient := mock_clients.NewMockDockerClient(gomock.NewController(t))
+	supervisorReadiness := &healthutils.ReadinessResponse{
+		Reasons: map[string]string{"block-lag": "no blocks scanned yet"},
+	}
+	runner := &Runner{
+		ctx:          context.Background(),
+		dockerClient: dockerClient,
+		readinessClient: func(port string) (*healthutils.ReadinessResponse, error) {
+			r.Equal("1001", port)
+			return supervisorReadiness, nil
+		},
+	}
+	handler := healthutils.ReadinessHandler(runner.readinessChecks()...)
+
+	checkReadiness := func(expectedCode int) *healthutils.ReadinessResponse {
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, httptest.NewRequest(http.MethodGet, healthutils.ReadinessPath, nil))
+		r.Equal(expectedCode, w.Code)
+		var resp healthutils.ReadinessResponse
+		r.NoError(json.NewDecoder(w.Body).Decode(&resp))
+		return &resp
+	}
+
+	// nothing is done yet
+	resp := checkReadiness(http.StatusServiceUnavailable)
+	r.False(resp.Ready)
+	r.Len(resp.Reasons, 2)
+	r.Contains(resp.Reasons, "start-up")
+	r.Contains(resp.Reasons, "supervisor")
+
+	// start-up checks passed but the supervisor has exited
+	runner.startUpChecked.Store(true)
+	runner.supervisorContainer = &clients.DockerContainer{ID: "supervisor-id"}
+	supervisorContainer := &types.Container{
+		State: "exited",
+		Ports: []types.Port{{PrivatePort: 8090, PublicPort: 1001}},
+	}
+	dockerClient.EXPECT().GetContainerByID(gomock.Any(), "supervisor-id").Return(supervisorContainer, nil).AnyTimes()
+	resp = checkReadiness(http.StatusServiceUnavailable)
+	r.Len(resp.Reasons, 1)
+	r.Equal("container is exited", resp.Reasons["supervisor"])
+
+	// supervisor is running but the blocks are lagging
+	supervisorContainer.State = "running"
+	resp = checkReadiness(http.StatusServiceUnavailable)
+	r.Equal("not ready: block-lag: no blocks scanned yet", resp.Reasons["supervisor"])
+
+	// supervisor is ready
+	supervisorReadiness.Ready = true
+	supervisorReadiness.Reasons = nil
+	resp = checkReadiness(http.StatusOK)
+	r.True(resp.Ready)
+	r.Empty(resp.Reasons)
+}
+
+func TestReadiness_ExternalSupervisor(t *testing.T) {
+	r := require.New(t)
+
+	supervisorReports := health.Reports{{Name: "health-api", Status: health.StatusDown, Details: "request failed"}}
+	runner := &Runner{
+		ctx: context.Background(),
+		cfg: config.Config{
+			Supervisor: config.SupervisorConfig{External: true, HealthURL: "http://10.0.0.5:8090/health"},
+		},
+		healthURLClient: func(rawurl string) health.Reports {
+			return supervisorReports
+		},
+	}
+	r.EqualError(runner.checkSupervisorReady(), "external supervisor health check failed: request failed")
+
+	supervisorReports = health.Reports{{Name: "service.supervisor", Status: health.StatusOK}}
+	r.NoError(runner.checkSupervisorReady())
+}
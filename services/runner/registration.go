@@ -0,0 +1,124 @@
+package runner
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/forta-network/forta-core-go/clients/health"
+	"github.com/forta-network/forta-node/store"
+	log "github.com/sirupsen/logrus"
+)
+
+// Errors
+var (
+	ErrScannerNotRegistered = errors.New("scanner is not registered")
+	ErrStakeBelowMinimum    = errors.New("scanner stake is below the minimum")
+)
+
+// registrationCheckEnabled tells if the registration of the scanner should be checked. The local
+// mode does not need the registration.
+func (runner *Runner) registrationCheckEnabled() bool {
+	runnerCfg := runner.cfg.RunnerConfig
+	if !runnerCfg.RegistrationCheck && !runnerCfg.StrictRegistration {
+		return false
+	}
+	return runner.scannerStatus != nil && !runner.cfg.LocalModeConfig.Enable && !runner.cfg.OfflineSkip("registry")
+}
+
+// scannerRegistryCheckEnabled tells if the dry run should check the scanner in the registry like
+// a real start does before starting the runner.
+func (runner *Runner) scannerRegistryCheckEnabled() bool {
+	return runner.scannerStatus != nil && !runner.skipRegistryCheck &&
+		!runner.cfg.LocalModeConfig.Enable && !runner.cfg.OfflineSkip("registry")
+}
+
+// checkScannerRegistered fails only if the scanner is not registered. A disabled scanner or a stake
+// below the minimum does not prevent the start.
+func (runner *Runner) checkScannerRegistered(ctx context.Context) error {
+	status, err := runner.getScannerStatus(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to check scanner state: %v", err)
+	}
+	if !status.Registered {
+		return fmt.Errorf("%w - please make sure you register with 'forta register' first", ErrScannerNotRegistered)
+	}
+	if !status.Enabled || status.StakeBelowMinimum {
+		log.Warn("the scanner is either disabled or does not meet the minimum staking requirement and will not receive any detection bots yet")
+	}
+	return nil
+}
+
+// checkRegistration checks if the scanner is registered and staked over the minimum so that the
+// operators find out before the node runs for days without any detection bots.
+func (runner *Runner) checkRegistration(ctx context.Context) error {
+	status, err := runner.getScannerStatus(ctx)
+	if err != nil {
+		return err
+	}
+	switch {
+	case !status.Registered:
+		log.Warn("the scanner is not registered and will not receive any detection bots - please register with 'forta register'")
+		return ErrScannerNotRegistered
+	case status.StakeBelowMinimum:
+		log.Warn("the scanner stake is below the minimum and it will not receive any detection bots - please stake on the scanner")
+		return ErrStakeBelowMinimum
+	}
+	return nil
+}
+
+// getScannerStatus returns the cached scanner status or gets it from the registry if the cached
+// status is too old.
+func (runner *Runner) getScannerStatus(ctx context.Context) (*store.ScannerStatus, error) {
+	cacheDuration := time.Duration(runner.cfg.RunnerConfig.RegistrationCacheSeconds) * time.Second
+	runner.registrationMu.Lock()
+	cached, checkedAt := runner.registration, runner.registeredAt
+	runner.registrationMu.Unlock()
+	if cached != nil && time.Since(checkedAt) < cacheDuration {
+		return cached, nil
+	}
+
+	address, err := store.NewScannerKeyStore(runner.cfg.KeyDirPath).Address()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get the scanner address: %v", err)
+	}
+	status, err := runner.scannerStatus.GetScannerStatus(ctx, address.Hex())
+	if err != nil {
+		return nil, err
+	}
+	runner.registrationMu.Lock()
+	runner.registration = status
+	runner.registeredAt = time.Now()
+	runner.registrationMu.Unlock()
+	return status, nil
+}
+
+// registrationReports reports the last known scanner status.
+func (runner *Runner) registrationReports() health.Reports {
+	runner.registrationMu.Lock()
+	status := runner.registration
+	runner.registrationMu.Unlock()
+	if status == nil {
+		return nil
+	}
+
+	registered := &health.Report{
+		Name:    "forta.registration.registered",
+		Status:  health.StatusOK,
+		Details: strconv.FormatBool(status.Registered),
+	}
+	if !status.Registered {
+		registered.Status = health.StatusFailing
+	}
+	stakeBelowMinimum := &health.Report{
+		Name:    "forta.registration.stake-below-minimum",
+		Status:  health.StatusOK,
+		Details: strconv.FormatBool(status.StakeBelowMinimum),
+	}
+	if status.StakeBelowMinimum {
+		stakeBelowMinimum.Status = health.StatusFailing
+	}
+	return health.Reports{registered, stakeBelowMinimum}
+}
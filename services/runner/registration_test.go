@@ -0,0 +1,96 @@
+package runner
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/forta-network/forta-core-go/clients/health"
+	"github.com/forta-network/forta-node/config"
+	"github.com/forta-network/forta-node/store"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+)
+
+type testScannerStatusClient struct {
+	status *store.ScannerStatus
+	calls  int
+}
+
+func (client *testScannerStatusClient) GetScannerStatus(ctx context.Context, scannerAddress string) (*store.ScannerStatus, error) {
+	client.calls++
+	return client.status, nil
+}
+
+func testRegistrationRunner(t *testing.T, strict bool, status *store.ScannerStatus) (*Runner, *testScannerStatusClient) {
+	rpcServer := testRPCServer()
+	t.Cleanup(rpcServer.Close)
+	ipfsServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	t.Cleanup(ipfsServer.Close)
+
+	var cfg config.Config
+	cfg.Scan.JsonRpc.Url = rpcServer.URL
+	cfg.Publish.SkipPublish = true
+	cfg.Registry.IPFS.GatewayURL = ipfsServer.URL
+	cfg.RunnerConfig.RegistrationCheck = true
+	cfg.RunnerConfig.StrictRegistration = strict
+	cfg.RunnerConfig.RegistrationCacheSeconds = 3600
+
+	runner, dockerClient := testDependencyRunner(t, cfg)
+	dockerClient.EXPECT().GetContainers(gomock.Any()).Return(nil, nil).AnyTimes()
+	statusClient := &testScannerStatusClient{status: status}
+	runner.scannerStatus = statusClient
+	return runner, statusClient
+}
+
+func TestRegistrationCheck_NotRegistered(t *testing.T) {
+	r := require.New(t)
+
+	// only a warning by default
+	runner, statusClient := testRegistrationRunner(t, false, &store.ScannerStatus{})
+	r.NoError(runner.doStartUpCheck())
+	reports := reportsByName(append(runner.dependencyReports(), runner.registrationReports()...))
+	r.Equal(health.StatusFailing, reports["forta.dependency.registration"].Status)
+	r.Equal("false", reports["forta.registration.registered"].Details)
+	r.Equal(health.StatusFailing, reports["forta.registration.registered"].Status)
+
+	// the status is cached
+	r.NoError(runner.runDependencyChecks())
+	r.Equal(1, statusClient.calls)
+
+	// strict registration fails the start-up
+	runner, _ = testRegistrationRunner(t, true, &store.ScannerStatus{})
+	err := runner.doStartUpCheck()
+	r.ErrorIs(err, ErrScannerNotRegistered)
+	var checkErr *StartupCheckError
+	r.ErrorAs(err, &checkErr)
+	r.Equal("registration", checkErr.Check)
+}
+
+func TestRegistrationCheck_StakeBelowMinimum(t *testing.T) {
+	r := require.New(t)
+
+	runner, _ := testRegistrationRunner(t, true, &store.ScannerStatus{Registered: true, StakeBelowMinimum: true})
+	r.ErrorIs(runner.doStartUpCheck(), ErrStakeBelowMinimum)
+	reports := reportsByName(runner.registrationReports())
+	r.Equal("true", reports["forta.registration.registered"].Details)
+	r.Equal("true", reports["forta.registration.stake-below-minimum"].Details)
+	r.Equal(health.StatusFailing, reports["forta.registration.stake-below-minimum"].Status)
+
+	runner, _ = testRegistrationRunner(t, true, &store.ScannerStatus{Registered: true, Enabled: true})
+	r.NoError(runner.doStartUpCheck())
+	reports = reportsByName(runner.registrationReports())
+	r.Equal(health.StatusOK, reports["forta.registration.registered"].Status)
+	r.Equal("false", reports["forta.registration.stake-below-minimum"].Details)
+}
+
+func TestRegistrationCheck_LocalMode(t *testing.T) {
+	r := require.New(t)
+
+	runner, statusClient := testRegistrationRunner(t, true, &store.ScannerStatus{})
+	runner.cfg.LocalModeConfig.Enable = true
+	r.NoError(runner.doStartUpCheck())
+	r.Zero(statusClient.calls)
+	r.Empty(runner.registrationReports())
+}
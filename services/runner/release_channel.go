@@ -0,0 +1,55 @@
+package runner
+
+import (
+	"github.com/forta-network/forta-core-go/clients/health"
+	"github.com/forta-network/forta-node/config"
+	"github.com/forta-network/forta-node/store"
+	log "github.com/sirupsen/logrus"
+)
+
+// releaseChannel returns the hot-reloadable release channel.
+func (runner *Runner) releaseChannel() string {
+	runner.cfgMu.RLock()
+	defer runner.cfgMu.RUnlock()
+	return runner.cfg.AutoUpdate.ReleaseChannel()
+}
+
+// switchReleaseChannel makes the image store track the new channel and restarts the updater
+// with it so that the latest release of the channel is provided.
+func (runner *Runner) switchReleaseChannel(channel string) error {
+	runner.imgStore.SetReleaseChannel(channel)
+
+	runner.containerMu.Lock()
+	defer runner.containerMu.Unlock()
+
+	logger := log.WithFields(log.Fields{
+		"channel": channel,
+		"updater": runner.currentUpdaterImg,
+	})
+	logger.Info("restarting the updater to switch the release channel")
+	return runner.replaceUpdater(logger, store.ImageRefs{
+		Updater:     runner.currentUpdaterImg,
+		ReleaseInfo: runner.currentRelease,
+	})
+}
+
+func (runner *Runner) releaseChannelReports() health.Reports {
+	reports := health.Reports{
+		{
+			Name:    "forta.update.channel",
+			Status:  health.StatusInfo,
+			Details: runner.releaseChannel(),
+		},
+	}
+
+	runner.containerMu.RLock()
+	defer runner.containerMu.RUnlock()
+	if runner.currentRelease != nil {
+		reports = append(reports, &health.Report{
+			Name:    "forta.update.release-channel",
+			Status:  health.StatusInfo,
+			Details: config.GetReleaseChannel(runner.currentRelease),
+		})
+	}
+	return reports
+}
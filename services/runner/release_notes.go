@@ -0,0 +1,48 @@
+package runner
+
+import (
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"github.com/forta-network/forta-node/config"
+	log "github.com/sirupsen/logrus"
+)
+
+// recordReleaseNotes logs the summary of the applied release notes and keeps them so that they
+// can be read from the admin API later.
+func (runner *Runner) recordReleaseNotes(logger *log.Entry, notes *config.ReleaseNotes) {
+	if notes == nil {
+		return
+	}
+	appliedAt := time.Now().UTC()
+	applied := *notes
+	applied.AppliedAt = &appliedAt
+	logger.WithFields(log.Fields{
+		"version":   applied.Version,
+		"truncated": applied.Truncated,
+	}).Infof("applied release notes: %s", applied.Summary())
+
+	if runner.releaseNotes == nil {
+		return
+	}
+	if err := runner.releaseNotes.Add(&applied); err != nil {
+		logger.WithError(err).Warn("failed to save the release notes")
+	}
+}
+
+func (runner *Runner) handleAdminReleases(w http.ResponseWriter, r *http.Request) {
+	notes := []*config.ReleaseNotes{}
+	if runner.releaseNotes != nil {
+		list, err := runner.releaseNotes.List()
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		if list != nil {
+			notes = list
+		}
+	}
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(notes)
+}
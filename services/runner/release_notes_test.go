@@ -0,0 +1,63 @@
+package runner
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/forta-network/forta-node/clients"
+	"github.com/forta-network/forta-node/config"
+	"github.com/forta-network/forta-node/store"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestUpdateContainers_ReleaseNotes(t *testing.T) {
+	r := require.New(t)
+
+	runner, dockerClient, _ := testStateRunner(t)
+	runner.adminToken = "token1"
+	runner.currentSupervisorImg = "supervisor-old"
+	runner.currentUpdaterImg = "updater"
+	runner.supervisorContainer = &clients.DockerContainer{Name: config.DockerSupervisorContainerName, ID: "supervisor1"}
+
+	dockerClient.EXPECT().TerminateContainer(gomock.Any(), "supervisor1", gomock.Any()).Return(nil)
+	dockerClient.EXPECT().WaitContainerExit(gomock.Any(), "supervisor1").Return(nil)
+	dockerClient.EXPECT().Prune(gomock.Any()).Return(nil)
+	dockerClient.EXPECT().WaitContainerPrune(gomock.Any(), "supervisor1").Return(nil)
+	expectStartContainer(r, dockerClient, "supervisor", "supervisor-new", "supervisor2")
+
+	r.NotNil(runner.updateContainers(store.ImageRefs{
+		Supervisor: "supervisor-new",
+		Updater:    "updater",
+		Notes:      &config.ReleaseNotes{Version: "v0.7.1", Commit: "commit2", IPFS: "cid2", Notes: "- fixed things\n- added things"},
+	}))
+
+	server := httptest.NewServer(runner.controlRouter())
+	defer server.Close()
+	req, err := http.NewRequest(http.MethodGet, server.URL+"/admin/releases", nil)
+	r.NoError(err)
+	req.Header.Set("Authorization", "Bearer token1")
+	resp, err := http.DefaultClient.Do(req)
+	r.NoError(err)
+	defer resp.Body.Close()
+	r.Equal(http.StatusOK, resp.StatusCode)
+
+	var notes []*config.ReleaseNotes
+	r.NoError(json.NewDecoder(resp.Body).Decode(&notes))
+	r.Len(notes, 1)
+	r.Equal("v0.7.1", notes[0].Version)
+	r.Equal("- fixed things\n- added things", notes[0].Notes)
+	r.NotNil(notes[0].AppliedAt)
+}
+
+func TestHandleAdminReleases_Empty(t *testing.T) {
+	r := require.New(t)
+
+	runner, _, _ := testStateRunner(t)
+	rec := httptest.NewRecorder()
+	runner.handleAdminReleases(rec, httptest.NewRequest(http.MethodGet, "/admin/releases", nil))
+	r.Equal(http.StatusOK, rec.Code)
+	r.Equal("[]\n", rec.Body.String())
+}
@@ -0,0 +1,79 @@
+package runner
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/forta-network/forta-node/config"
+	log "github.com/sirupsen/logrus"
+)
+
+// saveRemovedContainerLogs copies the last logs of a container to the forta dir before the
+// container is removed, so that the logs of the old containers survive the updates.
+func (runner *Runner) saveRemovedContainerLogs(name, id string) {
+	keepCfg := runner.cfg.Log.KeepRemoved
+	if keepCfg.Lines <= 0 {
+		return
+	}
+	logger := log.WithField("container", id).WithField("name", name)
+
+	logs, err := runner.dockerClient.GetContainerLogs(context.Background(), id, strconv.Itoa(keepCfg.Lines), -1)
+	if err != nil {
+		logger.WithError(err).Warn("failed to get the logs of the removed container")
+		return
+	}
+	if keepCfg.MaxBytes > 0 && len(logs) > keepCfg.MaxBytes {
+		logs = logs[len(logs)-keepCfg.MaxBytes:]
+	}
+
+	dir := path.Join(runner.cfg.FortaDir, config.DefaultRemovedLogsDirName)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		logger.WithError(err).Warn("failed to create the removed logs dir")
+		return
+	}
+	filePath := path.Join(dir, fmt.Sprintf("%s-%s.log", name, time.Now().UTC().Format("20060102T150405Z")))
+	if err := os.WriteFile(filePath, []byte(logs), 0644); err != nil {
+		logger.WithError(err).Warn("failed to save the logs of the removed container")
+		return
+	}
+	logger.WithField("file", filePath).Info("saved the logs of the removed container")
+
+	pruneRemovedLogs(dir, keepCfg.MaxFiles)
+}
+
+// pruneRemovedLogs deletes the oldest log files until at most maxFiles are left.
+func pruneRemovedLogs(dir string, maxFiles int) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		log.WithError(err).WithField("dir", dir).Warn("failed to list the removed logs")
+		return
+	}
+	type logFile struct {
+		name    string
+		modTime time.Time
+	}
+	var files []logFile
+	for _, entry := range entries {
+		info, err := entry.Info()
+		if err != nil || entry.IsDir() {
+			continue
+		}
+		files = append(files, logFile{name: entry.Name(), modTime: info.ModTime()})
+	}
+	if len(files) <= maxFiles {
+		return
+	}
+	sort.Slice(files, func(i, j int) bool {
+		return files[i].modTime.Before(files[j].modTime)
+	})
+	for _, file := range files[:len(files)-maxFiles] {
+		if err := os.Remove(path.Join(dir, file.name)); err != nil {
+			log.WithError(err).WithField("file", file.name).Warn("failed to delete the old removed logs")
+		}
+	}
+}
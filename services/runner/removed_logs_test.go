@@ -0,0 +1,49 @@
+package runner
+
+import (
+	"os"
+	"path"
+	"testing"
+	"time"
+
+	"github.com/forta-network/forta-node/config"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSaveRemovedContainerLogs(t *testing.T) {
+	r := require.New(t)
+
+	runner, dockerClient, _ := testStateRunner(t)
+	runner.cfg.Log.KeepRemoved = config.KeepRemovedLogsConfig{Lines: 100, MaxBytes: 6, MaxFiles: 2}
+
+	logsDir := path.Join(runner.cfg.FortaDir, config.DefaultRemovedLogsDirName)
+	r.NoError(os.MkdirAll(logsDir, 0755))
+	for i, name := range []string{"old-1.log", "old-2.log"} {
+		filePath := path.Join(logsDir, name)
+		r.NoError(os.WriteFile(filePath, nil, 0644))
+		modTime := time.Now().Add(-time.Hour * time.Duration(2-i))
+		r.NoError(os.Chtimes(filePath, modTime, modTime))
+	}
+
+	dockerClient.EXPECT().GetContainerLogs(gomock.Any(), "supervisor-id", "100", -1).Return("line1\nline2\n", nil)
+	runner.saveRemovedContainerLogs("forta-supervisor", "supervisor-id")
+
+	entries, err := os.ReadDir(logsDir)
+	r.NoError(err)
+	r.Len(entries, 2)
+	r.Equal("old-2.log", entries[1].Name())
+	b, err := os.ReadFile(path.Join(logsDir, entries[0].Name()))
+	r.NoError(err)
+	r.Equal("line2\n", string(b))
+}
+
+func TestSaveRemovedContainerLogs_Disabled(t *testing.T) {
+	r := require.New(t)
+
+	runner, _, _ := testStateRunner(t)
+	runner.saveRemovedContainerLogs("forta-supervisor", "supervisor-id")
+
+	_, err := os.Stat(path.Join(runner.cfg.FortaDir, config.DefaultRemovedLogsDirName))
+	r.True(os.IsNotExist(err))
+}
@@ -2,16 +2,20 @@ package runner
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"net/http"
 	"strconv"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/forta-network/forta-core-go/clients/health"
-	"github.com/forta-network/forta-core-go/ethereum"
+	"github.com/forta-network/forta-core-go/release"
 	"github.com/forta-network/forta-core-go/utils"
 	"github.com/forta-network/forta-node/clients"
+	"github.com/forta-network/forta-node/clients/ethclient"
 	"github.com/forta-network/forta-node/config"
 	"github.com/forta-network/forta-node/healthutils"
 	"github.com/forta-network/forta-node/services"
@@ -19,21 +23,88 @@ import (
 	log "github.com/sirupsen/logrus"
 )
 
+const updateWindowCheckInterval = time.Minute
+
 // Runner receives and starts the latest updater and supervisor.
 type Runner struct {
 	ctx          context.Context
 	cfg          config.Config
+	cfgMu        sync.RWMutex // protects the hot-reloaded config values
 	imgStore     store.FortaImageStore
 	dockerClient clients.DockerClient
 	globalClient clients.DockerClient
+	registryAuth clients.RegistryAuthProvider
+
+	scanAPILimiter    *ethclient.Limiter
+	archiveAPILimiter *ethclient.Limiter
+	traceAPILimiter   *ethclient.Limiter
 
 	updaterContainer     *clients.DockerContainer
 	supervisorContainer  *clients.DockerContainer
 	currentUpdaterImg    string
 	currentSupervisorImg string
+	currentRelease       *release.ReleaseInfo
 	containerMu          sync.RWMutex // protects above refs and containers
 
-	healthClient health.HealthClient
+	healthClient    health.HealthClient
+	healthURLClient func(rawurl string) health.Reports
+	readinessClient func(port string) (*healthutils.ReadinessResponse, error)
+	startUpChecked  atomic.Bool
+
+	livenessTicker *time.Ticker
+	deferredUpdate health.MessageTracker
+	pendingUpdate  health.MessageTracker
+	heldUpdate     heldUpdate
+	releaseSeen    store.ReleaseSeenStore
+	releaseNotes   store.ReleaseNotesStore
+	stateStore     store.RunnerStateStore
+	latestRelease  *latestRelease
+	latestMu       sync.RWMutex // protects the latest release
+	updateChecks   chan *updateCheck
+
+	validationInterval time.Duration
+	updateValidation   health.MessageTracker
+	rollbackRefs       *store.ImageRefs // previous release until the update is validated
+	rejectedRelease    string
+	validationMu       sync.RWMutex // protects above refs
+	updateMu           sync.Mutex   // held while updating and validating so that the images are not pruned
+	validating         atomic.Bool
+	invalidRelease     health.MessageTracker
+	imagePrune         health.MessageTracker
+	untrustedImages    atomic.Int64
+	untrustedImage     health.MessageTracker
+
+	// in memory only so the updates are resumed after restart
+	updatesPaused atomic.Bool
+	controlServer *http.Server
+	adminToken    string
+	adminAction   health.MessageTracker
+	restarts      map[string]int // protected by the container lock
+	daemon        daemonStatus   // protected by the container lock
+
+	requestDrain  func(port, token string) (*healthutils.DrainState, error)
+	getDrainState func(port, token string) (*healthutils.DrainState, error)
+	drainStatus   health.MessageTracker
+	retryAgents   func(port, token, agentID string) (*healthutils.AgentRetryResult, error)
+	disableAgent  func(port, token, agentID, reason string) (*healthutils.AgentDisableResult, error)
+	enableAgent   func(port, token, agentID string) (*healthutils.AgentDisableResult, error)
+	runAgentOnce  func(port, token string, req *healthutils.AgentRunRequest) (*healthutils.AgentRunResult, error)
+	resetAgents   func(port, token string) (*healthutils.AgentResetResult, error)
+
+	ethClient  EthereumClient
+	blockGap   *blockGap
+	blockGapMu sync.RWMutex
+
+	dependencyResults map[string]*dependencyCheckResult
+	diskUsages        []*diskUsage
+	diskFree          func(path string) (uint64, error)
+	dependencyMu      sync.RWMutex
+
+	scannerStatus     store.ScannerStatusClient
+	skipRegistryCheck bool
+	registration      *store.ScannerStatus
+	registeredAt      time.Time
+	registrationMu    sync.Mutex
 }
 
 // EthereumClient is useful for checking the JSON-RPC API.
@@ -53,34 +124,135 @@ func NewRunner(ctx context.Context, cfg config.Config,
 		dockerClient: runnerDockerClient,
 		globalClient: globalDockerClient,
 		healthClient: health.NewClient(),
+		releaseSeen:  store.NewReleaseSeenStore(cfg.FortaDir),
+		releaseNotes: store.NewReleaseNotesStore(cfg.FortaDir),
+		stateStore:   store.NewRunnerStateStore(cfg.FortaDir),
+		updateChecks: make(chan *updateCheck),
+
+		scanAPILimiter:    ethclient.NewLimiter("scan", cfg.Scan.JsonRpc),
+		archiveAPILimiter: ethclient.NewLimiter("archive", cfg.Scan.ArchiveJsonRpc),
+		traceAPILimiter:   ethclient.NewLimiter("trace", cfg.Trace.JsonRpc),
+
+		validationInterval: defaultValidationInterval,
+
+		healthURLClient: healthutils.CheckHealthURL,
+		readinessClient: healthutils.GetReadiness,
+		requestDrain:    healthutils.RequestDrain,
+		getDrainState:   healthutils.GetDrainState,
+		retryAgents:     healthutils.RetryQuarantinedAgents,
+		disableAgent:    healthutils.DisableAgent,
+		enableAgent:     healthutils.EnableAgent,
+		runAgentOnce:    healthutils.RunAgentOnce,
+		resetAgents:     healthutils.ResetAgents,
+		diskFree:        freeDiskBytes,
+
+		dependencyResults: make(map[string]*dependencyCheckResult),
+		scannerStatus:     store.NewScannerStatusClient(ctx, cfg),
 	}
 }
 
+// WithRegistryAuth sets the credentials which are used for checking the container registry.
+func (runner *Runner) WithRegistryAuth(auth clients.RegistryAuthProvider) *Runner {
+	runner.registryAuth = auth
+	return runner
+}
+
+// WithScannerStatus replaces the scanner status client so that the client which was used
+// before starting the runner is reused.
+func (runner *Runner) WithScannerStatus(client store.ScannerStatusClient) *Runner {
+	if client != nil {
+		runner.scannerStatus = client
+	}
+	return runner
+}
+
+// WithoutRegistryCheck disables checking the scanner in the registry before start-up.
+func (runner *Runner) WithoutRegistryCheck(skip bool) *Runner {
+	runner.skipRegistryCheck = skip
+	return runner
+}
+
 // Start starts the service.
 func (runner *Runner) Start() error {
+	// start early to report the start-up check results
+	if err := healthutils.StartServer(
+		runner.ctx, runner.cfg.Health.BindAddr, healthutils.DefaultHealthServerErrHandler, runner.cfg.TelemetryConfig.Auth,
+		runner.checkHealth, runner.readinessChecks()...,
+	); err != nil {
+		return fmt.Errorf("failed to start the health server: %v", err)
+	}
+
 	if err := runner.doStartUpCheck(); err != nil {
-		return fmt.Errorf("start-up check failed: %v", err)
+		return fmt.Errorf("start-up check failed: %w", err)
 	}
+	runner.startUpChecked.Store(true)
 	log.Info("start-up check successful")
 
-	if err := runner.globalClient.Nuke(context.Background()); err != nil {
-		return fmt.Errorf("failed to nuke leftover containers at start: %v", err)
+	if err := runner.startControlServer(); err != nil {
+		return err
 	}
 
-	health.StartServer(runner.ctx, "", healthutils.DefaultHealthServerErrHandler, runner.checkHealth)
-
-	if runner.cfg.AutoUpdate.Disable {
-		runner.startEmbeddedSupervisor()
+	// the external supervisor is only checked for health
+	if runner.cfg.Supervisor.External {
+		log.WithField("healthUrl", runner.cfg.Supervisor.HealthURL).Info("using the external supervisor")
 	} else {
-		runner.startEmbeddedUpdater()
-		go runner.keepContainersUpToDate()
+		if err := runner.startContainers(); err != nil {
+			return err
+		}
+		if !runner.cfg.UpdatesDisabled() {
+			runner.loadRejectedRelease()
+			go runner.keepContainersUpToDate()
+		}
+
+		runner.livenessTicker = time.NewTicker(runner.livenessCheckInterval())
+		go runner.keepContainersAlive()
+		if runner.cfg.RunnerConfig.ImagePruneIntervalSeconds > 0 {
+			go runner.keepImagesPruned()
+		}
 	}
 
-	go runner.keepContainersAlive()
+	if runner.cfg.RunnerConfig.WatchConfig {
+		go runner.watchConfig()
+	}
+	go runner.recheckDependencies()
+	if runner.cfg.RunnerConfig.BlockGapCheckIntervalSeconds > 0 {
+		go runner.keepBlockGapChecked()
+	}
 
 	return nil
 }
 
+// startContainers adopts the containers from the previous run if nothing has changed since then.
+// Otherwise, the leftover containers are removed and the last saved release is started.
+func (runner *Runner) startContainers() error {
+	state := runner.loadState()
+	if runner.adoptContainers(state) {
+		return nil
+	}
+
+	if err := runner.globalClient.Nuke(context.Background()); err != nil {
+		return fmt.Errorf("failed to nuke leftover containers at start: %v", err)
+	}
+
+	if runner.cfg.UpdatesDisabled() {
+		if err := runner.startEmbeddedSupervisor(); err != nil {
+			return fmt.Errorf("failed to start the supervisor: %v", err)
+		}
+		return nil
+	}
+	if hasSavedRelease(state) {
+		err := runner.startSavedRelease(state)
+		if err == nil {
+			return nil
+		}
+		log.WithError(err).Warn("failed to start the saved release - starting the embedded updater")
+	}
+	if err := runner.startEmbeddedUpdater(); err != nil {
+		return fmt.Errorf("failed to start the updater: %v", err)
+	}
+	return nil
+}
+
 // Name returns the name of the service.
 func (runner *Runner) Name() string {
 	return "runner"
@@ -92,34 +264,37 @@ func (runner *Runner) Stop() error {
 	defer runner.containerMu.RUnlock()
 
 	if runner.updaterContainer != nil {
-		runner.dockerClient.InterruptContainer(context.Background(), runner.updaterContainer.ID)
-
+		runner.stopContainer(runner.updaterContainer.ID)
 	}
 	if runner.supervisorContainer != nil {
-		runner.dockerClient.InterruptContainer(context.Background(), runner.supervisorContainer.ID)
+		runner.stopContainer(runner.supervisorContainer.ID)
+	}
+	if runner.controlServer != nil {
+		runner.controlServer.Close()
 	}
 	return nil
 }
 
-func (runner *Runner) doStartUpCheck() error {
-	// ensure that docker is available
-	_, err := runner.dockerClient.GetContainers(runner.ctx)
-	if err != nil {
-		return fmt.Errorf("docker check failed (get containers): %v", err)
+// stopContainer interrupts the container or stops it gracefully if a stop timeout is configured.
+func (runner *Runner) stopContainer(id string) {
+	timeout := runner.cfg.ResourcesConfig.ContainerStopTimeout()
+	if timeout == 0 {
+		runner.dockerClient.InterruptContainer(context.Background(), id)
+		return
 	}
-	// ensure that the scan json-rpc api is reachable
-	err = ethereum.TestAPI(runner.ctx, runner.fixTestRpcUrl(runner.cfg.Scan.JsonRpc.Url))
-	if err != nil {
-		return fmt.Errorf("scan api check failed: %v", err)
+	if err := runner.dockerClient.TerminateContainer(context.Background(), id, timeout); err != nil {
+		log.WithError(err).WithField("container", id).Error("error stopping container")
 	}
-	if runner.cfg.Trace.Enabled {
-		// ensure that the trace json-rpc api is reachable
-		err = ethereum.TestAPI(runner.ctx, runner.fixTestRpcUrl(runner.cfg.Trace.JsonRpc.Url))
-		if err != nil {
-			return fmt.Errorf("trace api check failed: %v", err)
+}
+
+func (runner *Runner) doStartUpCheck() error {
+	// fail before the slower checks and before the supervisor needs the key
+	if !runner.cfg.RunnerConfig.SkipKeyCheck {
+		if err := runner.checkScannerKey(); err != nil {
+			return &StartupCheckError{Check: "keystore", Cause: err}
 		}
 	}
-	return nil
+	return runner.runDependencyChecks()
 }
 
 func (runner *Runner) fixTestRpcUrl(rawurl string) string {
@@ -135,43 +310,56 @@ func (runner *Runner) removeContainer(container *clients.DockerContainer) error
 
 func (runner *Runner) removeContainerWithProps(name, id string) error {
 	logger := log.WithField("container", id).WithField("name", name)
-	if err := runner.dockerClient.TerminateContainer(context.Background(), id); err != nil {
+	if err := runner.dockerClient.TerminateContainer(
+		context.Background(), id, runner.cfg.ResourcesConfig.ContainerStopTimeout(),
+	); err != nil {
 		logger.WithError(err).Error("error stopping container")
 	} else {
 		logger.Info("interrupted")
 	}
 	if err := runner.dockerClient.WaitContainerExit(context.Background(), id); err != nil {
-		logger.WithError(err).Panic("error while waiting for container exit")
+		return fmt.Errorf("error while waiting for container exit: %v", err)
 	}
+	runner.saveRemovedContainerLogs(name, id)
 	if err := runner.dockerClient.Prune(runner.ctx); err != nil {
-		logger.WithError(err).Panic("error while pruning after stopping old containers")
+		return fmt.Errorf("error while pruning after stopping old containers: %v", err)
 	}
 	if err := runner.dockerClient.WaitContainerPrune(runner.ctx, id); err != nil {
-		logger.WithError(err).Panic("error while waiting for old container prune")
+		return fmt.Errorf("error while waiting for old container prune: %v", err)
 	}
 	return nil
 }
 
-func (runner *Runner) startEmbeddedUpdater() {
+func (runner *Runner) startEmbeddedUpdater() error {
+	runner.containerMu.Lock()
+	defer runner.containerMu.Unlock()
+
 	builtInRefs := runner.imgStore.EmbeddedImageRefs()
 	logger := log.WithField("supervisor", builtInRefs.Supervisor).WithField("updater", builtInRefs.Updater)
 
 	if err := runner.replaceUpdater(logger, builtInRefs); err != nil {
-		logger.WithError(err).Panic("error replacing updater")
-	} else {
-		runner.currentUpdaterImg = builtInRefs.Updater
+		return err
 	}
+	runner.currentUpdaterImg = builtInRefs.Updater
+	runner.currentRelease = builtInRefs.ReleaseInfo
+	runner.saveState()
+	return nil
 }
 
-func (runner *Runner) startEmbeddedSupervisor() {
+func (runner *Runner) startEmbeddedSupervisor() error {
+	runner.containerMu.Lock()
+	defer runner.containerMu.Unlock()
+
 	builtInRefs := runner.imgStore.EmbeddedImageRefs()
 	logger := log.WithField("supervisor", builtInRefs.Supervisor).WithField("updater", builtInRefs.Updater)
 
 	if err := runner.replaceSupervisor(logger, builtInRefs); err != nil {
-		logger.WithError(err).Panic("error replacing supervisor")
-	} else {
-		runner.currentSupervisorImg = builtInRefs.Supervisor
+		return err
 	}
+	runner.currentSupervisorImg = builtInRefs.Supervisor
+	runner.currentRelease = builtInRefs.ReleaseInfo
+	runner.saveState()
+	return nil
 }
 
 func (runner *Runner) keepContainersUpToDate() {
@@ -182,12 +370,151 @@ func (runner *Runner) keepContainersUpToDate() {
 		}
 	}()
 
-	for latestRefs := range runner.imgStore.Latest() {
-		runner.updateContainers(latestRefs)
+	log.WithField("channel", runner.releaseChannel()).Info("tracking the release channel")
+	ticker := time.NewTicker(updateWindowCheckInterval)
+	defer ticker.Stop()
+
+	var pendingRefs *store.ImageRefs
+	for {
+		var check *updateCheck
+		select {
+		case latestRefs := <-runner.imgStore.Latest():
+			if runner.skipsRelease(&latestRefs) {
+				continue
+			}
+			pendingRefs = &latestRefs
+
+		case check = <-runner.updateChecks:
+			latestRefs, err := runner.imgStore.CheckNow(runner.ctx)
+			if err != nil {
+				check.done(newUpdateCheckError(err))
+				continue
+			}
+			if latestRefs != nil {
+				if runner.skipsRelease(latestRefs) {
+					check.done(newUpdateCheckResult(updateCheckSkipped, latestRefs))
+					continue
+				}
+				pendingRefs = latestRefs
+			}
+
+		case <-ticker.C:
+
+		case <-runner.ctx.Done():
+			return
+		}
+
+		if pendingRefs == nil {
+			check.done(&updateCheckResult{Result: updateCheckNoUpdate})
+			continue
+		}
+		if reason := runner.holdUpdate(pendingRefs); len(reason) > 0 {
+			result := newUpdateCheckResult(updateCheckHeld, pendingRefs)
+			result.Reason = reason
+			check.done(result)
+			continue
+		}
+		// rejected by the validation of the previous update while pending
+		if runner.isRejected(pendingRefs) {
+			runner.heldUpdate.release()
+			check.done(newUpdateCheckResult(updateCheckSkipped, pendingRefs))
+			pendingRefs = nil
+			continue
+		}
+		runner.updateMu.Lock()
+		prevRefs := runner.updateContainers(*pendingRefs)
+		runner.setDeferredUpdate(nil)
+		runner.heldUpdate.release()
+		if prevRefs != nil {
+			runner.startValidation(*prevRefs, *pendingRefs)
+		}
+		runner.updateMu.Unlock()
+		check.done(runner.updatedResult(pendingRefs))
+		pendingRefs = nil
 	}
 }
 
-func (runner *Runner) updateContainers(latestRefs store.ImageRefs) {
+// skipsRelease tells if the release should not be applied.
+func (runner *Runner) skipsRelease(latestRefs *store.ImageRefs) bool {
+	runner.setLatestRelease(*latestRefs)
+	releaseChannel := runner.releaseChannel()
+	if latestRefs.ReleaseInfo != nil && !config.MatchesReleaseChannel(latestRefs.ReleaseInfo, releaseChannel) {
+		log.WithFields(log.Fields{
+			"version":        latestRefs.ReleaseInfo.Manifest.Release.Version,
+			"channel":        releaseChannel,
+			"releaseChannel": config.GetReleaseChannel(latestRefs.ReleaseInfo),
+		}).Info("skipping release from another channel")
+		return true
+	}
+	if runner.isRejected(latestRefs) {
+		log.WithField("release", describeRelease(latestRefs)).Info("skipping the rejected release")
+		return true
+	}
+	return !runner.acceptsRelease(latestRefs) || !runner.trustsImageSources(latestRefs)
+}
+
+// holdUpdate holds the pending update and returns the reason if the update cannot be applied now.
+func (runner *Runner) holdUpdate(pendingRefs *store.ImageRefs) (reason string) {
+	switch {
+	case runner.updatesPaused.Load():
+		runner.logPausedUpdate(pendingRefs)
+		reason = holdReasonPaused
+	case runner.validating.Load():
+		reason = holdReasonValidating
+	case runner.awaitsTrackDelay(pendingRefs):
+		reason = holdReasonTrackDelay
+	case !runner.inUpdateWindow():
+		runner.setDeferredUpdate(pendingRefs)
+		reason = holdReasonUpdateWindow
+	default:
+		return ""
+	}
+	runner.heldUpdate.hold(pendingRefs, reason)
+	return reason
+}
+
+func (runner *Runner) logPausedUpdate(refs *store.ImageRefs) {
+	var version string
+	if refs.ReleaseInfo != nil {
+		version = refs.ReleaseInfo.Manifest.Release.Version
+	}
+	log.WithFields(log.Fields{
+		"version":    version,
+		"supervisor": refs.Supervisor,
+		"updater":    refs.Updater,
+	}).Info("updates are paused - not applying the detected release")
+}
+
+func (runner *Runner) inUpdateWindow() bool {
+	window := runner.cfg.AutoUpdate.Window
+	if window == nil {
+		return true
+	}
+	inWindow, err := window.Contains(time.Now())
+	if err != nil {
+		// do not block the updates forever because of a bad config
+		log.WithError(err).Warn("failed to check the update window - allowing update")
+		return true
+	}
+	return inWindow
+}
+
+func (runner *Runner) setDeferredUpdate(refs *store.ImageRefs) {
+	if refs == nil {
+		runner.deferredUpdate.Set("")
+		return
+	}
+	deferred := describeRelease(refs)
+	if runner.deferredUpdate.GetReport("").Details != deferred {
+		log.WithField("release", deferred).Info("deferring update until the update window opens")
+	}
+	runner.deferredUpdate.Set(deferred)
+}
+
+// updateContainers replaces the containers with the latest images and returns the previous
+// images if the supervisor was replaced. The previous supervisor is restored if the new one does
+// not start in time.
+func (runner *Runner) updateContainers(latestRefs store.ImageRefs) (prevRefs *store.ImageRefs) {
 	runner.containerMu.Lock()
 	defer runner.containerMu.Unlock()
 
@@ -199,6 +526,16 @@ func (runner *Runner) updateContainers(latestRefs store.ImageRefs) {
 		})
 	}
 	logger.Info("detected new images")
+	if len(runner.currentSupervisorImg) > 0 && latestRefs.Supervisor != runner.currentSupervisorImg {
+		prevRefs = &store.ImageRefs{
+			Supervisor:  runner.currentSupervisorImg,
+			Updater:     runner.currentUpdaterImg,
+			ReleaseInfo: runner.currentRelease,
+		}
+	}
+	if latestRefs.ReleaseInfo != nil {
+		runner.currentRelease = latestRefs.ReleaseInfo
+	}
 	if latestRefs.Updater != runner.currentUpdaterImg {
 		if err := runner.replaceUpdater(logger, latestRefs); err != nil {
 			logger.WithError(err).Panic("error replacing updater")
@@ -210,34 +547,77 @@ func (runner *Runner) updateContainers(latestRefs store.ImageRefs) {
 	}
 
 	if latestRefs.Supervisor != runner.currentSupervisorImg {
-		if err := runner.replaceSupervisor(logger, latestRefs); err != nil {
-			logger.WithError(err).Panic("error replacing supervisor")
-		} else {
+		err := runner.replaceSupervisor(logger, latestRefs)
+		switch {
+		case err == nil:
 			runner.currentSupervisorImg = latestRefs.Supervisor
+		case errors.Is(err, clients.ErrContainerStartTimeout) && prevRefs != nil:
+			// rejected without validation
+			runner.rollbackStartTimeout(logger, *prevRefs, latestRefs, err)
+			prevRefs = nil
+		default:
+			logger.WithError(err).Panic("error replacing supervisor")
 		}
 	} else {
 		log.Debug("same image - not replacing supervisor")
 	}
+	if runner.currentSupervisorImg == latestRefs.Supervisor {
+		runner.recordReleaseNotes(logger, latestRefs.Notes)
+	}
+	runner.saveState()
+	return
 }
 
-func (runner *Runner) ensureImage(logger *log.Entry, name string, imageRef string) (string, error) {
+func (runner *Runner) ensureImage(logger *log.Entry, name string, imageRef string, manifestRef string) (string, error) {
 	logger = logger.WithField("ref", imageRef).WithField("name", name)
 
 	// to make things easier, don't require image ref validation in dev mode
 	if !runner.cfg.Development {
 		fixedRef, err := utils.ValidateDiscoImageRef(runner.cfg.Registry.ContainerRegistry, imageRef)
-		if err != nil {
+		switch {
+		case err != nil && runner.cfg.Registry.StrictImageRefs:
+			logger.WithError(err).WithField("imageRef", imageRef).Error("not a disco ref - refusing to run the image")
+			return "", fmt.Errorf("%w: %s", ErrInvalidImageRef, err)
+		case err != nil:
 			logger.WithError(err).WithField("imageRef", imageRef).Warn("not a disco ref")
-		} else {
+		default:
 			imageRef = fixedRef // important
 		}
 	}
+	if err := runner.checkImageSource(name, imageRef); err != nil {
+		logger.WithError(err).Error("refusing to run the image")
+		return "", err
+	}
+
+	if runner.cfg.RunnerConfig.MinFreeDiskBytes > 0 && !runner.dockerClient.HasLocalImage(runner.ctx, imageRef) {
+		if err := runner.checkDiskSpace(runner.ctx); err != nil {
+			logger.WithError(err).Error("not pulling the image")
+			return "", err
+		}
+	}
 
 	if err := runner.dockerClient.EnsureLocalImage(runner.ctx, name, imageRef); err != nil {
 		logger.WithError(err).Warn("failed to ensure local image")
 		return "", err
 	}
 
+	err := runner.verifyImageDigest(logger, imageRef, manifestRef)
+	if errors.Is(err, ErrImageDigestMismatch) {
+		// the local image may be left over from an interrupted pull - try once with a clean pull
+		logger.WithError(err).Warn("local image does not match the release - pulling again")
+		err = runner.repullImage(name, imageRef)
+		if err == nil {
+			err = runner.verifyImageDigest(logger, imageRef, manifestRef)
+		}
+	}
+	if err != nil {
+		if !runner.cfg.Development {
+			logger.WithError(err).Error("refusing to run the image")
+			return "", err
+		}
+		logger.WithError(err).Warn("image verification failed - ignoring in development mode")
+	}
+
 	return imageRef, nil
 }
 
@@ -261,37 +641,21 @@ func (runner *Runner) replaceSupervisor(logger *log.Entry, imageRefs store.Image
 
 func (runner *Runner) startUpdater(logger *log.Entry, latestRefs store.ImageRefs) (err error) {
 	updaterRef := latestRefs.Updater
-	updaterRef, err = runner.ensureImage(logger, "updater", updaterRef)
+	updaterRef, err = runner.ensureImage(
+		logger, "updater", updaterRef, manifestImageRef(latestRefs.ReleaseInfo, "updater"),
+	)
 	if err != nil {
 		return err
 	}
 
-	uc, err := runner.dockerClient.StartContainer(runner.ctx, clients.DockerContainerConfig{
-		Name:  config.DockerUpdaterContainerName,
-		Image: updaterRef,
-		Cmd:   []string{config.DefaultFortaNodeBinaryPath, "updater"},
-		Env: map[string]string{
-			config.EnvDevelopment: strconv.FormatBool(runner.cfg.Development),
-			config.EnvReleaseInfo: latestRefs.ReleaseInfo.String(),
-		},
-		Volumes: map[string]string{
-			runner.cfg.FortaDir: config.DefaultContainerFortaDirPath,
-		},
-		Ports: map[string]string{
-			config.DefaultContainerPort: config.DefaultContainerPort,
-			"":                          config.DefaultHealthPort, // random host port
-		},
-		DialHost:    true,
-		MaxLogSize:  runner.cfg.Log.MaxLogSize,
-		MaxLogFiles: runner.cfg.Log.MaxLogFiles,
-	})
+	uc, err := runner.dockerClient.StartContainer(runner.ctx, runner.updaterContainerConfig(updaterRef, latestRefs))
 	if err != nil {
 		logger.WithError(err).Errorf("failed to start the updater")
 		return err
 	}
 	runner.updaterContainer = uc
 
-	if err := runner.dockerClient.WaitContainerStart(runner.ctx, runner.updaterContainer.ID); err != nil {
+	if err := runner.waitContainerStart(runner.updaterContainer.ID); err != nil {
 		logger.WithError(err).Error("error while waiting for updater start")
 		return err
 	}
@@ -300,52 +664,113 @@ func (runner *Runner) startUpdater(logger *log.Entry, latestRefs store.ImageRefs
 
 func (runner *Runner) startSupervisor(logger *log.Entry, latestRefs store.ImageRefs) (err error) {
 	supervisorRef := latestRefs.Supervisor
-	supervisorRef, err = runner.ensureImage(logger, "supervisor", supervisorRef)
+	supervisorRef, err = runner.ensureImage(
+		logger, "supervisor", supervisorRef, manifestImageRef(latestRefs.ReleaseInfo, "supervisor"),
+	)
 	if err != nil {
 		return err
 	}
-	sc, err := runner.dockerClient.StartContainer(runner.ctx, clients.DockerContainerConfig{
+	sc, err := runner.dockerClient.StartContainer(runner.ctx, runner.supervisorContainerConfig(supervisorRef, latestRefs))
+	if err != nil {
+		logger.WithError(err).Errorf("failed to start the supervisor")
+		return err
+	}
+	runner.supervisorContainer = sc
+
+	if err := runner.waitContainerStart(runner.supervisorContainer.ID); err != nil {
+		logger.WithError(err).Error("error while waiting for supervisor start")
+		return err
+	}
+	return nil
+}
+
+// waitContainerStart waits for the container to start within the configured timeout so that a
+// container which never starts does not block the launch.
+func (runner *Runner) waitContainerStart(id string) error {
+	ctx := runner.ctx
+	if timeout := runner.cfg.ResourcesConfig.ContainerStartTimeout(); timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+	return runner.dockerClient.WaitContainerStart(ctx, id)
+}
+
+func (runner *Runner) updaterContainerConfig(imageRef string, latestRefs store.ImageRefs) clients.DockerContainerConfig {
+	cfg := runner.configSnapshot()
+	return clients.DockerContainerConfig{
+		Name:  config.DockerUpdaterContainerName,
+		Image: imageRef,
+		Cmd:   []string{config.DefaultFortaNodeBinaryPath, "updater"},
+		Env: cfg.AddRemoteConfigEnv(cfg.Network.Proxy.AddEnv(map[string]string{
+			config.EnvDevelopment:    strconv.FormatBool(cfg.Development),
+			config.EnvReleaseInfo:    latestRefs.ReleaseInfo.String(),
+			config.EnvLogFormat:      cfg.Log.Format,
+			config.EnvReleaseChannel: cfg.AutoUpdate.ReleaseChannel(),
+		})),
+		Volumes: map[string]string{
+			cfg.FortaDir: config.DefaultContainerFortaDirPath,
+		},
+		Ports: map[string]string{
+			runner.updaterPort(): config.DefaultContainerPort,
+			"":                   config.DefaultHealthPort, // random host port
+		},
+		DialHost:    true,
+		MaxLogSize:  cfg.Log.MaxLogSize,
+		MaxLogFiles: cfg.Log.MaxLogFiles,
+		LogDriver:   cfg.Log.LogDriver,
+		LogOpts:     cfg.Log.LogOpts,
+		Labels:      cfg.Docker.AddLabels(nil),
+	}
+}
+
+// updaterPort returns the host port of the updater API.
+func (runner *Runner) updaterPort() string {
+	if len(runner.cfg.RunnerConfig.UpdaterPort) == 0 {
+		return config.DefaultContainerPort
+	}
+	return runner.cfg.RunnerConfig.UpdaterPort
+}
+
+func (runner *Runner) supervisorContainerConfig(imageRef string, latestRefs store.ImageRefs) clients.DockerContainerConfig {
+	cfg := runner.configSnapshot()
+	return clients.DockerContainerConfig{
 		Name:  config.DockerSupervisorContainerName,
-		Image: supervisorRef,
+		Image: imageRef,
 		Cmd:   []string{config.DefaultFortaNodeBinaryPath, "supervisor"},
-		Env: map[string]string{
-			// supervisor needs to know and mount the forta dir on the host os
-			config.EnvHostFortaDir: runner.cfg.FortaDir,
-			config.EnvReleaseInfo:  latestRefs.ReleaseInfo.String(),
-		},
+		Env: cfg.AddRemoteConfigEnv(cfg.Signer.AddEnv(cfg.Nats.AddEnv(cfg.AddAgentEnvRefs(cfg.Network.Proxy.AddEnv(map[string]string{
+			// supervisor needs to know and mount the forta dir and the docker socket on the host os
+			config.EnvHostFortaDir:     cfg.FortaDir,
+			config.EnvHostDockerSocket: cfg.Docker.HostSocketPath(),
+			config.EnvRunnerHealthPort: cfg.Health.Port(),
+			config.EnvDevelopment:      strconv.FormatBool(cfg.Development),
+			config.EnvReleaseInfo:      latestRefs.ReleaseInfo.String(),
+			config.EnvLogFormat:        cfg.Log.Format,
+		}))))),
 		Volumes: map[string]string{
 			// give access to host docker
-			"/var/run/docker.sock": "/var/run/docker.sock",
-			runner.cfg.FortaDir:    config.DefaultContainerFortaDirPath,
+			cfg.Docker.HostSocketPath(): config.DefaultDockerSocketPath,
+			cfg.FortaDir:                config.DefaultContainerFortaDirPath,
 		},
 		Ports: map[string]string{
 			"": config.DefaultHealthPort, // random host port
 		},
 		Files: map[string][]byte{
-			"passphrase": []byte(runner.cfg.Passphrase),
+			"passphrase": []byte(cfg.Passphrase),
 		},
 		DialHost:    true,
-		MaxLogSize:  runner.cfg.Log.MaxLogSize,
-		MaxLogFiles: runner.cfg.Log.MaxLogFiles,
-	})
-	if err != nil {
-		logger.WithError(err).Errorf("failed to start the supervisor")
-		return err
-	}
-	runner.supervisorContainer = sc
-
-	if err := runner.dockerClient.WaitContainerStart(runner.ctx, runner.supervisorContainer.ID); err != nil {
-		logger.WithError(err).Error("error while waiting for supervisor start")
-		return err
+		MaxLogSize:  cfg.Log.MaxLogSize,
+		MaxLogFiles: cfg.Log.MaxLogFiles,
+		LogDriver:   cfg.Log.LogDriver,
+		LogOpts:     cfg.Log.LogOpts,
+		Labels:      cfg.Docker.AddLabels(nil),
 	}
-	return nil
 }
 
 func (runner *Runner) keepContainersAlive() {
-	ticker := time.NewTicker(time.Second * 10)
 	for {
 		select {
-		case <-ticker.C:
+		case <-runner.livenessTicker.C:
 			if err := runner.doKeepContainersAlive(); err != nil {
 				log.WithError(err).Error("failed while keeping containers alive")
 			}
@@ -360,9 +785,24 @@ func (runner *Runner) doKeepContainersAlive() error {
 	runner.containerMu.Lock()
 	defer runner.containerMu.Unlock()
 
+	if !runner.checkDaemon() {
+		// try again at the next tick
+		return nil
+	}
+
 	if runner.supervisorContainer != nil {
 		container, err := runner.dockerClient.GetContainerByID(runner.ctx, runner.supervisorContainer.ID)
-		if err == nil && container.State == "exited" {
+		switch {
+		case errors.Is(err, clients.ErrContainerNotFound):
+			if err := runner.recreateContainer(&runner.supervisorContainer); err != nil {
+				log.WithError(err).Error("failed to recreate the supervisor")
+			}
+
+		case err != nil:
+			// try again at the next tick
+			log.WithError(err).Warn("failed to get the supervisor container")
+
+		case container.State == "exited":
 			containerDetails, err := runner.dockerClient.InspectContainer(runner.ctx, container.ID)
 			if err != nil {
 				return err
@@ -373,16 +813,42 @@ func (runner *Runner) doKeepContainersAlive() error {
 				return nil
 			}
 			runner.dockerClient.StartContainer(runner.ctx, runner.supervisorContainer.Config)
+			runner.countRestart(config.DockerSupervisorContainerName)
 		}
 	}
 
 	// only keep updater up if auto-update is enabled
-	if runner.updaterContainer != nil && !runner.cfg.AutoUpdate.Disable {
+	if runner.updaterContainer != nil && !runner.cfg.UpdatesDisabled() {
 		container, err := runner.dockerClient.GetContainerByID(runner.ctx, runner.updaterContainer.ID)
-		if err == nil && container.State == "exited" {
+		switch {
+		case errors.Is(err, clients.ErrContainerNotFound):
+			if err := runner.recreateContainer(&runner.updaterContainer); err != nil {
+				log.WithError(err).Error("failed to recreate the updater")
+			}
+
+		case err != nil:
+			log.WithError(err).Warn("failed to get the updater container")
+
+		case container.State == "exited":
 			runner.dockerClient.StartContainer(runner.ctx, runner.updaterContainer.Config)
+			runner.countRestart(config.DockerUpdaterContainerName)
 		}
 	}
 
 	return nil
 }
+
+// recreateContainer creates the container again from the stored config if it was
+// removed outside of the node and replaces the stored container with the new one.
+func (runner *Runner) recreateContainer(container **clients.DockerContainer) error {
+	logger := log.WithField("name", (*container).Name).WithField("id", (*container).ID)
+	logger.Warn("container was removed - recreating")
+	newContainer, err := runner.dockerClient.StartContainer(runner.ctx, (*container).Config)
+	if err != nil {
+		return fmt.Errorf("failed to recreate the %s container: %v", (*container).Name, err)
+	}
+	logger.WithField("newId", newContainer.ID).Info("recreated container")
+	runner.countRestart((*container).Name)
+	*container = newContainer
+	return nil
+}
@@ -0,0 +1,36 @@
+package runner
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/forta-network/forta-node/clients"
+	mock_clients "github.com/forta-network/forta-node/clients/mocks"
+	"github.com/forta-network/forta-node/config"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStop(t *testing.T) {
+	r := require.New(t)
+
+	dockerClient := mock_clients.NewMockDockerClient(gomock.NewController(t))
+	runner := &Runner{
+		ctx:                 context.Background(),
+		dockerClient:        dockerClient,
+		updaterContainer:    &clients.DockerContainer{ID: "updater1"},
+		supervisorContainer: &clients.DockerContainer{ID: "supervisor1"},
+	}
+
+	// no timeout: interrupted
+	dockerClient.EXPECT().InterruptContainer(gomock.Any(), "updater1").Return(nil)
+	dockerClient.EXPECT().InterruptContainer(gomock.Any(), "supervisor1").Return(nil)
+	r.NoError(runner.Stop())
+
+	// timeout: stopped gracefully
+	runner.cfg = config.Config{ResourcesConfig: config.ResourcesConfig{ContainerStopTimeoutSeconds: 60}}
+	dockerClient.EXPECT().TerminateContainer(gomock.Any(), "updater1", time.Minute).Return(nil)
+	dockerClient.EXPECT().TerminateContainer(gomock.Any(), "supervisor1", time.Minute).Return(nil)
+	r.NoError(runner.Stop())
+}
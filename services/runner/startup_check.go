@@ -0,0 +1,293 @@
+package runner
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/ethereum/go-ethereum/accounts/keystore"
+	"github.com/forta-network/forta-core-go/clients/health"
+	"github.com/forta-network/forta-node/clients"
+	"github.com/forta-network/forta-node/clients/alertapi"
+	"github.com/forta-network/forta-node/clients/ethclient"
+	"github.com/forta-network/forta-node/clients/signer"
+	"github.com/forta-network/forta-node/config"
+	"github.com/forta-network/forta-node/store"
+	log "github.com/sirupsen/logrus"
+)
+
+const dependencyCheckTimeout = time.Second * 30
+
+// dependencyCheck is a start-up check which is repeated periodically after start-up.
+type dependencyCheck struct {
+	Name string
+	// Required checks fail the start-up.
+	Required bool
+	Check    func(ctx context.Context) error
+}
+
+// StartupCheckError is returned when a required start-up check fails.
+type StartupCheckError struct {
+	Check string
+	Cause error
+}
+
+func (e *StartupCheckError) Error() string {
+	return fmt.Sprintf("%s check failed: %v", e.Check, e.Cause)
+}
+
+func (e *StartupCheckError) Unwrap() error {
+	return e.Cause
+}
+
+type dependencyCheckResult struct {
+	Err     error
+	Latency time.Duration
+}
+
+func (runner *Runner) dependencyChecks() []*dependencyCheck {
+	checks := []*dependencyCheck{
+		{
+			Name:     "docker",
+			Required: true,
+			Check: func(ctx context.Context) error {
+				if _, err := runner.dockerClient.GetContainers(ctx); err != nil {
+					return fmt.Errorf("get containers: %v", err)
+				}
+				return nil
+			},
+		},
+		{
+			Name:     "forta-dir",
+			Required: true,
+			Check: func(ctx context.Context) error {
+				return checkFortaDir(runner.cfg.FortaDir, runner.cfg.RunnerConfig.FortaDirUID)
+			},
+		},
+		{
+			Name:     "scan-api",
+			Required: true,
+			Check: func(ctx context.Context) error {
+				return runner.testChainAPI(ctx, runner.scanAPILimiter, runner.cfg.Scan.JsonRpc)
+			},
+		},
+	}
+	if runner.cfg.Scan.ArchiveEnabled() {
+		checks = append(checks, &dependencyCheck{
+			Name:     "scan-archive-api",
+			Required: true,
+			Check: func(ctx context.Context) error {
+				return runner.testChainAPI(ctx, runner.archiveAPILimiter, runner.cfg.Scan.ArchiveJsonRpc)
+			},
+		})
+	}
+	if runner.cfg.Trace.Enabled {
+		checks = append(checks, &dependencyCheck{
+			Name:     "trace-api",
+			Required: true,
+			Check: func(ctx context.Context) error {
+				return runner.testChainAPI(ctx, runner.traceAPILimiter, runner.cfg.Trace.JsonRpc)
+			},
+		})
+	}
+	if !runner.cfg.Publish.SkipPublish && !runner.cfg.OfflineSkip("batch-api") {
+		checks = append(checks, &dependencyCheck{
+			Name: "batch-api",
+			Check: func(ctx context.Context) error {
+				return alertapi.CheckReachable(ctx, runner.fixTestRpcUrl(runner.cfg.Publish.APIURL), alertapi.StartupCheckRetryConfig)
+			},
+		})
+	}
+	if runner.cfg.RunnerConfig.MinFreeDiskBytes > 0 {
+		checks = append(checks, &dependencyCheck{
+			Name:  "disk",
+			Check: runner.checkDiskSpace,
+		})
+	}
+	if len(runner.cfg.Registry.ContainerRegistry) > 0 && !runner.cfg.OfflineSkip("container-registry") {
+		checks = append(checks, &dependencyCheck{
+			Name:     "container-registry",
+			Required: true,
+			Check: func(ctx context.Context) error {
+				return clients.PingRegistry(ctx, runner.cfg.Registry.ContainerRegistry, runner.registryAuth)
+			},
+		})
+	}
+	if runner.registrationCheckEnabled() {
+		checks = append(checks, &dependencyCheck{
+			Name:     "registration",
+			Required: runner.cfg.RunnerConfig.StrictRegistration,
+			Check:    runner.checkRegistration,
+		})
+	}
+	if !runner.cfg.OfflineSkip("ipfs") {
+		checks = append(checks, &dependencyCheck{
+			Name: "ipfs",
+			Check: func(ctx context.Context) error {
+				return checkReachable(ctx, runner.fixTestRpcUrl(runner.cfg.Registry.IPFS.GatewayURL))
+			},
+		})
+	}
+	return checks
+}
+
+// testChainAPI tests the API and checks if it serves the configured chain so that the node does
+// not scan another chain because of a wrong URL.
+func (runner *Runner) testChainAPI(ctx context.Context, limiter *ethclient.Limiter, cfg config.JsonRpcConfig) error {
+	rawurl := runner.fixTestRpcUrl(cfg.Url)
+	if err := ethclient.TestAPI(ctx, limiter, rawurl); err != nil {
+		return err
+	}
+	if runner.cfg.Scan.SkipChainIDCheck {
+		return nil
+	}
+	return ethclient.CheckChainID(ctx, limiter, rawurl, cfg.Headers, runner.cfg.ChainID)
+}
+
+// checkReachable checks if the server responds without a server error.
+func checkReachable(ctx context.Context, rawurl string) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, rawurl, nil)
+	if err != nil {
+		return err
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+	if resp.StatusCode >= 500 {
+		return fmt.Errorf("unexpected status code %d", resp.StatusCode)
+	}
+	return nil
+}
+
+// runDependencyChecks runs all dependency checks, stores the results and returns
+// the error from the first failing required check.
+func (runner *Runner) runDependencyChecks() error {
+	var requiredErr error
+	for _, check := range runner.dependencyChecks() {
+		ctx, cancel := context.WithTimeout(runner.ctx, dependencyCheckTimeout)
+		start := time.Now()
+		err := check.Check(ctx)
+		cancel()
+		result := &dependencyCheckResult{Err: err, Latency: time.Since(start)}
+
+		runner.dependencyMu.Lock()
+		runner.dependencyResults[check.Name] = result
+		runner.dependencyMu.Unlock()
+
+		logger := log.WithFields(log.Fields{
+			"check":   check.Name,
+			"latency": result.Latency.String(),
+		})
+		if err == nil {
+			logger.Debug("dependency check successful")
+			continue
+		}
+		logger.WithError(err).Warn("dependency check failed")
+		if check.Required && requiredErr == nil {
+			requiredErr = &StartupCheckError{Check: check.Name, Cause: err}
+		}
+	}
+	return requiredErr
+}
+
+// recheckDependencies repeats the start-up checks to keep the results up to date in the health report.
+func (runner *Runner) recheckDependencies() {
+	interval := time.Duration(runner.cfg.RunnerConfig.DependencyCheckIntervalSeconds) * time.Second
+	if interval == 0 {
+		return
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-runner.ctx.Done():
+			return
+		case <-ticker.C:
+			runner.runDependencyChecks()
+		}
+	}
+}
+
+func (runner *Runner) dependencyReports() (reports health.Reports) {
+	runner.dependencyMu.RLock()
+	defer runner.dependencyMu.RUnlock()
+	for _, check := range runner.dependencyChecks() {
+		result, ok := runner.dependencyResults[check.Name]
+		if !ok {
+			continue
+		}
+		report := &health.Report{
+			Name:    fmt.Sprintf("forta.dependency.%s", check.Name),
+			Status:  health.StatusOK,
+			Details: fmt.Sprintf("ok (%s)", result.Latency.Round(time.Millisecond)),
+		}
+		if result.Err != nil {
+			report.Status = health.StatusFailing
+			report.Details = fmt.Sprintf("%v (%s)", result.Err, result.Latency.Round(time.Millisecond))
+		}
+		reports = append(reports, report)
+	}
+	return
+}
+
+// checkScannerKey decrypts the scanner key with the passphrase without changing the key dir.
+func (runner *Runner) checkScannerKey() error {
+	address, err := store.NewScannerKeyStore(runner.cfg.KeyDirPath).Verify(runner.cfg.Passphrase)
+	switch {
+	case errors.Is(err, keystore.ErrDecrypt):
+		return fmt.Errorf("the passphrase can not decrypt the scanner key in %s - please check the passphrase", runner.cfg.KeyDirPath)
+	case errors.Is(err, store.ErrNoKey), errors.Is(err, store.ErrMultipleKeys):
+		return fmt.Errorf("%v in %s - please create or import the scanner key with 'forta keys'", err, runner.cfg.KeyDirPath)
+	case err != nil:
+		return err
+	}
+	log.WithField("address", address.Hex()).Info("scanner key check successful")
+	return nil
+}
+
+// batchSigningCheckEnabled tells if the batches are signed and published.
+func batchSigningCheckEnabled(cfg config.Config) bool {
+	return !cfg.LocalModeConfig.Enable && !cfg.Publish.SkipPublish
+}
+
+// CheckBatchSigningKey makes sure that the publisher will be able to sign the batches
+// with the configured signer instead of failing after the node starts. The returned error
+// tells if the remote signer was temporarily unavailable.
+func CheckBatchSigningKey(cfg config.Config) error {
+	if !batchSigningCheckEnabled(cfg) {
+		return nil
+	}
+	batchSigner, err := signer.New(cfg.Signer, cfg.KeyDirPath, func() (string, error) {
+		return cfg.Passphrase, nil
+	})
+	if err != nil {
+		return fmt.Errorf("failed to create the signer for the batches: %v", err)
+	}
+	payload := []byte("forta batch signing check")
+	signature, err := alertapi.SignBatchPayload(batchSigner, payload)
+	if signer.IsUnavailable(err) {
+		return err
+	}
+	if err != nil {
+		return fmt.Errorf("failed to sign with the %s signer: %v", cfg.Signer.SignerType(), err)
+	}
+	if err := alertapi.VerifyBatchSignature(payload, signature.Signature, batchSigner.Address().Hex()); err != nil {
+		return fmt.Errorf("failed to verify the batch signature of the scanner key: %v", err)
+	}
+	return nil
+}
+
+// checkBatchSigningKey checks the batch signing key like a real start does. The publisher keeps
+// the batches until the signer is reachable so an unavailable signer does not fail the check.
+func (runner *Runner) checkBatchSigningKey(ctx context.Context) error {
+	err := CheckBatchSigningKey(runner.cfg)
+	if signer.IsUnavailable(err) {
+		log.WithError(err).Warn("the remote signer is not reachable right now")
+		return nil
+	}
+	return err
+}
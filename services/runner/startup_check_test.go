@@ -0,0 +1,287 @@
+package runner
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"path"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/accounts/keystore"
+	"github.com/forta-network/forta-core-go/clients/health"
+	"github.com/forta-network/forta-node/clients/ethclient"
+	mock_clients "github.com/forta-network/forta-node/clients/mocks"
+	"github.com/forta-network/forta-node/config"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+)
+
+const testKeyPassphrase = "passphrase1"
+
+func testRPCServer() *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"jsonrpc":"2.0","id":1,"result":"0x1"}`))
+	}))
+}
+
+func testDependencyRunner(t *testing.T, cfg config.Config) (*Runner, *mock_clients.MockDockerClient) {
+	dockerClient := mock_clients.NewMockDockerClient(gomock.NewController(t))
+	cfg.ChainID = 1 // served by the test rpc server
+	cfg.FortaDir = t.TempDir()
+	cfg.KeyDirPath = path.Join(cfg.FortaDir, config.DefaultKeysDirName)
+	cfg.Passphrase = testKeyPassphrase
+	_, err := keystore.StoreKey(cfg.KeyDirPath, testKeyPassphrase, keystore.LightScryptN, keystore.LightScryptP)
+	require.NoError(t, err)
+	return &Runner{
+		ctx:               context.Background(),
+		cfg:               cfg,
+		dockerClient:      dockerClient,
+		dependencyResults: make(map[string]*dependencyCheckResult),
+	}, dockerClient
+}
+
+func reportsByName(reports health.Reports) map[string]*health.Report {
+	m := make(map[string]*health.Report)
+	for _, report := range reports {
+		m[report.Name] = report
+	}
+	return m
+}
+
+func TestDependencyChecks(t *testing.T) {
+	r := require.New(t)
+
+	rpcServer := testRPCServer()
+	defer rpcServer.Close()
+	apiServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+	}))
+	defer apiServer.Close()
+	ipfsServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	ipfsServer.Close()
+
+	var cfg config.Config
+	cfg.Scan.JsonRpc.Url = rpcServer.URL
+	cfg.Publish.APIURL = apiServer.URL
+	cfg.Registry.IPFS.GatewayURL = ipfsServer.URL
+
+	runner, dockerClient := testDependencyRunner(t, cfg)
+	dockerClient.EXPECT().GetContainers(gomock.Any()).Return(nil, nil)
+
+	// the ipfs check is not required for start-up
+	r.NoError(runner.doStartUpCheck())
+
+	reports := reportsByName(runner.dependencyReports())
+	r.Len(reports, 5)
+	r.Equal(health.StatusOK, reports["forta.dependency.docker"].Status)
+	r.Equal(health.StatusOK, reports["forta.dependency.forta-dir"].Status)
+	r.Equal(health.StatusOK, reports["forta.dependency.scan-api"].Status)
+	r.Equal(health.StatusOK, reports["forta.dependency.batch-api"].Status)
+	r.Equal(health.StatusFailing, reports["forta.dependency.ipfs"].Status)
+}
+
+func TestDependencyChecks_RequiredFailure(t *testing.T) {
+	r := require.New(t)
+
+	rpcServer := testRPCServer()
+	defer rpcServer.Close()
+
+	var cfg config.Config
+	cfg.Scan.JsonRpc.Url = rpcServer.URL
+	cfg.Publish.SkipPublish = true
+	cfg.Registry.IPFS.GatewayURL = rpcServer.URL
+
+	runner, dockerClient := testDependencyRunner(t, cfg)
+	dockerClient.EXPECT().GetContainers(gomock.Any()).Return(nil, errors.New("docker is down"))
+
+	err := runner.doStartUpCheck()
+	var checkErr *StartupCheckError
+	r.ErrorAs(err, &checkErr)
+	r.Equal("docker", checkErr.Check)
+	r.EqualError(checkErr.Cause, "get containers: docker is down")
+	r.Equal("docker check failed: get containers: docker is down", err.Error())
+
+	reports := reportsByName(runner.dependencyReports())
+	r.Len(reports, 4)
+	r.Equal(health.StatusFailing, reports["forta.dependency.docker"].Status)
+	r.Contains(reports["forta.dependency.docker"].Details, "docker is down")
+	r.Equal(health.StatusOK, reports["forta.dependency.scan-api"].Status)
+}
+
+func TestDependencyChecks_TraceDisabled(t *testing.T) {
+	r := require.New(t)
+
+	rpcServer := testRPCServer()
+	defer rpcServer.Close()
+
+	var traceCalls int64
+	traceServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt64(&traceCalls, 1)
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"jsonrpc":"2.0","id":1,"result":"0x1"}`))
+	}))
+	defer traceServer.Close()
+
+	var cfg config.Config
+	cfg.Scan.JsonRpc.Url = rpcServer.URL
+	cfg.Trace.JsonRpc.Url = traceServer.URL
+	cfg.Publish.SkipPublish = true
+	cfg.Registry.IPFS.GatewayURL = rpcServer.URL
+
+	runner, dockerClient := testDependencyRunner(t, cfg)
+	dockerClient.EXPECT().GetContainers(gomock.Any()).Return(nil, nil).Times(2)
+
+	r.NoError(runner.doStartUpCheck())
+	r.Zero(atomic.LoadInt64(&traceCalls))
+	_, ok := reportsByName(runner.dependencyReports())["forta.dependency.trace-api"]
+	r.False(ok)
+
+	runner.cfg.Trace.Enabled = true
+	r.NoError(runner.doStartUpCheck())
+	r.NotZero(atomic.LoadInt64(&traceCalls))
+}
+
+func TestDependencyChecks_Archive(t *testing.T) {
+	r := require.New(t)
+
+	rpcServer := testRPCServer()
+	defer rpcServer.Close()
+	archiveServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadGateway)
+	}))
+	defer archiveServer.Close()
+
+	var cfg config.Config
+	cfg.Scan.JsonRpc.Url = rpcServer.URL
+	cfg.Publish.SkipPublish = true
+	cfg.Registry.IPFS.GatewayURL = rpcServer.URL
+
+	runner, dockerClient := testDependencyRunner(t, cfg)
+	dockerClient.EXPECT().GetContainers(gomock.Any()).Return(nil, nil).Times(2)
+
+	r.NoError(runner.doStartUpCheck())
+	_, ok := reportsByName(runner.dependencyReports())["forta.dependency.scan-archive-api"]
+	r.False(ok)
+
+	// the archive api is required when it is configured
+	runner.cfg.Scan.ArchiveJsonRpc.Url = archiveServer.URL
+	err := runner.doStartUpCheck()
+	var checkErr *StartupCheckError
+	r.ErrorAs(err, &checkErr)
+	r.Equal("scan-archive-api", checkErr.Check)
+	r.Equal(health.StatusOK, reportsByName(runner.dependencyReports())["forta.dependency.scan-api"].Status)
+}
+
+func TestDependencyChecks_ChainID(t *testing.T) {
+	r := require.New(t)
+
+	rpcServer := testRPCServer()
+	defer rpcServer.Close()
+	polygonServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"jsonrpc":"2.0","id":1,"result":"0x89"}`))
+	}))
+	defer polygonServer.Close()
+
+	var cfg config.Config
+	cfg.Scan.JsonRpc.Url = polygonServer.URL
+	cfg.Trace.JsonRpc.Url = rpcServer.URL
+	cfg.Trace.Enabled = true
+	cfg.Publish.SkipPublish = true
+	cfg.Registry.IPFS.GatewayURL = rpcServer.URL
+
+	runner, dockerClient := testDependencyRunner(t, cfg)
+	dockerClient.EXPECT().GetContainers(gomock.Any()).Return(nil, nil).Times(4)
+
+	err := runner.doStartUpCheck()
+	r.ErrorIs(err, ethclient.ErrChainIDMismatch)
+	var checkErr *StartupCheckError
+	r.ErrorAs(err, &checkErr)
+	r.Equal("scan-api", checkErr.Check)
+	r.Contains(err.Error(), "chain id 1")
+	r.Contains(err.Error(), "chain id 137")
+
+	// the trace api is checked too
+	runner.cfg.Scan.JsonRpc.Url = rpcServer.URL
+	runner.cfg.Trace.JsonRpc.Url = polygonServer.URL
+	err = runner.doStartUpCheck()
+	r.ErrorIs(err, ethclient.ErrChainIDMismatch)
+	r.ErrorAs(err, &checkErr)
+	r.Equal("trace-api", checkErr.Check)
+
+	runner.cfg.ChainID = 137
+	runner.cfg.Scan.JsonRpc.Url = polygonServer.URL
+	r.NoError(runner.doStartUpCheck())
+
+	runner.cfg.ChainID = 1
+	runner.cfg.Scan.SkipChainIDCheck = true
+	r.NoError(runner.doStartUpCheck())
+}
+
+func TestDependencyChecks_Offline(t *testing.T) {
+	r := require.New(t)
+
+	var cfg config.Config
+	cfg.Offline = true
+	cfg.Scan.JsonRpc.Url = "http://localhost:8545"
+	cfg.Publish.APIURL = "http://localhost:8080"
+	cfg.Registry.IPFS.GatewayURL = "http://localhost:5001"
+
+	runner, _ := testDependencyRunner(t, cfg)
+	for _, check := range runner.dependencyChecks() {
+		r.NotEqual("batch-api", check.Name)
+		r.NotEqual("ipfs", check.Name)
+	}
+}
+
+func TestDependencyChecks_ContainerRegistry(t *testing.T) {
+	r := require.New(t)
+
+	rpcServer := testRPCServer()
+	defer rpcServer.Close()
+	registryServer := httptest.NewServer(http.NotFoundHandler())
+	defer registryServer.Close()
+
+	var cfg config.Config
+	cfg.Scan.JsonRpc.Url = rpcServer.URL
+	cfg.Publish.SkipPublish = true
+	cfg.Registry.IPFS.GatewayURL = rpcServer.URL
+	cfg.Registry.ContainerRegistry = strings.TrimPrefix(registryServer.URL, "http://")
+
+	runner, dockerClient := testDependencyRunner(t, cfg)
+	dockerClient.EXPECT().GetContainers(gomock.Any()).Return(nil, nil)
+
+	var checkErr *StartupCheckError
+	r.ErrorAs(runner.doStartUpCheck(), &checkErr)
+	r.Equal("container-registry", checkErr.Check)
+	r.ErrorContains(checkErr, "is "+cfg.Registry.ContainerRegistry+" a container registry?")
+}
+
+func TestStartUpCheck_ScannerKey(t *testing.T) {
+	r := require.New(t)
+
+	runner, dockerClient := testDependencyRunner(t, config.Config{})
+	r.NoError(runner.checkScannerKey())
+
+	// the docker and the other checks are not reached
+	runner.cfg.Passphrase = "wrong"
+	var checkErr *StartupCheckError
+	r.ErrorAs(runner.doStartUpCheck(), &checkErr)
+	r.Equal("keystore", checkErr.Check)
+	r.ErrorContains(checkErr, "please check the passphrase")
+
+	runner.cfg.KeyDirPath = t.TempDir()
+	r.ErrorAs(runner.doStartUpCheck(), &checkErr)
+	r.ErrorContains(checkErr, "no scanner key found")
+
+	// the check can be skipped
+	runner.cfg.RunnerConfig.SkipKeyCheck = true
+	dockerClient.EXPECT().GetContainers(gomock.Any()).Return(nil, nil)
+	runner.cfg.Scan.JsonRpc.Url = "http://localhost:1"
+	r.ErrorAs(runner.doStartUpCheck(), &checkErr)
+	r.NotEqual("keystore", checkErr.Check)
+}
@@ -0,0 +1,200 @@
+package runner
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+
+	"github.com/docker/docker/api/types"
+	"github.com/forta-network/forta-core-go/utils"
+	"github.com/forta-network/forta-node/clients"
+	"github.com/forta-network/forta-node/config"
+	"github.com/forta-network/forta-node/store"
+	log "github.com/sirupsen/logrus"
+)
+
+// loadState reads the last saved state. A missing or unreadable state is ignored so that the runner
+// can start from scratch.
+func (runner *Runner) loadState() *store.RunnerState {
+	if runner.stateStore == nil {
+		return nil
+	}
+	state, err := runner.stateStore.Get()
+	if err != nil {
+		log.WithError(err).Warn("failed to load the runner state - ignoring")
+		return nil
+	}
+	return state
+}
+
+// saveState saves the current images. The container lock should be held by the caller.
+func (runner *Runner) saveState() {
+	if runner.stateStore == nil {
+		return
+	}
+	var nodeImages []string
+	if prevState := runner.loadState(); prevState != nil {
+		nodeImages = prevState.NodeImages
+	}
+	if err := runner.stateStore.Put(store.RunnerState{
+		Updater:     runner.currentUpdaterImg,
+		Supervisor:  runner.currentSupervisorImg,
+		ReleaseInfo: runner.currentRelease,
+		NodeImages:  addNodeImages(nodeImages, runner.currentUpdaterImg, runner.currentSupervisorImg),
+	}); err != nil {
+		log.WithError(err).Warn("failed to save the runner state")
+	}
+}
+
+// addNodeImages adds the digests of the image refs to the node images.
+func addNodeImages(nodeImages []string, refs ...string) []string {
+	known := make(map[string]bool)
+	for _, digest := range nodeImages {
+		known[digest] = true
+	}
+	for digest := range imageDigests(refs) {
+		if !known[digest] {
+			nodeImages = append(nodeImages, digest)
+		}
+	}
+	sort.Strings(nodeImages)
+	return nodeImages
+}
+
+// forgetNodeImages removes the digests of the pruned images from the saved state.
+func (runner *Runner) forgetNodeImages(digests map[string]bool) {
+	state := runner.loadState()
+	if state == nil || len(digests) == 0 {
+		return
+	}
+	var nodeImages []string
+	for _, digest := range state.NodeImages {
+		if !digests[digest] {
+			nodeImages = append(nodeImages, digest)
+		}
+	}
+	state.NodeImages = nodeImages
+	if err := runner.stateStore.Put(*state); err != nil {
+		log.WithError(err).Warn("failed to save the runner state")
+	}
+}
+
+func stateRefs(state *store.RunnerState) store.ImageRefs {
+	return store.ImageRefs{
+		Supervisor:  state.Supervisor,
+		Updater:     state.Updater,
+		ReleaseInfo: state.ReleaseInfo,
+	}
+}
+
+// adoptContainers takes over the containers left running by the previous runner process if they
+// still run the images from the saved state.
+func (runner *Runner) adoptContainers(state *store.RunnerState) bool {
+	if state == nil || len(state.Supervisor) == 0 {
+		return false
+	}
+	disabled := runner.cfg.UpdatesDisabled()
+	switch {
+	case disabled && state.Supervisor != runner.imgStore.EmbeddedImageRefs().Supervisor:
+		return false
+	case !disabled && len(state.Updater) == 0:
+		return false
+	}
+
+	logger := log.WithField("supervisor", state.Supervisor).WithField("updater", state.Updater)
+	supervisor, err := runner.findRunningContainer(config.DockerSupervisorContainerName, state.Supervisor)
+	if err != nil {
+		logger.WithError(err).Info("not adopting the supervisor container")
+		return false
+	}
+	var updater *types.Container
+	if disabled {
+		_, err = runner.dockerClient.GetContainerByName(runner.ctx, config.DockerUpdaterContainerName)
+		if !errors.Is(err, clients.ErrContainerNotFound) {
+			logger.Info("not adopting the containers - found an updater while auto-updates are disabled")
+			return false
+		}
+	} else {
+		updater, err = runner.findRunningContainer(config.DockerUpdaterContainerName, state.Updater)
+		if err != nil {
+			logger.WithError(err).Info("not adopting the updater container")
+			return false
+		}
+	}
+
+	runner.containerMu.Lock()
+	defer runner.containerMu.Unlock()
+
+	refs := stateRefs(state)
+	runner.supervisorContainer = &clients.DockerContainer{
+		Name:      config.DockerSupervisorContainerName,
+		ID:        supervisor.ID,
+		ImageHash: supervisor.ImageID,
+		Config:    runner.supervisorContainerConfig(supervisor.Image, refs),
+	}
+	runner.currentSupervisorImg = state.Supervisor
+	if updater != nil {
+		runner.updaterContainer = &clients.DockerContainer{
+			Name:      config.DockerUpdaterContainerName,
+			ID:        updater.ID,
+			ImageHash: updater.ImageID,
+			Config:    runner.updaterContainerConfig(updater.Image, refs),
+		}
+		runner.currentUpdaterImg = state.Updater
+	}
+	runner.currentRelease = state.ReleaseInfo
+	logger.Info("adopted the running containers")
+	return true
+}
+
+// findRunningContainer finds the container by name and makes sure that it is running the image.
+func (runner *Runner) findRunningContainer(name, imageRef string) (*types.Container, error) {
+	container, err := runner.dockerClient.GetContainerByName(runner.ctx, name)
+	if err != nil {
+		return nil, err
+	}
+	if container.State != "running" {
+		return nil, &containerStateError{State: container.State}
+	}
+	if container.Image != imageRef && container.Image != runner.containerImageRef(imageRef) {
+		return nil, fmt.Errorf("container image '%s' does not match '%s'", container.Image, imageRef)
+	}
+	return container, nil
+}
+
+// containerImageRef returns the ref the containers are started with for the given image ref.
+func (runner *Runner) containerImageRef(imageRef string) string {
+	if runner.cfg.Development {
+		return imageRef
+	}
+	fixedRef, err := utils.ValidateDiscoImageRef(runner.cfg.Registry.ContainerRegistry, imageRef)
+	if err != nil {
+		return imageRef
+	}
+	return fixedRef
+}
+
+func hasSavedRelease(state *store.RunnerState) bool {
+	return state != nil && len(state.Updater) > 0 && len(state.Supervisor) > 0
+}
+
+// startSavedRelease starts the containers from the saved state so that the release that was running
+// before the restart is not replaced again.
+func (runner *Runner) startSavedRelease(state *store.RunnerState) error {
+	runner.containerMu.Lock()
+	defer runner.containerMu.Unlock()
+
+	refs := stateRefs(state)
+	logger := log.WithField("supervisor", refs.Supervisor).WithField("updater", refs.Updater)
+	if err := runner.replaceUpdater(logger, refs); err != nil {
+		return err
+	}
+	runner.currentUpdaterImg = refs.Updater
+	if err := runner.replaceSupervisor(logger, refs); err != nil {
+		return err
+	}
+	runner.currentSupervisorImg = refs.Supervisor
+	runner.currentRelease = refs.ReleaseInfo
+	runner.saveState()
+	return nil
+}
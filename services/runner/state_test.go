@@ -0,0 +1,165 @@
+package runner
+
+import (
+	"context"
+	"os"
+	"path"
+	"testing"
+
+	"github.com/docker/docker/api/types"
+	"github.com/forta-network/forta-node/clients"
+	mock_clients "github.com/forta-network/forta-node/clients/mocks"
+	"github.com/forta-network/forta-node/config"
+	"github.com/forta-network/forta-node/store"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+)
+
+type testImageStore struct {
+	store.FortaImageStore
+	embedded store.ImageRefs
+}
+
+func (imgStore *testImageStore) EmbeddedImageRefs() store.ImageRefs {
+	return imgStore.embedded
+}
+
+func testStateRunner(t *testing.T) (*Runner, *mock_clients.MockDockerClient, *mock_clients.MockDockerClient) {
+	ctrl := gomock.NewController(t)
+	dockerClient := mock_clients.NewMockDockerClient(ctrl)
+	globalClient := mock_clients.NewMockDockerClient(ctrl)
+	dir := t.TempDir()
+	return &Runner{
+		ctx:          context.Background(),
+		cfg:          config.Config{Development: true, FortaDir: dir},
+		imgStore:     &testImageStore{embedded: store.ImageRefs{Updater: "updater-embedded"}},
+		dockerClient: dockerClient,
+		globalClient: globalClient,
+		stateStore:   store.NewRunnerStateStore(dir),
+		releaseNotes: store.NewReleaseNotesStore(dir),
+	}, dockerClient, globalClient
+}
+
+func expectStartContainer(r *require.Assertions, dockerClient *mock_clients.MockDockerClient, name, image, id string) {
+	dockerClient.EXPECT().EnsureLocalImage(gomock.Any(), name, image).Return(nil)
+	dockerClient.EXPECT().StartContainer(gomock.Any(), gomock.Any()).
+		DoAndReturn(func(ctx context.Context, containerConfig clients.DockerContainerConfig) (*clients.DockerContainer, error) {
+			r.Equal(image, containerConfig.Image)
+			return &clients.DockerContainer{ID: id, Config: containerConfig}, nil
+		})
+	dockerClient.EXPECT().WaitContainerStart(gomock.Any(), id).Return(nil)
+}
+
+func TestStartContainers_Adopt(t *testing.T) {
+	r := require.New(t)
+
+	runner, dockerClient, _ := testStateRunner(t)
+	r.NoError(runner.stateStore.Put(store.RunnerState{Updater: "updater1", Supervisor: "supervisor1"}))
+
+	dockerClient.EXPECT().GetContainerByName(gomock.Any(), config.DockerSupervisorContainerName).
+		Return(&types.Container{ID: "supervisor-id", Image: "supervisor1", State: "running"}, nil)
+	dockerClient.EXPECT().GetContainerByName(gomock.Any(), config.DockerUpdaterContainerName).
+		Return(&types.Container{ID: "updater-id", Image: "updater1", State: "running"}, nil)
+
+	// no nuke and no container start
+	r.NoError(runner.startContainers())
+	r.Equal("supervisor-id", runner.supervisorContainer.ID)
+	r.Equal("supervisor1", runner.supervisorContainer.Config.Image)
+	r.Equal("updater-id", runner.updaterContainer.ID)
+	r.Equal("updater1", runner.currentUpdaterImg)
+	r.Equal("supervisor1", runner.currentSupervisorImg)
+
+	// same release again: nothing is replaced
+	r.Nil(runner.updateContainers(store.ImageRefs{Updater: "updater1", Supervisor: "supervisor1"}))
+}
+
+func TestStartContainers_Mismatch(t *testing.T) {
+	r := require.New(t)
+
+	runner, dockerClient, globalClient := testStateRunner(t)
+	r.NoError(runner.stateStore.Put(store.RunnerState{Updater: "updater2", Supervisor: "supervisor2"}))
+
+	// the containers are from another release
+	dockerClient.EXPECT().GetContainerByName(gomock.Any(), config.DockerSupervisorContainerName).
+		Return(&types.Container{ID: "supervisor-id", Image: "supervisor1", State: "running"}, nil)
+	globalClient.EXPECT().Nuke(gomock.Any()).Return(nil)
+	expectStartContainer(r, dockerClient, "updater", "updater2", "updater-id")
+	expectStartContainer(r, dockerClient, "supervisor", "supervisor2", "supervisor-id")
+
+	r.NoError(runner.startContainers())
+	r.Equal("updater2", runner.currentUpdaterImg)
+	r.Equal("supervisor2", runner.currentSupervisorImg)
+
+	// the updater announces the saved release: nothing is replaced
+	r.Nil(runner.updateContainers(store.ImageRefs{Updater: "updater2", Supervisor: "supervisor2"}))
+}
+
+func TestStartContainers_BadState(t *testing.T) {
+	r := require.New(t)
+
+	runner, dockerClient, globalClient := testStateRunner(t)
+	r.NoError(os.WriteFile(path.Join(runner.cfg.FortaDir, "runner-state.json"), []byte("{"), 0644))
+
+	globalClient.EXPECT().Nuke(gomock.Any()).Return(nil)
+	expectStartContainer(r, dockerClient, "updater", "updater-embedded", "updater-id")
+
+	r.NoError(runner.startContainers())
+	r.Equal("updater-embedded", runner.currentUpdaterImg)
+	r.Empty(runner.currentSupervisorImg)
+
+	state, err := runner.stateStore.Get()
+	r.NoError(err)
+	r.Equal("updater-embedded", state.Updater)
+}
+
+func TestContainerConfigs_Proxy(t *testing.T) {
+	r := require.New(t)
+	for _, key := range []string{"HTTP_PROXY", "HTTPS_PROXY", "NO_PROXY", "http_proxy", "https_proxy", "no_proxy"} {
+		t.Setenv(key, "")
+	}
+
+	runner, _, _ := testStateRunner(t)
+	refs := store.ImageRefs{Updater: "updater1", Supervisor: "supervisor1"}
+	r.NotContains(runner.supervisorContainerConfig("supervisor1", refs).Env, "HTTP_PROXY")
+
+	runner.cfg.Network.Proxy.HTTPProxy = "http://proxy.corp:3128"
+	for _, containerConfig := range []clients.DockerContainerConfig{
+		runner.updaterContainerConfig("updater1", refs),
+		runner.supervisorContainerConfig("supervisor1", refs),
+	} {
+		r.Equal("http://proxy.corp:3128", containerConfig.Env["HTTP_PROXY"])
+		r.Contains(containerConfig.Env["NO_PROXY"], config.DockerNatsContainerName)
+	}
+}
+
+func TestContainerConfigs_Labels(t *testing.T) {
+	r := require.New(t)
+
+	runner, _, _ := testStateRunner(t)
+	refs := store.ImageRefs{Updater: "updater1", Supervisor: "supervisor1"}
+	r.Empty(runner.supervisorContainerConfig("supervisor1", refs).Labels)
+
+	runner.cfg.Docker.ContainerLabels = map[string]string{"team": "infra"}
+	for _, containerConfig := range []clients.DockerContainerConfig{
+		runner.updaterContainerConfig("updater1", refs),
+		runner.supervisorContainerConfig("supervisor1", refs),
+	} {
+		r.Equal(map[string]string{"team": "infra"}, containerConfig.Labels)
+	}
+}
+
+func TestContainerConfigs_RemoteConfig(t *testing.T) {
+	r := require.New(t)
+
+	runner, _, _ := testStateRunner(t)
+	refs := store.ImageRefs{Updater: "updater1", Supervisor: "supervisor1"}
+	r.NotContains(runner.supervisorContainerConfig("supervisor1", refs).Env, config.EnvRemoteConfig)
+
+	runner.cfg.RemoteConfig = true
+	for _, containerConfig := range []clients.DockerContainerConfig{
+		runner.updaterContainerConfig("updater1", refs),
+		runner.supervisorContainerConfig("supervisor1", refs),
+	} {
+		r.Equal("true", containerConfig.Env[config.EnvRemoteConfig])
+	}
+}
@@ -0,0 +1,66 @@
+package runner
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/forta-network/forta-node/store"
+	log "github.com/sirupsen/logrus"
+)
+
+// releaseKey identifies a release while waiting for the track delay.
+func releaseKey(refs *store.ImageRefs) string {
+	if refs.ReleaseInfo != nil && len(refs.ReleaseInfo.IPFS) > 0 {
+		return refs.ReleaseInfo.IPFS
+	}
+	return fmt.Sprintf("%s|%s", refs.Supervisor, refs.Updater)
+}
+
+func describeRelease(refs *store.ImageRefs) string {
+	var version string
+	if refs.ReleaseInfo != nil {
+		version = refs.ReleaseInfo.Manifest.Release.Version
+	}
+	return fmt.Sprintf("%s (supervisor: %s, updater: %s)", version, refs.Supervisor, refs.Updater)
+}
+
+// awaitsTrackDelay tells if the release was seen too recently to be applied. A newer release
+// supersedes the pending one and restarts the delay.
+func (runner *Runner) awaitsTrackDelay(refs *store.ImageRefs) bool {
+	delay := runner.cfg.AutoUpdate.TrackDelay()
+	if delay == 0 {
+		return false
+	}
+
+	// do not keep the node idle if no supervisor was started yet
+	runner.containerMu.RLock()
+	supervisorStarted := len(runner.currentSupervisorImg) > 0
+	runner.containerMu.RUnlock()
+	if !supervisorStarted {
+		runner.pendingUpdate.Set("")
+		return false
+	}
+
+	firstSeen, err := runner.releaseSeen.FirstSeen(releaseKey(refs), time.Now())
+	if err != nil {
+		// do not block the updates forever because of a bad state file
+		log.WithError(err).Warn("failed to get the first seen time of the release - allowing update")
+		runner.pendingUpdate.Set("")
+		return false
+	}
+	applyAt := firstSeen.Add(delay)
+	if !time.Now().Before(applyAt) {
+		runner.pendingUpdate.Set("")
+		return false
+	}
+
+	pending := fmt.Sprintf("%s applies at %s", describeRelease(refs), applyAt.UTC().Format(time.RFC3339))
+	if runner.pendingUpdate.GetReport("").Details != pending {
+		log.WithFields(log.Fields{
+			"release": describeRelease(refs),
+			"applyAt": applyAt.UTC().Format(time.RFC3339),
+		}).Info("waiting for the track delay before applying the release")
+	}
+	runner.pendingUpdate.Set(pending)
+	return true
+}
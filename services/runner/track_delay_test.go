@@ -0,0 +1,65 @@
+package runner
+
+import (
+	"testing"
+	"time"
+
+	"github.com/forta-network/forta-core-go/release"
+	"github.com/forta-network/forta-node/config"
+	"github.com/forta-network/forta-node/store"
+	"github.com/stretchr/testify/require"
+)
+
+func testTrackDelayRunner(t *testing.T, delayHours int) (*Runner, store.ReleaseSeenStore) {
+	releaseSeen := store.NewReleaseSeenStore(t.TempDir())
+	return &Runner{
+		cfg: config.Config{
+			AutoUpdate: config.AutoUpdateConfig{TrackDelayHours: delayHours},
+		},
+		currentSupervisorImg: "supervisor0",
+		releaseSeen:          releaseSeen,
+	}, releaseSeen
+}
+
+func testTrackedRefs(ipfs string) *store.ImageRefs {
+	return &store.ImageRefs{
+		Supervisor:  "supervisor-" + ipfs,
+		Updater:     "updater-" + ipfs,
+		ReleaseInfo: &release.ReleaseInfo{IPFS: ipfs},
+	}
+}
+
+func TestAwaitsTrackDelay(t *testing.T) {
+	r := require.New(t)
+
+	runner, releaseSeen := testTrackDelayRunner(t, 24)
+
+	// new release waits
+	r.True(runner.awaitsTrackDelay(testTrackedRefs("release1")))
+	r.Contains(runner.pendingUpdate.GetReport("").Details, "supervisor-release1")
+
+	// newer release resets the clock
+	r.True(runner.awaitsTrackDelay(testTrackedRefs("release2")))
+	r.Contains(runner.pendingUpdate.GetReport("").Details, "supervisor-release2")
+
+	// release which was seen long ago is applied
+	_, err := releaseSeen.FirstSeen("release3", time.Now().Add(-25*time.Hour))
+	r.NoError(err)
+	r.False(runner.awaitsTrackDelay(testTrackedRefs("release3")))
+	r.Empty(runner.pendingUpdate.GetReport("").Details)
+}
+
+func TestAwaitsTrackDelay_NoDelay(t *testing.T) {
+	r := require.New(t)
+
+	runner, _ := testTrackDelayRunner(t, 0)
+	r.False(runner.awaitsTrackDelay(testTrackedRefs("release1")))
+}
+
+func TestAwaitsTrackDelay_NoSupervisor(t *testing.T) {
+	r := require.New(t)
+
+	runner, _ := testTrackDelayRunner(t, 24)
+	runner.currentSupervisorImg = ""
+	r.False(runner.awaitsTrackDelay(testTrackedRefs("release1")))
+}
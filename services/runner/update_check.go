@@ -0,0 +1,175 @@
+package runner
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/forta-network/forta-core-go/clients/health"
+	"github.com/forta-network/forta-node/store"
+	log "github.com/sirupsen/logrus"
+)
+
+// the results of the on-demand update checks
+const (
+	updateCheckNoUpdate   = "no-update"
+	updateCheckUpdated    = "updated"
+	updateCheckHeld       = "held"
+	updateCheckSkipped    = "skipped"
+	updateCheckRolledBack = "rolled-back"
+	updateCheckError      = "error"
+)
+
+var errUpdatesNotTracked = errors.New("the runner does not track the updates")
+
+// updateCheck is an on-demand update check request which is handled by the update loop.
+type updateCheck struct {
+	result chan *updateCheckResult
+}
+
+// done sends the result of the check. It does nothing if the update loop was not triggered by
+// an update check.
+func (check *updateCheck) done(result *updateCheckResult) {
+	if check == nil {
+		return
+	}
+	check.result <- result
+}
+
+// updateCheckResult is the response of the update check endpoint.
+type updateCheckResult struct {
+	Result     string `json:"result"`
+	Version    string `json:"version,omitempty"`
+	Supervisor string `json:"supervisor,omitempty"`
+	Updater    string `json:"updater,omitempty"`
+	Reason     string `json:"reason,omitempty"`
+	Error      string `json:"error,omitempty"`
+}
+
+func newUpdateCheckResult(result string, refs *store.ImageRefs) *updateCheckResult {
+	checkResult := &updateCheckResult{
+		Result:     result,
+		Supervisor: refs.Supervisor,
+		Updater:    refs.Updater,
+	}
+	if refs.ReleaseInfo != nil {
+		checkResult.Version = refs.ReleaseInfo.Manifest.Release.Version
+	}
+	return checkResult
+}
+
+func newUpdateCheckError(err error) *updateCheckResult {
+	return &updateCheckResult{Result: updateCheckError, Error: err.Error()}
+}
+
+// handleAdminCheckUpdates checks the latest release immediately and applies it through the
+// update loop. The response is written after the release is applied so that the rollouts can
+// wait for the result.
+func (runner *Runner) handleAdminCheckUpdates(w http.ResponseWriter, r *http.Request) {
+	log.WithField("action", adminActionCheckUpdates).Info("received admin action")
+	result := runner.checkUpdates(r)
+	w.Header().Set("Content-Type", "application/json")
+	if result.Result == updateCheckError {
+		log.WithField("action", adminActionCheckUpdates).WithField("error", result.Error).Error("admin action failed")
+		runner.adminAction.Set(fmt.Sprintf("%s failed: %s", adminActionCheckUpdates, result.Error))
+		w.WriteHeader(http.StatusInternalServerError)
+	} else {
+		runner.adminAction.Set(adminActionCheckUpdates)
+	}
+	_ = json.NewEncoder(w).Encode(result)
+}
+
+// tracksUpdates tells if the update loop is running.
+func (runner *Runner) tracksUpdates() bool {
+	runner.cfgMu.RLock()
+	defer runner.cfgMu.RUnlock()
+	return runner.updateChecks != nil && !runner.cfg.UpdatesDisabled() && !runner.cfg.Supervisor.External
+}
+
+func (runner *Runner) checkUpdates(r *http.Request) *updateCheckResult {
+	if !runner.tracksUpdates() {
+		return newUpdateCheckError(errUpdatesNotTracked)
+	}
+	check := &updateCheck{result: make(chan *updateCheckResult, 1)}
+	select {
+	case runner.updateChecks <- check:
+	case <-r.Context().Done():
+		return newUpdateCheckError(r.Context().Err())
+	}
+	select {
+	case result := <-check.result:
+		return result
+	case <-r.Context().Done():
+		return newUpdateCheckError(r.Context().Err())
+	}
+}
+
+// updatedResult tells if the release is running after the update or it was rolled back.
+func (runner *Runner) updatedResult(refs *store.ImageRefs) *updateCheckResult {
+	runner.containerMu.RLock()
+	applied := runner.currentSupervisorImg == refs.Supervisor && runner.currentUpdaterImg == refs.Updater
+	runner.containerMu.RUnlock()
+	if !applied {
+		return newUpdateCheckResult(updateCheckRolledBack, refs)
+	}
+	return newUpdateCheckResult(updateCheckUpdated, refs)
+}
+
+const (
+	defaultUpdateCheckLagThreshold = time.Minute * 10
+	updateCheckLagIntervals        = 3
+)
+
+// updateCheckLagThreshold returns the duration without a successful update check after which
+// the update checks are reported as lagging. It grows with the configured check interval so that
+// infrequent checks are not reported as lagging between two checks.
+func updateCheckLagThreshold(checkInterval time.Duration) time.Duration {
+	if threshold := checkInterval * updateCheckLagIntervals; threshold > defaultUpdateCheckLagThreshold {
+		return threshold
+	}
+	return defaultUpdateCheckLagThreshold
+}
+
+// updateCheckReports surfaces the last update check of the updater from the updater health reports
+// so that the failing update checks are visible in the node health.
+func updateCheckReports(updaterReports health.Reports, checkInterval time.Duration, now time.Time) (reports health.Reports) {
+	if checkErr, ok := updaterReports.NameContains("event.checked.error"); ok {
+		reports = append(reports, &health.Report{
+			Name:    "forta.update.check.error",
+			Status:  checkErr.Status,
+			Details: checkErr.Details,
+		})
+	}
+	if checked, ok := updaterReports.NameContains("event.checked.time"); ok {
+		reports = append(reports, &health.Report{
+			Name:    "forta.update.check.last-time",
+			Status:  health.StatusInfo,
+			Details: checked.Details,
+		})
+	}
+	succeeded, ok := updaterReports.NameContains("event.succeeded.time")
+	if !ok {
+		return
+	}
+	reports = append(reports, &health.Report{
+		Name:    "forta.update.check.last-success-time",
+		Status:  health.StatusInfo,
+		Details: succeeded.Details,
+	})
+	if t, err := time.Parse(time.RFC3339, succeeded.Details); err == nil {
+		sinceSuccess := now.Sub(t)
+		status := health.StatusInfo
+		if sinceSuccess > updateCheckLagThreshold(checkInterval) {
+			status = health.StatusLagging
+		}
+		reports = append(reports, &health.Report{
+			Name:    "forta.update.check.since-last-success",
+			Status:  status,
+			Details: strconv.FormatInt(int64(sinceSuccess.Seconds()), 10),
+		})
+	}
+	return
+}
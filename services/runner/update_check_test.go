@@ -0,0 +1,178 @@
+package runner
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/forta-network/forta-core-go/clients/health"
+	"github.com/forta-network/forta-core-go/release"
+	"github.com/forta-network/forta-node/config"
+	"github.com/forta-network/forta-node/store"
+	"github.com/stretchr/testify/require"
+)
+
+func TestUpdateCheckLagThreshold(t *testing.T) {
+	r := require.New(t)
+
+	r.Equal(defaultUpdateCheckLagThreshold, updateCheckLagThreshold(0))
+	r.Equal(defaultUpdateCheckLagThreshold, updateCheckLagThreshold(time.Minute))
+	r.Equal(time.Minute*90, updateCheckLagThreshold(time.Minute*30))
+}
+
+func TestUpdateCheckReports(t *testing.T) {
+	r := require.New(t)
+
+	now := time.Date(2022, 12, 1, 10, 0, 0, 0, time.UTC)
+	updaterReports := health.Reports{
+		{Name: "updater.event.checked.time", Status: health.StatusOK, Details: now.Add(-time.Minute).Format(time.RFC3339)},
+		{Name: "updater.event.succeeded.time", Status: health.StatusOK, Details: now.Add(-time.Minute * 2).Format(time.RFC3339)},
+		{Name: "updater.event.checked.error", Status: health.StatusFailing, Details: "registry error"},
+	}
+
+	reports := reportsByName(updateCheckReports(updaterReports, 0, now))
+	r.Len(reports, 4)
+	r.Equal(health.StatusFailing, reports["forta.update.check.error"].Status)
+	r.Equal("registry error", reports["forta.update.check.error"].Details)
+	r.Equal(updaterReports[0].Details, reports["forta.update.check.last-time"].Details)
+	r.Equal(updaterReports[1].Details, reports["forta.update.check.last-success-time"].Details)
+	r.Equal("120", reports["forta.update.check.since-last-success"].Details)
+	r.Equal(health.StatusInfo, reports["forta.update.check.since-last-success"].Status)
+
+	// no successful check for a while
+	reports = reportsByName(updateCheckReports(updaterReports, 0, now.Add(time.Hour)))
+	r.Equal(health.StatusLagging, reports["forta.update.check.since-last-success"].Status)
+
+	// not lagging yet with a long check interval
+	reports = reportsByName(updateCheckReports(updaterReports, time.Minute*30, now.Add(time.Hour)))
+	r.Equal(health.StatusInfo, reports["forta.update.check.since-last-success"].Status)
+	reports = reportsByName(updateCheckReports(updaterReports, time.Minute*30, now.Add(time.Hour*2)))
+	r.Equal(health.StatusLagging, reports["forta.update.check.since-last-success"].Status)
+
+	// older updater without the success time
+	reports = reportsByName(updateCheckReports(updaterReports[:1], 0, now))
+	r.Len(reports, 1)
+}
+
+type testCheckedImageStore struct {
+	store.FortaImageStore
+	latest *store.ImageRefs
+	err    error
+}
+
+func (imgStore *testCheckedImageStore) Latest() <-chan store.ImageRefs {
+	return nil
+}
+
+func (imgStore *testCheckedImageStore) CheckNow(ctx context.Context) (*store.ImageRefs, error) {
+	latest := imgStore.latest
+	imgStore.latest = nil
+	return latest, imgStore.err
+}
+
+func TestAdmin_CheckUpdates(t *testing.T) {
+	r := require.New(t)
+
+	var releaseInfo release.ReleaseInfo
+	releaseInfo.Manifest.Release.Version = "v0.7.2"
+	imgStore := &testCheckedImageStore{
+		latest: &store.ImageRefs{Supervisor: "supervisor-v0.7.2", Updater: "updater-v0.7.2", ReleaseInfo: &releaseInfo},
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	runner := &Runner{
+		ctx:          ctx,
+		cfg:          config.Config{Development: true},
+		imgStore:     imgStore,
+		updateChecks: make(chan *updateCheck),
+		adminToken:   "token1",
+	}
+	go runner.keepContainersUpToDate()
+	server := httptest.NewServer(runner.controlRouter())
+	defer server.Close()
+
+	check := func(expectedCode int) *updateCheckResult {
+		req, err := http.NewRequest(http.MethodPost, server.URL+"/admin/updates/check", nil)
+		r.NoError(err)
+		req.Header.Set("Authorization", "Bearer token1")
+		resp, err := http.DefaultClient.Do(req)
+		r.NoError(err)
+		defer resp.Body.Close()
+		r.Equal(expectedCode, resp.StatusCode)
+		var result updateCheckResult
+		r.NoError(json.NewDecoder(resp.Body).Decode(&result))
+		return &result
+	}
+
+	// the new release is held while the updates are paused
+	runner.updatesPaused.Store(true)
+	result := check(http.StatusOK)
+	r.Equal(updateCheckHeld, result.Result)
+	r.Equal("v0.7.2", result.Version)
+	r.Equal(holdReasonPaused, result.Reason)
+	r.Equal("supervisor-v0.7.2", runner.heldUpdate.get().Supervisor)
+
+	// the held release is reported again without a newer release
+	result = check(http.StatusOK)
+	r.Equal(updateCheckHeld, result.Result)
+	r.Equal("updater-v0.7.2", result.Updater)
+
+	imgStore.err = errors.New("updater error")
+	result = check(http.StatusInternalServerError)
+	r.Equal(updateCheckError, result.Result)
+	r.Equal("updater error", result.Error)
+	r.Contains(runner.adminAction.GetReport("").Details, "updater error")
+}
+
+func TestAdmin_CheckUpdatesNoUpdate(t *testing.T) {
+	r := require.New(t)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	runner := &Runner{
+		ctx:          ctx,
+		imgStore:     &testCheckedImageStore{},
+		updateChecks: make(chan *updateCheck),
+		adminToken:   "token1",
+	}
+	go runner.keepContainersUpToDate()
+	checkRequest := func() *http.Request {
+		req := httptest.NewRequest(http.MethodPost, "/admin/updates/check", nil)
+		req.Header.Set("Authorization", "Bearer token1")
+		return req
+	}
+
+	w := httptest.NewRecorder()
+	runner.controlRouter().ServeHTTP(w, checkRequest())
+	r.Equal(http.StatusOK, w.Code)
+	var result updateCheckResult
+	r.NoError(json.NewDecoder(w.Body).Decode(&result))
+	r.Equal(updateCheckNoUpdate, result.Result)
+
+	// not available without the update loop
+	runner.cfg.AutoUpdate.Disable = true
+	w = httptest.NewRecorder()
+	runner.controlRouter().ServeHTTP(w, checkRequest())
+	r.Equal(http.StatusInternalServerError, w.Code)
+	r.Contains(w.Body.String(), errUpdatesNotTracked.Error())
+
+	// not available without the admin token
+	w = httptest.NewRecorder()
+	runner.controlRouter().ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/admin/updates/check", nil))
+	r.Equal(http.StatusUnauthorized, w.Code)
+}
+
+func TestUpdatedResult(t *testing.T) {
+	r := require.New(t)
+
+	refs := &store.ImageRefs{Supervisor: "supervisor2", Updater: "updater2"}
+	runner := &Runner{currentSupervisorImg: "supervisor2", currentUpdaterImg: "updater2"}
+	r.Equal(updateCheckUpdated, runner.updatedResult(refs).Result)
+
+	runner.currentSupervisorImg = "supervisor1"
+	r.Equal(updateCheckRolledBack, runner.updatedResult(refs).Result)
+}
@@ -0,0 +1,242 @@
+package runner
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/forta-network/forta-core-go/clients/health"
+	"github.com/forta-network/forta-node/config"
+	"github.com/forta-network/forta-node/store"
+	log "github.com/sirupsen/logrus"
+)
+
+const defaultValidationInterval = time.Second * 15
+
+var errValidationTimeout = errors.New("validation timed out")
+
+// startValidation validates the update in the background so that the update loop keeps
+// responding. The next updates are held until the validation is done.
+func (runner *Runner) startValidation(prevRefs, latestRefs store.ImageRefs) {
+	if runner.cfg.AutoUpdate.ValidationTimeout() == 0 {
+		return
+	}
+	runner.validating.Store(true)
+	runner.validationMu.Lock()
+	runner.rollbackRefs = &prevRefs
+	runner.validationMu.Unlock()
+	go func() {
+		runner.updateMu.Lock()
+		defer runner.updateMu.Unlock()
+		defer runner.validating.Store(false)
+		runner.validateUpdate(prevRefs, latestRefs)
+	}()
+}
+
+// validateUpdate waits for the new supervisor to become valid and restores the previous
+// release if it does not. The release is rejected after a rollback so that it is not
+// applied again until a different release appears.
+func (runner *Runner) validateUpdate(prevRefs, latestRefs store.ImageRefs) {
+	timeout := runner.cfg.AutoUpdate.ValidationTimeout()
+	if timeout == 0 {
+		return
+	}
+
+	release := describeRelease(&latestRefs)
+	logger := log.WithFields(log.Fields{
+		"release":  release,
+		"previous": describeRelease(&prevRefs),
+		"timeout":  timeout.String(),
+	})
+
+	runner.validationMu.Lock()
+	runner.rollbackRefs = &prevRefs
+	runner.validationMu.Unlock()
+	runner.updateValidation.Set(fmt.Sprintf("validating %s", release))
+	logger.Info("validating the new supervisor")
+
+	err := runner.validateSupervisor(timeout)
+	if runner.ctx.Err() != nil {
+		return // stopping
+	}
+	if err == nil {
+		runner.validationMu.Lock()
+		runner.rollbackRefs = nil
+		runner.validationMu.Unlock()
+		runner.updateValidation.Set(fmt.Sprintf("validated %s", release))
+		logger.Info("new supervisor is valid")
+		return
+	}
+
+	logger.WithError(err).Warn("new supervisor is invalid - rolling back")
+	runner.rejectRelease(&latestRefs)
+
+	if rollbackErr := runner.rollbackUpdate(prevRefs); rollbackErr != nil {
+		runner.updateValidation.Set(fmt.Sprintf("rejected %s (%v) and failed to roll back: %v", release, err, rollbackErr))
+		logger.WithError(rollbackErr).Error("failed to roll back the release")
+		return
+	}
+	runner.validationMu.Lock()
+	runner.rollbackRefs = nil
+	runner.validationMu.Unlock()
+	runner.updateValidation.Set(fmt.Sprintf("rejected %s (%v) and rolled back", release, err))
+	logger.Info("rolled back the release")
+}
+
+// rollbackStartTimeout restores the previous release after the new supervisor did not start in
+// time and rejects the release. The container lock must be held.
+func (runner *Runner) rollbackStartTimeout(logger *log.Entry, prevRefs, latestRefs store.ImageRefs, err error) {
+	release := describeRelease(&latestRefs)
+	logger.WithError(err).Warn("new supervisor did not start in time - rolling back")
+	runner.rejectRelease(&latestRefs)
+
+	if rollbackErr := runner.rollbackRelease(logger, prevRefs); rollbackErr != nil {
+		logger.WithError(rollbackErr).Panic("failed to roll back the release")
+	}
+	runner.updateValidation.Set(fmt.Sprintf("rejected %s (%v) and rolled back", release, err))
+	logger.Info("rolled back the release")
+}
+
+// rejectRelease rejects the release and persists it so that it is not applied again after
+// a restart.
+func (runner *Runner) rejectRelease(refs *store.ImageRefs) {
+	key := releaseKey(refs)
+	runner.validationMu.Lock()
+	runner.rejectedRelease = key
+	runner.validationMu.Unlock()
+	if runner.releaseSeen == nil {
+		return
+	}
+	if err := runner.releaseSeen.Reject(key); err != nil {
+		log.WithError(err).WithField("release", describeRelease(refs)).Error("failed to persist the rejected release")
+	}
+}
+
+// loadRejectedRelease restores the release which was rejected before a restart.
+func (runner *Runner) loadRejectedRelease() {
+	rejected, err := runner.releaseSeen.Rejected()
+	if err != nil {
+		log.WithError(err).Warn("failed to load the rejected release")
+		return
+	}
+	runner.validationMu.Lock()
+	runner.rejectedRelease = rejected
+	runner.validationMu.Unlock()
+}
+
+// validateSupervisor waits until the supervisor is healthy and ready. The supervisor is ready
+// only after scanning a block so this makes sure that the new scanner is working. It fails early
+// if the supervisor stops running.
+func (runner *Runner) validateSupervisor(timeout time.Duration) error {
+	ticker := time.NewTicker(runner.validationInterval)
+	defer ticker.Stop()
+	deadline := time.NewTimer(timeout)
+	defer deadline.Stop()
+
+	var lastErr error
+	for {
+		select {
+		case <-runner.ctx.Done():
+			return runner.ctx.Err()
+
+		case <-deadline.C:
+			return fmt.Errorf("%w after %s: %v", errValidationTimeout, timeout, lastErr)
+
+		case <-ticker.C:
+			lastErr = runner.checkSupervisorValid()
+			if lastErr == nil {
+				return nil
+			}
+			var stateErr *containerStateError
+			if errors.As(lastErr, &stateErr) {
+				return lastErr
+			}
+			log.WithError(lastErr).Debug("new supervisor is not valid yet")
+		}
+	}
+}
+
+func (runner *Runner) checkSupervisorValid() error {
+	healthPort, err := runner.supervisorHealthPort()
+	if err != nil {
+		return err
+	}
+	for _, report := range runner.checkChildHealth("supervisor", healthPort) {
+		if !reportsHealthy(health.Reports{report}) {
+			return fmt.Errorf("not healthy: %s is %s", report.Name, report.Status)
+		}
+	}
+	return runner.checkReady(healthPort)
+}
+
+func (runner *Runner) rollbackUpdate(prevRefs store.ImageRefs) error {
+	runner.containerMu.Lock()
+	defer runner.containerMu.Unlock()
+
+	logger := log.WithField("supervisor", prevRefs.Supervisor).WithField("updater", prevRefs.Updater)
+	if err := runner.rollbackRelease(logger, prevRefs); err != nil {
+		return err
+	}
+	runner.saveState()
+	return nil
+}
+
+// rollbackRelease restores the updater and the supervisor of the previous release. The container
+// lock must be held.
+func (runner *Runner) rollbackRelease(logger *log.Entry, prevRefs store.ImageRefs) error {
+	if prevRefs.Updater != runner.currentUpdaterImg {
+		if err := runner.replaceUpdater(logger, prevRefs); err != nil {
+			return err
+		}
+		runner.currentUpdaterImg = prevRefs.Updater
+	}
+	if err := runner.replaceSupervisor(logger, prevRefs); err != nil {
+		return err
+	}
+	runner.currentSupervisorImg = prevRefs.Supervisor
+	runner.currentRelease = prevRefs.ReleaseInfo
+	return nil
+}
+
+func (runner *Runner) validationReports() (reports health.Reports) {
+	if validation := runner.updateValidation.GetReport("forta.update.validation"); len(validation.Details) > 0 {
+		reports = append(reports, validation)
+	}
+	if invalid := runner.invalidRelease.GetReport("forta.update.invalid-release"); len(invalid.Details) > 0 {
+		reports = append(reports, invalid)
+	}
+	runner.validationMu.RLock()
+	defer runner.validationMu.RUnlock()
+	if runner.rollbackRefs != nil {
+		reports = append(reports, &health.Report{
+			Name:    "forta.update.rollback-candidate",
+			Status:  health.StatusInfo,
+			Details: describeRelease(runner.rollbackRefs),
+		})
+	}
+	return
+}
+
+// acceptsRelease checks the release info from the updater so that a corrupt release manifest is
+// rejected before the running containers are replaced. The release info is not validated in
+// development mode where the local releases do not need real commits and image digests.
+func (runner *Runner) acceptsRelease(refs *store.ImageRefs) bool {
+	if runner.cfg.Development {
+		return true
+	}
+	err := config.ValidateReleaseInfo(refs.ReleaseInfo)
+	if err == nil {
+		runner.invalidRelease.Set("")
+		return true
+	}
+	log.WithError(err).WithField("release", describeRelease(refs)).Error("rejecting the invalid release")
+	runner.invalidRelease.Set(fmt.Sprintf("%s: %v", describeRelease(refs), err))
+	return false
+}
+
+// isRejected tells if the release was rejected after a failed validation.
+func (runner *Runner) isRejected(refs *store.ImageRefs) bool {
+	runner.validationMu.RLock()
+	defer runner.validationMu.RUnlock()
+	return len(runner.rejectedRelease) > 0 && runner.rejectedRelease == releaseKey(refs)
+}
@@ -0,0 +1,261 @@
+package runner
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/docker/docker/api/types"
+	"github.com/forta-network/forta-core-go/clients/health"
+	"github.com/forta-network/forta-core-go/release"
+	"github.com/forta-network/forta-node/clients"
+	mock_clients "github.com/forta-network/forta-node/clients/mocks"
+	"github.com/forta-network/forta-node/config"
+	"github.com/forta-network/forta-node/healthutils"
+	"github.com/forta-network/forta-node/store"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+)
+
+func testValidationRunner(t *testing.T, supervisorReports health.Reports, ready bool) (*Runner, *mock_clients.MockDockerClient) {
+	dockerClient := mock_clients.NewMockDockerClient(gomock.NewController(t))
+	return &Runner{
+		ctx: context.Background(),
+		cfg: config.Config{
+			Development: true,
+			AutoUpdate:  config.AutoUpdateConfig{ValidationMinutes: 1},
+		},
+		dockerClient:        dockerClient,
+		supervisorContainer: &clients.DockerContainer{ID: "supervisor1"},
+		healthClient: &testHealthClient{
+			reports: map[string]health.Reports{"1001": supervisorReports},
+		},
+		readinessClient: func(port string) (*healthutils.ReadinessResponse, error) {
+			return &healthutils.ReadinessResponse{Ready: ready}, nil
+		},
+		validationInterval: time.Millisecond * 10,
+	}, dockerClient
+}
+
+func TestValidateSupervisor_Pass(t *testing.T) {
+	r := require.New(t)
+
+	runner, dockerClient := testValidationRunner(t, health.Reports{
+		{Name: "service.supervisor", Status: health.StatusOK},
+	}, true)
+	dockerClient.EXPECT().GetContainerByID(gomock.Any(), "supervisor1").
+		Return(&types.Container{State: "running", Ports: []types.Port{{PrivatePort: 8090, PublicPort: 1001}}}, nil)
+
+	r.NoError(runner.validateSupervisor(time.Second))
+}
+
+func TestValidateSupervisor_Fail(t *testing.T) {
+	r := require.New(t)
+
+	runner, dockerClient := testValidationRunner(t, nil, false)
+	dockerClient.EXPECT().GetContainerByID(gomock.Any(), "supervisor1").
+		Return(&types.Container{State: "exited"}, nil)
+
+	// fails without waiting for the timeout
+	err := runner.validateSupervisor(time.Hour)
+	var stateErr *containerStateError
+	r.ErrorAs(err, &stateErr)
+}
+
+func TestValidateSupervisor_Timeout(t *testing.T) {
+	r := require.New(t)
+
+	// healthy but no blocks scanned yet
+	runner, dockerClient := testValidationRunner(t, health.Reports{
+		{Name: "service.supervisor", Status: health.StatusOK},
+	}, false)
+	dockerClient.EXPECT().GetContainerByID(gomock.Any(), "supervisor1").
+		Return(&types.Container{State: "running", Ports: []types.Port{{PrivatePort: 8090, PublicPort: 1001}}}, nil).
+		AnyTimes()
+	r.ErrorIs(runner.validateSupervisor(time.Millisecond*100), errValidationTimeout)
+
+	// ready but not healthy
+	runner.readinessClient = func(port string) (*healthutils.ReadinessResponse, error) {
+		return &healthutils.ReadinessResponse{Ready: true}, nil
+	}
+	runner.healthClient = &testHealthClient{
+		reports: map[string]health.Reports{"1001": {{Name: "service.supervisor", Status: health.StatusFailing}}},
+	}
+	r.ErrorIs(runner.validateSupervisor(time.Millisecond*100), errValidationTimeout)
+}
+
+func TestValidateUpdate_Rollback(t *testing.T) {
+	r := require.New(t)
+
+	runner, dockerClient := testValidationRunner(t, nil, false)
+	dir := t.TempDir()
+	runner.releaseSeen = store.NewReleaseSeenStore(dir)
+	runner.currentSupervisorImg = "supervisor-new"
+	runner.currentUpdaterImg = "updater-new"
+	runner.updaterContainer = &clients.DockerContainer{ID: "updater1"}
+
+	prevRefs := store.ImageRefs{Supervisor: "supervisor-old", Updater: "updater-old"}
+	latestRefs := store.ImageRefs{Supervisor: "supervisor-new", Updater: "updater-new"}
+
+	dockerClient.EXPECT().GetContainerByID(gomock.Any(), "supervisor1").Return(&types.Container{State: "exited"}, nil)
+	// rollback of both of the updater and the supervisor
+	for _, id := range []string{"updater1", "supervisor1"} {
+		dockerClient.EXPECT().TerminateContainer(gomock.Any(), id, time.Duration(0)).Return(nil)
+		dockerClient.EXPECT().WaitContainerExit(gomock.Any(), id).Return(nil)
+		dockerClient.EXPECT().Prune(gomock.Any()).Return(nil)
+		dockerClient.EXPECT().WaitContainerPrune(gomock.Any(), id).Return(nil)
+	}
+	gomock.InOrder(
+		dockerClient.EXPECT().EnsureLocalImage(gomock.Any(), "updater", "updater-old").Return(nil),
+		dockerClient.EXPECT().EnsureLocalImage(gomock.Any(), "supervisor", "supervisor-old").Return(nil),
+	)
+	dockerClient.EXPECT().StartContainer(gomock.Any(), gomock.Any()).
+		DoAndReturn(func(ctx context.Context, containerConfig clients.DockerContainerConfig) (*clients.DockerContainer, error) {
+			if containerConfig.Name == config.DockerUpdaterContainerName {
+				r.Equal("updater-old", containerConfig.Image)
+				return &clients.DockerContainer{ID: "updater2"}, nil
+			}
+			r.Equal("supervisor-old", containerConfig.Image)
+			return &clients.DockerContainer{ID: "supervisor2"}, nil
+		}).Times(2)
+	dockerClient.EXPECT().WaitContainerStart(gomock.Any(), "updater2").Return(nil)
+	dockerClient.EXPECT().WaitContainerStart(gomock.Any(), "supervisor2").Return(nil)
+
+	runner.validateUpdate(prevRefs, latestRefs)
+
+	r.Equal("supervisor-old", runner.currentSupervisorImg)
+	r.Equal("updater-old", runner.currentUpdaterImg)
+	r.True(runner.isRejected(&latestRefs))
+	r.False(runner.isRejected(&prevRefs))
+	reports := runner.validationReports()
+	r.Len(reports, 1)
+	r.Contains(reports[0].Details, "rolled back")
+
+	// the rejection survives restarts
+	restarted := &Runner{releaseSeen: store.NewReleaseSeenStore(dir)}
+	restarted.loadRejectedRelease()
+	r.True(restarted.isRejected(&latestRefs))
+}
+
+func TestStartValidation(t *testing.T) {
+	r := require.New(t)
+
+	runner, dockerClient := testValidationRunner(t, health.Reports{
+		{Name: "service.supervisor", Status: health.StatusOK},
+	}, false)
+	checked := make(chan struct{})
+	dockerClient.EXPECT().GetContainerByID(gomock.Any(), "supervisor1").
+		DoAndReturn(func(ctx context.Context, id string) (*types.Container, error) {
+			<-checked
+			return &types.Container{State: "running", Ports: []types.Port{{PrivatePort: 8090, PublicPort: 1001}}}, nil
+		}).AnyTimes()
+	runner.readinessClient = func(port string) (*healthutils.ReadinessResponse, error) {
+		return &healthutils.ReadinessResponse{Ready: true}, nil
+	}
+
+	prevRefs := store.ImageRefs{Supervisor: "supervisor-old", Updater: "updater"}
+	latestRefs := store.ImageRefs{Supervisor: "supervisor-new", Updater: "updater"}
+
+	// returns without waiting for the validation and holds the next updates
+	runner.startValidation(prevRefs, latestRefs)
+	r.Equal(holdReasonValidating, runner.holdUpdate(&store.ImageRefs{Supervisor: "supervisor-newer"}))
+	runner.validationMu.RLock()
+	r.Equal(&prevRefs, runner.rollbackRefs)
+	runner.validationMu.RUnlock()
+
+	close(checked)
+	r.Eventually(func() bool {
+		return !runner.validating.Load()
+	}, time.Second, time.Millisecond*10)
+	r.Empty(runner.holdUpdate(&store.ImageRefs{Supervisor: "supervisor-newer"}))
+	r.False(runner.isRejected(&latestRefs))
+}
+
+func TestValidateUpdate_Disabled(t *testing.T) {
+	r := require.New(t)
+
+	runner, _ := testValidationRunner(t, nil, false)
+	runner.cfg.AutoUpdate.ValidationMinutes = 0
+
+	latestRefs := store.ImageRefs{Supervisor: "supervisor-new"}
+	runner.validateUpdate(store.ImageRefs{Supervisor: "supervisor-old"}, latestRefs)
+	r.False(runner.isRejected(&latestRefs))
+	r.Empty(runner.validationReports())
+}
+
+func TestAcceptsRelease(t *testing.T) {
+	r := require.New(t)
+
+	runner := &Runner{}
+	validRelease := &release.ReleaseInfo{
+		Manifest: release.ReleaseManifest{
+			Release: release.Release{
+				Version: "v0.7.1",
+				Commit:  "4e9b5b8e1e4b5d2a3c1f0e9d8c7b6a5f4e3d2c1b",
+				Services: release.ReleaseServices{
+					Supervisor: "disco.forta.network/bafybeisupervisor@sha256:e0e7cd6b3c8ac4b8e3b9f9d6d4f0c1f1b8c5a6a1e1b4b6c2e4f3d9a1b2c3d4e5",
+					Updater:    "disco.forta.network/bafybeiupdater@sha256:f1f8de7c4d9bd5c9f4c0a0e7e5a1d2a2c9d6b7b2f2c5c7d3f5a4e0b2c3d4e5f6",
+				},
+			},
+		},
+	}
+	corruptRelease := *validRelease
+	corruptRelease.Manifest.Release.Commit = "not-a-commit"
+
+	r.False(runner.acceptsRelease(&store.ImageRefs{Supervisor: "supervisor-new", ReleaseInfo: &corruptRelease}))
+	reports := runner.validationReports()
+	r.Len(reports, 1)
+	r.Equal("forta.update.invalid-release", reports[0].Name)
+	r.Contains(reports[0].Details, "invalid release commit")
+
+	r.True(runner.acceptsRelease(&store.ImageRefs{Supervisor: "supervisor-new", ReleaseInfo: validRelease}))
+	r.Empty(runner.validationReports())
+
+	// not validated in development mode
+	runner.cfg.Development = true
+	r.True(runner.acceptsRelease(&store.ImageRefs{Supervisor: "supervisor-new"}))
+}
+
+func TestUpdateContainers_StartTimeout(t *testing.T) {
+	r := require.New(t)
+
+	runner, dockerClient, _ := testStateRunner(t)
+	runner.cfg.ResourcesConfig.ContainerStartTimeoutSeconds = 30
+	runner.currentSupervisorImg = "supervisor-old"
+	runner.currentUpdaterImg = "updater"
+	runner.supervisorContainer = &clients.DockerContainer{Name: config.DockerSupervisorContainerName, ID: "supervisor1"}
+	latestRefs := store.ImageRefs{Supervisor: "supervisor-new", Updater: "updater", Notes: &config.ReleaseNotes{Notes: "notes"}}
+
+	for _, id := range []string{"supervisor1", "supervisor2"} {
+		dockerClient.EXPECT().TerminateContainer(gomock.Any(), id, gomock.Any()).Return(nil)
+		dockerClient.EXPECT().WaitContainerExit(gomock.Any(), id).Return(nil)
+		dockerClient.EXPECT().Prune(gomock.Any()).Return(nil)
+		dockerClient.EXPECT().WaitContainerPrune(gomock.Any(), id).Return(nil)
+	}
+	// the new supervisor does not start in time
+	dockerClient.EXPECT().EnsureLocalImage(gomock.Any(), "supervisor", "supervisor-new").Return(nil)
+	dockerClient.EXPECT().StartContainer(gomock.Any(), gomock.Any()).
+		Return(&clients.DockerContainer{Name: config.DockerSupervisorContainerName, ID: "supervisor2"}, nil)
+	dockerClient.EXPECT().WaitContainerStart(gomock.Any(), "supervisor2").
+		DoAndReturn(func(ctx context.Context, id string) error {
+			deadline, ok := ctx.Deadline()
+			r.True(ok)
+			r.WithinDuration(time.Now().Add(time.Second*30), deadline, time.Second)
+			return clients.ErrContainerStartTimeout
+		})
+	// the previous supervisor is restored
+	expectStartContainer(r, dockerClient, "supervisor", "supervisor-old", "supervisor3")
+
+	r.Nil(runner.updateContainers(latestRefs))
+	r.Equal("supervisor-old", runner.currentSupervisorImg)
+	r.Equal("supervisor3", runner.supervisorContainer.ID)
+	r.True(runner.isRejected(&latestRefs))
+	reports := runner.validationReports()
+	r.Len(reports, 1)
+	r.Contains(reports[0].Details, "rolled back")
+	r.Contains(reports[0].Details, clients.ErrContainerStartTimeout.Error())
+	// the notes of the rolled back release are not kept
+	notes, err := runner.releaseNotes.List()
+	r.NoError(err)
+	r.Empty(notes)
+}
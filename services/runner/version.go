@@ -0,0 +1,92 @@
+package runner
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"github.com/forta-network/forta-node/config"
+	"github.com/forta-network/forta-node/store"
+	log "github.com/sirupsen/logrus"
+)
+
+const versionContainersTimeout = time.Second * 10
+
+// latestRelease is the latest release which the updater provided.
+type latestRelease struct {
+	refs   store.ImageRefs
+	seenAt time.Time
+}
+
+// setLatestRelease keeps the latest release from the updater so that the version info does not
+// need to query the registry.
+func (runner *Runner) setLatestRelease(refs store.ImageRefs) {
+	runner.latestMu.Lock()
+	defer runner.latestMu.Unlock()
+	runner.latestRelease = &latestRelease{refs: refs, seenAt: time.Now().UTC()}
+}
+
+// versionInfo collects the build, embedded, running and the latest release info from the runner
+// state.
+func (runner *Runner) versionInfo() *config.NodeVersion {
+	embedded := runner.imgStore.EmbeddedImageRefs()
+	version := &config.NodeVersion{
+		Embedded: config.NodeImages{
+			Supervisor: embedded.Supervisor,
+			Updater:    embedded.Updater,
+		},
+		ReleaseChannel: runner.releaseChannel(),
+		UpdatesEnabled: !runner.cfg.UpdatesDisabled(),
+	}
+	if buildRelease, ok := config.GetBuildReleaseSummary(); ok {
+		version.Build = buildRelease
+	}
+
+	runner.containerMu.RLock()
+	version.Running = config.NodeImages{
+		Supervisor: runner.currentSupervisorImg,
+		Updater:    runner.currentUpdaterImg,
+	}
+	version.RunningRelease = config.NewNodeRelease(runner.currentRelease)
+	runner.containerMu.RUnlock()
+
+	runner.latestMu.RLock()
+	if latest := runner.latestRelease; latest != nil {
+		version.LatestRelease = config.NewNodeRelease(latest.refs.ReleaseInfo)
+		if version.LatestRelease == nil {
+			version.LatestRelease = &config.NodeRelease{}
+		}
+		seenAt := latest.seenAt
+		version.LatestRelease.SeenAt = &seenAt
+	}
+	runner.latestMu.RUnlock()
+
+	version.Containers = runner.containerDigests()
+	return version
+}
+
+// containerDigests returns the image digests of the running node containers. The digests are
+// skipped if the containers could not be listed.
+func (runner *Runner) containerDigests() map[string]string {
+	ctx, cancel := context.WithTimeout(runner.ctx, versionContainersTimeout)
+	defer cancel()
+	containers, err := runner.globalClient.GetFortaServiceContainers(ctx)
+	if err != nil {
+		log.WithError(err).Warn("failed to get the containers for the version info")
+		return nil
+	}
+	digests := make(map[string]string)
+	for _, container := range containers {
+		if container.State != "running" {
+			continue
+		}
+		digests[container.Names[0][1:]] = imageDigest(container)
+	}
+	return digests
+}
+
+func (runner *Runner) handleAdminVersion(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(runner.versionInfo())
+}
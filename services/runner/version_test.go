@@ -0,0 +1,107 @@
+package runner
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/docker/docker/api/types"
+	"github.com/forta-network/forta-core-go/release"
+	"github.com/forta-network/forta-node/clients"
+	"github.com/forta-network/forta-node/config"
+	"github.com/forta-network/forta-node/store"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+)
+
+func testVersionRelease(version, commit string) *release.ReleaseInfo {
+	return &release.ReleaseInfo{
+		IPFS: "cid-" + commit,
+		Manifest: release.ReleaseManifest{
+			Release: release.Release{Version: version, Commit: commit, Timestamp: "2022-12-01T00:00:00Z"},
+		},
+	}
+}
+
+func TestVersionInfo(t *testing.T) {
+	r := require.New(t)
+
+	runner, _, globalClient := testStateRunner(t)
+	runner.adminToken = "token1"
+	runner.imgStore = &testImageStore{embedded: store.ImageRefs{Supervisor: "supervisor-embedded", Updater: "updater-embedded"}}
+	runner.currentSupervisorImg = "supervisor1"
+	runner.currentUpdaterImg = "updater1"
+	runner.currentRelease = testVersionRelease("v0.7.0", "commit1")
+	runner.setLatestRelease(store.ImageRefs{Supervisor: "supervisor2", Updater: "updater2", ReleaseInfo: testVersionRelease("v0.7.1-beta", "commit2")})
+	server := httptest.NewServer(runner.controlRouter())
+	defer server.Close()
+
+	supervisor := testContainer(config.DockerSupervisorContainerName, "running", 0)
+	supervisor.Image = "disco.forta.network/bafybeisupervisor@sha256:1111"
+	exited := testContainer(config.DockerUpdaterContainerName, "exited", 0)
+	scanner := testContainer(config.DockerScannerContainerName, "running", 0)
+	scanner.Image = "forta-network/forta-node:latest"
+	scanner.ImageID = "sha256:2222"
+	globalClient.EXPECT().GetFortaServiceContainers(gomock.Any()).Return(clients.DockerContainerList{supervisor, exited, scanner}, nil)
+
+	req, err := http.NewRequest(http.MethodGet, server.URL+"/admin/version", nil)
+	r.NoError(err)
+	req.Header.Set("Authorization", "Bearer token1")
+	resp, err := http.DefaultClient.Do(req)
+	r.NoError(err)
+	defer resp.Body.Close()
+	r.Equal(http.StatusOK, resp.StatusCode)
+	var version config.NodeVersion
+	r.NoError(json.NewDecoder(resp.Body).Decode(&version))
+
+	r.Equal(config.NodeImages{Supervisor: "supervisor-embedded", Updater: "updater-embedded"}, version.Embedded)
+	r.Equal(config.NodeImages{Supervisor: "supervisor1", Updater: "updater1"}, version.Running)
+	r.Equal("v0.7.0", version.RunningRelease.Version)
+	r.Equal("commit1", version.RunningRelease.Commit)
+	r.Equal(config.ReleaseChannelStable, version.RunningRelease.Channel)
+	r.Equal("v0.7.1-beta", version.LatestRelease.Version)
+	r.Equal("commit2", version.LatestRelease.Commit)
+	r.Equal("2022-12-01T00:00:00Z", version.LatestRelease.Timestamp)
+	r.Equal(config.ReleaseChannelBeta, version.LatestRelease.Channel)
+	r.NotNil(version.LatestRelease.SeenAt)
+	r.Equal(config.ReleaseChannelStable, version.ReleaseChannel)
+	r.True(version.UpdatesEnabled)
+	r.Equal(map[string]string{
+		config.DockerSupervisorContainerName: "sha256:1111",
+		config.DockerScannerContainerName:    "sha256:2222",
+	}, version.Containers)
+}
+
+func TestVersionInfo_UpdatesDisabled(t *testing.T) {
+	r := require.New(t)
+
+	runner, _, globalClient := testStateRunner(t)
+	runner.cfg.AutoUpdate.Disable = true
+	runner.currentSupervisorImg = "supervisor1"
+	globalClient.EXPECT().GetFortaServiceContainers(gomock.Any()).Return(nil, errors.New("failed"))
+
+	version := runner.versionInfo()
+	r.Equal("updater-embedded", version.Embedded.Updater)
+	r.Equal(config.NodeImages{Supervisor: "supervisor1"}, version.Running)
+	r.Nil(version.RunningRelease)
+	r.Nil(version.LatestRelease)
+	r.False(version.UpdatesEnabled)
+	// the container digests are skipped
+	r.Nil(version.Containers)
+}
+
+func TestVersionInfo_LatestWithoutRelease(t *testing.T) {
+	r := require.New(t)
+
+	runner, _, globalClient := testStateRunner(t)
+	runner.setLatestRelease(store.ImageRefs{Supervisor: "supervisor2", Updater: "updater2"})
+	globalClient.EXPECT().GetFortaServiceContainers(gomock.Any()).Return([]types.Container{}, nil)
+
+	version := runner.versionInfo()
+	r.NotNil(version.LatestRelease)
+	r.Empty(version.LatestRelease.Version)
+	r.NotNil(version.LatestRelease.SeenAt)
+	r.Empty(version.Containers)
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"strconv"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/ethereum/go-ethereum/common/hexutil"
@@ -32,12 +33,32 @@ type AgentPool struct {
 	dialer                  func(config.AgentConfig) (clients.AgentClient, error)
 	mu                      sync.RWMutex
 	botWaitGroup            *sync.WaitGroup
+	timeouts                poolagent.Timeouts
+	slowAgentThreshold      time.Duration
+	drainTimeout            time.Duration
+	bufferSize              int
+	dropOldest              bool
+	droppedTxRequests       atomic.Uint64
+
+	// completed are the agents which passed their stop blocks, by the container names.
+	completed map[string]config.AgentConfig
+	// oneOffRuns are the one-off agent runs, by the container names.
+	oneOffRuns         map[string]*oneOffRun
+	newOneOffBlockFeed OneOffBlockFeed
+	// replaced are the running agents which keep processing until their new versions are
+	// attached, by the container names of the new versions.
+	replaced map[string]*poolagent.Agent
 }
 
 // NewAgentPool creates a new agent pool.
-func NewAgentPool(ctx context.Context, _ config.ScannerConfig, msgClient clients.MessageClient, waitBots int) *AgentPool {
+func NewAgentPool(ctx context.Context, scannerCfg config.ScannerConfig, agentCfg config.AgentRuntimeConfig, msgClient clients.MessageClient, waitBots int) *AgentPool {
 	agentPool := &AgentPool{
 		ctx:                       ctx,
+		timeouts:                  poolagent.TimeoutsFromConfig(agentCfg),
+		slowAgentThreshold:        agentCfg.SlowAgentThreshold,
+		drainTimeout:              agentCfg.DrainTimeout,
+		bufferSize:                scannerCfg.Queue.Size(),
+		dropOldest:                scannerCfg.Queue.DropsOldest(),
 		txResults:                 make(chan *scanner.TxResult),
 		blockResults:              make(chan *scanner.BlockResult),
 		combinationAlertResults:   make(chan *scanner.CombinationAlertResult),
@@ -58,6 +79,7 @@ func NewAgentPool(ctx context.Context, _ config.ScannerConfig, msgClient clients
 
 	agentPool.registerMessageHandlers()
 	go agentPool.logAgentChanBuffersLoop()
+	go agentPool.aggregatePerfLoop()
 	return agentPool
 }
 
@@ -74,10 +96,10 @@ func (ap *AgentPool) Health() health.Reports {
 		}
 	}
 	status := health.StatusOK
-	if agentCount == 0 {
+	if agentCount == 0 && len(ap.completed) == 0 {
 		status = health.StatusFailing
 	}
-	return health.Reports{
+	reports := health.Reports{
 		&health.Report{
 			Name:    "agents.total",
 			Status:  status,
@@ -88,7 +110,26 @@ func (ap *AgentPool) Health() health.Reports {
 			Status:  health.StatusInfo,
 			Details: strconv.Itoa(fullCount),
 		},
+		ap.completedReport(),
+		ap.oneOffRunsReport(),
+		ap.timeoutsReport(),
 	}
+	reports = append(reports, ap.queueReportsUnsafe()...)
+	return append(reports, ap.perfReports()...)
+}
+
+// InFlightRequests returns the number of the requests which the agents did not process yet,
+// including the replaced agents which are still processing.
+func (ap *AgentPool) InFlightRequests() (count int) {
+	ap.mu.RLock()
+	defer ap.mu.RUnlock()
+	for _, agent := range ap.agents {
+		count += agent.InFlightRequests()
+	}
+	for _, agent := range ap.replaced {
+		count += agent.InFlightRequests()
+	}
+	return
 }
 
 // Name implements health.Reporter interface.
@@ -103,6 +144,16 @@ func (ap *AgentPool) logBotWait() {
 	}
 }
 
+// newAgent creates a new agent which sends the results to the pool.
+func (ap *AgentPool) newAgent(agentCfg config.AgentConfig) *poolagent.Agent {
+	agent := poolagent.New(ap.ctx, agentCfg, ap.msgClient, ap.txResults, ap.blockResults, ap.combinationAlertResults)
+	agent.SetTimeouts(ap.timeouts)
+	if ap.bufferSize > 0 {
+		agent.SetBufferSize(ap.bufferSize)
+	}
+	return agent
+}
+
 // discardAgent removes the agent from the list which eventually causes the
 // request channels to be deallocated.
 func (ap *AgentPool) discardAgent(discarded *poolagent.Agent) {
@@ -144,7 +195,7 @@ func (ap *AgentPool) SendEvaluateTxRequest(req *protocol.EvaluateTxRequest) {
 	}
 	var metricsList []*protocol.AgentMetric
 	for _, agent := range agents {
-		if !agent.IsReady() || !agent.ShouldProcessBlock(req.Event.Block.BlockNumber) {
+		if !agent.IsReady() || agent.Config().OneOff() || !agent.ShouldProcessBlock(req.Event.Block.BlockNumber) {
 			continue
 		}
 		lg.WithFields(log.Fields{
@@ -152,18 +203,12 @@ func (ap *AgentPool) SendEvaluateTxRequest(req *protocol.EvaluateTxRequest) {
 			"duration": time.Since(startTime),
 		}).Debug("sending tx request to evalTxCh")
 
-		// unblock req send and discard agent if agent is closed
-
-		select {
-		case <-agent.Closed():
-			ap.discardAgent(agent)
-		case agent.TxRequestCh() <- &poolagent.TxRequest{
+		if dropped := ap.sendTxRequest(agent, &poolagent.TxRequest{
 			Original: req,
 			Encoded:  encoded,
-		}:
-		default: // do not try to send if the buffer is full
-			lg.WithField("agent", agent.Config().ID).Debug("agent tx request buffer is full - skipping")
-			metricsList = append(metricsList, metrics.CreateAgentMetric(agent.Config().ID, metrics.MetricTxDrop, 1))
+		}); dropped > 0 {
+			lg.WithField("agent", agent.Config().ID).Debug("agent tx request buffer is full - dropped the oldest request")
+			metricsList = append(metricsList, metrics.CreateAgentMetric(agent.Config().ID, metrics.MetricTxDrop, float64(dropped)))
 		}
 		lg.WithFields(log.Fields{
 			"agent":    agent.Config().ID,
@@ -208,7 +253,7 @@ func (ap *AgentPool) SendEvaluateBlockRequest(req *protocol.EvaluateBlockRequest
 
 	var metricsList []*protocol.AgentMetric
 	for _, agent := range agents {
-		if !agent.IsReady() || !agent.ShouldProcessBlock(req.Event.BlockNumber) {
+		if !agent.IsReady() || agent.Config().OneOff() || !agent.ShouldProcessBlock(req.Event.BlockNumber) {
 			continue
 		}
 
@@ -217,18 +262,10 @@ func (ap *AgentPool) SendEvaluateBlockRequest(req *protocol.EvaluateBlockRequest
 			"duration": time.Since(startTime),
 		}).Debug("sending block request to evalBlockCh")
 
-		// unblock req send if agent is closed
-		select {
-		case <-agent.Closed():
-			ap.discardAgent(agent)
-		case agent.BlockRequestCh() <- &poolagent.BlockRequest{
+		ap.sendBlockRequest(agent, &poolagent.BlockRequest{
 			Original: req,
 			Encoded:  encoded,
-		}:
-		default: // do not try to send if the buffer is full
-			lg.WithField("agent", agent.Config().ID).Warn("agent block request buffer is full - skipping")
-			metricsList = append(metricsList, metrics.CreateAgentMetric(agent.Config().ID, metrics.MetricBlockDrop, 1))
-		}
+		})
 		lg.WithFields(
 			log.Fields{
 				"agent":    agent.Config().ID,
@@ -237,7 +274,10 @@ func (ap *AgentPool) SendEvaluateBlockRequest(req *protocol.EvaluateBlockRequest
 		).Debug("sent tx request to evalBlockCh")
 	}
 
-	blockNumber, _ := hexutil.DecodeUint64(req.Event.BlockNumber)
+	blockNumber, err := hexutil.DecodeUint64(req.Event.BlockNumber)
+	if err == nil {
+		ap.completeAgents(blockNumber)
+	}
 	ap.msgClient.Publish(messaging.SubjectScannerBlock, &messaging.ScannerPayload{
 		LatestBlockInput: blockNumber,
 	})
@@ -278,7 +318,7 @@ func (ap *AgentPool) SendEvaluateAlertRequest(req *protocol.EvaluateAlertRequest
 
 	var metricsList []*protocol.AgentMetric
 	for _, agent := range agents {
-		if !agent.IsReady() || !agent.ShouldProcessAlert(req.Event) {
+		if !agent.IsReady() || agent.Config().OneOff() || !agent.ShouldProcessAlert(req.Event) {
 			continue
 		}
 
@@ -352,21 +392,32 @@ func (ap *AgentPool) handleAgentVersionsUpdate(payload messaging.AgentPayload) e
 	// and send a "run" message.
 	var agentsToRun []config.AgentConfig
 	for _, agentCfg := range latestVersions {
+		if ap.isCompleted(agentCfg) {
+			continue
+		}
 		var found bool
 		for _, agent := range ap.agents {
 			found = found || (agent.Config().ContainerName() == agentCfg.ContainerName())
 		}
 		if !found {
-			newAgents = append(newAgents, poolagent.New(ap.ctx, agentCfg, ap.msgClient, ap.txResults, ap.blockResults, ap.combinationAlertResults))
+			newAgents = append(newAgents, ap.newAgent(agentCfg))
 			agentsToRun = append(agentsToRun, agentCfg)
 			log.WithField("agent", agentCfg.ID).Info("will trigger start")
 		}
 	}
 
 	// Find the missing agents in the latest versions and send a "stop" message.
-	// Otherwise, add to the new agents list, so we keep on running.
+	// Otherwise, add to the new agents list, so we keep on running. The one-off
+	// runs are not in the latest versions and they stop by themselves. The updated
+	// agents keep running until their new versions are attached and the removed
+	// agents are drained before they are stopped.
 	var agentsToStop []config.AgentConfig
+	replaced := make(map[string]*poolagent.Agent)
 	for _, agent := range ap.agents {
+		if agent.Config().OneOff() {
+			newAgents = append(newAgents, agent)
+			continue
+		}
 		var found bool
 		var agentCfg config.AgentConfig
 		for _, agentCfg = range latestVersions {
@@ -375,16 +426,27 @@ func (ap *AgentPool) handleAgentVersionsUpdate(payload messaging.AgentPayload) e
 				break
 			}
 		}
-		if !found {
-			agent.Close()
-			agentsToStop = append(agentsToStop, agent.Config())
-			log.WithField("agent", agent.Config().ID).WithField("image", agent.Config().Image).Info("will trigger stop")
-		} else {
+		if found {
 			newAgents = append(newAgents, agent)
+			continue
+		}
+		logger := log.WithField("agent", agent.Config().ID).WithField("image", agent.Config().Image)
+		replacement, ok := findReplacement(agent, latestVersions)
+		if ok && !ap.isCompleted(replacement) && agent.IsReady() && !agent.IsClosed() {
+			replaced[replacement.ContainerName()] = agent
+			newAgents = append(newAgents, agent)
+			logger.WithField("newImage", replacement.Image).Info("will switch to the new version after it is attached")
+			continue
+		}
+		if ap.drainOrClose(agent) {
+			agentsToStop = append(agentsToStop, agent.Config())
+			logger.Info("will trigger stop")
 		}
 	}
 
 	ap.agents = newAgents
+	ap.replaced = replaced
+	ap.forgetCompleted(latestVersions)
 	if len(agentsToRun) > 0 {
 		ap.msgClient.Publish(messaging.SubjectAgentsActionRun, agentsToRun)
 	}
@@ -401,6 +463,7 @@ func (ap *AgentPool) handleStatusRunning(payload messaging.AgentPayload) error {
 	// If an agent was added before and just started to run, we should mark as ready.
 	var agentsToStop []config.AgentConfig
 	var agentsReady []config.AgentConfig
+	var oneOffReady int
 	var newSubscriptions []messaging.CombinerBotSubscription
 	var removedSubscriptions []messaging.CombinerBotSubscription
 
@@ -416,6 +479,7 @@ func (ap *AgentPool) handleStatusRunning(payload messaging.AgentPayload) error {
 				if err != nil {
 					log.WithField("agent", agent.Config().ID).WithError(err).Error("handleStatusRunning: error while dialing")
 					agentsToStop = append(agentsToStop, agent.Config())
+					ap.releaseReplacedUnsafe(agent.Config())
 					if agent.IsCombinerBot() {
 						for _, subscription := range agent.AlertConfig().Subscriptions {
 							removedSubscriptions = append(removedSubscriptions, messaging.CombinerBotSubscription{Subscription: subscription})
@@ -436,14 +500,18 @@ func (ap *AgentPool) handleStatusRunning(payload messaging.AgentPayload) error {
 				}
 
 				logger.WithField("image", agent.Config().Image).Info("attached")
+				ap.releaseReplacedUnsafe(agent.Config())
 				agentsReady = append(agentsReady, agent.Config())
+				if agent.Config().OneOff() {
+					oneOffReady++
+				}
 			}
 		}
 	}
 	if len(agentsReady) > 0 {
 		ap.msgClient.Publish(messaging.SubjectAgentsStatusAttached, agentsReady)
 		if ap.botWaitGroup != nil {
-			ap.botWaitGroup.Add(-len(agentsReady))
+			ap.botWaitGroup.Add(-(len(agentsReady) - oneOffReady))
 		}
 	}
 	if len(agentsToStop) > 0 {
@@ -464,6 +532,7 @@ func (ap *AgentPool) handleStatusStopped(payload messaging.AgentPayload) error {
 	defer ap.mu.Unlock()
 
 	var newAgents []*poolagent.Agent
+	var stoppedAgents []*poolagent.Agent
 	for _, agent := range ap.agents {
 		var stopped bool
 		for _, agentCfg := range payload {
@@ -471,6 +540,7 @@ func (ap *AgentPool) handleStatusStopped(payload messaging.AgentPayload) error {
 				agent.Close()
 				log.WithField("agent", agent.Config().ID).WithField("image", agent.Config().Image).Info("detached")
 				stopped = true
+				stoppedAgents = append(stoppedAgents, agent)
 				break
 			}
 		}
@@ -480,6 +550,17 @@ func (ap *AgentPool) handleStatusStopped(payload messaging.AgentPayload) error {
 		}
 	}
 	ap.agents = newAgents
+
+	// the old versions are drained if their new versions stopped and the stopped old versions
+	// are not switched from anymore
+	for _, agent := range stoppedAgents {
+		ap.releaseReplacedUnsafe(agent.Config())
+		for name, replaced := range ap.replaced {
+			if replaced == agent {
+				delete(ap.replaced, name)
+			}
+		}
+	}
 	return nil
 }
 
@@ -2,7 +2,9 @@ package agentpool
 
 import (
 	"context"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/forta-network/forta-core-go/protocol"
 	"github.com/forta-network/forta-node/clients"
@@ -165,3 +167,136 @@ func (s *Suite) TestStartProcessStop() {
 	s.agentClient.EXPECT().Close()
 	s.r.NoError(s.ap.handleAgentVersionsUpdate(emptyPayload))
 }
+
+// TestCompleteAtStopBlock tests stopping an agent after its stop block.
+func (s *Suite) TestCompleteAtStopBlock() {
+	stopBlock := uint64(100)
+	agentConfig := config.AgentConfig{ID: testAgentID, StopBlock: &stopBlock}
+	agentPayload := messaging.AgentPayload{agentConfig}
+
+	s.agentClient.EXPECT().Initialize(gomock.Any(), gomock.Any()).Return(nil, nil).AnyTimes()
+	s.msgClient.EXPECT().Publish(messaging.SubjectAgentsActionRun, gomock.Any())
+	s.msgClient.EXPECT().Publish(messaging.SubjectAgentsStatusAttached, gomock.Any())
+	s.r.NoError(s.ap.handleAgentVersionsUpdate(agentPayload))
+	s.r.NoError(s.ap.handleStatusRunning(agentPayload))
+
+	// the stop block is processed
+	s.agentClient.EXPECT().Invoke(
+		gomock.Any(), agentgrpc.MethodEvaluateBlock,
+		gomock.AssignableToTypeOf(&grpc.PreparedMsg{}), gomock.AssignableToTypeOf(&protocol.EvaluateBlockResponse{}),
+	).Return(nil)
+	s.msgClient.EXPECT().Publish(messaging.SubjectScannerBlock, gomock.Any()).Times(2)
+	s.ap.SendEvaluateBlockRequest(&protocol.EvaluateBlockRequest{Event: &protocol.BlockEvent{BlockNumber: "0x64"}})
+	<-s.ap.BlockResults()
+
+	// the next block completes the agent
+	s.msgClient.EXPECT().Publish(messaging.SubjectAgentsActionStop, messaging.AgentPayload{agentConfig})
+	s.agentClient.EXPECT().Close()
+	s.ap.SendEvaluateBlockRequest(&protocol.EvaluateBlockRequest{Event: &protocol.BlockEvent{BlockNumber: "0x65"}})
+	s.r.Empty(s.ap.agents)
+	s.r.Equal("test-agent (stop block 100)", s.ap.completedReport().Details)
+
+	// the completed agent is not started again
+	s.r.NoError(s.ap.handleAgentVersionsUpdate(agentPayload))
+	s.r.Empty(s.ap.agents)
+
+	// unless the stop block changes
+	newStopBlock := uint64(200)
+	agentConfig.StopBlock = &newStopBlock
+	s.msgClient.EXPECT().Publish(messaging.SubjectAgentsActionRun, gomock.Any())
+	s.r.NoError(s.ap.handleAgentVersionsUpdate(messaging.AgentPayload{agentConfig}))
+	s.r.Len(s.ap.agents, 1)
+	s.r.Equal("none", s.ap.completedReport().Details)
+}
+
+// TestDrainOnUpdate tests switching to the new version of an agent and draining the old version.
+func (s *Suite) TestDrainOnUpdate() {
+	s.ap.drainTimeout = time.Second * 5
+	oldConfig := config.AgentConfig{ID: testAgentID, Image: "some.docker.registry.io/foobar@sha256:" + strings.Repeat("a", 64)}
+	newConfig := config.AgentConfig{ID: testAgentID, Image: "some.docker.registry.io/foobar@sha256:" + strings.Repeat("b", 64)}
+
+	s.agentClient.EXPECT().Initialize(gomock.Any(), gomock.Any()).Return(nil, nil).AnyTimes()
+	s.msgClient.EXPECT().Publish(messaging.SubjectAgentsActionRun, gomock.Any()).Times(2)
+	s.msgClient.EXPECT().Publish(messaging.SubjectAgentsStatusAttached, gomock.Any()).Times(2)
+	s.r.NoError(s.ap.handleAgentVersionsUpdate(messaging.AgentPayload{oldConfig}))
+	s.r.NoError(s.ap.handleStatusRunning(messaging.AgentPayload{oldConfig}))
+
+	// the old version holds a block request open
+	invoked := make(chan struct{})
+	release := make(chan struct{})
+	s.agentClient.EXPECT().Invoke(
+		gomock.Any(), agentgrpc.MethodEvaluateBlock,
+		gomock.AssignableToTypeOf(&grpc.PreparedMsg{}), gomock.AssignableToTypeOf(&protocol.EvaluateBlockResponse{}),
+	).DoAndReturn(func(context.Context, agentgrpc.Method, interface{}, interface{}, ...grpc.CallOption) error {
+		close(invoked)
+		<-release
+		return nil
+	})
+	s.msgClient.EXPECT().Publish(messaging.SubjectScannerBlock, gomock.Any())
+	s.ap.SendEvaluateBlockRequest(&protocol.EvaluateBlockRequest{Event: &protocol.BlockEvent{BlockNumber: "0x64"}})
+	<-invoked
+
+	// the old version keeps running until the new version is attached
+	s.r.NoError(s.ap.handleAgentVersionsUpdate(messaging.AgentPayload{newConfig}))
+	s.r.Len(s.ap.agents, 2)
+	s.r.NoError(s.ap.handleStatusRunning(messaging.AgentPayload{newConfig}))
+	s.r.Len(s.ap.agents, 1)
+	s.r.Equal(newConfig, s.ap.agents[0].Config())
+
+	// the old version is stopped after the result of the request in flight is sent
+	stopped := make(chan struct{})
+	s.agentClient.EXPECT().Close()
+	s.msgClient.EXPECT().Publish(messaging.SubjectAgentsActionStop, messaging.AgentPayload{oldConfig}).
+		Do(func(string, interface{}) { close(stopped) })
+	close(release)
+	blockResult := <-s.ap.BlockResults()
+	s.r.Equal(oldConfig, blockResult.AgentConfig)
+	select {
+	case <-stopped:
+	case <-time.After(time.Second * 5):
+		s.r.FailNow("old version was not stopped")
+	}
+}
+
+// TestDrainTimeoutOnRemoval tests stopping a removed agent which does not finish its request in time.
+func (s *Suite) TestDrainTimeoutOnRemoval() {
+	s.ap.drainTimeout = time.Millisecond * 100
+	agentConfig := config.AgentConfig{ID: testAgentID}
+
+	s.agentClient.EXPECT().Initialize(gomock.Any(), gomock.Any()).Return(nil, nil).AnyTimes()
+	s.msgClient.EXPECT().Publish(messaging.SubjectAgentsActionRun, gomock.Any())
+	s.msgClient.EXPECT().Publish(messaging.SubjectAgentsStatusAttached, gomock.Any())
+	s.r.NoError(s.ap.handleAgentVersionsUpdate(messaging.AgentPayload{agentConfig}))
+	s.r.NoError(s.ap.handleStatusRunning(messaging.AgentPayload{agentConfig}))
+
+	// the agent holds a block request open until it is stopped
+	invoked := make(chan struct{})
+	release := make(chan struct{})
+	defer close(release)
+	s.agentClient.EXPECT().Invoke(
+		gomock.Any(), agentgrpc.MethodEvaluateBlock,
+		gomock.AssignableToTypeOf(&grpc.PreparedMsg{}), gomock.AssignableToTypeOf(&protocol.EvaluateBlockResponse{}),
+	).DoAndReturn(func(context.Context, agentgrpc.Method, interface{}, interface{}, ...grpc.CallOption) error {
+		close(invoked)
+		<-release
+		return context.Canceled
+	})
+	s.msgClient.EXPECT().Publish(messaging.SubjectScannerBlock, gomock.Any())
+	s.ap.SendEvaluateBlockRequest(&protocol.EvaluateBlockRequest{Event: &protocol.BlockEvent{BlockNumber: "0x64"}})
+	<-invoked
+
+	// the agent is removed from the pool right away and stopped after the timeout
+	stopped := make(chan struct{})
+	s.agentClient.EXPECT().Close()
+	s.msgClient.EXPECT().Publish(messaging.SubjectAgentsActionStop, messaging.AgentPayload{agentConfig}).
+		Do(func(string, interface{}) { close(stopped) })
+	start := time.Now()
+	s.r.NoError(s.ap.handleAgentVersionsUpdate(messaging.AgentPayload{}))
+	s.r.Empty(s.ap.agents)
+	select {
+	case <-stopped:
+	case <-time.After(time.Second * 5):
+		s.r.FailNow("agent was not stopped")
+	}
+	s.r.GreaterOrEqual(time.Since(start), s.ap.drainTimeout)
+}
@@ -0,0 +1,87 @@
+package agentpool
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/forta-network/forta-core-go/clients/health"
+	"github.com/forta-network/forta-node/clients/messaging"
+	"github.com/forta-network/forta-node/config"
+	"github.com/forta-network/forta-node/services/scanner/agentpool/poolagent"
+	log "github.com/sirupsen/logrus"
+)
+
+// completeAgents stops the agents which processed all of the blocks up to their stop blocks.
+func (ap *AgentPool) completeAgents(blockNumber uint64) {
+	ap.mu.Lock()
+	var (
+		remaining []*poolagent.Agent
+		completed messaging.AgentPayload
+	)
+	for _, agent := range ap.agents {
+		agentCfg := agent.Config()
+		if !agent.IsReady() || agentCfg.OneOff() || !agentCfg.PastStopBlock(blockNumber) || agent.HasPendingRequests() {
+			remaining = append(remaining, agent)
+			continue
+		}
+		agent.Close()
+		if ap.completed == nil {
+			ap.completed = make(map[string]config.AgentConfig)
+		}
+		ap.completed[agentCfg.ContainerName()] = agentCfg
+		completed = append(completed, agentCfg)
+		log.WithFields(log.Fields{
+			"agent":     agentCfg.ID,
+			"stopBlock": *agentCfg.StopBlock,
+		}).Info("agent reached the stop block - completed")
+	}
+	ap.agents = remaining
+	ap.mu.Unlock()
+
+	if len(completed) > 0 {
+		ap.msgClient.Publish(messaging.SubjectAgentsActionStop, completed)
+	}
+}
+
+// isCompleted tells if the agent already completed with the same stop block. The lock should be
+// held by the caller.
+func (ap *AgentPool) isCompleted(agentCfg config.AgentConfig) bool {
+	completedCfg, ok := ap.completed[agentCfg.ContainerName()]
+	return ok && agentCfg.StopBlock != nil && *agentCfg.StopBlock == *completedCfg.StopBlock
+}
+
+// forgetCompleted forgets the completed agents which are removed or which have new stop blocks.
+// The lock should be held by the caller.
+func (ap *AgentPool) forgetCompleted(latestVersions messaging.AgentPayload) {
+	for name := range ap.completed {
+		var keep bool
+		for _, agentCfg := range latestVersions {
+			if agentCfg.ContainerName() == name && ap.isCompleted(agentCfg) {
+				keep = true
+				break
+			}
+		}
+		if !keep {
+			delete(ap.completed, name)
+		}
+	}
+}
+
+// completedReport lists the completed agents. The lock should be held by the caller.
+func (ap *AgentPool) completedReport() *health.Report {
+	var completed []string
+	for _, agentCfg := range ap.completed {
+		completed = append(completed, fmt.Sprintf("%s (stop block %d)", agentCfg.ID, *agentCfg.StopBlock))
+	}
+	sort.Strings(completed)
+	details := "none"
+	if len(completed) > 0 {
+		details = strings.Join(completed, ", ")
+	}
+	return &health.Report{
+		Name:    "agents.completed",
+		Status:  health.StatusInfo,
+		Details: details,
+	}
+}
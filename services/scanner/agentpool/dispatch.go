@@ -0,0 +1,93 @@
+package agentpool
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/forta-network/forta-core-go/clients/health"
+	"github.com/forta-network/forta-node/config"
+	"github.com/forta-network/forta-node/services/scanner/agentpool/poolagent"
+)
+
+// sendTxRequest hands the tx request over to the agent. If the agent buffer is full, it waits for
+// the agent or drops the oldest tx requests of the agent, depending on the overflow policy.
+// It returns the number of the dropped requests.
+func (ap *AgentPool) sendTxRequest(agent *poolagent.Agent, req *poolagent.TxRequest) (dropped int) {
+	if !ap.dropOldest {
+		select {
+		case <-agent.Closed():
+			ap.discardAgent(agent)
+		case agent.TxRequestCh() <- req:
+		case <-ap.ctx.Done():
+		}
+		return
+	}
+	for {
+		select {
+		case <-agent.Closed():
+			ap.discardAgent(agent)
+			return
+		case agent.TxRequestCh() <- req:
+			return
+		default:
+		}
+		// the agent may have taken the oldest request in the meantime
+		if agent.DropOldestTxRequest() {
+			dropped++
+			agent.CountDropped()
+			ap.droppedTxRequests.Add(1)
+		}
+	}
+}
+
+// sendBlockRequest hands the block request over to the agent. The block requests are never
+// dropped so it waits for the agent if its buffer is full.
+func (ap *AgentPool) sendBlockRequest(agent *poolagent.Agent, req *poolagent.BlockRequest) {
+	select {
+	case <-agent.Closed():
+		ap.discardAgent(agent)
+	case agent.BlockRequestCh() <- req:
+	case <-ap.ctx.Done():
+	}
+}
+
+// queueReportsUnsafe reports the number of the requests waiting in the agent buffers and
+// the number of the dropped tx requests.
+func (ap *AgentPool) queueReportsUnsafe() health.Reports {
+	var txDepth, blockDepth int
+	for _, agent := range ap.agents {
+		tx, block := agent.BufferDepths()
+		txDepth += tx
+		blockDepth += block
+	}
+	capacity := ap.bufferSize
+	if capacity == 0 {
+		capacity = poolagent.DefaultBufferSize
+	}
+	policy := config.OverflowPolicyBlock
+	if ap.dropOldest {
+		policy = config.OverflowPolicyDropOldest
+	}
+	return health.Reports{
+		&health.Report{
+			Name:    "queue.tx.depth",
+			Status:  health.StatusInfo,
+			Details: strconv.Itoa(txDepth),
+		},
+		&health.Report{
+			Name:    "queue.block.depth",
+			Status:  health.StatusInfo,
+			Details: strconv.Itoa(blockDepth),
+		},
+		&health.Report{
+			Name:    "queue.tx.dropped",
+			Status:  health.StatusInfo,
+			Details: strconv.FormatUint(ap.droppedTxRequests.Load(), 10),
+		},
+		&health.Report{
+			Name:    "queue.policy",
+			Status:  health.StatusInfo,
+			Details: fmt.Sprintf("%s (capacity: %d per agent)", policy, capacity),
+		},
+	}
+}
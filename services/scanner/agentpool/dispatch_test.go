@@ -0,0 +1,127 @@
+package agentpool
+
+import (
+	"fmt"
+	"runtime"
+	"time"
+
+	"github.com/forta-network/forta-core-go/protocol"
+	"github.com/forta-network/forta-node/config"
+	"github.com/forta-network/forta-node/services/scanner/agentpool/poolagent"
+	"github.com/golang/mock/gomock"
+)
+
+// addIdleAgent adds a ready agent which does not process the requests so that its buffers
+// fill up.
+func (s *Suite) addIdleAgent() *poolagent.Agent {
+	agent := s.ap.newAgent(config.AgentConfig{ID: testAgentID})
+	agent.SetReady()
+	s.ap.agents = append(s.ap.agents, agent)
+	return agent
+}
+
+func testTxRequest(i int) *protocol.EvaluateTxRequest {
+	return &protocol.EvaluateTxRequest{
+		Event: &protocol.TransactionEvent{
+			Block: &protocol.TransactionEvent_EthBlock{BlockNumber: "0x1"},
+			Transaction: &protocol.TransactionEvent_EthTransaction{
+				Hash:  fmt.Sprintf("0x%064x", i),
+				Input: fmt.Sprintf("0x%01024x", i),
+			},
+		},
+	}
+}
+
+func heapAlloc() uint64 {
+	runtime.GC()
+	var stats runtime.MemStats
+	runtime.ReadMemStats(&stats)
+	return stats.HeapAlloc
+}
+
+func (s *Suite) TestDispatchDropOldestLoad() {
+	const (
+		capacity = 10
+		requests = 50000
+	)
+	s.ap.bufferSize = capacity
+	s.ap.dropOldest = true
+	agent := s.addIdleAgent()
+	s.msgClient.EXPECT().PublishProto(gomock.Any(), gomock.Any()).AnyTimes()
+
+	before := heapAlloc()
+	for i := 0; i < requests; i++ {
+		s.ap.SendEvaluateTxRequest(testTxRequest(i))
+	}
+	after := heapAlloc()
+
+	// only the newest requests are kept and the memory does not grow with the load
+	txDepth, _ := agent.BufferDepths()
+	s.r.Equal(capacity, txDepth)
+	s.r.Equal(uint64(requests-capacity), s.ap.droppedTxRequests.Load())
+	s.r.Less(int64(after)-int64(before), int64(8*1024*1024))
+
+	reports := s.ap.Health()
+	dropped, ok := reports.NameContains("queue.tx.dropped")
+	s.r.True(ok)
+	s.r.Equal(fmt.Sprint(requests-capacity), dropped.Details)
+	depth, ok := reports.NameContains("queue.tx.depth")
+	s.r.True(ok)
+	s.r.Equal(fmt.Sprint(capacity), depth.Details)
+}
+
+func (s *Suite) TestDispatchBlockPolicy() {
+	s.ap.bufferSize = 2
+	agent := s.addIdleAgent()
+
+	s.ap.SendEvaluateTxRequest(testTxRequest(0))
+	s.ap.SendEvaluateTxRequest(testTxRequest(1))
+
+	// the full buffer makes the sender wait until the agent takes a request
+	sent := make(chan struct{})
+	go func() {
+		s.ap.SendEvaluateTxRequest(testTxRequest(2))
+		close(sent)
+	}()
+	select {
+	case <-sent:
+		s.r.FailNow("tx request was not blocked")
+	case <-time.After(time.Millisecond * 100):
+	}
+	s.r.True(agent.DropOldestTxRequest())
+	select {
+	case <-sent:
+	case <-time.After(time.Second * 5):
+		s.r.FailNow("tx request was not sent")
+	}
+	s.r.Zero(s.ap.droppedTxRequests.Load())
+}
+
+func (s *Suite) TestDispatchNeverDropsBlocks() {
+	s.ap.bufferSize = 1
+	s.ap.dropOldest = true
+	s.addIdleAgent()
+	s.msgClient.EXPECT().Publish(gomock.Any(), gomock.Any()).AnyTimes()
+
+	blockReq := &protocol.EvaluateBlockRequest{Event: &protocol.BlockEvent{BlockNumber: "0x1"}}
+	s.ap.SendEvaluateBlockRequest(blockReq)
+
+	sent := make(chan struct{})
+	go func() {
+		s.ap.SendEvaluateBlockRequest(blockReq)
+		close(sent)
+	}()
+	select {
+	case <-sent:
+		s.r.FailNow("block request was not blocked")
+	case <-time.After(time.Millisecond * 100):
+	}
+	// unblocked when the agent is closed
+	s.ap.agents[0].Close()
+	select {
+	case <-sent:
+	case <-time.After(time.Second * 5):
+		s.r.FailNow("block request was not unblocked")
+	}
+	s.r.Zero(s.ap.droppedTxRequests.Load())
+}
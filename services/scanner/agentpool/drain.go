@@ -0,0 +1,73 @@
+package agentpool
+
+import (
+	"github.com/forta-network/forta-node/clients/messaging"
+	"github.com/forta-network/forta-node/config"
+	"github.com/forta-network/forta-node/services/scanner/agentpool/poolagent"
+	log "github.com/sirupsen/logrus"
+)
+
+// findReplacement finds the new version of the agent in the latest versions.
+func findReplacement(agent *poolagent.Agent, latestVersions messaging.AgentPayload) (config.AgentConfig, bool) {
+	for _, agentCfg := range latestVersions {
+		if agentCfg.ID == agent.Config().ID && !agentCfg.OneOff() {
+			return agentCfg, true
+		}
+	}
+	return config.AgentConfig{}, false
+}
+
+// drainOrClose closes the idle agents right away and drains the others in the background before
+// stopping them. The agent should not be in the pool anymore. It tells if the agent was closed and
+// the caller should stop it.
+func (ap *AgentPool) drainOrClose(agent *poolagent.Agent) bool {
+	if ap.drainTimeout <= 0 || agent.IsIdle() {
+		agent.Close()
+		return true
+	}
+	go ap.drainAgent(agent)
+	return false
+}
+
+func (ap *AgentPool) drainAgent(agent *poolagent.Agent) {
+	logger := log.WithFields(log.Fields{
+		"agent": agent.Config().ID,
+		"image": agent.Config().Image,
+	})
+	logger.WithField("timeout", ap.drainTimeout).Info("draining")
+	if agent.Drain(ap.drainTimeout) {
+		logger.Info("drained")
+	} else {
+		logger.Warn("failed to drain in time - stopping with the requests in flight")
+	}
+	agent.Close()
+	ap.msgClient.Publish(messaging.SubjectAgentsActionStop, messaging.AgentPayload{agent.Config()})
+}
+
+// releaseReplacedUnsafe removes the old version of the agent from the pool after the new version
+// is attached or fails to start, and then drains and stops the old version. The lock should be
+// held by the caller.
+func (ap *AgentPool) releaseReplacedUnsafe(agentCfg config.AgentConfig) {
+	replaced, ok := ap.replaced[agentCfg.ContainerName()]
+	if !ok {
+		return
+	}
+	delete(ap.replaced, agentCfg.ContainerName())
+
+	var newAgents []*poolagent.Agent
+	for _, agent := range ap.agents {
+		if agent != replaced {
+			newAgents = append(newAgents, agent)
+		}
+	}
+	ap.agents = newAgents
+
+	log.WithFields(log.Fields{
+		"agent":    replaced.Config().ID,
+		"oldImage": replaced.Config().Image,
+		"newImage": agentCfg.Image,
+	}).Info("switched to the new version")
+	if ap.drainOrClose(replaced) {
+		ap.msgClient.Publish(messaging.SubjectAgentsActionStop, messaging.AgentPayload{replaced.Config()})
+	}
+}
@@ -0,0 +1,263 @@
+package agentpool
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/forta-network/forta-core-go/clients/health"
+	"github.com/forta-network/forta-core-go/domain"
+	"github.com/forta-network/forta-core-go/feeds"
+	"github.com/forta-network/forta-core-go/protocol"
+	"github.com/forta-network/forta-node/clients/agentgrpc"
+	"github.com/forta-network/forta-node/clients/messaging"
+	"github.com/forta-network/forta-node/config"
+	"github.com/forta-network/forta-node/services/scanner/agentpool/poolagent"
+	"github.com/google/uuid"
+	log "github.com/sirupsen/logrus"
+)
+
+const (
+	oneOffAgentReadyTimeout    = time.Minute * 5
+	oneOffPendingCheckInterval = time.Millisecond * 100
+	maxFinishedOneOffRuns      = 10
+)
+
+var errOneOffAgentClosed = errors.New("agent stopped before the run was complete")
+
+// OneOffBlockFeed creates the block feed for the block range of a one-off agent run.
+type OneOffBlockFeed func(ctx context.Context, startBlock, stopBlock uint64) (feeds.BlockFeed, error)
+
+// oneOffRun is the progress of a one-off agent run.
+type oneOffRun struct {
+	agentCfg   config.AgentConfig
+	status     string
+	blocks     int
+	finishedAt time.Time
+}
+
+// WithOneOffRuns enables the one-off agent runs which use the given block feeds.
+func (ap *AgentPool) WithOneOffRuns(newBlockFeed OneOffBlockFeed) *AgentPool {
+	ap.newOneOffBlockFeed = newBlockFeed
+	ap.msgClient.Subscribe(messaging.SubjectAgentsActionRunOnce, messaging.AgentsHandler(ap.handleAgentRunOnce))
+	return ap
+}
+
+func (ap *AgentPool) handleAgentRunOnce(payload messaging.AgentPayload) error {
+	for _, agentCfg := range payload {
+		if !agentCfg.OneOff() || agentCfg.StartBlock == nil || agentCfg.StopBlock == nil {
+			log.WithField("agent", agentCfg.ID).Warn("one-off run needs a run ID and a block range - skipping")
+			continue
+		}
+		go ap.runOnce(agentCfg)
+	}
+	return nil
+}
+
+// runOnce runs the agent next to the steady-state agents, sends the blocks and the transactions in
+// the block range only to this agent and stops the agent after the stop block.
+func (ap *AgentPool) runOnce(agentCfg config.AgentConfig) {
+	logger := log.WithFields(log.Fields{
+		"agent":      agentCfg.ID,
+		"runId":      agentCfg.RunID,
+		"startBlock": *agentCfg.StartBlock,
+		"stopBlock":  *agentCfg.StopBlock,
+	})
+
+	run := &oneOffRun{agentCfg: agentCfg, status: "starting"}
+	agent := ap.newAgent(agentCfg)
+	ap.mu.Lock()
+	if ap.oneOffRuns == nil {
+		ap.oneOffRuns = make(map[string]*oneOffRun)
+	}
+	ap.oneOffRuns[agentCfg.ContainerName()] = run
+	ap.agents = append(ap.agents, agent)
+	ap.mu.Unlock()
+	logger.Info("starting one-off agent run")
+	ap.msgClient.Publish(messaging.SubjectAgentsActionRun, messaging.AgentPayload{agentCfg})
+
+	err := ap.feedOneOffAgent(agent, run)
+
+	agent.Close()
+	ap.discardAgent(agent)
+	ap.msgClient.Publish(messaging.SubjectAgentsActionStop, messaging.AgentPayload{agentCfg})
+
+	ap.mu.Lock()
+	defer ap.mu.Unlock()
+	run.finishedAt = time.Now()
+	if err != nil {
+		run.status = fmt.Sprintf("failed: %v", err)
+		logger.WithError(err).WithField("blocks", run.blocks).Error("one-off agent run failed")
+	} else {
+		run.status = "completed"
+		logger.WithField("blocks", run.blocks).Info("one-off agent run completed")
+	}
+	ap.pruneOneOffRuns()
+}
+
+// feedOneOffAgent waits for the agent to start and sends the blocks in the range until the agent
+// evaluates all of them.
+func (ap *AgentPool) feedOneOffAgent(agent *poolagent.Agent, run *oneOffRun) error {
+	select {
+	case <-agent.Ready():
+	case <-agent.Closed():
+		return errOneOffAgentClosed
+	case <-time.After(oneOffAgentReadyTimeout):
+		return errors.New("timed out waiting for the agent to start")
+	case <-ap.ctx.Done():
+		return ap.ctx.Err()
+	}
+
+	ctx, cancel := context.WithCancel(ap.ctx)
+	defer cancel()
+
+	agentCfg := agent.Config()
+	blockFeed, err := ap.newOneOffBlockFeed(ctx, *agentCfg.StartBlock, *agentCfg.StopBlock)
+	if err != nil {
+		return fmt.Errorf("failed to create the block feed: %v", err)
+	}
+	ap.setOneOffRunStatus(run, "running")
+	// only this runner sends requests to the agent so the sent requests are evaluated when the
+	// agent evaluated as many requests
+	var sent uint64
+	errCh := blockFeed.Subscribe(func(evt *domain.BlockEvent) error {
+		n, err := sendOneOffBlock(ctx, agent, evt)
+		sent += n
+		if err != nil {
+			return err
+		}
+		ap.mu.Lock()
+		run.blocks++
+		ap.mu.Unlock()
+		return nil
+	})
+	blockFeed.Start()
+
+	select {
+	case err = <-errCh:
+	case <-agent.Closed():
+		cancel()
+		<-errCh
+		return errOneOffAgentClosed
+	}
+	if err != feeds.ErrEndBlockReached {
+		return err
+	}
+
+	// wait for the agent to evaluate the last requests
+	ticker := time.NewTicker(oneOffPendingCheckInterval)
+	defer ticker.Stop()
+	for agent.Evaluated() < sent {
+		select {
+		case <-ticker.C:
+		case <-agent.Closed():
+			return errOneOffAgentClosed
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+	return nil
+}
+
+// sendOneOffBlock sends the block and the transactions in the block to the agent of a one-off run
+// and returns how many requests were sent. Unlike with the steady-state agents, the requests wait
+// for room in the agent buffers.
+func sendOneOffBlock(ctx context.Context, agent *poolagent.Agent, evt *domain.BlockEvent) (sent uint64, err error) {
+	blockMsg, err := evt.ToMessage()
+	if err != nil {
+		return 0, fmt.Errorf("failed to convert the block: %v", err)
+	}
+	blockReq := &protocol.EvaluateBlockRequest{RequestId: uuid.Must(uuid.NewUUID()).String(), Event: blockMsg}
+	encoded, err := agentgrpc.EncodeMessage(blockReq)
+	if err != nil {
+		return 0, fmt.Errorf("failed to encode the block request: %v", err)
+	}
+	select {
+	case agent.BlockRequestCh() <- &poolagent.BlockRequest{Original: blockReq, Encoded: encoded}:
+		sent++
+	case <-agent.Closed():
+		return 0, errOneOffAgentClosed
+	case <-ctx.Done():
+		return 0, ctx.Err()
+	}
+
+	for _, tx := range evt.Block.Transactions {
+		tx := tx
+		txEvt := &domain.TransactionEvent{
+			BlockEvt:    evt,
+			Transaction: &tx,
+			Timestamps: &domain.TrackingTimestamps{
+				Block: evt.Timestamps.Block,
+				Feed:  time.Now().UTC(),
+			},
+		}
+		txMsg, err := txEvt.ToMessage()
+		if err != nil {
+			return sent, fmt.Errorf("failed to convert the tx %s: %v", tx.Hash, err)
+		}
+		txReq := &protocol.EvaluateTxRequest{RequestId: uuid.Must(uuid.NewUUID()).String(), Event: txMsg}
+		encoded, err := agentgrpc.EncodeMessage(txReq)
+		if err != nil {
+			return sent, fmt.Errorf("failed to encode the tx request: %v", err)
+		}
+		select {
+		case agent.TxRequestCh() <- &poolagent.TxRequest{Original: txReq, Encoded: encoded}:
+			sent++
+		case <-agent.Closed():
+			return sent, errOneOffAgentClosed
+		case <-ctx.Done():
+			return sent, ctx.Err()
+		}
+	}
+	return sent, nil
+}
+
+func (ap *AgentPool) setOneOffRunStatus(run *oneOffRun, status string) {
+	ap.mu.Lock()
+	defer ap.mu.Unlock()
+	run.status = status
+}
+
+// pruneOneOffRuns forgets the oldest finished runs. The lock should be held by the caller.
+func (ap *AgentPool) pruneOneOffRuns() {
+	var finished []string
+	for name, run := range ap.oneOffRuns {
+		if !run.finishedAt.IsZero() {
+			finished = append(finished, name)
+		}
+	}
+	if len(finished) <= maxFinishedOneOffRuns {
+		return
+	}
+	sort.Slice(finished, func(i, j int) bool {
+		return ap.oneOffRuns[finished[i]].finishedAt.Before(ap.oneOffRuns[finished[j]].finishedAt)
+	})
+	for _, name := range finished[:len(finished)-maxFinishedOneOffRuns] {
+		delete(ap.oneOffRuns, name)
+	}
+}
+
+// oneOffRunsReport lists the one-off agent runs. The lock should be held by the caller.
+func (ap *AgentPool) oneOffRunsReport() *health.Report {
+	var runs []string
+	for _, run := range ap.oneOffRuns {
+		agentCfg := run.agentCfg
+		runs = append(runs, fmt.Sprintf(
+			"%s run %s (blocks %d-%d, %d done): %s",
+			agentCfg.ID, agentCfg.RunID, *agentCfg.StartBlock, *agentCfg.StopBlock, run.blocks, run.status,
+		))
+	}
+	sort.Strings(runs)
+	details := "none"
+	if len(runs) > 0 {
+		details = strings.Join(runs, ", ")
+	}
+	return &health.Report{
+		Name:    "agents.runs",
+		Status:  health.StatusInfo,
+		Details: details,
+	}
+}
@@ -0,0 +1,119 @@
+package agentpool
+
+import (
+	"context"
+	"math/big"
+	"time"
+
+	"github.com/forta-network/forta-core-go/clients/health"
+	"github.com/forta-network/forta-core-go/domain"
+	"github.com/forta-network/forta-core-go/feeds"
+	"github.com/forta-network/forta-core-go/protocol"
+	"github.com/forta-network/forta-node/clients/agentgrpc"
+	"github.com/forta-network/forta-node/clients/messaging"
+	"github.com/forta-network/forta-node/config"
+	"github.com/golang/mock/gomock"
+	"google.golang.org/grpc"
+)
+
+// testBlockFeed sends the blocks to the subscriber and then reports the end block.
+type testBlockFeed struct {
+	blocks  []*domain.BlockEvent
+	handler func(evt *domain.BlockEvent) error
+	errCh   chan error
+}
+
+func (feed *testBlockFeed) Start() {
+	go func() {
+		for _, block := range feed.blocks {
+			if err := feed.handler(block); err != nil {
+				feed.errCh <- err
+				return
+			}
+		}
+		feed.errCh <- feeds.ErrEndBlockReached
+	}()
+}
+
+func (feed *testBlockFeed) StartRange(start int64, end int64, rate int64) {}
+
+func (feed *testBlockFeed) IsStarted() bool {
+	return false
+}
+
+func (feed *testBlockFeed) Subscribe(handler func(evt *domain.BlockEvent) error) <-chan error {
+	feed.handler = handler
+	feed.errCh = make(chan error)
+	return feed.errCh
+}
+
+func (feed *testBlockFeed) Name() string {
+	return "test-block-feed"
+}
+
+func (feed *testBlockFeed) Health() health.Reports {
+	return nil
+}
+
+// TestOneOffRun tests running an agent once over a block range.
+func (s *Suite) TestOneOffRun() {
+	startBlock, stopBlock := uint64(100), uint64(100)
+	agentConfig := config.AgentConfig{ID: testAgentID, StartBlock: &startBlock, StopBlock: &stopBlock, RunID: "run1"}
+	agentPayload := messaging.AgentPayload{agentConfig}
+
+	s.msgClient.EXPECT().Subscribe(messaging.SubjectAgentsActionRunOnce, gomock.Any())
+	s.ap.WithOneOffRuns(func(ctx context.Context, start, stop uint64) (feeds.BlockFeed, error) {
+		s.r.Equal(startBlock, start)
+		s.r.Equal(stopBlock, stop)
+		return &testBlockFeed{blocks: []*domain.BlockEvent{
+			{
+				EventType: domain.EventTypeBlock,
+				Block: &domain.Block{
+					Hash:         "0x1",
+					Number:       "0x64",
+					Timestamp:    "0x1",
+					Transactions: []domain.Transaction{{Hash: "0x2", BlockNumber: "0x64"}},
+				},
+				ChainID:    big.NewInt(1),
+				Timestamps: &domain.TrackingTimestamps{},
+			},
+		}}, nil
+	})
+
+	s.agentClient.EXPECT().Initialize(gomock.Any(), gomock.Any()).Return(nil, nil).AnyTimes()
+	s.msgClient.EXPECT().Publish(messaging.SubjectAgentsActionRun, agentPayload).Do(func(string, interface{}) {
+		go func() {
+			// the steady-state agent updates do not stop the one-off run
+			s.r.NoError(s.ap.handleAgentVersionsUpdate(messaging.AgentPayload{}))
+			s.r.NoError(s.ap.handleStatusRunning(agentPayload))
+		}()
+	})
+	s.msgClient.EXPECT().Publish(messaging.SubjectAgentsStatusAttached, gomock.Any())
+	s.agentClient.EXPECT().Invoke(
+		gomock.Any(), agentgrpc.MethodEvaluateBlock,
+		gomock.AssignableToTypeOf(&grpc.PreparedMsg{}), gomock.AssignableToTypeOf(&protocol.EvaluateBlockResponse{}),
+	).Return(nil)
+	s.agentClient.EXPECT().Invoke(
+		gomock.Any(), agentgrpc.MethodEvaluateTx,
+		gomock.AssignableToTypeOf(&grpc.PreparedMsg{}), gomock.AssignableToTypeOf(&protocol.EvaluateTxResponse{}),
+	).Return(nil)
+	s.msgClient.EXPECT().Publish(messaging.SubjectAgentsActionStop, agentPayload)
+	s.agentClient.EXPECT().Close()
+
+	go func() {
+		blockResult := <-s.ap.BlockResults()
+		s.r.Equal("run1", blockResult.AgentConfig.RunID)
+		txResult := <-s.ap.TxResults()
+		s.r.Equal("0x2", txResult.Request.Event.Transaction.Hash)
+	}()
+
+	s.r.NoError(s.ap.handleAgentRunOnce(agentPayload))
+	s.r.Eventually(func() bool {
+		s.ap.mu.RLock()
+		defer s.ap.mu.RUnlock()
+		return s.ap.oneOffRunsReport().Details == "test-agent run run1 (blocks 100-100, 1 done): completed"
+	}, time.Second*10, time.Millisecond*100)
+	s.ap.mu.RLock()
+	defer s.ap.mu.RUnlock()
+	s.r.Empty(s.ap.agents)
+}
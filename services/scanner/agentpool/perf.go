@@ -0,0 +1,108 @@
+package agentpool
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/forta-network/forta-core-go/clients/health"
+	"github.com/forta-network/forta-core-go/protocol"
+	"github.com/forta-network/forta-node/metrics"
+	"github.com/forta-network/forta-node/services/scanner/agentpool/poolagent"
+	log "github.com/sirupsen/logrus"
+)
+
+func (ap *AgentPool) aggregatePerfLoop() {
+	ticker := time.NewTicker(poolagent.PerfWindow)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ap.ctx.Done():
+			return
+		case <-ticker.C:
+			ap.aggregatePerf()
+		}
+	}
+}
+
+// aggregatePerf ends the performance windows of the agents, warns about the slow agents and
+// publishes the aggregates as agent metrics.
+func (ap *AgentPool) aggregatePerf() {
+	ap.mu.RLock()
+	agents := ap.agents
+	ap.mu.RUnlock()
+
+	var metricsList []*protocol.AgentMetric
+	for _, agent := range agents {
+		stats := agent.RotatePerf()
+		agentID := agent.Config().ID
+		if ap.slowAgentThreshold > 0 && stats.SlowerThan(ap.slowAgentThreshold) {
+			log.WithFields(log.Fields{
+				"agent":     agentID,
+				"threshold": ap.slowAgentThreshold.String(),
+				"block":     stats.Block.String(),
+				"tx":        stats.Tx.String(),
+			}).Warn("agent is slow")
+		}
+		metricsList = append(metricsList, perfMetrics(agentID, stats)...)
+	}
+	metrics.SendAgentMetrics(ap.msgClient, metricsList)
+}
+
+func perfMetrics(agentID string, stats poolagent.PerfStats) (ms []*protocol.AgentMetric) {
+	if stats.Block.Count > 0 {
+		ms = append(ms,
+			metrics.CreateAgentMetric(agentID, metrics.MetricBlockLatencyAvg, float64(stats.Block.Avg.Milliseconds())),
+			metrics.CreateAgentMetric(agentID, metrics.MetricBlockLatencyP95, float64(stats.Block.P95.Milliseconds())),
+		)
+	}
+	if stats.Tx.Count > 0 {
+		ms = append(ms,
+			metrics.CreateAgentMetric(agentID, metrics.MetricTxLatencyAvg, float64(stats.Tx.Avg.Milliseconds())),
+			metrics.CreateAgentMetric(agentID, metrics.MetricTxLatencyP95, float64(stats.Tx.P95.Milliseconds())),
+		)
+	}
+	if stats.Timeouts > 0 {
+		ms = append(ms, metrics.CreateAgentMetric(agentID, metrics.MetricTimeout, float64(stats.Timeouts)))
+	}
+	return
+}
+
+// perfReports report the performance of the agents in the last window and the slow agents.
+// The lock should be held by the caller.
+func (ap *AgentPool) perfReports() health.Reports {
+	var perf, slow []string
+	for _, agent := range ap.agents {
+		stats, ok := agent.LastPerf()
+		if !ok {
+			continue
+		}
+		agentID := agent.Config().ID
+		perf = append(perf, fmt.Sprintf("%s (%s)", agentID, stats))
+		if ap.slowAgentThreshold > 0 && stats.SlowerThan(ap.slowAgentThreshold) {
+			slow = append(slow, agentID)
+		}
+	}
+	sort.Strings(perf)
+	sort.Strings(slow)
+	return health.Reports{
+		&health.Report{
+			Name:    "agents.performance",
+			Status:  health.StatusInfo,
+			Details: joinOrNone(perf),
+		},
+		&health.Report{
+			Name:    "agents.slow",
+			Status:  health.StatusInfo,
+			Details: joinOrNone(slow),
+		},
+	}
+}
+
+func joinOrNone(items []string) string {
+	if len(items) == 0 {
+		return "none"
+	}
+	return strings.Join(items, ", ")
+}
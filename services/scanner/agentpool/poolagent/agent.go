@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"regexp"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/ethereum/go-ethereum/common"
@@ -47,8 +48,11 @@ type Agent struct {
 	combinationRequests chan *CombinationRequest // never closed - deallocated when agent is discarded
 	combinationResults  chan<- *scanner.CombinationAlertResult
 
-	errCounter *errorCounter
-	msgClient  clients.MessageClient
+	errCounter     *errorCounter
+	timeouts       Timeouts
+	timeoutCounter *timeoutCounter
+	perf           *perfCounter
+	msgClient      clients.MessageClient
 
 	client    clients.AgentClient
 	ready     chan struct{}
@@ -56,6 +60,10 @@ type Agent struct {
 	closed    chan struct{}
 	closeOnce sync.Once
 	initWait  sync.WaitGroup
+	// evaluated counts the tx and block requests which were sent to the agent.
+	evaluated atomic.Uint64
+	// inFlight counts the requests which are being processed.
+	inFlight atomic.Int64
 
 	mu          sync.RWMutex
 }
@@ -114,6 +122,9 @@ func New(ctx context.Context, agentCfg config.AgentConfig, msgClient clients.Mes
 		combinationRequests: make(chan *CombinationRequest, DefaultBufferSize),
 		combinationResults:  alertResults,
 		errCounter:          NewErrorCounter(3, isCriticalErr),
+		timeouts:            Timeouts{}.withDefaults(),
+		timeoutCounter:      NewTimeoutCounter(DefaultMaxConsecutiveTimeouts),
+		perf:                newPerfCounter(time.Now()),
 		msgClient:           msgClient,
 		ready:               make(chan struct{}),
 		closed:              make(chan struct{}),
@@ -140,7 +151,41 @@ func (agent *Agent) LogStatus() {
 
 // TxBufferIsFull tells if an agent input buffer is full.
 func (agent *Agent) TxBufferIsFull() bool {
-	return len(agent.txRequests) == DefaultBufferSize
+	return len(agent.txRequests) == cap(agent.txRequests)
+}
+
+// SetBufferSize sets the size of the tx and the block request buffers. It should be called before
+// the agent starts processing.
+func (agent *Agent) SetBufferSize(size int) {
+	agent.txRequests = make(chan *TxRequest, size)
+	agent.blockRequests = make(chan *BlockRequest, size)
+}
+
+// BufferDepths returns the number of the tx and the block requests waiting in the agent buffers.
+func (agent *Agent) BufferDepths() (tx, block int) {
+	return len(agent.txRequests), len(agent.blockRequests)
+}
+
+// DropOldestTxRequest removes the oldest tx request from the buffer to make room for a new one.
+// It tells if a request was removed.
+func (agent *Agent) DropOldestTxRequest() bool {
+	select {
+	case <-agent.txRequests:
+		return true
+	default:
+		return false
+	}
+}
+
+// HasPendingRequests tells if there are tx or block requests waiting in the agent buffers.
+func (agent *Agent) HasPendingRequests() bool {
+	return len(agent.txRequests) > 0 || len(agent.blockRequests) > 0
+}
+
+// Evaluated returns how many tx and block requests were sent to the agent, including the failed
+// ones.
+func (agent *Agent) Evaluated() uint64 {
+	return agent.evaluated.Load()
 }
 
 // Config returns the agent config.
@@ -224,6 +269,7 @@ func (agent *Agent) StartProcessing() {
 	go agent.processTransactions()
 	go agent.processBlocks()
 	go agent.processCombinationAlerts()
+	go agent.healthCheck()
 }
 
 func (agent *Agent) initialize() {
@@ -293,7 +339,8 @@ func (agent *Agent) processTransactions() {
 		if agent.IsClosed() {
 			return
 		}
-		ctx, cancel := context.WithTimeout(agent.ctx, AgentTimeout)
+		agent.inFlight.Add(1)
+		ctx, cancel := context.WithTimeout(agent.ctx, agent.timeouts.Tx)
 		lg.WithField("duration", time.Since(startTime)).Debugf("sending request")
 		resp := new(protocol.EvaluateTxResponse)
 
@@ -301,6 +348,8 @@ func (agent *Agent) processTransactions() {
 		err := agent.client.Invoke(ctx, agentgrpc.MethodEvaluateTx, request.Encoded, resp)
 		responseTime := time.Now().UTC()
 		cancel()
+		agent.evaluated.Add(1)
+		agent.checkTimeout(err, agentgrpc.MethodEvaluateTx)
 		if err == nil {
 			// truncate findings
 			if len(resp.Findings) > MaxFindings {
@@ -309,6 +358,9 @@ func (agent *Agent) processTransactions() {
 				agent.msgClient.PublishProto(messaging.SubjectMetricAgent, &protocol.AgentMetricList{Metrics: []*protocol.AgentMetric{droppedMetric}})
 				resp.Findings = resp.Findings[:MaxFindings]
 			}
+			perf := agent.perf.window()
+			perf.tx.observe(responseTime.Sub(requestTime))
+			perf.findings.Add(uint64(len(resp.Findings)))
 			var duration time.Duration
 			resp.Timestamp, resp.LatencyMs, duration = calculateResponseTime(&startTime)
 			lg.WithField("duration", duration).Debugf("request successful")
@@ -328,9 +380,11 @@ func (agent *Agent) processTransactions() {
 				Response:    resp,
 				Timestamps:  ts,
 			}
+			agent.inFlight.Add(-1)
 			lg.WithField("duration", time.Since(startTime)).Debugf("sent results")
 			continue
 		}
+		agent.inFlight.Add(-1)
 		lg.WithField("duration", time.Since(startTime)).WithError(err).Error("error invoking agent")
 		if agent.errCounter.TooManyErrs(err) {
 			lg.WithField("duration", time.Since(startTime)).Error("too many errors - shutting down agent")
@@ -366,13 +420,16 @@ func (agent *Agent) processBlocks() {
 			return
 		}
 
-		ctx, cancel := context.WithTimeout(agent.ctx, AgentTimeout)
+		agent.inFlight.Add(1)
+		ctx, cancel := context.WithTimeout(agent.ctx, agent.timeouts.Block)
 		lg.WithField("duration", time.Since(startTime)).Debugf("sending request")
 		resp := new(protocol.EvaluateBlockResponse)
 		requestTime := time.Now().UTC()
 		err := agent.client.Invoke(ctx, agentgrpc.MethodEvaluateBlock, request.Encoded, resp)
 		responseTime := time.Now().UTC()
 		cancel()
+		agent.evaluated.Add(1)
+		agent.checkTimeout(err, agentgrpc.MethodEvaluateBlock)
 		if err == nil {
 			// truncate findings
 			if len(resp.Findings) > MaxFindings {
@@ -381,6 +438,9 @@ func (agent *Agent) processBlocks() {
 				agent.msgClient.PublishProto(messaging.SubjectMetricAgent, &protocol.AgentMetricList{Metrics: []*protocol.AgentMetric{droppedMetric}})
 				resp.Findings = resp.Findings[:MaxFindings]
 			}
+			perf := agent.perf.window()
+			perf.block.observe(responseTime.Sub(requestTime))
+			perf.findings.Add(uint64(len(resp.Findings)))
 			var duration time.Duration
 			resp.Timestamp, resp.LatencyMs, duration = calculateResponseTime(&startTime)
 			lg.WithField("duration", duration).Debugf("request successful")
@@ -400,9 +460,11 @@ func (agent *Agent) processBlocks() {
 				Response:    resp,
 				Timestamps:  ts,
 			}
+			agent.inFlight.Add(-1)
 			lg.WithField("duration", time.Since(startTime)).Debugf("sent results")
 			continue
 		}
+		agent.inFlight.Add(-1)
 		lg.WithField("duration", time.Since(startTime)).WithError(err).Error("error invoking agent")
 		if agent.errCounter.TooManyErrs(err) {
 			lg.WithField("duration", time.Since(startTime)).Error("too many errors - shutting down agent")
@@ -430,6 +492,7 @@ func (agent *Agent) processCombinationAlerts() {
 			return
 		}
 
+		agent.inFlight.Add(1)
 		ctx, cancel := context.WithTimeout(agent.ctx, AgentTimeout)
 		lg.WithField("duration", time.Since(startTime)).Debugf("sending request")
 		resp := new(protocol.EvaluateAlertResponse)
@@ -442,6 +505,7 @@ func (agent *Agent) processCombinationAlerts() {
 			lg.WithField("duration", time.Since(startTime)).WithError(err).Error("error invoking agent")
 			if agent.errCounter.TooManyErrs(err) {
 				lg.WithField("duration", time.Since(startTime)).Error("too many errors - shutting down agent")
+				agent.inFlight.Add(-1)
 				agent.Close()
 				agent.msgClient.Publish(messaging.SubjectAgentsActionStop, messaging.AgentPayload{agent.config})
 				return
@@ -451,6 +515,7 @@ func (agent *Agent) processCombinationAlerts() {
 		// validate response
 		if vErr := validateEvaluateAlertResponse(resp); vErr != nil {
 			lg.WithField("request", request.Original.RequestId).WithError(vErr).Error("evaluate combination response validation failed")
+			agent.inFlight.Add(-1)
 			continue
 		}
 
@@ -482,6 +547,7 @@ func (agent *Agent) processCombinationAlerts() {
 			Response:    resp,
 			Timestamps:  ts,
 		}
+		agent.inFlight.Add(-1)
 
 		lg.WithField("duration", time.Since(startTime)).Debugf("sent results")
 		continue
@@ -535,21 +601,7 @@ func calculateResponseTime(startTime *time.Time) (timestamp string, latencyMs ui
 // ShouldProcessBlock tells if the agent should process block.
 func (agent *Agent) ShouldProcessBlock(blockNumberHex string) bool {
 	blockNumber, _ := hexutil.DecodeUint64(blockNumberHex)
-	var isAtLeastStartBlock bool
-	if agent.config.StartBlock != nil {
-		isAtLeastStartBlock = blockNumber >= *agent.config.StartBlock
-	} else {
-		isAtLeastStartBlock = true
-	}
-
-	var isAtMostStopBlock bool
-	if agent.config.StopBlock != nil {
-		isAtMostStopBlock = blockNumber <= *agent.config.StopBlock
-	} else {
-		isAtMostStopBlock = true
-	}
-
-	return isAtLeastStartBlock && isAtMostStopBlock
+	return agent.config.InBlockRange(blockNumber)
 }
 
 func (agent *Agent) ShouldProcessAlert(event *protocol.AlertEvent) bool {
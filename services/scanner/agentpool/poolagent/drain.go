@@ -0,0 +1,41 @@
+package poolagent
+
+import (
+	"time"
+)
+
+// drainCheckInterval is how often a draining agent is checked for the remaining requests.
+const drainCheckInterval = 100 * time.Millisecond
+
+// IsIdle tells if the agent has no buffered requests and no requests in flight.
+func (agent *Agent) IsIdle() bool {
+	return agent.inFlight.Load() == 0 && !agent.HasPendingRequests() && len(agent.combinationRequests) == 0
+}
+
+// InFlightRequests returns the number of the requests which are buffered or being processed.
+func (agent *Agent) InFlightRequests() int {
+	return int(agent.inFlight.Load()) + len(agent.txRequests) + len(agent.blockRequests) + len(agent.combinationRequests)
+}
+
+// Drain waits until the agent processes the buffered requests and the requests in flight. The
+// results are still sent while draining so the caller should only stop sending new requests.
+// It tells if the agent was drained before the timeout.
+func (agent *Agent) Drain(timeout time.Duration) bool {
+	deadline := time.NewTimer(timeout)
+	defer deadline.Stop()
+	ticker := time.NewTicker(drainCheckInterval)
+	defer ticker.Stop()
+
+	for {
+		if agent.IsIdle() {
+			return true
+		}
+		select {
+		case <-agent.closed:
+			return false
+		case <-deadline.C:
+			return false
+		case <-ticker.C:
+		}
+	}
+}
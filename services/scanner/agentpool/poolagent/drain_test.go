@@ -0,0 +1,54 @@
+package poolagent
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/forta-network/forta-node/config"
+	"github.com/forta-network/forta-node/services/scanner"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAgentDrain(t *testing.T) {
+	r := require.New(t)
+
+	blockResults := make(chan *scanner.BlockResult, 1)
+	agent := New(context.Background(), config.AgentConfig{ID: "agent"}, nil, nil, blockResults, nil)
+	r.True(agent.IsIdle())
+	r.True(agent.Drain(time.Millisecond))
+
+	// the request in flight completes within the timeout and the result is sent
+	agent.SetTimeouts(Timeouts{Block: time.Second * 5})
+	agent.SetClient(startSleepingAgent(t, time.Millisecond*200))
+	go agent.processBlocks()
+	defer agent.Close()
+	agent.BlockRequestCh() <- testBlockRequest(t)
+	r.False(agent.IsIdle())
+	r.Equal(1, agent.InFlightRequests())
+
+	r.True(agent.Drain(time.Second * 5))
+	r.True(agent.IsIdle())
+	r.Zero(agent.InFlightRequests())
+	r.Len(blockResults, 1)
+}
+
+func TestAgentDrainTimeout(t *testing.T) {
+	r := require.New(t)
+
+	blockResults := make(chan *scanner.BlockResult, 1)
+	agent := New(context.Background(), config.AgentConfig{ID: "agent"}, nil, nil, blockResults, nil)
+
+	// the request in flight takes longer than the timeout
+	agent.SetTimeouts(Timeouts{Block: time.Second * 5})
+	agent.SetClient(startSleepingAgent(t, time.Second*5))
+	go agent.processBlocks()
+	defer agent.Close()
+	agent.BlockRequestCh() <- testBlockRequest(t)
+
+	start := time.Now()
+	r.False(agent.Drain(time.Millisecond * 200))
+	r.Less(time.Since(start), time.Second)
+	r.False(agent.IsIdle())
+	r.Empty(blockResults)
+}
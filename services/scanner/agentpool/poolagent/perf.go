@@ -0,0 +1,180 @@
+package poolagent
+
+import (
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// PerfWindow is the length of the windows which the agent performance is aggregated over.
+const PerfWindow = 5 * time.Minute
+
+// latencyBuckets are the upper bounds of the latency histogram buckets. The last bucket counts
+// the latencies above the last bound.
+var latencyBuckets = [...]time.Duration{
+	5 * time.Millisecond,
+	10 * time.Millisecond,
+	25 * time.Millisecond,
+	50 * time.Millisecond,
+	100 * time.Millisecond,
+	250 * time.Millisecond,
+	500 * time.Millisecond,
+	time.Second,
+	2500 * time.Millisecond,
+	5 * time.Second,
+	10 * time.Second,
+	30 * time.Second,
+}
+
+// latencyWindow is a fixed-size latency histogram which is safe to update concurrently.
+type latencyWindow struct {
+	count   atomic.Uint64
+	sum     atomic.Uint64 // in microseconds
+	max     atomic.Uint64 // in microseconds
+	buckets [len(latencyBuckets) + 1]atomic.Uint64
+}
+
+func (lw *latencyWindow) observe(latency time.Duration) {
+	micros := uint64(latency.Microseconds())
+	lw.count.Add(1)
+	lw.sum.Add(micros)
+	for {
+		max := lw.max.Load()
+		if micros <= max || lw.max.CompareAndSwap(max, micros) {
+			break
+		}
+	}
+	i := 0
+	for i < len(latencyBuckets) && latency > latencyBuckets[i] {
+		i++
+	}
+	lw.buckets[i].Add(1)
+}
+
+// LatencyStats are the aggregated latencies of a window. P95 is the upper bound of the histogram
+// bucket which the 95th percentile falls in, capped by the max latency.
+type LatencyStats struct {
+	Count uint64
+	Avg   time.Duration
+	P95   time.Duration
+	Max   time.Duration
+}
+
+func (lw *latencyWindow) stats() (stats LatencyStats) {
+	stats.Count = lw.count.Load()
+	if stats.Count == 0 {
+		return
+	}
+	stats.Avg = time.Duration(lw.sum.Load()/stats.Count) * time.Microsecond
+	stats.Max = time.Duration(lw.max.Load()) * time.Microsecond
+	stats.P95 = stats.Max
+	rank := (stats.Count*95 + 99) / 100
+	var seen uint64
+	for i, bound := range latencyBuckets {
+		seen += lw.buckets[i].Load()
+		if seen >= rank {
+			if bound < stats.Max {
+				stats.P95 = bound
+			}
+			break
+		}
+	}
+	return
+}
+
+func (stats LatencyStats) String() string {
+	return fmt.Sprintf("avg=%s p95=%s", stats.Avg.Round(time.Millisecond), stats.P95.Round(time.Millisecond))
+}
+
+// perfWindow collects the agent performance in a window.
+type perfWindow struct {
+	start    time.Time
+	block    latencyWindow
+	tx       latencyWindow
+	timeouts atomic.Uint64
+	dropped  atomic.Uint64
+	findings atomic.Uint64
+}
+
+// PerfStats are the aggregated agent performance in a window.
+type PerfStats struct {
+	Start    time.Time
+	End      time.Time
+	Block    LatencyStats
+	Tx       LatencyStats
+	Timeouts uint64
+	Dropped  uint64
+	Findings uint64
+}
+
+// SlowerThan tells if the block or the tx latencies are above the threshold.
+func (stats PerfStats) SlowerThan(threshold time.Duration) bool {
+	return stats.Block.P95 > threshold || stats.Tx.P95 > threshold
+}
+
+func (stats PerfStats) String() string {
+	return fmt.Sprintf("block %s, tx %s, timeouts=%d, dropped=%d, findings=%d",
+		stats.Block, stats.Tx, stats.Timeouts, stats.Dropped, stats.Findings)
+}
+
+// perfCounter counts in the current window which is swapped with a new one at the end of each
+// window so that the hot path only does atomic operations.
+type perfCounter struct {
+	current atomic.Pointer[perfWindow]
+	last    *PerfStats
+	mu      sync.RWMutex // protects the last stats
+}
+
+func newPerfCounter(now time.Time) *perfCounter {
+	pc := &perfCounter{}
+	pc.current.Store(&perfWindow{start: now})
+	return pc
+}
+
+func (pc *perfCounter) window() *perfWindow {
+	return pc.current.Load()
+}
+
+// rotate starts a new window and returns the stats of the ended one. The counts from the
+// requests which are in progress during the rotation may be lost.
+func (pc *perfCounter) rotate(now time.Time) PerfStats {
+	ended := pc.current.Swap(&perfWindow{start: now})
+	stats := PerfStats{
+		Start:    ended.start,
+		End:      now,
+		Block:    ended.block.stats(),
+		Tx:       ended.tx.stats(),
+		Timeouts: ended.timeouts.Load(),
+		Dropped:  ended.dropped.Load(),
+		Findings: ended.findings.Load(),
+	}
+	pc.mu.Lock()
+	pc.last = &stats
+	pc.mu.Unlock()
+	return stats
+}
+
+func (pc *perfCounter) lastStats() (PerfStats, bool) {
+	pc.mu.RLock()
+	defer pc.mu.RUnlock()
+	if pc.last == nil {
+		return PerfStats{}, false
+	}
+	return *pc.last, true
+}
+
+// RotatePerf ends the current performance window of the agent and returns its stats.
+func (agent *Agent) RotatePerf() PerfStats {
+	return agent.perf.rotate(time.Now())
+}
+
+// LastPerf returns the stats of the last ended performance window.
+func (agent *Agent) LastPerf() (PerfStats, bool) {
+	return agent.perf.lastStats()
+}
+
+// CountDropped counts a request which was not sent to the agent because its buffer was full.
+func (agent *Agent) CountDropped() {
+	agent.perf.window().dropped.Add(1)
+}
@@ -0,0 +1,91 @@
+package poolagent
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/forta-network/forta-node/config"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLatencyWindow(t *testing.T) {
+	r := require.New(t)
+
+	var lw latencyWindow
+	r.Equal(LatencyStats{}, lw.stats())
+
+	// 94 fast responses, 6 slow ones
+	for i := 0; i < 94; i++ {
+		lw.observe(10 * time.Millisecond)
+	}
+	for i := 0; i < 6; i++ {
+		lw.observe(4 * time.Second)
+	}
+	stats := lw.stats()
+	r.Equal(uint64(100), stats.Count)
+	r.Equal(249400*time.Microsecond, stats.Avg)
+	// falls in the 2.5s-5s bucket and is capped by the max
+	r.Equal(4*time.Second, stats.P95)
+	r.Equal(4*time.Second, stats.Max)
+
+	// the percentile is capped by the max latency
+	lw = latencyWindow{}
+	lw.observe(time.Minute)
+	r.Equal(time.Minute, lw.stats().P95)
+	lw.observe(7 * time.Millisecond)
+	r.Equal(30*time.Second+3500*time.Microsecond, lw.stats().Avg)
+	r.Equal(time.Minute, lw.stats().P95)
+}
+
+func TestLatencyWindowP95(t *testing.T) {
+	r := require.New(t)
+
+	var lw latencyWindow
+	for i := 0; i < 95; i++ {
+		lw.observe(20 * time.Millisecond)
+	}
+	for i := 0; i < 5; i++ {
+		lw.observe(20 * time.Second)
+	}
+	stats := lw.stats()
+	r.Equal(25*time.Millisecond, stats.P95)
+	r.False(PerfStats{Block: stats}.SlowerThan(time.Second))
+	r.True(PerfStats{Tx: LatencyStats{P95: 2 * time.Second}}.SlowerThan(time.Second))
+}
+
+func TestAgentPerf(t *testing.T) {
+	r := require.New(t)
+
+	agent := New(context.Background(), config.AgentConfig{ID: "agent"}, nil, nil, nil, nil)
+	_, ok := agent.LastPerf()
+	r.False(ok)
+
+	window := agent.perf.window()
+	window.block.observe(100 * time.Millisecond)
+	window.block.observe(300 * time.Millisecond)
+	window.tx.observe(time.Millisecond)
+	window.timeouts.Add(1)
+	window.findings.Add(3)
+	agent.CountDropped()
+	agent.CountDropped()
+
+	stats := agent.RotatePerf()
+	r.Equal(uint64(2), stats.Block.Count)
+	r.Equal(200*time.Millisecond, stats.Block.Avg)
+	r.Equal(300*time.Millisecond, stats.Block.P95)
+	r.Equal(uint64(1), stats.Tx.Count)
+	r.Equal(uint64(1), stats.Timeouts)
+	r.Equal(uint64(2), stats.Dropped)
+	r.Equal(uint64(3), stats.Findings)
+	r.Equal(window.start, stats.Start)
+
+	last, ok := agent.LastPerf()
+	r.True(ok)
+	r.Equal(stats, last)
+
+	// the new window starts empty
+	stats = agent.RotatePerf()
+	r.Equal(uint64(0), stats.Block.Count)
+	r.Equal(uint64(0), stats.Dropped)
+}
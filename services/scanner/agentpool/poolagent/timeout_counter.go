@@ -0,0 +1,47 @@
+package poolagent
+
+import "sync"
+
+// timeoutCounter counts the timeouts of an agent and tells when the agent
+// should be restarted.
+type timeoutCounter struct {
+	max         int
+	consecutive int
+	timeouts    uint64
+	restarts    uint64
+	sync.Mutex
+}
+
+// NewTimeoutCounter creates a new timeout counter.
+func NewTimeoutCounter(max int) *timeoutCounter {
+	return &timeoutCounter{max: max}
+}
+
+// Timeout counts a timeout and tells if the agent timed out too many times
+// in a row. The consecutive timeouts are counted again after the restart.
+func (tc *timeoutCounter) Timeout() (restart bool) {
+	tc.Lock()
+	defer tc.Unlock()
+	tc.timeouts++
+	tc.consecutive++
+	if tc.consecutive < tc.max {
+		return false
+	}
+	tc.consecutive = 0
+	tc.restarts++
+	return true
+}
+
+// Responded resets the consecutive timeouts.
+func (tc *timeoutCounter) Responded() {
+	tc.Lock()
+	defer tc.Unlock()
+	tc.consecutive = 0
+}
+
+// Counts returns the total timeouts and the restarts.
+func (tc *timeoutCounter) Counts() (timeouts, restarts uint64) {
+	tc.Lock()
+	defer tc.Unlock()
+	return tc.timeouts, tc.restarts
+}
@@ -0,0 +1,121 @@
+package poolagent
+
+import (
+	"context"
+	"time"
+
+	"github.com/forta-network/forta-node/clients/agentgrpc"
+	"github.com/forta-network/forta-node/clients/messaging"
+	"github.com/forta-network/forta-node/config"
+	log "github.com/sirupsen/logrus"
+	"google.golang.org/grpc/codes"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/status"
+)
+
+// Default timeouts
+const (
+	DefaultBlockTimeout           = 30 * time.Second
+	DefaultTxTimeout              = 10 * time.Second
+	DefaultHealthCheckInterval    = 30 * time.Second
+	DefaultMaxConsecutiveTimeouts = 5
+)
+
+// Timeouts are the deadlines of the agent requests and the health checks.
+type Timeouts struct {
+	Block               time.Duration
+	Tx                  time.Duration
+	HealthCheckInterval time.Duration
+	// MaxConsecutive is the number of timeouts in a row after which the agent is restarted.
+	MaxConsecutive int
+}
+
+// TimeoutsFromConfig gets the timeouts from the agent runtime config and uses the defaults for
+// the values which are not set.
+func TimeoutsFromConfig(cfg config.AgentRuntimeConfig) Timeouts {
+	return Timeouts{
+		Block:               cfg.BlockTimeout,
+		Tx:                  cfg.TxTimeout,
+		HealthCheckInterval: cfg.HealthCheckInterval,
+		MaxConsecutive:      cfg.MaxConsecutiveTimeouts,
+	}.withDefaults()
+}
+
+func (timeouts Timeouts) withDefaults() Timeouts {
+	if timeouts.Block <= 0 {
+		timeouts.Block = DefaultBlockTimeout
+	}
+	if timeouts.Tx <= 0 {
+		timeouts.Tx = DefaultTxTimeout
+	}
+	if timeouts.HealthCheckInterval <= 0 {
+		timeouts.HealthCheckInterval = DefaultHealthCheckInterval
+	}
+	if timeouts.MaxConsecutive <= 0 {
+		timeouts.MaxConsecutive = DefaultMaxConsecutiveTimeouts
+	}
+	return timeouts
+}
+
+// SetTimeouts sets the timeouts. It should be called before the agent starts processing.
+func (agent *Agent) SetTimeouts(timeouts Timeouts) {
+	agent.timeouts = timeouts.withDefaults()
+	agent.timeoutCounter = NewTimeoutCounter(agent.timeouts.MaxConsecutive)
+}
+
+// TimeoutCounts returns how many times the agent timed out and how many times it was restarted
+// because of the timeouts.
+func (agent *Agent) TimeoutCounts() (timeouts, restarts uint64) {
+	return agent.timeoutCounter.Counts()
+}
+
+// checkTimeout counts the timeouts and asks the supervisor to restart the agent if it times out
+// too many times in a row. The other responses and errors reset the consecutive timeouts.
+func (agent *Agent) checkTimeout(err error, method agentgrpc.Method) {
+	if status.Code(err) != codes.DeadlineExceeded {
+		agent.timeoutCounter.Responded()
+		return
+	}
+	agent.perf.window().timeouts.Add(1)
+	agent.countTimeout(method)
+}
+
+func (agent *Agent) countTimeout(method agentgrpc.Method) {
+	if !agent.timeoutCounter.Timeout() {
+		return
+	}
+	timeouts, restarts := agent.timeoutCounter.Counts()
+	log.WithFields(log.Fields{
+		"agent":    agent.config.ID,
+		"method":   method,
+		"timeouts": timeouts,
+		"restarts": restarts,
+	}).Warn("agent timed out too many times in a row - restarting")
+	agent.msgClient.Publish(messaging.SubjectAgentsActionRestart, messaging.AgentPayload{agent.config})
+}
+
+// healthCheck pings the agent periodically. The agents which do not serve the gRPC health service
+// still respond to the pings with an error. Only the timeouts are counted and the successful pings
+// do not reset the consecutive timeouts, so the agents which answer the pings but hang on the
+// requests are still restarted.
+func (agent *Agent) healthCheck() {
+	agent.initWait.Wait()
+
+	ticker := time.NewTicker(agent.timeouts.HealthCheckInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+		case <-agent.closed:
+			return
+		case <-agent.ctx.Done():
+			return
+		}
+		ctx, cancel := context.WithTimeout(agent.ctx, agent.timeouts.Tx)
+		err := agent.client.Invoke(ctx, agentgrpc.MethodHealthCheck, &healthpb.HealthCheckRequest{}, &healthpb.HealthCheckResponse{})
+		cancel()
+		if status.Code(err) == codes.DeadlineExceeded {
+			agent.countTimeout(agentgrpc.MethodHealthCheck)
+		}
+	}
+}
@@ -0,0 +1,171 @@
+package poolagent
+
+import (
+	"context"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/forta-network/forta-core-go/protocol"
+	"github.com/forta-network/forta-node/clients/agentgrpc"
+	"github.com/forta-network/forta-node/clients/messaging"
+	mock_clients "github.com/forta-network/forta-node/clients/mocks"
+	"github.com/forta-network/forta-node/config"
+	"github.com/forta-network/forta-node/services/scanner"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+)
+
+// sleepingAgentServer accepts the requests but responds only after sleeping.
+type sleepingAgentServer struct {
+	sleep time.Duration
+	protocol.UnimplementedAgentServer
+}
+
+func (as *sleepingAgentServer) EvaluateBlock(ctx context.Context, _ *protocol.EvaluateBlockRequest) (*protocol.EvaluateBlockResponse, error) {
+	select {
+	case <-time.After(as.sleep):
+	case <-ctx.Done():
+	}
+	return &protocol.EvaluateBlockResponse{Status: protocol.ResponseStatus_SUCCESS}, nil
+}
+
+func startSleepingAgent(t *testing.T, sleep time.Duration) *agentgrpc.Client {
+	r := require.New(t)
+
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	r.NoError(err)
+	server := grpc.NewServer()
+	protocol.RegisterAgentServer(server, &sleepingAgentServer{sleep: sleep})
+	healthpb.RegisterHealthServer(server, health.NewServer())
+	go server.Serve(lis)
+	t.Cleanup(server.Stop)
+
+	conn, err := grpc.Dial(lis.Addr().String(), grpc.WithInsecure())
+	r.NoError(err)
+	client := agentgrpc.NewClient()
+	client.WithConn(conn)
+	return client
+}
+
+func testBlockRequest(t *testing.T) *BlockRequest {
+	original := &protocol.EvaluateBlockRequest{
+		RequestId: "1",
+		Event:     &protocol.BlockEvent{Timestamps: &protocol.TrackingTimestamps{}},
+	}
+	encoded, err := agentgrpc.EncodeMessage(original)
+	require.NoError(t, err)
+	return &BlockRequest{Original: original, Encoded: encoded}
+}
+
+func TestAgentTimeoutRestart(t *testing.T) {
+	r := require.New(t)
+	ctrl := gomock.NewController(t)
+	msgClient := mock_clients.NewMockMessageClient(ctrl)
+
+	agentCfg := config.AgentConfig{ID: "agent"}
+	blockResults := make(chan *scanner.BlockResult, 1)
+	agent := New(context.Background(), agentCfg, msgClient, nil, blockResults, nil)
+	agent.SetTimeouts(Timeouts{
+		Block:               time.Millisecond * 50,
+		HealthCheckInterval: time.Hour,
+		MaxConsecutive:      2,
+	})
+	agent.SetClient(startSleepingAgent(t, time.Second))
+
+	restarted := make(chan struct{})
+	msgClient.EXPECT().Publish(messaging.SubjectAgentsActionRestart, messaging.AgentPayload{agentCfg}).
+		Do(func(string, interface{}) { close(restarted) })
+
+	go agent.processBlocks()
+	defer agent.Close()
+	agent.BlockRequestCh() <- testBlockRequest(t)
+	agent.BlockRequestCh() <- testBlockRequest(t)
+
+	select {
+	case <-restarted:
+	case <-time.After(time.Second * 5):
+		r.FailNow("agent was not restarted")
+	}
+	timeouts, restarts := agent.TimeoutCounts()
+	r.Equal(uint64(2), timeouts)
+	r.Equal(uint64(1), restarts)
+	r.Empty(blockResults)
+}
+
+func TestAgentTimeoutReset(t *testing.T) {
+	r := require.New(t)
+	ctrl := gomock.NewController(t)
+	msgClient := mock_clients.NewMockMessageClient(ctrl)
+
+	agentCfg := config.AgentConfig{ID: "agent"}
+	blockResults := make(chan *scanner.BlockResult, 1)
+	agent := New(context.Background(), agentCfg, msgClient, nil, blockResults, nil)
+	agent.SetTimeouts(Timeouts{Block: time.Second, MaxConsecutive: 2})
+
+	// a response between the timeouts resets the consecutive timeouts
+	r.False(agent.timeoutCounter.Timeout())
+	agent.SetClient(startSleepingAgent(t, 0))
+	go agent.processBlocks()
+	defer agent.Close()
+	agent.BlockRequestCh() <- testBlockRequest(t)
+
+	select {
+	case <-blockResults:
+	case <-time.After(time.Second * 5):
+		r.FailNow("no block result")
+	}
+	r.False(agent.timeoutCounter.Timeout())
+	timeouts, restarts := agent.TimeoutCounts()
+	r.Equal(uint64(2), timeouts)
+	r.Equal(uint64(0), restarts)
+}
+
+func TestAgentHealthCheckTimeout(t *testing.T) {
+	r := require.New(t)
+	ctrl := gomock.NewController(t)
+	msgClient := mock_clients.NewMockMessageClient(ctrl)
+
+	agentCfg := config.AgentConfig{ID: "agent"}
+	agent := New(context.Background(), agentCfg, msgClient, nil, nil, nil)
+	agent.SetTimeouts(Timeouts{HealthCheckInterval: time.Millisecond * 10, Tx: time.Millisecond * 50, MaxConsecutive: 1})
+
+	// a server which never responds to the pings
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	r.NoError(err)
+	server := grpc.NewServer()
+	healthpb.RegisterHealthServer(server, &hangingHealthServer{})
+	go server.Serve(lis)
+	defer server.Stop()
+	conn, err := grpc.Dial(lis.Addr().String(), grpc.WithInsecure())
+	r.NoError(err)
+	client := agentgrpc.NewClient()
+	client.WithConn(conn)
+	agent.SetClient(client)
+
+	restarted := make(chan struct{})
+	msgClient.EXPECT().Publish(messaging.SubjectAgentsActionRestart, messaging.AgentPayload{agentCfg}).
+		Do(func(string, interface{}) { close(restarted) })
+	msgClient.EXPECT().Publish(messaging.SubjectAgentsActionRestart, gomock.Any()).AnyTimes()
+
+	go agent.healthCheck()
+	defer agent.Close()
+
+	select {
+	case <-restarted:
+	case <-time.After(time.Second * 5):
+		r.FailNow("agent was not restarted")
+	}
+}
+
+type hangingHealthServer struct {
+	healthpb.UnimplementedHealthServer
+}
+
+func (hs *hangingHealthServer) Check(ctx context.Context, _ *healthpb.HealthCheckRequest) (*healthpb.HealthCheckResponse, error) {
+	<-ctx.Done()
+	return nil, ctx.Err()
+}
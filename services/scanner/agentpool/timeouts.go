@@ -0,0 +1,32 @@
+package agentpool
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/forta-network/forta-core-go/clients/health"
+)
+
+// timeoutsReport lists the agents which timed out and how many times they were restarted because
+// of the timeouts. The lock should be held by the caller.
+func (ap *AgentPool) timeoutsReport() *health.Report {
+	var agents []string
+	for _, agent := range ap.agents {
+		timeouts, restarts := agent.TimeoutCounts()
+		if timeouts == 0 {
+			continue
+		}
+		agents = append(agents, fmt.Sprintf("%s (%d timeouts, %d restarts)", agent.Config().ID, timeouts, restarts))
+	}
+	sort.Strings(agents)
+	details := "none"
+	if len(agents) > 0 {
+		details = strings.Join(agents, ", ")
+	}
+	return &health.Report{
+		Name:    "agents.timeouts",
+		Status:  health.StatusInfo,
+		Details: details,
+	}
+}
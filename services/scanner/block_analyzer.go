@@ -103,6 +103,11 @@ func (t *BlockAnalyzerService) Start() error {
 				EvalBlockResponse: result.Response,
 			}
 
+			if result.AgentConfig.OneOff() {
+				logOneOffFindings(result.AgentConfig, result.Request.Event.BlockNumber, result.Response.Findings)
+				continue
+			}
+
 			if len(result.Response.Findings) == 0 {
 				if err := t.cfg.AlertSender.NotifyWithoutAlert(
 					rt, result.Timestamps,
@@ -27,6 +27,8 @@ type CombinerAlertStreamService struct {
 type CombinerAlertStreamServiceConfig struct {
 	Start uint64
 	End   uint64
+	// Offline disables the alert feed since it needs the alert API.
+	Offline bool
 }
 
 func (t *CombinerAlertStreamService) registerMessageHandlers() {
@@ -59,6 +61,10 @@ func (t *CombinerAlertStreamService) handleAlert(evt *domain.AlertEvent) error {
 
 func (t *CombinerAlertStreamService) Start() error {
 	t.registerMessageHandlers()
+	if t.cfg.Offline {
+		log.Debug("offline mode - not starting the combiner alert feed")
+		return nil
+	}
 	go func() {
 		t.alertFeed.RegisterHandler(t.handleAlert)
 		t.alertFeed.StartRange(t.cfg.Start, t.cfg.End, 0)
@@ -0,0 +1,112 @@
+package scanner
+
+import (
+	"context"
+	"sync/atomic"
+	"time"
+
+	"github.com/forta-network/forta-node/clients"
+	"github.com/forta-network/forta-node/clients/messaging"
+	log "github.com/sirupsen/logrus"
+)
+
+const drainReportInterval = time.Second
+
+// BlockStream is the stream which can stop taking new blocks.
+type BlockStream interface {
+	Pause()
+}
+
+// RequestCounter counts the agent requests which are not processed yet.
+type RequestCounter interface {
+	InFlightRequests() int
+}
+
+// AlertFlusher publishes the collected alerts right away.
+type AlertFlusher interface {
+	Flush()
+	Pending() (alerts int, batches int)
+}
+
+// DrainService stops taking new blocks and flushes the alerts when the supervisor asks for it.
+// The drain state is reported back until the agents finish the requests and there is nothing left
+// to publish.
+type DrainService struct {
+	ctx       context.Context
+	msgClient clients.MessageClient
+	stream    BlockStream
+	requests  RequestCounter
+	flusher   AlertFlusher
+	draining  atomic.Bool
+}
+
+// NewDrainService creates a new drain service.
+func NewDrainService(ctx context.Context, msgClient clients.MessageClient, stream BlockStream, requests RequestCounter, flusher AlertFlusher) *DrainService {
+	return &DrainService{
+		ctx:       ctx,
+		msgClient: msgClient,
+		stream:    stream,
+		requests:  requests,
+		flusher:   flusher,
+	}
+}
+
+func (drain *DrainService) handleDrain(payload messaging.DrainPayload) error {
+	if drain.draining.Swap(true) {
+		// already draining: just let the supervisor know where we are
+		drain.reportState()
+		return nil
+	}
+	log.Info("draining the scanner")
+	drain.stream.Pause()
+	drain.flusher.Flush()
+	go drain.reportUntilDrained()
+	return nil
+}
+
+func (drain *DrainService) reportUntilDrained() {
+	ticker := time.NewTicker(drainReportInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-drain.ctx.Done():
+			return
+		case <-ticker.C:
+			if drain.reportState() {
+				log.Info("drained the scanner")
+				return
+			}
+		}
+	}
+}
+
+// reportState publishes the drain state and tells if drained. The requests in flight are counted
+// first because they can still produce alerts.
+func (drain *DrainService) reportState() bool {
+	requests := drain.requests.InFlightRequests()
+	alerts, batches := drain.flusher.Pending()
+	payload := messaging.DrainPayload{
+		Drained:          requests == 0 && alerts == 0 && batches == 0,
+		InFlightRequests: requests,
+		PendingAlerts:    alerts,
+		QueuedBatches:    batches,
+	}
+	drain.msgClient.Publish(messaging.SubjectNodeDrainState, payload)
+	return payload.Drained
+}
+
+// Start implements the services.Service interface.
+func (drain *DrainService) Start() error {
+	drain.msgClient.Subscribe(messaging.SubjectNodeDrain, messaging.DrainHandler(drain.handleDrain))
+	return nil
+}
+
+// Stop implements the services.Service interface.
+func (drain *DrainService) Stop() error {
+	return nil
+}
+
+// Name implements the services.Service interface.
+func (drain *DrainService) Name() string {
+	return "drain"
+}
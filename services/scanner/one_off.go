@@ -0,0 +1,22 @@
+package scanner
+
+import (
+	"github.com/forta-network/forta-core-go/protocol"
+	"github.com/forta-network/forta-node/config"
+	log "github.com/sirupsen/logrus"
+)
+
+// logOneOffFindings logs the findings of a one-off agent run. The one-off runs are for
+// investigating the past blocks so their findings are not sent as alerts.
+func logOneOffFindings(agentCfg config.AgentConfig, blockNumber string, findings []*protocol.Finding) {
+	for _, finding := range findings {
+		log.WithFields(log.Fields{
+			"agent":       agentCfg.ID,
+			"runId":       agentCfg.RunID,
+			"blockNumber": blockNumber,
+			"alertId":     finding.AlertId,
+			"severity":    finding.Severity.String(),
+			"name":        finding.Name,
+		}).Info("one-off agent run finding: ", finding.Description)
+	}
+}
@@ -95,6 +95,11 @@ func (t *TxAnalyzerService) Start() error {
 				EvalTxResponse: result.Response,
 			}
 
+			if result.AgentConfig.OneOff() {
+				logOneOffFindings(result.AgentConfig, result.Request.Event.Block.BlockNumber, result.Response.Findings)
+				continue
+			}
+
 			if len(result.Response.Findings) == 0 {
 				if err := t.cfg.AlertSender.NotifyWithoutAlert(
 					rt, result.Timestamps,
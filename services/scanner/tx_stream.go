@@ -2,6 +2,8 @@ package scanner
 
 import (
 	"context"
+	"strconv"
+	"sync/atomic"
 	"time"
 
 	"github.com/forta-network/forta-core-go/clients/health"
@@ -20,6 +22,7 @@ type TxStreamService struct {
 	blockOutput chan *domain.BlockEvent
 	txOutput    chan *domain.TransactionEvent
 	txFeed      feeds.TransactionFeed
+	paused      atomic.Bool
 
 	lastBlockActivity health.TimeTracker
 	lastTxActivity    health.TimeTracker
@@ -39,7 +42,26 @@ func (t *TxStreamService) ReadOnlyTxStream() <-chan *domain.TransactionEvent {
 	return t.txOutput
 }
 
+// Pause stops handing over the new blocks. The transactions of the last block are still handed
+// over and the feed waits until the process exits.
+func (t *TxStreamService) Pause() {
+	if !t.paused.Swap(true) {
+		log.Info("paused the block stream")
+	}
+}
+
+func (t *TxStreamService) waitWhilePaused() {
+	for t.paused.Load() {
+		select {
+		case <-t.ctx.Done():
+			return
+		case <-time.After(time.Second):
+		}
+	}
+}
+
 func (t *TxStreamService) handleBlock(evt *domain.BlockEvent) error {
+	t.waitWhilePaused()
 	select {
 	case <-t.ctx.Done():
 		return nil
@@ -115,6 +137,11 @@ func (t *TxStreamService) Health() health.Reports {
 	return health.Reports{
 		t.lastBlockActivity.GetReport("event.block.time"),
 		t.lastTxActivity.GetReport("event.transaction.time"),
+		&health.Report{
+			Name:    "paused",
+			Status:  health.StatusInfo,
+			Details: strconv.FormatBool(t.paused.Load()),
+		},
 	}
 }
 
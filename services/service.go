@@ -12,6 +12,7 @@ import (
 	"github.com/google/uuid"
 	log "github.com/sirupsen/logrus"
 
+	"github.com/forta-network/forta-node/clients/logforward"
 	"github.com/forta-network/forta-node/config"
 )
 
@@ -66,13 +67,22 @@ func ContainerMain(name string, getServices func(ctx context.Context, cfg config
 		return
 	}
 
-	lvl, err := log.ParseLevel(cfg.Log.Level)
-	if err != nil {
-		logger.WithError(err).Error("could not initialize log level")
+	// containers have always logged json
+	if len(cfg.Log.Format) == 0 {
+		cfg.Log.Format = config.LogFormatJSON
+	}
+	if err := config.InitLogging(cfg, name); err != nil {
+		logger.WithError(err).Error("could not initialize logging")
 		return
 	}
-	log.SetLevel(lvl)
-	log.SetFormatter(&log.JSONFormatter{})
+	if cfg.Log.Remote.Enabled() && cfg.Log.Remote.IncludeContainers {
+		forwarder, err := logforward.Install(cfg.Log.Remote, map[string]string{logforward.LabelContainer: name})
+		if err != nil {
+			logger.WithError(err).Error("could not initialize log forwarding")
+			return
+		}
+		defer forwarder.Close()
+	}
 	logger.Info("starting")
 	defer logger.Info("exiting")
 
@@ -141,12 +151,14 @@ func TriggerExit(delay time.Duration) {
 	InterruptMainContext()
 }
 
-// StartServices kicks off all services.
+// StartServices kicks off all services. If a service fails to start, the services which
+// were started are stopped and the start error is returned.
 func StartServices(ctx context.Context, cancelMainCtx context.CancelFunc, logger *log.Entry, services []Service) error {
 	// each service should be able to start successfully within reasonable time
-	for _, service := range services {
+	for i, service := range services {
 		serviceStartedCtx, serviceStarted := context.WithCancel(context.Background())
 		defer serviceStarted()
+		startErrCh := make(chan error, 1)
 
 		logger := logger.WithField("service", service.Name())
 
@@ -154,7 +166,7 @@ func StartServices(ctx context.Context, cancelMainCtx context.CancelFunc, logger
 			logger.Info("starting service")
 			if err := service.Start(); err != nil {
 				logger.WithError(err).Error("failed to start service")
-				cancelMainCtx()
+				startErrCh <- err
 				return
 			}
 			serviceStarted()
@@ -167,6 +179,11 @@ func StartServices(ctx context.Context, cancelMainCtx context.CancelFunc, logger
 			break
 		case <-serviceStartedCtx.Done():
 			// ok - do nothing
+		case err := <-startErrCh:
+			cancelMainCtx()
+			// the failed service can have started some parts
+			stopServices(logger, services[:i+1])
+			return fmt.Errorf("failed to start %s: %w", service.Name(), err)
 		case <-ctx.Done():
 			return ctx.Err()
 		}
@@ -175,13 +192,7 @@ func StartServices(ctx context.Context, cancelMainCtx context.CancelFunc, logger
 	<-ctx.Done()
 	logger.WithError(ctx.Err()).Info("context is done")
 
-	// stop all services
-	for _, service := range services {
-		serviceLogger := logger.WithField("service", service.Name())
-		serviceLogger.Info("stopping service")
-		err := service.Stop()
-		serviceLogger.WithError(err).Info("stopped service")
-	}
+	stopServices(logger, services)
 
 	if exitTriggered {
 		return ErrExitTriggered
@@ -189,3 +200,12 @@ func StartServices(ctx context.Context, cancelMainCtx context.CancelFunc, logger
 
 	return nil
 }
+
+func stopServices(logger *log.Entry, services []Service) {
+	for _, service := range services {
+		serviceLogger := logger.WithField("service", service.Name())
+		serviceLogger.Info("stopping service")
+		err := service.Stop()
+		serviceLogger.WithError(err).Info("stopped service")
+	}
+}
@@ -2,6 +2,7 @@ package services
 
 import (
 	"context"
+	"errors"
 	"os"
 	"syscall"
 	"testing"
@@ -51,3 +52,50 @@ func TestSigIntSignalCancelsService(t *testing.T) {
 	assert.Error(t, err, context.Canceled)
 	assert.True(t, svc.cancelled)
 }
+
+type failingService struct {
+	stopped bool
+}
+
+func (f *failingService) Start() error {
+	return errors.New("failed to launch")
+}
+
+func (f *failingService) Stop() error {
+	f.stopped = true
+	return nil
+}
+
+func (f *failingService) Name() string {
+	return "failing"
+}
+
+type startedService struct {
+	stopped bool
+}
+
+func (s *startedService) Start() error {
+	return nil
+}
+
+func (s *startedService) Stop() error {
+	s.stopped = true
+	return nil
+}
+
+func (s *startedService) Name() string {
+	return "started"
+}
+
+func TestStartFailureIsReturned(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	started := &startedService{}
+	failing := &failingService{}
+	err := StartServices(ctx, cancel, logrus.NewEntry(logrus.StandardLogger()), []Service{started, failing})
+	assert.ErrorContains(t, err, "failed to start failing: failed to launch")
+	assert.True(t, started.stopped)
+	assert.True(t, failing.stopped)
+	assert.Error(t, ctx.Err())
+}
@@ -111,6 +111,10 @@ func (storage *Storage) prepareAndSendBloom(ctx context.Context, user *userInfo)
 		return fmt.Errorf("failed to get peer id: %v", err)
 	}
 
+	if storage.router == nil {
+		logger.Debug("no ipfs router - skipping provide call")
+		return nil
+	}
 	if err := storage.router.Provide(ctx, user.User, idResp.ID, bloomEncoded); err != nil {
 		return fmt.Errorf("failed to update router: %v", err)
 	}
@@ -44,10 +44,13 @@ func NewStorage(ctx context.Context, ipfsURL, routerURL string) (*Storage, error
 	storage := &Storage{
 		ctx:     ctx,
 		ipfs:    ipfsclient.New(ipfsURL),
-		router:  ipfsrouter.NewClient(routerURL),
 		server:  grpc.NewServer(),
 		lsCache: cache.New(time.Minute*5, time.Minute*5),
 	}
+	// the router is not used if it is not configured (e.g. offline)
+	if len(routerURL) > 0 {
+		storage.router = ipfsrouter.NewClient(routerURL)
+	}
 	protocol.RegisterStorageServer(storage.server, storage)
 	return storage, nil
 }
@@ -0,0 +1,156 @@
+package supervisor
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/forta-network/forta-core-go/clients/health"
+	"github.com/forta-network/forta-node/clients/messaging"
+	"github.com/forta-network/forta-node/config"
+	log "github.com/sirupsen/logrus"
+)
+
+var errAgentDisabled = errors.New("agent is disabled")
+
+// isAgentDisabled tells if the agent is disabled and remembers the agent config so that the agent
+// can be started when it is enabled again. The agents are not disabled if the disabled agents
+// cannot be read.
+func (sup *SupervisorService) isAgentDisabled(agent config.AgentConfig) bool {
+	if sup.isAgentDisabledInConfig(agent.ID) {
+		return true
+	}
+	disabled, err := sup.disabledAgents.IsDisabled(agent.ID)
+	if err != nil {
+		agentLogger(agent).WithError(err).Error("failed to check if the agent is disabled")
+		return false
+	}
+	if disabled {
+		sup.disableMu.Lock()
+		sup.disabledAgentConfigs[agent.ID] = agent
+		sup.disableMu.Unlock()
+	}
+	return disabled
+}
+
+func (sup *SupervisorService) isAgentDisabledInConfig(agentID string) bool {
+	for _, disabledID := range sup.config.Config.Agent.Disabled {
+		if strings.EqualFold(disabledID, agentID) {
+			return true
+		}
+	}
+	return false
+}
+
+// DisableAgent stops the containers of the agent and keeps the agent from starting until it is
+// enabled again.
+func (sup *SupervisorService) DisableAgent(agentID, reason string) error {
+	if _, err := sup.disabledAgents.Disable(agentID, reason); err != nil {
+		return err
+	}
+	logger := log.WithFields(log.Fields{
+		"agentId": agentID,
+		"reason":  reason,
+	})
+	logger.Warn("disabled the agent")
+
+	var payload messaging.AgentPayload
+	sup.mu.RLock()
+	for _, container := range sup.containers {
+		if container.IsAgent && container.AgentConfig.ID == agentID {
+			payload = append(payload, *container.AgentConfig)
+		}
+	}
+	sup.mu.RUnlock()
+	if len(payload) == 0 {
+		sup.removeDisabledWaitingAgent(agentID)
+		return nil
+	}
+
+	sup.disableMu.Lock()
+	sup.disabledAgentConfigs[agentID] = payload[0]
+	sup.disableMu.Unlock()
+	if err := sup.handleAgentStop(payload); err != nil {
+		return fmt.Errorf("failed to stop the disabled agent: %v", err)
+	}
+	logger.Info("stopped the disabled agent")
+	return nil
+}
+
+// EnableAgent lets the agent start again and tells if it was disabled. The agent is started
+// right away if it was requested to run while it was disabled.
+func (sup *SupervisorService) EnableAgent(agentID string) (bool, error) {
+	enabled, err := sup.disabledAgents.Enable(agentID)
+	if err != nil || !enabled {
+		return false, err
+	}
+	log.WithField("agentId", agentID).Info("enabled the agent")
+
+	sup.disableMu.Lock()
+	agent, ok := sup.disabledAgentConfigs[agentID]
+	delete(sup.disabledAgentConfigs, agentID)
+	sup.disableMu.Unlock()
+	if ok {
+		go func() {
+			if err := sup.handleAgentRun(messaging.AgentPayload{agent}); err != nil {
+				agentLogger(agent).WithError(err).Error("failed to start the enabled agent")
+			}
+		}()
+	}
+	return true, nil
+}
+
+// removeDisabledWaitingAgent removes the disabled agent from the waiting list and remembers
+// the agent config so that the agent waits again when it is enabled.
+func (sup *SupervisorService) removeDisabledWaitingAgent(agentID string) {
+	sup.mu.Lock()
+	var removed []config.AgentConfig
+	for _, agent := range sup.agentSlots.waiting {
+		if agent.ID == agentID {
+			removed = append(removed, agent.AgentConfig)
+		}
+	}
+	for _, agent := range removed {
+		sup.agentSlots.remove(agent.ContainerName())
+	}
+	sup.mu.Unlock()
+	if len(removed) == 0 {
+		return
+	}
+
+	sup.disableMu.Lock()
+	sup.disabledAgentConfigs[agentID] = removed[0]
+	sup.disableMu.Unlock()
+	log.WithField("agentId", agentID).Info("removed the disabled agent from the waiting list")
+}
+
+func (sup *SupervisorService) disabledAgentsReport() *health.Report {
+	report := &health.Report{
+		Name:   "agents.disabled",
+		Status: health.StatusInfo,
+	}
+	agents, err := sup.disabledAgents.Get()
+	if err != nil {
+		report.Status = health.StatusFailing
+		report.Details = err.Error()
+		return report
+	}
+
+	var disabled []string
+	for _, agentID := range sup.config.Config.Agent.Disabled {
+		disabled = append(disabled, fmt.Sprintf("%s (config)", agentID))
+	}
+	for _, agent := range agents {
+		note := fmt.Sprintf("since %s", agent.DisabledAt.Format(time.RFC3339))
+		if len(agent.Reason) > 0 {
+			note = fmt.Sprintf("%s, %s", agent.Reason, note)
+		}
+		disabled = append(disabled, fmt.Sprintf("%s (%s)", agent.AgentID, note))
+	}
+	report.Details = "none"
+	if len(disabled) > 0 {
+		report.Details = fmt.Sprintf("%d agents: %s", len(disabled), strings.Join(disabled, ", "))
+	}
+	return report
+}
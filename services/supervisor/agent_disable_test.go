@@ -0,0 +1,60 @@
+package supervisor
+
+import (
+	"time"
+
+	"github.com/forta-network/forta-node/clients"
+	"github.com/forta-network/forta-node/clients/messaging"
+	"github.com/golang/mock/gomock"
+)
+
+func (s *Suite) TestAgentDisableWhileRunning() {
+	s.TestAgentRun()
+	agentConfig, agentPayload := testAgentData()
+
+	// disabling stops the running agent
+	s.dockerClient.EXPECT().StopContainer(s.service.ctx, testAgentContainerID, time.Duration(0))
+	s.msgClient.EXPECT().Publish(messaging.SubjectAgentsStatusStopped, agentPayload)
+	s.r.NoError(s.service.DisableAgent(testAgentID, "too many alerts"))
+	_, ok := s.service.getContainerUnsafe(testAgentContainerName)
+	s.r.False(ok)
+	s.r.Contains(s.service.disabledAgentsReport().Details, "test-agent (too many alerts, since")
+
+	// the disabled agent is not started again
+	s.r.NoError(s.service.handleAgentRun(agentPayload))
+
+	// enabling starts the agent again
+	started := make(chan struct{})
+	s.agentImageClient.EXPECT().EnsureLocalImage(gomock.Any(), "agent test-agent", agentConfig.Image).Return(nil)
+	s.dockerClient.EXPECT().CreatePublicNetwork(gomock.Any(), testAgentContainerName).Return(testAgentNetworkID, nil)
+	s.dockerClient.EXPECT().StartContainer(gomock.Any(), gomock.Any()).
+		Return(&clients.DockerContainer{Name: testAgentContainerName, ID: testAgentContainerID}, nil)
+	s.dockerClient.EXPECT().AttachNetwork(gomock.Any(), gomock.Any(), testAgentNetworkID).Times(3)
+	s.msgClient.EXPECT().Publish(messaging.SubjectAgentsStatusRunning, agentPayload).Do(func(string, interface{}) {
+		close(started)
+	})
+
+	enabled, err := s.service.EnableAgent(testAgentID)
+	s.r.NoError(err)
+	s.r.True(enabled)
+	select {
+	case <-started:
+	case <-time.After(time.Second * 5):
+		s.r.FailNow("enabled agent was not started")
+	}
+	s.r.Equal("none", s.service.disabledAgentsReport().Details)
+
+	enabled, err = s.service.EnableAgent(testAgentID)
+	s.r.NoError(err)
+	s.r.False(enabled)
+}
+
+func (s *Suite) TestAgentDisabledInConfig() {
+	_, agentPayload := testAgentData()
+	s.service.config.Config.Agent.Disabled = []string{testAgentID}
+
+	s.r.NoError(s.service.handleAgentRun(agentPayload))
+	_, ok := s.service.getContainerUnsafe(testAgentContainerName)
+	s.r.False(ok)
+	s.r.Equal("1 agents: test-agent (config)", s.service.disabledAgentsReport().Details)
+}
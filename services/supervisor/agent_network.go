@@ -0,0 +1,97 @@
+package supervisor
+
+import (
+	"context"
+
+	"github.com/forta-network/forta-core-go/clients/health"
+	"github.com/forta-network/forta-node/clients"
+	"github.com/forta-network/forta-node/config"
+	log "github.com/sirupsen/logrus"
+)
+
+// agentNetworkPolicy returns the configured agent network policy.
+func (sup *SupervisorService) agentNetworkPolicy() string {
+	if sup.config.Config.Agent.Isolated() {
+		return config.AgentNetworkPolicyIsolated
+	}
+	return config.AgentNetworkPolicyOpen
+}
+
+// createAgentNetwork creates the network of the agent. The isolated agents get an internal network
+// so that they can reach only the node containers which are attached to it.
+func (sup *SupervisorService) createAgentNetwork(ctx context.Context, agent config.AgentConfig) (string, error) {
+	if sup.config.Config.Agent.Isolated() {
+		return sup.client.CreateInternalNetwork(ctx, agent.ContainerName())
+	}
+	return sup.client.CreatePublicNetwork(ctx, agent.ContainerName())
+}
+
+// agentNetworkContainerIDs returns the node containers which should be attached to the agent networks.
+func (sup *SupervisorService) agentNetworkContainerIDs() []string {
+	ids := []string{sup.scannerContainer.ID, sup.jsonRpcContainer.ID, sup.jwtProviderContainer.ID}
+	if sup.egressProxyContainer != nil {
+		ids = append(ids, sup.egressProxyContainer.ID)
+	}
+	return ids
+}
+
+// isAgentNetworkPolicyChanged tells if the agent container was started with another network policy.
+// The containers from before the policy was introduced were started with the open policy.
+func (sup *SupervisorService) isAgentNetworkPolicyChanged(labels map[string]string) bool {
+	policy, ok := labels[clients.DockerLabelFortaAgentNetworkPolicy]
+	if !ok {
+		policy = config.AgentNetworkPolicyOpen
+	}
+	return policy != sup.agentNetworkPolicy()
+}
+
+// egressProxyContainerConfig returns the config of the proxy which forwards the requests from the
+// isolated agents to the allowlisted hosts. The proxy is on the node network so that it can reach
+// the internet and it is attached to the agent networks when the agents start.
+func (sup *SupervisorService) egressProxyContainerConfig(image, hostFortaDir, nodeNetworkID string) clients.DockerContainerConfig {
+	return clients.DockerContainerConfig{
+		Name:  config.DockerEgressProxyContainerName,
+		Image: image,
+		Cmd:   []string{config.DefaultFortaNodeBinaryPath, "egress-proxy"},
+		Env: sup.config.Config.AddRemoteConfigEnv(sup.config.Config.Network.Proxy.AddEnv(map[string]string{
+			config.EnvLogFormat: sup.config.Config.Log.Format,
+		})),
+		Volumes: map[string]string{
+			hostFortaDir: config.DefaultContainerFortaDirPath,
+		},
+		Ports: map[string]string{
+			"": config.DefaultHealthPort, // random host port
+		},
+		NetworkID:   nodeNetworkID,
+		MaxLogFiles: sup.maxLogFiles,
+		LogDriver:   sup.logDriver,
+		LogOpts:     sup.logOpts,
+		MaxLogSize:  sup.maxLogSize,
+		Labels:      sup.config.Config.Docker.AddLabels(nil),
+	}
+}
+
+// startEgressProxy starts the egress proxy if the agents are isolated and some hosts are allowed.
+func (sup *SupervisorService) startEgressProxy(image, hostFortaDir, nodeNetworkID string) error {
+	if !sup.config.Config.Agent.EgressProxyEnabled() {
+		return nil
+	}
+	log.WithField("allowlist", sup.config.Config.Agent.EgressAllowlist).Info("starting the agent egress proxy")
+	egressProxyContainer, err := sup.client.StartContainer(
+		sup.ctx, sup.egressProxyContainerConfig(image, hostFortaDir, nodeNetworkID),
+	)
+	if err != nil {
+		return err
+	}
+	sup.egressProxyContainer = egressProxyContainer
+	sup.addContainerUnsafe(egressProxyContainer)
+	return nil
+}
+
+func (sup *SupervisorService) networkPolicyReport() *health.Report {
+	return &health.Report{
+		Name:    "agents.network-policy",
+		Status:  health.StatusInfo,
+		Details: sup.config.Config.Agent.NetworkPolicyString(),
+	}
+}
@@ -0,0 +1,88 @@
+package supervisor
+
+import (
+	"context"
+
+	"github.com/forta-network/forta-node/clients"
+	"github.com/forta-network/forta-node/clients/messaging"
+	"github.com/forta-network/forta-node/config"
+)
+
+const testEgressProxyContainerID = "test-egress-proxy-container-id"
+
+// isolatedAgentMatcher matches the agent container config with the isolated network policy.
+type isolatedAgentMatcher clients.DockerContainerConfig
+
+// Matches implements the gomock.Matcher interface.
+func (m isolatedAgentMatcher) Matches(x interface{}) bool {
+	c, ok := x.(clients.DockerContainerConfig)
+	if !ok || c.Name != m.Name || c.NetworkID != m.NetworkID || len(c.LinkNetworkIDs) > 0 {
+		return false
+	}
+	for key, value := range m.Env {
+		if c.Env[key] != value {
+			return false
+		}
+	}
+	return c.Labels[clients.DockerLabelFortaAgentNetworkPolicy] == config.AgentNetworkPolicyIsolated
+}
+
+// String implements the gomock.Matcher interface.
+func (m isolatedAgentMatcher) String() string {
+	return (configMatcher)(m).String()
+}
+
+// TestAgentRunIsolated tests running the agent only on an internal network with the egress proxy.
+func (s *Suite) TestAgentRunIsolated() {
+	agentConfig, agentPayload := testAgentData()
+	s.service.config.Config.Agent.NetworkPolicy = config.AgentNetworkPolicyIsolated
+	s.service.config.Config.Agent.EgressAllowlist = []string{"api.example.com"}
+	s.service.egressProxyContainer = &clients.DockerContainer{ID: testEgressProxyContainerID}
+
+	ctx, cancel := context.WithTimeout(s.service.ctx, agentStartTimeout)
+	defer cancel()
+	s.agentImageClient.EXPECT().EnsureLocalImage(ctx, "agent test-agent", agentConfig.Image).Return(nil)
+	s.dockerClient.EXPECT().CreateInternalNetwork(ctx, testAgentContainerName).Return(testAgentNetworkID, nil)
+	s.dockerClient.EXPECT().StartContainer(
+		ctx, (isolatedAgentMatcher)(
+			clients.DockerContainerConfig{
+				Name:      agentConfig.ContainerName(),
+				NetworkID: testAgentNetworkID,
+				Env:       config.EgressProxyEnv(),
+			},
+		),
+	).Return(&clients.DockerContainer{Name: agentConfig.ContainerName(), ID: testAgentContainerID}, nil)
+	s.dockerClient.EXPECT().AttachNetwork(ctx, testScannerContainerID, testAgentNetworkID)
+	s.dockerClient.EXPECT().AttachNetwork(ctx, testProxyContainerID, testAgentNetworkID)
+	s.dockerClient.EXPECT().AttachNetwork(ctx, testJWTProviderContainerID, testAgentNetworkID)
+	s.dockerClient.EXPECT().AttachNetwork(ctx, testEgressProxyContainerID, testAgentNetworkID)
+	s.msgClient.EXPECT().Publish(messaging.SubjectAgentsStatusRunning, agentPayload)
+
+	s.r.NoError(s.service.handleAgentRunWithContext(ctx, agentPayload))
+	s.r.Equal("isolated (egress allowlist: api.example.com)", s.service.networkPolicyReport().Details)
+}
+
+// TestAgentNetworkContainers tests the node containers which are attached to the agent networks.
+func (s *Suite) TestAgentNetworkContainers() {
+	s.r.Equal([]string{testScannerContainerID, testProxyContainerID, testJWTProviderContainerID}, s.service.agentNetworkContainerIDs())
+	s.r.Equal("open", s.service.networkPolicyReport().Details)
+
+	// the egress proxy is only on the node network until the agents start
+	proxyConfig := s.service.egressProxyContainerConfig("image", "/tmp/forta", testNodeNetworkID)
+	s.r.Equal(config.DockerEgressProxyContainerName, proxyConfig.Name)
+	s.r.Equal(testNodeNetworkID, proxyConfig.NetworkID)
+	s.r.Empty(proxyConfig.LinkNetworkIDs)
+
+	s.service.egressProxyContainer = &clients.DockerContainer{ID: testEgressProxyContainerID}
+	s.r.Equal([]string{
+		testScannerContainerID, testProxyContainerID, testJWTProviderContainerID, testEgressProxyContainerID,
+	}, s.service.agentNetworkContainerIDs())
+
+	// the agents are replaced after the policy changes
+	s.r.False(s.service.isAgentNetworkPolicyChanged(map[string]string{}))
+	s.service.config.Config.Agent.NetworkPolicy = config.AgentNetworkPolicyIsolated
+	s.r.True(s.service.isAgentNetworkPolicyChanged(map[string]string{}))
+	s.r.False(s.service.isAgentNetworkPolicyChanged(map[string]string{
+		clients.DockerLabelFortaAgentNetworkPolicy: config.AgentNetworkPolicyIsolated,
+	}))
+}
@@ -0,0 +1,95 @@
+package supervisor
+
+import (
+	"fmt"
+
+	"github.com/forta-network/forta-node/clients/messaging"
+	"github.com/forta-network/forta-node/config"
+	log "github.com/sirupsen/logrus"
+)
+
+// ResetAgents terminates and removes all agent containers, including the leftover ones which are
+// not tracked anymore, and asks for a fresh agent assignment. The service containers keep running.
+// The names of the removed containers are returned.
+func (sup *SupervisorService) ResetAgents() ([]string, error) {
+	containers, err := sup.client.GetContainers(sup.ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get containers list: %v", err)
+	}
+
+	sup.mu.Lock()
+	defer sup.mu.Unlock()
+
+	sup.lastStop.Set()
+
+	var removed []string
+	removedIDs := make(map[string]bool)
+	for _, container := range containers {
+		containerName := container.Names[0][1:]
+		if !config.IsAnyAgentContainerName(containerName) {
+			continue
+		}
+		logger := log.WithFields(log.Fields{
+			"containerName": containerName,
+			"containerId":   container.ID,
+		})
+		if err = sup.removeAgentContainer(container.ID, containerName); err != nil {
+			logger.WithError(err).Error("failed to reset agent container")
+			break
+		}
+		logger.Info("removed the agent container")
+		removed = append(removed, containerName)
+		removedIDs[container.ID] = true
+	}
+
+	// Forget the removed agents and the waiting agents so that they are started
+	// again with the new assignment.
+	var (
+		payload             messaging.AgentPayload
+		remainingContainers []*Container
+	)
+	for _, container := range sup.containers {
+		if container.IsAgent && (removedIDs[container.ID] || err == nil) {
+			payload = append(payload, *container.AgentConfig)
+			sup.clearAgentRestarts(container.AgentConfig.ID)
+			continue
+		}
+		remainingContainers = append(remainingContainers, container)
+	}
+	sup.containers = remainingContainers
+	if err == nil {
+		for _, agent := range sup.agentSlots.waiting {
+			payload = append(payload, agent.AgentConfig)
+		}
+		sup.agentSlots.waiting = nil
+	}
+
+	if len(payload) > 0 {
+		sup.msgClient.Publish(messaging.SubjectAgentsStatusStopped, payload)
+	}
+	if err != nil {
+		return removed, err
+	}
+	sup.msgClient.Publish(messaging.SubjectAgentsActionReset, messaging.AgentPayload{})
+	log.WithField("containers", len(removed)).Info("reset the agents")
+	return removed, nil
+}
+
+func (sup *SupervisorService) removeAgentContainer(containerID, containerName string) error {
+	if err := sup.client.TerminateContainer(
+		sup.ctx, containerID, sup.config.Config.ResourcesConfig.ContainerStopTimeout(),
+	); err != nil {
+		return fmt.Errorf("failed to stop container '%s': %v", containerID, err)
+	}
+	if err := sup.client.RemoveContainer(sup.ctx, containerID); err != nil {
+		return fmt.Errorf("failed to remove container '%s': %v", containerID, err)
+	}
+	if err := sup.client.WaitContainerPrune(sup.ctx, containerID); err != nil {
+		return fmt.Errorf("failed while waiting removal of container '%s': %v", containerID, err)
+	}
+	if err := sup.client.RemoveNetworkByName(sup.ctx, containerName); err != nil {
+		log.WithError(err).WithField("containerName", containerName).Warn("failed to remove agent network")
+		// ignore network removal errs
+	}
+	return nil
+}
@@ -0,0 +1,34 @@
+package supervisor
+
+import (
+	"time"
+
+	"github.com/docker/docker/api/types"
+	"github.com/forta-network/forta-node/clients/messaging"
+	"github.com/forta-network/forta-node/config"
+)
+
+func (s *Suite) TestResetAgents() {
+	s.TestAgentRun()
+	_, agentPayload := testAgentData()
+
+	s.dockerClient.EXPECT().GetContainers(s.service.ctx).Return(
+		[]types.Container{
+			{Names: []string{"/" + config.DockerScannerContainerName}, ID: testGenericContainerID},
+			{Names: []string{"/" + testAgentContainerName}, ID: testAgentContainerID},
+		}, nil,
+	)
+	// only the agent container is removed
+	s.dockerClient.EXPECT().TerminateContainer(s.service.ctx, testAgentContainerID, time.Duration(0))
+	s.dockerClient.EXPECT().RemoveContainer(s.service.ctx, testAgentContainerID)
+	s.dockerClient.EXPECT().WaitContainerPrune(s.service.ctx, testAgentContainerID)
+	s.dockerClient.EXPECT().RemoveNetworkByName(s.service.ctx, testAgentContainerName)
+	s.msgClient.EXPECT().Publish(messaging.SubjectAgentsStatusStopped, agentPayload)
+	s.msgClient.EXPECT().Publish(messaging.SubjectAgentsActionReset, messaging.AgentPayload{})
+
+	removed, err := s.service.ResetAgents()
+	s.r.NoError(err)
+	s.r.Equal([]string{testAgentContainerName}, removed)
+	_, ok := s.service.getContainerUnsafe(testAgentContainerName)
+	s.r.False(ok)
+}
@@ -0,0 +1,329 @@
+package supervisor
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/docker/docker/api/types"
+	"github.com/forta-network/forta-core-go/clients/health"
+	"github.com/forta-network/forta-node/clients/messaging"
+	log "github.com/sirupsen/logrus"
+)
+
+const (
+	agentRestartDelay            = time.Second
+	agentMaxRestartDelay         = time.Minute * 5
+	agentStableRunDuration       = time.Minute * 10
+	agentQuarantineRetryInterval = time.Hour
+	agentOOMWindow               = time.Hour
+
+	defaultMaxAgentCrashes  = 10
+	defaultMaxAgentOOMKills = 3
+)
+
+// agentExit is the last exit of an agent container.
+type agentExit struct {
+	StartedAt  time.Time
+	FinishedAt time.Time
+	// ID identifies the exit so that the same exit is not counted again.
+	ID        string
+	OOMKilled bool
+}
+
+func agentExitFromState(state *types.ContainerState) agentExit {
+	startedAt, _ := time.Parse(time.RFC3339Nano, state.StartedAt)
+	finishedAt, _ := time.Parse(time.RFC3339Nano, state.FinishedAt)
+	return agentExit{
+		StartedAt:  startedAt,
+		FinishedAt: finishedAt,
+		ID:         state.FinishedAt,
+		OOMKilled:  state.OOMKilled,
+	}
+}
+
+// stable tells if the container ran long enough before exiting to forget the previous crashes.
+func (exit agentExit) stable() bool {
+	return !exit.StartedAt.IsZero() && exit.FinishedAt.Sub(exit.StartedAt) >= agentStableRunDuration
+}
+
+// agentRestartPolicy limits the restarts of an agent.
+type agentRestartPolicy struct {
+	maxCrashes  int
+	maxOOMKills int
+	maxRestarts int // unlimited if zero
+	backoff     time.Duration
+	maxBackoff  time.Duration
+}
+
+// restartBackoff returns how long to wait before restarting after the consecutive crashes.
+func (policy agentRestartPolicy) restartBackoff(crashes int) time.Duration {
+	if crashes <= 0 {
+		return 0
+	}
+	delay := policy.backoff
+	for i := 1; i < crashes && delay < policy.maxBackoff; i++ {
+		delay *= 2
+	}
+	if delay > policy.maxBackoff {
+		delay = policy.maxBackoff
+	}
+	return delay
+}
+
+// agentRestartState tracks the crashes of an agent.
+type agentRestartState struct {
+	crashes        int
+	restarts       int
+	oomKills       []time.Time
+	lastExitID     string
+	restartAfter   time.Time
+	quarantined    bool
+	failed         bool
+	quarantineNote string
+}
+
+// recordExit counts the exit and decides when the agent can be restarted. The agents which crash
+// too many times in a row are quarantined and retried hourly. The agents which are OOM killed
+// too many times in an hour are quarantined until they are retried manually. The agents which
+// use up the restart budget are marked failed and wait for a manual retry as well.
+func (state *agentRestartState) recordExit(now time.Time, exit agentExit, policy agentRestartPolicy) {
+	if exit.ID == state.lastExitID {
+		return
+	}
+	state.lastExitID = exit.ID
+
+	if exit.stable() {
+		state.crashes = 0
+		state.restarts = 0
+	}
+	state.crashes++
+
+	if exit.OOMKilled {
+		var kills []time.Time
+		for _, kill := range state.oomKills {
+			if now.Sub(kill) < agentOOMWindow {
+				kills = append(kills, kill)
+			}
+		}
+		state.oomKills = append(kills, now)
+	}
+
+	switch {
+	case policy.maxRestarts > 0 && state.restarts >= policy.maxRestarts:
+		state.quarantine(time.Time{}, fmt.Sprintf("restarted %d times", state.restarts))
+		state.failed = true
+	case exit.OOMKilled && len(state.oomKills) >= policy.maxOOMKills:
+		state.quarantine(time.Time{}, fmt.Sprintf("OOM killed %d times in an hour", len(state.oomKills)))
+	case state.crashes >= policy.maxCrashes:
+		state.quarantine(now.Add(agentQuarantineRetryInterval), fmt.Sprintf("crashed %d times in a row", state.crashes))
+	default:
+		state.restartAfter = now.Add(policy.restartBackoff(state.crashes))
+	}
+}
+
+// quarantine stops restarting the agent until the retry time. The agent is not retried
+// automatically if the retry time is zero.
+func (state *agentRestartState) quarantine(retryAfter time.Time, note string) {
+	state.quarantined = true
+	state.restartAfter = retryAfter
+	state.quarantineNote = note
+}
+
+// canRestart tells if the agent can be restarted now and releases the quarantine if it is time
+// to retry.
+func (state *agentRestartState) canRestart(now time.Time) bool {
+	if state.quarantined {
+		if state.restartAfter.IsZero() || now.Before(state.restartAfter) {
+			return false
+		}
+		state.quarantined = false
+		return true
+	}
+	return !now.Before(state.restartAfter)
+}
+
+func (state *agentRestartState) String() string {
+	if state.restartAfter.IsZero() {
+		return fmt.Sprintf("%s - waiting for a manual retry", state.quarantineNote)
+	}
+	return fmt.Sprintf("%s - retrying at %s", state.quarantineNote, state.restartAfter.UTC().Format(time.RFC3339))
+}
+
+// shouldRestartAgent records the exit of the agent container and tells if the container can be
+// restarted now.
+func (sup *SupervisorService) shouldRestartAgent(knownContainer *Container, exit agentExit) bool {
+	sup.restartMu.Lock()
+	defer sup.restartMu.Unlock()
+
+	agentID := knownContainer.AgentConfig.ID
+	logger := log.WithFields(log.Fields{
+		"agentId": agentID,
+		"name":    knownContainer.Name,
+	})
+
+	state, ok := sup.agentRestarts[agentID]
+	if !ok {
+		state = &agentRestartState{}
+		sup.agentRestarts[agentID] = state
+	}
+
+	if exit.ID != state.lastExitID {
+		wasQuarantined := state.quarantined
+		state.recordExit(time.Now(), exit, sup.agentRestartPolicy(agentID))
+		logger = logger.WithFields(log.Fields{
+			"crashes":   state.crashes,
+			"restarts":  state.restarts,
+			"oomKilled": exit.OOMKilled,
+		})
+		if state.failed && !wasQuarantined {
+			logger.WithField("failure", state.String()).Error("agent used up the restart budget - marked failed")
+		} else if state.quarantined && !wasQuarantined {
+			logger.WithField("quarantine", state.String()).Error("agent keeps exiting - quarantined")
+		} else if !state.quarantined {
+			logger.WithField("restartAfter", state.restartAfter.UTC().Format(time.RFC3339)).Warn("agent exited - delaying the restart")
+		}
+	}
+
+	wasQuarantined := state.quarantined
+	if !state.canRestart(time.Now()) {
+		return false
+	}
+	if wasQuarantined {
+		logger.Info("retrying the quarantined agent")
+	}
+	state.restarts++
+	return true
+}
+
+// agentRestartPolicy returns the restart policy of the agent from the config.
+func (sup *SupervisorService) agentRestartPolicy(agentID string) agentRestartPolicy {
+	restart := sup.config.Config.Agent.AgentRestart(agentID)
+	policy := agentRestartPolicy{
+		maxCrashes:  sup.maxAgentCrashes(),
+		maxOOMKills: sup.maxAgentOOMKills(),
+		maxRestarts: restart.MaxRestarts,
+		backoff:     restart.RestartBackoff,
+		maxBackoff:  restart.RestartMaxBackoff,
+	}
+	if policy.backoff <= 0 {
+		policy.backoff = agentRestartDelay
+	}
+	if policy.maxBackoff <= 0 {
+		policy.maxBackoff = agentMaxRestartDelay
+	}
+	return policy
+}
+
+func (sup *SupervisorService) maxAgentCrashes() int {
+	if maxCrashes := sup.config.Config.Agent.MaxConsecutiveCrashes; maxCrashes > 0 {
+		return maxCrashes
+	}
+	return defaultMaxAgentCrashes
+}
+
+func (sup *SupervisorService) maxAgentOOMKills() int {
+	if maxKills := sup.config.Config.Agent.MaxOOMKillsPerHour; maxKills > 0 {
+		return maxKills
+	}
+	return defaultMaxAgentOOMKills
+}
+
+// RetryQuarantinedAgents releases the quarantined and the failed agents so that they are restarted
+// with the next health check. All of them are retried if the agent ID is empty.
+func (sup *SupervisorService) RetryQuarantinedAgents(agentID string) (agentIDs []string) {
+	sup.restartMu.Lock()
+	defer sup.restartMu.Unlock()
+	for id, state := range sup.agentRestarts {
+		if !state.quarantined || (len(agentID) > 0 && id != agentID) {
+			continue
+		}
+		sup.agentRestarts[id] = &agentRestartState{lastExitID: state.lastExitID}
+		agentIDs = append(agentIDs, id)
+	}
+	sort.Strings(agentIDs)
+	log.WithField("agents", agentIDs).Info("retrying the quarantined agents")
+	return
+}
+
+// clearAgentRestarts forgets the crashes of a stopped agent so that it can start again later.
+func (sup *SupervisorService) clearAgentRestarts(agentID string) {
+	sup.restartMu.Lock()
+	defer sup.restartMu.Unlock()
+	delete(sup.agentRestarts, agentID)
+}
+
+func (sup *SupervisorService) quarantineReport() *health.Report {
+	sup.restartMu.Lock()
+	defer sup.restartMu.Unlock()
+
+	var quarantined []string
+	for agentID, state := range sup.agentRestarts {
+		if state.quarantined && !state.failed {
+			quarantined = append(quarantined, fmt.Sprintf("%s (%s)", agentID, state))
+		}
+	}
+	sort.Strings(quarantined)
+
+	details := "none"
+	if len(quarantined) > 0 {
+		details = fmt.Sprintf("%d agents: %s", len(quarantined), strings.Join(quarantined, ", "))
+	}
+	return &health.Report{
+		Name:    "agents.quarantined",
+		Status:  health.StatusInfo,
+		Details: details,
+	}
+}
+
+// failedAgentsReport fails while there are agents which used up the restart budget.
+func (sup *SupervisorService) failedAgentsReport() *health.Report {
+	sup.restartMu.Lock()
+	defer sup.restartMu.Unlock()
+
+	var failed []string
+	for agentID, state := range sup.agentRestarts {
+		if state.failed {
+			failed = append(failed, fmt.Sprintf("%s (%s)", agentID, state))
+		}
+	}
+	sort.Strings(failed)
+
+	if len(failed) == 0 {
+		return &health.Report{
+			Name:    "agents.failed",
+			Status:  health.StatusOK,
+			Details: "none",
+		}
+	}
+	return &health.Report{
+		Name:    "agents.failed",
+		Status:  health.StatusFailing,
+		Details: fmt.Sprintf("%d agents: %s", len(failed), strings.Join(failed, ", ")),
+	}
+}
+
+// handleAgentRestart stops the agents which stopped responding. The stopped containers are
+// restarted by the health check after the crash-loop backoff like the crashed agents.
+func (sup *SupervisorService) handleAgentRestart(payload messaging.AgentPayload) error {
+	sup.mu.RLock()
+	defer sup.mu.RUnlock()
+
+	for _, agentCfg := range payload {
+		logger := agentLogger(agentCfg)
+
+		container, ok := sup.getContainerUnsafe(agentCfg.ContainerName())
+		if !ok {
+			logger.Warn("container for agent was not found - skipping restart action")
+			continue
+		}
+		if err := sup.client.StopContainer(
+			sup.ctx, container.ID, sup.config.Config.ResourcesConfig.ContainerStopTimeout(),
+		); err != nil {
+			return fmt.Errorf("failed to stop container '%s': %v", container.ID, err)
+		}
+		logger.Warn("stopped the unresponsive agent - will restart")
+	}
+	return nil
+}
@@ -0,0 +1,278 @@
+package supervisor
+
+import (
+	"testing"
+	"time"
+
+	"github.com/docker/docker/api/types"
+	"github.com/forta-network/forta-core-go/clients/health"
+	"github.com/forta-network/forta-node/clients"
+	"github.com/forta-network/forta-node/clients/messaging"
+	"github.com/forta-network/forta-node/config"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAgentRestartBackoff(t *testing.T) {
+	policy := agentRestartPolicy{backoff: agentRestartDelay, maxBackoff: agentMaxRestartDelay}
+	for _, testCase := range []struct {
+		crashes int
+		delay   time.Duration
+	}{
+		{crashes: 0, delay: 0},
+		{crashes: 1, delay: time.Second},
+		{crashes: 2, delay: time.Second * 2},
+		{crashes: 3, delay: time.Second * 4},
+		{crashes: 9, delay: time.Second * 256},
+		{crashes: 10, delay: agentMaxRestartDelay},
+		{crashes: 100, delay: agentMaxRestartDelay},
+	} {
+		require.Equal(t, testCase.delay, policy.restartBackoff(testCase.crashes), "crashes: %d", testCase.crashes)
+	}
+}
+
+func TestAgentRestartState_RecordExit(t *testing.T) {
+	now := time.Now()
+	crash := func(id string) agentExit {
+		return agentExit{ID: id, StartedAt: now.Add(-time.Second), FinishedAt: now}
+	}
+	stableExit := func(id string) agentExit {
+		return agentExit{ID: id, StartedAt: now.Add(-agentStableRunDuration), FinishedAt: now}
+	}
+	oomKill := func(id string) agentExit {
+		exit := crash(id)
+		exit.OOMKilled = true
+		return exit
+	}
+
+	for _, testCase := range []struct {
+		name         string
+		exits        []agentExit
+		crashes      int
+		quarantined  bool
+		restartAfter time.Duration // zero means no restart
+	}{
+		{
+			name:         "first crash",
+			exits:        []agentExit{crash("1")},
+			crashes:      1,
+			restartAfter: time.Second,
+		},
+		{
+			name:         "same exit is counted once",
+			exits:        []agentExit{crash("1"), crash("1")},
+			crashes:      1,
+			restartAfter: time.Second,
+		},
+		{
+			name:         "backoff grows with the crashes",
+			exits:        []agentExit{crash("1"), crash("2"), crash("3")},
+			crashes:      3,
+			restartAfter: time.Second * 4,
+		},
+		{
+			name:         "stable run resets the crashes",
+			exits:        []agentExit{crash("1"), crash("2"), crash("3"), stableExit("4")},
+			crashes:      1,
+			restartAfter: time.Second,
+		},
+		{
+			name:         "unknown start time is not a stable run",
+			exits:        []agentExit{crash("1"), {ID: "2", FinishedAt: now}},
+			crashes:      2,
+			restartAfter: time.Second * 2,
+		},
+		{
+			name:         "quarantined after too many crashes",
+			exits:        []agentExit{crash("1"), crash("2"), crash("3"), crash("4")},
+			crashes:      4,
+			quarantined:  true,
+			restartAfter: agentQuarantineRetryInterval,
+		},
+		{
+			name:        "quarantined after too many OOM kills",
+			exits:       []agentExit{oomKill("1"), crash("2"), oomKill("3")},
+			crashes:     3,
+			quarantined: true,
+		},
+	} {
+		t.Run(testCase.name, func(t *testing.T) {
+			r := require.New(t)
+
+			state := &agentRestartState{}
+			for _, exit := range testCase.exits {
+				state.recordExit(now, exit, agentRestartPolicy{
+					maxCrashes:  4,
+					maxOOMKills: 2,
+					backoff:     agentRestartDelay,
+					maxBackoff:  agentMaxRestartDelay,
+				})
+			}
+			r.Equal(testCase.crashes, state.crashes)
+			r.Equal(testCase.quarantined, state.quarantined)
+			if testCase.restartAfter == 0 {
+				r.True(state.restartAfter.IsZero())
+			} else {
+				r.Equal(now.Add(testCase.restartAfter), state.restartAfter)
+			}
+		})
+	}
+}
+
+func TestAgentRestartState_CanRestart(t *testing.T) {
+	r := require.New(t)
+
+	now := time.Now()
+	state := &agentRestartState{restartAfter: now.Add(time.Second)}
+	r.False(state.canRestart(now))
+	r.True(state.canRestart(now.Add(time.Second)))
+
+	// retried hourly
+	state.quarantine(now.Add(agentQuarantineRetryInterval), "crashed")
+	r.False(state.canRestart(now.Add(time.Minute)))
+	r.True(state.canRestart(now.Add(agentQuarantineRetryInterval)))
+	r.False(state.quarantined)
+
+	// not retried automatically
+	state.quarantine(time.Time{}, "OOM killed")
+	r.False(state.canRestart(now.Add(agentQuarantineRetryInterval * 10)))
+	r.True(state.quarantined)
+}
+
+func (s *Suite) expectAgentExit(oomKilled bool, finishedAt string) {
+	s.dockerClient.EXPECT().InspectContainer(s.service.ctx, testAgentContainerID).Return(&types.ContainerJSON{
+		ContainerJSONBase: &types.ContainerJSONBase{
+			State: &types.ContainerState{OOMKilled: oomKilled, FinishedAt: finishedAt},
+		},
+	}, nil)
+}
+
+// TestAgentOOMQuarantine tests delaying the restarts of an OOM killed agent and quarantining it.
+func (s *Suite) TestAgentOOMQuarantine() {
+	s.TestAgentRun()
+	s.service.config.Config.Agent.MaxOOMKillsPerHour = 3
+
+	agentContainer, ok := s.service.getContainerUnsafe(testAgentContainerName)
+	s.r.True(ok)
+	exited := &types.Container{ID: testAgentContainerID, State: "exited"}
+
+	// the first restart is delayed
+	s.expectAgentExit(true, "t1")
+	s.r.NoError(s.service.ensureUp(agentContainer, exited))
+
+	// the same exit is not counted again after the delay
+	s.service.agentRestarts[testAgentID].restartAfter = time.Now().Add(-time.Second)
+	s.expectAgentExit(true, "t1")
+	s.dockerClient.EXPECT().StartContainer(s.service.ctx, gomock.Any()).Return(&clients.DockerContainer{}, nil)
+	s.r.NoError(s.service.ensureUp(agentContainer, exited))
+	s.r.Len(s.service.agentRestarts[testAgentID].oomKills, 1)
+
+	// the next restart is delayed longer
+	s.expectAgentExit(true, "t2")
+	s.r.NoError(s.service.ensureUp(agentContainer, exited))
+	s.r.True(s.service.agentRestarts[testAgentID].restartAfter.After(time.Now().Add(agentRestartDelay)))
+
+	// quarantined after too many kills
+	s.expectAgentExit(true, "t3")
+	s.r.NoError(s.service.ensureUp(agentContainer, exited))
+	s.expectAgentExit(true, "t3")
+	s.r.NoError(s.service.ensureUp(agentContainer, exited))
+
+	report := s.service.quarantineReport()
+	s.r.Equal(health.StatusInfo, report.Status)
+	s.r.Contains(report.Details, testAgentID)
+	s.r.Contains(report.Details, "manual retry")
+
+	// stopping the agent forgets the quarantine
+	_, agentPayload := testAgentData()
+	s.dockerClient.EXPECT().StopContainer(s.service.ctx, testAgentContainerID, time.Duration(0))
+	s.msgClient.EXPECT().Publish(messaging.SubjectAgentsStatusStopped, agentPayload)
+	s.r.NoError(s.service.handleAgentStop(agentPayload))
+	s.r.Equal("none", s.service.quarantineReport().Details)
+}
+
+// TestAgentCrashRetry tests quarantining a crashing agent and retrying it manually.
+func (s *Suite) TestAgentCrashRetry() {
+	s.TestAgentRun()
+	s.service.config.Config.Agent.MaxConsecutiveCrashes = 2
+
+	agentContainer, ok := s.service.getContainerUnsafe(testAgentContainerName)
+	s.r.True(ok)
+	exited := &types.Container{ID: testAgentContainerID, State: "exited"}
+
+	s.expectAgentExit(false, "t1")
+	s.r.NoError(s.service.ensureUp(agentContainer, exited))
+	s.expectAgentExit(false, "t2")
+	s.r.NoError(s.service.ensureUp(agentContainer, exited))
+	s.r.True(s.service.agentRestarts[testAgentID].quarantined)
+	s.r.Contains(s.service.quarantineReport().Details, "crashed 2 times in a row - retrying at")
+
+	s.r.Empty(s.service.RetryQuarantinedAgents("other-agent"))
+	s.r.Equal([]string{testAgentID}, s.service.RetryQuarantinedAgents(""))
+	s.r.Equal("none", s.service.quarantineReport().Details)
+
+	// restarted right away with the same exit
+	s.expectAgentExit(false, "t2")
+	s.dockerClient.EXPECT().StartContainer(s.service.ctx, gomock.Any()).Return(&clients.DockerContainer{}, nil)
+	s.r.NoError(s.service.ensureUp(agentContainer, exited))
+}
+
+// TestAgentRestartBudget tests marking an agent failed after it uses up the restart budget
+// with the individual backoff.
+func (s *Suite) TestAgentRestartBudget() {
+	s.TestAgentRun()
+	s.service.config.Config.Agent.MaxConsecutiveCrashes = 10
+	s.service.config.Config.Agent.MaxRestarts = 10
+	s.service.config.Config.Agent.Restarts = []config.AgentRestartConfig{
+		{AgentID: testAgentID, MaxRestarts: 2, RestartBackoff: time.Minute},
+	}
+
+	agentContainer, ok := s.service.getContainerUnsafe(testAgentContainerName)
+	s.r.True(ok)
+	exited := &types.Container{ID: testAgentContainerID, State: "exited"}
+
+	for _, exitID := range []string{"t1", "t2"} {
+		s.expectAgentExit(false, exitID)
+		s.r.NoError(s.service.ensureUp(agentContainer, exited))
+		s.r.True(s.service.agentRestarts[testAgentID].restartAfter.After(time.Now().Add(time.Second * 50)))
+		s.service.agentRestarts[testAgentID].restartAfter = time.Now().Add(-time.Second)
+		s.expectAgentExit(false, exitID)
+		s.dockerClient.EXPECT().StartContainer(s.service.ctx, gomock.Any()).Return(&clients.DockerContainer{}, nil)
+		s.r.NoError(s.service.ensureUp(agentContainer, exited))
+	}
+	s.r.Equal(health.StatusOK, s.service.failedAgentsReport().Status)
+
+	// not restarted anymore
+	s.expectAgentExit(false, "t3")
+	s.r.NoError(s.service.ensureUp(agentContainer, exited))
+	s.r.True(s.service.agentRestarts[testAgentID].failed)
+	report := s.service.failedAgentsReport()
+	s.r.Equal(health.StatusFailing, report.Status)
+	s.r.Contains(report.Details, "restarted 2 times - waiting for a manual retry")
+	s.r.Equal("none", s.service.quarantineReport().Details)
+
+	// retried manually with a new budget
+	s.r.Equal([]string{testAgentID}, s.service.RetryQuarantinedAgents(testAgentID))
+	s.r.Equal(health.StatusOK, s.service.failedAgentsReport().Status)
+	s.expectAgentExit(false, "t3")
+	s.dockerClient.EXPECT().StartContainer(s.service.ctx, gomock.Any()).Return(&clients.DockerContainer{}, nil)
+	s.r.NoError(s.service.ensureUp(agentContainer, exited))
+}
+
+// TestAgentTimeoutRestart tests that the unresponsive agents are stopped and then restarted
+// after the backoff.
+func (s *Suite) TestAgentTimeoutRestart() {
+	s.TestAgentRun()
+
+	_, agentPayload := testAgentData()
+	s.dockerClient.EXPECT().StopContainer(s.service.ctx, testAgentContainerID, time.Duration(0))
+	s.r.NoError(s.service.handleAgentRestart(agentPayload))
+
+	// still known so that the health check restarts it
+	agentContainer, ok := s.service.getContainerUnsafe(testAgentContainerName)
+	s.r.True(ok)
+	exited := &types.Container{ID: testAgentContainerID, State: "exited"}
+	s.expectAgentExit(false, "t1")
+	s.r.NoError(s.service.ensureUp(agentContainer, exited))
+	s.r.Equal(1, s.service.agentRestarts[testAgentID].crashes)
+}
@@ -0,0 +1,31 @@
+package supervisor
+
+import (
+	"github.com/forta-network/forta-node/clients/messaging"
+	"github.com/forta-network/forta-node/config"
+	"github.com/forta-network/forta-node/healthutils"
+	"github.com/google/uuid"
+)
+
+// RunAgentOnce launches a one-off run of the agent over the block range. The scanner runs the agent
+// in a separate container next to the steady-state agents and stops it after the stop block.
+func (sup *SupervisorService) RunAgentOnce(req *healthutils.AgentRunRequest) (*healthutils.AgentRunResult, error) {
+	if err := req.Validate(); err != nil {
+		return nil, err
+	}
+	startBlock, stopBlock := req.StartBlock, req.StopBlock
+	agent := config.AgentConfig{
+		ID:         req.ID,
+		Image:      req.Image,
+		StartBlock: &startBlock,
+		StopBlock:  &stopBlock,
+		RunID:      uuid.NewString()[:8],
+	}
+	agentLogger(agent).WithField("startBlock", startBlock).WithField("stopBlock", stopBlock).
+		Info("launching one-off agent run")
+	sup.msgClient.Publish(messaging.SubjectAgentsActionRunOnce, messaging.AgentPayload{agent})
+	return &healthutils.AgentRunResult{
+		RunID:         agent.RunID,
+		ContainerName: agent.ContainerName(),
+	}, nil
+}
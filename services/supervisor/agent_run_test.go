@@ -0,0 +1,28 @@
+package supervisor
+
+import (
+	"github.com/forta-network/forta-node/clients/messaging"
+	"github.com/forta-network/forta-node/healthutils"
+	"github.com/golang/mock/gomock"
+)
+
+func (s *Suite) TestAgentRunOnce() {
+	_, err := s.service.RunAgentOnce(&healthutils.AgentRunRequest{ID: testAgentID, Image: "image", StartBlock: 10, StopBlock: 5})
+	s.r.Error(err)
+
+	var payload messaging.AgentPayload
+	s.msgClient.EXPECT().Publish(messaging.SubjectAgentsActionRunOnce, gomock.Any()).Do(func(_ string, v interface{}) {
+		payload = v.(messaging.AgentPayload)
+	})
+	result, err := s.service.RunAgentOnce(&healthutils.AgentRunRequest{ID: testAgentID, Image: "image", StartBlock: 5, StopBlock: 10})
+	s.r.NoError(err)
+	s.r.Len(payload, 1)
+
+	agent := payload[0]
+	s.r.True(agent.OneOff())
+	s.r.Equal(result.RunID, agent.RunID)
+	s.r.Equal(result.ContainerName, agent.ContainerName())
+	s.r.Equal(testAgentID, agent.ID)
+	s.r.Equal(uint64(5), *agent.StartBlock)
+	s.r.Equal(uint64(10), *agent.StopBlock)
+}
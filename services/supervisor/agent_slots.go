@@ -0,0 +1,168 @@
+package supervisor
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/forta-network/forta-core-go/clients/health"
+	"github.com/forta-network/forta-node/config"
+	log "github.com/sirupsen/logrus"
+)
+
+// waitingAgent is an assigned agent which waits for a slot because the max number of agents are
+// already running.
+type waitingAgent struct {
+	config.AgentConfig
+	// seq keeps the assignment order.
+	seq uint64
+}
+
+// agentSlots tracks the agents which are waiting for a slot and the agents which are starting.
+// It is protected by the container lock.
+type agentSlots struct {
+	waiting  []*waitingAgent
+	starting map[string]bool
+	seq      uint64
+}
+
+// wait adds the agent to the waiting list or updates the config of an already waiting agent.
+func (slots *agentSlots) wait(agent config.AgentConfig) {
+	for _, waiting := range slots.waiting {
+		if waiting.ContainerName() == agent.ContainerName() {
+			waiting.AgentConfig = agent
+			return
+		}
+	}
+	slots.seq++
+	slots.waiting = append(slots.waiting, &waitingAgent{AgentConfig: agent, seq: slots.seq})
+	sort.SliceStable(slots.waiting, func(i, j int) bool {
+		a, b := slots.waiting[i], slots.waiting[j]
+		if a.Priority != b.Priority {
+			return a.Priority > b.Priority
+		}
+		return a.seq < b.seq
+	})
+}
+
+// remove removes the agent from the waiting list and tells if it was waiting.
+func (slots *agentSlots) remove(containerName string) bool {
+	for i, agent := range slots.waiting {
+		if agent.ContainerName() == containerName {
+			slots.waiting = append(slots.waiting[:i], slots.waiting[i+1:]...)
+			return true
+		}
+	}
+	return false
+}
+
+func (slots *agentSlots) setStarting(containerName string, starting bool) {
+	if slots.starting == nil {
+		slots.starting = make(map[string]bool)
+	}
+	if starting {
+		slots.starting[containerName] = true
+	} else {
+		delete(slots.starting, containerName)
+	}
+}
+
+// runningAgentCountUnsafe counts the agents which take a slot. The one-off runs do not take
+// slots because they are requested explicitly.
+func (sup *SupervisorService) runningAgentCountUnsafe() int {
+	count := len(sup.agentSlots.starting)
+	for _, container := range sup.containers {
+		if container.IsAgent && !container.AgentConfig.OneOff() && !sup.agentSlots.starting[container.Name] {
+			count++
+		}
+	}
+	return count
+}
+
+// admitAgentsUnsafe returns the agents which can start now and puts the rest in the waiting list.
+// The waiting agents with higher priorities are admitted before the new ones. The running agents
+// are not stopped for the waiting agents with higher priorities.
+func (sup *SupervisorService) admitAgentsUnsafe(agents []config.AgentConfig) (admitted []config.AgentConfig) {
+	maxConcurrent := sup.config.Config.Agent.MaxConcurrent
+	for _, agent := range agents {
+		_, running := sup.getContainerUnsafe(agent.ContainerName())
+		// the running agents are admitted again so that their status is published
+		if maxConcurrent == 0 || agent.OneOff() || running {
+			admitted = append(admitted, agent)
+			continue
+		}
+		if sup.agentSlots.starting[agent.ContainerName()] {
+			continue
+		}
+		sup.agentSlots.wait(agent)
+	}
+	if maxConcurrent == 0 {
+		return
+	}
+
+	var stillWaiting []*waitingAgent
+	for _, agent := range sup.agentSlots.waiting {
+		// disabled agents do not take slots and they are started again when enabled
+		if sup.isAgentDisabled(agent.AgentConfig) {
+			agentLogger(agent.AgentConfig).Info("waiting agent is disabled - removed from the waiting list")
+			continue
+		}
+		if sup.runningAgentCountUnsafe() >= maxConcurrent {
+			stillWaiting = append(stillWaiting, agent)
+			continue
+		}
+		sup.agentSlots.setStarting(agent.ContainerName(), true)
+		admitted = append(admitted, agent.AgentConfig)
+	}
+	sup.agentSlots.waiting = stillWaiting
+
+	if len(stillWaiting) > 0 {
+		log.WithFields(log.Fields{
+			"waiting":       len(stillWaiting),
+			"maxConcurrent": maxConcurrent,
+		}).Info("max number of agents are running - some agents are waiting")
+	}
+	return
+}
+
+// startWaitingAgents starts the waiting agents if there are free slots.
+func (sup *SupervisorService) startWaitingAgents() {
+	sup.mu.Lock()
+	if len(sup.agentSlots.waiting) == 0 {
+		sup.mu.Unlock()
+		return
+	}
+	admitted := sup.admitAgentsUnsafe(nil)
+	sup.mu.Unlock()
+	if len(admitted) == 0 {
+		return
+	}
+
+	for _, agent := range admitted {
+		agentLogger(agent).Info("starting the waiting agent")
+	}
+	ctx, cancel := context.WithTimeout(sup.ctx, agentStartTimeout)
+	defer cancel()
+	sup.startAgents(ctx, admitted)
+}
+
+// waitingAgentsReportUnsafe lists the agents which wait for a slot in the order they will start.
+func (sup *SupervisorService) waitingAgentsReportUnsafe() *health.Report {
+	report := &health.Report{
+		Name:    "agents.waiting",
+		Status:  health.StatusInfo,
+		Details: "none",
+	}
+	maxConcurrent := sup.config.Config.Agent.MaxConcurrent
+	if maxConcurrent == 0 || len(sup.agentSlots.waiting) == 0 {
+		return report
+	}
+	var agentIDs []string
+	for _, agent := range sup.agentSlots.waiting {
+		agentIDs = append(agentIDs, agent.ID)
+	}
+	report.Details = fmt.Sprintf("%d agents (max concurrent: %d): %s",
+		len(agentIDs), maxConcurrent, strings.Join(agentIDs, ", "))
+	return report
+}
@@ -0,0 +1,116 @@
+package supervisor
+
+import (
+	"time"
+
+	"github.com/forta-network/forta-node/clients"
+	"github.com/forta-network/forta-node/clients/messaging"
+	"github.com/forta-network/forta-node/config"
+	"github.com/golang/mock/gomock"
+)
+
+func testSlotAgents() []config.AgentConfig {
+	return []config.AgentConfig{
+		{ID: "agent-1", Image: testImageRef},
+		{ID: "agent-2", Image: testImageRef},
+		{ID: "agent-3", Image: testImageRef, Priority: 1},
+		{ID: "agent-4", Image: testImageRef},
+	}
+}
+
+func agentIDs(agents []config.AgentConfig) (ids []string) {
+	for _, agent := range agents {
+		ids = append(ids, agent.ID)
+	}
+	return
+}
+
+// runAdmitted adds the containers of the admitted agents as if they were started.
+func (s *Suite) runAdmitted(agents []config.AgentConfig) {
+	for i := range agents {
+		agent := agents[i]
+		s.service.agentSlots.setStarting(agent.ContainerName(), false)
+		s.service.addContainerUnsafe(&clients.DockerContainer{Name: agent.ContainerName(), ID: agent.ID}, &agent)
+	}
+}
+
+func (s *Suite) TestAgentSlotsUnlimited() {
+	agents := testSlotAgents()
+
+	s.r.Equal(agents, s.service.admitAgentsUnsafe(agents))
+	s.r.Empty(s.service.agentSlots.waiting)
+	s.r.Equal("none", s.service.waitingAgentsReportUnsafe().Details)
+}
+
+func (s *Suite) TestAgentSlotsSelection() {
+	s.service.config.Config.Agent.MaxConcurrent = 2
+	agents := testSlotAgents()
+
+	// the agent with the explicit priority runs first and then the assignment order is followed
+	admitted := s.service.admitAgentsUnsafe(agents)
+	s.r.Equal([]string{"agent-3", "agent-1"}, agentIDs(admitted))
+	s.r.Equal("2 agents (max concurrent: 2): agent-2, agent-4", s.service.waitingAgentsReportUnsafe().Details)
+
+	// the starting agents take the slots
+	s.r.Empty(s.service.admitAgentsUnsafe(nil))
+	s.runAdmitted(admitted)
+	s.r.Empty(s.service.admitAgentsUnsafe(nil))
+
+	// the running agents are admitted again and the waiting agents keep waiting
+	s.r.Equal([]string{"agent-1", "agent-3"}, agentIDs(s.service.admitAgentsUnsafe(agents)))
+	s.r.Len(s.service.agentSlots.waiting, 2)
+
+	// the one-off runs do not take slots
+	oneOff := config.AgentConfig{ID: "agent-2", Image: testImageRef, RunID: "run"}
+	s.r.Equal([]string{"agent-2"}, agentIDs(s.service.admitAgentsUnsafe([]config.AgentConfig{oneOff})))
+}
+
+func (s *Suite) TestAgentSlotsRotateOnStop() {
+	s.service.config.Config.Agent.MaxConcurrent = 1
+	agents := testSlotAgents()[:2]
+
+	admitted := s.service.admitAgentsUnsafe(agents)
+	s.r.Equal([]string{"agent-1"}, agentIDs(admitted))
+	s.runAdmitted(admitted)
+
+	// stopping a waiting agent only removes it from the waiting list
+	s.msgClient.EXPECT().Publish(messaging.SubjectAgentsStatusStopped, messaging.AgentPayload{agents[1]})
+	s.r.NoError(s.service.handleAgentStop(messaging.AgentPayload{agents[1]}))
+	s.r.Empty(s.service.agentSlots.waiting)
+	s.r.Empty(s.service.admitAgentsUnsafe([]config.AgentConfig{agents[1]}))
+
+	// removing the running agent starts the waiting agent
+	started := make(chan struct{})
+	s.dockerClient.EXPECT().StopContainer(gomock.Any(), "agent-1", time.Duration(0))
+	s.msgClient.EXPECT().Publish(messaging.SubjectAgentsStatusStopped, messaging.AgentPayload{agents[0]})
+	s.agentImageClient.EXPECT().EnsureLocalImage(gomock.Any(), "agent agent-2", testImageRef).Return(nil)
+	s.dockerClient.EXPECT().CreatePublicNetwork(gomock.Any(), agents[1].ContainerName()).Return(testAgentNetworkID, nil)
+	s.dockerClient.EXPECT().StartContainer(gomock.Any(), gomock.Any()).
+		Return(&clients.DockerContainer{Name: agents[1].ContainerName(), ID: "agent-2"}, nil)
+	s.dockerClient.EXPECT().AttachNetwork(gomock.Any(), gomock.Any(), testAgentNetworkID).Times(3)
+	s.msgClient.EXPECT().Publish(messaging.SubjectAgentsStatusRunning, messaging.AgentPayload{agents[1]}).
+		Do(func(string, interface{}) { close(started) })
+
+	s.r.NoError(s.service.handleAgentStop(messaging.AgentPayload{agents[0]}))
+	select {
+	case <-started:
+	case <-time.After(time.Second * 5):
+		s.r.FailNow("waiting agent was not started")
+	}
+	s.service.mu.RLock()
+	defer s.service.mu.RUnlock()
+	_, ok := s.service.getContainerUnsafe(agents[1].ContainerName())
+	s.r.True(ok)
+	s.r.Empty(s.service.agentSlots.starting)
+	s.r.Equal("none", s.service.waitingAgentsReportUnsafe().Details)
+}
+
+func (s *Suite) TestAgentSlotsDisabledWaitingAgent() {
+	s.service.config.Config.Agent.MaxConcurrent = 1
+	agents := testSlotAgents()[:2]
+
+	s.runAdmitted(s.service.admitAgentsUnsafe(agents))
+	s.r.NoError(s.service.DisableAgent("agent-2", "test"))
+	s.r.Empty(s.service.agentSlots.waiting)
+	s.r.Equal(agents[1], s.service.disabledAgentConfigs["agent-2"])
+}
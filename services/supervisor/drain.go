@@ -0,0 +1,72 @@
+package supervisor
+
+import (
+	"fmt"
+
+	"github.com/forta-network/forta-core-go/clients/health"
+	"github.com/forta-network/forta-node/clients/messaging"
+	"github.com/forta-network/forta-node/config"
+	"github.com/forta-network/forta-node/healthutils"
+	log "github.com/sirupsen/logrus"
+)
+
+// Drain asks the scanner to stop taking new blocks and to flush the alerts. The request is sent
+// again on every call in case the scanner missed it.
+func (sup *SupervisorService) Drain() *healthutils.DrainState {
+	sup.drainMu.Lock()
+	if !sup.drainState.Draining {
+		log.Info("draining the node")
+		sup.drainState = healthutils.DrainState{Draining: true, Details: "waiting for the scanner"}
+	}
+	sup.drainMu.Unlock()
+
+	sup.msgClient.Publish(messaging.SubjectNodeDrain, messaging.DrainPayload{})
+	return sup.DrainState()
+}
+
+// DrainState returns the last drain state reported by the scanner.
+func (sup *SupervisorService) DrainState() *healthutils.DrainState {
+	sup.drainMu.RLock()
+	defer sup.drainMu.RUnlock()
+	state := sup.drainState
+	return &state
+}
+
+func (sup *SupervisorService) handleDrainState(payload messaging.DrainPayload) error {
+	sup.drainMu.Lock()
+	defer sup.drainMu.Unlock()
+	if !sup.drainState.Draining {
+		return nil
+	}
+	if payload.Drained && !sup.drainState.Drained {
+		log.Info("drained the node")
+	}
+	sup.drainState.Drained = payload.Drained
+	sup.drainState.Details = fmt.Sprintf(
+		"%d agent requests in flight, %d pending alerts, %d queued batches",
+		payload.InFlightRequests, payload.PendingAlerts, payload.QueuedBatches,
+	)
+	return nil
+}
+
+func (sup *SupervisorService) drainReport() *health.Report {
+	state := sup.DrainState()
+	details := "not draining"
+	switch {
+	case state.Drained:
+		details = "drained"
+	case state.Draining:
+		details = fmt.Sprintf("draining: %s", state.Details)
+	}
+	return &health.Report{
+		Name:    "drain",
+		Status:  health.StatusInfo,
+		Details: details,
+	}
+}
+
+// AdminToken returns the runner admin token which is required by the drain and the agent retry
+// endpoints.
+func AdminToken() (string, error) {
+	return config.ReadAdminToken(config.DefaultContainerFortaDirPath)
+}
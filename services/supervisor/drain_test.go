@@ -0,0 +1,35 @@
+package supervisor
+
+import (
+	"testing"
+
+	"github.com/forta-network/forta-node/clients/messaging"
+	mock_clients "github.com/forta-network/forta-node/clients/mocks"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDrain(t *testing.T) {
+	r := require.New(t)
+
+	msgClient := mock_clients.NewMockMessageClient(gomock.NewController(t))
+	sup := &SupervisorService{msgClient: msgClient}
+
+	// the state updates are ignored before draining
+	r.NoError(sup.handleDrainState(messaging.DrainPayload{Drained: true}))
+	r.False(sup.DrainState().Drained)
+	r.Equal("not draining", sup.drainReport().Details)
+
+	msgClient.EXPECT().Publish(messaging.SubjectNodeDrain, messaging.DrainPayload{}).Times(2)
+	state := sup.Drain()
+	r.True(state.Draining)
+	r.False(state.Drained)
+
+	r.NoError(sup.handleDrainState(messaging.DrainPayload{InFlightRequests: 2, PendingAlerts: 3, QueuedBatches: 1}))
+	r.Equal("draining: 2 agent requests in flight, 3 pending alerts, 1 queued batches", sup.drainReport().Details)
+
+	r.NoError(sup.handleDrainState(messaging.DrainPayload{Drained: true}))
+	state = sup.Drain()
+	r.True(state.Drained)
+	r.Equal("drained", sup.drainReport().Details)
+}
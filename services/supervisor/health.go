@@ -86,6 +86,10 @@ func (sup *SupervisorService) ensureUp(knownContainer *Container, foundContainer
 			return nil
 		}
 
+		if knownContainer.IsAgent && !sup.shouldRestartAgent(knownContainer, agentExitFromState(containerDetails.State)) {
+			return nil
+		}
+
 		logger.Warn("starting exited container")
 		_, err = sup.client.StartContainer(sup.ctx, knownContainer.Config)
 		if err != nil {
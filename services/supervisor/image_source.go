@@ -0,0 +1,32 @@
+package supervisor
+
+import (
+	"fmt"
+
+	"github.com/forta-network/forta-core-go/clients/health"
+	"github.com/forta-network/forta-node/config"
+)
+
+// checkAgentImageSource checks the agent image against the trusted registries and counts the
+// rejections.
+func (sup *SupervisorService) checkAgentImageSource(agent config.AgentConfig) error {
+	err := sup.config.Config.CheckImageSource("agent", agent.Image)
+	if err != nil {
+		sup.untrustedImages.Add(1)
+		sup.untrustedImage.Set(fmt.Sprintf("%s: %v", agent.ID, err))
+	}
+	return err
+}
+
+func (sup *SupervisorService) untrustedImagesReport() *health.Report {
+	report := &health.Report{
+		Name:    "agents.untrusted-images",
+		Status:  health.StatusInfo,
+		Details: "none",
+	}
+	if count := sup.untrustedImages.Load(); count > 0 {
+		report.Status = health.StatusFailing
+		report.Details = fmt.Sprintf("rejected %d times, last: %s", count, sup.untrustedImage.GetReport("").Details)
+	}
+	return report
+}
@@ -0,0 +1,31 @@
+package supervisor
+
+import (
+	"github.com/forta-network/forta-core-go/clients/health"
+	"github.com/forta-network/forta-node/config"
+)
+
+func (s *Suite) TestUntrustedAgentImage() {
+	agentConfig, _ := testAgentData()
+	s.service.config.Config.Registry.AllowedRegistries = []string{"disco.forta.network"}
+
+	// the image is never pulled
+	err := s.service.startAgent(s.service.ctx, agentConfig)
+	s.r.ErrorIs(err, config.ErrImageRegistryNotAllowed)
+	report := s.service.untrustedImagesReport()
+	s.r.Equal(health.StatusFailing, report.Status)
+	s.r.Contains(report.Details, "rejected 1 times, last: test-agent")
+
+	// the development mode bypasses the allowlist
+	s.service.config.Config.Development = true
+	s.r.NoError(s.service.checkAgentImageSource(agentConfig))
+}
+
+func (s *Suite) TestTrustedAgentImage() {
+	agentConfig, _ := testAgentData()
+	s.service.config.Config.Registry.AllowedRegistries = []string{"some.docker.registry.io"}
+	s.service.config.Config.Registry.RequireDigest = true
+
+	s.r.NoError(s.service.checkAgentImageSource(agentConfig))
+	s.r.Equal("none", s.service.untrustedImagesReport().Details)
+}
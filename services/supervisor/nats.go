@@ -0,0 +1,48 @@
+package supervisor
+
+import (
+	"fmt"
+
+	"github.com/forta-network/forta-node/clients"
+	"github.com/forta-network/forta-node/config"
+)
+
+func (sup *SupervisorService) natsContainerConfig(natsNetworkID string) clients.DockerContainerConfig {
+	// the default command of the image
+	cmd := []string{"--config", "nats-server.conf"}
+	// the services connect with the same credentials
+	if natsCfg := sup.config.Config.Nats; len(natsCfg.User) > 0 {
+		cmd = append(cmd, "--user", natsCfg.User, "--pass", natsCfg.Password)
+	}
+	return clients.DockerContainerConfig{
+		Name:  config.DockerNatsContainerName,
+		Image: "nats:2.3.2",
+		Cmd:   cmd,
+		Ports: map[string]string{
+			"4222": "4222",
+			"6222": "6222",
+			"8222": "8222",
+		},
+		NetworkID:   natsNetworkID,
+		MaxLogFiles: sup.maxLogFiles,
+		LogDriver:   sup.logDriver,
+		LogOpts:     sup.logOpts,
+		MaxLogSize:  sup.maxLogSize,
+		Labels:      sup.config.Config.Docker.AddLabels(nil),
+	}
+}
+
+// startNats starts the NATS container and waits for it. It is not started if the node uses
+// an external NATS server.
+func (sup *SupervisorService) startNats(natsNetworkID string) error {
+	natsContainer, err := sup.client.StartContainer(sup.ctx, sup.natsContainerConfig(natsNetworkID))
+	if err != nil {
+		return err
+	}
+	sup.addContainerUnsafe(natsContainer)
+
+	if err := sup.waitContainerStart(natsContainer.ID); err != nil {
+		return fmt.Errorf("failed while waiting for nats to start: %v", err)
+	}
+	return nil
+}
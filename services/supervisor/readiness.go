@@ -0,0 +1,45 @@
+package supervisor
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/forta-network/forta-core-go/clients/health"
+	"github.com/forta-network/forta-node/clients/messaging"
+	"github.com/forta-network/forta-node/healthutils"
+)
+
+func (sup *SupervisorService) handleScannerBlock(payload messaging.ScannerPayload) error {
+	sup.lastScannerBlock.Set()
+	return nil
+}
+
+// ReadinessChecks returns the checks which tell if the node is ready to scan.
+func (sup *SupervisorService) ReadinessChecks() []healthutils.ReadinessCheck {
+	return []healthutils.ReadinessCheck{
+		{Name: "containers", Check: sup.checkContainersReady},
+		{Name: "block-lag", Check: sup.checkBlockLag},
+	}
+}
+
+func (sup *SupervisorService) checkContainersReady() error {
+	sup.mu.RLock()
+	defer sup.mu.RUnlock()
+	if managed := sup.config.Config.SupervisorManagedContainers(); len(sup.containers) < managed {
+		return fmt.Errorf("%d of %d containers are running", len(sup.containers), managed)
+	}
+	return nil
+}
+
+func (sup *SupervisorService) checkBlockLag() error {
+	maxLag := time.Duration(sup.config.Config.Readiness.MaxBlockLagSeconds) * time.Second
+	ts, status := sup.lastScannerBlock.Check(maxLag)
+	switch status {
+	case health.StatusUnknown:
+		return errors.New("no blocks scanned yet")
+	case health.StatusLagging:
+		return fmt.Errorf("no blocks scanned in %s since %s", maxLag, ts)
+	}
+	return nil
+}
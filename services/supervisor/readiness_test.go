@@ -0,0 +1,37 @@
+package supervisor
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/forta-network/forta-node/clients/messaging"
+	"github.com/forta-network/forta-node/config"
+	"github.com/forta-network/forta-node/healthutils"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReadiness(t *testing.T) {
+	r := require.New(t)
+
+	sup := &SupervisorService{}
+	sup.config.Config.Readiness.MaxBlockLagSeconds = 60
+	checks := sup.ReadinessChecks()
+
+	resp := healthutils.CheckReadiness(checks...)
+	r.False(resp.Ready)
+	r.Equal(fmt.Sprintf("0 of %d containers are running", config.DockerSupervisorManagedContainers), resp.Reasons["containers"])
+	r.Equal("no blocks scanned yet", resp.Reasons["block-lag"])
+
+	for i := 0; i < config.DockerSupervisorManagedContainers; i++ {
+		sup.containers = append(sup.containers, &Container{})
+	}
+	resp = healthutils.CheckReadiness(checks...)
+	r.False(resp.Ready)
+	r.Len(resp.Reasons, 1)
+	r.Contains(resp.Reasons, "block-lag")
+
+	r.NoError(sup.handleScannerBlock(messaging.ScannerPayload{LatestBlockInput: 1}))
+	resp = healthutils.CheckReadiness(checks...)
+	r.True(resp.Ready)
+	r.Empty(resp.Reasons)
+}
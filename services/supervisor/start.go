@@ -8,6 +8,7 @@ import (
 	"strconv"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/forta-network/forta-core-go/manifest"
@@ -22,9 +23,12 @@ import (
 	"github.com/forta-network/forta-core-go/clients/health"
 	"github.com/forta-network/forta-core-go/security"
 	"github.com/forta-network/forta-node/clients"
+	"github.com/forta-network/forta-node/clients/ipfsgateway"
 	"github.com/forta-network/forta-node/clients/messaging"
 	"github.com/forta-network/forta-node/config"
+	"github.com/forta-network/forta-node/healthutils"
 	"github.com/forta-network/forta-node/services"
+	"github.com/forta-network/forta-node/store"
 )
 
 const (
@@ -48,13 +52,17 @@ type SupervisorService struct {
 	config      SupervisorServiceConfig
 	maxLogSize  string
 	maxLogFiles int
+	logDriver   string
+	logOpts     map[string]string
 
 	scannerContainer     *clients.DockerContainer
 	inspectorContainer   *clients.DockerContainer
 	jsonRpcContainer     *clients.DockerContainer
 	jwtProviderContainer *clients.DockerContainer
 	storageContainer     *clients.DockerContainer
+	egressProxyContainer *clients.DockerContainer
 	containers           []*Container
+	agentSlots           agentSlots
 	mu                   sync.RWMutex
 
 	lastRun                         health.TimeTracker
@@ -65,6 +73,20 @@ type SupervisorService struct {
 	lastCustomTelemetryRequestError health.ErrorTracker
 	lastAgentLogsRequest            health.TimeTracker
 	lastAgentLogsRequestError       health.ErrorTracker
+	lastScannerBlock                health.TimeTracker
+
+	drainState healthutils.DrainState
+	drainMu    sync.RWMutex
+
+	agentRestarts map[string]*agentRestartState
+	restartMu     sync.Mutex
+
+	disabledAgents       store.DisabledAgentsStore
+	disabledAgentConfigs map[string]config.AgentConfig
+	disableMu            sync.Mutex
+
+	untrustedImages atomic.Int64
+	untrustedImage  health.MessageTracker
 
 	healthClient health.HealthClient
 
@@ -99,12 +121,13 @@ func (sup *SupervisorService) Start() error {
 func (sup *SupervisorService) start() error {
 	// in addition to the feature disable flags, check local mode flags to disable agent logging and telemetry
 
-	shouldDisableTelemetry := sup.config.Config.TelemetryConfig.Disable
+	shouldDisableTelemetry := sup.config.Config.TelemetryConfig.Disable || sup.config.Config.OfflineSkip("telemetry")
 	if !shouldDisableTelemetry {
 		go sup.syncTelemetryData()
 	}
 
-	shouldDisableAgentLogs := sup.config.Config.AgentLogsConfig.Disable || sup.config.Config.LocalModeConfig.Enable
+	shouldDisableAgentLogs := sup.config.Config.AgentLogsConfig.Disable || sup.config.Config.LocalModeConfig.Enable ||
+		sup.config.Config.OfflineSkip("agent-logs")
 	if !shouldDisableAgentLogs {
 		go sup.syncAgentLogs()
 	}
@@ -122,6 +145,11 @@ func (sup *SupervisorService) start() error {
 	if len(hostFortaDir) == 0 {
 		return fmt.Errorf("supervisor needs to know $%s to mount to the other containers it runs", config.EnvHostFortaDir)
 	}
+	// older runners do not set the socket path
+	hostDockerSocket := os.Getenv(config.EnvHostDockerSocket)
+	if len(hostDockerSocket) == 0 {
+		hostDockerSocket = config.DefaultDockerSocketPath
+	}
 	releaseInfo := release.ReleaseInfoFromString(os.Getenv(config.EnvReleaseInfo))
 	releaseInfo, err = sup.getFullReleaseInfo(releaseInfo)
 	if err != nil {
@@ -133,6 +161,8 @@ func (sup *SupervisorService) start() error {
 
 	sup.maxLogSize = sup.config.Config.Log.MaxLogSize
 	sup.maxLogFiles = sup.config.Config.Log.MaxLogFiles
+	sup.logDriver = sup.config.Config.Log.LogDriver
+	sup.logOpts = sup.config.Config.Log.LogOpts
 
 	if err := sup.removeOldContainers(); err != nil {
 		return err
@@ -189,7 +219,10 @@ ipfs config Datastore.StorageMax '1GB'
 		},
 		NetworkID:   nodeNetworkID,
 		MaxLogFiles: sup.maxLogFiles,
+		LogDriver:   sup.logDriver,
+		LogOpts:     sup.logOpts,
 		MaxLogSize:  sup.maxLogSize,
+		Labels:      sup.config.Config.Docker.AddLabels(nil),
 		Cmd: []string{
 			// default CMD - taken from https://hub.docker.com/layers/ipfs/kubo/master-latest/images/sha256-65b4c19a75987bd9bb677e8d9b1b1dafb81eec2335ba65f73dfb8256f6b3d22a?context=explore
 			"daemon", "--migrate=true", "--agent-version-suffix=docker",
@@ -204,29 +237,14 @@ ipfs config Datastore.StorageMax '1GB'
 	sup.addContainerUnsafe(ipfsContainer)
 
 	// start nats, wait for it and connect from the supervisor
-	natsContainer, err := sup.client.StartContainer(sup.ctx, clients.DockerContainerConfig{
-		Name:  config.DockerNatsContainerName,
-		Image: "nats:2.3.2",
-		Ports: map[string]string{
-			"4222": "4222",
-			"6222": "6222",
-			"8222": "8222",
-		},
-		NetworkID:   natsNetworkID,
-		MaxLogFiles: sup.maxLogFiles,
-		MaxLogSize:  sup.maxLogSize,
-	})
-	if err != nil {
-		return err
-	}
-	sup.addContainerUnsafe(natsContainer)
-
-	if err := sup.client.WaitContainerStart(sup.ctx, natsContainer.ID); err != nil {
-		return fmt.Errorf("failed while waiting for nats to start: %v", err)
+	if !sup.config.Config.Nats.External {
+		if err := sup.startNats(natsNetworkID); err != nil {
+			return err
+		}
 	}
 	// in tests, this is already set to a mock client
 	if sup.msgClient == nil {
-		sup.msgClient = messaging.NewClient("supervisor", fmt.Sprintf("%s:%s", config.DockerNatsContainerName, config.DefaultNatsPort))
+		sup.msgClient = messaging.NewClient("supervisor", sup.config.Config.Nats)
 	}
 	sup.registerMessageHandlers()
 
@@ -235,13 +253,14 @@ ipfs config Datastore.StorageMax '1GB'
 			Name:  config.DockerStorageContainerName,
 			Image: commonNodeImage,
 			Cmd:   []string{config.DefaultFortaNodeBinaryPath, "storage"},
-			Env: map[string]string{
+			Env: sup.config.Config.AddRemoteConfigEnv(map[string]string{
 				config.EnvReleaseInfo: releaseInfo.String(),
-			},
+				config.EnvLogFormat:   sup.config.Config.Log.Format,
+			}),
 			Volumes: map[string]string{
 				// give access to host docker
-				"/var/run/docker.sock": "/var/run/docker.sock",
-				hostFortaDir:           config.DefaultContainerFortaDirPath,
+				hostDockerSocket: config.DefaultDockerSocketPath,
+				hostFortaDir:     config.DefaultContainerFortaDirPath,
 			},
 			Ports: map[string]string{
 				"": config.DefaultHealthPort, // random host port
@@ -252,7 +271,10 @@ ipfs config Datastore.StorageMax '1GB'
 			DialHost:    true,
 			NetworkID:   nodeNetworkID,
 			MaxLogFiles: sup.maxLogFiles,
+			LogDriver:   sup.logDriver,
+			LogOpts:     sup.logOpts,
 			MaxLogSize:  sup.maxLogSize,
+			Labels:      sup.config.Config.Docker.AddLabels(nil),
 		},
 	)
 	if err != nil {
@@ -260,7 +282,7 @@ ipfs config Datastore.StorageMax '1GB'
 	}
 	sup.addContainerUnsafe(sup.storageContainer)
 
-	if err := sup.client.WaitContainerStart(sup.ctx, sup.storageContainer.ID); err != nil {
+	if err := sup.waitContainerStart(sup.storageContainer.ID); err != nil {
 		return fmt.Errorf("failed while waiting for the storage container to start: %v", err)
 	}
 
@@ -269,10 +291,13 @@ ipfs config Datastore.StorageMax '1GB'
 			Name:  config.DockerJSONRPCProxyContainerName,
 			Image: commonNodeImage,
 			Cmd:   []string{config.DefaultFortaNodeBinaryPath, "json-rpc"},
+			Env: sup.config.Config.AddRemoteConfigEnv(sup.config.Config.Nats.AddEnv(map[string]string{
+				config.EnvLogFormat: sup.config.Config.Log.Format,
+			})),
 			Volumes: map[string]string{
 				// give access to host docker
-				"/var/run/docker.sock": "/var/run/docker.sock",
-				hostFortaDir:           config.DefaultContainerFortaDirPath,
+				hostDockerSocket: config.DefaultDockerSocketPath,
+				hostFortaDir:     config.DefaultContainerFortaDirPath,
 			},
 			Ports: map[string]string{
 				"": config.DefaultHealthPort, // random host port
@@ -281,7 +306,10 @@ ipfs config Datastore.StorageMax '1GB'
 			NetworkID:      nodeNetworkID,
 			LinkNetworkIDs: []string{natsNetworkID},
 			MaxLogFiles:    sup.maxLogFiles,
+			LogDriver:      sup.logDriver,
+			LogOpts:        sup.logOpts,
 			MaxLogSize:     sup.maxLogSize,
+			Labels:         sup.config.Config.Docker.AddLabels(nil),
 		},
 	)
 	if err != nil {
@@ -295,7 +323,7 @@ ipfs config Datastore.StorageMax '1GB'
 	}
 
 	if shouldInspectAtStartup {
-		if err := sup.client.WaitContainerStart(sup.ctx, sup.jsonRpcContainer.ID); err != nil {
+		if err := sup.waitContainerStart(sup.jsonRpcContainer.ID); err != nil {
 			return fmt.Errorf("failed while waiting for json-rpc container to start: %v", err)
 		}
 	}
@@ -305,6 +333,9 @@ ipfs config Datastore.StorageMax '1GB'
 			Name:  config.DockerInspectorContainerName,
 			Image: commonNodeImage,
 			Cmd:   []string{config.DefaultFortaNodeBinaryPath, "inspector"},
+			Env: sup.config.Config.AddRemoteConfigEnv(sup.config.Config.Nats.AddEnv(map[string]string{
+				config.EnvLogFormat: sup.config.Config.Log.Format,
+			})),
 			Volumes: map[string]string{
 				hostFortaDir: config.DefaultContainerFortaDirPath,
 			},
@@ -315,7 +346,10 @@ ipfs config Datastore.StorageMax '1GB'
 			NetworkID:      nodeNetworkID,
 			LinkNetworkIDs: []string{natsNetworkID},
 			MaxLogFiles:    sup.maxLogFiles,
+			LogDriver:      sup.logDriver,
+			LogOpts:        sup.logOpts,
 			MaxLogSize:     sup.maxLogSize,
+			Labels:         sup.config.Config.Docker.AddLabels(nil),
 		},
 	)
 	if err != nil {
@@ -324,7 +358,7 @@ ipfs config Datastore.StorageMax '1GB'
 	sup.addContainerUnsafe(sup.inspectorContainer)
 
 	if shouldInspectAtStartup {
-		if err := sup.client.WaitContainerStart(sup.ctx, sup.inspectorContainer.ID); err != nil {
+		if err := sup.waitContainerStart(sup.inspectorContainer.ID); err != nil {
 			return fmt.Errorf("failed while waiting for inspector to start: %v", err)
 		}
 
@@ -338,9 +372,13 @@ ipfs config Datastore.StorageMax '1GB'
 			Name:  config.DockerScannerContainerName,
 			Image: commonNodeImage,
 			Cmd:   []string{config.DefaultFortaNodeBinaryPath, "scanner"},
-			Env: map[string]string{
-				config.EnvReleaseInfo: releaseInfo.String(),
-			},
+			Env: sup.config.Config.AddRemoteConfigEnv(sup.config.Config.Signer.AddEnv(sup.config.Config.Nats.AddEnv(sup.config.Config.Network.Proxy.AddEnv(map[string]string{
+				config.EnvReleaseInfo:       releaseInfo.String(),
+				config.EnvBlockPollInterval: sup.config.Config.Scan.PollInterval().String(),
+				config.EnvTraceEnabled:      strconv.FormatBool(sup.config.Config.Trace.Enabled),
+				config.EnvLogFormat:         sup.config.Config.Log.Format,
+				config.EnvDevelopment:       strconv.FormatBool(sup.config.Config.Development),
+			})))),
 			Volumes: map[string]string{
 				hostFortaDir: config.DefaultContainerFortaDirPath,
 			},
@@ -354,7 +392,10 @@ ipfs config Datastore.StorageMax '1GB'
 			NetworkID:      nodeNetworkID,
 			LinkNetworkIDs: []string{natsNetworkID},
 			MaxLogFiles:    sup.maxLogFiles,
+			LogDriver:      sup.logDriver,
+			LogOpts:        sup.logOpts,
 			MaxLogSize:     sup.maxLogSize,
+			Labels:         sup.config.Config.Docker.AddLabels(nil),
 		},
 	)
 	if err != nil {
@@ -367,13 +408,14 @@ ipfs config Datastore.StorageMax '1GB'
 			Name:  config.DockerJWTProviderContainerName,
 			Image: commonNodeImage,
 			Cmd:   []string{config.DefaultFortaNodeBinaryPath, "jwt-provider"},
-			Env: map[string]string{
+			Env: sup.config.Config.AddRemoteConfigEnv(map[string]string{
 				config.EnvReleaseInfo: releaseInfo.String(),
-			},
+				config.EnvLogFormat:   sup.config.Config.Log.Format,
+			}),
 			Volumes: map[string]string{
 				// give access to host docker
-				"/var/run/docker.sock": "/var/run/docker.sock",
-				hostFortaDir:           config.DefaultContainerFortaDirPath,
+				hostDockerSocket: config.DefaultDockerSocketPath,
+				hostFortaDir:     config.DefaultContainerFortaDirPath,
 			},
 			Ports: map[string]string{
 				"": config.DefaultHealthPort, // random host port
@@ -385,7 +427,10 @@ ipfs config Datastore.StorageMax '1GB'
 			NetworkID:      nodeNetworkID,
 			LinkNetworkIDs: []string{natsNetworkID},
 			MaxLogFiles:    sup.maxLogFiles,
+			LogDriver:      sup.logDriver,
+			LogOpts:        sup.logOpts,
 			MaxLogSize:     sup.maxLogSize,
+			Labels:         sup.config.Config.Docker.AddLabels(nil),
 		},
 	)
 	if err != nil {
@@ -393,6 +438,10 @@ ipfs config Datastore.StorageMax '1GB'
 	}
 	sup.addContainerUnsafe(sup.jwtProviderContainer)
 
+	if err := sup.startEgressProxy(commonNodeImage, hostFortaDir, nodeNetworkID); err != nil {
+		return err
+	}
+
 	return nil
 }
 
@@ -403,6 +452,17 @@ func prepareIpfsDir() error {
 	return nil
 }
 
+// waitContainerStart waits for the container to start within the configured timeout.
+func (sup *SupervisorService) waitContainerStart(id string) error {
+	ctx := sup.ctx
+	if timeout := sup.config.Config.ResourcesConfig.ContainerStartTimeout(); timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+	return sup.client.WaitContainerStart(ctx, id)
+}
+
 func (sup *SupervisorService) attachToNetwork(containerName, nodeNetworkID string) error {
 	container, err := sup.client.GetContainerByName(sup.ctx, containerName)
 	if err != nil {
@@ -452,6 +512,7 @@ func (sup *SupervisorService) removeOldContainers() error {
 		config.DockerNatsContainerName,
 		config.DockerIpfsContainerName,
 		config.DockerStorageContainerName,
+		config.DockerEgressProxyContainerName,
 	} {
 		container, err := sup.client.GetContainerByName(sup.ctx, containerName)
 		if err != nil {
@@ -475,10 +536,11 @@ func (sup *SupervisorService) removeOldContainers() error {
 			"containerName": containerName,
 			"containerId":   container.ID,
 		})
-		if !strings.Contains(containerName, "forta-agent-") {
+		if !strings.HasPrefix(containerName, config.DockerAgentContainerNamePrefix) {
 			continue
 		}
-		if container.Labels[clients.DockerLabelFortaSupervisorStrategyVersion] != SupervisorStrategyVersion {
+		oldStrategy := container.Labels[clients.DockerLabelFortaSupervisorStrategyVersion] != SupervisorStrategyVersion
+		if oldStrategy || sup.isAgentNetworkPolicyChanged(container.Labels) {
 			logger.Info("agent container is old - need to remove")
 			containersToRemove = append(containersToRemove, &containerDefinition{
 				ID:   container.ID,
@@ -567,7 +629,17 @@ func (sup *SupervisorService) doSyncTelemetryData(destUrl string) error {
 	if err != nil {
 		return err
 	}
-	dataSrc := fmt.Sprintf("http://host.docker.internal:%s/health", config.DefaultHealthPort)
+	// the runner health endpoint may require auth and tls
+	authCfg := sup.config.Config.TelemetryConfig.Auth
+	// the runner may have assigned another port at start-up
+	healthPort := os.Getenv(config.EnvRunnerHealthPort)
+	if len(healthPort) == 0 {
+		healthPort = sup.config.Config.Health.Port()
+	}
+	dataSrc := healthutils.NodeHealthURL(authCfg, "host.docker.internal", healthPort)
+	if authCfg.Enabled() || authCfg.TLSEnabled() {
+		return healthutils.SendReports(authCfg, dataSrc, destUrl, scannerJwt)
+	}
 	return sup.healthClient.SendReports(
 		dataSrc,
 		destUrl,
@@ -586,6 +658,9 @@ func (sup *SupervisorService) getFullReleaseInfo(releaseInfo *release.ReleaseInf
 	if _, err := cid.Parse(releaseInfo.IPFS); err != nil {
 		return releaseInfo, nil
 	}
+	if sup.config.Config.OfflineSkip("release-manifest") {
+		return releaseInfo, nil
+	}
 	fullReleaseManifest, err := sup.releaseClient.GetReleaseManifest(sup.ctx, releaseInfo.IPFS)
 	if err != nil {
 		return nil, err
@@ -602,26 +677,37 @@ func (sup *SupervisorService) Stop() error {
 	defer sup.mu.RUnlock()
 
 	ctx := context.Background()
+	stopTimeout := sup.config.Config.ResourcesConfig.ContainerStopTimeout()
+	// stop concurrently so that the graceful stops do not add up
+	var wg sync.WaitGroup
 	for _, cnt := range sup.containers {
 		if services.IsGracefulShutdown() && cnt.IsAgent {
 			continue // keep container agents alive
 		}
-		var err error
-		if cnt.IsAgent {
-			err = sup.client.StopContainer(ctx, cnt.DockerContainer.ID)
-		} else {
-			err = sup.client.InterruptContainer(ctx, cnt.DockerContainer.ID)
-		}
-		logger := log.WithFields(log.Fields{
-			"id":      cnt.ID,
-			"isAgent": cnt.IsAgent,
-		})
-		if err != nil {
-			logger.WithError(err).Error("error stopping container")
-		} else {
-			logger.Info("requested to stop container")
-		}
+		wg.Add(1)
+		go func(cnt *Container) {
+			defer wg.Done()
+			var err error
+			switch {
+			case cnt.IsAgent:
+				err = sup.client.StopContainer(ctx, cnt.DockerContainer.ID, stopTimeout)
+			case stopTimeout > 0:
+				err = sup.client.TerminateContainer(ctx, cnt.DockerContainer.ID, stopTimeout)
+			default:
+				err = sup.client.InterruptContainer(ctx, cnt.DockerContainer.ID)
+			}
+			logger := log.WithFields(log.Fields{
+				"id":      cnt.ID,
+				"isAgent": cnt.IsAgent,
+			})
+			if err != nil {
+				logger.WithError(err).Error("error stopping container")
+			} else {
+				logger.Info("requested to stop container")
+			}
+		}(cnt)
 	}
+	wg.Wait()
 	return nil
 }
 
@@ -635,11 +721,11 @@ func (sup *SupervisorService) Health() health.Reports {
 	defer sup.mu.RUnlock()
 
 	containersStatus := health.StatusOK
-	if len(sup.containers) < config.DockerSupervisorManagedContainers {
+	if len(sup.containers) < sup.config.Config.SupervisorManagedContainers() {
 		containersStatus = health.StatusFailing
 	}
 
-	return health.Reports{
+	reports := health.Reports{
 		&health.Report{
 			Name:    "local-mode",
 			Status:  health.StatusInfo,
@@ -666,7 +752,21 @@ func (sup *SupervisorService) Health() health.Reports {
 		sup.lastCustomTelemetryRequestError.GetReport("event.custom-telemetry-sync.error"),
 		sup.lastAgentLogsRequest.GetReport("event.agent-logs-sync.time"),
 		sup.lastAgentLogsRequestError.GetReport("event.agent-logs-sync.error"),
+		sup.drainReport(),
+		sup.quarantineReport(),
+		sup.failedAgentsReport(),
+		sup.disabledAgentsReport(),
+		sup.networkPolicyReport(),
+		sup.waitingAgentsReportUnsafe(),
+		sup.untrustedImagesReport(),
+	}
+	if reporter, ok := sup.msgClient.(health.Reporter); ok {
+		reports = append(reports, reporter.Health()...)
+	}
+	if reporter, ok := sup.releaseClient.(health.Reporter); ok {
+		reports = append(reports, reporter.Health()...)
 	}
+	return reports
 }
 
 // handleInspectionResults listen for inspections.
@@ -690,7 +790,9 @@ func NewSupervisorService(ctx context.Context, cfg SupervisorServiceConfig) (*Su
 		return nil, fmt.Errorf("failed to create the global docker client: %v", err)
 	}
 
-	releaseClient, err := release.NewClient(cfg.Config.Registry.IPFS.GatewayURL)
+	releaseClient, err := ipfsgateway.NewReleaseClient(
+		cfg.Config.Registry.IPFS, path.Join(config.DefaultContainerFortaDirPath, config.DefaultSupervisorReleaseCacheFileName),
+	)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create the release client: %v", err)
 	}
@@ -720,5 +822,9 @@ func NewSupervisorService(ctx context.Context, cfg SupervisorServiceConfig) (*Su
 		healthClient:     health.NewClient(),
 		agentLogsClient:  agentlogs.NewClient(cfg.Config.AgentLogsConfig.URL),
 		inspectionCh:     make(chan *protocol.InspectionResults),
+		agentRestarts:    make(map[string]*agentRestartState),
+
+		disabledAgents:       store.NewDisabledAgentsStore(config.DefaultContainerFortaDirPath),
+		disabledAgentConfigs: make(map[string]config.AgentConfig),
 	}, nil
 }
@@ -23,6 +23,13 @@ const (
 )
 
 func (sup *SupervisorService) startAgent(ctx context.Context, agent config.AgentConfig) error {
+	// the one-off runs are requested explicitly so they can run the disabled agents
+	if !agent.OneOff() && sup.isAgentDisabled(agent) {
+		return errAgentDisabled
+	}
+	if err := sup.checkAgentImageSource(agent); err != nil {
+		return err
+	}
 	if err := sup.agentImageClient.EnsureLocalImage(ctx, fmt.Sprintf("agent %s", agent.ID), agent.Image); err != nil {
 		return err
 	}
@@ -35,12 +42,32 @@ func (sup *SupervisorService) startAgent(ctx context.Context, agent config.Agent
 		return errAgentAlreadyRunning
 	}
 
-	nwID, err := sup.client.CreatePublicNetwork(ctx, agent.ContainerName())
+	nwID, err := sup.createAgentNetwork(ctx, agent)
 	if err != nil {
 		return err
 	}
 
-	limits := config.GetAgentResourceLimits(sup.config.Config.ResourcesConfig)
+	sup.config.Config.SetAgentLimits(&agent)
+	sup.config.Config.SetAgentEnv(&agent)
+	limits := sup.config.Config.AgentResourceLimits(agent)
+
+	// the node env vars cannot be overridden
+	env := config.ExpandAgentEnv(agent.Env)
+	if sup.config.Config.Agent.EgressProxyEnabled() {
+		for key, value := range config.EgressProxyEnv() {
+			env[key] = value
+		}
+	}
+	for key, value := range map[string]string{
+		config.EnvJsonRpcHost:     config.DockerJSONRPCProxyContainerName,
+		config.EnvJsonRpcPort:     config.DefaultJSONRPCProxyPort,
+		config.EnvJWTProviderHost: config.DockerJWTProviderContainerName,
+		config.EnvJWTProviderPort: config.DefaultJWTProviderPort,
+		config.EnvAgentGrpcPort:   agent.GrpcPort(),
+		config.EnvFortaBotID:      agent.ID,
+	} {
+		env[key] = value
+	}
 
 	agentContainer, err := sup.client.StartContainer(
 		ctx, clients.DockerContainerConfig{
@@ -48,31 +75,25 @@ func (sup *SupervisorService) startAgent(ctx context.Context, agent config.Agent
 			Image:          agent.Image,
 			NetworkID:      nwID,
 			LinkNetworkIDs: []string{},
-			Env: map[string]string{
-				config.EnvJsonRpcHost:     config.DockerJSONRPCProxyContainerName,
-				config.EnvJsonRpcPort:     config.DefaultJSONRPCProxyPort,
-				config.EnvJWTProviderHost: config.DockerJWTProviderContainerName,
-				config.EnvJWTProviderPort: config.DefaultJWTProviderPort,
-				config.EnvAgentGrpcPort:   agent.GrpcPort(),
-				config.EnvFortaBotID:      agent.ID,
-			},
-			MaxLogFiles: sup.maxLogFiles,
-			MaxLogSize:  sup.maxLogSize,
-			CPUQuota:    limits.CPUQuota,
-			Memory:      limits.Memory,
-			Labels: map[string]string{
+			Env:            env,
+			MaxLogFiles:    sup.maxLogFiles,
+			LogDriver:      sup.logDriver,
+			LogOpts:        sup.logOpts,
+			MaxLogSize:     sup.maxLogSize,
+			CPUQuota:       limits.CPUQuota,
+			Memory:         limits.Memory,
+			Labels: sup.config.Config.Docker.AddLabels(map[string]string{
 				clients.DockerLabelFortaSupervisorStrategyVersion: SupervisorStrategyVersion,
-			},
+				clients.DockerLabelFortaAgentNetworkPolicy:        sup.agentNetworkPolicy(),
+				clients.DockerLabelFortaAgentID:                   agent.ID,
+			}),
 		},
 	)
 	if err != nil {
 		return err
 	}
-	// Attach the scanner, JWT Provider and the JSON-RPC proxy to the agent's network.
-	for _, containerID := range []string{
-		sup.scannerContainer.ID, sup.jsonRpcContainer.ID,
-		sup.jwtProviderContainer.ID,
-	} {
+	// Attach the scanner, JWT Provider, the JSON-RPC proxy and the egress proxy to the agent's network.
+	for _, containerID := range sup.agentNetworkContainerIDs() {
 		err := sup.client.AttachNetwork(ctx, containerID, nwID)
 		if err != nil {
 			return err
@@ -123,17 +144,25 @@ func (sup *SupervisorService) handleAgentRunWithContext(ctx context.Context, pay
 		},
 	).Infof("handle agent run")
 
+	sup.mu.Lock()
+	admitted := sup.admitAgentsUnsafe(payload)
+	sup.mu.Unlock()
+
+	sup.startAgents(ctx, admitted)
+	return nil
+}
+
+// startAgents starts the agents concurrently and waits for all of them.
+func (sup *SupervisorService) startAgents(ctx context.Context, agents []config.AgentConfig) {
 	var wg sync.WaitGroup
 
-	wg.Add(len(payload))
+	wg.Add(len(agents))
 
-	for _, agent := range payload {
+	for _, agent := range agents {
 		go sup.doStartAgent(ctx, agent, &wg)
 	}
 
 	wg.Wait()
-
-	return nil
 }
 
 // doStartAgent intended to use during multiple agent starts
@@ -143,13 +172,22 @@ func (sup *SupervisorService) doStartAgent(ctx context.Context, agent config.Age
 	logger := agentLogger(agent)
 
 	err := sup.startAgent(ctx, agent)
+	sup.mu.Lock()
+	sup.agentSlots.setStarting(agent.ContainerName(), false)
+	sup.mu.Unlock()
 	if err == errAgentAlreadyRunning {
 		logger.Infof("agent container is already running - skipped")
 		sup.msgClient.Publish(messaging.SubjectAgentsStatusRunning, messaging.AgentPayload{agent})
 		return
 	}
+	if err == errAgentDisabled {
+		logger.Info("agent is disabled - skipped")
+		go sup.startWaitingAgents() // let the waiting agents use the slot
+		return
+	}
 	if err != nil {
 		logger.WithError(err).Error("failed to start agent")
+		go sup.startWaitingAgents()
 		return
 	}
 
@@ -168,15 +206,24 @@ func (sup *SupervisorService) handleAgentStop(payload messaging.AgentPayload) er
 		logger := agentLogger(agentCfg)
 
 		container, ok := sup.getContainerUnsafe(agentCfg.ContainerName())
+		if !ok && sup.agentSlots.remove(agentCfg.ContainerName()) {
+			logger.Info("removed the agent from the waiting list")
+			continue
+		}
 		if !ok {
 			logger.Warnf("container for agent was not found - skipping stop action")
 			continue
 		}
-		if err := sup.client.StopContainer(sup.ctx, container.ID); err != nil {
+		if err := sup.client.StopContainer(
+			sup.ctx, container.ID, sup.config.Config.ResourcesConfig.ContainerStopTimeout(),
+		); err != nil {
 			return fmt.Errorf("failed to stop container '%s': %v", container.ID, err)
 		}
 		logger.Infof("successfully stopped the container")
 		stopped[container.ID] = true
+		if !agentCfg.OneOff() {
+			sup.clearAgentRestarts(agentCfg.ID)
+		}
 	}
 
 	// Remove the stopped agents from the list.
@@ -187,6 +234,9 @@ func (sup *SupervisorService) handleAgentStop(payload messaging.AgentPayload) er
 		}
 	}
 	sup.containers = remainingContainers
+	if len(stopped) > 0 {
+		go sup.startWaitingAgents()
+	}
 
 	// Broadcast the agent statuses.
 	if len(payload) > 0 {
@@ -198,6 +248,9 @@ func (sup *SupervisorService) handleAgentStop(payload messaging.AgentPayload) er
 func (sup *SupervisorService) registerMessageHandlers() {
 	sup.msgClient.Subscribe(messaging.SubjectAgentsActionRun, messaging.AgentsHandler(sup.handleAgentRun))
 	sup.msgClient.Subscribe(messaging.SubjectAgentsActionStop, messaging.AgentsHandler(sup.handleAgentStop))
+	sup.msgClient.Subscribe(messaging.SubjectAgentsActionRestart, messaging.AgentsHandler(sup.handleAgentRestart))
+	sup.msgClient.Subscribe(messaging.SubjectScannerBlock, messaging.ScannerHandler(sup.handleScannerBlock))
+	sup.msgClient.Subscribe(messaging.SubjectNodeDrainState, messaging.DrainHandler(sup.handleDrainState))
 	if sup.config.Config.InspectionConfig.InspectAtStartup {
 		sup.msgClient.Subscribe(messaging.SubjectInspectionDone, messaging.InspectionResultsHandler(sup.handleInspectionResults))
 	}
@@ -206,7 +259,8 @@ func (sup *SupervisorService) registerMessageHandlers() {
 func agentLogger(agent config.AgentConfig) *log.Entry {
 	return log.WithFields(
 		log.Fields{
-			"agentId": agent.ID, "image": agent.Image, "containerName": agent.ContainerName(),
+			"agentId": agent.ID, "image": agent.Image, "containerName": agent.ContainerName(), "isLocal": agent.IsLocal,
+			"runId": agent.RunID,
 		},
 	)
 }
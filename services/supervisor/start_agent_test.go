@@ -0,0 +1,164 @@
+package supervisor
+
+import (
+	"context"
+
+	"github.com/forta-network/forta-node/clients"
+	"github.com/forta-network/forta-node/clients/messaging"
+	"github.com/forta-network/forta-node/config"
+)
+
+// limitsMatcher matches the agent container config with the resource limits.
+type limitsMatcher clients.DockerContainerConfig
+
+// Matches implements the gomock.Matcher interface.
+func (m limitsMatcher) Matches(x interface{}) bool {
+	c, ok := x.(clients.DockerContainerConfig)
+	if !ok {
+		return false
+	}
+	return c.Name == m.Name && c.CPUQuota == m.CPUQuota && c.Memory == m.Memory
+}
+
+// String implements the gomock.Matcher interface.
+func (m limitsMatcher) String() string {
+	return (configMatcher)(m).String()
+}
+
+// TestAgentRunWithLimits tests running the agent with the individual resource limits.
+func (s *Suite) TestAgentRunWithLimits() {
+	agentConfig, agentPayload := testAgentData()
+	s.service.config.Config.Agent.Defaults = config.AgentLimitsConfig{CPULimit: 0.5, MemoryLimit: 200}
+	s.service.config.Config.Agent.Limits = []config.AgentLimitsConfig{{AgentID: testAgentID, MemoryLimit: 500}}
+
+	ctx, cancel := context.WithTimeout(s.service.ctx, agentStartTimeout)
+	defer cancel()
+	s.agentImageClient.EXPECT().EnsureLocalImage(ctx, "agent test-agent", agentConfig.Image).Return(nil)
+	s.dockerClient.EXPECT().CreatePublicNetwork(ctx, testAgentContainerName).Return(testAgentNetworkID, nil)
+	s.dockerClient.EXPECT().StartContainer(
+		ctx, (limitsMatcher)(
+			clients.DockerContainerConfig{
+				Name:     agentConfig.ContainerName(),
+				CPUQuota: 50000,
+				Memory:   500 * 1024 * 1024,
+			},
+		),
+	).Return(&clients.DockerContainer{Name: agentConfig.ContainerName(), ID: testAgentContainerID}, nil)
+	s.dockerClient.EXPECT().AttachNetwork(ctx, testScannerContainerID, testAgentNetworkID)
+	s.dockerClient.EXPECT().AttachNetwork(ctx, testProxyContainerID, testAgentNetworkID)
+	s.dockerClient.EXPECT().AttachNetwork(ctx, testJWTProviderContainerID, testAgentNetworkID)
+	s.msgClient.EXPECT().Publish(messaging.SubjectAgentsStatusRunning, agentPayload)
+
+	s.r.NoError(s.service.handleAgentRunWithContext(ctx, agentPayload))
+}
+
+// envMatcher matches the agent container config with the env.
+type envMatcher clients.DockerContainerConfig
+
+// Matches implements the gomock.Matcher interface.
+func (m envMatcher) Matches(x interface{}) bool {
+	c, ok := x.(clients.DockerContainerConfig)
+	if !ok || c.Name != m.Name || len(c.Env) != len(m.Env) {
+		return false
+	}
+	for key, value := range m.Env {
+		if c.Env[key] != value {
+			return false
+		}
+	}
+	return true
+}
+
+// String implements the gomock.Matcher interface.
+func (m envMatcher) String() string {
+	return (configMatcher)(m).String()
+}
+
+// TestAgentRunWithEnv tests running the agent with the env vars from the config.
+func (s *Suite) TestAgentRunWithEnv() {
+	s.T().Setenv("TEST_AGENT_API_KEY", "key1")
+	agentConfig, agentPayload := testAgentData()
+	s.service.config.Config.Agent.Env = []config.AgentEnvConfig{
+		{AgentID: testAgentID, Vars: map[string]string{"API_KEY": "${TEST_AGENT_API_KEY}", config.EnvFortaBotID: "other"}},
+	}
+
+	ctx, cancel := context.WithTimeout(s.service.ctx, agentStartTimeout)
+	defer cancel()
+	s.agentImageClient.EXPECT().EnsureLocalImage(ctx, "agent test-agent", agentConfig.Image).Return(nil)
+	s.dockerClient.EXPECT().CreatePublicNetwork(ctx, testAgentContainerName).Return(testAgentNetworkID, nil)
+	s.dockerClient.EXPECT().StartContainer(
+		ctx, (envMatcher)(
+			clients.DockerContainerConfig{
+				Name: agentConfig.ContainerName(),
+				Env: map[string]string{
+					"API_KEY":                 "key1",
+					config.EnvJsonRpcHost:     config.DockerJSONRPCProxyContainerName,
+					config.EnvJsonRpcPort:     config.DefaultJSONRPCProxyPort,
+					config.EnvJWTProviderHost: config.DockerJWTProviderContainerName,
+					config.EnvJWTProviderPort: config.DefaultJWTProviderPort,
+					config.EnvAgentGrpcPort:   config.AgentGrpcPort,
+					config.EnvFortaBotID:      testAgentID,
+				},
+			},
+		),
+	).Return(&clients.DockerContainer{Name: agentConfig.ContainerName(), ID: testAgentContainerID}, nil)
+	s.dockerClient.EXPECT().AttachNetwork(ctx, testScannerContainerID, testAgentNetworkID)
+	s.dockerClient.EXPECT().AttachNetwork(ctx, testProxyContainerID, testAgentNetworkID)
+	s.dockerClient.EXPECT().AttachNetwork(ctx, testJWTProviderContainerID, testAgentNetworkID)
+	s.msgClient.EXPECT().Publish(messaging.SubjectAgentsStatusRunning, agentPayload)
+
+	s.r.NoError(s.service.handleAgentRunWithContext(ctx, agentPayload))
+}
+
+// labelsMatcher matches the agent container config with the labels.
+type labelsMatcher clients.DockerContainerConfig
+
+// Matches implements the gomock.Matcher interface.
+func (m labelsMatcher) Matches(x interface{}) bool {
+	c, ok := x.(clients.DockerContainerConfig)
+	if !ok || c.Name != m.Name || len(c.Labels) != len(m.Labels) {
+		return false
+	}
+	for key, value := range m.Labels {
+		if c.Labels[key] != value {
+			return false
+		}
+	}
+	return true
+}
+
+// String implements the gomock.Matcher interface.
+func (m labelsMatcher) String() string {
+	return (configMatcher)(m).String()
+}
+
+// TestAgentRunWithLabels tests running the agent with the container labels from the config.
+func (s *Suite) TestAgentRunWithLabels() {
+	agentConfig, agentPayload := testAgentData()
+	s.service.config.Config.Docker.ContainerLabels = map[string]string{"team": "infra", "env": "prod"}
+
+	ctx, cancel := context.WithTimeout(s.service.ctx, agentStartTimeout)
+	defer cancel()
+	s.agentImageClient.EXPECT().EnsureLocalImage(ctx, "agent test-agent", agentConfig.Image).Return(nil)
+	s.dockerClient.EXPECT().CreatePublicNetwork(ctx, testAgentContainerName).Return(testAgentNetworkID, nil)
+	s.dockerClient.EXPECT().StartContainer(
+		ctx, (labelsMatcher)(
+			clients.DockerContainerConfig{
+				Name: agentConfig.ContainerName(),
+				Labels: map[string]string{
+					"team": "infra",
+					"env":  "prod",
+					clients.DockerLabelFortaSupervisorStrategyVersion: SupervisorStrategyVersion,
+					clients.DockerLabelFortaAgentNetworkPolicy:        config.AgentNetworkPolicyOpen,
+					clients.DockerLabelFortaAgentID:                   agentConfig.ID,
+				},
+			},
+		),
+	).Return(&clients.DockerContainer{Name: agentConfig.ContainerName(), ID: testAgentContainerID}, nil)
+	s.dockerClient.EXPECT().AttachNetwork(ctx, testScannerContainerID, testAgentNetworkID)
+	s.dockerClient.EXPECT().AttachNetwork(ctx, testProxyContainerID, testAgentNetworkID)
+	s.dockerClient.EXPECT().AttachNetwork(ctx, testJWTProviderContainerID, testAgentNetworkID)
+	s.msgClient.EXPECT().Publish(messaging.SubjectAgentsStatusRunning, agentPayload)
+
+	s.r.NoError(s.service.handleAgentRunWithContext(ctx, agentPayload))
+}
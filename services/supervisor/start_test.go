@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"os"
 	"testing"
+	"time"
 
 	"github.com/forta-network/forta-core-go/release"
 
@@ -16,6 +17,7 @@ import (
 	"github.com/forta-network/forta-node/clients/messaging"
 	mock_clients "github.com/forta-network/forta-node/clients/mocks"
 	"github.com/forta-network/forta-node/config"
+	"github.com/forta-network/forta-node/store"
 	"github.com/golang/mock/gomock"
 	"github.com/stretchr/testify/require"
 	"github.com/stretchr/testify/suite"
@@ -94,6 +96,10 @@ func (s *Suite) SetupTest() {
 		msgClient:        s.msgClient,
 		releaseClient:    s.releaseClient,
 		agentImageClient: s.agentImageClient,
+		agentRestarts:    make(map[string]*agentRestartState),
+
+		disabledAgents:       store.NewDisabledAgentsStore(s.T().TempDir()),
+		disabledAgentConfigs: make(map[string]config.AgentConfig),
 	}
 	service.config.Config.TelemetryConfig.Disable = true
 	service.config.Config.Log.Level = "debug"
@@ -169,6 +175,9 @@ func (s *Suite) SetupTest() {
 	s.dockerClient.EXPECT().WaitContainerStart(service.ctx, gomock.Any()).Return(nil).AnyTimes()
 	s.msgClient.EXPECT().Subscribe(messaging.SubjectAgentsActionRun, gomock.Any())
 	s.msgClient.EXPECT().Subscribe(messaging.SubjectAgentsActionStop, gomock.Any())
+	s.msgClient.EXPECT().Subscribe(messaging.SubjectAgentsActionRestart, gomock.Any())
+	s.msgClient.EXPECT().Subscribe(messaging.SubjectScannerBlock, gomock.Any())
+	s.msgClient.EXPECT().Subscribe(messaging.SubjectNodeDrainState, gomock.Any())
 
 	s.r.NoError(service.start())
 }
@@ -185,6 +194,8 @@ func (s *Suite) initialContainerCheck() {
 	} {
 		s.dockerClient.EXPECT().GetContainerByName(s.service.ctx, containerName).Return(&types.Container{ID: testGenericContainerID}, nil)
 	}
+	// the egress proxy is not running in the open network policy
+	s.dockerClient.EXPECT().GetContainerByName(s.service.ctx, config.DockerEgressProxyContainerName).Return(nil, clients.ErrContainerNotFound)
 
 	s.dockerClient.EXPECT().GetContainers(s.service.ctx).Return(
 		[]types.Container{
@@ -275,12 +286,23 @@ func (s *Suite) TestAgentStopOne() {
 
 	_, agentPayload := testAgentData()
 	// Stops the agent container and publishes a "stopped" message.
-	s.dockerClient.EXPECT().StopContainer(s.service.ctx, testAgentContainerID)
+	s.dockerClient.EXPECT().StopContainer(s.service.ctx, testAgentContainerID, time.Duration(0))
 	s.msgClient.EXPECT().Publish(messaging.SubjectAgentsStatusStopped, agentPayload)
 
 	s.r.NoError(s.service.handleAgentStop(agentPayload))
 }
 
+// TestStopWithTimeout tests stopping the containers gracefully.
+func (s *Suite) TestStopWithTimeout() {
+	s.TestAgentRun()
+
+	s.service.config.Config.ResourcesConfig.ContainerStopTimeoutSeconds = 30
+	s.dockerClient.EXPECT().StopContainer(gomock.Any(), testAgentContainerID, 30*time.Second).Return(nil)
+	s.dockerClient.EXPECT().TerminateContainer(gomock.Any(), gomock.Any(), 30*time.Second).Return(nil).AnyTimes()
+
+	s.r.NoError(s.service.Stop())
+}
+
 // TestAgentStopNone tests stopping when there are no agents.
 func (s *Suite) TestAgentStopNone() {
 	s.TestAgentRun()
@@ -37,24 +37,27 @@ type UpdaterService struct {
 	registryClient registry.Client
 	server         *http.Server
 
-	developmentMode  bool
-	trackPrereleases bool
+	developmentMode bool
+	releaseChannel  string
 
 	latestReference string
 	latestRelease   *release.ReleaseManifest
+	mismatchedRef   string // prerelease which does not match the channel
 
 	updateDelay         time.Duration
 	updateCheckInterval time.Duration
 
 	lastChecked        health.TimeTracker
+	lastSucceeded      health.TimeTracker
 	lastErr            health.ErrorTracker
 	latestVersion      health.MessageTracker
 	latestIsPrerelease health.MessageTracker
+	latestChannel      health.MessageTracker
 }
 
-// NewUpdaterService creates a new updater service.
+// NewUpdaterService creates a new updater service which provides the latest release from given channel.
 func NewUpdaterService(ctx context.Context, registryClient registry.Client, releaseClient release.Client,
-	port string, developmentMode bool, trackPrereleases bool, updateDelaySeconds, updateCheckIntervalSeconds int,
+	port string, developmentMode bool, releaseChannel string, updateDelaySeconds, updateCheckIntervalSeconds int,
 ) *UpdaterService {
 	if updateCheckIntervalSeconds == 0 {
 		updateCheckIntervalSeconds = defaultUpdateCheckIntervalSeconds
@@ -66,7 +69,7 @@ func NewUpdaterService(ctx context.Context, registryClient registry.Client, rele
 		releaseClient:       releaseClient,
 		registryClient:      registryClient,
 		developmentMode:     developmentMode,
-		trackPrereleases:    trackPrereleases,
+		releaseChannel:      releaseChannel,
 		updateDelay:         time.Duration(updateDelaySeconds) * time.Second,
 		updateCheckInterval: time.Duration(updateCheckIntervalSeconds) * time.Second,
 	}
@@ -92,14 +95,32 @@ func (updater *UpdaterService) handleGetVersion(w http.ResponseWriter, r *http.R
 	w.Write(b)
 }
 
+// handleCheck checks the latest release immediately and without the update delay so that the
+// operators can roll out a release on demand.
+func (updater *UpdaterService) handleCheck(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+	if err := updater.checkLatestRelease(0); err != nil {
+		log.WithError(err).Error("error checking the release on demand")
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.WriteHeader(http.StatusOK)
+}
+
 // Start starts the service.
 func (updater *UpdaterService) Start() error {
+	mux := http.NewServeMux()
+	mux.HandleFunc(config.UpdaterCheckPath, updater.handleCheck)
+	mux.HandleFunc("/", updater.handleGetVersion)
 	updater.server = &http.Server{
 		Addr:    fmt.Sprintf(":%s", updater.port),
-		Handler: http.HandlerFunc(updater.handleGetVersion),
+		Handler: mux,
 	}
 
-	if err := updater.updateLatestRelease(); err != nil {
+	if err := updater.checkLatestRelease(0); err != nil {
 		log.WithError(err).Error("error initializing release")
 		return err
 	}
@@ -115,10 +136,7 @@ func (updater *UpdaterService) Start() error {
 				updater.stopServer()
 				return
 			case <-t.C:
-				err := updater.updateLatestReleaseWithDelay(updater.updateDelay)
-				updater.lastErr.Set(err)
-				updater.lastChecked.Set()
-				if err != nil {
+				if err := updater.checkLatestRelease(updater.updateDelay); err != nil {
 					log.WithError(err).Error("error getting release")
 				}
 			}
@@ -129,6 +147,17 @@ func (updater *UpdaterService) Start() error {
 	return nil
 }
 
+// checkLatestRelease updates the latest release and records the result of the check.
+func (updater *UpdaterService) checkLatestRelease(delay time.Duration) error {
+	err := updater.updateLatestReleaseWithDelay(delay)
+	updater.lastErr.Set(err)
+	updater.lastChecked.Set()
+	if err == nil {
+		updater.lastSucceeded.Set()
+	}
+	return err
+}
+
 func (updater *UpdaterService) updateLatestRelease() error {
 	return updater.updateLatestReleaseWithDelay(0)
 }
@@ -178,8 +207,10 @@ func (updater *UpdaterService) updateLatestReleaseWithDelay(delay time.Duration)
 		log.Info("successfully waited before version update")
 	}
 
+	latestChannel := config.GetReleaseChannel(&release.ReleaseInfo{Manifest: *releaseManifest})
 	updater.latestVersion.Set(releaseManifest.Release.Version)
-	updater.latestIsPrerelease.Set(strconv.FormatBool(updater.trackPrereleases))
+	updater.latestIsPrerelease.Set(strconv.FormatBool(latestChannel != config.ReleaseChannelStable))
+	updater.latestChannel.Set(latestChannel)
 
 	updater.mu.Lock()
 	defer updater.mu.Unlock()
@@ -197,12 +228,53 @@ func (updater *UpdaterService) fetchNewerRelease(previousRef string) (string, *r
 	if err != nil {
 		return ref, nil, err
 	}
+	rm, err := updater.getReleaseManifest(ref)
+	if err != nil {
+		return ref, nil, err
+	}
+	if config.MatchesReleaseChannel(&release.ReleaseInfo{Manifest: *rm}, updater.releaseChannel) {
+		return ref, rm, nil
+	}
+
+	// the prerelease is newer than what this node tracks (e.g. canary release for a beta node)
+	log.WithFields(log.Fields{
+		"release": ref,
+		"version": rm.Release.Version,
+		"channel": updater.releaseChannel,
+	}).Warn("release does not match the channel - falling back to stable")
+	updater.mu.Lock()
+	updater.mismatchedRef = ref
+	updater.mu.Unlock()
+	ref, err = updater.getStableReleaseRef()
+	if err != nil {
+		return ref, nil, err
+	}
+	if ref == previousRef {
+		return ref, nil, errNotAvailable
+	}
+	rm, err = updater.getReleaseManifest(ref)
+	if err != nil {
+		return ref, nil, err
+	}
+	return ref, rm, nil
+}
+
+func (updater *UpdaterService) getReleaseManifest(ref string) (*release.ReleaseManifest, error) {
 	rm, err := updater.releaseClient.GetReleaseManifest(context.Background(), ref)
 	if err != nil {
 		log.WithError(err).Error("error getting release manifest")
-		return ref, nil, fmt.Errorf("failed while downloading the release manifest: %v", err)
+		return nil, fmt.Errorf("failed while downloading the release manifest: %v", err)
 	}
-	return ref, rm, nil
+	return rm, nil
+}
+
+func (updater *UpdaterService) getStableReleaseRef() (string, error) {
+	ref, err := updater.registryClient.GetScannerNodeVersion()
+	if err != nil {
+		log.WithError(err).Error("error getting the latest release manifest ref")
+		return "", fmt.Errorf("failed to get the latest release manifest ref: %v", err)
+	}
+	return ref, nil
 }
 
 func (updater *UpdaterService) compareScannerNodeVersion(previousRef string) (newRef string, err error) {
@@ -211,15 +283,25 @@ func (updater *UpdaterService) compareScannerNodeVersion(previousRef string) (ne
 		return
 	}
 
+	// beta and canary nodes track the prerelease and fall back to stable if there is none
 	var ref string
-	if updater.trackPrereleases {
+	if updater.releaseChannel != config.ReleaseChannelStable {
 		ref, err = updater.registryClient.GetScannerNodePrereleaseVersion()
-	} else {
-		ref, err = updater.registryClient.GetScannerNodeVersion()
+		if err != nil {
+			log.WithError(err).Error("error getting the latest prerelease manifest ref")
+			return "", fmt.Errorf("failed to get the latest prerelease manifest ref: %v", err)
+		}
+		updater.mu.RLock()
+		if ref == updater.mismatchedRef {
+			ref = ""
+		}
+		updater.mu.RUnlock()
 	}
-	if err != nil {
-		log.WithError(err).Error("error getting the latest release manifest ref")
-		return "", fmt.Errorf("failed to get the latest release manifest ref: %v", err)
+	if len(ref) == 0 {
+		ref, err = updater.getStableReleaseRef()
+		if err != nil {
+			return "", err
+		}
 	}
 	if ref == previousRef {
 		return ref, errNotAvailable
@@ -305,8 +387,15 @@ func (updater *UpdaterService) Stop() error {
 func (updater *UpdaterService) Health() health.Reports {
 	return health.Reports{
 		updater.lastChecked.GetReport("event.checked.time"),
+		updater.lastSucceeded.GetReport("event.succeeded.time"),
 		updater.lastErr.GetReport("event.checked.error"),
 		updater.latestVersion.GetReport("latest.version"),
 		updater.latestIsPrerelease.GetReport("latest.is-prerelease"),
+		updater.latestChannel.GetReport("latest.channel"),
+		&health.Report{
+			Name:    "channel",
+			Status:  health.StatusInfo,
+			Details: updater.releaseChannel,
+		},
 	}
 }
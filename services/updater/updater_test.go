@@ -2,9 +2,13 @@ package updater
 
 import (
 	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
 	"testing"
 
 	"github.com/forta-network/forta-core-go/release"
+	"github.com/forta-network/forta-node/config"
 	"github.com/stretchr/testify/require"
 
 	rm "github.com/forta-network/forta-core-go/registry/mocks"
@@ -17,13 +21,17 @@ const (
 	testUpdateDelaySeconds         = 15
 )
 
+func testReleaseManifest(version string) *release.ReleaseManifest {
+	return &release.ReleaseManifest{Release: release.Release{Version: version}}
+}
+
 func TestUpdaterService_UpdateLatestRelease(t *testing.T) {
 	r := require.New(t)
 
 	registryClient := rm.NewMockClient(gomock.NewController(t))
 	releaseClient := im.NewMockClient(gomock.NewController(t))
 	updater := NewUpdaterService(
-		context.Background(), registryClient, releaseClient, "8080", false, false,
+		context.Background(), registryClient, releaseClient, "8080", false, config.ReleaseChannelStable,
 		testUpdateDelaySeconds, testUpdateCheckIntervalSeconds,
 	)
 
@@ -40,7 +48,7 @@ func TestUpdaterService_UpdateLatestReleaseCached(t *testing.T) {
 	registryClient := rm.NewMockClient(gomock.NewController(t))
 	releaseClient := im.NewMockClient(gomock.NewController(t))
 	updater := NewUpdaterService(
-		context.Background(), registryClient, releaseClient, "8080", false, false,
+		context.Background(), registryClient, releaseClient, "8080", false, config.ReleaseChannelStable,
 		testUpdateDelaySeconds, testUpdateCheckIntervalSeconds,
 	)
 
@@ -60,7 +68,7 @@ func TestUpdaterService_UpdateLatestReleaseNotCached(t *testing.T) {
 	registryClient := rm.NewMockClient(gomock.NewController(t))
 	releaseClient := im.NewMockClient(gomock.NewController(t))
 	updater := NewUpdaterService(
-		context.Background(), registryClient, releaseClient, "8080", false, false,
+		context.Background(), registryClient, releaseClient, "8080", false, config.ReleaseChannelStable,
 		testUpdateDelaySeconds, testUpdateCheckIntervalSeconds,
 	)
 
@@ -81,7 +89,7 @@ func TestUpdaterService_UpdateLatestReleaseAbort(t *testing.T) {
 	registryClient := rm.NewMockClient(gomock.NewController(t))
 	releaseClient := im.NewMockClient(gomock.NewController(t))
 	updater := NewUpdaterService(
-		context.Background(), registryClient, releaseClient, "8080", false, false,
+		context.Background(), registryClient, releaseClient, "8080", false, config.ReleaseChannelStable,
 		testUpdateDelaySeconds, testUpdateCheckIntervalSeconds,
 	)
 
@@ -98,3 +106,125 @@ func TestUpdaterService_UpdateLatestReleaseAbort(t *testing.T) {
 	// update should be ineffective and be aborted
 	r.Equal(initalLatestRef, updater.latestReference)
 }
+
+func TestUpdaterService_ChannelFallbackToStable(t *testing.T) {
+	r := require.New(t)
+
+	registryClient := rm.NewMockClient(gomock.NewController(t))
+	releaseClient := im.NewMockClient(gomock.NewController(t))
+	updater := NewUpdaterService(
+		context.Background(), registryClient, releaseClient, "8080", false, config.ReleaseChannelBeta,
+		testUpdateDelaySeconds, testUpdateCheckIntervalSeconds,
+	)
+
+	// no prerelease in the channel
+	registryClient.EXPECT().GetScannerNodePrereleaseVersion().Return("", nil).Times(1)
+	registryClient.EXPECT().GetScannerNodeVersion().Return("stable", nil).Times(1)
+	releaseClient.EXPECT().GetReleaseManifest(gomock.Any(), "stable").Return(testReleaseManifest("v0.7.1"), nil).
+		Times(1)
+
+	r.NoError(updater.updateLatestRelease())
+	r.Equal("stable", updater.latestReference)
+	r.Equal(config.ReleaseChannelStable, updater.latestChannel.GetReport("").Details)
+}
+
+func TestUpdaterService_ChannelMismatch(t *testing.T) {
+	r := require.New(t)
+
+	registryClient := rm.NewMockClient(gomock.NewController(t))
+	releaseClient := im.NewMockClient(gomock.NewController(t))
+	updater := NewUpdaterService(
+		context.Background(), registryClient, releaseClient, "8080", false, config.ReleaseChannelBeta,
+		testUpdateDelaySeconds, testUpdateCheckIntervalSeconds,
+	)
+
+	// the prerelease is a canary release which beta nodes should not use
+	registryClient.EXPECT().GetScannerNodePrereleaseVersion().Return("canary", nil).Times(2)
+	releaseClient.EXPECT().GetReleaseManifest(gomock.Any(), "canary").Return(testReleaseManifest("v0.7.2-canary.1"), nil).
+		Times(1)
+	registryClient.EXPECT().GetScannerNodeVersion().Return("stable", nil).Times(2)
+	releaseClient.EXPECT().GetReleaseManifest(gomock.Any(), "stable").Return(testReleaseManifest("v0.7.1"), nil).
+		Times(1)
+
+	r.NoError(updater.updateLatestRelease())
+	r.Equal("stable", updater.latestReference)
+
+	// the mismatched release is not downloaded again
+	r.NoError(updater.updateLatestRelease())
+	r.Equal("stable", updater.latestReference)
+}
+
+func TestUpdaterService_ChannelMatch(t *testing.T) {
+	r := require.New(t)
+
+	registryClient := rm.NewMockClient(gomock.NewController(t))
+	releaseClient := im.NewMockClient(gomock.NewController(t))
+	updater := NewUpdaterService(
+		context.Background(), registryClient, releaseClient, "8080", false, config.ReleaseChannelCanary,
+		testUpdateDelaySeconds, testUpdateCheckIntervalSeconds,
+	)
+
+	registryClient.EXPECT().GetScannerNodePrereleaseVersion().Return("canary", nil).Times(1)
+	releaseClient.EXPECT().GetReleaseManifest(gomock.Any(), "canary").Return(testReleaseManifest("v0.7.2-canary.1"), nil).
+		Times(1)
+
+	r.NoError(updater.updateLatestRelease())
+	r.Equal("canary", updater.latestReference)
+	r.Equal(config.ReleaseChannelCanary, updater.latestChannel.GetReport("").Details)
+}
+
+func TestUpdaterService_CheckLatestRelease(t *testing.T) {
+	r := require.New(t)
+
+	registryClient := rm.NewMockClient(gomock.NewController(t))
+	releaseClient := im.NewMockClient(gomock.NewController(t))
+	updater := NewUpdaterService(
+		context.Background(), registryClient, releaseClient, "8080", false, config.ReleaseChannelStable,
+		testUpdateDelaySeconds, testUpdateCheckIntervalSeconds,
+	)
+
+	registryClient.EXPECT().GetScannerNodeVersion().Return("reference", nil)
+	releaseClient.EXPECT().GetReleaseManifest(gomock.Any(), "reference").Return(&release.ReleaseManifest{}, nil)
+	r.NoError(updater.checkLatestRelease(0))
+	succeeded := updater.lastSucceeded.String()
+	r.NotEmpty(succeeded)
+
+	registryClient.EXPECT().GetScannerNodeVersion().Return("", errors.New("registry error"))
+	r.Error(updater.checkLatestRelease(0))
+	reports := updater.Health()
+	checkErr, ok := reports.GetByName("event.checked.error")
+	r.True(ok)
+	r.Contains(checkErr.Details, "registry error")
+	lastSucceeded, ok := reports.GetByName("event.succeeded.time")
+	r.True(ok)
+	r.Equal(succeeded, lastSucceeded.Details)
+}
+
+func TestUpdaterService_HandleCheck(t *testing.T) {
+	r := require.New(t)
+
+	registryClient := rm.NewMockClient(gomock.NewController(t))
+	releaseClient := im.NewMockClient(gomock.NewController(t))
+	updater := NewUpdaterService(
+		context.Background(), registryClient, releaseClient, "8080", false, config.ReleaseChannelStable,
+		testUpdateDelaySeconds, testUpdateCheckIntervalSeconds,
+	)
+
+	// checks without the update delay
+	registryClient.EXPECT().GetScannerNodeVersion().Return("reference", nil)
+	releaseClient.EXPECT().GetReleaseManifest(gomock.Any(), "reference").Return(testReleaseManifest("v0.7.2"), nil)
+	w := httptest.NewRecorder()
+	updater.handleCheck(w, httptest.NewRequest(http.MethodPost, config.UpdaterCheckPath, nil))
+	r.Equal(http.StatusOK, w.Code)
+	r.Equal("reference", updater.latestReference)
+
+	registryClient.EXPECT().GetScannerNodeVersion().Return("", errors.New("registry error"))
+	w = httptest.NewRecorder()
+	updater.handleCheck(w, httptest.NewRequest(http.MethodPost, config.UpdaterCheckPath, nil))
+	r.Equal(http.StatusInternalServerError, w.Code)
+	r.Contains(w.Body.String(), "registry error")
+
+	w = httptest.NewRecorder()
+	updater.handleCheck(w, httptest.NewRequest(http.MethodGet, config.UpdaterCheckPath, nil))
+	r.Equal(http.StatusMethodNotAllowed, w.Code)
+}
@@ -0,0 +1,255 @@
+package store
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"os"
+	"path"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/forta-network/forta-core-go/domain"
+	"github.com/goccy/go-json"
+	log "github.com/sirupsen/logrus"
+)
+
+const (
+	batchQueueFileExt    = ".batch"
+	batchQueueHeaderSize = 8 // 4 bytes length + 4 bytes checksum
+)
+
+// Batch queue errors
+var (
+	ErrBatchQueueEmpty   = errors.New("batch queue is empty")
+	errCorruptQueueEntry = errors.New("corrupt batch queue entry")
+)
+
+// QueuedBatch is a batch request which failed to be sent and waits for a retry.
+type QueuedBatch struct {
+	QueuedAt time.Time                 `json:"queuedAt"`
+	Request  *domain.AlertBatchRequest `json:"request"`
+}
+
+// BatchQueue persists the batches that need to be sent later.
+type BatchQueue interface {
+	Push(batch *QueuedBatch) error
+	Peek() (*QueuedBatch, error)
+	Pop() error
+	Len() int
+	OldestAge() time.Duration
+	Dropped() int
+}
+
+type batchQueueEntry struct {
+	fileName string
+	size     int64
+	queuedAt time.Time
+}
+
+type batchQueue struct {
+	dir      string
+	maxBytes int64
+	maxAge   time.Duration
+
+	entries   []*batchQueueEntry
+	totalSize int64
+	dropped   int
+	seq       uint64
+	mu        sync.Mutex
+}
+
+// NewBatchQueue creates a disk-backed batch queue in given dir and loads
+// the existing entries. Zero limits mean no limit.
+func NewBatchQueue(dir string, maxBytes int64, maxAge time.Duration) (*batchQueue, error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, fmt.Errorf("failed to create the batch queue dir: %v", err)
+	}
+	queue := &batchQueue{
+		dir:      dir,
+		maxBytes: maxBytes,
+		maxAge:   maxAge,
+	}
+	if err := queue.load(); err != nil {
+		return nil, err
+	}
+	return queue, nil
+}
+
+func (queue *batchQueue) load() error {
+	dirEntries, err := os.ReadDir(queue.dir)
+	if err != nil {
+		return fmt.Errorf("failed to read the batch queue dir: %v", err)
+	}
+	var fileNames []string
+	for _, dirEntry := range dirEntries {
+		if dirEntry.IsDir() || !strings.HasSuffix(dirEntry.Name(), batchQueueFileExt) {
+			continue
+		}
+		fileNames = append(fileNames, dirEntry.Name())
+	}
+	sort.Strings(fileNames)
+
+	for _, fileName := range fileNames {
+		logger := log.WithField("file", fileName)
+		batch, size, err := queue.readEntry(fileName)
+		if err != nil {
+			// most probably a crash happened during the write
+			logger.WithError(err).Warn("skipping unreadable batch queue entry")
+			os.Remove(path.Join(queue.dir, fileName))
+			continue
+		}
+		queue.entries = append(queue.entries, &batchQueueEntry{
+			fileName: fileName,
+			size:     size,
+			queuedAt: batch.QueuedAt,
+		})
+		queue.totalSize += size
+	}
+	if len(queue.entries) > 0 {
+		log.WithField("count", len(queue.entries)).Info("loaded queued batches")
+	}
+	queue.enforceLimits()
+	return nil
+}
+
+// Push appends a batch to the end of the queue.
+func (queue *batchQueue) Push(batch *QueuedBatch) error {
+	queue.mu.Lock()
+	defer queue.mu.Unlock()
+
+	if batch.QueuedAt.IsZero() {
+		batch.QueuedAt = time.Now().UTC()
+	}
+	payload, err := json.Marshal(batch)
+	if err != nil {
+		return fmt.Errorf("failed to encode queued batch: %v", err)
+	}
+	record := make([]byte, batchQueueHeaderSize+len(payload))
+	binary.BigEndian.PutUint32(record[0:4], uint32(len(payload)))
+	binary.BigEndian.PutUint32(record[4:8], crc32.ChecksumIEEE(payload))
+	copy(record[batchQueueHeaderSize:], payload)
+
+	queue.seq++
+	fileName := fmt.Sprintf("%020d-%06d%s", time.Now().UnixNano(), queue.seq%1000000, batchQueueFileExt)
+	if err := os.WriteFile(path.Join(queue.dir, fileName), record, 0644); err != nil {
+		return fmt.Errorf("failed to write queued batch: %v", err)
+	}
+
+	queue.entries = append(queue.entries, &batchQueueEntry{
+		fileName: fileName,
+		size:     int64(len(record)),
+		queuedAt: batch.QueuedAt,
+	})
+	queue.totalSize += int64(len(record))
+	queue.enforceLimits()
+	return nil
+}
+
+// Peek returns the oldest batch in the queue without removing it.
+func (queue *batchQueue) Peek() (*QueuedBatch, error) {
+	queue.mu.Lock()
+	defer queue.mu.Unlock()
+
+	queue.enforceLimits()
+	for len(queue.entries) > 0 {
+		batch, _, err := queue.readEntry(queue.entries[0].fileName)
+		if err == nil {
+			return batch, nil
+		}
+		log.WithError(err).WithField("file", queue.entries[0].fileName).Warn("removing unreadable batch queue entry")
+		queue.removeFirst()
+	}
+	return nil, ErrBatchQueueEmpty
+}
+
+// Pop removes the oldest batch from the queue.
+func (queue *batchQueue) Pop() error {
+	queue.mu.Lock()
+	defer queue.mu.Unlock()
+
+	if len(queue.entries) == 0 {
+		return ErrBatchQueueEmpty
+	}
+	queue.removeFirst()
+	return nil
+}
+
+// Len returns the number of queued batches.
+func (queue *batchQueue) Len() int {
+	queue.mu.Lock()
+	defer queue.mu.Unlock()
+	return len(queue.entries)
+}
+
+// OldestAge returns the age of the oldest batch in the queue.
+func (queue *batchQueue) OldestAge() time.Duration {
+	queue.mu.Lock()
+	defer queue.mu.Unlock()
+	if len(queue.entries) == 0 {
+		return 0
+	}
+	return time.Since(queue.entries[0].queuedAt)
+}
+
+// Dropped returns the number of batches dropped due to the queue limits.
+func (queue *batchQueue) Dropped() int {
+	queue.mu.Lock()
+	defer queue.mu.Unlock()
+	return queue.dropped
+}
+
+// drops the oldest entries until the queue is within the limits
+func (queue *batchQueue) enforceLimits() {
+	for len(queue.entries) > 0 {
+		oldest := queue.entries[0]
+		exceedsSize := queue.maxBytes > 0 && queue.totalSize > queue.maxBytes
+		exceedsAge := queue.maxAge > 0 && time.Since(oldest.queuedAt) > queue.maxAge
+		if !exceedsSize && !exceedsAge {
+			return
+		}
+		log.WithFields(log.Fields{
+			"file":        oldest.fileName,
+			"exceedsSize": exceedsSize,
+			"exceedsAge":  exceedsAge,
+		}).Warn("batch queue limit exceeded - dropping oldest batch")
+		queue.removeFirst()
+		queue.dropped++
+	}
+}
+
+func (queue *batchQueue) removeFirst() {
+	first := queue.entries[0]
+	if err := os.Remove(path.Join(queue.dir, first.fileName)); err != nil && !os.IsNotExist(err) {
+		log.WithError(err).WithField("file", first.fileName).Warn("failed to remove batch queue entry")
+	}
+	queue.entries = queue.entries[1:]
+	queue.totalSize -= first.size
+}
+
+func (queue *batchQueue) readEntry(fileName string) (*QueuedBatch, int64, error) {
+	b, err := os.ReadFile(path.Join(queue.dir, fileName))
+	if err != nil {
+		return nil, 0, err
+	}
+	if len(b) < batchQueueHeaderSize {
+		return nil, 0, fmt.Errorf("%w: short header", errCorruptQueueEntry)
+	}
+	length := binary.BigEndian.Uint32(b[0:4])
+	checksum := binary.BigEndian.Uint32(b[4:8])
+	payload := b[batchQueueHeaderSize:]
+	if uint32(len(payload)) != length {
+		return nil, 0, fmt.Errorf("%w: expected %d bytes but found %d", errCorruptQueueEntry, length, len(payload))
+	}
+	if crc32.ChecksumIEEE(payload) != checksum {
+		return nil, 0, fmt.Errorf("%w: checksum mismatch", errCorruptQueueEntry)
+	}
+	var batch QueuedBatch
+	if err := json.Unmarshal(payload, &batch); err != nil {
+		return nil, 0, fmt.Errorf("%w: %v", errCorruptQueueEntry, err)
+	}
+	return &batch, int64(len(b)), nil
+}
@@ -0,0 +1,119 @@
+package store
+
+import (
+	"os"
+	"path"
+	"testing"
+	"time"
+
+	"github.com/forta-network/forta-core-go/domain"
+	"github.com/stretchr/testify/require"
+)
+
+func testQueuedBatch(ref string) *QueuedBatch {
+	return &QueuedBatch{
+		Request: &domain.AlertBatchRequest{
+			Ref:        ref,
+			BlockStart: 1,
+			BlockEnd:   2,
+		},
+	}
+}
+
+func TestBatchQueue_Order(t *testing.T) {
+	r := require.New(t)
+
+	dir := t.TempDir()
+	queue, err := NewBatchQueue(dir, 0, 0)
+	r.NoError(err)
+
+	r.NoError(queue.Push(testQueuedBatch("ref1")))
+	r.NoError(queue.Push(testQueuedBatch("ref2")))
+	r.Equal(2, queue.Len())
+
+	// reload from disk
+	queue, err = NewBatchQueue(dir, 0, 0)
+	r.NoError(err)
+	r.Equal(2, queue.Len())
+
+	batch, err := queue.Peek()
+	r.NoError(err)
+	r.Equal("ref1", batch.Request.Ref)
+	r.False(batch.QueuedAt.IsZero())
+	r.NoError(queue.Pop())
+
+	batch, err = queue.Peek()
+	r.NoError(err)
+	r.Equal("ref2", batch.Request.Ref)
+	r.NoError(queue.Pop())
+
+	_, err = queue.Peek()
+	r.ErrorIs(err, ErrBatchQueueEmpty)
+	r.ErrorIs(queue.Pop(), ErrBatchQueueEmpty)
+}
+
+func TestBatchQueue_DropOldestBySize(t *testing.T) {
+	r := require.New(t)
+
+	queue, err := NewBatchQueue(t.TempDir(), 0, 0)
+	r.NoError(err)
+	r.NoError(queue.Push(testQueuedBatch("ref1")))
+	entrySize := queue.totalSize
+
+	// the entry sizes can differ slightly because of the timestamp encoding
+	queue.maxBytes = entrySize*2 + entrySize/2
+	r.NoError(queue.Push(testQueuedBatch("ref2")))
+	r.NoError(queue.Push(testQueuedBatch("ref3")))
+	r.Equal(2, queue.Len())
+	r.Equal(1, queue.Dropped())
+
+	batch, err := queue.Peek()
+	r.NoError(err)
+	r.Equal("ref2", batch.Request.Ref)
+}
+
+func TestBatchQueue_DropOldestByAge(t *testing.T) {
+	r := require.New(t)
+
+	queue, err := NewBatchQueue(t.TempDir(), 0, time.Hour)
+	r.NoError(err)
+
+	old := testQueuedBatch("ref1")
+	old.QueuedAt = time.Now().Add(-time.Hour * 2)
+	r.NoError(queue.Push(old))
+	r.NoError(queue.Push(testQueuedBatch("ref2")))
+
+	r.Equal(1, queue.Len())
+	r.Equal(1, queue.Dropped())
+	r.Less(queue.OldestAge(), time.Minute)
+}
+
+func TestBatchQueue_SkipPartialRecord(t *testing.T) {
+	r := require.New(t)
+
+	dir := t.TempDir()
+	queue, err := NewBatchQueue(dir, 0, 0)
+	r.NoError(err)
+	r.NoError(queue.Push(testQueuedBatch("ref1")))
+	r.NoError(queue.Push(testQueuedBatch("ref2")))
+
+	// simulate a crash during the write of the first record
+	firstFile := path.Join(dir, queue.entries[0].fileName)
+	b, err := os.ReadFile(firstFile)
+	r.NoError(err)
+	r.NoError(os.WriteFile(firstFile, b[:len(b)/2], 0644))
+
+	// and a record with only a partial header
+	r.NoError(os.WriteFile(path.Join(dir, "00000000000000000000-000000.batch"), []byte{0, 1}, 0644))
+
+	queue, err = NewBatchQueue(dir, 0, 0)
+	r.NoError(err)
+	r.Equal(1, queue.Len())
+
+	batch, err := queue.Peek()
+	r.NoError(err)
+	r.Equal("ref2", batch.Request.Ref)
+
+	_, err = os.Stat(firstFile)
+	r.True(os.IsNotExist(err))
+}
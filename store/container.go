@@ -2,11 +2,15 @@ package store
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"io"
+	"math/rand"
 	"net/http"
+	"sync"
 	"time"
 
+	"github.com/forta-network/forta-core-go/clients/health"
 	"github.com/forta-network/forta-core-go/release"
 	"github.com/goccy/go-json"
 	log "github.com/sirupsen/logrus"
@@ -14,12 +18,24 @@ import (
 	"github.com/forta-network/forta-node/config"
 )
 
-const defaultImageCheckInterval = time.Second * 5
+// the check interval, the max jitter as the ratio of the interval and the max backoff after failures
+const (
+	defaultImageCheckInterval = time.Second * 5
+	imageCheckJitterRatio     = 0.2
+	maxImageCheckBackoff      = time.Minute * 10
+)
+
+const releaseNotesTimeout = time.Second * 30
+
+// ErrOfflineImageStore is returned when the offline store is asked to check the updater.
+var ErrOfflineImageStore = errors.New("offline mode - not checking the updater")
 
 // FortaImageStore keeps track of the latest Forta node image.
 type FortaImageStore interface {
 	Latest() <-chan ImageRefs
 	EmbeddedImageRefs() ImageRefs
+	SetReleaseChannel(channel string)
+	CheckNow(ctx context.Context) (*ImageRefs, error)
 }
 
 // ImageRefs contains the latest image references.
@@ -27,19 +43,56 @@ type ImageRefs struct {
 	Supervisor  string
 	Updater     string
 	ReleaseInfo *release.ReleaseInfo
+	// Notes are the notes of the release if the release has any.
+	Notes *config.ReleaseNotes
+}
+
+// ReleaseSource provides the latest release.
+type ReleaseSource interface {
+	LatestRelease(ctx context.Context) (*release.ReleaseInfo, error)
+}
+
+// releaseChecker is a release source which can be asked to check the latest release immediately.
+type releaseChecker interface {
+	CheckRelease(ctx context.Context) error
 }
 
 type fortaImageStore struct {
-	updaterPort string
-	latestCh    chan ImageRefs
-	latestImgs  ImageRefs
+	offline        bool
+	autoUpdate     bool
+	source         ReleaseSource
+	notesSource    ReleaseNotesSource
+	checkInterval  time.Duration
+	releaseChannel string
+	latestCh       chan ImageRefs
+	refreshCh      chan struct{}
+	sentImgs       ImageRefs    // the last refs provided from the latest channel
+	mu             sync.RWMutex // protects the channel and the sent images
+
+	lastChecked health.TimeTracker
+	lastErr     error
+	failures    int
+	errMu       sync.RWMutex // protects the error and the failure count
 }
 
-// NewFortaImageStore creates a new store.
-func NewFortaImageStore(ctx context.Context, updaterPort string, autoUpdate bool) (*fortaImageStore, error) {
+// NewFortaImageStore creates a new store which provides the latest releases from given channel.
+// The updater is checked at the given interval with a random jitter so that the nodes do not
+// check in lockstep. The default interval is used if the interval is zero. The release notes
+// are provided with the releases if the notes source is not nil.
+func NewFortaImageStore(ctx context.Context, updaterPort string, autoUpdate bool, releaseChannel string,
+	checkInterval time.Duration, notesSource ReleaseNotesSource,
+) (*fortaImageStore, error) {
+	if checkInterval == 0 {
+		checkInterval = defaultImageCheckInterval
+	}
 	store := &fortaImageStore{
-		updaterPort: updaterPort,
-		latestCh:    make(chan ImageRefs),
+		autoUpdate:     autoUpdate,
+		source:         &updaterReleaseSource{port: updaterPort},
+		notesSource:    notesSource,
+		checkInterval:  checkInterval,
+		releaseChannel: releaseChannel,
+		latestCh:       make(chan ImageRefs),
+		refreshCh:      make(chan struct{}, 1),
 	}
 	if autoUpdate {
 		go store.loop(ctx)
@@ -47,19 +100,55 @@ func NewFortaImageStore(ctx context.Context, updaterPort string, autoUpdate bool
 	return store, nil
 }
 
+// NewOfflineImageStore creates a new store which provides only the embedded images and never
+// checks the updater.
+func NewOfflineImageStore() *fortaImageStore {
+	return &fortaImageStore{
+		offline:   true,
+		latestCh:  make(chan ImageRefs),
+		refreshCh: make(chan struct{}, 1),
+	}
+}
+
 func (store *fortaImageStore) loop(ctx context.Context) {
-	store.check(ctx)
-	ticker := time.NewTicker(defaultImageCheckInterval)
 	for {
+		store.check(ctx)
+		timer := time.NewTimer(store.nextCheckDelay())
 		select {
 		case <-ctx.Done():
+			timer.Stop()
 			return
-		case <-ticker.C:
-			store.check(ctx)
+		case <-timer.C:
+		case <-store.refreshCh:
+			timer.Stop()
 		}
 	}
 }
 
+// nextCheckDelay returns the check interval with a random jitter. The interval is doubled after
+// each consecutive failure up to the max backoff.
+func (store *fortaImageStore) nextCheckDelay() time.Duration {
+	store.errMu.RLock()
+	failures := store.failures
+	store.errMu.RUnlock()
+
+	delay := store.checkInterval
+	maxBackoff := maxImageCheckBackoff
+	if delay > maxBackoff {
+		maxBackoff = delay
+	}
+	for i := 1; i < failures && delay < maxBackoff; i++ {
+		delay *= 2
+	}
+	if delay > maxBackoff {
+		delay = maxBackoff
+	}
+	if jitter := int64(float64(delay) * imageCheckJitterRatio); jitter > 0 {
+		delay += time.Duration(rand.Int63n(jitter))
+	}
+	return delay
+}
+
 func (store *fortaImageStore) EmbeddedImageRefs() ImageRefs {
 	return ImageRefs{
 		Supervisor:  config.DockerSupervisorImage,
@@ -68,34 +157,194 @@ func (store *fortaImageStore) EmbeddedImageRefs() ImageRefs {
 	}
 }
 
-func (store *fortaImageStore) check(ctx context.Context) {
-	latestReleaseInfo, err := store.getFromUpdater(ctx)
-	if err != nil {
-		log.WithError(err).Warn("failed to get the latest release from the updater")
+// SetReleaseChannel changes the tracked channel and makes the store check the latest release
+// of the new channel.
+func (store *fortaImageStore) SetReleaseChannel(channel string) {
+	store.mu.Lock()
+	if channel == store.releaseChannel {
+		store.mu.Unlock()
+		return
 	}
+	log.WithFields(log.Fields{
+		"from": store.releaseChannel,
+		"to":   channel,
+	}).Info("switching the release channel")
+	store.releaseChannel = channel
+	store.mu.Unlock()
+	store.refresh()
+}
 
-	if latestReleaseInfo == nil {
+// refresh makes the store check the latest release from the updater without waiting for the next
+// check.
+func (store *fortaImageStore) refresh() {
+	if store.offline {
+		log.Debug("offline mode - not checking the updater")
 		return
 	}
+	select {
+	case store.refreshCh <- struct{}{}:
+	default: // already requested
+	}
+}
 
-	serviceImgs := latestReleaseInfo.Manifest.Release.Services
-	if serviceImgs.Supervisor != store.latestImgs.Supervisor || serviceImgs.Updater != store.latestImgs.Updater {
-		log.WithField("commit", latestReleaseInfo.Manifest.Release.Commit).Info("got newer release from updater")
+func (store *fortaImageStore) check(ctx context.Context) {
+	if store.offline {
+		log.Debug("offline mode - not checking the updater")
+		return
+	}
+	latestImgs, _ := store.checkLatest(ctx)
+	if latestImgs == nil {
+		return
+	}
+	select {
+	case store.latestCh <- *latestImgs:
+	case <-ctx.Done():
+	}
+}
 
-		store.latestImgs = ImageRefs{
-			Supervisor:  serviceImgs.Supervisor,
-			Updater:     serviceImgs.Updater,
-			ReleaseInfo: latestReleaseInfo,
+// CheckNow makes the updater check the latest release immediately and returns the images of the
+// release if it is newer than the last provided one. The images are returned instead of being
+// sent to the latest channel so that the caller can apply them synchronously. No images are
+// returned if there is no newer release.
+func (store *fortaImageStore) CheckNow(ctx context.Context) (*ImageRefs, error) {
+	if store.offline {
+		return nil, ErrOfflineImageStore
+	}
+	if checker, ok := store.source.(releaseChecker); ok {
+		if err := checker.CheckRelease(ctx); err != nil {
+			return nil, fmt.Errorf("failed to check the latest release: %v", err)
 		}
-		store.latestCh <- store.latestImgs
 	}
+	return store.checkLatest(ctx)
 }
 
-func (store *fortaImageStore) getFromUpdater(ctx context.Context) (*release.ReleaseInfo, error) {
-	resp, err := http.Get(fmt.Sprintf("http://localhost:%s", store.updaterPort))
+// checkLatest gets the latest release from the updater and returns the images of the release if
+// they were not provided before.
+func (store *fortaImageStore) checkLatest(ctx context.Context) (*ImageRefs, error) {
+	latestReleaseInfo, err := store.source.LatestRelease(ctx)
+	store.setCheckResult(err)
+	if err != nil || latestReleaseInfo == nil {
+		return nil, err
+	}
+
+	store.mu.Lock()
+	releaseChannel := store.releaseChannel
+	if !config.MatchesReleaseChannel(latestReleaseInfo, releaseChannel) {
+		store.mu.Unlock()
+		log.WithFields(log.Fields{
+			"version":        latestReleaseInfo.Manifest.Release.Version,
+			"channel":        releaseChannel,
+			"releaseChannel": config.GetReleaseChannel(latestReleaseInfo),
+		}).Debug("skipping release from another channel")
+		return nil, nil
+	}
+
+	// never provide the same images twice in a row
+	serviceImgs := latestReleaseInfo.Manifest.Release.Services
+	if serviceImgs.Supervisor == store.sentImgs.Supervisor && serviceImgs.Updater == store.sentImgs.Updater {
+		store.mu.Unlock()
+		return nil, nil
+	}
+	log.WithFields(log.Fields{
+		"commit":  latestReleaseInfo.Manifest.Release.Commit,
+		"channel": releaseChannel,
+	}).Info("got newer release from updater")
+	store.sentImgs = ImageRefs{
+		Supervisor:  serviceImgs.Supervisor,
+		Updater:     serviceImgs.Updater,
+		ReleaseInfo: latestReleaseInfo,
+	}
+	latestImgs := store.sentImgs
+	store.mu.Unlock()
+
+	latestImgs.Notes = store.getReleaseNotes(ctx, latestReleaseInfo)
+	return &latestImgs, nil
+}
+
+// getReleaseNotes gets the notes of the release. The missing notes do not block the update so
+// the failures are only logged.
+func (store *fortaImageStore) getReleaseNotes(ctx context.Context, releaseInfo *release.ReleaseInfo) *config.ReleaseNotes {
+	if store.notesSource == nil {
+		return nil
+	}
+	ctx, cancel := context.WithTimeout(ctx, releaseNotesTimeout)
+	defer cancel()
+	notes, err := store.notesSource.ReleaseNotes(ctx, releaseInfo)
+	if err != nil {
+		log.WithError(err).WithField("release", releaseInfo.IPFS).Warn("failed to get the release notes")
+		return nil
+	}
+	return notes
+}
+
+// setCheckResult counts the consecutive failures to back off. Only the first failure and the
+// recovery are logged and the failures are reported in the health reports.
+func (store *fortaImageStore) setCheckResult(err error) {
+	store.lastChecked.Set()
+	store.errMu.Lock()
+	defer store.errMu.Unlock()
+	switch {
+	case err != nil && store.failures == 0:
+		log.WithError(err).Warn("failed to get the latest release from the updater - backing off")
+	case err == nil && store.failures > 0:
+		log.WithField("failures", store.failures).Info("got the latest release from the updater again")
+	}
+	store.lastErr = err
+	if err != nil {
+		store.failures++
+	} else {
+		store.failures = 0
+	}
+}
+
+// Health implements the health.Reporter interface. Nothing is reported if the store does not
+// check the updater.
+func (store *fortaImageStore) Health() health.Reports {
+	if !store.autoUpdate {
+		return nil
+	}
+	store.errMu.RLock()
+	defer store.errMu.RUnlock()
+	checkReport := &health.Report{
+		Name:   "forta.image-store.check",
+		Status: health.StatusOK,
+	}
+	if store.lastErr != nil {
+		checkReport.Status = health.StatusFailing
+		checkReport.Details = fmt.Sprintf("%d consecutive failures: %v", store.failures, store.lastErr)
+	}
+	return health.Reports{
+		{
+			Name:    "forta.image-store.last-check",
+			Status:  health.StatusInfo,
+			Details: store.lastChecked.String(),
+		},
+		checkReport,
+	}
+}
+
+// Latest returns a channel that provides the latest image reference.
+func (store *fortaImageStore) Latest() <-chan ImageRefs {
+	return store.latestCh
+}
+
+// updaterReleaseSource gets the latest release from the updater.
+type updaterReleaseSource struct {
+	port string
+}
+
+// LatestRelease implements the ReleaseSource interface. No release is returned if the updater is
+// not ready yet.
+func (source *updaterReleaseSource) LatestRelease(ctx context.Context) (*release.ReleaseInfo, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, fmt.Sprintf("http://localhost:%s", source.port), nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := http.DefaultClient.Do(req)
 	if err != nil {
 		return nil, err
 	}
+	defer resp.Body.Close()
 	respBody, err := io.ReadAll(resp.Body)
 	if err != nil {
 		return nil, err
@@ -107,10 +356,26 @@ func (store *fortaImageStore) getFromUpdater(ctx context.Context) (*release.Rele
 		return nil, fmt.Errorf("unexpected updater response with code %d: %s", resp.StatusCode, string(respBody))
 	}
 	var releaseInfo release.ReleaseInfo
-	return &releaseInfo, json.Unmarshal(respBody, &releaseInfo)
+	if err := json.Unmarshal(respBody, &releaseInfo); err != nil {
+		return nil, fmt.Errorf("failed to decode the updater response: %v", err)
+	}
+	return &releaseInfo, nil
 }
 
-// Latest returns a channel that provides the latest image reference.
-func (store *fortaImageStore) Latest() <-chan ImageRefs {
-	return store.latestCh
+// CheckRelease makes the updater check the latest release from the registry immediately.
+func (source *updaterReleaseSource) CheckRelease(ctx context.Context) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, fmt.Sprintf("http://localhost:%s%s", source.port, config.UpdaterCheckPath), nil)
+	if err != nil {
+		return err
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		respBody, _ := io.ReadAll(resp.Body)
+		return fmt.Errorf("unexpected updater response with code %d: %s", resp.StatusCode, string(respBody))
+	}
+	return nil
 }
@@ -0,0 +1,278 @@
+package store
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/forta-network/forta-core-go/clients/health"
+	"github.com/forta-network/forta-core-go/release"
+	"github.com/forta-network/forta-node/config"
+	"github.com/goccy/go-json"
+	"github.com/stretchr/testify/require"
+)
+
+func testUpdaterServer(t *testing.T, version string) (*httptest.Server, string) {
+	b, err := json.Marshal(testRelease(version))
+	require.NoError(t, err)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write(b)
+	}))
+	u, err := url.Parse(server.URL)
+	require.NoError(t, err)
+	return server, u.Port()
+}
+
+type testReleaseSource struct {
+	releaseInfo *release.ReleaseInfo
+	err         error
+	mu          sync.Mutex
+}
+
+func (source *testReleaseSource) LatestRelease(ctx context.Context) (*release.ReleaseInfo, error) {
+	source.mu.Lock()
+	defer source.mu.Unlock()
+	return source.releaseInfo, source.err
+}
+
+func (source *testReleaseSource) setRelease(releaseInfo *release.ReleaseInfo) {
+	source.mu.Lock()
+	defer source.mu.Unlock()
+	source.releaseInfo = releaseInfo
+	source.err = nil
+}
+
+func testRelease(version string) *release.ReleaseInfo {
+	var releaseInfo release.ReleaseInfo
+	releaseInfo.Manifest.Release.Version = version
+	releaseInfo.Manifest.Release.Services.Supervisor = "supervisor-" + version
+	releaseInfo.Manifest.Release.Services.Updater = "updater-" + version
+	return &releaseInfo
+}
+
+func testImageStore(source ReleaseSource, releaseChannel string) *fortaImageStore {
+	return &fortaImageStore{
+		autoUpdate:     true,
+		source:         source,
+		checkInterval:  defaultImageCheckInterval,
+		releaseChannel: releaseChannel,
+		latestCh:       make(chan ImageRefs),
+		refreshCh:      make(chan struct{}, 1),
+	}
+}
+
+func receiveLatest(store *fortaImageStore) *ImageRefs {
+	select {
+	case latest := <-store.Latest():
+		return &latest
+	case <-time.After(time.Second):
+		return nil
+	}
+}
+
+func TestFortaImageStore_ChannelMismatch(t *testing.T) {
+	r := require.New(t)
+
+	server, port := testUpdaterServer(t, "v0.7.2-canary.1")
+	defer server.Close()
+
+	store, err := NewFortaImageStore(context.Background(), port, false, config.ReleaseChannelBeta, 0, nil)
+	r.NoError(err)
+
+	go store.check(context.Background())
+	r.Nil(receiveLatest(store))
+}
+
+func TestFortaImageStore_SetReleaseChannel(t *testing.T) {
+	r := require.New(t)
+
+	source := &testReleaseSource{releaseInfo: testRelease("v0.7.2-beta.1")}
+	store := testImageStore(source, config.ReleaseChannelStable)
+
+	// skipped until the channel is switched
+	go store.check(context.Background())
+	r.Nil(receiveLatest(store))
+
+	store.SetReleaseChannel(config.ReleaseChannelBeta)
+	r.Len(store.refreshCh, 1)
+	go store.check(context.Background())
+	latest := receiveLatest(store)
+	r.NotNil(latest)
+	r.Equal("updater-v0.7.2-beta.1", latest.Updater)
+
+	// the same release is not provided twice after switching the channel
+	store.SetReleaseChannel(config.ReleaseChannelCanary)
+	go store.check(context.Background())
+	r.Nil(receiveLatest(store))
+}
+
+func TestFortaImageStore_Refresh(t *testing.T) {
+	r := require.New(t)
+
+	source := &testReleaseSource{releaseInfo: testRelease("v0.7.1")}
+	store := testImageStore(source, config.ReleaseChannelStable)
+	store.checkInterval = time.Hour
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go store.loop(ctx)
+	r.NotNil(receiveLatest(store))
+
+	// checks again before the next tick
+	source.setRelease(testRelease("v0.7.2"))
+	store.refresh()
+	store.refresh() // does not block
+	latest := receiveLatest(store)
+	r.NotNil(latest)
+	r.Equal("supervisor-v0.7.2", latest.Supervisor)
+
+	// the same release is not provided twice
+	store.refresh()
+	r.Nil(receiveLatest(store))
+}
+
+func TestFortaImageStore_Backoff(t *testing.T) {
+	r := require.New(t)
+
+	source := &testReleaseSource{err: errors.New("connection refused")}
+	store := testImageStore(source, config.ReleaseChannelStable)
+	store.checkInterval = time.Minute
+
+	// nothing is reported before the first check
+	reports := store.Health()
+	r.Len(reports, 2)
+	r.Empty(reports[0].Details)
+	r.Equal(health.StatusOK, reports[1].Status)
+
+	// the interval is doubled after each failure up to the max backoff
+	for i, expected := range []time.Duration{
+		time.Minute, time.Minute * 2, time.Minute * 4, time.Minute * 8, maxImageCheckBackoff, maxImageCheckBackoff,
+	} {
+		store.check(context.Background())
+		delay := store.nextCheckDelay()
+		r.GreaterOrEqual(delay, expected, i)
+		r.Less(delay, expected+time.Duration(float64(expected)*imageCheckJitterRatio), i)
+	}
+	reports = store.Health()
+	r.NotEmpty(reports[0].Details)
+	r.Equal("forta.image-store.check", reports[1].Name)
+	r.Equal(health.StatusFailing, reports[1].Status)
+	r.Equal("6 consecutive failures: connection refused", reports[1].Details)
+
+	// recovers after a successful check
+	source.setRelease(nil)
+	store.check(context.Background())
+	r.Equal(health.StatusOK, store.Health()[1].Status)
+	r.Less(store.nextCheckDelay(), time.Minute+time.Duration(float64(time.Minute)*imageCheckJitterRatio))
+
+	// the interval is not limited by the max backoff
+	store.checkInterval = time.Hour
+	source.err = errors.New("connection refused")
+	store.check(context.Background())
+	store.check(context.Background())
+	r.GreaterOrEqual(store.nextCheckDelay(), time.Hour)
+}
+
+func TestUpdaterReleaseSource(t *testing.T) {
+	r := require.New(t)
+
+	server, port := testUpdaterServer(t, "v0.7.1")
+	defer server.Close()
+	releaseInfo, err := (&updaterReleaseSource{port: port}).LatestRelease(context.Background())
+	r.NoError(err)
+	r.Equal("v0.7.1", releaseInfo.Manifest.Release.Version)
+
+	// not ready yet
+	notReady := httptest.NewServer(http.NotFoundHandler())
+	defer notReady.Close()
+	u, err := url.Parse(notReady.URL)
+	r.NoError(err)
+	releaseInfo, err = (&updaterReleaseSource{port: u.Port()}).LatestRelease(context.Background())
+	r.NoError(err)
+	r.Nil(releaseInfo)
+}
+
+func TestFortaImageStore_Offline(t *testing.T) {
+	r := require.New(t)
+
+	store := NewOfflineImageStore()
+	go store.check(context.Background())
+	store.refresh()
+	r.Nil(receiveLatest(store))
+}
+
+type testNotesSource struct {
+	notes *config.ReleaseNotes
+	err   error
+}
+
+func (source *testNotesSource) ReleaseNotes(ctx context.Context, releaseInfo *release.ReleaseInfo) (*config.ReleaseNotes, error) {
+	return source.notes, source.err
+}
+
+func TestFortaImageStore_ReleaseNotes(t *testing.T) {
+	r := require.New(t)
+
+	source := &testReleaseSource{releaseInfo: testRelease("v0.7.1")}
+	store := testImageStore(source, config.ReleaseChannelStable)
+	store.notesSource = &testNotesSource{notes: &config.ReleaseNotes{Notes: "- fixed things"}}
+
+	go store.check(context.Background())
+	latest := receiveLatest(store)
+	r.NotNil(latest)
+	r.Equal("- fixed things", latest.Notes.Notes)
+
+	// the missing notes do not block the release
+	store.notesSource = &testNotesSource{err: errors.New("failed")}
+	source.setRelease(testRelease("v0.7.2"))
+	go store.check(context.Background())
+	latest = receiveLatest(store)
+	r.NotNil(latest)
+	r.Equal("supervisor-v0.7.2", latest.Supervisor)
+	r.Nil(latest.Notes)
+}
+
+type testCheckedReleaseSource struct {
+	testReleaseSource
+	checks int
+}
+
+func (source *testCheckedReleaseSource) CheckRelease(ctx context.Context) error {
+	source.checks++
+	return nil
+}
+
+func TestFortaImageStore_CheckNow(t *testing.T) {
+	r := require.New(t)
+
+	source := &testCheckedReleaseSource{testReleaseSource: testReleaseSource{releaseInfo: testRelease("v0.7.1")}}
+	store := testImageStore(source, config.ReleaseChannelStable)
+
+	latest, err := store.CheckNow(context.Background())
+	r.NoError(err)
+	r.NotNil(latest)
+	r.Equal("supervisor-v0.7.1", latest.Supervisor)
+	r.Equal(1, source.checks)
+
+	// the same release is not returned or sent twice
+	latest, err = store.CheckNow(context.Background())
+	r.NoError(err)
+	r.Nil(latest)
+	go store.check(context.Background())
+	r.Nil(receiveLatest(store))
+
+	source.mu.Lock()
+	source.err = errors.New("updater error")
+	source.mu.Unlock()
+	_, err = store.CheckNow(context.Background())
+	r.Error(err)
+
+	_, err = NewOfflineImageStore().CheckNow(context.Background())
+	r.ErrorIs(err, ErrOfflineImageStore)
+}
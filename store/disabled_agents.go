@@ -0,0 +1,133 @@
+package store
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path"
+	"sort"
+	"sync"
+	"time"
+)
+
+const disabledAgentsFileName = "disabled-agents.json"
+
+// DisabledAgent is an agent which is not started until it is enabled again.
+type DisabledAgent struct {
+	AgentID    string    `json:"agentId"`
+	Reason     string    `json:"reason,omitempty"`
+	DisabledAt time.Time `json:"disabledAt"`
+}
+
+// DisabledAgentsStore persists the disabled agents so that they stay disabled after the restarts
+// and the updates.
+type DisabledAgentsStore interface {
+	Get() ([]*DisabledAgent, error)
+	IsDisabled(agentID string) (bool, error)
+	Disable(agentID, reason string) (*DisabledAgent, error)
+	Enable(agentID string) (bool, error)
+}
+
+type disabledAgentsStore struct {
+	filePath string
+	mu       sync.Mutex
+}
+
+// NewDisabledAgentsStore creates a new disabled agents store.
+func NewDisabledAgentsStore(dir string) *disabledAgentsStore {
+	return &disabledAgentsStore{
+		filePath: path.Join(dir, disabledAgentsFileName),
+	}
+}
+
+// Get returns the disabled agents sorted by the agent ID.
+func (store *disabledAgentsStore) Get() ([]*DisabledAgent, error) {
+	store.mu.Lock()
+	defer store.mu.Unlock()
+
+	agents, err := store.read()
+	if err != nil {
+		return nil, err
+	}
+	var list []*DisabledAgent
+	for _, agent := range agents {
+		list = append(list, agent)
+	}
+	sort.Slice(list, func(i, j int) bool {
+		return list[i].AgentID < list[j].AgentID
+	})
+	return list, nil
+}
+
+// IsDisabled tells if the agent is disabled.
+func (store *disabledAgentsStore) IsDisabled(agentID string) (bool, error) {
+	store.mu.Lock()
+	defer store.mu.Unlock()
+
+	agents, err := store.read()
+	if err != nil {
+		return false, err
+	}
+	_, ok := agents[agentID]
+	return ok, nil
+}
+
+// Disable adds the agent to the disabled agents. The reason and the time are updated if the agent
+// is already disabled.
+func (store *disabledAgentsStore) Disable(agentID, reason string) (*DisabledAgent, error) {
+	store.mu.Lock()
+	defer store.mu.Unlock()
+
+	agents, err := store.read()
+	if err != nil {
+		return nil, err
+	}
+	agent := &DisabledAgent{
+		AgentID:    agentID,
+		Reason:     reason,
+		DisabledAt: time.Now().UTC(),
+	}
+	agents[agentID] = agent
+	return agent, store.write(agents)
+}
+
+// Enable removes the agent from the disabled agents and tells if it was disabled.
+func (store *disabledAgentsStore) Enable(agentID string) (bool, error) {
+	store.mu.Lock()
+	defer store.mu.Unlock()
+
+	agents, err := store.read()
+	if err != nil {
+		return false, err
+	}
+	if _, ok := agents[agentID]; !ok {
+		return false, nil
+	}
+	delete(agents, agentID)
+	return true, store.write(agents)
+}
+
+func (store *disabledAgentsStore) read() (map[string]*DisabledAgent, error) {
+	agents := make(map[string]*DisabledAgent)
+	b, err := os.ReadFile(store.filePath)
+	switch {
+	case errors.Is(err, os.ErrNotExist):
+		return agents, nil
+	case err != nil:
+		return nil, fmt.Errorf("failed to read the disabled agents file: %v", err)
+	}
+	if err := json.Unmarshal(b, &agents); err != nil {
+		return nil, fmt.Errorf("failed to decode the disabled agents file: %v", err)
+	}
+	return agents, nil
+}
+
+// write replaces the file through a temporary file so that it is never left half-written.
+func (store *disabledAgentsStore) write(agents map[string]*DisabledAgent) error {
+	b, _ := json.Marshal(agents)
+	if err := writeFileAtomic(store.filePath, b, 0644); err != nil {
+		return fmt.Errorf("failed to write the disabled agents file: %v", err)
+	}
+	return nil
+}
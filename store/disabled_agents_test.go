@@ -0,0 +1,46 @@
+package store
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestDisabledAgentsStore(t *testing.T) {
+	r := require.New(t)
+
+	dir := t.TempDir()
+	store := NewDisabledAgentsStore(dir)
+
+	agents, err := store.Get()
+	r.NoError(err)
+	r.Empty(agents)
+
+	agent, err := store.Disable("0x2", "too many alerts")
+	r.NoError(err)
+	r.Equal("too many alerts", agent.Reason)
+	r.False(agent.DisabledAt.IsZero())
+	_, err = store.Disable("0x1", "")
+	r.NoError(err)
+
+	// survives the restarts
+	store = NewDisabledAgentsStore(dir)
+	disabled, err := store.IsDisabled("0x2")
+	r.NoError(err)
+	r.True(disabled)
+	agents, err = store.Get()
+	r.NoError(err)
+	r.Len(agents, 2)
+	r.Equal("0x1", agents[0].AgentID)
+	r.Equal("too many alerts", agents[1].Reason)
+
+	enabled, err := store.Enable("0x2")
+	r.NoError(err)
+	r.True(enabled)
+	enabled, err = store.Enable("0x2")
+	r.NoError(err)
+	r.False(enabled)
+	disabled, err = store.IsDisabled("0x2")
+	r.NoError(err)
+	r.False(disabled)
+}
@@ -0,0 +1,19 @@
+package store
+
+import (
+	"os"
+)
+
+// writeFileAtomic replaces the file through a temporary file in the same dir so that the file
+// is never left half-written.
+func writeFileAtomic(filePath string, b []byte, perm os.FileMode) error {
+	tmpPath := filePath + ".tmp"
+	if err := os.WriteFile(tmpPath, b, perm); err != nil {
+		return err
+	}
+	if err := os.Rename(tmpPath, filePath); err != nil {
+		os.Remove(tmpPath)
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,29 @@
+package store
+
+import (
+	"os"
+	"path"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestWriteFileAtomic(t *testing.T) {
+	r := require.New(t)
+
+	filePath := path.Join(t.TempDir(), "test.json")
+	r.NoError(writeFileAtomic(filePath, []byte("1"), 0644))
+	r.NoError(writeFileAtomic(filePath, []byte("2"), 0644))
+
+	b, err := os.ReadFile(filePath)
+	r.NoError(err)
+	r.Equal("2", string(b))
+	_, err = os.Stat(filePath + ".tmp")
+	r.ErrorIs(err, os.ErrNotExist)
+
+	// the previous file is kept on failure
+	r.Error(writeFileAtomic(path.Join(filePath, "not-a-dir"), []byte("3"), 0644))
+	b, err = os.ReadFile(filePath)
+	r.NoError(err)
+	r.Equal("2", string(b))
+}
@@ -0,0 +1,260 @@
+package store
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/ethereum/go-ethereum/accounts/keystore"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+// Key store errors
+var (
+	ErrKeyExists       = errors.New("a scanner key already exists")
+	ErrNoKey           = errors.New("no scanner key found")
+	ErrMultipleKeys    = errors.New("multiple scanner keys found")
+	ErrEmptyPassphrase = errors.New("empty passphrase")
+)
+
+// the scrypt params of the key files
+var keyScryptN, keyScryptP = keystore.StandardScryptN, keystore.StandardScryptP
+
+// ScannerKeyStore manages the scanner key file in the key dir. The key dir must contain only
+// the scanner key file. The replaced key files are moved to the backup dir next to the key dir.
+type ScannerKeyStore struct {
+	dir       string
+	backupDir string
+}
+
+// NewScannerKeyStore creates a new scanner key store.
+func NewScannerKeyStore(dir string) *ScannerKeyStore {
+	dir = path.Clean(dir)
+	return &ScannerKeyStore{
+		dir:       dir,
+		backupDir: dir + "-backup",
+	}
+}
+
+// BackupDir returns the dir which the replaced key files are moved to.
+func (store *ScannerKeyStore) BackupDir() string {
+	return store.backupDir
+}
+
+// Create generates a new scanner key and encrypts it with the passphrase.
+func (store *ScannerKeyStore) Create(passphrase string, force bool) (common.Address, error) {
+	if err := store.prepare(passphrase, force); err != nil {
+		return common.Address{}, err
+	}
+	account, err := store.keystore().NewAccount(passphrase)
+	if err != nil {
+		return common.Address{}, fmt.Errorf("failed to create the key: %v", err)
+	}
+	return account.Address, nil
+}
+
+// ImportPrivateKey imports the hex private key and encrypts it with the passphrase.
+func (store *ScannerKeyStore) ImportPrivateKey(hexKey, passphrase string, force bool) (common.Address, error) {
+	privateKey, err := crypto.HexToECDSA(strings.TrimPrefix(strings.TrimSpace(hexKey), "0x"))
+	if err != nil {
+		// the parse errors do not include the key
+		return common.Address{}, errors.New("could not parse the private key hex")
+	}
+	if err := store.prepare(passphrase, force); err != nil {
+		return common.Address{}, err
+	}
+	account, err := store.keystore().ImportECDSA(privateKey, passphrase)
+	if err != nil {
+		return common.Address{}, fmt.Errorf("failed to import the key: %v", err)
+	}
+	return account.Address, nil
+}
+
+// ImportKeystore imports the keystore JSON which is encrypted with the keystore passphrase and
+// encrypts the key with the passphrase.
+func (store *ScannerKeyStore) ImportKeystore(keyJSON []byte, keystorePassphrase, passphrase string, force bool) (common.Address, error) {
+	if _, err := keystore.DecryptKey(keyJSON, keystorePassphrase); err != nil {
+		return common.Address{}, fmt.Errorf("failed to decrypt the keystore: %v", err)
+	}
+	if err := store.prepare(passphrase, force); err != nil {
+		return common.Address{}, err
+	}
+	account, err := store.keystore().Import(keyJSON, keystorePassphrase, passphrase)
+	if err != nil {
+		return common.Address{}, fmt.Errorf("failed to import the keystore: %v", err)
+	}
+	return account.Address, nil
+}
+
+// Export copies the encrypted key file to the given path after checking the passphrase.
+func (store *ScannerKeyStore) Export(to, passphrase string, force bool) (common.Address, error) {
+	_, keyJSON, key, err := store.readKey(passphrase)
+	if err != nil {
+		return common.Address{}, err
+	}
+	flags := os.O_WRONLY | os.O_CREATE | os.O_TRUNC
+	if !force {
+		flags |= os.O_EXCL
+	}
+	file, err := os.OpenFile(to, flags, 0600)
+	if errors.Is(err, os.ErrExist) {
+		return common.Address{}, fmt.Errorf("%s already exists", to)
+	}
+	if err != nil {
+		return common.Address{}, fmt.Errorf("failed to create the export file: %v", err)
+	}
+	defer file.Close()
+	if _, err := file.Write(keyJSON); err != nil {
+		return common.Address{}, fmt.Errorf("failed to write the export file: %v", err)
+	}
+	return key.Address, nil
+}
+
+// Verify decrypts the key with the passphrase without changing any files.
+func (store *ScannerKeyStore) Verify(passphrase string) (common.Address, error) {
+	_, _, key, err := store.readKey(passphrase)
+	if err != nil {
+		return common.Address{}, err
+	}
+	return key.Address, nil
+}
+
+// Address reads the address of the key from the key file without decrypting the key.
+func (store *ScannerKeyStore) Address() (common.Address, error) {
+	keyFile, err := store.keyFile()
+	if err != nil {
+		return common.Address{}, err
+	}
+	keyJSON, err := os.ReadFile(keyFile)
+	if err != nil {
+		return common.Address{}, fmt.Errorf("failed to read the key file: %v", err)
+	}
+	var encryptedKey struct {
+		Address string `json:"address"`
+	}
+	if err := json.Unmarshal(keyJSON, &encryptedKey); err != nil || !common.IsHexAddress(encryptedKey.Address) {
+		return common.Address{}, errors.New("the key file does not contain a valid address")
+	}
+	return common.HexToAddress(encryptedKey.Address), nil
+}
+
+// ChangePassphrase re-encrypts the key with the new passphrase. The previous key file is copied
+// to the backup dir first and then it is replaced in one step.
+func (store *ScannerKeyStore) ChangePassphrase(passphrase, newPassphrase string) (common.Address, error) {
+	if len(newPassphrase) == 0 {
+		return common.Address{}, ErrEmptyPassphrase
+	}
+	keyFile, keyJSON, key, err := store.readKey(passphrase)
+	if err != nil {
+		return common.Address{}, err
+	}
+	newKeyJSON, err := keystore.EncryptKey(key, newPassphrase, keyScryptN, keyScryptP)
+	if err != nil {
+		return common.Address{}, fmt.Errorf("failed to encrypt the key: %v", err)
+	}
+
+	backupPath, err := store.backupPath(keyFile)
+	if err != nil {
+		return common.Address{}, err
+	}
+	if err := os.WriteFile(backupPath, keyJSON, 0600); err != nil {
+		return common.Address{}, fmt.Errorf("failed to back up the key file: %v", err)
+	}
+	// the temporary file is kept out of the key dir
+	tmpPath := path.Join(store.backupDir, path.Base(keyFile)+".tmp")
+	if err := os.WriteFile(tmpPath, newKeyJSON, 0600); err != nil {
+		return common.Address{}, fmt.Errorf("failed to write the key file: %v", err)
+	}
+	if err := os.Rename(tmpPath, keyFile); err != nil {
+		os.Remove(tmpPath)
+		return common.Address{}, fmt.Errorf("failed to replace the key file: %v", err)
+	}
+	return key.Address, nil
+}
+
+func (store *ScannerKeyStore) keystore() *keystore.KeyStore {
+	return keystore.NewKeyStore(store.dir, keyScryptN, keyScryptP)
+}
+
+// keyFile returns the path of the only key file in the key dir.
+func (store *ScannerKeyStore) keyFile() (string, error) {
+	entries, err := store.entries()
+	if err != nil {
+		return "", err
+	}
+	switch {
+	case len(entries) == 0:
+		return "", ErrNoKey
+	case len(entries) > 1:
+		return "", ErrMultipleKeys
+	}
+	return path.Join(store.dir, entries[0].Name()), nil
+}
+
+// readKey reads and decrypts the only key file in the key dir.
+func (store *ScannerKeyStore) readKey(passphrase string) (keyFile string, keyJSON []byte, key *keystore.Key, err error) {
+	keyFile, err = store.keyFile()
+	if err != nil {
+		return
+	}
+	keyJSON, err = os.ReadFile(keyFile)
+	if err != nil {
+		err = fmt.Errorf("failed to read the key file: %v", err)
+		return
+	}
+	key, err = keystore.DecryptKey(keyJSON, passphrase)
+	if err != nil {
+		err = fmt.Errorf("failed to decrypt the key: %w", err)
+	}
+	return
+}
+
+func (store *ScannerKeyStore) entries() ([]os.DirEntry, error) {
+	entries, err := os.ReadDir(store.dir)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return nil, fmt.Errorf("failed to read the key dir: %v", err)
+	}
+	return entries, nil
+}
+
+// prepare makes sure that the key dir exists and it is empty. The existing key files are moved
+// to the backup dir if forced.
+func (store *ScannerKeyStore) prepare(passphrase string, force bool) error {
+	if len(passphrase) == 0 {
+		return ErrEmptyPassphrase
+	}
+	entries, err := store.entries()
+	if err != nil {
+		return err
+	}
+	if len(entries) > 0 && !force {
+		return ErrKeyExists
+	}
+	for _, entry := range entries {
+		keyFile := path.Join(store.dir, entry.Name())
+		backupPath, err := store.backupPath(keyFile)
+		if err != nil {
+			return err
+		}
+		if err := os.Rename(keyFile, backupPath); err != nil {
+			return fmt.Errorf("failed to back up the key file: %v", err)
+		}
+	}
+	if err := os.MkdirAll(store.dir, 0755); err != nil {
+		return fmt.Errorf("failed to create the key dir: %v", err)
+	}
+	return nil
+}
+
+// backupPath returns a new path for the key file in the backup dir.
+func (store *ScannerKeyStore) backupPath(keyFile string) (string, error) {
+	if err := os.MkdirAll(store.backupDir, 0700); err != nil {
+		return "", fmt.Errorf("failed to create the key backup dir: %v", err)
+	}
+	return path.Join(store.backupDir, fmt.Sprintf("%s.%d", path.Base(keyFile), time.Now().UnixNano())), nil
+}
@@ -0,0 +1,151 @@
+package store
+
+import (
+	"encoding/hex"
+	"os"
+	"path"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/accounts/keystore"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/forta-network/forta-core-go/security"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	testKeyPassphrase = "passphrase1"
+	testPrivateKeyHex = "4f3edf983ac636a65a842ce7c78d9aa706d3b113bce9c46f30d7d21715b23b1d"
+	testKeyAddress    = "0x90F8bf6A479f320ead074411a4B0e7944Ea8c9C1"
+)
+
+func useLightScrypt(t *testing.T) {
+	keyScryptN, keyScryptP = keystore.LightScryptN, keystore.LightScryptP
+	t.Cleanup(func() {
+		keyScryptN, keyScryptP = keystore.StandardScryptN, keystore.StandardScryptP
+	})
+}
+
+func TestScannerKeyStore_Create(t *testing.T) {
+	r := require.New(t)
+	useLightScrypt(t)
+
+	keyDir := path.Join(t.TempDir(), ".keys")
+	store := NewScannerKeyStore(keyDir)
+
+	_, err := store.Create("", false)
+	r.ErrorIs(err, ErrEmptyPassphrase)
+
+	address, err := store.Create(testKeyPassphrase, false)
+	r.NoError(err)
+	key, err := security.LoadKeyWithPassphrase(keyDir, testKeyPassphrase)
+	r.NoError(err)
+	r.Equal(address, key.Address)
+
+	// the key is not replaced unless forced
+	_, err = store.Create(testKeyPassphrase, false)
+	r.ErrorIs(err, ErrKeyExists)
+
+	newAddress, err := store.Create(testKeyPassphrase, true)
+	r.NoError(err)
+	r.NotEqual(address, newAddress)
+	key, err = security.LoadKeyWithPassphrase(keyDir, testKeyPassphrase)
+	r.NoError(err)
+	r.Equal(newAddress, key.Address)
+
+	// the replaced key is backed up
+	backups, err := os.ReadDir(store.BackupDir())
+	r.NoError(err)
+	r.Len(backups, 1)
+}
+
+func TestScannerKeyStore_ImportExport(t *testing.T) {
+	r := require.New(t)
+	useLightScrypt(t)
+
+	dir := t.TempDir()
+	store := NewScannerKeyStore(path.Join(dir, ".keys"))
+
+	_, err := store.ImportPrivateKey("not a key", testKeyPassphrase, false)
+	r.Error(err)
+	r.NotContains(err.Error(), "not a key")
+
+	address, err := store.ImportPrivateKey("0x"+testPrivateKeyHex+"\n", testKeyPassphrase, false)
+	r.NoError(err)
+	r.Equal(testKeyAddress, address.Hex())
+
+	_, err = store.ImportPrivateKey(testPrivateKeyHex, testKeyPassphrase, false)
+	r.ErrorIs(err, ErrKeyExists)
+
+	// export needs the right passphrase and does not overwrite unless forced
+	exportPath := path.Join(dir, "exported.json")
+	_, err = store.Export(exportPath, "wrong", false)
+	r.Error(err)
+	address, err = store.Export(exportPath, testKeyPassphrase, false)
+	r.NoError(err)
+	r.Equal(testKeyAddress, address.Hex())
+	_, err = store.Export(exportPath, testKeyPassphrase, false)
+	r.Error(err)
+	_, err = store.Export(exportPath, testKeyPassphrase, true)
+	r.NoError(err)
+
+	// the exported keystore is imported to another key dir with a new passphrase
+	keyJSON, err := os.ReadFile(exportPath)
+	r.NoError(err)
+	otherKeyDir := path.Join(dir, "other-keys")
+	otherStore := NewScannerKeyStore(otherKeyDir)
+	_, err = otherStore.ImportKeystore(keyJSON, "wrong", "passphrase2", false)
+	r.Error(err)
+	address, err = otherStore.ImportKeystore(keyJSON, testKeyPassphrase, "passphrase2", false)
+	r.NoError(err)
+	r.Equal(testKeyAddress, address.Hex())
+
+	key, err := security.LoadKeyWithPassphrase(otherKeyDir, "passphrase2")
+	r.NoError(err)
+	r.Equal(testKeyAddress, key.Address.Hex())
+	r.Equal(testPrivateKeyHex, keyHex(key))
+}
+
+func TestScannerKeyStore_ChangePassphrase(t *testing.T) {
+	r := require.New(t)
+	useLightScrypt(t)
+
+	keyDir := path.Join(t.TempDir(), ".keys")
+	store := NewScannerKeyStore(keyDir)
+
+	_, err := store.ChangePassphrase(testKeyPassphrase, "passphrase2")
+	r.ErrorIs(err, ErrNoKey)
+
+	_, err = store.ImportPrivateKey(testPrivateKeyHex, testKeyPassphrase, false)
+	r.NoError(err)
+
+	_, err = store.ChangePassphrase("wrong", "passphrase2")
+	r.Error(err)
+	_, err = store.ChangePassphrase(testKeyPassphrase, "")
+	r.ErrorIs(err, ErrEmptyPassphrase)
+
+	address, err := store.ChangePassphrase(testKeyPassphrase, "passphrase2")
+	r.NoError(err)
+	r.Equal(testKeyAddress, address.Hex())
+
+	_, err = security.LoadKeyWithPassphrase(keyDir, testKeyPassphrase)
+	r.Error(err)
+	key, err := security.LoadKeyWithPassphrase(keyDir, "passphrase2")
+	r.NoError(err)
+	r.Equal(testPrivateKeyHex, keyHex(key))
+
+	// only the key file is left in the key dir and the backup has the old passphrase
+	entries, err := os.ReadDir(keyDir)
+	r.NoError(err)
+	r.Len(entries, 1)
+	backups, err := os.ReadDir(store.BackupDir())
+	r.NoError(err)
+	r.Len(backups, 1)
+	backupJSON, err := os.ReadFile(path.Join(store.BackupDir(), backups[0].Name()))
+	r.NoError(err)
+	_, err = keystore.DecryptKey(backupJSON, testKeyPassphrase)
+	r.NoError(err)
+}
+
+func keyHex(key *keystore.Key) string {
+	return hex.EncodeToString(crypto.FromECDSA(key.PrivateKey))
+}
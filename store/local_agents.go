@@ -0,0 +1,136 @@
+package store
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"path"
+	"sync"
+
+	"github.com/forta-network/forta-node/config"
+	log "github.com/sirupsen/logrus"
+	"gopkg.in/yaml.v3"
+)
+
+// LocalAgentsFileName is the file in the forta dir which lists the agents to run in addition to
+// the agents from the registry.
+const LocalAgentsFileName = "local-agents.yml"
+
+// localAgentsStore adds the agents from the local agents file to the agents from the registry.
+// The local agents take precedence if the IDs conflict.
+type localAgentsStore struct {
+	RegistryStore
+	filePath string
+
+	registryAgents []*config.AgentConfig
+	localFile      []byte
+	localAgents    []*config.AgentConfig
+	mu             sync.Mutex
+}
+
+// NewLocalAgentsStore creates a new local agents store which reads the local agents file from
+// the dir.
+func NewLocalAgentsStore(registryStore RegistryStore, dir string) *localAgentsStore {
+	return &localAgentsStore{
+		RegistryStore: registryStore,
+		filePath:      path.Join(dir, LocalAgentsFileName),
+	}
+}
+
+// GetAgentsIfChanged returns the merged agents if the registry agents or the local agents changed.
+func (store *localAgentsStore) GetAgentsIfChanged(scanner string) ([]*config.AgentConfig, bool, error) {
+	store.mu.Lock()
+	defer store.mu.Unlock()
+
+	agents, changed, err := store.RegistryStore.GetAgentsIfChanged(scanner)
+	if err != nil {
+		return nil, false, err
+	}
+	if changed {
+		store.registryAgents = agents
+	}
+	if localChanged := store.reloadLocalAgents(); !changed && !localChanged {
+		return nil, false, nil
+	}
+	return mergeLocalAgents(store.registryAgents, store.localAgents), true, nil
+}
+
+// LocalAgents returns the last loaded local agents.
+func (store *localAgentsStore) LocalAgents() []*config.AgentConfig {
+	store.mu.Lock()
+	defer store.mu.Unlock()
+	return store.localAgents
+}
+
+// reloadLocalAgents reads the local agents file and tells if the local agents changed. The
+// previous agents are kept if the file is invalid.
+func (store *localAgentsStore) reloadLocalAgents() bool {
+	logger := log.WithField("file", store.filePath)
+
+	b, err := os.ReadFile(store.filePath)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		logger.WithError(err).Warn("failed to read the local agents file")
+		return false
+	}
+	if bytes.Equal(b, store.localFile) {
+		return false
+	}
+	store.localFile = b
+
+	agents, err := parseLocalAgents(b)
+	if err != nil {
+		logger.WithError(err).Warn("invalid local agents file - keeping the previous local agents")
+		return false
+	}
+	store.localAgents = agents
+	for _, agent := range agents {
+		logger.WithFields(log.Fields{
+			"agentId": agent.ID,
+			"image":   agent.Image,
+		}).Warn("running local agent which is not from the registry")
+	}
+	logger.WithField("count", len(agents)).Info("loaded the local agents")
+	return true
+}
+
+func parseLocalAgents(b []byte) ([]*config.AgentConfig, error) {
+	var agents []*config.AgentConfig
+	if err := yaml.Unmarshal(b, &agents); err != nil {
+		return nil, fmt.Errorf("failed to decode: %v", err)
+	}
+	seen := make(map[string]bool)
+	for i, agent := range agents {
+		if agent == nil || len(agent.ID) == 0 || len(agent.Image) == 0 {
+			return nil, fmt.Errorf("agent #%d needs an id and an image", i+1)
+		}
+		if seen[agent.ID] {
+			return nil, fmt.Errorf("duplicate agent id: %s", agent.ID)
+		}
+		seen[agent.ID] = true
+		agent.IsLocal = true
+	}
+	return agents, nil
+}
+
+// mergeLocalAgents replaces the registry agents with the local agents which have the same IDs
+// and adds the rest of the local agents.
+func mergeLocalAgents(registryAgents, localAgents []*config.AgentConfig) []*config.AgentConfig {
+	local := make(map[string]bool)
+	for _, agent := range localAgents {
+		local[agent.ID] = true
+	}
+	merged := []*config.AgentConfig{}
+	for _, agent := range registryAgents {
+		if local[agent.ID] {
+			log.WithField("agentId", agent.ID).Warn("local agent replaces the agent from the registry")
+			continue
+		}
+		merged = append(merged, agent)
+	}
+	for _, agent := range localAgents {
+		copied := *agent
+		merged = append(merged, &copied)
+	}
+	return merged
+}
@@ -0,0 +1,103 @@
+package store
+
+import (
+	"os"
+	"path"
+	"testing"
+
+	"github.com/forta-network/forta-node/config"
+	mock_store "github.com/forta-network/forta-node/store/mocks"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+)
+
+const testLocalScanner = "0x1"
+
+func writeLocalAgents(t *testing.T, dir, content string) {
+	require.NoError(t, os.WriteFile(path.Join(dir, LocalAgentsFileName), []byte(content), 0644))
+}
+
+func agentIDs(agents []*config.AgentConfig) (ids []string) {
+	for _, agent := range agents {
+		ids = append(ids, agent.ID)
+	}
+	return
+}
+
+func TestLocalAgentsStore_Merge(t *testing.T) {
+	r := require.New(t)
+
+	dir := t.TempDir()
+	writeLocalAgents(t, dir, `
+- id: "0x2"
+  image: local-image-2
+- id: "0x3"
+  image: local-image-3
+`)
+
+	registryStore := mock_store.NewMockRegistryStore(gomock.NewController(t))
+	registryStore.EXPECT().GetAgentsIfChanged(testLocalScanner).Return([]*config.AgentConfig{
+		{ID: "0x1", Image: "registry-image-1"},
+		{ID: "0x2", Image: "registry-image-2"},
+	}, true, nil)
+
+	agents, changed, err := NewLocalAgentsStore(registryStore, dir).GetAgentsIfChanged(testLocalScanner)
+	r.NoError(err)
+	r.True(changed)
+	r.Equal([]string{"0x1", "0x2", "0x3"}, agentIDs(agents))
+	r.False(agents[0].IsLocal)
+	r.Equal("local-image-2", agents[1].Image)
+	r.True(agents[1].IsLocal)
+	r.True(agents[2].IsLocal)
+}
+
+func TestLocalAgentsStore_Reload(t *testing.T) {
+	r := require.New(t)
+
+	dir := t.TempDir()
+	registryStore := mock_store.NewMockRegistryStore(gomock.NewController(t))
+	registryStore.EXPECT().GetAgentsIfChanged(testLocalScanner).Return([]*config.AgentConfig{
+		{ID: "0x1", Image: "registry-image-1"},
+	}, true, nil)
+	registryStore.EXPECT().GetAgentsIfChanged(testLocalScanner).Return(nil, false, nil).AnyTimes()
+	localStore := NewLocalAgentsStore(registryStore, dir)
+
+	// no local agents file yet
+	agents, changed, err := localStore.GetAgentsIfChanged(testLocalScanner)
+	r.NoError(err)
+	r.True(changed)
+	r.Equal([]string{"0x1"}, agentIDs(agents))
+
+	// add a local agent
+	writeLocalAgents(t, dir, `[{id: "0x2", image: local-image-2}]`)
+	agents, changed, err = localStore.GetAgentsIfChanged(testLocalScanner)
+	r.NoError(err)
+	r.True(changed)
+	r.Equal([]string{"0x1", "0x2"}, agentIDs(agents))
+	r.Len(localStore.LocalAgents(), 1)
+
+	// nothing changed
+	_, changed, err = localStore.GetAgentsIfChanged(testLocalScanner)
+	r.NoError(err)
+	r.False(changed)
+
+	// an invalid file keeps the previous local agents
+	writeLocalAgents(t, dir, `[{id: "0x3"}]`)
+	_, changed, err = localStore.GetAgentsIfChanged(testLocalScanner)
+	r.NoError(err)
+	r.False(changed)
+	r.Equal([]string{"0x2"}, agentIDs(localStore.LocalAgents()))
+
+	// remove the local agents
+	r.NoError(os.Remove(path.Join(dir, LocalAgentsFileName)))
+	agents, changed, err = localStore.GetAgentsIfChanged(testLocalScanner)
+	r.NoError(err)
+	r.True(changed)
+	r.Equal([]string{"0x1"}, agentIDs(agents))
+	r.Empty(localStore.LocalAgents())
+}
+
+func TestParseLocalAgents_Duplicate(t *testing.T) {
+	_, err := parseLocalAgents([]byte(`[{id: "0x1", image: a}, {id: "0x1", image: b}]`))
+	require.Error(t, err)
+}
@@ -242,6 +242,10 @@ func (rs *privateRegistryStore) GetAgentsIfChanged(scanner string) ([]*config.Ag
 	}
 
 	// load by bot IDs
+	if len(rs.cfg.LocalModeConfig.BotIDs) > 0 && (rs.rc == nil || rs.mc == nil) {
+		log.Warn("offline mode - skipping the bot IDs which need the registry and ipfs")
+		return agentConfigs, true, nil
+	}
 	for _, agentID := range rs.cfg.LocalModeConfig.BotIDs {
 		agt, err := rs.rc.GetAgent(agentID)
 		logger := log.WithFields(log.Fields{
@@ -275,6 +279,15 @@ func (rs *privateRegistryStore) makePrivateModeAgentConfig(id string, image stri
 }
 
 func NewPrivateRegistryStore(ctx context.Context, cfg config.Config) (*privateRegistryStore, error) {
+	rs := &privateRegistryStore{
+		ctx: ctx,
+		cfg: cfg,
+	}
+	// only the bot images can run in offline mode
+	if cfg.OfflineSkip("ipfs") || cfg.OfflineSkip("registry") {
+		return rs, nil
+	}
+
 	mc, err := manifest.NewClient(cfg.Registry.IPFS.GatewayURL)
 	if err != nil {
 		return nil, err
@@ -288,12 +301,34 @@ func NewPrivateRegistryStore(ctx context.Context, cfg config.Config) (*privateRe
 	if err != nil {
 		return nil, err
 	}
-	return &privateRegistryStore{
-		ctx: ctx,
-		cfg: cfg,
-		mc:  mc,
-		rc:  rc,
-	}, nil
+	rs.mc = mc
+	rs.rc = rc
+	return rs, nil
+}
+
+// offlineRegistryStore assigns no agents because the registry is not reachable in offline mode.
+type offlineRegistryStore struct {
+	published bool
+	mu        sync.Mutex
+}
+
+// NewOfflineRegistryStore creates a new registry store for the offline mode.
+func NewOfflineRegistryStore() *offlineRegistryStore {
+	return &offlineRegistryStore{}
+}
+
+func (rs *offlineRegistryStore) GetAgentsIfChanged(scanner string) ([]*config.AgentConfig, bool, error) {
+	rs.mu.Lock()
+	defer rs.mu.Unlock()
+	if rs.published {
+		return nil, false, nil
+	}
+	rs.published = true
+	return []*config.AgentConfig{}, true, nil
+}
+
+func (rs *offlineRegistryStore) FindAgentGlobally(agentID string) (*config.AgentConfig, error) {
+	return nil, errors.New("feature not available (offline mode)")
 }
 
 // GetRegistryClient checks the config and returns the suitaable registry.
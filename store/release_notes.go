@@ -0,0 +1,135 @@
+package store
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path"
+	"sync"
+
+	"github.com/forta-network/forta-core-go/release"
+	"github.com/forta-network/forta-node/config"
+)
+
+const (
+	releaseNotesFileName     = "release-notes.json"
+	defaultReleaseNotesLimit = 10
+)
+
+// ReleaseNotesSource provides the notes of the releases.
+type ReleaseNotesSource interface {
+	ReleaseNotes(ctx context.Context, releaseInfo *release.ReleaseInfo) (*config.ReleaseNotes, error)
+}
+
+// ContentClient gets content by reference.
+type ContentClient interface {
+	GetContent(ctx context.Context, reference string) ([]byte, error)
+}
+
+type releaseNotesSource struct {
+	client ContentClient
+}
+
+// NewReleaseNotesSource creates a new source which reads the notes from the release manifests.
+func NewReleaseNotesSource(client ContentClient) *releaseNotesSource {
+	return &releaseNotesSource{client: client}
+}
+
+// ReleaseNotes gets the release manifest and returns the notes in it, or the notes referred by
+// it. No notes are returned if the manifest does not have any.
+func (source *releaseNotesSource) ReleaseNotes(ctx context.Context, releaseInfo *release.ReleaseInfo) (*config.ReleaseNotes, error) {
+	if releaseInfo == nil || len(releaseInfo.IPFS) == 0 {
+		return nil, nil
+	}
+	manifest, err := source.client.GetContent(ctx, releaseInfo.IPFS)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get the release manifest: %v", err)
+	}
+	notes, notesRef, err := config.ParseReleaseNotes(manifest)
+	if err != nil {
+		return nil, err
+	}
+	if len(notesRef) > 0 {
+		b, err := source.client.GetContent(ctx, notesRef)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get the release notes: %v", err)
+		}
+		notes = string(b)
+	}
+	if len(notes) == 0 {
+		return nil, nil
+	}
+	return config.NewReleaseNotes(releaseInfo, notes), nil
+}
+
+// ReleaseNotesStore persists the notes of the last applied releases.
+type ReleaseNotesStore interface {
+	Add(notes *config.ReleaseNotes) error
+	List() ([]*config.ReleaseNotes, error)
+}
+
+type releaseNotesStore struct {
+	filePath string
+	limit    int
+	mu       sync.Mutex
+}
+
+// NewReleaseNotesStore creates a new release notes store.
+func NewReleaseNotesStore(dir string) *releaseNotesStore {
+	return &releaseNotesStore{
+		filePath: path.Join(dir, releaseNotesFileName),
+		limit:    defaultReleaseNotesLimit,
+	}
+}
+
+// Add adds the notes as the latest notes. Only the latest notes are kept up to the limit and the
+// notes of the same release replace the previous ones.
+func (store *releaseNotesStore) Add(notes *config.ReleaseNotes) error {
+	store.mu.Lock()
+	defer store.mu.Unlock()
+
+	list, err := store.read()
+	if err != nil {
+		return err
+	}
+	updated := []*config.ReleaseNotes{notes}
+	for _, existing := range list {
+		if existing.IPFS == notes.IPFS && existing.Commit == notes.Commit {
+			continue
+		}
+		updated = append(updated, existing)
+	}
+	if len(updated) > store.limit {
+		updated = updated[:store.limit]
+	}
+
+	b, _ := json.Marshal(updated)
+	if err := writeFileAtomic(store.filePath, b, 0644); err != nil {
+		return fmt.Errorf("failed to write the release notes file: %v", err)
+	}
+	return nil
+}
+
+// List returns the notes starting from the latest.
+func (store *releaseNotesStore) List() ([]*config.ReleaseNotes, error) {
+	store.mu.Lock()
+	defer store.mu.Unlock()
+	return store.read()
+}
+
+func (store *releaseNotesStore) read() ([]*config.ReleaseNotes, error) {
+	var list []*config.ReleaseNotes
+	b, err := os.ReadFile(store.filePath)
+	switch {
+	case errors.Is(err, os.ErrNotExist):
+		return nil, nil
+	case err != nil:
+		return nil, fmt.Errorf("failed to read the release notes file: %v", err)
+	}
+	if err := json.Unmarshal(b, &list); err != nil {
+		return nil, fmt.Errorf("failed to decode the release notes file: %v", err)
+	}
+	return list, nil
+}
@@ -0,0 +1,85 @@
+package store
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path"
+	"testing"
+
+	"github.com/forta-network/forta-node/config"
+	"github.com/stretchr/testify/require"
+)
+
+type testContentClient map[string]string
+
+func (client testContentClient) GetContent(ctx context.Context, reference string) ([]byte, error) {
+	content, ok := client[reference]
+	if !ok {
+		return nil, errors.New("not found")
+	}
+	return []byte(content), nil
+}
+
+func TestReleaseNotesSource(t *testing.T) {
+	r := require.New(t)
+
+	source := NewReleaseNotesSource(testContentClient{
+		"manifest1": `{"release":{"version":"v0.7.1","notes":"- fixed things"}}`,
+		"manifest2": `{"release":{"version":"v0.7.2","notesIpfs":"notes2"}}`,
+		"notes2":    "- added things",
+		"manifest3": `{"release":{"version":"v0.7.3"}}`,
+		"manifest4": `{"release":{"version":"v0.7.4","notesIpfs":"notes4"}}`,
+	})
+	releaseInfo := testRelease("v0.7.1")
+
+	releaseInfo.IPFS = "manifest1"
+	notes, err := source.ReleaseNotes(context.Background(), releaseInfo)
+	r.NoError(err)
+	r.Equal("- fixed things", notes.Notes)
+	r.Equal("v0.7.1", notes.Version)
+
+	releaseInfo.IPFS = "manifest2"
+	notes, err = source.ReleaseNotes(context.Background(), releaseInfo)
+	r.NoError(err)
+	r.Equal("- added things", notes.Notes)
+
+	// no notes
+	releaseInfo.IPFS = "manifest3"
+	notes, err = source.ReleaseNotes(context.Background(), releaseInfo)
+	r.NoError(err)
+	r.Nil(notes)
+
+	releaseInfo.IPFS = "manifest4"
+	_, err = source.ReleaseNotes(context.Background(), releaseInfo)
+	r.Error(err)
+}
+
+func TestReleaseNotesStore(t *testing.T) {
+	r := require.New(t)
+
+	dir := t.TempDir()
+	store := NewReleaseNotesStore(dir)
+	store.limit = 3
+	list, err := store.List()
+	r.NoError(err)
+	r.Empty(list)
+
+	for i := 1; i <= 4; i++ {
+		r.NoError(store.Add(&config.ReleaseNotes{IPFS: fmt.Sprintf("cid%d", i), Notes: fmt.Sprintf("notes%d", i)}))
+	}
+	// the same release replaces the previous notes
+	r.NoError(store.Add(&config.ReleaseNotes{IPFS: "cid3", Notes: "notes3-updated"}))
+
+	list, err = NewReleaseNotesStore(dir).List()
+	r.NoError(err)
+	r.Len(list, 3)
+	r.Equal("notes3-updated", list[0].Notes)
+	r.Equal("cid4", list[1].IPFS)
+	r.Equal("cid2", list[2].IPFS)
+
+	r.NoError(os.WriteFile(path.Join(dir, releaseNotesFileName), []byte("{"), 0644))
+	_, err = store.List()
+	r.Error(err)
+}
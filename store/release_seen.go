@@ -0,0 +1,105 @@
+package store
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path"
+	"sync"
+	"time"
+)
+
+const (
+	releaseSeenFileName     = "release-first-seen.json"
+	releaseRejectedFileName = "release-rejected.json"
+)
+
+// ReleaseSeenStore persists the time the releases were first seen at so that the update delays
+// survive the restarts. It also persists the release which was rejected after a failed
+// validation so that it is not applied again after a restart.
+type ReleaseSeenStore interface {
+	FirstSeen(releaseKey string, now time.Time) (time.Time, error)
+	Reject(releaseKey string) error
+	Rejected() (string, error)
+}
+
+type releaseSeenStore struct {
+	filePath     string
+	rejectedPath string
+	mu           sync.Mutex
+}
+
+// rejectedRelease is the content of the release rejected file.
+type rejectedRelease struct {
+	Release    string    `json:"release"`
+	RejectedAt time.Time `json:"rejectedAt"`
+}
+
+// NewReleaseSeenStore creates a new release seen store.
+func NewReleaseSeenStore(dir string) *releaseSeenStore {
+	return &releaseSeenStore{
+		filePath:     path.Join(dir, releaseSeenFileName),
+		rejectedPath: path.Join(dir, releaseRejectedFileName),
+	}
+}
+
+// FirstSeen returns the time the release was first seen at. If the release was not seen before,
+// the given time is recorded. Only the latest release is kept so a newer release supersedes the
+// previous one.
+func (store *releaseSeenStore) FirstSeen(releaseKey string, now time.Time) (time.Time, error) {
+	store.mu.Lock()
+	defer store.mu.Unlock()
+
+	seen := make(map[string]time.Time)
+	b, err := os.ReadFile(store.filePath)
+	switch {
+	case errors.Is(err, os.ErrNotExist):
+	case err != nil:
+		return time.Time{}, fmt.Errorf("failed to read the release seen file: %v", err)
+	default:
+		if err := json.Unmarshal(b, &seen); err != nil {
+			return time.Time{}, fmt.Errorf("failed to decode the release seen file: %v", err)
+		}
+	}
+	if firstSeen, ok := seen[releaseKey]; ok {
+		return firstSeen, nil
+	}
+
+	b, _ = json.Marshal(map[string]time.Time{releaseKey: now})
+	if err := writeFileAtomic(store.filePath, b, 0644); err != nil {
+		return time.Time{}, fmt.Errorf("failed to write the release seen file: %v", err)
+	}
+	return now, nil
+}
+
+// Reject records the rejected release. Only the latest rejected release is kept.
+func (store *releaseSeenStore) Reject(releaseKey string) error {
+	store.mu.Lock()
+	defer store.mu.Unlock()
+
+	b, _ := json.Marshal(&rejectedRelease{Release: releaseKey, RejectedAt: time.Now().UTC()})
+	if err := writeFileAtomic(store.rejectedPath, b, 0644); err != nil {
+		return fmt.Errorf("failed to write the release rejected file: %v", err)
+	}
+	return nil
+}
+
+// Rejected returns the last rejected release or an empty string if no release was rejected.
+func (store *releaseSeenStore) Rejected() (string, error) {
+	store.mu.Lock()
+	defer store.mu.Unlock()
+
+	b, err := os.ReadFile(store.rejectedPath)
+	switch {
+	case errors.Is(err, os.ErrNotExist):
+		return "", nil
+	case err != nil:
+		return "", fmt.Errorf("failed to read the release rejected file: %v", err)
+	}
+	var rejected rejectedRelease
+	if err := json.Unmarshal(b, &rejected); err != nil {
+		return "", fmt.Errorf("failed to decode the release rejected file: %v", err)
+	}
+	return rejected.Release, nil
+}
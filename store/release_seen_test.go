@@ -0,0 +1,75 @@
+package store
+
+import (
+	"os"
+	"path"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestReleaseSeenStore(t *testing.T) {
+	r := require.New(t)
+
+	dir := t.TempDir()
+	t1 := time.Date(2022, 12, 1, 10, 0, 0, 0, time.UTC)
+	t2 := t1.Add(time.Hour)
+	t3 := t2.Add(time.Hour)
+
+	store := NewReleaseSeenStore(dir)
+	firstSeen, err := store.FirstSeen("release1", t1)
+	r.NoError(err)
+	r.True(t1.Equal(firstSeen))
+
+	// survives restarts
+	store = NewReleaseSeenStore(dir)
+	firstSeen, err = store.FirstSeen("release1", t2)
+	r.NoError(err)
+	r.True(t1.Equal(firstSeen))
+
+	// newer release supersedes
+	firstSeen, err = store.FirstSeen("release2", t2)
+	r.NoError(err)
+	r.True(t2.Equal(firstSeen))
+	firstSeen, err = store.FirstSeen("release1", t3)
+	r.NoError(err)
+	r.True(t3.Equal(firstSeen))
+}
+
+func TestReleaseSeenStore_BadFile(t *testing.T) {
+	r := require.New(t)
+
+	dir := t.TempDir()
+	r.NoError(os.WriteFile(path.Join(dir, releaseSeenFileName), []byte("{"), 0644))
+
+	_, err := NewReleaseSeenStore(dir).FirstSeen("release1", time.Now())
+	r.Error(err)
+}
+
+func TestReleaseSeenStore_Rejected(t *testing.T) {
+	r := require.New(t)
+
+	dir := t.TempDir()
+	store := NewReleaseSeenStore(dir)
+	rejected, err := store.Rejected()
+	r.NoError(err)
+	r.Empty(rejected)
+
+	r.NoError(store.Reject("release1"))
+	r.NoError(store.Reject("release2"))
+
+	// survives restarts
+	rejected, err = NewReleaseSeenStore(dir).Rejected()
+	r.NoError(err)
+	r.Equal("release2", rejected)
+
+	// does not affect the first seen times
+	firstSeen, err := store.FirstSeen("release2", time.Unix(1, 0))
+	r.NoError(err)
+	r.True(time.Unix(1, 0).Equal(firstSeen))
+
+	r.NoError(os.WriteFile(path.Join(dir, releaseRejectedFileName), []byte("{"), 0644))
+	_, err = store.Rejected()
+	r.Error(err)
+}
@@ -0,0 +1,77 @@
+package store
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path"
+	"sync"
+
+	"github.com/forta-network/forta-core-go/release"
+)
+
+const runnerStateFileName = "runner-state.json"
+
+// RunnerState is the last known state of the runner containers.
+type RunnerState struct {
+	Updater       string               `json:"updater"`
+	Supervisor    string               `json:"supervisor"`
+	ReleaseCommit string               `json:"releaseCommit,omitempty"`
+	ReleaseInfo   *release.ReleaseInfo `json:"releaseInfo,omitempty"`
+	// NodeImages are the digests of the node images which the runner started. Only these images
+	// are pruned.
+	NodeImages []string `json:"nodeImages,omitempty"`
+}
+
+// RunnerStateStore persists the runner state so that the updates are not repeated after restarts.
+type RunnerStateStore interface {
+	Get() (*RunnerState, error)
+	Put(state RunnerState) error
+}
+
+type runnerStateStore struct {
+	filePath string
+	mu       sync.Mutex
+}
+
+// NewRunnerStateStore creates a new runner state store.
+func NewRunnerStateStore(dir string) *runnerStateStore {
+	return &runnerStateStore{
+		filePath: path.Join(dir, runnerStateFileName),
+	}
+}
+
+// Get reads the last saved state. It returns nil if no state was saved before.
+func (store *runnerStateStore) Get() (*RunnerState, error) {
+	store.mu.Lock()
+	defer store.mu.Unlock()
+
+	b, err := os.ReadFile(store.filePath)
+	switch {
+	case errors.Is(err, os.ErrNotExist):
+		return nil, nil
+	case err != nil:
+		return nil, fmt.Errorf("failed to read the runner state file: %v", err)
+	}
+	var state RunnerState
+	if err := json.Unmarshal(b, &state); err != nil {
+		return nil, fmt.Errorf("failed to decode the runner state file: %v", err)
+	}
+	return &state, nil
+}
+
+// Put replaces the saved state. The file is never left half-written.
+func (store *runnerStateStore) Put(state RunnerState) error {
+	store.mu.Lock()
+	defer store.mu.Unlock()
+
+	if state.ReleaseInfo != nil && len(state.ReleaseCommit) == 0 {
+		state.ReleaseCommit = state.ReleaseInfo.Manifest.Release.Commit
+	}
+	b, _ := json.Marshal(state)
+	if err := writeFileAtomic(store.filePath, b, 0644); err != nil {
+		return fmt.Errorf("failed to write the runner state file: %v", err)
+	}
+	return nil
+}
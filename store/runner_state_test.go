@@ -0,0 +1,67 @@
+package store
+
+import (
+	"os"
+	"path"
+	"testing"
+
+	"github.com/forta-network/forta-core-go/release"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRunnerStateStore(t *testing.T) {
+	r := require.New(t)
+
+	dir := t.TempDir()
+	store := NewRunnerStateStore(dir)
+
+	state, err := store.Get()
+	r.NoError(err)
+	r.Nil(state)
+
+	releaseInfo := &release.ReleaseInfo{IPFS: "Qm1"}
+	releaseInfo.Manifest.Release.Commit = "commit1"
+	r.NoError(store.Put(RunnerState{Updater: "updater1", Supervisor: "supervisor1", ReleaseInfo: releaseInfo}))
+
+	// survives restarts
+	state, err = NewRunnerStateStore(dir).Get()
+	r.NoError(err)
+	r.Equal("updater1", state.Updater)
+	r.Equal("supervisor1", state.Supervisor)
+	r.Equal("commit1", state.ReleaseCommit)
+	r.Equal("Qm1", state.ReleaseInfo.IPFS)
+}
+
+func TestRunnerStateStore_InterruptedWrite(t *testing.T) {
+	r := require.New(t)
+
+	dir := t.TempDir()
+	store := NewRunnerStateStore(dir)
+	r.NoError(store.Put(RunnerState{Updater: "updater1", Supervisor: "supervisor1"}))
+
+	// crashed while writing the next state: the previous state should be intact
+	tmpPath := path.Join(dir, runnerStateFileName+".tmp")
+	r.NoError(os.WriteFile(tmpPath, []byte(`{"updater":"upd`), 0644))
+	state, err := NewRunnerStateStore(dir).Get()
+	r.NoError(err)
+	r.Equal("updater1", state.Updater)
+	r.Equal("supervisor1", state.Supervisor)
+
+	// the leftover file does not get in the way of the next write
+	r.NoError(store.Put(RunnerState{Updater: "updater2", Supervisor: "supervisor2"}))
+	state, err = store.Get()
+	r.NoError(err)
+	r.Equal("updater2", state.Updater)
+	_, err = os.Stat(tmpPath)
+	r.ErrorIs(err, os.ErrNotExist)
+}
+
+func TestRunnerStateStore_BadFile(t *testing.T) {
+	r := require.New(t)
+
+	dir := t.TempDir()
+	r.NoError(os.WriteFile(path.Join(dir, runnerStateFileName), []byte("{"), 0644))
+
+	_, err := NewRunnerStateStore(dir).Get()
+	r.Error(err)
+}
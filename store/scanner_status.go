@@ -0,0 +1,104 @@
+package store
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"sync"
+
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/forta-network/forta-core-go/contracts/contract_scanner_registry"
+	"github.com/forta-network/forta-core-go/registry"
+	"github.com/forta-network/forta-core-go/utils"
+	"github.com/forta-network/forta-node/clients/ethclient"
+	"github.com/forta-network/forta-node/config"
+)
+
+// ScannerStatus is the registration and the stake status of a scanner.
+type ScannerStatus struct {
+	Registered        bool `json:"registered"`
+	Enabled           bool `json:"enabled"`
+	StakeBelowMinimum bool `json:"stakeBelowMinimum"`
+}
+
+// ScannerStatusClient gets the status of the scanners from the registry contracts.
+type ScannerStatusClient interface {
+	GetScannerStatus(ctx context.Context, scannerAddress string) (*ScannerStatus, error)
+}
+
+// scannerStakeCaller checks the stake of the scanners.
+type scannerStakeCaller interface {
+	IsStakedOverMin(opts *bind.CallOpts, subject *big.Int) (bool, error)
+}
+
+type scannerStatusClient struct {
+	ctx context.Context
+	cfg config.Config
+	rc  registry.Client
+	sr  scannerStakeCaller
+	mu  sync.Mutex
+}
+
+// NewScannerStatusClient creates a new scanner status client. The registry is connected on the
+// first call so that an unreachable registry API does not fail the creation. The same client
+// should be used for all of the checks so that the registry client is created only once.
+func NewScannerStatusClient(ctx context.Context, cfg config.Config) *scannerStatusClient {
+	return &scannerStatusClient{
+		ctx: ctx,
+		cfg: cfg,
+	}
+}
+
+// GetScannerStatus checks if the scanner is registered and staked over the minimum.
+func (client *scannerStatusClient) GetScannerStatus(ctx context.Context, scannerAddress string) (*ScannerStatus, error) {
+	client.mu.Lock()
+	defer client.mu.Unlock()
+
+	if err := client.connect(); err != nil {
+		return nil, err
+	}
+	scanner, err := client.rc.GetScanner(scannerAddress)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get the scanner: %v", err)
+	}
+	// the reverts are treated as not registered
+	if scanner == nil {
+		return &ScannerStatus{}, nil
+	}
+	staked, err := client.sr.IsStakedOverMin(&bind.CallOpts{Context: ctx}, utils.ScannerIDHexToBigInt(scannerAddress))
+	if err != nil {
+		return nil, fmt.Errorf("failed to check the scanner stake: %v", err)
+	}
+	return &ScannerStatus{
+		Registered:        true,
+		Enabled:           scanner.Enabled,
+		StakeBelowMinimum: !staked,
+	}, nil
+}
+
+func (client *scannerStatusClient) connect() error {
+	if client.rc == nil {
+		rc, err := GetRegistryClient(client.ctx, client.cfg, registry.ClientConfig{
+			JsonRpcUrl: client.cfg.Registry.JsonRpc.Url,
+			ENSAddress: client.cfg.ENSConfig.ContractAddress,
+			Name:       "scanner-status",
+		})
+		if err != nil {
+			return fmt.Errorf("failed to create the registry client: %v", err)
+		}
+		client.rc = rc
+	}
+	if client.sr == nil {
+		// the registry client does not expose the stake check so it is called through the limiter
+		caller, err := ethclient.NewContractCaller(client.ctx, "registry", client.cfg.Registry.JsonRpc)
+		if err != nil {
+			return fmt.Errorf("failed to dial the registry api: %v", err)
+		}
+		sr, err := contract_scanner_registry.NewScannerRegistryCaller(client.rc.RegistryContracts().ScannerRegistry, caller)
+		if err != nil {
+			return fmt.Errorf("failed to create the scanner registry caller: %v", err)
+		}
+		client.sr = sr
+	}
+	return nil
+}
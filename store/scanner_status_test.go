@@ -0,0 +1,69 @@
+package store
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/forta-network/forta-core-go/registry"
+	mock_registry "github.com/forta-network/forta-core-go/registry/mocks"
+	"github.com/forta-network/forta-core-go/utils"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+)
+
+const testStatusScanner = "0x1111111111111111111111111111111111111111"
+
+type testStakeCaller struct {
+	staked bool
+	err    error
+}
+
+func (caller *testStakeCaller) IsStakedOverMin(opts *bind.CallOpts, subject *big.Int) (bool, error) {
+	if subject.Cmp(utils.ScannerIDHexToBigInt(testStatusScanner)) != 0 {
+		return false, errors.New("unexpected scanner")
+	}
+	return caller.staked, caller.err
+}
+
+func testScannerStatusClient(t *testing.T, stakeCaller *testStakeCaller) (*scannerStatusClient, *mock_registry.MockClient) {
+	rc := mock_registry.NewMockClient(gomock.NewController(t))
+	return &scannerStatusClient{
+		ctx: context.Background(),
+		rc:  rc,
+		sr:  stakeCaller,
+	}, rc
+}
+
+func TestScannerStatusClient(t *testing.T) {
+	r := require.New(t)
+
+	// registered and staked
+	client, rc := testScannerStatusClient(t, &testStakeCaller{staked: true})
+	rc.EXPECT().GetScanner(testStatusScanner).Return(&registry.Scanner{Enabled: true}, nil)
+	status, err := client.GetScannerStatus(context.Background(), testStatusScanner)
+	r.NoError(err)
+	r.Equal(&ScannerStatus{Registered: true, Enabled: true}, status)
+
+	// registered but not staked enough
+	client, rc = testScannerStatusClient(t, &testStakeCaller{})
+	rc.EXPECT().GetScanner(testStatusScanner).Return(&registry.Scanner{}, nil)
+	status, err = client.GetScannerStatus(context.Background(), testStatusScanner)
+	r.NoError(err)
+	r.Equal(&ScannerStatus{Registered: true, StakeBelowMinimum: true}, status)
+
+	// not registered: the stake is not checked
+	client, rc = testScannerStatusClient(t, &testStakeCaller{err: errors.New("should not be called")})
+	rc.EXPECT().GetScanner(testStatusScanner).Return(nil, nil)
+	status, err = client.GetScannerStatus(context.Background(), testStatusScanner)
+	r.NoError(err)
+	r.False(status.Registered)
+
+	// the contract call fails
+	client, rc = testScannerStatusClient(t, &testStakeCaller{err: errors.New("execution reverted")})
+	rc.EXPECT().GetScanner(testStatusScanner).Return(&registry.Scanner{}, nil)
+	_, err = client.GetScannerStatus(context.Background(), testStatusScanner)
+	r.Error(err)
+}
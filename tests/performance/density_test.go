@@ -175,7 +175,7 @@ func NewTestContext(t *testing.T, cfg *TestConfig) *TestContext {
 	return &TestContext{
 		t:         t,
 		cfg:       cfg,
-		msgClient: messaging.NewClient("perf-test", fmt.Sprintf("%s:4222", cfg.host)),
+		msgClient: messaging.NewClient("perf-test", config.NatsConfig{External: true, URL: fmt.Sprintf("nats://%s:4222", cfg.host)}),
 		ready:     nil,
 		metrics:   publisher.NewMetricsAggregator(),
 	}